@@ -36,7 +36,7 @@ interface AdvisoryVersionsResult {
 async function updateMultipleStates(newStates: StateChange[]) {
   try {
     const token = await getAccessToken();
-    const response = await fetch(`/api/status`, {
+    const response = await fetch(`api/status`, {
       headers: {
         Authorization: `Bearer ${token}`
       },
@@ -28,6 +28,11 @@ const COLUMNS = {
     "state",
     "comments",
     "recent",
+    "upstream_changed",
+    "staleness",
+    "localized_title",
+    "languages",
+    "lang",
     "versions"
   ],
   DOCUMENT: [
@@ -44,7 +49,8 @@ const COLUMNS = {
     "cvss_v3_score",
     "four_cves",
     "comments",
-    "tracking_status"
+    "tracking_status",
+    "lang"
   ],
   EVENT: [
     "critical",
@@ -22,6 +22,7 @@ type Source = {
   active?: boolean;
   rate?: number;
   slots?: number;
+  weight?: number;
   headers: string[];
   strict_mode?: boolean;
   secure?: boolean;
@@ -43,6 +44,7 @@ type Source = {
 
 type SourceConfig = {
   slots: number;
+  weight: number;
   rate: number;
   log_level: LogLevel;
   strict_mode: boolean;
@@ -167,6 +169,34 @@ const resetSourceAttention = async (source: Source): Promise<Result<Attention[],
   };
 };
 
+const acknowledgeSourceAttentions = async (): Promise<Result<Attention[], ErrorDetails>> => {
+  const resp = await request(`/api/sources/attention/ack`, "POST", new FormData());
+  if (resp.ok) {
+    return {
+      ok: true,
+      value: resp.content
+    };
+  }
+  return {
+    ok: false,
+    error: getErrorDetails(`Could not acknowledge source changes`, resp)
+  };
+};
+
+const acknowledgeAggregatorAttentions = async (): Promise<Result<Attention[], ErrorDetails>> => {
+  const resp = await request(`/api/aggregators/attention/ack`, "POST", new FormData());
+  if (resp.ok) {
+    return {
+      ok: true,
+      value: resp.content
+    };
+  }
+  return {
+    ok: false,
+    error: getErrorDetails(`Could not acknowledge aggregator changes`, resp)
+  };
+};
+
 const saveSource = async (source: Source): Promise<Result<Source, ErrorDetails>> => {
   let method = "POST";
   let path = `/api/sources`;
@@ -190,6 +220,10 @@ const saveSource = async (source: Source): Promise<Result<Source, ErrorDetails>>
     source.slots = 0;
   }
   formData.append("slots", source.slots.toString());
+  if ((source.weight && source.weight < 0) || source.weight === undefined) {
+    source.weight = 0;
+  }
+  formData.append("weight", source.weight.toString());
   if (source.strict_mode !== undefined) {
     formData.append("strict_mode", source.strict_mode.toString());
   }
@@ -722,6 +756,8 @@ export {
   resetSourceAttention,
   fetchAggregatorAttentionList,
   resetAggregatorAttention,
+  acknowledgeSourceAttentions,
+  acknowledgeAggregatorAttentions,
   dtClass,
   ddClass,
   logLevels
@@ -8,8 +8,9 @@
 
 import { appStore } from "./store.svelte";
 import { type UserManagerSettings, WebStorageStateStore } from "oidc-client-ts";
+import { appURL } from "./utils";
 
-const url = window.location.origin;
+const url = appURL;
 
 const configuration = {
   getConfiguration: (): UserManagerSettings => {
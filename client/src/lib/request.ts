@@ -13,6 +13,7 @@ import { push } from "$routes/router.svelte";
 import type { User } from "oidc-client-ts";
 import type { HttpResponse } from "./types";
 import { jwtDecode } from "jwt-decode";
+import { withBasePath } from "./utils";
 
 const requestData = async (
   abortController: AbortController | undefined,
@@ -21,6 +22,7 @@ const requestData = async (
   requestMethod: string,
   formData?: FormData | string
 ) => {
+  path = withBasePath(path);
   if (abortController) {
     return fetch(path, {
       headers: {
@@ -61,4 +61,16 @@ const splitMatches = (text: string, positions: number[][]): string[] => {
   return decodedSplits;
 };
 
-export { truncate, areArraysEqual, addSlashes, splitMatches };
+// The path the client is served under, e.g. "/isduba" behind a reverse proxy.
+// Empty if the client is served at the root.
+const basePath = window.location.pathname.replace(/\/(index\.html)?$/, "");
+
+// The URL the client is served under without a trailing slash.
+const appURL = window.location.origin + basePath;
+
+// Prefixes absolute paths with the base path.
+const withBasePath = (path: string) => {
+  return path.startsWith("/") ? basePath + path : path;
+};
+
+export { truncate, areArraysEqual, addSlashes, splitMatches, basePath, appURL, withBasePath };
@@ -11,6 +11,7 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"flag"
 	"fmt"
 	"log/slog"
@@ -21,17 +22,38 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 
 	"github.com/ISDuBA/ISDuBA/pkg/aggregators"
+	"github.com/ISDuBA/ISDuBA/pkg/archiving"
+	"github.com/ISDuBA/ISDuBA/pkg/assets"
+	"github.com/ISDuBA/ISDuBA/pkg/blobs"
 	"github.com/ISDuBA/ISDuBA/pkg/config"
 	"github.com/ISDuBA/ISDuBA/pkg/database"
+	"github.com/ISDuBA/ISDuBA/pkg/demo"
+	"github.com/ISDuBA/ISDuBA/pkg/eventbus"
 	"github.com/ISDuBA/ISDuBA/pkg/forwarder"
+	"github.com/ISDuBA/ISDuBA/pkg/grouptlps"
+	"github.com/ISDuBA/ISDuBA/pkg/httpcache"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/receipts"
+	"github.com/ISDuBA/ISDuBA/pkg/recompute"
+	"github.com/ISDuBA/ISDuBA/pkg/scanner"
+	"github.com/ISDuBA/ISDuBA/pkg/scheduler"
+	"github.com/ISDuBA/ISDuBA/pkg/searchcache"
+	"github.com/ISDuBA/ISDuBA/pkg/siem"
+	"github.com/ISDuBA/ISDuBA/pkg/sla"
 	"github.com/ISDuBA/ISDuBA/pkg/sources"
+	"github.com/ISDuBA/ISDuBA/pkg/subscriptions"
+	"github.com/ISDuBA/ISDuBA/pkg/sweeper"
 	"github.com/ISDuBA/ISDuBA/pkg/tempstore"
+	"github.com/ISDuBA/ISDuBA/pkg/transform"
+	"github.com/ISDuBA/ISDuBA/pkg/usage"
 	"github.com/ISDuBA/ISDuBA/pkg/version"
 	"github.com/ISDuBA/ISDuBA/pkg/web"
 	"github.com/gocsaf/csaf/v3/csaf"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func check(err error) {
@@ -41,6 +63,30 @@ func check(err error) {
 	}
 }
 
+// runDemo loads or wipes the demo data.
+func runDemo(cfg *config.Config, action string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if _, err := database.CheckMigrations(ctx, &cfg.Database); err != nil {
+		return fmt.Errorf("migrating failed: %w", err)
+	}
+	db, err := database.NewDB(ctx, &cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer db.Close(ctx)
+
+	switch action {
+	case "load":
+		return demo.Load(ctx, db)
+	case "wipe":
+		return demo.Wipe(ctx, db)
+	default:
+		return fmt.Errorf("unknown demo action %q (use 'load' or 'wipe')", action)
+	}
+}
+
 func run(cfg *config.Config) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -59,17 +105,35 @@ func run(cfg *config.Config) error {
 		return err
 	}
 	defer db.Close(ctx)
-	tmpStore := tempstore.NewStore(&cfg.TempStore)
-	go tmpStore.Run(ctx)
 
-	forwardManager, err := forwarder.NewManager(cfg, db)
+	var scoringStored bool
+	if err := db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+		var err error
+		scoringStored, err = models.StoreScoringDefaults(
+			rctx, conn, cfg.Scoring.Weights, cfg.Scoring.PublishersTrust)
+		return err
+	}, 0); err != nil {
+		return fmt.Errorf("storing scoring defaults failed: %w", err)
+	}
+
+	if err := db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+		return models.StoreLocale(rctx, conn, cfg.General.Locale)
+	}, 0); err != nil {
+		return fmt.Errorf("storing locale failed: %w", err)
+	}
+
+	tasks := scheduler.NewRegistry()
+
+	bus := eventbus.NewBus(db)
+	go bus.Run(ctx)
+
+	malwareScanner, err := scanner.NewScanner(&cfg.Scanner)
 	if err != nil {
-		return fmt.Errorf("creating forwarder failed: %w", err)
+		return err
 	}
-	go forwardManager.Run(ctx)
 
-	agg := aggregators.NewManager(cfg, db)
-	go agg.Run(ctx)
+	tmpStore := tempstore.NewStore(&cfg.TempStore, tasks, malwareScanner)
+	go tmpStore.Run(ctx)
 
 	// Is the remote validator configured?
 	var val csaf.RemoteValidator
@@ -82,8 +146,25 @@ func run(cfg *config.Config) error {
 		defer val.Close()
 	}
 
+	transformer, err := transform.NewTransformer(&cfg.Transformations)
+	if err != nil {
+		return fmt.Errorf("creating transformer failed: %w", err)
+	}
+
+	// The original uploads may be kept outside of the database.
+	blobStore, err := blobs.NewStore(&cfg.BlobStorage, db)
+	if err != nil {
+		return err
+	}
+	if blobStore != nil {
+		go blobStore.Run(ctx)
+	}
+
+	// The HTTP cache is shared by the fetches of the sources and aggregators.
+	httpCache := httpcache.New(int64(cfg.HTTPCache.Size))
+
 	// Setup the source manager.
-	sm, err := sources.NewManager(cfg, db, val)
+	sm, err := sources.NewManager(cfg, db, bus, val, transformer, blobStore, httpCache, tasks)
 	if err != nil {
 		return fmt.Errorf("creating source manager failed: %w", err)
 	}
@@ -92,6 +173,55 @@ func run(cfg *config.Config) error {
 	}
 	go sm.Run(ctx)
 
+	// The secrets of the forward targets are encrypted like the ones of the sources.
+	forwardManager, err := forwarder.NewManager(cfg, db, blobStore, bus, tasks, sm)
+	if err != nil {
+		return fmt.Errorf("creating forwarder failed: %w", err)
+	}
+	go forwardManager.Run(ctx)
+
+	agg := aggregators.NewManager(cfg, db, sm, httpCache, tasks)
+	go agg.Run(ctx)
+
+	sw := sweeper.NewSweeper(db, blobStore, val)
+	go sw.Run(ctx)
+
+	rc := recompute.NewRecomputer(&cfg.Recompute, db, blobStore)
+	go rc.Run(ctx)
+	// The scores are only recomputed if the weights are stored for the first time.
+	if scoringStored {
+		if _, err := rc.Schedule(ctx, sql.NullString{}, []recompute.Field{recompute.Score}); err != nil {
+			return fmt.Errorf("scheduling recomputation of scores failed: %w", err)
+		}
+	}
+
+	qc := searchcache.NewCache(&cfg.SearchCache, db)
+	go qc.Run(ctx)
+
+	gt := grouptlps.NewRules(db)
+	go gt.Run(ctx)
+
+	ur := usage.NewRecorder(&cfg.APIUsage, db, tasks)
+	go ur.Run(ctx)
+
+	mirror := assets.NewMirror(cfg, db, tasks, malwareScanner)
+	go mirror.Run(ctx)
+
+	notifier := subscriptions.NewNotifier(cfg, db, tasks)
+	go notifier.Run(ctx)
+
+	receipter := receipts.NewSender(cfg, db, tasks)
+	go receipter.Run(ctx)
+
+	exporter := siem.NewExporter(cfg, db, tasks)
+	go exporter.Run(ctx)
+
+	escalator := sla.NewChecker(cfg, db, bus, tasks)
+	go escalator.Run(ctx)
+
+	archiver := archiving.NewArchiver(cfg, db, bus, tasks)
+	go archiver.Run(ctx)
+
 	cfg.Web.Configure()
 
 	ctrl := web.NewController(
@@ -100,69 +230,112 @@ func run(cfg *config.Config) error {
 		tmpStore,
 		sm,
 		agg,
+		sw,
+		rc,
+		blobStore,
 		val,
+		tasks,
+		qc,
+		gt,
+		ur,
+		notifier,
+		malwareScanner,
+		transformer,
+		bus,
 	)
 
-	addr := cfg.Web.Addr()
-	slog.Info("Starting web server", "address", addr)
-	srv := &http.Server{
-		Addr:    addr,
-		Handler: ctrl.Bind(),
+	handler := ctrl.Bind()
+	servers := []*http.Server{{Handler: handler}}
+	listeners := []net.Listener{nil}
+
+	slog.Info("Starting web server", "address", cfg.Web.Addr())
+	l, cleanup, err := listen(cfg.Web.Host, cfg.Web.Port)
+	if err != nil {
+		return err
 	}
+	defer cleanup()
+	listeners[0] = l
 
-	// Check if we should serve on an unix domain socket.
-	var listener net.Listener
-	if host := cfg.Web.Host; filepath.IsAbs(host) {
-		host = strings.ReplaceAll(host, "{port}", strconv.Itoa(cfg.Web.Port))
-		l, err := net.Listen("unix", host)
+	if cfg.Web.AdminListener() {
+		slog.Info("Starting admin web server", "address", cfg.Web.AdminAddr())
+		l, cleanup, err := listen(cfg.Web.AdminHost, cfg.Web.AdminPort)
 		if err != nil {
-			return fmt.Errorf("cannot listen on domain socket: %w", err)
-		}
-		defer func() {
-			l.Close()
-			// Cleanup socket file
-			os.Remove(host)
-		}()
-		// Enable writing to socket
-		if err := os.Chmod(host, 0777); err != nil {
-			return fmt.Errorf("cannot change rights on socket: %w", err)
+			return err
 		}
-		listener = l
+		defer cleanup()
+		servers = append(servers, &http.Server{
+			Handler: handler,
+			BaseContext: func(net.Listener) context.Context {
+				return web.AdminListenerContext(context.Background())
+			},
+		})
+		listeners = append(listeners, l)
 	}
 
-	srvErrors := make(chan error)
+	srvErrors := make(chan error, len(servers))
 
-	done := make(chan struct{})
-	go func() {
-		defer close(done)
-		serve := srv.ListenAndServe
-		if listener != nil {
-			serve = func() error { return srv.Serve(listener) }
-		}
-		if err := serve(); err != http.ErrServerClosed {
-			srvErrors <- err
-		}
-	}()
+	var wg sync.WaitGroup
+	for i, srv := range servers {
+		wg.Go(func() {
+			if err := srv.Serve(listeners[i]); err != http.ErrServerClosed {
+				srvErrors <- err
+			}
+		})
+	}
 
 	select {
 	case <-ctx.Done():
 		slog.Info("Shutting down")
-		srv.Shutdown(ctx)
 	case err = <-srvErrors:
 	}
-	<-done
+	for _, srv := range servers {
+		srv.Shutdown(ctx)
+	}
+	wg.Wait()
 	return err
 }
 
+// listen opens a listener on the given host and port.
+// If the host is an absolute path an unix domain socket is used.
+// The returned function closes the listener and removes
+// the socket file.
+func listen(host string, port int) (net.Listener, func(), error) {
+	if !filepath.IsAbs(host) {
+		l, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot listen: %w", err)
+		}
+		return l, func() { l.Close() }, nil
+	}
+	host = strings.ReplaceAll(host, "{port}", strconv.Itoa(port))
+	l, err := net.Listen("unix", host)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot listen on domain socket: %w", err)
+	}
+	cleanup := func() {
+		l.Close()
+		// Cleanup socket file
+		os.Remove(host)
+	}
+	// Enable writing to socket
+	if err := os.Chmod(host, 0777); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("cannot change rights on socket: %w", err)
+	}
+	return l, cleanup, nil
+}
+
 func main() {
 	var (
 		cfgFile     string
 		showVersion bool
+		demoAction  string
 	)
 	flag.StringVar(&cfgFile, "config", config.DefaultConfigFile, "configuration file")
 	flag.StringVar(&cfgFile, "c", config.DefaultConfigFile, "configuration file (shorthand)")
 	flag.BoolVar(&showVersion, "version", false, "show version")
 	flag.BoolVar(&showVersion, "V", false, "show version (shorthand)")
+	flag.StringVar(&demoAction, "demo", "", "'load' or 'wipe' the demo data and exit")
 	flag.Parse()
 	if showVersion {
 		fmt.Printf("%s version: %s\n", os.Args[0], version.SemVersion)
@@ -171,5 +344,9 @@ func main() {
 	cfg, err := config.Load(cfgFile)
 	check(err)
 	check(cfg.Log.Config())
+	if demoAction != "" {
+		check(runDemo(cfg, demoAction))
+		return
+	}
 	check(run(cfg))
 }
@@ -10,6 +10,7 @@ package aggregators
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -17,10 +18,14 @@ import (
 	"net/http"
 	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"golang.org/x/time/rate"
+
 	"github.com/ISDuBA/ISDuBA/pkg/cache"
 	"github.com/ISDuBA/ISDuBA/pkg/config"
+	"github.com/ISDuBA/ISDuBA/pkg/httpcache"
 	"github.com/ISDuBA/ISDuBA/pkg/sources"
 	"github.com/gocsaf/csaf/v3/csaf"
 )
@@ -38,30 +43,70 @@ type CachedAggregator struct {
 type Cache struct {
 	*cache.ExpirationCache[string, *CachedAggregator]
 	timeout time.Duration
+	http    *httpcache.Cache
+	// limiter limits the rate of the requests. nil is unlimited.
+	limiter *rate.Limiter
+
+	hits     atomic.Int64
+	fetches  atomic.Int64
+	failures atomic.Int64
+	waiting  atomic.Int64
 }
 
-func newCache(timeout time.Duration) *Cache {
+func newCache(cfg *config.Aggregators, hc *httpcache.Cache) *Cache {
+	var limiter *rate.Limiter
+	if cfg.Rate > 0 {
+		limiter = rate.NewLimiter(rate.Limit(cfg.Rate), 1)
+	}
 	return &Cache{
 		ExpirationCache: cache.NewExpirationCache[string, *CachedAggregator](holdingDuration),
-		timeout:         timeout,
+		timeout:         cfg.Timeout,
+		http:            hc,
+		limiter:         limiter,
 	}
 }
 
 // GetAggregator fetches a cached aggregator.
-func (c *Cache) GetAggregator(url string, cfg *config.Config) (*CachedAggregator, error) {
+// If the aggregator is not cached it is fetched respecting the rate limit.
+func (c *Cache) GetAggregator(
+	ctx context.Context,
+	url string,
+	cfg *config.Config,
+) (*CachedAggregator, error) {
 	if !strings.HasSuffix(url, "/aggregator.json") {
 		return nil, errors.New("invalid aggregator url")
 	}
 	if ca, ok := c.Get(url); ok {
+		c.hits.Add(1)
 		return ca, nil
 	}
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if c.limiter != nil {
+		c.waiting.Add(1)
+		err := c.limiter.Wait(ctx)
+		c.waiting.Add(-1)
+		if err != nil {
+			return nil, err
+		}
+	}
+	c.fetches.Add(1)
+	ca, err := c.fetch(ctx, url, cfg)
+	if err != nil {
+		c.failures.Add(1)
+		return nil, err
+	}
+	c.Set(url, ca)
+	return ca, nil
+}
+
+// fetch loads and validates an aggregator.
+func (c *Cache) fetch(ctx context.Context, url string, cfg *config.Config) (*CachedAggregator, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Add("User-Agent", sources.UserAgent)
 	client := &http.Client{
-		Transport: cfg.General.Transport(),
+		Transport: c.http.Transport(cfg.General.Transport(), ""),
 	}
 	if c.timeout > 0 {
 		client.Timeout = c.timeout
@@ -92,12 +137,42 @@ func (c *Cache) GetAggregator(url string, cfg *config.Config) (*CachedAggregator
 	if err := json.Unmarshal(raw, agg); err != nil {
 		return nil, fmt.Errorf("cannot unmarshal aggregator: %w", err)
 	}
-	ca := &CachedAggregator{
+	return &CachedAggregator{
 		Raw:        raw,
 		Aggregator: agg,
+	}, nil
+}
+
+// Listings extracts the listings of the providers and publishers
+// from the cached aggregator fetched from the given url.
+func (ca *CachedAggregator) Listings(url string) []sources.AggregatorListing {
+	var listings []sources.AggregatorListing
+	add := func(metadata *csaf.AggregatorCSAFProviderMetadata, mirrors []csaf.ProviderURL) {
+		if metadata == nil || metadata.URL == nil {
+			return
+		}
+		listing := sources.AggregatorListing{
+			Aggregator: url,
+			URL:        string(*metadata.URL),
+			Publisher:  metadata.Publisher,
+			Role:       metadata.Role,
+		}
+		for _, m := range mirrors {
+			listing.Mirrors = append(listing.Mirrors, string(m))
+		}
+		listings = append(listings, listing)
 	}
-	c.Set(url, ca)
-	return ca, nil
+	for _, provider := range ca.Aggregator.CSAFProviders {
+		if provider != nil {
+			add(provider.Metadata, provider.Mirrors)
+		}
+	}
+	for _, publisher := range ca.Aggregator.CSAFPublishers {
+		if publisher != nil {
+			add(publisher.Metadata, publisher.Mirrors)
+		}
+	}
+	return listings
 }
 
 // SourceURLs extracts the source URLs from the cached aggregator.
@@ -130,3 +205,17 @@ func (ca *CachedAggregator) SourceURLs() []string {
 	}
 	return urls
 }
+
+// Evict removes the cached aggregator fetched from the given url.
+// If url is empty all aggregators are removed.
+// The responses are removed from the HTTP cache, too.
+// Returns the number of removed entries.
+func (c *Cache) Evict(url string) int {
+	return c.DeleteFunc(func(k string, _ *CachedAggregator) bool {
+		if url == "" || k == url {
+			c.http.Evict(k)
+			return true
+		}
+		return false
+	})
+}
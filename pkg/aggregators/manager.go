@@ -16,16 +16,18 @@ import (
 	"log/slog"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ISDuBA/ISDuBA/pkg/config"
 	"github.com/ISDuBA/ISDuBA/pkg/database"
+	"github.com/ISDuBA/ISDuBA/pkg/httpcache"
+	"github.com/ISDuBA/ISDuBA/pkg/scheduler"
+	"github.com/ISDuBA/ISDuBA/pkg/sources"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-const maxPMDWorkers = 10
-
 // Manager handles the refreshing of the aggregators.
 type Manager struct {
 	Cache *Cache
@@ -34,15 +36,55 @@ type Manager struct {
 	fns  chan func(*Manager)
 	cfg  *config.Config
 	db   *database.DB
+	sm   *sources.Manager
+
+	refreshTask *scheduler.Task
+	cacheTask   *scheduler.Task
+
+	statsMu     sync.Mutex
+	lastRefresh RefreshStats
+}
+
+// RefreshStats are statistics about the last refresh of the aggregators.
+type RefreshStats struct {
+	Started  *time.Time    `json:"started,omitempty"`
+	Duration time.Duration `json:"duration" swaggertype:"integer"`
+	Fetched  int           `json:"fetched"`
+	Failed   int           `json:"failed"`
+}
+
+// Stats are statistics about the fetching of the aggregators.
+type Stats struct {
+	Workers      int          `json:"workers"`
+	Rate         float64      `json:"rate"`
+	CacheEntries int          `json:"cache_entries"`
+	CacheHits    int64        `json:"cache_hits"`
+	Fetches      int64        `json:"fetches"`
+	Failures     int64        `json:"failures"`
+	Waiting      int64        `json:"waiting"`
+	LastRefresh  RefreshStats `json:"last_refresh"`
 }
 
 // NewManager creates a new aggregators manager.
-func NewManager(cfg *config.Config, db *database.DB) *Manager {
+func NewManager(
+	cfg *config.Config,
+	db *database.DB,
+	sm *sources.Manager,
+	hc *httpcache.Cache,
+	tasks *scheduler.Registry,
+) *Manager {
 	return &Manager{
-		Cache: newCache(cfg.Aggregators.Timeout),
+		Cache: newCache(&cfg.Aggregators, hc),
 		fns:   make(chan func(*Manager)),
 		cfg:   cfg,
 		db:    db,
+		sm:    sm,
+		refreshTask: tasks.Register("aggregator_refresh",
+			"Checks the aggregators for changed source lists.",
+			cfg.Aggregators.UpdateInterval),
+		cacheTask: tasks.Register("aggregator_cache",
+			"Removes outdated entries from the aggregator cache.",
+			holdingDuration),
 	}
 }
 
@@ -59,9 +101,17 @@ func (m *Manager) Run(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			m.refresh(ctx)
+			if !m.refreshTask.Paused() {
+				m.runRefresh(ctx)
+			}
+		case <-m.refreshTask.Triggered():
+			m.runRefresh(ctx)
 		case <-cacheTicker.C:
-			m.Cache.Cleanup()
+			if !m.cacheTask.Paused() {
+				m.cleanupCache()
+			}
+		case <-m.cacheTask.Triggered():
+			m.cleanupCache()
 		}
 	}
 }
@@ -77,15 +127,32 @@ func aggregatorChecksum(cagg *CachedAggregator) []byte {
 	return hash.Sum(nil)
 }
 
-func (m *Manager) refresh(ctx context.Context) {
+func (m *Manager) runRefresh(ctx context.Context) {
+	// Wait for the next round if the database is unavailable.
+	if !m.db.Available() {
+		return
+	}
+	done := m.refreshTask.Start()
+	done(m.refresh(ctx))
+}
+
+func (m *Manager) cleanupCache() {
+	done := m.cacheTask.Start()
+	m.Cache.Cleanup()
+	done(nil)
+}
+
+func (m *Manager) refresh(ctx context.Context) error {
 	type aggregator struct {
 		id          int64
 		url         string
+		active      bool
 		checksum    []byte
 		newChecksum []byte
+		cached      *CachedAggregator
 	}
 	const (
-		selectSQL = `SELECT id, url, checksum FROM aggregators`
+		selectSQL = `SELECT id, url, active, checksum FROM aggregators`
 		updateSQL = `UPDATE aggregators ` +
 			`SET (checksum, checksum_updated) = ($1, $2) ` +
 			`WHERE id = $3 AND active = TRUE`
@@ -98,32 +165,36 @@ func (m *Manager) refresh(ctx context.Context) {
 			var err error
 			aggregators, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (aggregator, error) {
 				var agg aggregator
-				err := row.Scan(&agg.id, &agg.url, &agg.checksum)
+				err := row.Scan(&agg.id, &agg.url, &agg.active, &agg.checksum)
 				return agg, err
 			})
 			return err
 		}, 0,
 	); err != nil {
 		slog.Error("fetching aggregators failed", "error", err)
-		return
+		return err
 	}
 	if len(aggregators) == 0 {
-		return
+		return nil
 	}
 	var (
 		toFetch    = make(chan *aggregator)
-		numWorkers = min(maxPMDWorkers, len(aggregators))
+		numWorkers = min(m.cfg.Aggregators.Workers, len(aggregators))
 		wg         sync.WaitGroup
+		failed     atomic.Int64
+		started    = time.Now()
 	)
 	fetch := func() {
 		defer wg.Done()
 		for agg := range toFetch {
-			cagg, err := m.Cache.GetAggregator(agg.url, m.cfg)
+			cagg, err := m.Cache.GetAggregator(ctx, agg.url, m.cfg)
 			if err != nil {
+				failed.Add(1)
 				slog.Warn("fetching aggregator failed", "url", agg.url, "err", err)
 				continue
 			}
 			agg.newChecksum = aggregatorChecksum(cagg)
+			agg.cached = cagg
 		}
 	}
 	for range numWorkers {
@@ -135,6 +206,25 @@ func (m *Manager) refresh(ctx context.Context) {
 	}
 	close(toFetch)
 	wg.Wait()
+	m.statsMu.Lock()
+	m.lastRefresh = RefreshStats{
+		Started:  &started,
+		Duration: time.Since(started),
+		Fetched:  len(aggregators) - int(failed.Load()),
+		Failed:   int(failed.Load()),
+	}
+	m.statsMu.Unlock()
+	if m.cfg.Aggregators.VerifySources && m.sm != nil {
+		var listings []sources.AggregatorListing
+		for i := range aggregators {
+			if agg := &aggregators[i]; agg.active && agg.cached != nil {
+				listings = append(listings, agg.cached.Listings(agg.url)...)
+			}
+		}
+		if len(listings) > 0 {
+			go m.sm.VerifyListings(listings)
+		}
+	}
 	var (
 		batch pgx.Batch
 		now   = time.Now()
@@ -146,7 +236,7 @@ func (m *Manager) refresh(ctx context.Context) {
 		}
 	}
 	if batch.Len() == 0 {
-		return
+		return nil
 	}
 	if err := m.db.Run(
 		ctx,
@@ -163,6 +253,25 @@ func (m *Manager) refresh(ctx context.Context) {
 		}, 0,
 	); err != nil {
 		slog.Error("fetching aggregators failed", "error", err)
+		return err
+	}
+	return nil
+}
+
+// Stats returns statistics about the fetching of the aggregators.
+func (m *Manager) Stats() Stats {
+	m.statsMu.Lock()
+	lastRefresh := m.lastRefresh
+	m.statsMu.Unlock()
+	return Stats{
+		Workers:      m.cfg.Aggregators.Workers,
+		Rate:         m.cfg.Aggregators.Rate,
+		CacheEntries: m.Cache.Len(),
+		CacheHits:    m.Cache.hits.Load(),
+		Fetches:      m.Cache.fetches.Load(),
+		Failures:     m.Cache.failures.Load(),
+		Waiting:      m.Cache.waiting.Load(),
+		LastRefresh:  lastRefresh,
 	}
 }
 
@@ -0,0 +1,442 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package archiving archives or deletes the advisories which stayed
+// long enough in a workflow state as configured by the archiving policies.
+package archiving
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+	"github.com/ISDuBA/ISDuBA/pkg/database"
+	"github.com/ISDuBA/ISDuBA/pkg/eventbus"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/scheduler"
+)
+
+var (
+	// errGone is returned if an advisory left the state
+	// of a policy before the policy was applied.
+	errGone = errors.New("advisory left the state")
+	// errApproval is returned if the archiving of an advisory
+	// needs an approval which is requested instead.
+	errApproval = errors.New("approval requested")
+)
+
+// Candidate is an advisory an archiving policy applies to.
+type Candidate struct {
+	Policy     string          `json:"policy"`
+	Action     string          `json:"action"`
+	AdvisoryID int64           `json:"-"`
+	Publisher  string          `json:"publisher"`
+	TrackingID string          `json:"tracking_id"`
+	State      models.Workflow `json:"state"`
+	Entered    time.Time       `json:"entered"`
+}
+
+// Archiver applies the archiving policies periodically.
+// A nil archiver is valid and does nothing.
+type Archiver struct {
+	cfg      *config.Archiving
+	workflow *config.Workflow
+	db       *database.DB
+	bus      *eventbus.Bus
+	task     *scheduler.Task
+}
+
+// NewArchiver returns a new archiver. If the archiving is not
+// enabled or there are no policies nil is returned.
+func NewArchiver(
+	cfg *config.Config,
+	db *database.DB,
+	bus *eventbus.Bus,
+	tasks *scheduler.Registry,
+) *Archiver {
+	if !cfg.Archiving.Enabled || len(cfg.Archiving.Policies) == 0 {
+		return nil
+	}
+	return &Archiver{
+		cfg:      &cfg.Archiving,
+		workflow: &cfg.Workflow,
+		db:       db,
+		bus:      bus,
+		task: tasks.Register("archiving",
+			"Archives or deletes advisories as configured by the archiving policies.",
+			cfg.Archiving.Interval),
+	}
+}
+
+// Run applies the policies periodically. To be used in a Go routine.
+func (a *Archiver) Run(ctx context.Context) {
+	if a == nil {
+		return
+	}
+	if a.cfg.DryRun {
+		slog.Info("archiving policies are applied as dry run")
+	}
+	ticker := time.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if a.db.Available() && !a.task.Paused() {
+				a.scheduledApply(ctx)
+			}
+		case <-a.task.Triggered():
+			a.scheduledApply(ctx)
+		}
+	}
+}
+
+// scheduledApply applies the policies and records the run in the scheduler task.
+func (a *Archiver) scheduledApply(ctx context.Context) {
+	done := a.task.Start()
+	done(a.apply(ctx))
+}
+
+// apply applies every policy to a batch of its candidates.
+func (a *Archiver) apply(ctx context.Context) error {
+	var errs []error
+	for i := range a.cfg.Policies {
+		p := &a.cfg.Policies[i]
+		candidates, err := Candidates(ctx, a.db, p, a.cfg.DryRun, a.cfg.BatchSize)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var applied int
+		for j := range candidates {
+			ok, err := a.process(ctx, p, &candidates[j])
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if ok {
+				applied++
+			}
+		}
+		if applied > 0 {
+			slog.Info("applied archiving policy",
+				"policy", p.Name, "action", p.Action, "advisories", applied, "dry_run", a.cfg.DryRun)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// candidatesSQL selects the advisories which are longer than allowed
+// in the state of a policy. Advisories already recorded by a dry run
+// may be skipped. Advisories which could not be processed before are
+// ordered last so they do not block the others.
+const candidatesSQL = `SELECT ads.id, ads.publisher, ads.tracking_id, sh.state::text, sh.valid_from ` +
+	`FROM state_history sh JOIN advisories ads ON sh.advisories_id = ads.id ` +
+	`WHERE sh.valid_to IS NULL AND sh.state = $1::workflow ` +
+	`AND sh.valid_from < current_timestamp - $2::float8 * interval '1 second' ` +
+	`AND ($3::text IS NULL OR ads.publisher = $3) ` +
+	`AND NOT ($4 AND EXISTS (SELECT 1 FROM archiving_log al ` +
+	`WHERE al.policy = $5 AND al.publisher = ads.publisher AND al.tracking_id = ads.tracking_id ` +
+	`AND al.entered = sh.valid_from AND al.dry_run)) ` +
+	`ORDER BY EXISTS (SELECT 1 FROM archiving_log al ` +
+	`WHERE al.policy = $5 AND al.publisher = ads.publisher AND al.tracking_id = ads.tracking_id ` +
+	`AND al.entered = sh.valid_from AND NOT al.dry_run AND al.outcome <> 'done'), sh.valid_from ` +
+	`LIMIT $6`
+
+// Candidates returns up to limit advisories the given policy applies to,
+// the longest in the state first. If skipRecorded is set the advisories
+// already recorded by a dry run of the policy are left out.
+func Candidates(
+	ctx context.Context,
+	db *database.DB,
+	p *config.ArchivingPolicy,
+	skipRecorded bool,
+	limit int,
+) ([]Candidate, error) {
+	var publisher *string
+	if p.Publisher != "" {
+		publisher = &p.Publisher
+	}
+	var candidates []Candidate
+	if err := db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, candidatesSQL,
+				string(p.State), p.After.Seconds(), publisher, skipRecorded, p.Name, limit)
+			var err error
+			candidates, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (Candidate, error) {
+				c := Candidate{Policy: p.Name, Action: p.Action.String()}
+				var state string
+				err := row.Scan(&c.AdvisoryID, &c.Publisher, &c.TrackingID, &state, &c.Entered)
+				c.State = models.Workflow(state)
+				c.Entered = c.Entered.UTC()
+				return c, err
+			})
+			return err
+		}, 0,
+	); err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// process applies a policy to an advisory in its own transaction.
+// In a dry run the transaction is rolled back after the policy is applied.
+// The outcome is recorded in the archiving log. The result reports
+// if the policy was applied. Requested approvals are not rolled back.
+func (a *Archiver) process(
+	ctx context.Context,
+	p *config.ArchivingPolicy,
+	c *Candidate,
+) (bool, error) {
+	var (
+		evs     []eventbus.Event
+		applied bool
+	)
+	if err := a.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tx, err := conn.BeginTx(rctx, pgx.TxOptions{})
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(rctx)
+			var failure error
+			switch p.Action {
+			case config.ArchivingActionArchive:
+				evs, failure = archive(rctx, tx, a.workflow, c)
+			case config.ArchivingActionDelete:
+				evs, failure = remove(rctx, tx, c)
+			}
+			if errors.Is(failure, errGone) {
+				evs = nil
+				return nil
+			}
+			requested := errors.Is(failure, errApproval)
+			if (failure != nil && !requested) || a.cfg.DryRun {
+				evs = nil
+				if err := tx.Rollback(rctx); err != nil {
+					return err
+				}
+				if failure != nil && !requested {
+					slog.Warn("applying archiving policy failed",
+						"policy", p.Name, "publisher", c.Publisher,
+						"tracking_id", c.TrackingID, "error", failure)
+				}
+				applied = failure == nil
+				return record(rctx, conn, c, a.cfg.DryRun, failure)
+			}
+			if err := record(rctx, tx, c, false, failure); err != nil {
+				return err
+			}
+			applied = failure == nil
+			return tx.Commit(rctx)
+		}, 0,
+	); err != nil {
+		return false, err
+	}
+	for _, ev := range evs {
+		a.bus.Publish(ev)
+	}
+	return applied, nil
+}
+
+// archive moves an advisory to the state archived. If the transition
+// needs an approval it is requested once per stay in the state instead
+// and errApproval is returned. The pending approvals of other
+// transitions of an archived advisory are rejected.
+func archive(
+	ctx context.Context,
+	tx pgx.Tx,
+	workflow *config.Workflow,
+	c *Candidate,
+) ([]eventbus.Event, error) {
+	const (
+		findSQL = `SELECT ads.state::text, docs.id, docs.critical ` +
+			`FROM advisories ads ` +
+			`JOIN documents docs ON docs.advisories_id = ads.id AND docs.latest ` +
+			`WHERE ads.id = $1 ` +
+			`FOR UPDATE OF ads`
+		requestedSQL = `SELECT EXISTS(SELECT 1 FROM state_approvals ` +
+			`WHERE advisories_id = $1 AND (status = 'pending' ` +
+			`OR (to_state = 'archived' AND requested >= $2)))`
+		requestSQL = `INSERT INTO state_approvals ` +
+			`(advisories_id, documents_id, from_state, to_state, requester) ` +
+			`VALUES ($1, $2, $3::workflow, 'archived', $4)`
+		rejectSQL = `UPDATE state_approvals ` +
+			`SET (status, approver, decided) = ('rejected', $2, current_timestamp) ` +
+			`WHERE advisories_id = $1 AND status = 'pending' ` +
+			`RETURNING to_state::text`
+		updateSQL = `UPDATE advisories SET state = 'archived' WHERE id = $1`
+		eventSQL  = `INSERT INTO events_log (event, state, actor, documents_id) ` +
+			`VALUES ($1::events, $2::workflow, $3, $4)`
+	)
+	var (
+		state      string
+		documentID int64
+		critical   *float64
+	)
+	switch err := tx.QueryRow(ctx, findSQL, c.AdvisoryID).Scan(&state, &documentID, &critical); {
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, errGone
+	case err != nil:
+		return nil, err
+	case models.Workflow(state) != c.State:
+		return nil, errGone
+	}
+	event := func(typ models.Event, state string) (eventbus.Event, error) {
+		_, err := tx.Exec(ctx, eventSQL, string(typ), state, c.Policy, documentID)
+		return eventbus.Event{
+			Type:       typ,
+			DocumentID: documentID,
+			State:      state,
+			Actor:      c.Policy,
+		}, err
+	}
+	archived := string(models.ArchivedWorkflow)
+
+	if workflow.RequiresApproval(models.ArchivedWorkflow, critical) {
+		var requested bool
+		if err := tx.QueryRow(ctx, requestedSQL, c.AdvisoryID, c.Entered).Scan(&requested); err != nil {
+			return nil, err
+		}
+		if requested {
+			return nil, errApproval
+		}
+		if _, err := tx.Exec(ctx, requestSQL, c.AdvisoryID, documentID, state, c.Policy); err != nil {
+			return nil, err
+		}
+		ev, err := event(models.RequestStateChangeEvent, archived)
+		if err != nil {
+			return nil, err
+		}
+		return []eventbus.Event{ev}, errApproval
+	}
+
+	rows, _ := tx.Query(ctx, rejectSQL, c.AdvisoryID, c.Policy)
+	rejected, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+	evs := make([]eventbus.Event, 0, len(rejected)+1)
+	for _, to := range rejected {
+		ev, err := event(models.RejectStateChangeEvent, to)
+		if err != nil {
+			return nil, err
+		}
+		evs = append(evs, ev)
+	}
+	if _, err := tx.Exec(ctx, updateSQL, c.AdvisoryID); err != nil {
+		return nil, err
+	}
+	ev, err := event(models.StateChangeEvent, archived)
+	if err != nil {
+		return nil, err
+	}
+	return append(evs, ev), nil
+}
+
+// remove deletes the documents of an advisory if they are not under a legal hold.
+// Every deleted document is logged with the policy as actor. As the
+// documents are gone the message of the event tells which one it was.
+func remove(ctx context.Context, tx pgx.Tx, c *Candidate) ([]eventbus.Event, error) {
+	const (
+		stateSQL   = `SELECT state::text FROM advisories WHERE id = $1 FOR UPDATE`
+		idsSQL     = `SELECT id FROM documents WHERE advisories_id = $1`
+		detailsSQL = `SELECT docs.id, ads.publisher, ads.tracking_id, docs.version, docs.tlp ` +
+			`FROM documents docs JOIN advisories ads ON docs.advisories_id = ads.id ` +
+			`WHERE ads.id = $1`
+		deleteSQL = `DELETE FROM documents WHERE advisories_id = $1`
+		eventSQL  = `INSERT INTO events_log (event, actor, message) ` +
+			`VALUES ('delete_document', $1, $2)`
+	)
+	var state string
+	switch err := tx.QueryRow(ctx, stateSQL, c.AdvisoryID).Scan(&state); {
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, errGone
+	case err != nil:
+		return nil, err
+	case models.Workflow(state) != c.State:
+		return nil, errGone
+	}
+	rows, _ := tx.Query(ctx, idsSQL, c.AdvisoryID)
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, err
+	}
+	if err := models.CheckLegalHolds(ctx, tx, ids); err != nil {
+		return nil, err
+	}
+	rows, _ = tx.Query(ctx, detailsSQL, c.AdvisoryID)
+	evs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (eventbus.Event, error) {
+		ev := eventbus.Event{Type: models.DeleteDocumentEvent, Actor: c.Policy}
+		var tlp *string
+		err := row.Scan(&ev.DocumentID, &ev.Publisher, &ev.TrackingID, &ev.Version, &tlp)
+		if tlp != nil {
+			ev.TLP = *tlp
+		}
+		return ev, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, deleteSQL, c.AdvisoryID); err != nil {
+		return nil, err
+	}
+	for i := range evs {
+		ev := &evs[i]
+		msg := fmt.Sprintf("publisher: %s, tracking_id: %s, version: %s",
+			ev.Publisher, ev.TrackingID, ev.Version)
+		if _, err := tx.Exec(ctx, eventSQL, c.Policy, msg); err != nil {
+			return nil, err
+		}
+	}
+	return evs, nil
+}
+
+// execer is implemented by connections and transactions.
+type execer interface {
+	Exec(context.Context, string, ...any) (pgconn.CommandTag, error)
+}
+
+// outcome returns the recorded outcome of applying a policy
+// and the error message to be recorded with it.
+func outcome(failure error) (string, *string) {
+	switch {
+	case failure == nil:
+		return "done", nil
+	case errors.Is(failure, models.ErrLegalHold):
+		return "held", nil
+	case errors.Is(failure, errApproval):
+		return "pending", nil
+	default:
+		msg := failure.Error()
+		return "failed", &msg
+	}
+}
+
+// record records the outcome of applying a policy to an advisory.
+func record(ctx context.Context, db execer, c *Candidate, dryRun bool, failure error) error {
+	const insertSQL = `INSERT INTO archiving_log ` +
+		`(policy, action, dry_run, outcome, publisher, tracking_id, state, entered, error) ` +
+		`VALUES ($1, $2, $3, $4::archiving_outcome, $5, $6, $7::workflow, $8, $9) ` +
+		`ON CONFLICT DO NOTHING`
+	result, msg := outcome(failure)
+	_, err := db.Exec(ctx, insertSQL,
+		c.Policy, c.Action, dryRun, result,
+		c.Publisher, c.TrackingID, string(c.State), c.Entered, msg)
+	return err
+}
@@ -0,0 +1,39 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package archiving
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+func TestOutcome(t *testing.T) {
+	for _, x := range []struct {
+		failure  error
+		expected string
+		msg      string
+	}{
+		{nil, "done", ""},
+		{models.ErrLegalHold, "held", ""},
+		{fmt.Errorf("checking: %w", models.ErrLegalHold), "held", ""},
+		{errApproval, "pending", ""},
+		{errors.New("broken"), "failed", "broken"},
+	} {
+		have, msg := outcome(x.failure)
+		if have != x.expected {
+			t.Errorf("%v: have %q expected %q", x.failure, have, x.expected)
+		}
+		if (msg == nil) != (x.msg == "") || (msg != nil && *msg != x.msg) {
+			t.Errorf("%v: unexpected message %v", x.failure, msg)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package assets mirrors the files referenced by advisories so that
+// the assessments remain complete if the vendors remove linked content.
+package assets
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+	"github.com/ISDuBA/ISDuBA/pkg/database"
+	"github.com/ISDuBA/ISDuBA/pkg/scanner"
+	"github.com/ISDuBA/ISDuBA/pkg/scheduler"
+	"github.com/ISDuBA/ISDuBA/pkg/sources"
+)
+
+// Mirror periodically mirrors the files referenced by the documents.
+// A nil mirror is valid and does nothing.
+type Mirror struct {
+	cfg     *config.Assets
+	db      *database.DB
+	task    *scheduler.Task
+	client  *http.Client
+	scanner *scanner.Scanner
+}
+
+// asset is a fetched file.
+type asset struct {
+	data        []byte
+	contentType string
+	sha256      []byte
+}
+
+// NewMirror returns a new mirror. If mirroring is
+// not enabled in the configuration nil is returned.
+// If a scanner is given the files are scanned for malware
+// and infected files are not mirrored.
+func NewMirror(
+	cfg *config.Config,
+	db *database.DB,
+	tasks *scheduler.Registry,
+	scanner *scanner.Scanner,
+) *Mirror {
+	if !cfg.Assets.Enabled {
+		return nil
+	}
+	client := &http.Client{Transport: cfg.General.Transport()}
+	if cfg.Assets.Timeout > 0 {
+		client.Timeout = cfg.Assets.Timeout
+	}
+	return &Mirror{
+		cfg:     &cfg.Assets,
+		db:      db,
+		client:  client,
+		scanner: scanner,
+		task: tasks.Register("assets_mirror",
+			"Mirrors the files referenced by the advisories.",
+			cfg.Assets.Interval),
+	}
+}
+
+// Run periodically mirrors the assets of the new documents. To be used in a Go routine.
+func (m *Mirror) Run(ctx context.Context) {
+	if m == nil {
+		return
+	}
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if m.db.Available() && !m.task.Paused() {
+				m.scheduledMirror(ctx)
+			}
+		case <-m.task.Triggered():
+			m.scheduledMirror(ctx)
+		}
+	}
+}
+
+// scheduledMirror mirrors and records the run in the scheduler task.
+func (m *Mirror) scheduledMirror(ctx context.Context) {
+	done := m.task.Start()
+	err := m.mirror(ctx)
+	if err == nil {
+		err = m.cleanup(ctx)
+	}
+	done(err)
+}
+
+// reference is a link in a CSAF document.
+type reference struct {
+	URL string `json:"url"`
+}
+
+// references extracts the URLs of the files referenced by a CSAF document.
+// The URLs are returned in document order without duplicates.
+func references(document []byte) ([]string, error) {
+	var doc struct {
+		Document struct {
+			References []reference `json:"references"`
+		} `json:"document"`
+		Vulnerabilities []struct {
+			References   []reference `json:"references"`
+			Remediations []reference `json:"remediations"`
+		} `json:"vulnerabilities"`
+	}
+	if err := json.Unmarshal(document, &doc); err != nil {
+		return nil, err
+	}
+	var (
+		urls []string
+		seen = map[string]bool{}
+		add  = func(refs []reference) {
+			for _, ref := range refs {
+				if ref.URL != "" && !seen[ref.URL] {
+					seen[ref.URL] = true
+					urls = append(urls, ref.URL)
+				}
+			}
+		}
+	)
+	add(doc.Document.References)
+	for i := range doc.Vulnerabilities {
+		add(doc.Vulnerabilities[i].References)
+		add(doc.Vulnerabilities[i].Remediations)
+	}
+	return urls, nil
+}
+
+// allowed filters the URLs which may be mirrored.
+func (m *Mirror) allowed(urls []string) []string {
+	var result []string
+	for _, u := range urls {
+		parsed, err := url.Parse(u)
+		if err != nil ||
+			(parsed.Scheme != "https" && parsed.Scheme != "http") ||
+			!m.cfg.Allowed(parsed.Hostname()) {
+			continue
+		}
+		if result = append(result, u); len(result) >= m.cfg.MaxPerDocument {
+			break
+		}
+	}
+	return result
+}
+
+// mirror mirrors the assets of a batch of documents
+// whose assets are not mirrored yet.
+func (m *Mirror) mirror(ctx context.Context) error {
+	const pendingSQL = `SELECT id, document FROM documents ` +
+		`WHERE assets_mirrored IS NULL ` +
+		`ORDER BY id LIMIT $1`
+	type pending struct {
+		id       int64
+		document []byte
+	}
+	var docs []pending
+	if err := m.db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+		rows, _ := conn.Query(rctx, pendingSQL, m.cfg.BatchSize)
+		var err error
+		docs, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (pending, error) {
+			var p pending
+			err := row.Scan(&p.id, &p.document)
+			return p, err
+		})
+		return err
+	}, 0); err != nil {
+		return fmt.Errorf("fetching pending documents failed: %w", err)
+	}
+	for _, doc := range docs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		urls, err := references(doc.document)
+		if err != nil {
+			slog.Warn("extracting references failed", "document", doc.id, "error", err)
+		}
+		if err := m.mirrorDocument(ctx, doc.id, m.allowed(urls)); err != nil {
+			return fmt.Errorf("mirroring assets of document %d failed: %w", doc.id, err)
+		}
+	}
+	if len(docs) > 0 {
+		slog.Debug("assets mirrored", "documents", len(docs))
+	}
+	return nil
+}
+
+// mirrorDocument fetches the given assets of a document and
+// marks the document as mirrored. Files already mirrored for
+// other documents are not fetched again.
+func (m *Mirror) mirrorDocument(ctx context.Context, docID int64, urls []string) error {
+	const (
+		knownSQL = `SELECT assets_sha256 FROM document_assets ` +
+			`WHERE url = $1 AND assets_sha256 IS NOT NULL ` +
+			`ORDER BY fetched DESC LIMIT 1`
+		assetSQL = `INSERT INTO assets (sha256, size, content_type, data) ` +
+			`VALUES ($1, $2, $3, $4) ` +
+			`ON CONFLICT DO NOTHING`
+		documentAssetSQL = `INSERT INTO document_assets ` +
+			`(documents_id, url, assets_sha256, error) ` +
+			`VALUES ($1, $2, $3, $4) ` +
+			`ON CONFLICT DO NOTHING`
+		mirroredSQL = `UPDATE documents SET assets_mirrored = $1 WHERE id = $2`
+	)
+	type result struct {
+		url   string
+		known []byte
+		asset *asset
+		err   error
+	}
+	// Fetch outside of the transaction as this may take a while.
+	results := make([]result, 0, len(urls))
+	for _, u := range urls {
+		var known []byte
+		if err := m.db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+			return conn.QueryRow(rctx, knownSQL, u).Scan(&known)
+		}, 0); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		if known != nil {
+			results = append(results, result{url: u, known: known})
+			continue
+		}
+		a, err := m.fetch(ctx, u)
+		if err != nil {
+			slog.Debug("fetching asset failed", "url", u, "error", err)
+		}
+		results = append(results, result{url: u, asset: a, err: err})
+	}
+	return m.db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+		tx, err := conn.Begin(rctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(rctx)
+		for _, r := range results {
+			var (
+				hash   []byte
+				errMsg *string
+			)
+			switch {
+			case r.known != nil:
+				hash = r.known
+			case r.err != nil:
+				msg := r.err.Error()
+				errMsg = &msg
+			default:
+				var contentType *string
+				if r.asset.contentType != "" {
+					contentType = &r.asset.contentType
+				}
+				if _, err := tx.Exec(rctx, assetSQL,
+					r.asset.sha256, len(r.asset.data), contentType, r.asset.data,
+				); err != nil {
+					return err
+				}
+				hash = r.asset.sha256
+			}
+			if _, err := tx.Exec(rctx, documentAssetSQL, docID, r.url, hash, errMsg); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(rctx, mirroredSQL, time.Now().UTC(), docID); err != nil {
+			return err
+		}
+		return tx.Commit(rctx)
+	}, 0)
+}
+
+// fetch downloads an asset respecting the size limit and
+// the allowed content types.
+func (m *Mirror) fetch(ctx context.Context, u string) (*asset, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", sources.UserAgent)
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code %d (%s)", resp.StatusCode, resp.Status)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if !m.cfg.ContentTypeAllowed(contentType) {
+		return nil, fmt.Errorf("content type %q is not allowed", contentType)
+	}
+	limit := int64(m.cfg.MaxSize)
+	if resp.ContentLength > limit {
+		return nil, fmt.Errorf("size %d exceeds limit of %d bytes", resp.ContentLength, limit)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("size exceeds limit of %d bytes", limit)
+	}
+	if err := m.scanner.Scan(ctx, u, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(data)
+	return &asset{
+		data:        data,
+		contentType: contentType,
+		sha256:      hash[:],
+	}, nil
+}
+
+// cleanup removes the assets which are not referenced
+// by any document any more.
+func (m *Mirror) cleanup(ctx context.Context) error {
+	const deleteSQL = `DELETE FROM assets WHERE NOT EXISTS (` +
+		`SELECT 1 FROM document_assets WHERE assets_sha256 = assets.sha256)`
+	return m.db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+		_, err := conn.Exec(rctx, deleteSQL)
+		return err
+	}, 0)
+}
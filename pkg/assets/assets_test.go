@@ -0,0 +1,66 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package assets
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+)
+
+func TestReferences(t *testing.T) {
+	const doc = `{
+  "document": {
+    "references": [
+      {"category": "self", "url": "https://example.com/advisory.json"},
+      {"category": "external", "url": "https://example.com/advisory.pdf"}
+    ]
+  },
+  "vulnerabilities": [
+    {
+      "references": [{"url": "https://example.com/advisory.pdf"}],
+      "remediations": [
+        {"category": "vendor_fix", "url": "https://example.com/patch.diff"},
+        {"category": "workaround", "details": "No url"}
+      ]
+    }
+  ]
+}`
+	have, err := references([]byte(doc))
+	if err != nil {
+		t.Fatalf("extracting references failed: %v", err)
+	}
+	expected := []string{
+		"https://example.com/advisory.json",
+		"https://example.com/advisory.pdf",
+		"https://example.com/patch.diff",
+	}
+	if !slices.Equal(have, expected) {
+		t.Errorf("have %q expected %q", have, expected)
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	m := Mirror{cfg: &config.Assets{
+		MaxPerDocument: 2,
+		Domains:        []string{"example.com"},
+	}}
+	have := m.allowed([]string{
+		"ftp://example.com/file",
+		"https://example.org/file",
+		"https://example.com/a",
+		"https://cdn.example.com/b",
+		"https://example.com/c",
+	})
+	expected := []string{"https://example.com/a", "https://cdn.example.com/b"}
+	if !slices.Equal(have, expected) {
+		t.Errorf("have %q expected %q", have, expected)
+	}
+}
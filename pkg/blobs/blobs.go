@@ -0,0 +1,176 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package blobs keeps the original uploads of the documents in a
+// filesystem or an S3 compatible object storage instead of the database.
+package blobs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+	"github.com/ISDuBA/ISDuBA/pkg/database"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+var (
+	// ErrNotFound is returned if there is no blob with a given key.
+	ErrNotFound = errors.New("blob not found")
+	// ErrNotConfigured is returned if a document refers to a blob
+	// but no blob storage is configured.
+	ErrNotConfigured = errors.New("document is kept in blob storage but none is configured")
+)
+
+// backend is a storage to keep the blobs in.
+type backend interface {
+	put(ctx context.Context, key string, data []byte) error
+	// get returns [ErrNotFound] if there is no such blob.
+	get(ctx context.Context, key string) ([]byte, error)
+	// remove does not fail if there is no such blob.
+	remove(ctx context.Context, key string) error
+}
+
+// Store keeps the original uploads of the documents.
+// A nil store keeps them in the database.
+type Store struct {
+	cfg     *config.BlobStorage
+	db      *database.DB
+	backend backend
+}
+
+// NewStore returns a new store. If the original uploads are
+// configured to be kept in the database nil is returned.
+func NewStore(cfg *config.BlobStorage, db *database.DB) (*Store, error) {
+	var (
+		b   backend
+		err error
+	)
+	switch cfg.Type {
+	case config.BlobStorageTypeFilesystem:
+		b, err = newDirectory(cfg.Path)
+	case config.BlobStorageTypeS3:
+		b, err = newS3(cfg)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating %s blob storage failed: %w", cfg.Type, err)
+	}
+	return &Store{cfg: cfg, db: db, backend: b}, nil
+}
+
+// Key returns the key of a blob which is the hex encoded SHA-256 sum of its data.
+func Key(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// validKey checks if the key is a hex encoded SHA-256 sum.
+func validKey(key string) bool {
+	if len(key) != 2*sha256.Size {
+		return false
+	}
+	_, err := hex.DecodeString(key)
+	return err == nil
+}
+
+// Put stores the data and returns its key.
+func (s *Store) Put(ctx context.Context, data []byte) (string, error) {
+	key := Key(data)
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+	if err := s.backend.put(ctx, key, data); err != nil {
+		return "", fmt.Errorf("storing blob %s failed: %w", key, err)
+	}
+	return key, nil
+}
+
+// Get loads the data stored under the given key.
+// The data is checked against the key.
+func (s *Store) Get(ctx context.Context, key string) ([]byte, error) {
+	if !validKey(key) {
+		return nil, fmt.Errorf("invalid blob key %q", key)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+	data, err := s.backend.get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("loading blob %s failed: %w", key, err)
+	}
+	if Key(data) != key {
+		return nil, fmt.Errorf("blob %s is corrupted", key)
+	}
+	return data, nil
+}
+
+// Original returns the original upload of a document. It is either
+// given inline from the database or stored under the key.
+func (s *Store) Original(ctx context.Context, inline []byte, key *string) ([]byte, error) {
+	if key == nil {
+		return inline, nil
+	}
+	if s == nil {
+		return nil, ErrNotConfigured
+	}
+	return s.Get(ctx, *key)
+}
+
+// Offload returns a function to move the original upload of an
+// imported document into the store. With a nil store it does nothing.
+func (s *Store) Offload(raw []byte) models.DocumentStoreChainFunc {
+	return func(ctx context.Context, tx pgx.Tx, docID int64, duplicate bool) error {
+		if s == nil || duplicate {
+			return nil
+		}
+		return s.offloadDocument(ctx, tx, docID, raw)
+	}
+}
+
+// offloadDocument moves the original upload of a document into the store.
+func (s *Store) offloadDocument(ctx context.Context, tx pgx.Tx, docID int64, raw []byte) error {
+	const (
+		// Locks the key against a concurrent removal till the end of the
+		// transaction. If the key is not recorded as deleted the inserted
+		// row is locked so a concurrent deletion of another document with
+		// the same original has to wait for the import to be committed.
+		lockSQL = `INSERT INTO deleted_blobs (key) VALUES ($1) ` +
+			`ON CONFLICT (key) DO UPDATE SET deleted = deleted_blobs.deleted`
+		undeleteSQL = `DELETE FROM deleted_blobs WHERE key = $1`
+		updateSQL   = `UPDATE documents ` +
+			`SET (original, original_key, original_size) = (NULL, $1, $2) ` +
+			`WHERE id = $3`
+		// The document was deleted in the meantime.
+		orphanedSQL = `INSERT INTO deleted_blobs (key) VALUES ($1) ON CONFLICT DO NOTHING`
+	)
+	key := Key(raw)
+	if _, err := tx.Exec(ctx, lockSQL, key); err != nil {
+		return fmt.Errorf("locking blob failed: %w", err)
+	}
+	if _, err := tx.Exec(ctx, undeleteSQL, key); err != nil {
+		return fmt.Errorf("reviving blob failed: %w", err)
+	}
+	if _, err := s.Put(ctx, raw); err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, updateSQL, key, len(raw), docID)
+	if err != nil {
+		return fmt.Errorf("storing blob key failed: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := tx.Exec(ctx, orphanedSQL, key); err != nil {
+			return fmt.Errorf("recording orphaned blob failed: %w", err)
+		}
+	}
+	return nil
+}
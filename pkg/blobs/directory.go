@@ -0,0 +1,78 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package blobs
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// directory keeps the blobs as files below a directory.
+// The files are spread over sub directories named by
+// the first two characters of their keys.
+type directory struct {
+	path string
+}
+
+func newDirectory(path string) (*directory, error) {
+	if err := os.MkdirAll(path, 0o700); err != nil {
+		return nil, err
+	}
+	return &directory{path: path}, nil
+}
+
+func (d *directory) file(key string) string {
+	return filepath.Join(d.path, key[:2], key)
+}
+
+func (d *directory) put(_ context.Context, key string, data []byte) error {
+	fname := d.file(key)
+	dir := filepath.Dir(fname)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	// Write to a temporary file first to not leave partial blobs.
+	f, err := os.CreateTemp(dir, ".blob-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := errors.Join(f.Sync(), f.Close()); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, fname); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func (d *directory) get(_ context.Context, key string) ([]byte, error) {
+	data, err := os.ReadFile(d.file(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (d *directory) remove(_ context.Context, key string) error {
+	if err := os.Remove(d.file(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
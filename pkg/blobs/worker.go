@@ -0,0 +1,157 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package blobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Run moves the original uploads still kept in the database into the
+// store and removes the blobs of deleted documents till the context
+// is canceled.
+func (s *Store) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	var last int64
+	for {
+		if err := s.collect(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("removing blobs of deleted documents failed", "err", err)
+		}
+		var err error
+		if last, err = s.offload(ctx, last); err != nil && ctx.Err() == nil {
+			slog.Error("moving documents to blob storage failed", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// collect removes the blobs of the deleted documents.
+func (s *Store) collect(ctx context.Context) error {
+	const (
+		// Keys which are referenced again are kept. The rows stay locked
+		// till the blobs are removed so imports of the same original
+		// have to wait.
+		selectSQL = `SELECT key FROM deleted_blobs ` +
+			`WHERE NOT EXISTS (SELECT 1 FROM documents WHERE original_key = deleted_blobs.key) ` +
+			`LIMIT $1 ` +
+			`FOR UPDATE SKIP LOCKED`
+		deleteSQL     = `DELETE FROM deleted_blobs WHERE key = ANY($1)`
+		referencedSQL = `DELETE FROM deleted_blobs ` +
+			`WHERE EXISTS (SELECT 1 FROM documents WHERE original_key = deleted_blobs.key)`
+	)
+	for {
+		var n int
+		if err := s.db.Run(
+			ctx,
+			func(rctx context.Context, conn *pgxpool.Conn) error {
+				tx, err := conn.Begin(rctx)
+				if err != nil {
+					return err
+				}
+				defer tx.Rollback(rctx)
+				rows, _ := tx.Query(rctx, selectSQL, s.cfg.BatchSize)
+				keys, err := pgx.CollectRows(rows, pgx.RowTo[string])
+				if err != nil {
+					return err
+				}
+				n = len(keys)
+				removed := make([]string, 0, len(keys))
+				for _, key := range keys {
+					if !validKey(key) {
+						// Not ours to remove.
+						removed = append(removed, key)
+						continue
+					}
+					dctx, cancel := context.WithTimeout(rctx, s.cfg.Timeout)
+					err := s.backend.remove(dctx, key)
+					cancel()
+					if err != nil {
+						slog.Warn("removing blob failed", "key", key, "err", err)
+						continue
+					}
+					removed = append(removed, key)
+				}
+				if _, err := tx.Exec(rctx, deleteSQL, removed); err != nil {
+					return err
+				}
+				if _, err := tx.Exec(rctx, referencedSQL); err != nil {
+					return err
+				}
+				return tx.Commit(rctx)
+			}, 0,
+		); err != nil {
+			return err
+		}
+		if n < s.cfg.BatchSize {
+			return nil
+		}
+	}
+}
+
+// offload moves the original uploads of the documents with ids
+// greater than the given one into the store. Returns the id of
+// the last moved document.
+func (s *Store) offload(ctx context.Context, last int64) (int64, error) {
+	const selectSQL = `SELECT id, original FROM documents ` +
+		`WHERE id > $1 AND original IS NOT NULL ` +
+		`ORDER BY id ` +
+		`LIMIT $2`
+	type inline struct {
+		id       int64
+		original []byte
+	}
+	for {
+		var n int
+		if err := s.db.Run(
+			ctx,
+			func(rctx context.Context, conn *pgxpool.Conn) error {
+				rows, _ := conn.Query(rctx, selectSQL, last, s.cfg.BatchSize)
+				docs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (inline, error) {
+					var doc inline
+					err := row.Scan(&doc.id, &doc.original)
+					return doc, err
+				})
+				if err != nil {
+					return err
+				}
+				n = len(docs)
+				for i := range docs {
+					doc := &docs[i]
+					tx, err := conn.Begin(rctx)
+					if err != nil {
+						return err
+					}
+					if err := s.offloadDocument(rctx, tx, doc.id, doc.original); err != nil {
+						tx.Rollback(rctx)
+						return err
+					}
+					if err := tx.Commit(rctx); err != nil {
+						return err
+					}
+					last = doc.id
+				}
+				return nil
+			}, 0,
+		); err != nil {
+			return last, err
+		}
+		if n < s.cfg.BatchSize {
+			return last, nil
+		}
+	}
+}
@@ -93,3 +93,32 @@ func (c *ExpirationCache[K, V]) SetWithExpiration(k K, v V, expiration time.Dura
 		value:   v,
 	}
 }
+
+// Len returns the number of items in the cache including the expired ones.
+func (c *ExpirationCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// Clear removes all items from the cache.
+func (c *ExpirationCache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.items)
+}
+
+// DeleteFunc removes all items for which del returns true
+// and returns the number of the removed items.
+func (c *ExpirationCache[K, V]) DeleteFunc(del func(K, V) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for k, v := range c.items {
+		if del(k, v.value) {
+			delete(c.items, k)
+			n++
+		}
+	}
+	return n
+}
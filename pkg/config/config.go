@@ -10,11 +10,15 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net"
+	"net/http"
 	"os"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -24,7 +28,9 @@ import (
 	"github.com/gocsaf/csaf/v3/csaf"
 
 	"github.com/ISDuBA/ISDuBA/pkg/ginkeycloak"
+	"github.com/ISDuBA/ISDuBA/pkg/logbuffer"
 	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/requestid"
 )
 
 // General are the overarching settings.
@@ -35,6 +41,7 @@ type General struct {
 	BlockLoopback         bool        `toml:"block_loopback"`
 	BlockedRanges         []IPRange   `toml:"blocked_ranges"`
 	AllowedIPs            []net.IP    `toml:"allowed_ips"`
+	Locale                string      `toml:"locale"`
 }
 
 // Log are the config options for the logging.
@@ -56,11 +63,16 @@ type Keycloak struct {
 
 // Web are the config options for the web interface.
 type Web struct {
-	Host        string `toml:"host"`
-	Port        int    `toml:"port"`
-	GinMode     string `toml:"gin_mode"`
-	Static      string `toml:"static"`
-	ExternalURL string `toml:"external_url"`
+	Host         string `toml:"host"`
+	Port         int    `toml:"port"`
+	GinMode      string `toml:"gin_mode"`
+	Static       string `toml:"static"`
+	Embedded     bool   `toml:"embedded"`
+	ExternalURL  string `toml:"external_url"`
+	BasePath     string `toml:"base_path"`
+	DevEndpoints bool   `toml:"dev_endpoints"`
+	AdminHost    string `toml:"admin_host"`
+	AdminPort    int    `toml:"admin_port"`
 }
 
 // Database are the config options for the database.
@@ -76,13 +88,42 @@ type Database struct {
 	Migrate                 bool          `toml:"migrate"`
 	TerminateAfterMigration bool          `toml:"terminate_after_migration"`
 	MaxQueryDuration        time.Duration `toml:"max_query_duration"`
+	RetryMin                time.Duration `toml:"retry_min"`
+	RetryMax                time.Duration `toml:"retry_max"`
 }
 
 // TempStore are the config options for the temporary document storage.
 type TempStore struct {
-	FilesTotal      int           `toml:"files_total"`
-	FilesUser       int           `toml:"files_user"`
-	StorageDuration time.Duration `toml:"storage_duration"`
+	FilesTotal         int                       `toml:"files_total"`
+	FilesUser          int                       `toml:"files_user"`
+	SizeTotal          HumanSize                 `toml:"size_total"`
+	SizeUser           HumanSize                 `toml:"size_user"`
+	StorageDuration    time.Duration             `toml:"storage_duration"`
+	MaxStorageDuration time.Duration             `toml:"max_storage_duration"`
+	Quotas             map[string]TempStoreQuota `toml:"quotas"`
+}
+
+// TempStoreQuota overrides the per user limits of the
+// temporary document storage for a single user.
+// Zero values fall back to the general limits.
+type TempStoreQuota struct {
+	Files int       `toml:"files" json:"files"`
+	Size  HumanSize `toml:"size" json:"size"`
+}
+
+// UserLimits returns the max number of files and the max size
+// of the stored files for a given user.
+func (ts *TempStore) UserLimits(user string) (files int, size HumanSize) {
+	files, size = ts.FilesUser, ts.SizeUser
+	if q, ok := ts.Quotas[user]; ok {
+		if q.Files > 0 {
+			files = q.Files
+		}
+		if q.Size > 0 {
+			size = q.Size
+		}
+	}
+	return files, size
 }
 
 // Sources are the config options for downloading sources.
@@ -92,6 +133,8 @@ type Sources struct {
 	MaxRatePerSource  float64               `toml:"max_rate_per_source"`
 	OpenPGPCaching    time.Duration         `toml:"openpgp_caching"`
 	FeedRefresh       time.Duration         `toml:"feed_refresh"`
+	CriticalRefresh   time.Duration         `toml:"critical_feed_refresh"`
+	LowRefresh        time.Duration         `toml:"low_feed_refresh"`
 	Timeout           time.Duration         `toml:"timeout"`
 	FeedLogLevel      FeedLogLevel          `tomt:"feed_log_level"`
 	PublishersTLPs    models.PublishersTLPs `toml:"publishers_tlps"`
@@ -104,6 +147,39 @@ type Sources struct {
 	AESKey            string                `toml:"aes_key"`
 	Checking          time.Duration         `toml:"checking"`
 	KeepFeedLogs      time.Duration         `toml:"keep_feed_logs"`
+	KeepFeedMetrics   time.Duration         `toml:"keep_feed_metrics"`
+	PMDProxyRoles     []string              `toml:"pmd_proxy_roles"`
+	PMDProxyDomains   []string              `toml:"pmd_proxy_domains"`
+	ValidationWorkers int                   `toml:"validation_workers"`
+	ImportWorkers     int                   `toml:"import_workers"`
+	StageQueueSize    int                   `toml:"stage_queue_size"`
+	PipelineMemory    HumanSize             `toml:"pipeline_memory"`
+	SpoolThreshold    HumanSize             `toml:"spool_threshold"`
+	DownloadRetries   int                   `toml:"download_retries"`
+	RetryDelay        time.Duration         `toml:"download_retry_delay"`
+	BootWorkers       int                   `toml:"boot_workers"`
+}
+
+// PMDProxyAllowed checks if the PMD proxy is allowed to fetch from the given host.
+// An empty list of domains allows all hosts.
+func (s *Sources) PMDProxyAllowed(host string) bool {
+	return domainAllowed(s.PMDProxyDomains, host)
+}
+
+// domainAllowed checks if the host is one of the domains or a sub domain of them.
+// An empty list of domains allows all hosts.
+func domainAllowed(domains []string, host string) bool {
+	if len(domains) == 0 {
+		return true
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
 }
 
 // ForwardTarget are the config options for the forward target.
@@ -117,19 +193,276 @@ type ForwardTarget struct {
 	Automatic         bool               `toml:"automatic"`
 	Timeout           time.Duration      `toml:"timeout"`
 	Strategy          *ForwarderStrategy `toml:"strategy"`
+	Type              ForwardTargetType  `toml:"type"`
+	Password          *string            `toml:"password"`
+	TLP               string             `toml:"tlp"`
+	Passphrase        *string            `toml:"passphrase"`
+	HealthURL         string             `toml:"health_url"`
+	HealthMethod      string             `toml:"health_method"`
+	Rules             []ForwardRule      `toml:"rule"`
+}
+
+// ForwardRule is a filter of the documents forwarded to a target.
+// A document matches a rule if it meets all the conditions set in it.
+type ForwardRule struct {
+	Namespaces []string          `toml:"namespaces" json:"namespaces,omitempty"`
+	TLPs       []string          `toml:"tlps" json:"tlps,omitempty"`
+	States     []models.Workflow `toml:"states" json:"states,omitempty"`
+	MinCVSS    *float64          `toml:"min_cvss" json:"min_cvss,omitempty"`
 }
 
 // Forwarder are the config options for the document forwarder.
 type Forwarder struct {
-	Targets        []ForwardTarget   `toml:"target"`
-	UpdateInterval time.Duration     `toml:"update_interval"`
-	Strategy       ForwarderStrategy `toml:"strategy"`
+	Targets          []ForwardTarget   `toml:"target"`
+	UpdateInterval   time.Duration     `toml:"update_interval"`
+	Strategy         ForwarderStrategy `toml:"strategy"`
+	HealthInterval   time.Duration     `toml:"health_interval"`
+	FailureThreshold int               `toml:"failure_threshold"`
 }
 
 // Aggregators are the config options for the aggregators.
 type Aggregators struct {
 	Timeout        time.Duration `toml:"timeout"`
 	UpdateInterval time.Duration `toml:"update_interval"`
+	VerifySources  bool          `toml:"verify_sources"`
+	Workers        int           `toml:"workers"`
+	Rate           float64       `toml:"rate"`
+}
+
+// Approval is a rule which state transitions need the approval of a second user.
+type Approval struct {
+	State   models.Workflow `toml:"state"`
+	MinCVSS *float64        `toml:"min_cvss"`
+}
+
+// Workflow are the config options for the advisory workflow.
+type Workflow struct {
+	Approvals        []Approval    `toml:"approval"`
+	ClaimDuration    time.Duration `toml:"claim_duration"`
+	ShareDuration    time.Duration `toml:"share_duration"`
+	ShareMaxDuration time.Duration `toml:"share_max_duration"`
+	ShareTLPs        []models.TLP  `toml:"share_tlps"`
+}
+
+// Scoring are the config options for the weighted scoring of documents.
+type Scoring struct {
+	Weights         models.ScoringWeights  `toml:"weights"`
+	PublishersTrust models.PublishersTrust `toml:"publishers_trust"`
+}
+
+// SearchCache are the config options for caching search results.
+type SearchCache struct {
+	Enabled      bool          `toml:"enabled"`
+	Expiration   time.Duration `toml:"expiration"`
+	MaxEntries   int           `toml:"max_entries"`
+	MaxEntrySize HumanSize     `toml:"max_entry_size"`
+}
+
+// APIUsage are the config options for recording the API usage per user.
+type APIUsage struct {
+	Enabled       bool          `toml:"enabled"`
+	FlushInterval time.Duration `toml:"flush_interval"`
+	Retention     time.Duration `toml:"retention"`
+}
+
+// Assets are the config options for mirroring the files referenced by advisories.
+type Assets struct {
+	Enabled        bool          `toml:"enabled"`
+	Interval       time.Duration `toml:"interval"`
+	BatchSize      int           `toml:"batch_size"`
+	Timeout        time.Duration `toml:"timeout"`
+	MaxSize        HumanSize     `toml:"max_size"`
+	MaxPerDocument int           `toml:"max_per_document"`
+	Domains        []string      `toml:"domains"`
+	ContentTypes   []string      `toml:"content_types"`
+}
+
+// Allowed checks if assets may be mirrored from the given host.
+// An empty list of domains allows all hosts.
+func (a *Assets) Allowed(host string) bool {
+	return domainAllowed(a.Domains, host)
+}
+
+// ContentTypeAllowed checks if assets of the given content type may be mirrored.
+// Parameters of the content type are ignored. An empty list allows all types.
+func (a *Assets) ContentTypeAllowed(contentType string) bool {
+	if len(a.ContentTypes) == 0 {
+		return true
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	for _, ct := range a.ContentTypes {
+		if strings.EqualFold(mediaType, ct) {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscriptions are the config options for the notifications
+// about new documents matching subscribed stored queries.
+type Subscriptions struct {
+	Enabled        bool          `toml:"enabled"`
+	Interval       time.Duration `toml:"interval"`
+	WebhookTimeout time.Duration `toml:"webhook_timeout"`
+	MaxAttempts    int           `toml:"max_attempts"`
+	Retention      time.Duration `toml:"retention"`
+	WebhookDomains []string      `toml:"webhook_domains"`
+}
+
+// WebhookAllowed checks if the webhooks are allowed to post to the given host.
+// Without configured domains no webhooks are allowed.
+func (s *Subscriptions) WebhookAllowed(host string) bool {
+	return len(s.WebhookDomains) > 0 && domainAllowed(s.WebhookDomains, host)
+}
+
+// Receipts are the config options for the receipts sent to the
+// providers of the sources acknowledging the imported advisories.
+type Receipts struct {
+	Enabled     bool          `toml:"enabled"`
+	Interval    time.Duration `toml:"interval"`
+	Timeout     time.Duration `toml:"timeout"`
+	MaxAttempts int           `toml:"max_attempts"`
+	Retention   time.Duration `toml:"retention"`
+	Receiver    string        `toml:"receiver"`
+}
+
+// Banners are the config options for the distribution banners
+// and the provenance notes added to rendered texts and forwarded documents.
+type Banners struct {
+	Templates  bool              `toml:"templates"`
+	Forwarding bool              `toml:"forwarding"`
+	Provenance string            `toml:"provenance"`
+	TLP        map[string]string `toml:"tlp"`
+}
+
+// Texts returns the banner for the TLP label and the provenance note
+// with their placeholders replaced by the given values.
+func (b *Banners) Texts(tlp string, vars map[string]string) (string, string) {
+	return models.ExpandTemplate(models.DistributionBanner(b.TLP, tlp), vars),
+		models.ExpandTemplate(b.Provenance, vars)
+}
+
+// Archive are the config options for the signed exports
+// of the assessments of documents.
+type Archive struct {
+	SigningKey string `toml:"signing_key"`
+	Passphrase string `toml:"passphrase"`
+}
+
+// QueryHistory are the config options for recording
+// the recently executed ad-hoc queries of the users.
+type QueryHistory struct {
+	Enabled    bool          `toml:"enabled"`
+	MaxEntries int           `toml:"max_entries"`
+	Retention  time.Duration `toml:"retention"`
+}
+
+// Scanner are the config options for scanning
+// the uploaded and fetched files for malware.
+type Scanner struct {
+	Type     ScannerType   `toml:"type"`
+	Address  string        `toml:"address"`
+	Timeout  time.Duration `toml:"timeout"`
+	FailOpen bool          `toml:"fail_open"`
+}
+
+// SIEM are the config options for exporting the audit
+// and event history to a security information and event management.
+type SIEM struct {
+	Enabled   bool              `toml:"enabled"`
+	Format    SIEMFormat        `toml:"format"`
+	Address   string            `toml:"address"`
+	Token     string            `toml:"token"`
+	Interval  time.Duration     `toml:"interval"`
+	Timeout   time.Duration     `toml:"timeout"`
+	BatchSize int               `toml:"batch_size"`
+	Fields    map[string]string `toml:"fields"`
+}
+
+// Anomalies are the config options for detecting
+// unusual import volumes of the sources.
+type Anomalies struct {
+	Enabled        bool          `toml:"enabled"`
+	Interval       time.Duration `toml:"interval"`
+	History        int           `toml:"history"`
+	MinHistory     int           `toml:"min_history"`
+	SpikeFactor    float64       `toml:"spike_factor"`
+	MinSpike       int           `toml:"min_spike"`
+	SilenceAverage float64       `toml:"silence_average"`
+}
+
+// TransformationRule is a rule to normalize a field of the imported documents.
+type TransformationRule struct {
+	Name        string `toml:"name"`
+	Publisher   string `toml:"publisher"`
+	Path        string `toml:"path"`
+	Category    string `toml:"category"`
+	Pattern     string `toml:"pattern"`
+	Replacement string `toml:"replacement"`
+}
+
+// Transformations are the config options for normalizing
+// known quirks of the documents at import.
+type Transformations struct {
+	Rules []TransformationRule `toml:"rule"`
+}
+
+// HTTPCache are the config options for the HTTP cache shared by
+// the fetches of provider metadata, keys, feeds and aggregators.
+type HTTPCache struct {
+	Size HumanSize `toml:"size"`
+}
+
+// Recompute are the config options for recomputing the
+// derived fields of the stored documents.
+type Recompute struct {
+	BatchSize int           `toml:"batch_size"`
+	Pause     time.Duration `toml:"pause"`
+}
+
+// ArchivingPolicy archives or deletes the advisories
+// which stayed long enough in a workflow state.
+type ArchivingPolicy struct {
+	Name      string          `toml:"name"`
+	State     models.Workflow `toml:"state"`
+	After     time.Duration   `toml:"after"`
+	Action    ArchivingAction `toml:"action"`
+	Publisher string          `toml:"publisher"`
+}
+
+// Archiving are the config options for the automatic
+// archiving and deletion of advisories.
+type Archiving struct {
+	Enabled   bool              `toml:"enabled"`
+	DryRun    bool              `toml:"dry_run"`
+	Interval  time.Duration     `toml:"interval"`
+	BatchSize int               `toml:"batch_size"`
+	Policies  []ArchivingPolicy `toml:"policy"`
+}
+
+// SLA are the config options for the maximum durations
+// the advisories may stay in the workflow states.
+type SLA struct {
+	Interval time.Duration            `toml:"interval"`
+	Limits   map[string]time.Duration `toml:"limits"`
+}
+
+// BlobStorage are the config options for keeping the original
+// uploads of the documents outside of the database.
+type BlobStorage struct {
+	Type      BlobStorageType `toml:"type"`
+	Path      string          `toml:"path"`
+	Endpoint  string          `toml:"endpoint"`
+	Region    string          `toml:"region"`
+	Bucket    string          `toml:"bucket"`
+	Prefix    string          `toml:"prefix"`
+	AccessKey string          `toml:"access_key"`
+	SecretKey string          `toml:"secret_key"`
+	PathStyle bool            `toml:"path_style"`
+	Timeout   time.Duration   `toml:"timeout"`
+	BatchSize int             `toml:"batch_size"`
+	Interval  time.Duration   `toml:"interval"`
 }
 
 // Client are the config options for the client.
@@ -155,6 +488,25 @@ type Config struct {
 	Client          Client                      `toml:"client"`
 	Forwarder       Forwarder                   `toml:"forwarder"`
 	Aggregators     Aggregators                 `toml:"aggregators"`
+	Workflow        Workflow                    `toml:"workflow"`
+	Scoring         Scoring                     `toml:"scoring"`
+	SearchCache     SearchCache                 `toml:"search_cache"`
+	APIUsage        APIUsage                    `toml:"api_usage"`
+	Assets          Assets                      `toml:"assets"`
+	Subscriptions   Subscriptions               `toml:"subscriptions"`
+	Receipts        Receipts                    `toml:"receipts"`
+	Banners         Banners                     `toml:"banners"`
+	Archive         Archive                     `toml:"archive"`
+	QueryHistory    QueryHistory                `toml:"query_history"`
+	Scanner         Scanner                     `toml:"scanner"`
+	SIEM            SIEM                        `toml:"siem"`
+	Anomalies       Anomalies                   `toml:"anomalies"`
+	Transformations Transformations             `toml:"transformations"`
+	HTTPCache       HTTPCache                   `toml:"http_cache"`
+	Recompute       Recompute                   `toml:"recompute"`
+	BlobStorage     BlobStorage                 `toml:"blob_storage"`
+	SLA             SLA                         `toml:"sla"`
+	Archiving       Archiving                   `toml:"archiving"`
 }
 
 func escape(s string) string {
@@ -194,6 +546,17 @@ func (w *Web) Addr() string {
 	return net.JoinHostPort(w.Host, strconv.Itoa(w.Port))
 }
 
+// AdminListener returns true if the administrative endpoints
+// are served on a separate listener.
+func (w *Web) AdminListener() bool {
+	return w.AdminHost != ""
+}
+
+// AdminAddr returns the address of the admin listener.
+func (w *Web) AdminAddr() string {
+	return net.JoinHostPort(w.AdminHost, strconv.Itoa(w.AdminPort))
+}
+
 // Configure sets up the global web server attributes.
 func (w *Web) Configure() {
 	// If there is a fighting env var, warn the user.
@@ -241,7 +604,9 @@ func (lg *Log) Config() error {
 	} else {
 		handler = slog.NewTextHandler(w, &opts)
 	}
-	logger := slog.New(handler)
+	// Keep the recent warnings and errors for the support bundles.
+	handler = logbuffer.NewHandler(handler, logbuffer.Recent, slog.LevelWarn)
+	logger := slog.New(requestid.NewHandler(handler))
 	slog.SetDefault(logger)
 	return nil
 }
@@ -257,6 +622,7 @@ func Load(file string) (*Config, error) {
 			BlockLoopback:         defaultBlockLoopback,
 			BlockedRanges:         nil,
 			AllowedIPs:            nil,
+			Locale:                defaultLocale,
 		},
 		Log: Log{
 			File:   defaultLogFile,
@@ -272,10 +638,12 @@ func Load(file string) (*Config, error) {
 			FullCertsPath: defaultKeycloakFullCertsPath,
 		},
 		Web: Web{
-			Host:    defaultWebHost,
-			Port:    defaultWebPort,
-			GinMode: defaultWebGinMode,
-			Static:  defaultWebStatic,
+			Host:      defaultWebHost,
+			Port:      defaultWebPort,
+			GinMode:   defaultWebGinMode,
+			Static:    defaultWebStatic,
+			Embedded:  defaultWebEmbedded,
+			AdminPort: defaultWebAdminPort,
 		},
 		Database: Database{
 			Host:                    defaultDatabaseHost,
@@ -289,12 +657,17 @@ func Load(file string) (*Config, error) {
 			Migrate:                 defaultDatabaseMigrate,
 			TerminateAfterMigration: defaultDatabaseTerminateAfterMigration,
 			MaxQueryDuration:        defaultMaxQueryDuration,
+			RetryMin:                defaultDatabaseRetryMin,
+			RetryMax:                defaultDatabaseRetryMax,
 		},
 		PublishersTLPs: defaultPublishersTLPs,
 		TempStore: TempStore{
-			FilesTotal:      defaultTempStorageFilesTotal,
-			FilesUser:       defaultTempStorageFilesUser,
-			StorageDuration: defaultTempStorageDuration,
+			FilesTotal:         defaultTempStorageFilesTotal,
+			FilesUser:          defaultTempStorageFilesUser,
+			SizeTotal:          defaultTempStorageSizeTotal,
+			SizeUser:           defaultTempStorageSizeUser,
+			StorageDuration:    defaultTempStorageDuration,
+			MaxStorageDuration: defaultTempStorageMaxDuration,
 		},
 		Sources: Sources{
 			DownloadSlots:     defaultSourcesDownloadSlots,
@@ -302,6 +675,8 @@ func Load(file string) (*Config, error) {
 			MaxRatePerSource:  defaultSourcesMaxRatePerSlot,
 			OpenPGPCaching:    defaultSourcesOpenPGPCaching,
 			FeedRefresh:       defaultSourcesFeedRefresh,
+			CriticalRefresh:   defaultSourcesCriticalRefresh,
+			LowRefresh:        defaultSourcesLowRefresh,
 			Timeout:           defaultSourcesTimeout,
 			FeedLogLevel:      defaultSourcesFeedLogLevel,
 			FeedImporter:      defaultSourcesFeedImporter,
@@ -313,10 +688,21 @@ func Load(file string) (*Config, error) {
 			DefaultAge:        defaultSourcesAge,
 			Checking:          defaultSourcesChecking,
 			KeepFeedLogs:      defaultKeepFeedLogs,
+			KeepFeedMetrics:   defaultKeepFeedMetrics,
+			ValidationWorkers: defaultSourcesValidationWorkers,
+			ImportWorkers:     defaultSourcesImportWorkers,
+			BootWorkers:       defaultSourcesBootWorkers,
+			StageQueueSize:    defaultSourcesStageQueueSize,
+			PipelineMemory:    defaultSourcesPipelineMemory,
+			SpoolThreshold:    defaultSourcesSpoolThreshold,
+			DownloadRetries:   defaultSourcesDownloadRetries,
+			RetryDelay:        defaultSourcesRetryDelay,
 		},
 		Forwarder: Forwarder{
-			UpdateInterval: defaultForwarderUpdateInterval,
-			Strategy:       defaultForwarderStratgy,
+			UpdateInterval:   defaultForwarderUpdateInterval,
+			Strategy:         defaultForwarderStratgy,
+			HealthInterval:   defaultForwarderHealthInterval,
+			FailureThreshold: defaultForwarderFailureThreshold,
 		},
 		RemoteValidator: csaf.RemoteValidatorOptions{
 			URL:     defaultRemoteValidatorURL,
@@ -332,6 +718,95 @@ func Load(file string) (*Config, error) {
 		Aggregators: Aggregators{
 			Timeout:        defaultAggregatorsTimeout,
 			UpdateInterval: defaultAggregatorsUpdateInterval,
+			Workers:        defaultAggregatorsWorkers,
+		},
+		Workflow: Workflow{
+			ClaimDuration:    defaultWorkflowClaimDuration,
+			ShareDuration:    defaultWorkflowShareDuration,
+			ShareMaxDuration: defaultWorkflowShareMaxDuration,
+		},
+		SearchCache: SearchCache{
+			Enabled:      defaultSearchCacheEnabled,
+			Expiration:   defaultSearchCacheExpiration,
+			MaxEntries:   defaultSearchCacheMaxEntries,
+			MaxEntrySize: defaultSearchCacheMaxEntrySize,
+		},
+		APIUsage: APIUsage{
+			Enabled:       defaultAPIUsageEnabled,
+			FlushInterval: defaultAPIUsageFlushInterval,
+			Retention:     defaultAPIUsageRetention,
+		},
+		Assets: Assets{
+			Enabled:        defaultAssetsEnabled,
+			Interval:       defaultAssetsInterval,
+			BatchSize:      defaultAssetsBatchSize,
+			Timeout:        defaultAssetsTimeout,
+			MaxSize:        defaultAssetsMaxSize,
+			MaxPerDocument: defaultAssetsMaxPerDocument,
+		},
+		Subscriptions: Subscriptions{
+			Enabled:        defaultSubscriptionsEnabled,
+			Interval:       defaultSubscriptionsInterval,
+			WebhookTimeout: defaultSubscriptionsWebhookTimeout,
+			MaxAttempts:    defaultSubscriptionsMaxAttempts,
+			Retention:      defaultSubscriptionsRetention,
+		},
+		Receipts: Receipts{
+			Enabled:     defaultReceiptsEnabled,
+			Interval:    defaultReceiptsInterval,
+			Timeout:     defaultReceiptsTimeout,
+			MaxAttempts: defaultReceiptsMaxAttempts,
+			Retention:   defaultReceiptsRetention,
+		},
+		Banners: Banners{
+			Templates:  defaultBannersTemplates,
+			Forwarding: defaultBannersForwarding,
+		},
+		QueryHistory: QueryHistory{
+			Enabled:    defaultQueryHistoryEnabled,
+			MaxEntries: defaultQueryHistoryMaxEntries,
+			Retention:  defaultQueryHistoryRetention,
+		},
+		Scanner: Scanner{
+			Timeout:  defaultScannerTimeout,
+			FailOpen: defaultScannerFailOpen,
+		},
+		SIEM: SIEM{
+			Enabled:   defaultSIEMEnabled,
+			Interval:  defaultSIEMInterval,
+			Timeout:   defaultSIEMTimeout,
+			BatchSize: defaultSIEMBatchSize,
+		},
+		Anomalies: Anomalies{
+			Enabled:        defaultAnomaliesEnabled,
+			Interval:       defaultAnomaliesInterval,
+			History:        defaultAnomaliesHistory,
+			MinHistory:     defaultAnomaliesMinHistory,
+			SpikeFactor:    defaultAnomaliesSpikeFactor,
+			MinSpike:       defaultAnomaliesMinSpike,
+			SilenceAverage: defaultAnomaliesSilenceAverage,
+		},
+		HTTPCache: HTTPCache{
+			Size: defaultHTTPCacheSize,
+		},
+		Recompute: Recompute{
+			BatchSize: defaultRecomputeBatchSize,
+			Pause:     defaultRecomputePause,
+		},
+		BlobStorage: BlobStorage{
+			Region:    defaultBlobStorageRegion,
+			Timeout:   defaultBlobStorageTimeout,
+			BatchSize: defaultBlobStorageBatchSize,
+			Interval:  defaultBlobStorageInterval,
+		},
+		SLA: SLA{
+			Interval: defaultSLAInterval,
+		},
+		Archiving: Archiving{
+			Enabled:   defaultArchivingEnabled,
+			DryRun:    defaultArchivingDryRun,
+			Interval:  defaultArchivingInterval,
+			BatchSize: defaultArchivingBatchSize,
 		},
 	}
 	if file != "" {
@@ -355,10 +830,489 @@ func Load(file string) (*Config, error) {
 }
 
 func (cfg *Config) validate() error {
-	return cfg.Forwarder.validate()
+	return errors.Join(
+		cfg.General.validate(),
+		cfg.Web.validate(),
+		cfg.Database.validate(),
+		cfg.TempStore.validate(),
+		cfg.Sources.validate(),
+		cfg.Aggregators.validate(),
+		cfg.Forwarder.validate(),
+		cfg.Workflow.validate(),
+		cfg.Scoring.validate(),
+		cfg.SearchCache.validate(),
+		cfg.APIUsage.validate(),
+		cfg.Assets.validate(),
+		cfg.Subscriptions.validate(),
+		cfg.Receipts.validate(),
+		cfg.Banners.validate(),
+		cfg.QueryHistory.validate(),
+		cfg.Scanner.validate(),
+		cfg.SIEM.validate(),
+		cfg.Anomalies.validate(),
+		cfg.Transformations.validate(),
+		cfg.Recompute.validate(),
+		cfg.BlobStorage.validate(),
+		cfg.SLA.validate(),
+		cfg.Archiving.validate())
+}
+
+// localeRe matches simple language tags like "de" or "de-DE".
+var localeRe = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{1,8})*$`)
+
+func (g *General) validate() error {
+	if !localeRe.MatchString(g.Locale) {
+		return fmt.Errorf("general locale %q is not a valid language tag", g.Locale)
+	}
+	return nil
+}
+
+func (w *Web) validate() error {
+	if w.AdminListener() && w.AdminHost == w.Host && w.AdminPort == w.Port {
+		return errors.New("web admin_host and admin_port have to differ from host and port")
+	}
+	// Normalize to "" or "/prefix" without a trailing slash.
+	bp := strings.Trim(strings.TrimSpace(w.BasePath), "/")
+	if bp == "" {
+		w.BasePath = ""
+		return nil
+	}
+	for _, segment := range strings.Split(bp, "/") {
+		if segment == "" || segment == "." || segment == ".." ||
+			strings.ContainsAny(segment, "?#%*:") {
+			return fmt.Errorf("web base_path %q is invalid", w.BasePath)
+		}
+	}
+	w.BasePath = "/" + bp
+	return nil
+}
+
+func (ts *TempStore) validate() error {
+	switch {
+	case ts.FilesTotal < 1:
+		return errors.New("temp_storage files_total has to be at least 1")
+	case ts.FilesUser < 1:
+		return errors.New("temp_storage files_user has to be at least 1")
+	case ts.SizeTotal <= 0:
+		return errors.New("temp_storage size_total has to be positive")
+	case ts.SizeUser <= 0:
+		return errors.New("temp_storage size_user has to be positive")
+	case ts.StorageDuration <= 0:
+		return errors.New("temp_storage storage_duration has to be positive")
+	case ts.MaxStorageDuration < ts.StorageDuration:
+		return errors.New("temp_storage max_storage_duration must not be less than storage_duration")
+	}
+	for user, q := range ts.Quotas {
+		if q.Files < 0 || q.Size < 0 {
+			return fmt.Errorf("temp_storage quota of %q must not be negative", user)
+		}
+	}
+	return nil
+}
+
+func (au *APIUsage) validate() error {
+	if !au.Enabled {
+		return nil
+	}
+	if au.FlushInterval <= 0 {
+		return errors.New("api_usage flush_interval has to be positive")
+	}
+	if au.Retention < 0 {
+		return errors.New("api_usage retention must not be negative")
+	}
+	return nil
+}
+
+func (a *Assets) validate() error {
+	if !a.Enabled {
+		return nil
+	}
+	if a.Interval <= 0 {
+		return errors.New("assets interval has to be positive")
+	}
+	if a.BatchSize < 1 {
+		return errors.New("assets batch_size has to be at least 1")
+	}
+	if a.MaxSize <= 0 {
+		return errors.New("assets max_size has to be positive")
+	}
+	if a.MaxPerDocument < 1 {
+		return errors.New("assets max_per_document has to be at least 1")
+	}
+	return nil
+}
+
+func (s *Subscriptions) validate() error {
+	if !s.Enabled {
+		return nil
+	}
+	if s.Interval <= 0 {
+		return errors.New("subscriptions interval has to be positive")
+	}
+	if s.MaxAttempts < 1 {
+		return errors.New("subscriptions max_attempts has to be at least 1")
+	}
+	if s.Retention < 0 {
+		return errors.New("subscriptions retention must not be negative")
+	}
+	return nil
+}
+
+func (r *Receipts) validate() error {
+	if !r.Enabled {
+		return nil
+	}
+	if r.Interval <= 0 {
+		return errors.New("receipts interval has to be positive")
+	}
+	if r.MaxAttempts < 1 {
+		return errors.New("receipts max_attempts has to be at least 1")
+	}
+	if r.Retention < 0 {
+		return errors.New("receipts retention must not be negative")
+	}
+	return nil
+}
+
+func (r *Recompute) validate() error {
+	if r.BatchSize < 1 {
+		return errors.New("recompute batch_size has to be at least 1")
+	}
+	if r.Pause < 0 {
+		return errors.New("recompute pause must not be negative")
+	}
+	return nil
+}
+
+// Limit returns the maximum duration an advisory may
+// stay in a given state. The zero duration means no limit.
+func (s *SLA) Limit(state models.Workflow) time.Duration {
+	return s.Limits[string(state)]
+}
+
+func (s *SLA) validate() error {
+	if len(s.Limits) == 0 {
+		return nil
+	}
+	if s.Interval <= 0 {
+		return errors.New("sla interval has to be positive")
+	}
+	for state, limit := range s.Limits {
+		switch models.Workflow(state) {
+		case models.NewWorkflow, models.ReadWorkflow,
+			models.AssessingWorkflow, models.ReviewWorkflow:
+		default:
+			return fmt.Errorf("sla limits: %q is not a state with a limit", state)
+		}
+		if limit <= 0 {
+			return fmt.Errorf("sla limit of %q has to be positive", state)
+		}
+	}
+	return nil
+}
+
+func (a *Archiving) validate() error {
+	if a.Interval <= 0 {
+		return errors.New("archiving interval has to be positive")
+	}
+	if a.BatchSize < 1 {
+		return errors.New("archiving batch_size has to be at least 1")
+	}
+	names := make(map[string]struct{}, len(a.Policies))
+	for i := range a.Policies {
+		p := &a.Policies[i]
+		if p.Name == "" {
+			return errors.New("archiving policy is missing a name")
+		}
+		if _, dup := names[p.Name]; dup {
+			return fmt.Errorf("archiving policy %q is defined twice", p.Name)
+		}
+		names[p.Name] = struct{}{}
+		if p.After <= 0 {
+			return fmt.Errorf("archiving policy %q: after has to be positive", p.Name)
+		}
+		// Only transitions of the workflow are allowed.
+		switch p.Action {
+		case ArchivingActionArchive:
+			if len(p.State.TransitionsRoles(models.ArchivedWorkflow)) == 0 {
+				return fmt.Errorf(
+					"archiving policy %q: advisories in state %q cannot be archived", p.Name, p.State)
+			}
+		case ArchivingActionDelete:
+			if p.State != models.DeleteWorkflow &&
+				len(p.State.TransitionsRoles(models.DeleteWorkflow)) == 0 {
+				return fmt.Errorf(
+					"archiving policy %q: advisories in state %q cannot be deleted", p.Name, p.State)
+			}
+		}
+	}
+	return nil
+}
+
+func (bs *BlobStorage) validate() error {
+	switch bs.Type {
+	case BlobStorageTypeDatabase:
+		return nil
+	case BlobStorageTypeFilesystem:
+		if bs.Path == "" {
+			return errors.New("blob_storage path is missing")
+		}
+	case BlobStorageTypeS3:
+		if !strings.HasPrefix(bs.Endpoint, "https://") && !strings.HasPrefix(bs.Endpoint, "http://") {
+			return fmt.Errorf(
+				"blob_storage endpoint %q has to start with 'https://' or 'http://'", bs.Endpoint)
+		}
+		if bs.Bucket == "" {
+			return errors.New("blob_storage bucket is missing")
+		}
+		if bs.AccessKey == "" || bs.SecretKey == "" {
+			return errors.New("blob_storage access_key and secret_key are needed")
+		}
+	}
+	if bs.Timeout <= 0 {
+		return errors.New("blob_storage timeout has to be positive")
+	}
+	if bs.BatchSize < 1 {
+		return errors.New("blob_storage batch_size has to be at least 1")
+	}
+	if bs.Interval <= 0 {
+		return errors.New("blob_storage interval has to be positive")
+	}
+	return nil
+}
+
+func (a *Anomalies) validate() error {
+	if !a.Enabled {
+		return nil
+	}
+	if a.Interval <= 0 {
+		return errors.New("anomalies interval has to be positive")
+	}
+	if a.MinHistory < 1 {
+		return errors.New("anomalies min_history has to be at least 1")
+	}
+	if a.History < a.MinHistory {
+		return errors.New("anomalies history has to be at least min_history")
+	}
+	if a.SpikeFactor <= 1 {
+		return errors.New("anomalies spike_factor has to be greater than 1")
+	}
+	if a.MinSpike < 0 {
+		return errors.New("anomalies min_spike must not be negative")
+	}
+	if a.SilenceAverage <= 0 {
+		return errors.New("anomalies silence_average has to be positive")
+	}
+	return nil
+}
+
+func (t *Transformations) validate() error {
+	names := make(map[string]struct{}, len(t.Rules))
+	for i := range t.Rules {
+		r := &t.Rules[i]
+		if r.Name == "" {
+			return fmt.Errorf("transformation rule %d has no name", i+1)
+		}
+		if _, found := names[r.Name]; found {
+			return fmt.Errorf("transformation rule name %q is not unique", r.Name)
+		}
+		names[r.Name] = struct{}{}
+		if !strings.HasPrefix(r.Path, "/") {
+			return fmt.Errorf("path %q of transformation rule %q has to start with '/'",
+				r.Path, r.Name)
+		}
+		if r.Pattern == "" {
+			return fmt.Errorf("transformation rule %q has no pattern", r.Name)
+		}
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("pattern of transformation rule %q is invalid: %w", r.Name, err)
+		}
+	}
+	return nil
+}
+
+func (s *SIEM) validate() error {
+	if !s.Enabled {
+		return nil
+	}
+	scheme, _, ok := strings.Cut(s.Address, "://")
+	if !ok || !slices.Contains([]string{"udp", "tcp", "tls", "http", "https"}, scheme) {
+		return fmt.Errorf(
+			"siem address %q has to start with 'udp://', 'tcp://', 'tls://', 'http://' or 'https://'",
+			s.Address)
+	}
+	if s.Interval <= 0 {
+		return errors.New("siem interval has to be positive")
+	}
+	if s.BatchSize < 1 {
+		return errors.New("siem batch_size has to be at least 1")
+	}
+	return nil
+}
+
+func (qh *QueryHistory) validate() error {
+	if !qh.Enabled {
+		return nil
+	}
+	if qh.MaxEntries < 1 {
+		return errors.New("query_history max_entries has to be at least 1")
+	}
+	if qh.Retention < 0 {
+		return errors.New("query_history retention must not be negative")
+	}
+	return nil
+}
+
+func (s *Scanner) validate() error {
+	switch s.Type {
+	case ScannerTypeNone:
+		return nil
+	case ScannerTypeClamd:
+		if !strings.HasPrefix(s.Address, "tcp://") && !strings.HasPrefix(s.Address, "unix://") {
+			return fmt.Errorf(
+				"scanner address %q has to start with 'tcp://' or 'unix://'", s.Address)
+		}
+	case ScannerTypeICAP:
+		if !strings.HasPrefix(s.Address, "icap://") {
+			return fmt.Errorf("scanner address %q has to start with 'icap://'", s.Address)
+		}
+	}
+	if s.Timeout <= 0 {
+		return errors.New("scanner timeout has to be positive")
+	}
+	return nil
+}
+
+func (b *Banners) validate() error {
+	for tlp := range b.TLP {
+		switch strings.ToUpper(tlp) {
+		case string(models.TLPWhite), "CLEAR", string(models.TLPGreen),
+			string(models.TLPAmber), "AMBER+STRICT", string(models.TLPRed):
+		default:
+			return fmt.Errorf("banners: unknown TLP label %q", tlp)
+		}
+	}
+	return nil
+}
+
+func (sc *SearchCache) validate() error {
+	if !sc.Enabled {
+		return nil
+	}
+	if sc.Expiration <= 0 {
+		return errors.New("search_cache expiration has to be positive")
+	}
+	if sc.MaxEntries < 1 {
+		return errors.New("search_cache max_entries has to be at least 1")
+	}
+	return nil
+}
+
+func (db *Database) validate() error {
+	if db.RetryMin <= 0 {
+		return errors.New("database retry_min has to be positive")
+	}
+	if db.RetryMax < db.RetryMin {
+		return errors.New("database retry_max must not be less than retry_min")
+	}
+	return nil
+}
+
+func (a *Aggregators) validate() error {
+	if a.Workers < 1 {
+		return errors.New("aggregators workers has to be at least 1")
+	}
+	if a.Rate < 0 {
+		return errors.New("aggregators rate must not be negative")
+	}
+	return nil
+}
+
+func (s *Sources) validate() error {
+	if s.ValidationWorkers < 1 {
+		return errors.New("sources validation_workers has to be at least 1")
+	}
+	if s.ImportWorkers < 1 {
+		return errors.New("sources import_workers has to be at least 1")
+	}
+	if s.BootWorkers < 1 {
+		return errors.New("sources boot_workers has to be at least 1")
+	}
+	if s.StageQueueSize < 0 {
+		return errors.New("sources stage_queue_size must not be negative")
+	}
+	if s.PipelineMemory <= 0 {
+		return errors.New("sources pipeline_memory has to be positive")
+	}
+	if s.DownloadRetries < 0 {
+		return errors.New("sources download_retries must not be negative")
+	}
+	if s.RetryDelay <= 0 {
+		return errors.New("sources download_retry_delay has to be positive")
+	}
+	if s.CriticalRefresh <= 0 {
+		return errors.New("sources critical_feed_refresh has to be positive")
+	}
+	if s.LowRefresh <= 0 {
+		return errors.New("sources low_feed_refresh has to be positive")
+	}
+	return nil
+}
+
+func (w *Workflow) validate() error {
+	if w.ClaimDuration <= 0 {
+		return errors.New("workflow claim_duration has to be positive")
+	}
+	if w.ShareDuration <= 0 {
+		return errors.New("workflow share_duration has to be positive")
+	}
+	if w.ShareMaxDuration < w.ShareDuration {
+		return errors.New("workflow share_max_duration has to be at least share_duration")
+	}
+	for i := range w.Approvals {
+		switch st := w.Approvals[i].State; st {
+		case "":
+			return errors.New("workflow approval is missing a state")
+		case models.NewWorkflow:
+			return fmt.Errorf("workflow approval for state %q is not supported", st)
+		}
+	}
+	return nil
+}
+
+func (s *Scoring) validate() error {
+	if err := s.Weights.Validate(); err != nil {
+		return fmt.Errorf("scoring weights: %w", err)
+	}
+	if err := s.PublishersTrust.Validate(); err != nil {
+		return fmt.Errorf("scoring publishers_trust: %w", err)
+	}
+	return nil
+}
+
+// RequiresApproval checks if a transition into the given state needs
+// the approval of a second user. critical is the highest CVSS score
+// of the advisory, if any.
+func (w *Workflow) RequiresApproval(state models.Workflow, critical *float64) bool {
+	for i := range w.Approvals {
+		ap := &w.Approvals[i]
+		if ap.State != state {
+			continue
+		}
+		if ap.MinCVSS == nil || (critical != nil && *critical >= *ap.MinCVSS) {
+			return true
+		}
+	}
+	return false
 }
 
 func (f *Forwarder) validate() error {
+	if f.HealthInterval <= 0 {
+		return errors.New("forwarder health_interval has to be positive")
+	}
+	if f.FailureThreshold < 1 {
+		return errors.New("forwarder failure_threshold has to be at least 1")
+	}
 	urls := make(map[string]struct{}, len(f.Targets))
 	for i := range f.Targets {
 		url := f.Targets[i].URL
@@ -366,14 +1320,61 @@ func (f *Forwarder) validate() error {
 			return fmt.Errorf("forwarder target URL %q is not unique", url)
 		}
 		urls[url] = struct{}{}
-		for _, header := range f.Targets[i].Header {
-			if _, _, ok := strings.Cut(header, ":"); !ok {
-				return fmt.Errorf(
-					"header %q of forward target %q is missing a ':'",
-					header, url)
-			}
+		if err := f.Targets[i].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks the settings of a forward target.
+func (ft *ForwardTarget) Validate() error {
+	if ft.Type == ForwardTargetTypeCSAFProvider {
+		switch strings.ToLower(ft.TLP) {
+		case "", "csaf", "white", "green", "amber", "red":
+		default:
+			return fmt.Errorf(
+				"tlp %q of forward target %q is invalid", ft.TLP, ft.URL)
+		}
+	}
+	for _, header := range ft.Header {
+		if _, _, ok := strings.Cut(header, ":"); !ok {
+			return fmt.Errorf(
+				"header %q of forward target %q is missing a ':'",
+				header, ft.URL)
+		}
+	}
+	switch strings.ToUpper(ft.HealthMethod) {
+	case "", http.MethodHead, http.MethodOptions, http.MethodGet:
+	default:
+		return fmt.Errorf(
+			"health_method %q of forward target %q is invalid",
+			ft.HealthMethod, ft.URL)
+	}
+	for i := range ft.Rules {
+		if err := ft.Rules[i].validate(); err != nil {
+			return fmt.Errorf("rule %d of forward target %q: %w", i+1, ft.URL, err)
+		}
+	}
+	return nil
+}
+
+func (fr *ForwardRule) validate() error {
+	for _, tlp := range fr.TLPs {
+		switch strings.ToUpper(tlp) {
+		case "WHITE", "CLEAR", "GREEN", "AMBER", "AMBER+STRICT", "RED":
+		default:
+			return fmt.Errorf("tlp %q is invalid", tlp)
 		}
 	}
+	for _, state := range fr.States {
+		if !state.Valid() {
+			return fmt.Errorf("state %q is invalid", state)
+		}
+	}
+	if fr.MinCVSS != nil && (*fr.MinCVSS < 0 || *fr.MinCVSS > 10) {
+		return errors.New("min_cvss has to be between 0 and 10")
+	}
 	return nil
 }
 
@@ -399,6 +1400,18 @@ func (cfg *Config) presetEmptyDefaults() {
 	if cfg.Client.KeycloakURL == "" {
 		cfg.Client.KeycloakURL = cfg.Keycloak.URL
 	}
+	if cfg.Sources.PMDProxyRoles == nil {
+		cfg.Sources.PMDProxyRoles = defaultSourcesPMDProxyRoles
+	}
+	if cfg.Workflow.ShareTLPs == nil {
+		cfg.Workflow.ShareTLPs = defaultWorkflowShareTLPs
+	}
+	if cfg.Scoring.Weights == nil {
+		cfg.Scoring.Weights = defaultScoringWeights
+	}
+	if cfg.Scoring.PublishersTrust == nil {
+		cfg.Scoring.PublishersTrust = defaultScoringPublishersTrust
+	}
 }
 
 func (cfg *Config) fillFromEnv() error {
@@ -412,10 +1425,14 @@ func (cfg *Config) fillFromEnv() error {
 		storeFeedLogLevel      = store(storeFeedLogLevel)
 		storeForwarderStrategy = store(ParseForwarderStrategy)
 		storeFloat64           = store(parseFloat64)
+		storeScannerType       = store(ParseScannerType)
+		storeBlobStorageType   = store(ParseBlobStorageType)
+		storeSIEMFormat        = store(ParseSIEMFormat)
 	)
 	return storeFromEnv(
 		envStore{"ISDUBA_ADVISORY_UPLOAD_LIMIT", storeHumanSize(&cfg.General.AdvisoryUploadLimit)},
 		envStore{"ISDUBA_ANONYMOUS_EVENT_LOGGING", storeBool(&cfg.General.AnonymousEventLogging)},
+		envStore{"ISDUBA_LOCALE", storeString(&cfg.General.Locale)},
 		envStore{"ISDUBA_LOG_FILE", storeString(&cfg.Log.File)},
 		envStore{"ISDUBA_LOG_LEVEL", storeLevel(&cfg.Log.Level)},
 		envStore{"ISDUBA_LOG_JSON", storeBool(&cfg.Log.JSON)},
@@ -429,7 +1446,12 @@ func (cfg *Config) fillFromEnv() error {
 		envStore{"ISDUBA_WEB_PORT", storeInt(&cfg.Web.Port)},
 		envStore{"ISDUBA_WEB_GIN_MODE", storeString(&cfg.Web.GinMode)},
 		envStore{"ISDUBA_WEB_STATIC", storeString(&cfg.Web.Static)},
+		envStore{"ISDUBA_WEB_EMBEDDED", storeBool(&cfg.Web.Embedded)},
 		envStore{"ISDUBA_WEB_EXTERNAL_URL", storeString(&cfg.Web.ExternalURL)},
+		envStore{"ISDUBA_WEB_BASE_PATH", storeString(&cfg.Web.BasePath)},
+		envStore{"ISDUBA_WEB_DEV_ENDPOINTS", storeBool(&cfg.Web.DevEndpoints)},
+		envStore{"ISDUBA_WEB_ADMIN_HOST", storeString(&cfg.Web.AdminHost)},
+		envStore{"ISDUBA_WEB_ADMIN_PORT", storeInt(&cfg.Web.AdminPort)},
 		envStore{"ISDUBA_DB_HOST", storeString(&cfg.Database.Host)},
 		envStore{"ISDUBA_DB_PORT", storeInt(&cfg.Database.Port)},
 		envStore{"ISDUBA_DB_DATABASE", storeString(&cfg.Database.Database)},
@@ -441,14 +1463,21 @@ func (cfg *Config) fillFromEnv() error {
 		envStore{"ISDUBA_DB_MIGRATE", storeBool(&cfg.Database.Migrate)},
 		envStore{"ISDUBA_DB_TERMINATE_AFTER_MIGRATION", storeBool(&cfg.Database.TerminateAfterMigration)},
 		envStore{"ISDUBA_DB_MAX_QUERY_DURATION", storeDuration(&cfg.Database.MaxQueryDuration)},
+		envStore{"ISDUBA_DB_RETRY_MIN", storeDuration(&cfg.Database.RetryMin)},
+		envStore{"ISDUBA_DB_RETRY_MAX", storeDuration(&cfg.Database.RetryMax)},
 		envStore{"ISDUBA_TEMP_STORAGE_FILES_TOTAL", storeInt(&cfg.TempStore.FilesTotal)},
 		envStore{"ISDUBA_TEMP_STORAGE_FILES_USER", storeInt(&cfg.TempStore.FilesUser)},
+		envStore{"ISDUBA_TEMP_STORAGE_SIZE_TOTAL", storeHumanSize(&cfg.TempStore.SizeTotal)},
+		envStore{"ISDUBA_TEMP_STORAGE_SIZE_USER", storeHumanSize(&cfg.TempStore.SizeUser)},
 		envStore{"ISDUBA_TEMP_STORAGE_DURATION", storeDuration(&cfg.TempStore.StorageDuration)},
+		envStore{"ISDUBA_TEMP_STORAGE_MAX_DURATION", storeDuration(&cfg.TempStore.MaxStorageDuration)},
 		envStore{"ISDUBA_SOURCES_DOWNLOAD_SLOTS", storeInt(&cfg.Sources.DownloadSlots)},
 		envStore{"ISDUBA_SOURCES_MAX_SLOTS_PER_SOURCE", storeInt(&cfg.Sources.MaxSlotsPerSource)},
 		envStore{"ISDUBA_SOURCES_MAX_RATE_PER_SOURCE", storeFloat64(&cfg.Sources.MaxRatePerSource)},
 		envStore{"ISDUBA_SOURCES_OPENPGP_CACHING", storeDuration(&cfg.Sources.OpenPGPCaching)},
 		envStore{"ISDUBA_SOURCES_FEED_REFRESH", storeDuration(&cfg.Sources.FeedRefresh)},
+		envStore{"ISDUBA_SOURCES_CRITICAL_FEED_REFRESH", storeDuration(&cfg.Sources.CriticalRefresh)},
+		envStore{"ISDUBA_SOURCES_LOW_FEED_REFRESH", storeDuration(&cfg.Sources.LowRefresh)},
 		envStore{"ISDUBA_SOURCES_FEED_LOG_LEVEL", storeFeedLogLevel(&cfg.Sources.FeedLogLevel)},
 		envStore{"ISDUBA_SOURCES_FEED_IMPORTER", storeString(&cfg.Sources.FeedImporter)},
 		envStore{"ISDUBA_SOURCES_DEFAULT_MESSAGE", storeString(&cfg.Sources.DefaultMessage)},
@@ -460,6 +1489,15 @@ func (cfg *Config) fillFromEnv() error {
 		envStore{"ISDUBA_SOURCES_AES_KEY", storeString(&cfg.Sources.AESKey)},
 		envStore{"ISDUBA_SOURCES_CHECKING", storeDuration(&cfg.Sources.Checking)},
 		envStore{"ISDUBA_SOURCES_KEEP_FEED_LOGS", storeDuration(&cfg.Sources.KeepFeedLogs)},
+		envStore{"ISDUBA_SOURCES_KEEP_FEED_METRICS", storeDuration(&cfg.Sources.KeepFeedMetrics)},
+		envStore{"ISDUBA_SOURCES_VALIDATION_WORKERS", storeInt(&cfg.Sources.ValidationWorkers)},
+		envStore{"ISDUBA_SOURCES_IMPORT_WORKERS", storeInt(&cfg.Sources.ImportWorkers)},
+		envStore{"ISDUBA_SOURCES_BOOT_WORKERS", storeInt(&cfg.Sources.BootWorkers)},
+		envStore{"ISDUBA_SOURCES_STAGE_QUEUE_SIZE", storeInt(&cfg.Sources.StageQueueSize)},
+		envStore{"ISDUBA_SOURCES_PIPELINE_MEMORY", storeHumanSize(&cfg.Sources.PipelineMemory)},
+		envStore{"ISDUBA_SOURCES_SPOOL_THRESHOLD", storeHumanSize(&cfg.Sources.SpoolThreshold)},
+		envStore{"ISDUBA_SOURCES_DOWNLOAD_RETRIES", storeInt(&cfg.Sources.DownloadRetries)},
+		envStore{"ISDUBA_SOURCES_DOWNLOAD_RETRY_DELAY", storeDuration(&cfg.Sources.RetryDelay)},
 		envStore{"ISDUBA_REMOTE_VALIDATOR_URL", storeString(&cfg.RemoteValidator.URL)},
 		envStore{"ISDUBA_REMOTE_VALIDATOR_CACHE", storeString(&cfg.RemoteValidator.Cache)},
 		envStore{"ISDUBA_CLIENT_KEYCLOAK_URL", storeString(&cfg.Client.KeycloakURL)},
@@ -469,7 +1507,85 @@ func (cfg *Config) fillFromEnv() error {
 		envStore{"ISDUBA_CLIENT_IDLE_TIMEOUT", storeDuration(&cfg.Client.IdleTimeout)},
 		envStore{"ISDUBA_FORWARDER_UPDATE_INTERVAL", storeDuration(&cfg.Forwarder.UpdateInterval)},
 		envStore{"ISDUBA_FORWARDER_STRATEGY", storeForwarderStrategy(&cfg.Forwarder.Strategy)},
+		envStore{"ISDUBA_FORWARDER_HEALTH_INTERVAL", storeDuration(&cfg.Forwarder.HealthInterval)},
+		envStore{"ISDUBA_FORWARDER_FAILURE_THRESHOLD", storeInt(&cfg.Forwarder.FailureThreshold)},
 		envStore{"ISDUBA_AGGREGATORS_TIMEOUT", storeDuration(&cfg.Aggregators.Timeout)},
 		envStore{"ISDUBA_AGGREGATORS_UPDATE_INTERVAL", storeDuration(&cfg.Aggregators.UpdateInterval)},
+		envStore{"ISDUBA_AGGREGATORS_VERIFY_SOURCES", storeBool(&cfg.Aggregators.VerifySources)},
+		envStore{"ISDUBA_AGGREGATORS_WORKERS", storeInt(&cfg.Aggregators.Workers)},
+		envStore{"ISDUBA_AGGREGATORS_RATE", storeFloat64(&cfg.Aggregators.Rate)},
+		envStore{"ISDUBA_WORKFLOW_CLAIM_DURATION", storeDuration(&cfg.Workflow.ClaimDuration)},
+		envStore{"ISDUBA_WORKFLOW_SHARE_DURATION", storeDuration(&cfg.Workflow.ShareDuration)},
+		envStore{"ISDUBA_WORKFLOW_SHARE_MAX_DURATION", storeDuration(&cfg.Workflow.ShareMaxDuration)},
+		envStore{"ISDUBA_SEARCH_CACHE_ENABLED", storeBool(&cfg.SearchCache.Enabled)},
+		envStore{"ISDUBA_SEARCH_CACHE_EXPIRATION", storeDuration(&cfg.SearchCache.Expiration)},
+		envStore{"ISDUBA_SEARCH_CACHE_MAX_ENTRIES", storeInt(&cfg.SearchCache.MaxEntries)},
+		envStore{"ISDUBA_SEARCH_CACHE_MAX_ENTRY_SIZE", storeHumanSize(&cfg.SearchCache.MaxEntrySize)},
+		envStore{"ISDUBA_API_USAGE_ENABLED", storeBool(&cfg.APIUsage.Enabled)},
+		envStore{"ISDUBA_API_USAGE_FLUSH_INTERVAL", storeDuration(&cfg.APIUsage.FlushInterval)},
+		envStore{"ISDUBA_API_USAGE_RETENTION", storeDuration(&cfg.APIUsage.Retention)},
+		envStore{"ISDUBA_ASSETS_ENABLED", storeBool(&cfg.Assets.Enabled)},
+		envStore{"ISDUBA_ASSETS_INTERVAL", storeDuration(&cfg.Assets.Interval)},
+		envStore{"ISDUBA_ASSETS_BATCH_SIZE", storeInt(&cfg.Assets.BatchSize)},
+		envStore{"ISDUBA_ASSETS_TIMEOUT", storeDuration(&cfg.Assets.Timeout)},
+		envStore{"ISDUBA_ASSETS_MAX_SIZE", storeHumanSize(&cfg.Assets.MaxSize)},
+		envStore{"ISDUBA_ASSETS_MAX_PER_DOCUMENT", storeInt(&cfg.Assets.MaxPerDocument)},
+		envStore{"ISDUBA_SUBSCRIPTIONS_ENABLED", storeBool(&cfg.Subscriptions.Enabled)},
+		envStore{"ISDUBA_SUBSCRIPTIONS_INTERVAL", storeDuration(&cfg.Subscriptions.Interval)},
+		envStore{"ISDUBA_SUBSCRIPTIONS_WEBHOOK_TIMEOUT", storeDuration(&cfg.Subscriptions.WebhookTimeout)},
+		envStore{"ISDUBA_SUBSCRIPTIONS_MAX_ATTEMPTS", storeInt(&cfg.Subscriptions.MaxAttempts)},
+		envStore{"ISDUBA_SUBSCRIPTIONS_RETENTION", storeDuration(&cfg.Subscriptions.Retention)},
+		envStore{"ISDUBA_RECEIPTS_ENABLED", storeBool(&cfg.Receipts.Enabled)},
+		envStore{"ISDUBA_RECEIPTS_INTERVAL", storeDuration(&cfg.Receipts.Interval)},
+		envStore{"ISDUBA_RECEIPTS_TIMEOUT", storeDuration(&cfg.Receipts.Timeout)},
+		envStore{"ISDUBA_RECEIPTS_MAX_ATTEMPTS", storeInt(&cfg.Receipts.MaxAttempts)},
+		envStore{"ISDUBA_RECEIPTS_RETENTION", storeDuration(&cfg.Receipts.Retention)},
+		envStore{"ISDUBA_RECEIPTS_RECEIVER", storeString(&cfg.Receipts.Receiver)},
+		envStore{"ISDUBA_BANNERS_TEMPLATES", storeBool(&cfg.Banners.Templates)},
+		envStore{"ISDUBA_BANNERS_FORWARDING", storeBool(&cfg.Banners.Forwarding)},
+		envStore{"ISDUBA_BANNERS_PROVENANCE", storeString(&cfg.Banners.Provenance)},
+		envStore{"ISDUBA_ARCHIVE_SIGNING_KEY", storeString(&cfg.Archive.SigningKey)},
+		envStore{"ISDUBA_ARCHIVE_PASSPHRASE", storeString(&cfg.Archive.Passphrase)},
+		envStore{"ISDUBA_QUERY_HISTORY_ENABLED", storeBool(&cfg.QueryHistory.Enabled)},
+		envStore{"ISDUBA_QUERY_HISTORY_MAX_ENTRIES", storeInt(&cfg.QueryHistory.MaxEntries)},
+		envStore{"ISDUBA_QUERY_HISTORY_RETENTION", storeDuration(&cfg.QueryHistory.Retention)},
+		envStore{"ISDUBA_SCANNER_TYPE", storeScannerType(&cfg.Scanner.Type)},
+		envStore{"ISDUBA_SCANNER_ADDRESS", storeString(&cfg.Scanner.Address)},
+		envStore{"ISDUBA_SCANNER_TIMEOUT", storeDuration(&cfg.Scanner.Timeout)},
+		envStore{"ISDUBA_SCANNER_FAIL_OPEN", storeBool(&cfg.Scanner.FailOpen)},
+		envStore{"ISDUBA_SIEM_ENABLED", storeBool(&cfg.SIEM.Enabled)},
+		envStore{"ISDUBA_SIEM_FORMAT", storeSIEMFormat(&cfg.SIEM.Format)},
+		envStore{"ISDUBA_SIEM_ADDRESS", storeString(&cfg.SIEM.Address)},
+		envStore{"ISDUBA_SIEM_TOKEN", storeString(&cfg.SIEM.Token)},
+		envStore{"ISDUBA_SIEM_INTERVAL", storeDuration(&cfg.SIEM.Interval)},
+		envStore{"ISDUBA_SIEM_TIMEOUT", storeDuration(&cfg.SIEM.Timeout)},
+		envStore{"ISDUBA_SIEM_BATCH_SIZE", storeInt(&cfg.SIEM.BatchSize)},
+		envStore{"ISDUBA_ANOMALIES_ENABLED", storeBool(&cfg.Anomalies.Enabled)},
+		envStore{"ISDUBA_ANOMALIES_INTERVAL", storeDuration(&cfg.Anomalies.Interval)},
+		envStore{"ISDUBA_ANOMALIES_HISTORY", storeInt(&cfg.Anomalies.History)},
+		envStore{"ISDUBA_ANOMALIES_MIN_HISTORY", storeInt(&cfg.Anomalies.MinHistory)},
+		envStore{"ISDUBA_ANOMALIES_SPIKE_FACTOR", storeFloat64(&cfg.Anomalies.SpikeFactor)},
+		envStore{"ISDUBA_ANOMALIES_MIN_SPIKE", storeInt(&cfg.Anomalies.MinSpike)},
+		envStore{"ISDUBA_ANOMALIES_SILENCE_AVERAGE", storeFloat64(&cfg.Anomalies.SilenceAverage)},
+		envStore{"ISDUBA_HTTP_CACHE_SIZE", storeHumanSize(&cfg.HTTPCache.Size)},
+		envStore{"ISDUBA_RECOMPUTE_BATCH_SIZE", storeInt(&cfg.Recompute.BatchSize)},
+		envStore{"ISDUBA_RECOMPUTE_PAUSE", storeDuration(&cfg.Recompute.Pause)},
+		envStore{"ISDUBA_BLOB_STORAGE_TYPE", storeBlobStorageType(&cfg.BlobStorage.Type)},
+		envStore{"ISDUBA_BLOB_STORAGE_PATH", storeString(&cfg.BlobStorage.Path)},
+		envStore{"ISDUBA_BLOB_STORAGE_ENDPOINT", storeString(&cfg.BlobStorage.Endpoint)},
+		envStore{"ISDUBA_BLOB_STORAGE_REGION", storeString(&cfg.BlobStorage.Region)},
+		envStore{"ISDUBA_BLOB_STORAGE_BUCKET", storeString(&cfg.BlobStorage.Bucket)},
+		envStore{"ISDUBA_BLOB_STORAGE_PREFIX", storeString(&cfg.BlobStorage.Prefix)},
+		envStore{"ISDUBA_BLOB_STORAGE_ACCESS_KEY", storeString(&cfg.BlobStorage.AccessKey)},
+		envStore{"ISDUBA_BLOB_STORAGE_SECRET_KEY", storeString(&cfg.BlobStorage.SecretKey)},
+		envStore{"ISDUBA_BLOB_STORAGE_PATH_STYLE", storeBool(&cfg.BlobStorage.PathStyle)},
+		envStore{"ISDUBA_BLOB_STORAGE_TIMEOUT", storeDuration(&cfg.BlobStorage.Timeout)},
+		envStore{"ISDUBA_BLOB_STORAGE_BATCH_SIZE", storeInt(&cfg.BlobStorage.BatchSize)},
+		envStore{"ISDUBA_BLOB_STORAGE_INTERVAL", storeDuration(&cfg.BlobStorage.Interval)},
+		envStore{"ISDUBA_SLA_INTERVAL", storeDuration(&cfg.SLA.Interval)},
+		envStore{"ISDUBA_ARCHIVING_ENABLED", storeBool(&cfg.Archiving.Enabled)},
+		envStore{"ISDUBA_ARCHIVING_DRY_RUN", storeBool(&cfg.Archiving.DryRun)},
+		envStore{"ISDUBA_ARCHIVING_INTERVAL", storeDuration(&cfg.Archiving.Interval)},
+		envStore{"ISDUBA_ARCHIVING_BATCH_SIZE", storeInt(&cfg.Archiving.BatchSize)},
 	)
 }
@@ -21,6 +21,7 @@ const DefaultConfigFile = "isduba.toml"
 const (
 	defaultAdvisoryUploadLimit   = 512 * 1024 * 1024
 	defaultAnonymousEventLogging = false
+	defaultLocale                = "en"
 )
 
 var (
@@ -58,10 +59,12 @@ const (
 )
 
 const (
-	defaultWebHost    = "localhost"
-	defaultWebPort    = 8081
-	defaultWebGinMode = "release"
-	defaultWebStatic  = "web"
+	defaultWebHost      = "localhost"
+	defaultWebPort      = 8081
+	defaultWebGinMode   = "release"
+	defaultWebStatic    = "web"
+	defaultWebEmbedded  = true
+	defaultWebAdminPort = 8082
 )
 
 const (
@@ -76,6 +79,8 @@ const (
 	defaultDatabaseMigrate                 = false
 	defaultDatabaseTerminateAfterMigration = true
 	defaultMaxQueryDuration                = 30 * time.Second
+	defaultDatabaseRetryMin                = time.Second
+	defaultDatabaseRetryMax                = 30 * time.Second
 )
 
 var (
@@ -93,9 +98,12 @@ var (
 )
 
 const (
-	defaultTempStorageFilesTotal = 10
-	defaultTempStorageFilesUser  = 2
-	defaultTempStorageDuration   = 30 * time.Minute
+	defaultTempStorageFilesTotal  = 10
+	defaultTempStorageFilesUser   = 2
+	defaultTempStorageSizeTotal   = 256 * 1024 * 1024
+	defaultTempStorageSizeUser    = 64 * 1024 * 1024
+	defaultTempStorageDuration    = 30 * time.Minute
+	defaultTempStorageMaxDuration = 24 * time.Hour
 )
 
 const (
@@ -115,11 +123,29 @@ const (
 	defaultSourcesAge            = 17520 * time.Hour
 	defaultSourcesChecking       = 2 * time.Hour
 	defaultKeepFeedLogs          = 3 * 31 * 24 * time.Hour
+	defaultKeepFeedMetrics       = 13 * 31 * 24 * time.Hour
 )
 
 const (
-	defaultForwarderUpdateInterval = 5 * time.Minute
-	defaultForwarderStratgy        = ForwarderStrategyAll
+	defaultSourcesValidationWorkers = 4
+	defaultSourcesImportWorkers     = 4
+	defaultSourcesBootWorkers       = 8
+	defaultSourcesStageQueueSize    = 16
+	defaultSourcesPipelineMemory    = 256 * 1024 * 1024
+	defaultSourcesSpoolThreshold    = 8 * 1024 * 1024
+	defaultSourcesDownloadRetries   = 3
+	defaultSourcesRetryDelay        = 5 * time.Minute
+	defaultSourcesCriticalRefresh   = 5 * time.Minute
+	defaultSourcesLowRefresh        = time.Hour
+)
+
+var defaultSourcesPMDProxyRoles = []string{string(models.SourceManager)}
+
+const (
+	defaultForwarderUpdateInterval   = 5 * time.Minute
+	defaultForwarderStratgy          = ForwarderStrategyAll
+	defaultForwarderHealthInterval   = time.Minute
+	defaultForwarderFailureThreshold = 3
 )
 
 const (
@@ -139,4 +165,116 @@ const (
 const (
 	defaultAggregatorsTimeout        = 30 * time.Second
 	defaultAggregatorsUpdateInterval = 1 * time.Hour
+	defaultAggregatorsWorkers        = 10
+)
+
+const (
+	defaultWorkflowClaimDuration    = 2 * time.Hour
+	defaultWorkflowShareDuration    = 72 * time.Hour
+	defaultWorkflowShareMaxDuration = 30 * 24 * time.Hour
+)
+
+var defaultWorkflowShareTLPs = []models.TLP{models.TLPWhite, models.TLPGreen}
+
+var (
+	defaultScoringWeights = models.ScoringWeights{
+		models.CVSSFactor: 1,
+	}
+	defaultScoringPublishersTrust = models.PublishersTrust{
+		"*": 1,
+	}
+)
+
+const (
+	defaultSearchCacheEnabled      = false
+	defaultSearchCacheExpiration   = 5 * time.Minute
+	defaultSearchCacheMaxEntries   = 1000
+	defaultSearchCacheMaxEntrySize = 1024 * 1024
+)
+
+const (
+	defaultAPIUsageEnabled       = true
+	defaultAPIUsageFlushInterval = time.Minute
+	defaultAPIUsageRetention     = 400 * 24 * time.Hour
+)
+
+const (
+	defaultAssetsEnabled        = false
+	defaultAssetsInterval       = 10 * time.Minute
+	defaultAssetsBatchSize      = 50
+	defaultAssetsTimeout        = time.Minute
+	defaultAssetsMaxSize        = 10 * 1024 * 1024
+	defaultAssetsMaxPerDocument = 20
+)
+
+const (
+	defaultSubscriptionsEnabled        = true
+	defaultSubscriptionsInterval       = time.Minute
+	defaultSubscriptionsWebhookTimeout = 10 * time.Second
+	defaultSubscriptionsMaxAttempts    = 5
+	defaultSubscriptionsRetention      = 90 * 24 * time.Hour
+)
+
+const (
+	defaultReceiptsEnabled     = true
+	defaultReceiptsInterval    = time.Minute
+	defaultReceiptsTimeout     = 10 * time.Second
+	defaultReceiptsMaxAttempts = 10
+	defaultReceiptsRetention   = 90 * 24 * time.Hour
+)
+
+const (
+	defaultBannersTemplates  = true
+	defaultBannersForwarding = true
+)
+
+const (
+	defaultQueryHistoryEnabled    = true
+	defaultQueryHistoryMaxEntries = 50
+	defaultQueryHistoryRetention  = 30 * 24 * time.Hour
+)
+
+const (
+	defaultScannerTimeout  = 30 * time.Second
+	defaultScannerFailOpen = false
+)
+
+const (
+	defaultSIEMEnabled   = false
+	defaultSIEMInterval  = time.Minute
+	defaultSIEMTimeout   = 10 * time.Second
+	defaultSIEMBatchSize = 500
+)
+
+const (
+	defaultAnomaliesEnabled        = false
+	defaultAnomaliesInterval       = time.Hour
+	defaultAnomaliesHistory        = 28
+	defaultAnomaliesMinHistory     = 7
+	defaultAnomaliesSpikeFactor    = 10.0
+	defaultAnomaliesMinSpike       = 20
+	defaultAnomaliesSilenceAverage = 1.0
+)
+
+const defaultHTTPCacheSize = 64 * 1024 * 1024
+
+const (
+	defaultRecomputeBatchSize = 100
+	defaultRecomputePause     = 100 * time.Millisecond
+)
+
+const (
+	defaultBlobStorageRegion    = "us-east-1"
+	defaultBlobStorageTimeout   = 30 * time.Second
+	defaultBlobStorageBatchSize = 100
+	defaultBlobStorageInterval  = time.Minute
+)
+
+const defaultSLAInterval = 5 * time.Minute
+
+const (
+	defaultArchivingEnabled   = false
+	defaultArchivingDryRun    = true
+	defaultArchivingInterval  = time.Hour
+	defaultArchivingBatchSize = 100
 )
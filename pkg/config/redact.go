@@ -0,0 +1,99 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package config
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// redactedValue replaces the secrets in redacted configurations.
+const redactedValue = "REDACTED"
+
+// redact replaces a set secret.
+func redact(s string) string {
+	if s == "" {
+		return ""
+	}
+	return redactedValue
+}
+
+// redactPtr replaces a set secret given by a pointer.
+func redactPtr(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	r := redactedValue
+	return &r
+}
+
+// redactURL removes the password from an URL.
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	return u.Redacted()
+}
+
+// redactHeaders keeps only the names of "name: value" headers
+// as their values may carry credentials.
+func redactHeaders(headers []string) []string {
+	if headers == nil {
+		return nil
+	}
+	redacted := make([]string, len(headers))
+	for i, header := range headers {
+		name, _, _ := strings.Cut(header, ":")
+		redacted[i] = strings.TrimSpace(name) + ": " + redactedValue
+	}
+	return redacted
+}
+
+// Redacted returns a copy of the configuration with the secrets
+// like passwords, keys and tokens replaced. Secrets which are set
+// are replaced by a marker so that it is still visible if they are
+// configured. The copy is intended to be handed out for troubleshooting.
+func (cfg *Config) Redacted() *Config {
+	r := *cfg
+
+	r.Keycloak.URL = redactURL(r.Keycloak.URL)
+	r.Web.ExternalURL = redactURL(r.Web.ExternalURL)
+
+	r.Database.Password = redact(r.Database.Password)
+	r.Database.AdminPassword = redact(r.Database.AdminPassword)
+
+	r.Sources.AESKey = redact(r.Sources.AESKey)
+
+	r.RemoteValidator.URL = redactURL(r.RemoteValidator.URL)
+
+	r.Forwarder.Targets = slices.Clone(r.Forwarder.Targets)
+	for i := range r.Forwarder.Targets {
+		target := &r.Forwarder.Targets[i]
+		target.URL = redactURL(target.URL)
+		target.HealthURL = redactURL(target.HealthURL)
+		target.ClientPrivateCert = redact(target.ClientPrivateCert)
+		target.Header = redactHeaders(target.Header)
+		target.Password = redactPtr(target.Password)
+		target.Passphrase = redactPtr(target.Passphrase)
+	}
+
+	r.Archive.SigningKey = redact(r.Archive.SigningKey)
+	r.Archive.Passphrase = redact(r.Archive.Passphrase)
+
+	r.Scanner.Address = redactURL(r.Scanner.Address)
+
+	r.SIEM.Address = redactURL(r.SIEM.Address)
+	r.SIEM.Token = redact(r.SIEM.Token)
+
+	r.BlobStorage.SecretKey = redact(r.BlobStorage.SecretKey)
+
+	return &r
+}
@@ -173,3 +173,260 @@ func (fs *ForwarderStrategy) UnmarshalText(b []byte) error {
 	*fs = x
 	return nil
 }
+
+// ForwardTargetType is the kind of endpoint a forwarder sends documents to.
+type ForwardTargetType int
+
+const (
+	// ForwardTargetTypeDefault is the ISDuBA forward request.
+	ForwardTargetTypeDefault ForwardTargetType = iota
+	// ForwardTargetTypeCSAFProvider is the upload API of a gocsaf csaf_provider.
+	ForwardTargetTypeCSAFProvider
+)
+
+// String implements [fmt.Stringer].
+func (ftt ForwardTargetType) String() string {
+	switch ftt {
+	case ForwardTargetTypeDefault:
+		return "default"
+	case ForwardTargetTypeCSAFProvider:
+		return "csaf_provider"
+	default:
+		return fmt.Sprintf("unknown forward target type %d", ftt)
+	}
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (ftt ForwardTargetType) MarshalText() ([]byte, error) {
+	return []byte(ftt.String()), nil
+}
+
+// ParseForwardTargetType parses the type of a forward target.
+func ParseForwardTargetType(s string) (ForwardTargetType, error) {
+	switch strings.ToLower(s) {
+	case "default", "":
+		return ForwardTargetTypeDefault, nil
+	case "csaf_provider":
+		return ForwardTargetTypeCSAFProvider, nil
+	default:
+		return 0, fmt.Errorf("unknown forward target type %q", s)
+	}
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (ftt *ForwardTargetType) UnmarshalText(b []byte) error {
+	x, err := ParseForwardTargetType(string(b))
+	if err != nil {
+		return err
+	}
+	*ftt = x
+	return nil
+}
+
+// ScannerType is the kind of service files are scanned for malware with.
+type ScannerType int
+
+const (
+	// ScannerTypeNone disables the scanning.
+	ScannerTypeNone ScannerType = iota
+	// ScannerTypeClamd is a ClamAV daemon.
+	ScannerTypeClamd
+	// ScannerTypeICAP is an ICAP server (RFC 3507).
+	ScannerTypeICAP
+)
+
+// String implements [fmt.Stringer].
+func (st ScannerType) String() string {
+	switch st {
+	case ScannerTypeNone:
+		return "none"
+	case ScannerTypeClamd:
+		return "clamd"
+	case ScannerTypeICAP:
+		return "icap"
+	default:
+		return fmt.Sprintf("unknown scanner type %d", st)
+	}
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (st ScannerType) MarshalText() ([]byte, error) {
+	return []byte(st.String()), nil
+}
+
+// ParseScannerType parses the type of a malware scanner.
+func ParseScannerType(s string) (ScannerType, error) {
+	switch strings.ToLower(s) {
+	case "none", "":
+		return ScannerTypeNone, nil
+	case "clamd":
+		return ScannerTypeClamd, nil
+	case "icap":
+		return ScannerTypeICAP, nil
+	default:
+		return 0, fmt.Errorf("unknown scanner type %q", s)
+	}
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (st *ScannerType) UnmarshalText(b []byte) error {
+	x, err := ParseScannerType(string(b))
+	if err != nil {
+		return err
+	}
+	*st = x
+	return nil
+}
+
+// BlobStorageType is the kind of storage the original uploads of the documents are kept in.
+type BlobStorageType int
+
+const (
+	// BlobStorageTypeDatabase keeps the original uploads in the database.
+	BlobStorageTypeDatabase BlobStorageType = iota
+	// BlobStorageTypeFilesystem keeps the original uploads in a directory.
+	BlobStorageTypeFilesystem
+	// BlobStorageTypeS3 keeps the original uploads in an S3 compatible object storage.
+	BlobStorageTypeS3
+)
+
+// String implements [fmt.Stringer].
+func (bst BlobStorageType) String() string {
+	switch bst {
+	case BlobStorageTypeDatabase:
+		return "database"
+	case BlobStorageTypeFilesystem:
+		return "filesystem"
+	case BlobStorageTypeS3:
+		return "s3"
+	default:
+		return fmt.Sprintf("unknown blob storage type %d", bst)
+	}
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (bst BlobStorageType) MarshalText() ([]byte, error) {
+	return []byte(bst.String()), nil
+}
+
+// ParseBlobStorageType parses the type of a blob storage.
+func ParseBlobStorageType(s string) (BlobStorageType, error) {
+	switch strings.ToLower(s) {
+	case "database", "":
+		return BlobStorageTypeDatabase, nil
+	case "filesystem":
+		return BlobStorageTypeFilesystem, nil
+	case "s3":
+		return BlobStorageTypeS3, nil
+	default:
+		return 0, fmt.Errorf("unknown blob storage type %q", s)
+	}
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (bst *BlobStorageType) UnmarshalText(b []byte) error {
+	x, err := ParseBlobStorageType(string(b))
+	if err != nil {
+		return err
+	}
+	*bst = x
+	return nil
+}
+
+// SIEMFormat is the format the records are exported to a SIEM with.
+type SIEMFormat int
+
+const (
+	// SIEMFormatCEF is the ArcSight Common Event Format.
+	SIEMFormatCEF SIEMFormat = iota
+	// SIEMFormatLEEF is the QRadar Log Event Extended Format.
+	SIEMFormatLEEF
+)
+
+// String implements [fmt.Stringer].
+func (sf SIEMFormat) String() string {
+	switch sf {
+	case SIEMFormatCEF:
+		return "cef"
+	case SIEMFormatLEEF:
+		return "leef"
+	default:
+		return fmt.Sprintf("unknown SIEM format %d", sf)
+	}
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (sf SIEMFormat) MarshalText() ([]byte, error) {
+	return []byte(sf.String()), nil
+}
+
+// ParseSIEMFormat parses the export format of a SIEM.
+func ParseSIEMFormat(s string) (SIEMFormat, error) {
+	switch strings.ToLower(s) {
+	case "cef", "":
+		return SIEMFormatCEF, nil
+	case "leef":
+		return SIEMFormatLEEF, nil
+	default:
+		return 0, fmt.Errorf("unknown SIEM format %q", s)
+	}
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (sf *SIEMFormat) UnmarshalText(b []byte) error {
+	x, err := ParseSIEMFormat(string(b))
+	if err != nil {
+		return err
+	}
+	*sf = x
+	return nil
+}
+
+// ArchivingAction is what an archiving policy does with the advisories.
+type ArchivingAction int
+
+const (
+	// ArchivingActionArchive moves the advisories to the state archived.
+	ArchivingActionArchive ArchivingAction = iota
+	// ArchivingActionDelete deletes the documents of the advisories.
+	ArchivingActionDelete
+)
+
+// String implements [fmt.Stringer].
+func (aa ArchivingAction) String() string {
+	switch aa {
+	case ArchivingActionArchive:
+		return "archive"
+	case ArchivingActionDelete:
+		return "delete"
+	default:
+		return fmt.Sprintf("unknown archiving action %d", aa)
+	}
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (aa ArchivingAction) MarshalText() ([]byte, error) {
+	return []byte(aa.String()), nil
+}
+
+// ParseArchivingAction parses the action of an archiving policy.
+func ParseArchivingAction(s string) (ArchivingAction, error) {
+	switch strings.ToLower(s) {
+	case "archive", "":
+		return ArchivingActionArchive, nil
+	case "delete":
+		return ArchivingActionDelete, nil
+	default:
+		return 0, fmt.Errorf("unknown archiving action %q", s)
+	}
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (aa *ArchivingAction) UnmarshalText(b []byte) error {
+	x, err := ParseArchivingAction(string(b))
+	if err != nil {
+		return err
+	}
+	*aa = x
+	return nil
+}
@@ -21,7 +21,10 @@ import (
 
 // DB implements the handling with the database connection pool.
 type DB struct {
-	pool *pgxpool.Pool
+	pool     *pgxpool.Pool
+	retryMin time.Duration
+	retryMax time.Duration
+	outage   outage
 }
 
 // NewDB creates a new connection pool.
@@ -30,34 +33,58 @@ func NewDB(ctx context.Context, cfg *config.Database) (*DB, error) {
 	if err != nil {
 		return nil, err
 	}
+	cc.ConnConfig.Tracer = requestTracer{}
 	pool, err := pgxpool.NewWithConfig(ctx, cc)
 	if err != nil {
 		return nil, fmt.Errorf("creating postgresql pool failed: %w", err)
 	}
-	return &DB{pool: pool}, nil
+	return &DB{
+		pool:     pool,
+		retryMin: cfg.RetryMin,
+		retryMax: cfg.RetryMax,
+		outage:   outage{done: make(chan struct{})},
+	}, nil
 }
 
 // Close closes the connection pool.
 func (db *DB) Close(context.Context) {
+	close(db.outage.done)
 	db.pool.Close()
 }
 
 // Run a function hands over a database connection from the connection pool.
 // If the given timeout is not zero the given context will be cancelled
 // after this duration.
+// While the database is unavailable [ErrUnavailable] is returned
+// without calling the function.
+// If the context carries TLP rules (see [WithTLPRules]) the function
+// only sees the documents allowed by them.
 func (db *DB) Run(
 	ctx context.Context,
 	fn func(context.Context, *pgxpool.Conn) error,
 	timeout time.Duration,
+) error {
+	if !db.Available() {
+		return ErrUnavailable
+	}
+	err := db.run(ctx, fn, timeout)
+	db.failed(err)
+	return err
+}
+
+func (db *DB) run(
+	ctx context.Context,
+	fn func(context.Context, *pgxpool.Conn) error,
+	timeout time.Duration,
 ) error {
 	if timeout == 0 {
 		return db.pool.AcquireFunc(ctx, func(conn *pgxpool.Conn) error {
-			return fn(ctx, conn)
+			return restricted(ctx, conn, fn)
 		})
 	}
 	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 	return db.pool.AcquireFunc(timeoutCtx, func(conn *pgxpool.Conn) error {
-		return fn(timeoutCtx, conn)
+		return restricted(timeoutCtx, conn, fn)
 	})
 }
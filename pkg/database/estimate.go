@@ -0,0 +1,52 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// EstimateCount returns the number of rows the query planner
+// estimates for the given statement without executing it.
+// The statement should not aggregate as the estimates of
+// the nodes below a parallel aggregation are per worker.
+func EstimateCount(
+	ctx context.Context,
+	conn *pgxpool.Conn,
+	sql string,
+	args ...any,
+) (int64, error) {
+	var plan []byte
+	if err := conn.QueryRow(ctx, "EXPLAIN (FORMAT JSON) "+sql, args...).Scan(&plan); err != nil {
+		return 0, fmt.Errorf("explaining statement failed: %w", err)
+	}
+	return estimatedRows(plan)
+}
+
+// estimatedRows extracts the estimated number of rows from a JSON query plan.
+func estimatedRows(plan []byte) (int64, error) {
+	var explained []struct {
+		Plan struct {
+			PlanRows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(plan, &explained); err != nil {
+		return 0, fmt.Errorf("decoding query plan failed: %w", err)
+	}
+	if len(explained) == 0 {
+		return 0, errors.New("empty query plan")
+	}
+	return int64(math.Round(explained[0].Plan.PlanRows)), nil
+}
@@ -0,0 +1,36 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package database
+
+import "testing"
+
+func TestEstimatedRows(t *testing.T) {
+	for _, tc := range []struct {
+		plan string
+		want int64
+		err  bool
+	}{
+		{plan: `[{"Plan":{"Node Type":"Seq Scan","Plan Rows":42}}]`, want: 42},
+		{plan: `[{"Plan":{"Node Type":"Gather Merge","Plan Rows":1233.6,` +
+			`"Plans":[{"Node Type":"Sort","Plan Rows":514}]}}]`,
+			want: 1234},
+		{plan: `[]`, err: true},
+		{plan: `{`, err: true},
+	} {
+		got, err := estimatedRows([]byte(tc.plan))
+		switch {
+		case tc.err && err == nil:
+			t.Errorf("%s: expected error", tc.plan)
+		case !tc.err && err != nil:
+			t.Errorf("%s: unexpected error: %v", tc.plan, err)
+		case got != tc.want:
+			t.Errorf("%s: got %d, want %d", tc.plan, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SearchChangesChannel is the notification channel the database
+// uses to report changes which affect search results.
+const SearchChangesChannel = "search_changes"
+
+// SearchReadsChangesChannel is the notification channel the database
+// uses to report changed read states. The payload is the user.
+const SearchReadsChangesChannel = "search_reads_changes"
+
+// DocumentsImportedChannel is the notification channel the database
+// uses to report newly imported documents.
+const DocumentsImportedChannel = "documents_imported"
+
+// GroupTLPsChangesChannel is the notification channel the database
+// uses to report changes of the TLP rules of the groups.
+const GroupTLPsChangesChannel = "group_tlps_changes"
+
+// Listen calls fn for every notification on the given channel.
+// As notifications may be missed while the connection is lost
+// fn is also called after re-establishing the connection.
+// To be used in a Go routine.
+func (db *DB) Listen(ctx context.Context, channel string, fn func()) {
+	db.ListenPayloads(ctx, channel, func(string) { fn() }, fn)
+}
+
+// ListenPayloads calls fn with the payload of every notification
+// on the given channel. As notifications may be missed while the
+// connection is lost lost is called after re-establishing the connection.
+// To be used in a Go routine.
+func (db *DB) ListenPayloads(
+	ctx context.Context,
+	channel string,
+	fn func(payload string),
+	lost func(),
+) {
+	for {
+		if err := db.WaitAvailable(ctx); err != nil {
+			return
+		}
+		err := db.listen(ctx, channel, fn)
+		if ctx.Err() != nil {
+			return
+		}
+		db.failed(err)
+		if !IsConnectionError(err) {
+			slog.Error("listening for notifications failed",
+				"channel", channel, "error", err)
+			// Don't spin on persistent errors.
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(db.retryMax):
+			}
+		}
+		lost()
+	}
+}
+
+func (db *DB) listen(ctx context.Context, channel string, fn func(string)) error {
+	pconn, err := db.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// A listening connection must not be given back to the pool.
+	conn := pconn.Hijack()
+	defer conn.Close(context.Background())
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return err
+	}
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		fn(n.Payload)
+	}
+}
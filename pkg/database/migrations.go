@@ -87,6 +87,19 @@ func CheckMigrations(ctx context.Context, cfg *config.Database) (bool, error) {
 	return doMigrations(ctx, cfg, version, migs)
 }
 
+// LatestMigration returns the version of the newest migration
+// known to the application.
+func LatestMigration() (int64, error) {
+	migs, err := listMigrations()
+	if err != nil {
+		return -1, err
+	}
+	if len(migs) == 0 {
+		return -1, errors.New("no migrations found")
+	}
+	return migs[len(migs)-1].version, nil
+}
+
 func doMigrations(
 	ctx context.Context,
 	cfg *config.Database,
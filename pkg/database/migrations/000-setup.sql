@@ -27,11 +27,18 @@ CREATE TABLE advisories (
     -- comments and recent are cached here for performance.
     comments     int NOT NULL DEFAULT 0,
     recent       timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- The latest current_release_date of the documents.
+    upstream_changed timestamptz,
+    -- The title best matching the configured locale, see update_localized_title().
+    localized_title text,
+    -- The languages of the documents.
+    languages    text,
     CHECK(comments >= 0),
     UNIQUE(tracking_id, publisher)
 );
 
 CREATE INDEX advisories_recent_idx ON advisories(recent);
+CREATE INDEX advisories_upstream_changed_idx ON advisories(upstream_changed);
 
 CREATE FUNCTION utc_timestamp(text) RETURNS timestamp with time zone AS $$
     SELECT $1::timestamp with time zone AT time zone 'utc'
@@ -53,6 +60,20 @@ CREATE FUNCTION max_cvss3_score(jsonb) RETURNS float AS $$
             $1, '$.vulnerabilities[*].scores[*].cvss_v3.baseScore') a
 $$ LANGUAGE SQL IMMUTABLE;
 
+CREATE TYPE severity AS ENUM (
+    'none', 'low', 'medium', 'high', 'critical');
+
+-- severity_bucket follows the qualitative rating scale of CVSS v3.
+CREATE FUNCTION severity_bucket(float) RETURNS severity AS $$
+    SELECT CASE
+        WHEN $1 >= 9.0 THEN 'critical'
+        WHEN $1 >= 7.0 THEN 'high'
+        WHEN $1 >= 4.0 THEN 'medium'
+        WHEN $1 > 0.0  THEN 'low'
+        ELSE 'none'
+    END::severity
+$$ LANGUAGE SQL IMMUTABLE;
+
 CREATE FUNCTION first_four_cves(jsonb) RETURNS jsonb AS $$
     SELECT jsonb_path_query_array(
         $1, '$.vulnerabilities[0 to 3]."cve"')
@@ -88,6 +109,8 @@ CREATE TABLE documents (
                 GENERATED ALWAYS AS (document #>> '{document,distribution,tlp,label}') STORED,
     title       text
                 GENERATED ALWAYS AS (document #>> '{document,title}') STORED,
+    lang        text
+                GENERATED ALWAYS AS (document #>> '{document,lang}') STORED,
     rev_history_length int
                 GENERATED ALWAYS AS (revision_history_length(document)) STORED,
     cvss_v2_score float
@@ -97,17 +120,38 @@ CREATE TABLE documents (
     critical    float
                 GENERATED ALWAYS AS (
                     coalesce(max_cvss3_score(document), max_cvss2_score(document))) STORED,
+    severity    severity
+                GENERATED ALWAYS AS (severity_bucket(
+                    coalesce(max_cvss3_score(document), max_cvss2_score(document)))) STORED,
     four_cves   jsonb
                 GENERATED ALWAYS AS (first_four_cves(document)) STORED,
+    -- Weighted score, see document_score()
+    score       float,
+    -- Human readable texts, see extract_document_texts()
+    notes           text COMPRESSION lz4,
+    remediations    text COMPRESSION lz4,
+    acknowledgments text COMPRESSION lz4,
     -- The data
     document    jsonb COMPRESSION lz4 NOT NULL,  -- see documents_texts comment
-    original    bytea COMPRESSION lz4 NOT NULL,
+    -- The original upload, either here or in the blob storage
+    original      bytea COMPRESSION lz4,
+    original_key  varchar,
+    original_size int,
     signature   bytea COMPRESSION lz4,
     filename    varchar,
-
-    UNIQUE (advisories_id, version, rev_history_length, tracking_status)
+    -- Time the referenced files were mirrored, see document_assets
+    assets_mirrored timestamptz,
+    -- Publisher of the advisory for the row level security, see set_advisory_publisher()
+    advisory_publisher text NOT NULL,
+
+    UNIQUE (advisories_id, version, rev_history_length, tracking_status),
+    CONSTRAINT documents_original_check
+        CHECK (original IS NOT NULL OR original_key IS NOT NULL)
 );
 
+CREATE INDEX documents_original_key_idx ON documents (original_key)
+    WHERE original_key IS NOT NULL;
+
 CREATE UNIQUE INDEX only_one_latest_constraint
     ON documents (advisories_id)
     WHERE latest;
@@ -179,15 +223,105 @@ CREATE TRIGGER insert_document
     ON documents
     FOR EACH ROW EXECUTE FUNCTION update_advisory();
 
+-- set_advisory_publisher keeps the publisher of the advisory with the document.
+CREATE FUNCTION set_advisory_publisher() RETURNS trigger AS $$
+    BEGIN
+        NEW.advisory_publisher := (
+            SELECT publisher FROM advisories WHERE id = NEW.advisories_id);
+        RETURN NEW;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER set_advisory_publisher
+    BEFORE INSERT OR UPDATE OF advisories_id ON documents
+    FOR EACH ROW EXECUTE FUNCTION set_advisory_publisher();
+
 CREATE TRIGGER delete_document AFTER DELETE ON documents
     FOR EACH ROW EXECUTE FUNCTION delete_advisory();
 
+CREATE FUNCTION update_upstream_changed() RETURNS trigger AS $$
+    BEGIN
+        IF TG_OP = 'INSERT' THEN
+            UPDATE advisories
+                SET upstream_changed = greatest(upstream_changed, NEW.current_release_date)
+                WHERE id = NEW.advisories_id;
+        ELSE
+            UPDATE advisories SET upstream_changed = (
+                SELECT max(current_release_date) FROM documents
+                WHERE documents.advisories_id = OLD.advisories_id)
+                WHERE id = OLD.advisories_id;
+        END IF;
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER documents_upstream_changed
+    AFTER INSERT OR DELETE ON documents
+    FOR EACH ROW EXECUTE FUNCTION update_upstream_changed();
+
+-- instance_locale is the locale configured for this instance.
+CREATE TABLE instance_locale (
+    singleton bool PRIMARY KEY DEFAULT TRUE CHECK(singleton),
+    locale    text NOT NULL
+);
+
+INSERT INTO instance_locale (locale) VALUES ('en');
+
+-- lang_rank ranks how well a language tag matches a locale.
+-- Lower is better. English is the fallback.
+CREATE FUNCTION lang_rank(lang text, locale text) RETURNS int AS $$
+    SELECT CASE
+        WHEN lower(lang) = lower(locale) THEN 0
+        WHEN lower(split_part(lang, '-', 1)) = lower(split_part(locale, '-', 1)) THEN 1
+        WHEN lower(split_part(lang, '-', 1)) = 'en' THEN 2
+        ELSE 3
+    END
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- update_localized_title selects the title of the document of an advisory
+-- matching the configured locale best. Ties are broken in favor
+-- of the latest document. It also collects the languages of the documents.
+CREATE FUNCTION update_localized_title(adv_id int) RETURNS void AS $$
+    UPDATE advisories SET
+        localized_title = (
+            SELECT title FROM documents
+            WHERE advisories_id = adv_id
+            ORDER BY lang_rank(lang, (SELECT locale FROM instance_locale)),
+                latest DESC NULLS LAST,
+                current_release_date DESC NULLS LAST,
+                id DESC
+            LIMIT 1),
+        languages = (
+            SELECT string_agg(DISTINCT lang, ',' ORDER BY lang) FROM documents
+            WHERE advisories_id = adv_id AND coalesce(lang, '') <> '')
+    WHERE id = adv_id
+$$ LANGUAGE SQL;
+
+CREATE FUNCTION documents_localized_title() RETURNS trigger AS $$
+    BEGIN
+        IF TG_OP = 'INSERT' THEN
+            PERFORM update_localized_title(NEW.advisories_id);
+        ELSE
+            PERFORM update_localized_title(OLD.advisories_id);
+        END IF;
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER documents_localized_title
+    AFTER INSERT OR DELETE ON documents
+    FOR EACH ROW EXECUTE FUNCTION documents_localized_title();
+
 CREATE INDEX current_release_date_idx ON documents (current_release_date);
 CREATE INDEX initial_release_date_idx ON documents (initial_release_date);
 
 CREATE INDEX documents_cvss2_idx ON documents(coalesce(cvss_v2_score, '0'::double precision) DESC);
 CREATE INDEX documents_cvss3_idx ON documents(coalesce(cvss_v3_score, '0'::double precision) DESC);
 CREATE INDEX documents_critical_idx ON documents(coalesce(critical, '0'::double precision) DESC);
+CREATE INDEX documents_severity_idx ON documents(severity);
+CREATE INDEX documents_score_idx ON documents(coalesce(score, '0'::double precision) DESC);
+CREATE INDEX documents_assets_pending_idx ON documents(id)
+    WHERE assets_mirrored IS NULL;
 
 CREATE TABLE unique_texts (
     id  int PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
@@ -215,11 +349,16 @@ CREATE TABLE comments (
     documents_id int NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
     time         timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
     commentator  varchar NOT NULL,
-    message      varchar(10000)
+    message      varchar(10000),
+    -- Full text search without stemming as the analysts
+    -- write in different languages.
+    ts           tsvector GENERATED ALWAYS AS (
+        to_tsvector('simple', coalesce(message, ''))) STORED
 );
 
 CREATE INDEX ON comments(documents_id);
 CREATE INDEX ON comments USING gin(message gin_trgm_ops);
+CREATE INDEX comments_ts_idx ON comments USING gin(ts);
 
 -- Trigger functions to update cached comment count per advisory.
 CREATE FUNCTION incr_comments() RETURNS trigger AS $$
@@ -260,7 +399,11 @@ CREATE TYPE events AS ENUM (
     'import_document', 'delete_document',
     'state_change',
     'add_sscv', 'change_sscv', 'delete_sscv',
-    'add_comment', 'change_comment', 'delete_comment'
+    'add_comment', 'change_comment', 'delete_comment',
+    'request_state_change', 'approve_state_change', 'reject_state_change',
+    'share_document', 'revoke_share', 'access_share',
+    'legal_hold', 'release_legal_hold',
+    'sla_escalation'
 );
 
 CREATE TABLE events_log (
@@ -269,11 +412,31 @@ CREATE TABLE events_log (
     time         timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
     actor        varchar,
     documents_id int REFERENCES documents(id) ON DELETE SET NULL,
-    comments_id  int REFERENCES comments(id) ON DELETE SET NULL
+    comments_id  int REFERENCES comments(id) ON DELETE SET NULL,
+    id           bigint GENERATED BY DEFAULT AS IDENTITY,
+    ts           tsvector,
+    -- message describes events whose documents are gone.
+    message      text
 );
 
 CREATE INDEX events_log_time_idx ON events_log(time);
+CREATE UNIQUE INDEX events_log_id_idx ON events_log(id);
 CREATE INDEX ON events_log(documents_id);
+CREATE INDEX events_log_ts_idx ON events_log USING gin(ts);
+
+-- The enums are not immutable so the text vector for the
+-- full text search is maintained by a trigger.
+CREATE FUNCTION events_log_ts() RETURNS trigger AS $$
+    BEGIN
+        NEW.ts = to_tsvector('simple', concat_ws(' ', NEW.event, NEW.state, NEW.actor, NEW.message));
+        RETURN NEW;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER events_log_ts
+    BEFORE INSERT OR UPDATE OF event, state, actor, message
+    ON events_log
+    FOR EACH ROW EXECUTE FUNCTION events_log_ts();
 
 -- Trigger to update cached recent value of advisory.
 CREATE FUNCTION upd_recent() RETURNS trigger AS $$
@@ -293,6 +456,88 @@ CREATE TRIGGER update_recent
     ON events_log
     FOR EACH ROW EXECUTE FUNCTION upd_recent();
 
+--
+-- teams
+--
+
+-- teams are the organizational units of the users.
+-- The members of a team are taken from its Keycloak group.
+-- leads are the users allowed to assign the work of the team.
+CREATE TABLE teams (
+    id          int       PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    name        varchar   NOT NULL UNIQUE,
+    description varchar,
+    group_name  varchar   UNIQUE,
+    parent_id   int       REFERENCES teams(id) ON DELETE SET NULL,
+    leads       varchar[] NOT NULL DEFAULT '{}',
+    CHECK(name <> ''),
+    CHECK(group_name <> ''),
+    CHECK(parent_id <> id)
+);
+
+-- team_members are the users of the teams as seen
+-- in their Keycloak groups on their last login.
+CREATE TABLE team_members (
+    teams_id int         NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    "user"   varchar     NOT NULL,
+    seen     timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (teams_id, "user")
+);
+
+CREATE INDEX team_members_user_idx ON team_members("user");
+
+-- team_subtree returns a team and all its sub-teams.
+CREATE FUNCTION team_subtree(team int) RETURNS SETOF int AS $$
+    WITH RECURSIVE tree(id) AS (
+        SELECT team
+        UNION
+        SELECT teams.id FROM teams JOIN tree ON teams.parent_id = tree.id
+    ) SELECT id FROM tree
+$$ LANGUAGE SQL STABLE;
+
+-- user_teams returns the teams a user is a member of
+-- together with all their parent teams.
+CREATE FUNCTION user_teams(member varchar) RETURNS SETOF int AS $$
+    WITH RECURSIVE tree(id) AS (
+        SELECT teams_id FROM team_members WHERE "user" = member
+        UNION
+        SELECT teams.parent_id FROM teams JOIN tree ON teams.id = tree.id
+        WHERE teams.parent_id IS NOT NULL
+    ) SELECT id FROM tree
+$$ LANGUAGE SQL STABLE;
+
+-- led_teams returns the teams led by a user together with all their sub-teams.
+CREATE FUNCTION led_teams(leader varchar) RETURNS SETOF int AS $$
+    WITH RECURSIVE tree(id) AS (
+        SELECT id FROM teams WHERE leader = ANY(leads)
+        UNION
+        SELECT teams.id FROM teams JOIN tree ON teams.parent_id = tree.id
+    ) SELECT id FROM tree
+$$ LANGUAGE SQL STABLE;
+
+-- tlp_allowed checks if the documents of a publisher with a TLP label
+-- are visible under the TLP rules of a user. The rules map publishers
+-- to lists of TLP labels. The publisher '*' stands for all publishers
+-- which are not listed. Documents without a TLP label are not visible.
+CREATE FUNCTION tlp_allowed(publisher text, tlp text, rules jsonb) RETURNS boolean AS $$
+    SELECT coalesce(
+        CASE WHEN rules ? publisher THEN rules -> publisher ELSE rules -> '*' END ? tlp,
+        false)
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
+
+-- group_tlps are the TLP levels of the publishers the members
+-- of a Keycloak group may see in addition to their own rules.
+-- '*' as publisher matches all publishers without own rules.
+CREATE TABLE group_tlps (
+    group_name varchar NOT NULL,
+    publisher  varchar NOT NULL,
+    tlps       varchar[] NOT NULL DEFAULT '{}',
+    PRIMARY KEY (group_name, publisher),
+    CHECK(group_name <> ''),
+    CHECK(publisher <> ''),
+    CHECK(tlps <@ '{WHITE,GREEN,AMBER,RED}'::varchar[])
+);
+
 --
 -- user defined stored queries
 --
@@ -318,43 +563,390 @@ CREATE TABLE stored_queries (
     dashboard     bool                NOT NULL DEFAULT FALSE,
     default_query bool                NOT NULL DEFAULT FALSE,
     role        stored_queries_roles,
+    -- teams_id shares the query with the members of the team and its sub-teams.
+    teams_id    int                 REFERENCES teams(id) ON DELETE SET NULL,
+    -- shared_roles shares the query with all users having one of the roles.
+    shared_roles varchar[]          NOT NULL DEFAULT '{}',
+    -- cloned_from is the shared query this query was cloned from.
+    cloned_from  int                REFERENCES stored_queries(id) ON DELETE SET NULL,
     CHECK(name <> ''),
     UNIQUE (definer, name),
     UNIQUE (definer, num) DEFERRABLE INITIALLY DEFERRED
 );
 
+CREATE INDEX stored_queries_shared_roles_idx ON stored_queries USING gin(shared_roles);
+
 CREATE TABLE default_query_exclusion (
     "user"  text    NOT NULL,
     id      int     NOT NULL REFERENCES stored_queries(id) ON DELETE CASCADE,
     UNIQUE ("user", id)
 );
 
+--
+-- team defaults
+--
+-- team_defaults are the defaults per Keycloak group.
+CREATE TABLE team_defaults (
+    group_name varchar PRIMARY KEY,
+    landing    varchar,
+    CHECK(group_name <> '')
+);
+
+-- team_queries are the stored queries copied to the new members of a group.
+CREATE TABLE team_queries (
+    group_name        varchar NOT NULL REFERENCES team_defaults(group_name) ON DELETE CASCADE,
+    stored_queries_id int     NOT NULL REFERENCES stored_queries(id) ON DELETE CASCADE,
+    position          int     NOT NULL,
+    PRIMARY KEY (group_name, stored_queries_id)
+);
+
+-- provisioned_users are the users which already got their team defaults.
+CREATE TABLE provisioned_users (
+    "user"      varchar     PRIMARY KEY,
+    provisioned timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- four-eyes approvals of state changes
+--
+CREATE TYPE approval_status AS ENUM (
+    'pending', 'approved', 'rejected');
+
+CREATE TABLE state_approvals (
+    id            int             PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    advisories_id int             NOT NULL REFERENCES advisories(id) ON DELETE CASCADE,
+    documents_id  int             REFERENCES documents(id) ON DELETE SET NULL,
+    from_state    workflow        NOT NULL,
+    to_state      workflow        NOT NULL,
+    requester     varchar         NOT NULL,
+    requested     timestamptz     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    approver      varchar,
+    decided       timestamptz,
+    status        approval_status NOT NULL DEFAULT 'pending'
+);
+
+CREATE UNIQUE INDEX ON state_approvals(advisories_id) WHERE status = 'pending';
+
+--
+-- claims of documents to avoid concurrent assessments
+--
+CREATE TABLE document_claims (
+    documents_id int         PRIMARY KEY REFERENCES documents(id) ON DELETE CASCADE,
+    claimant     varchar     NOT NULL,
+    claimed      timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires      timestamptz NOT NULL
+);
+
+--
+-- per user reading status of documents
+--
+CREATE TABLE document_reads (
+    documents_id int         NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    "user"       varchar     NOT NULL,
+    time         timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (documents_id, "user")
+);
+
+--
+-- expiring links to share single documents
+--
+CREATE TABLE shared_links (
+    id            int         PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    token_hash    bytea       NOT NULL UNIQUE,
+    documents_id  int         NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    creator       varchar     NOT NULL,
+    created       timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires       timestamptz NOT NULL,
+    revoked       timestamptz,
+    accesses      int         NOT NULL DEFAULT 0,
+    last_access   timestamptz
+);
+
+CREATE INDEX ON shared_links(documents_id);
+
+--
+-- templates for comments and assessments
+--
+CREATE TYPE text_templates_kind AS ENUM (
+    'comment', 'assessment'
+);
+
+CREATE TABLE text_templates (
+    id          int                  PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    kind        text_templates_kind  NOT NULL DEFAULT 'comment',
+    definer     varchar              NOT NULL,
+    global      boolean              NOT NULL DEFAULT FALSE,
+    -- teams_id shares the template with the members of the team and its sub-teams.
+    teams_id    int                  REFERENCES teams(id) ON DELETE SET NULL,
+    name        varchar              NOT NULL,
+    description varchar,
+    body        varchar(10000)       NOT NULL,
+    CHECK(name <> ''),
+    UNIQUE (definer, name)
+);
+
+--
+-- weighted scoring of documents
+--
+CREATE TYPE scoring_factor AS ENUM (
+    'cvss', 'epss', 'kev', 'assets', 'publisher'
+);
+
+CREATE TABLE scoring_weights (
+    factor scoring_factor PRIMARY KEY,
+    weight float          NOT NULL CHECK (weight >= 0)
+);
+
+-- '*' is the trust of all publishers not explicitly stated.
+CREATE TABLE publishers_trust (
+    publisher text  PRIMARY KEY,
+    trust     float NOT NULL CHECK (trust BETWEEN 0 AND 1)
+);
+
+CREATE TABLE cve_scores (
+    cve  text    PRIMARY KEY,
+    epss float   CHECK (epss BETWEEN 0 AND 1),
+    kev  boolean NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE asset_matches (
+    documents_id int   PRIMARY KEY REFERENCES documents(id) ON DELETE CASCADE,
+    relevance    float NOT NULL CHECK (relevance BETWEEN 0 AND 1)
+);
+
+-- document_score calculates the weighted score of a document in the range of 0 to 10.
+CREATE FUNCTION document_score(doc jsonb, adv_id int, doc_id int) RETURNS float AS $$
+    WITH weights AS (
+        SELECT
+            coalesce(sum(weight) FILTER (WHERE factor = 'cvss'), 0)      AS cvss,
+            coalesce(sum(weight) FILTER (WHERE factor = 'epss'), 0)      AS epss,
+            coalesce(sum(weight) FILTER (WHERE factor = 'kev'), 0)       AS kev,
+            coalesce(sum(weight) FILTER (WHERE factor = 'assets'), 0)    AS assets,
+            coalesce(sum(weight) FILTER (WHERE factor = 'publisher'), 0) AS publisher,
+            sum(weight) AS total
+        FROM scoring_weights
+    ),
+    cves AS (
+        SELECT jsonb_array_elements_text(
+            jsonb_path_query_array(doc, '$.vulnerabilities."cve"')) AS cve
+    ),
+    factors AS (
+        SELECT
+            coalesce(max_cvss3_score(doc), max_cvss2_score(doc), 0) / 10 AS cvss,
+            coalesce((SELECT max(epss) FROM cve_scores JOIN cves USING (cve)), 0) AS epss,
+            coalesce((SELECT bool_or(kev) FROM cve_scores JOIN cves USING (cve)), FALSE)::int AS kev,
+            coalesce((SELECT relevance FROM asset_matches WHERE documents_id = doc_id), 0) AS assets,
+            coalesce(
+                (SELECT trust FROM publishers_trust JOIN advisories
+                    ON publishers_trust.publisher = advisories.publisher
+                    WHERE advisories.id = adv_id),
+                (SELECT trust FROM publishers_trust WHERE publisher = '*'),
+                0) AS publisher
+    )
+    SELECT CASE WHEN w.total > 0 THEN
+        10 * (w.cvss * f.cvss + w.epss * f.epss + w.kev * f.kev +
+              w.assets * f.assets + w.publisher * f.publisher) / w.total
+        END
+    FROM weights w, factors f
+$$ LANGUAGE SQL STABLE;
+
+CREATE FUNCTION score_document() RETURNS trigger AS $$
+    BEGIN
+        NEW.score = document_score(NEW.document, NEW.advisories_id, NEW.id);
+        RETURN NEW;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER score_document_trigger BEFORE INSERT
+    ON documents
+    FOR EACH ROW
+    EXECUTE FUNCTION score_document();
+
+--
+-- searchable notes, remediations and acknowledgments
+--
+-- original_json parses the original upload of a document.
+-- In contrast to documents.document the strings are not replaced
+-- by references to unique_texts.
+CREATE FUNCTION original_json(orig bytea) RETURNS jsonb AS $$
+    BEGIN
+        RETURN convert_from(orig, 'UTF8')::jsonb;
+    EXCEPTION WHEN others THEN
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- extract_texts joins the strings found by the given JSON paths in order.
+CREATE FUNCTION extract_texts(doc jsonb, paths text[]) RETURNS text AS $$
+    SELECT string_agg(q.v #>> '{}', E'\n' ORDER BY ps.p, q.n)
+    FROM unnest(paths) WITH ORDINALITY AS ps(path, p),
+        LATERAL jsonb_path_query(doc, ps.path::jsonpath) WITH ORDINALITY AS q(v, n)
+    WHERE jsonb_typeof(q.v) = 'string'
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE FUNCTION document_notes(doc jsonb) RETURNS text AS $$
+    SELECT extract_texts(doc, ARRAY[
+        '$.document.notes[*].text',
+        '$.vulnerabilities[*].notes[*].text'])
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE FUNCTION document_remediations(doc jsonb) RETURNS text AS $$
+    SELECT extract_texts(doc, ARRAY[
+        '$.vulnerabilities[*].remediations[*].details'])
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE FUNCTION document_acknowledgments(doc jsonb) RETURNS text AS $$
+    SELECT extract_texts(doc, ARRAY[
+        '$.document.acknowledgments[*].names[*]',
+        '$.document.acknowledgments[*].organization',
+        '$.document.acknowledgments[*].summary',
+        '$.vulnerabilities[*].acknowledgments[*].names[*]',
+        '$.vulnerabilities[*].acknowledgments[*].organization',
+        '$.vulnerabilities[*].acknowledgments[*].summary'])
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE FUNCTION extract_document_texts() RETURNS trigger AS $$
+    DECLARE
+        doc jsonb := original_json(NEW.original);
+    BEGIN
+        NEW.notes           = document_notes(doc);
+        NEW.remediations    = document_remediations(doc);
+        NEW.acknowledgments = document_acknowledgments(doc);
+        RETURN NEW;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER extract_document_texts_trigger BEFORE INSERT
+    ON documents
+    FOR EACH ROW
+    EXECUTE FUNCTION extract_document_texts();
+
+CREATE INDEX ON documents USING gin(notes gin_trgm_ops);
+CREATE INDEX ON documents USING gin(remediations gin_trgm_ops);
+CREATE INDEX ON documents USING gin(acknowledgments gin_trgm_ops);
+
+--
+-- validation sweeps
+--
+CREATE TYPE sweep_status AS ENUM (
+    'running', 'finished', 'canceled', 'failed');
+
+CREATE TABLE validation_sweeps (
+    id          int         PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    creator     varchar,
+    started     timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished    timestamptz,
+    status      sweep_status NOT NULL DEFAULT 'running',
+    publisher   text,
+    since       timestamptz,
+    until       timestamptz,
+    remote      boolean     NOT NULL DEFAULT FALSE,
+    checked     int         NOT NULL DEFAULT 0,
+    regressions int         NOT NULL DEFAULT 0,
+    fixed       int         NOT NULL DEFAULT 0,
+    error       text
+);
+
+-- validation_results only stores the documents where
+-- the verdict of the sweep differs from the recorded one.
+CREATE TABLE validation_results (
+    validation_sweeps_id   int     NOT NULL REFERENCES validation_sweeps(id) ON DELETE CASCADE,
+    documents_id           int     NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    schema_failed          boolean NOT NULL,
+    remote_failed          boolean,
+    previous_schema_failed boolean NOT NULL,
+    previous_remote_failed boolean,
+    regression             boolean NOT NULL,
+    messages               text[],
+    PRIMARY KEY (validation_sweeps_id, documents_id)
+);
+
+-- recompute_jobs recompute the derived fields of the stored documents
+-- in batches. last_id is the highest id of the documents processed
+-- so far. Interrupted jobs are resumed from there.
+CREATE TABLE recompute_jobs (
+    id        int          PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    creator   varchar,
+    started   timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished  timestamptz,
+    status    sweep_status NOT NULL DEFAULT 'running',
+    fields    varchar[]    NOT NULL,
+    publisher text,
+    total     int          NOT NULL DEFAULT 0,
+    processed int          NOT NULL DEFAULT 0,
+    skipped   int          NOT NULL DEFAULT 0,
+    last_id   int          NOT NULL DEFAULT 0,
+    error     text
+);
+
+--
+-- blob storage
+--
+-- deleted_blobs are the keys of the original uploads of deleted
+-- documents which have to be removed from the blob storage.
+CREATE TABLE deleted_blobs (
+    key     varchar     PRIMARY KEY,
+    deleted timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE FUNCTION record_deleted_blob() RETURNS trigger AS $$
+    BEGIN
+        INSERT INTO deleted_blobs (key) VALUES (OLD.original_key)
+            ON CONFLICT (key) DO UPDATE SET deleted = CURRENT_TIMESTAMP;
+        RETURN OLD;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER record_deleted_blob_trigger AFTER DELETE
+    ON documents
+    FOR EACH ROW
+    WHEN (OLD.original_key IS NOT NULL)
+    EXECUTE FUNCTION record_deleted_blob();
+
 ---
 --- sources
 ---
+CREATE TYPE source_priority AS ENUM (
+    'critical', 'normal', 'low');
+
 CREATE TABLE sources (
     id                     int     PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
     name                   varchar NOT NULL UNIQUE,
     url                    varchar NOT NULL,
     active                 bool    NOT NULL DEFAULT FALSE,
+    shadow                 bool    NOT NULL DEFAULT FALSE,
+    priority               source_priority NOT NULL DEFAULT 'normal',
     rate                   float,
     slots                  int,
+    weight                 int,
     headers                text[],
     strict_mode            bool,
     secure                 bool,
     signature_check        bool,
     age                    interval,
     ignore_patterns        text[],
+    fetch_windows          text[],
     client_cert_public     bytea,
     client_cert_private    bytea,
     client_cert_passphrase bytea,
+    oauth2_token_url       varchar,
+    oauth2_client_id       varchar,
+    oauth2_client_secret   bytea,
+    oauth2_scopes          text[],
+    basic_auth_user        varchar,
+    basic_auth_password    bytea,
+    tls_ca_bundle          bytea,
+    tls_pinned_certs       bytea,
+    receipt_url            varchar,
     checksum               bytea,
     checksum_ack           timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP - '1 second'::interval,
     checksum_updated       timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
     CHECK(name <> ''),
     CHECK(url <> ''),
     CHECK(rate IS NULL OR rate > 0.0),
-    CHECK(slots IS NULL OR slots >= 1)
+    CHECK(slots IS NULL OR slots >= 1),
+    CHECK(weight IS NULL OR weight BETWEEN 1 AND 100),
+    CHECK(receipt_url <> '')
 );
 
 CREATE TYPE feed_logs_level AS ENUM (
@@ -465,6 +1057,7 @@ CREATE TABLE ssvc_history (
     change_number bigint      NOT NULL,
     documents_id  integer     NOT NULL                            REFERENCES documents(id) ON DELETE CASCADE,
     ssvc          text,
+    reason        text,
 
     PRIMARY KEY (documents_id, change_number)
 );
@@ -544,6 +1137,172 @@ BEFORE INSERT ON ssvc_history
 FOR EACH ROW
 EXECUTE FUNCTION generate_ssvc_change_number();
 
+-- The SSVC history is an audit trail. Its records are never modified.
+CREATE FUNCTION reject_ssvc_history_update()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    RAISE EXCEPTION 'ssvc_history records are immutable';
+END;
+$$;
+
+CREATE TRIGGER ssvc_history_immutable
+BEFORE UPDATE ON ssvc_history
+FOR EACH ROW EXECUTE FUNCTION reject_ssvc_history_update();
+
+--
+-- history of workflow states and SSVC values
+--
+
+-- state_history keeps the workflow states of the advisories
+-- with the time ranges they were valid.
+CREATE TABLE state_history (
+    advisories_id int         NOT NULL REFERENCES advisories(id) ON DELETE CASCADE,
+    state         workflow    NOT NULL,
+    valid_from    timestamptz NOT NULL,
+    valid_to      timestamptz,
+    CHECK(valid_to IS NULL OR valid_to >= valid_from)
+);
+
+CREATE INDEX state_history_advisories_id_idx ON state_history(advisories_id, valid_from);
+CREATE UNIQUE INDEX state_history_open_idx ON state_history(advisories_id) WHERE valid_to IS NULL;
+
+-- sla_escalations are the stays of the advisories in workflow
+-- states which took longer than the configured limits.
+-- Every stay in a state is escalated only once.
+CREATE TABLE sla_escalations (
+    id            int         PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    advisories_id int         NOT NULL REFERENCES advisories(id) ON DELETE CASCADE,
+    state         workflow    NOT NULL,
+    entered       timestamptz NOT NULL,
+    deadline      timestamptz NOT NULL,
+    escalated     timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (advisories_id, state, entered)
+);
+
+CREATE INDEX sla_escalations_escalated_idx ON sla_escalations(escalated);
+
+CREATE TYPE archiving_outcome AS ENUM (
+    'done', 'held', 'failed', 'pending');
+
+-- archiving_log records what the archiving policies did with the
+-- advisories. Dry runs record what the policies would have done.
+-- Every stay of an advisory in a state is recorded once per outcome.
+CREATE TABLE archiving_log (
+    id          bigint            PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    time        timestamptz       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    policy      varchar           NOT NULL,
+    action      varchar           NOT NULL,
+    dry_run     boolean           NOT NULL,
+    outcome     archiving_outcome NOT NULL,
+    publisher   text              NOT NULL,
+    tracking_id text              NOT NULL,
+    state       workflow          NOT NULL,
+    entered     timestamptz       NOT NULL,
+    error       varchar,
+    UNIQUE (policy, publisher, tracking_id, entered, dry_run, outcome)
+);
+
+CREATE INDEX archiving_log_time_idx ON archiving_log(time);
+
+-- Trigger to record the changes of the workflow states.
+CREATE FUNCTION record_state_history() RETURNS trigger AS $$
+    BEGIN
+        UPDATE state_history SET valid_to = current_timestamp
+            WHERE advisories_id = NEW.id AND valid_to IS NULL;
+        INSERT INTO state_history (advisories_id, state, valid_from)
+            VALUES (NEW.id, NEW.state, current_timestamp);
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER advisories_state_history
+    AFTER INSERT ON advisories
+    FOR EACH ROW EXECUTE FUNCTION record_state_history();
+
+CREATE TRIGGER advisories_state_history_update
+    AFTER UPDATE OF state ON advisories
+    FOR EACH ROW
+    WHEN (OLD.state IS DISTINCT FROM NEW.state)
+    EXECUTE FUNCTION record_state_history();
+
+-- ssvc_ranges are the SSVC values of the documents
+-- with the time ranges they were valid.
+CREATE VIEW ssvc_ranges AS
+    SELECT documents_id, ssvc, actor, changedate AS valid_from,
+        LEAD(changedate) OVER (
+            PARTITION BY documents_id
+            ORDER BY changedate, change_number) AS valid_to
+    FROM ssvc_history;
+
+-- Notify the search caches about changes of the searched data.
+-- Identical notifications within a transaction are only delivered once.
+CREATE FUNCTION notify_search_changes() RETURNS trigger AS $$
+    BEGIN
+        PERFORM pg_notify('search_changes', '');
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER advisories_search_changes
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON advisories
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_search_changes();
+
+CREATE TRIGGER documents_search_changes
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON documents
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_search_changes();
+
+CREATE TRIGGER comments_search_changes
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON comments
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_search_changes();
+
+CREATE TRIGGER events_log_search_changes
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON events_log
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_search_changes();
+
+CREATE TRIGGER ssvc_history_search_changes
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON ssvc_history
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_search_changes();
+
+CREATE TRIGGER state_history_search_changes
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON state_history
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_search_changes();
+
+-- Changed read states only affect the searches of the users.
+-- Identical notifications within a transaction are only delivered once.
+CREATE FUNCTION notify_search_reads_changes() RETURNS trigger AS $$
+    BEGIN
+        IF TG_OP IN ('UPDATE', 'DELETE') THEN
+            PERFORM pg_notify('search_reads_changes', OLD."user");
+        END IF;
+        IF TG_OP IN ('INSERT', 'UPDATE') THEN
+            PERFORM pg_notify('search_reads_changes', NEW."user");
+        END IF;
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER document_reads_search_reads_changes
+    AFTER INSERT OR UPDATE OF documents_id, "user" OR DELETE ON document_reads
+    FOR EACH ROW EXECUTE FUNCTION notify_search_reads_changes();
+
+CREATE TRIGGER document_reads_search_changes
+    AFTER TRUNCATE ON document_reads
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_search_changes();
+
+-- Notify the servers about changed TLP rules of the groups.
+CREATE FUNCTION notify_group_tlps_changes() RETURNS trigger AS $$
+    BEGIN
+        PERFORM pg_notify('group_tlps_changes', '');
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER group_tlps_changes
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON group_tlps
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_group_tlps_changes();
+
 
 --
 -- forwarded documents
@@ -566,6 +1325,70 @@ CREATE TABLE forwarders_queue (
 
 CREATE INDEX ON forwarders_queue(state) WHERE state = 'pending';
 
+-- Backfills queue the already imported documents matching
+-- the current filter of an automatic forward target.
+CREATE TABLE forwarder_backfills (
+    id            int          PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    forwarders_id int          NOT NULL REFERENCES forwarders(id) ON DELETE CASCADE,
+    creator       varchar,
+    started       timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished      timestamptz,
+    status        sweep_status NOT NULL DEFAULT 'running',
+    advisories    int          NOT NULL DEFAULT 0,
+    processed     int          NOT NULL DEFAULT 0,
+    queued        int          NOT NULL DEFAULT 0,
+    error         text
+);
+
+-- forward_log records every attempt to forward a document to a target.
+-- The document is kept by its identity to prove the delivery
+-- even after the document is deleted.
+CREATE TYPE forward_trigger AS ENUM (
+    'automatic', 'manual', 'replay');
+
+CREATE TABLE forward_log (
+    id           bigint          PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    time         timestamptz     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    target       varchar         NOT NULL,
+    documents_id int             REFERENCES documents(id) ON DELETE SET NULL,
+    publisher    varchar         NOT NULL,
+    tracking_id  varchar         NOT NULL,
+    version      varchar         NOT NULL,
+    trigger      forward_trigger NOT NULL,
+    actor        varchar,
+    delivered    boolean         NOT NULL,
+    status_code  int,
+    response     text,
+    error        text
+);
+
+CREATE INDEX forward_log_time_idx         ON forward_log(time);
+CREATE INDEX forward_log_documents_id_idx ON forward_log(documents_id);
+
+-- forward_targets are the forward targets managed via the API.
+-- The secrets are encrypted with the key of the sources.
+CREATE TABLE forward_targets (
+    forwarders_id       int         PRIMARY KEY REFERENCES forwarders(id) ON DELETE CASCADE,
+    name                varchar,
+    type                varchar     NOT NULL DEFAULT 'default',
+    automatic           boolean     NOT NULL DEFAULT FALSE,
+    strategy            varchar,
+    publisher           varchar,
+    headers             varchar[],
+    timeout             interval,
+    tlp                 varchar,
+    password            bytea,
+    passphrase          bytea,
+    client_cert_public  bytea,
+    client_cert_private bytea,
+    health_url          varchar,
+    health_method       varchar,
+    rules               jsonb,
+    creator             varchar,
+    created             timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated             timestamptz
+);
+
 --
 -- aggregators
 --
@@ -580,6 +1403,422 @@ CREATE TABLE aggregators (
     CHECK(url LIKE '%/aggregator.json')
 );
 
+-- demo_data records the advisories and sources loaded by the demo mode
+-- so that they can be wiped without touching other data.
+CREATE TABLE demo_data (
+    advisories_id int UNIQUE REFERENCES advisories(id) ON DELETE CASCADE,
+    sources_id    int UNIQUE REFERENCES sources(id) ON DELETE CASCADE,
+    CHECK((advisories_id IS NULL) <> (sources_id IS NULL))
+);
+
+-- api_usage accumulates the API calls and the transferred data
+-- per day, user and endpoint.
+CREATE TABLE api_usage (
+    day       date    NOT NULL,
+    "user"    varchar NOT NULL,
+    method    varchar NOT NULL,
+    endpoint  varchar NOT NULL,
+    requests  bigint  NOT NULL DEFAULT 0,
+    bytes_in  bigint  NOT NULL DEFAULT 0,
+    bytes_out bigint  NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, "user", method, endpoint)
+);
+
+CREATE INDEX api_usage_user_idx ON api_usage("user", day);
+
+-- attention_acks records who acknowledged the attention flags
+-- of sources and aggregators in bulk.
+CREATE TABLE attention_acks (
+    id             int         PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    time           timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    actor          varchar     NOT NULL,
+    kind           varchar     NOT NULL CHECK (kind IN ('source', 'aggregator')),
+    sources_id     int         REFERENCES sources(id) ON DELETE SET NULL,
+    aggregators_id int         REFERENCES aggregators(id) ON DELETE SET NULL,
+    name           varchar     NOT NULL,
+    changed        timestamptz NOT NULL
+);
+
+CREATE INDEX attention_acks_time_idx ON attention_acks(time);
+
+-- source_actions records the state changes the system
+-- performs automatically on the sources.
+CREATE TABLE source_actions (
+    id         bigint      PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    time       timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sources_id int         NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    action     varchar     NOT NULL CHECK (action IN ('deactivated', 'attention', 'pin_mismatch')),
+    message    text        NOT NULL
+);
+
+CREATE INDEX source_actions_sources_id_idx ON source_actions(sources_id, time);
+
+-- document_accesses records the downloads of documents with
+-- restricted TLP labels. The advisory is copied so that the
+-- records survive the deletion of the document.
+-- The records can only be added, not changed.
+CREATE TABLE document_accesses (
+    id           bigint      PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    time         timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    actor        varchar,    -- NULL for accesses via shared links
+    kind         varchar     NOT NULL CHECK (kind IN ('download', 'share', 'export')),
+    documents_id int         REFERENCES documents(id) ON DELETE SET NULL,
+    publisher    text        NOT NULL,
+    tracking_id  text        NOT NULL,
+    version      text        NOT NULL,
+    tlp          text        NOT NULL
+);
+
+CREATE INDEX document_accesses_time_idx ON document_accesses(time);
+
+-- assets are the mirrored files referenced by advisories.
+-- The same file referenced by several documents is stored only once.
+CREATE TABLE assets (
+    sha256       bytea   PRIMARY KEY,
+    size         bigint  NOT NULL,
+    content_type varchar,
+    data         bytea   COMPRESSION lz4 NOT NULL
+);
+
+-- document_assets are the results of mirroring the files
+-- referenced by the documents.
+CREATE TABLE document_assets (
+    documents_id  int         NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    url           varchar     NOT NULL,
+    fetched       timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    assets_sha256 bytea       REFERENCES assets(sha256),
+    error         varchar,
+    PRIMARY KEY (documents_id, url),
+    CHECK((assets_sha256 IS NULL) <> (error IS NULL))
+);
+
+CREATE INDEX document_assets_url_idx ON document_assets(url);
+CREATE INDEX document_assets_sha256_idx ON document_assets(assets_sha256);
+
+-- query_subscriptions are the stored queries users want to be
+-- notified about if newly imported documents match them.
+-- tlps are the TLP permissions of the user when subscribing.
+-- last_import is the highest number of the imports already matched.
+CREATE TABLE query_subscriptions (
+    id                int         PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    stored_queries_id int         NOT NULL REFERENCES stored_queries(id) ON DELETE CASCADE,
+    "user"            varchar     NOT NULL,
+    tlps              jsonb       NOT NULL,
+    webhook           varchar,
+    created           timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_import       bigint      NOT NULL DEFAULT 0,
+    -- teams_id shares the matches with the members of the team.
+    teams_id          int         REFERENCES teams(id) ON DELETE CASCADE,
+    CHECK(webhook <> '')
+);
+
+CREATE UNIQUE INDEX query_subscriptions_user_idx
+    ON query_subscriptions(stored_queries_id, "user") WHERE teams_id IS NULL;
+CREATE UNIQUE INDEX query_subscriptions_team_idx
+    ON query_subscriptions(stored_queries_id, teams_id) WHERE teams_id IS NOT NULL;
+
+-- query_matches are the documents matching the subscribed queries.
+-- delivered is the time the match was sent to the webhook of the subscription.
+CREATE TABLE query_matches (
+    id                     bigint      PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    query_subscriptions_id int         NOT NULL REFERENCES query_subscriptions(id) ON DELETE CASCADE,
+    documents_id           int         NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    matched                timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seen                   boolean     NOT NULL DEFAULT FALSE,
+    delivered              timestamptz,
+    attempts               int         NOT NULL DEFAULT 0,
+    error                  varchar,
+    UNIQUE (query_subscriptions_id, documents_id)
+);
+
+CREATE INDEX query_matches_matched_idx ON query_matches(matched);
+CREATE INDEX query_matches_pending_idx ON query_matches(query_subscriptions_id)
+    WHERE delivered IS NULL;
+
+-- Notify the matching of subscribed queries about new documents.
+CREATE FUNCTION notify_documents_imported() RETURNS trigger AS $$
+    BEGIN
+        PERFORM pg_notify('documents_imported', '');
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER documents_imported
+    AFTER INSERT ON documents
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_documents_imported();
+
+-- document_imports numbers the imported documents in the order their
+-- imports are committed. The documents ids are assigned when inserting
+-- and parallel imports may commit them out of order.
+CREATE TABLE document_imports (
+    seq          bigint PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    documents_id int    NOT NULL REFERENCES documents(id) ON DELETE CASCADE
+);
+
+-- The lock is held until the end of the importing transaction
+-- so the numbers are visible in the order they are drawn.
+-- Imports are only recorded if there are subscriptions to match.
+CREATE FUNCTION record_document_import() RETURNS trigger AS $$
+    BEGIN
+        LOCK TABLE document_imports IN EXCLUSIVE MODE;
+        INSERT INTO document_imports (documents_id)
+            SELECT NEW.id
+            WHERE EXISTS (SELECT 1 FROM query_subscriptions)
+            AND EXISTS (SELECT 1 FROM documents WHERE id = NEW.id);
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public, pg_temp;
+
+CREATE CONSTRAINT TRIGGER record_document_import
+    AFTER INSERT ON documents
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION record_document_import();
+
+-- document_changes records the imports, updates and deletions
+-- of the documents to be fetched incrementally by external
+-- synchronization jobs. The rows of deleted documents are kept
+-- as tombstones. Changes are ordered by (xid, id) as only the
+-- transactions older than all running ones are final.
+CREATE TYPE document_change AS ENUM (
+    'imported', 'updated', 'deleted'
+);
+
+CREATE TABLE document_changes (
+    id           bigint          PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    xid          xid8            NOT NULL DEFAULT pg_current_xact_id(),
+    documents_id int             NOT NULL,
+    kind         document_change NOT NULL,
+    changed      timestamptz     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    publisher    text            NOT NULL,
+    tracking_id  text            NOT NULL,
+    version      text            NOT NULL,
+    tlp          text
+);
+
+CREATE INDEX document_changes_cursor_idx ON document_changes(xid, id);
+CREATE INDEX document_changes_documents_id_idx ON document_changes(documents_id);
+
+-- record_document_change records a change of a document.
+-- Only changes of the latest flag count as updates. The initial
+-- setting of the flag is part of the import.
+CREATE FUNCTION record_document_change() RETURNS trigger AS $$
+    BEGIN
+        IF TG_OP = 'INSERT' THEN
+            INSERT INTO document_changes
+                (documents_id, kind, publisher, tracking_id, version, tlp)
+                SELECT NEW.id, 'imported', publisher, tracking_id, NEW.version, NEW.tlp
+                FROM advisories WHERE id = NEW.advisories_id;
+        ELSIF TG_OP = 'UPDATE' THEN
+            IF OLD.latest IS NOT NULL AND OLD.latest IS DISTINCT FROM NEW.latest THEN
+                INSERT INTO document_changes
+                    (documents_id, kind, publisher, tracking_id, version, tlp)
+                    SELECT NEW.id, 'updated', publisher, tracking_id, NEW.version, NEW.tlp
+                    FROM advisories WHERE id = NEW.advisories_id;
+            END IF;
+        ELSE
+            -- The advisory may already be gone so take it from the history.
+            INSERT INTO document_changes
+                (documents_id, kind, publisher, tracking_id, version, tlp)
+                SELECT OLD.id, 'deleted', publisher, tracking_id, OLD.version, OLD.tlp
+                FROM document_changes WHERE documents_id = OLD.id
+                ORDER BY id DESC LIMIT 1;
+        END IF;
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER record_document_changes
+    AFTER INSERT OR DELETE OR UPDATE OF latest ON documents
+    FOR EACH ROW EXECUTE FUNCTION record_document_change();
+
+-- annotations are remarks of the analysts anchored to a part
+-- of a document addressed by a JSON pointer (RFC 6901).
+CREATE TABLE annotations (
+    id           int            PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    documents_id int            NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    pointer      text           NOT NULL,
+    time         timestamptz    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    author       varchar        NOT NULL,
+    message      varchar(10000) NOT NULL
+);
+
+CREATE INDEX ON annotations(documents_id);
+
+-- query_history are the recently executed ad-hoc document
+-- queries of the users.
+CREATE TABLE query_history (
+    id         int         PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    "user"     varchar     NOT NULL,
+    executed   timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    advisories boolean     NOT NULL DEFAULT FALSE,
+    query      text        NOT NULL,
+    columns    text        NOT NULL DEFAULT '',
+    orders     text        NOT NULL DEFAULT ''
+);
+
+CREATE INDEX ON query_history("user", executed);
+
+-- Legal holds exempt documents from deletion until they are released.
+-- The documents are given explicitly and/or by a documents query.
+CREATE TABLE legal_holds (
+    id       int         PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    name     varchar     NOT NULL,
+    reason   varchar,
+    query    varchar,
+    creator  varchar     NOT NULL,
+    created  timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    releaser varchar,
+    released timestamptz,
+    CHECK(name <> '')
+);
+
+CREATE TABLE legal_hold_documents (
+    legal_holds_id int NOT NULL REFERENCES legal_holds(id) ON DELETE CASCADE,
+    documents_id   int NOT NULL REFERENCES documents(id)   ON DELETE CASCADE,
+    PRIMARY KEY (legal_holds_id, documents_id)
+);
+
+CREATE INDEX ON legal_hold_documents(documents_id);
+
+-- siem_cursors stores the last record exported
+-- to the SIEM per kind of record.
+CREATE TABLE siem_cursors (
+    stream  varchar     PRIMARY KEY,
+    last_id bigint      NOT NULL,
+    changed timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- import_anomalies records the days on which the number of
+-- documents imported from a source deviated strongly from the usual.
+CREATE TABLE import_anomalies (
+    sources_id int         NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    day        date        NOT NULL,
+    kind       varchar     NOT NULL CHECK (kind IN ('silence', 'spike')),
+    imports    bigint      NOT NULL,
+    average    float       NOT NULL,
+    detected   timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (sources_id, day)
+);
+
+-- feed_metrics are the outcomes of the downloads of the feeds
+-- summed up per minute.
+CREATE TABLE feed_metrics (
+    feeds_id    int         NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    time        timestamptz NOT NULL,
+    successes   int         NOT NULL DEFAULT 0,
+    failures    int         NOT NULL DEFAULT 0,
+    duration_ms bigint      NOT NULL DEFAULT 0,
+    bytes       bigint      NOT NULL DEFAULT 0,
+    PRIMARY KEY (feeds_id, time)
+);
+
+CREATE INDEX feed_metrics_time_idx ON feed_metrics(time);
+
+-- team_sources are the sources a team is responsible for.
+CREATE TABLE team_sources (
+    teams_id   int NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    sources_id int NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    PRIMARY KEY (teams_id, sources_id)
+);
+
+-- document_transformations are the fields of the documents changed
+-- by the transformation rules at import. original is the upstream value.
+CREATE TABLE document_transformations (
+    documents_id int     NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    num          int     NOT NULL,
+    rule         varchar NOT NULL,
+    path         varchar NOT NULL,
+    original     varchar NOT NULL,
+    replaced     varchar NOT NULL,
+    PRIMARY KEY (documents_id, num)
+);
+
+CREATE INDEX document_transformations_rule_idx ON document_transformations(rule);
+
+-- failed_downloads are the advisories of the feeds which could not
+-- be downloaded or imported after all retries.
+CREATE TABLE failed_downloads (
+    id           int         PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    feeds_id     int         NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    url          varchar     NOT NULL,
+    hash         varchar,
+    signature    varchar,
+    updated      timestamptz NOT NULL,
+    attempts     int         NOT NULL,
+    first_failed timestamptz NOT NULL DEFAULT current_timestamp,
+    last_failed  timestamptz NOT NULL DEFAULT current_timestamp,
+    error        varchar     NOT NULL,
+    dismissed    bool        NOT NULL DEFAULT FALSE,
+    UNIQUE(feeds_id, url),
+    CHECK(url <> '')
+);
+
+-- advisory_receipts are the receipts waiting to be sent to the
+-- providers or already sent. callback and url are the receipt URL
+-- of the source and the URL of the advisory at the time of the import.
+CREATE TABLE advisory_receipts (
+    id           bigint      PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    sources_id   int         NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    documents_id int         NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    callback     varchar     NOT NULL,
+    url          varchar     NOT NULL,
+    received     timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered    timestamptz,
+    attempts     int         NOT NULL DEFAULT 0,
+    error        varchar
+);
+
+CREATE INDEX advisory_receipts_received_idx ON advisory_receipts(received);
+CREATE INDEX advisory_receipts_pending_idx ON advisory_receipts(id)
+    WHERE delivered IS NULL;
+
+--
+-- row level security of the documents
+--
+
+-- session_tlp_allowed checks if the documents of a publisher with a TLP
+-- label are visible under the TLP rules in the setting isduba.tlp_rules
+-- of the session. Sessions without rules, like the ones of the background
+-- tasks, see all documents.
+CREATE FUNCTION session_tlp_allowed(publisher text, tlp text) RETURNS boolean AS $$
+    SELECT CASE coalesce(current_setting('isduba.tlp_rules', true), '')
+        WHEN '' THEN true
+        ELSE tlp_allowed(publisher, tlp, current_setting('isduba.tlp_rules', true)::jsonb)
+    END
+$$ LANGUAGE SQL STABLE PARALLEL SAFE;
+
+-- session_tlp_rules returns the TLP rules of the session or NULL
+-- if there are none.
+CREATE FUNCTION session_tlp_rules() RETURNS jsonb AS $$
+    SELECT nullif(current_setting('isduba.tlp_rules', true), '')::jsonb
+$$ LANGUAGE SQL STABLE PARALLEL SAFE;
+
+-- The documents are only visible within the TLP rules of the session.
+-- Rows which are not visible can neither be updated nor deleted.
+-- The rules are sub-selects so they are only parsed once per statement.
+ALTER TABLE documents ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY documents_visible ON documents FOR SELECT
+    USING ((SELECT session_tlp_rules()) IS NULL
+        OR tlp_allowed(advisory_publisher, tlp, (SELECT session_tlp_rules())));
+CREATE POLICY documents_insert ON documents FOR INSERT
+    WITH CHECK (true);
+CREATE POLICY documents_update ON documents FOR UPDATE
+    USING (true);
+CREATE POLICY documents_delete ON documents FOR DELETE
+    USING (true);
+
+-- The book keeping of the advisories has to see all documents.
+ALTER FUNCTION update_advisory()            SECURITY DEFINER SET search_path = public, pg_temp;
+ALTER FUNCTION delete_advisory()            SECURITY DEFINER SET search_path = public, pg_temp;
+ALTER FUNCTION update_upstream_changed()    SECURITY DEFINER SET search_path = public, pg_temp;
+ALTER FUNCTION update_localized_title(int)  SECURITY DEFINER SET search_path = public, pg_temp;
+ALTER FUNCTION incr_comments()              SECURITY DEFINER SET search_path = public, pg_temp;
+ALTER FUNCTION decr_comments()              SECURITY DEFINER SET search_path = public, pg_temp;
+ALTER FUNCTION upd_recent()                 SECURITY DEFINER SET search_path = public, pg_temp;
+ALTER FUNCTION extract_cves()               SECURITY DEFINER SET search_path = public, pg_temp;
+ALTER FUNCTION log_ssvc_history_to_events() SECURITY DEFINER SET search_path = public, pg_temp;
+
 --
 -- permissions
 --
@@ -603,6 +1842,55 @@ GRANT INSERT, DELETE, SELECT, UPDATE ON forwarders              TO {{ .User | sa
 GRANT INSERT, DELETE, SELECT, UPDATE ON forwarders_queue        TO {{ .User | sanitize }};
 GRANT INSERT, DELETE, SELECT, UPDATE ON aggregators             TO {{ .User | sanitize }};
 GRANT INSERT, DELETE, SELECT, UPDATE ON ssvc_history            TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON text_templates          TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON state_approvals         TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON document_claims         TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON scoring_weights         TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON publishers_trust        TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON cve_scores              TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON asset_matches           TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON shared_links            TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON validation_sweeps       TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON validation_results      TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON recompute_jobs          TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON deleted_blobs           TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON group_tlps              TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON sla_escalations         TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON archiving_log           TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON team_defaults           TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON team_queries            TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON provisioned_users       TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON state_history           TO {{ .User | sanitize }};
+GRANT SELECT ON ssvc_ranges                                     TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON demo_data               TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON api_usage               TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON instance_locale         TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON attention_acks          TO {{ .User | sanitize }};
+GRANT INSERT, SELECT                 ON document_accesses       TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON assets                  TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON document_assets         TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON query_subscriptions     TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON query_matches           TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON document_imports        TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON document_changes        TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON annotations             TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON query_history           TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON legal_holds             TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON legal_hold_documents    TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON forwarder_backfills     TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON source_actions          TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON document_reads          TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON siem_cursors            TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON import_anomalies        TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON feed_metrics            TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON teams                   TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON team_members            TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON team_sources            TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON document_transformations TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON failed_downloads        TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON advisory_receipts       TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON forward_log             TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON forward_targets         TO {{ .User | sanitize }};
 --
 -- default queries
 --
@@ -0,0 +1,26 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+CREATE TYPE text_templates_kind AS ENUM (
+    'comment', 'assessment'
+);
+
+CREATE TABLE text_templates (
+    id          int                  PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    kind        text_templates_kind  NOT NULL DEFAULT 'comment',
+    definer     varchar              NOT NULL,
+    global      boolean              NOT NULL DEFAULT FALSE,
+    role        stored_queries_roles,
+    name        varchar              NOT NULL,
+    description varchar,
+    body        varchar(10000)       NOT NULL,
+    CHECK(name <> ''),
+    UNIQUE (definer, name)
+);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON text_templates TO {{ .User | sanitize }};
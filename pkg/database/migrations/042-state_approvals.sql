@@ -0,0 +1,32 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+ALTER TYPE events ADD VALUE 'request_state_change';
+ALTER TYPE events ADD VALUE 'approve_state_change';
+ALTER TYPE events ADD VALUE 'reject_state_change';
+
+CREATE TYPE approval_status AS ENUM (
+    'pending', 'approved', 'rejected');
+
+CREATE TABLE state_approvals (
+    id            int             PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    advisories_id int             NOT NULL REFERENCES advisories(id) ON DELETE CASCADE,
+    documents_id  int             REFERENCES documents(id) ON DELETE SET NULL,
+    from_state    workflow        NOT NULL,
+    to_state      workflow        NOT NULL,
+    requester     varchar         NOT NULL,
+    requested     timestamptz     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    approver      varchar,
+    decided       timestamptz,
+    status        approval_status NOT NULL DEFAULT 'pending'
+);
+
+-- Only one open request per advisory.
+CREATE UNIQUE INDEX ON state_approvals(advisories_id) WHERE status = 'pending';
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON state_approvals TO {{ .User | sanitize }};
@@ -0,0 +1,16 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+CREATE TABLE document_claims (
+    documents_id int         PRIMARY KEY REFERENCES documents(id) ON DELETE CASCADE,
+    claimant     varchar     NOT NULL,
+    claimed      timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires      timestamptz NOT NULL
+);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON document_claims TO {{ .User | sanitize }};
@@ -0,0 +1,90 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+CREATE TYPE scoring_factor AS ENUM (
+    'cvss', 'epss', 'kev', 'assets', 'publisher'
+);
+
+CREATE TABLE scoring_weights (
+    factor scoring_factor PRIMARY KEY,
+    weight float          NOT NULL CHECK (weight >= 0)
+);
+
+-- '*' is the trust of all publishers not explicitly stated.
+CREATE TABLE publishers_trust (
+    publisher text  PRIMARY KEY,
+    trust     float NOT NULL CHECK (trust BETWEEN 0 AND 1)
+);
+
+CREATE TABLE cve_scores (
+    cve  text    PRIMARY KEY,
+    epss float   CHECK (epss BETWEEN 0 AND 1),
+    kev  boolean NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE asset_matches (
+    documents_id int   PRIMARY KEY REFERENCES documents(id) ON DELETE CASCADE,
+    relevance    float NOT NULL CHECK (relevance BETWEEN 0 AND 1)
+);
+
+ALTER TABLE documents ADD COLUMN score float;
+
+CREATE INDEX documents_score_idx ON documents(coalesce(score, '0'::double precision) DESC);
+
+-- document_score calculates the weighted score of a document in the range of 0 to 10.
+CREATE FUNCTION document_score(doc jsonb, adv_id int, doc_id int) RETURNS float AS $$
+    WITH weights AS (
+        SELECT
+            coalesce(sum(weight) FILTER (WHERE factor = 'cvss'), 0)      AS cvss,
+            coalesce(sum(weight) FILTER (WHERE factor = 'epss'), 0)      AS epss,
+            coalesce(sum(weight) FILTER (WHERE factor = 'kev'), 0)       AS kev,
+            coalesce(sum(weight) FILTER (WHERE factor = 'assets'), 0)    AS assets,
+            coalesce(sum(weight) FILTER (WHERE factor = 'publisher'), 0) AS publisher,
+            sum(weight) AS total
+        FROM scoring_weights
+    ),
+    cves AS (
+        SELECT jsonb_array_elements_text(
+            jsonb_path_query_array(doc, '$.vulnerabilities."cve"')) AS cve
+    ),
+    factors AS (
+        SELECT
+            coalesce(max_cvss3_score(doc), max_cvss2_score(doc), 0) / 10 AS cvss,
+            coalesce((SELECT max(epss) FROM cve_scores JOIN cves USING (cve)), 0) AS epss,
+            coalesce((SELECT bool_or(kev) FROM cve_scores JOIN cves USING (cve)), FALSE)::int AS kev,
+            coalesce((SELECT relevance FROM asset_matches WHERE documents_id = doc_id), 0) AS assets,
+            coalesce(
+                (SELECT trust FROM publishers_trust JOIN advisories
+                    ON publishers_trust.publisher = advisories.publisher
+                    WHERE advisories.id = adv_id),
+                (SELECT trust FROM publishers_trust WHERE publisher = '*'),
+                0) AS publisher
+    )
+    SELECT CASE WHEN w.total > 0 THEN
+        10 * (w.cvss * f.cvss + w.epss * f.epss + w.kev * f.kev +
+              w.assets * f.assets + w.publisher * f.publisher) / w.total
+        END
+    FROM weights w, factors f
+$$ LANGUAGE SQL STABLE;
+
+CREATE FUNCTION score_document() RETURNS trigger AS $$
+    BEGIN
+        NEW.score = document_score(NEW.document, NEW.advisories_id, NEW.id);
+        RETURN NEW;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER score_document_trigger BEFORE INSERT
+    ON documents
+    FOR EACH ROW
+    EXECUTE FUNCTION score_document();
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON scoring_weights  TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON publishers_trust TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON cve_scores       TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON asset_matches    TO {{ .User | sanitize }};
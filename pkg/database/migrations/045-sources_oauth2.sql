@@ -0,0 +1,13 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+ALTER TABLE sources
+    ADD COLUMN oauth2_token_url     varchar,
+    ADD COLUMN oauth2_client_id     varchar,
+    ADD COLUMN oauth2_client_secret bytea,
+    ADD COLUMN oauth2_scopes        text[];
@@ -0,0 +1,27 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+ALTER TYPE events ADD VALUE 'share_document';
+ALTER TYPE events ADD VALUE 'revoke_share';
+ALTER TYPE events ADD VALUE 'access_share';
+
+CREATE TABLE shared_links (
+    id            int         PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    token_hash    bytea       NOT NULL UNIQUE,
+    documents_id  int         NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    creator       varchar     NOT NULL,
+    created       timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires       timestamptz NOT NULL,
+    revoked       timestamptz,
+    accesses      int         NOT NULL DEFAULT 0,
+    last_access   timestamptz
+);
+
+CREATE INDEX ON shared_links(documents_id);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON shared_links TO {{ .User | sanitize }};
@@ -0,0 +1,78 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+ALTER TABLE documents
+    ADD COLUMN notes           text COMPRESSION lz4,
+    ADD COLUMN remediations    text COMPRESSION lz4,
+    ADD COLUMN acknowledgments text COMPRESSION lz4;
+
+-- original_json parses the original upload of a document.
+-- In contrast to documents.document the strings are not replaced
+-- by references to unique_texts.
+CREATE FUNCTION original_json(orig bytea) RETURNS jsonb AS $$
+    BEGIN
+        RETURN convert_from(orig, 'UTF8')::jsonb;
+    EXCEPTION WHEN others THEN
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- extract_texts joins the strings found by the given JSON paths in order.
+CREATE FUNCTION extract_texts(doc jsonb, paths text[]) RETURNS text AS $$
+    SELECT string_agg(q.v #>> '{}', E'\n' ORDER BY ps.p, q.n)
+    FROM unnest(paths) WITH ORDINALITY AS ps(path, p),
+        LATERAL jsonb_path_query(doc, ps.path::jsonpath) WITH ORDINALITY AS q(v, n)
+    WHERE jsonb_typeof(q.v) = 'string'
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE FUNCTION document_notes(doc jsonb) RETURNS text AS $$
+    SELECT extract_texts(doc, ARRAY[
+        '$.document.notes[*].text',
+        '$.vulnerabilities[*].notes[*].text'])
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE FUNCTION document_remediations(doc jsonb) RETURNS text AS $$
+    SELECT extract_texts(doc, ARRAY[
+        '$.vulnerabilities[*].remediations[*].details'])
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE FUNCTION document_acknowledgments(doc jsonb) RETURNS text AS $$
+    SELECT extract_texts(doc, ARRAY[
+        '$.document.acknowledgments[*].names[*]',
+        '$.document.acknowledgments[*].organization',
+        '$.document.acknowledgments[*].summary',
+        '$.vulnerabilities[*].acknowledgments[*].names[*]',
+        '$.vulnerabilities[*].acknowledgments[*].organization',
+        '$.vulnerabilities[*].acknowledgments[*].summary'])
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE FUNCTION extract_document_texts() RETURNS trigger AS $$
+    DECLARE
+        doc jsonb := original_json(NEW.original);
+    BEGIN
+        NEW.notes           = document_notes(doc);
+        NEW.remediations    = document_remediations(doc);
+        NEW.acknowledgments = document_acknowledgments(doc);
+        RETURN NEW;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER extract_document_texts_trigger BEFORE INSERT
+    ON documents
+    FOR EACH ROW
+    EXECUTE FUNCTION extract_document_texts();
+
+CREATE INDEX ON documents USING gin(notes gin_trgm_ops);
+CREATE INDEX ON documents USING gin(remediations gin_trgm_ops);
+CREATE INDEX ON documents USING gin(acknowledgments gin_trgm_ops);
+
+-- Extract the texts of the already imported documents.
+UPDATE documents SET
+    notes           = document_notes(original_json(original)),
+    remediations    = document_remediations(original_json(original)),
+    acknowledgments = document_acknowledgments(original_json(original));
@@ -0,0 +1,43 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+CREATE TYPE sweep_status AS ENUM (
+    'running', 'finished', 'canceled', 'failed');
+
+CREATE TABLE validation_sweeps (
+    id          int         PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    creator     varchar,
+    started     timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished    timestamptz,
+    status      sweep_status NOT NULL DEFAULT 'running',
+    publisher   text,
+    since       timestamptz,
+    until       timestamptz,
+    remote      boolean     NOT NULL DEFAULT FALSE,
+    checked     int         NOT NULL DEFAULT 0,
+    regressions int         NOT NULL DEFAULT 0,
+    fixed       int         NOT NULL DEFAULT 0,
+    error       text
+);
+
+-- validation_results only stores the documents where
+-- the verdict of the sweep differs from the recorded one.
+CREATE TABLE validation_results (
+    validation_sweeps_id   int     NOT NULL REFERENCES validation_sweeps(id) ON DELETE CASCADE,
+    documents_id           int     NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    schema_failed          boolean NOT NULL,
+    remote_failed          boolean,
+    previous_schema_failed boolean NOT NULL,
+    previous_remote_failed boolean,
+    regression             boolean NOT NULL,
+    messages               text[],
+    PRIMARY KEY (validation_sweeps_id, documents_id)
+);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON validation_sweeps  TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON validation_results TO {{ .User | sanitize }};
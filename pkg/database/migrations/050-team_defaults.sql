@@ -0,0 +1,39 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- team_defaults are the defaults per Keycloak group.
+CREATE TABLE team_defaults (
+    group_name varchar PRIMARY KEY,
+    landing    varchar,
+    CHECK(group_name <> '')
+);
+
+-- team_queries are the stored queries copied to the new members of a group.
+CREATE TABLE team_queries (
+    group_name        varchar NOT NULL REFERENCES team_defaults(group_name) ON DELETE CASCADE,
+    stored_queries_id int     NOT NULL REFERENCES stored_queries(id) ON DELETE CASCADE,
+    position          int     NOT NULL,
+    PRIMARY KEY (group_name, stored_queries_id)
+);
+
+-- provisioned_users are the users which already got their team defaults.
+CREATE TABLE provisioned_users (
+    "user"      varchar     PRIMARY KEY,
+    provisioned timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Known users are not new and should not get the team defaults.
+INSERT INTO provisioned_users ("user")
+    SELECT definer FROM stored_queries
+    UNION
+    SELECT actor FROM events_log WHERE actor IS NOT NULL
+    ON CONFLICT DO NOTHING;
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON team_defaults     TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON team_queries      TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON provisioned_users TO {{ .User | sanitize }};
@@ -0,0 +1,82 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- state_history keeps the workflow states of the advisories
+-- with the time ranges they were valid.
+CREATE TABLE state_history (
+    advisories_id int         NOT NULL REFERENCES advisories(id) ON DELETE CASCADE,
+    state         workflow    NOT NULL,
+    valid_from    timestamptz NOT NULL,
+    valid_to      timestamptz,
+    CHECK(valid_to IS NULL OR valid_to >= valid_from)
+);
+
+CREATE INDEX state_history_advisories_id_idx ON state_history(advisories_id, valid_from);
+CREATE UNIQUE INDEX state_history_open_idx ON state_history(advisories_id) WHERE valid_to IS NULL;
+
+-- Reconstruct the history from the imports and state changes in the events log.
+-- Importing a document resets the state to 'new'.
+INSERT INTO state_history (advisories_id, state, valid_from, valid_to)
+    SELECT advisories_id, state, time,
+        LEAD(time) OVER (PARTITION BY advisories_id ORDER BY time)
+    FROM (
+        SELECT documents.advisories_id,
+            CASE WHEN event = 'import_document' THEN 'new'::workflow
+                ELSE events_log.state END AS state,
+            events_log.time
+        FROM events_log JOIN documents ON events_log.documents_id = documents.id
+        WHERE event IN ('import_document', 'state_change')
+            AND (event = 'import_document' OR events_log.state IS NOT NULL)
+    ) AS changes;
+
+-- The last known state may differ from the current one if
+-- the events log is incomplete.
+UPDATE state_history SET valid_to = current_timestamp
+    FROM advisories
+    WHERE state_history.advisories_id = advisories.id
+        AND state_history.valid_to IS NULL
+        AND state_history.state <> advisories.state;
+
+INSERT INTO state_history (advisories_id, state, valid_from)
+    SELECT id, state, current_timestamp FROM advisories
+    WHERE NOT EXISTS (
+        SELECT 1 FROM state_history
+        WHERE advisories_id = advisories.id AND valid_to IS NULL);
+
+-- Trigger to record the changes of the workflow states.
+CREATE FUNCTION record_state_history() RETURNS trigger AS $$
+    BEGIN
+        UPDATE state_history SET valid_to = current_timestamp
+            WHERE advisories_id = NEW.id AND valid_to IS NULL;
+        INSERT INTO state_history (advisories_id, state, valid_from)
+            VALUES (NEW.id, NEW.state, current_timestamp);
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER advisories_state_history
+    AFTER INSERT ON advisories
+    FOR EACH ROW EXECUTE FUNCTION record_state_history();
+
+CREATE TRIGGER advisories_state_history_update
+    AFTER UPDATE OF state ON advisories
+    FOR EACH ROW
+    WHEN (OLD.state IS DISTINCT FROM NEW.state)
+    EXECUTE FUNCTION record_state_history();
+
+-- ssvc_ranges are the SSVC values of the documents
+-- with the time ranges they were valid.
+CREATE VIEW ssvc_ranges AS
+    SELECT documents_id, ssvc, actor, changedate AS valid_from,
+        LEAD(changedate) OVER (
+            PARTITION BY documents_id
+            ORDER BY changedate, change_number) AS valid_to
+    FROM ssvc_history;
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON state_history TO {{ .User | sanitize }};
+GRANT SELECT ON ssvc_ranges TO {{ .User | sanitize }};
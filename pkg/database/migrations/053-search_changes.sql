@@ -0,0 +1,40 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- Notify the search caches about changes of the searched data.
+-- Identical notifications within a transaction are only delivered once.
+CREATE FUNCTION notify_search_changes() RETURNS trigger AS $$
+    BEGIN
+        PERFORM pg_notify('search_changes', '');
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER advisories_search_changes
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON advisories
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_search_changes();
+
+CREATE TRIGGER documents_search_changes
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON documents
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_search_changes();
+
+CREATE TRIGGER comments_search_changes
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON comments
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_search_changes();
+
+CREATE TRIGGER events_log_search_changes
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON events_log
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_search_changes();
+
+CREATE TRIGGER ssvc_history_search_changes
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON ssvc_history
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_search_changes();
+
+CREATE TRIGGER state_history_search_changes
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON state_history
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_search_changes();
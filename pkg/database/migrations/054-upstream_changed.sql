@@ -0,0 +1,38 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- upstream_changed is the latest current_release_date of the documents
+-- of an advisory. In contrast to recent it is not affected by the
+-- time of the import or local changes.
+ALTER TABLE advisories ADD COLUMN upstream_changed timestamptz;
+
+UPDATE advisories SET upstream_changed = (
+    SELECT max(current_release_date) FROM documents
+    WHERE documents.advisories_id = advisories.id);
+
+CREATE INDEX advisories_upstream_changed_idx ON advisories(upstream_changed);
+
+CREATE FUNCTION update_upstream_changed() RETURNS trigger AS $$
+    BEGIN
+        IF TG_OP = 'INSERT' THEN
+            UPDATE advisories
+                SET upstream_changed = greatest(upstream_changed, NEW.current_release_date)
+                WHERE id = NEW.advisories_id;
+        ELSE
+            UPDATE advisories SET upstream_changed = (
+                SELECT max(current_release_date) FROM documents
+                WHERE documents.advisories_id = OLD.advisories_id)
+                WHERE id = OLD.advisories_id;
+        END IF;
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER documents_upstream_changed
+    AFTER INSERT OR DELETE ON documents
+    FOR EACH ROW EXECUTE FUNCTION update_upstream_changed();
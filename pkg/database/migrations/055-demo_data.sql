@@ -0,0 +1,17 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- demo_data records the advisories and sources loaded by the demo mode
+-- so that they can be wiped without touching other data.
+CREATE TABLE demo_data (
+    advisories_id int UNIQUE REFERENCES advisories(id) ON DELETE CASCADE,
+    sources_id    int UNIQUE REFERENCES sources(id) ON DELETE CASCADE,
+    CHECK((advisories_id IS NULL) <> (sources_id IS NULL))
+);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON demo_data TO {{ .User | sanitize }};
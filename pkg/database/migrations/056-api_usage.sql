@@ -0,0 +1,24 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- api_usage accumulates the API calls and the transferred data
+-- per day, user and endpoint.
+CREATE TABLE api_usage (
+    day       date    NOT NULL,
+    "user"    varchar NOT NULL,
+    method    varchar NOT NULL,
+    endpoint  varchar NOT NULL,
+    requests  bigint  NOT NULL DEFAULT 0,
+    bytes_in  bigint  NOT NULL DEFAULT 0,
+    bytes_out bigint  NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, "user", method, endpoint)
+);
+
+CREATE INDEX api_usage_user_idx ON api_usage("user", day);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON api_usage TO {{ .User | sanitize }};
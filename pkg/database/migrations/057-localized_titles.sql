@@ -0,0 +1,72 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- lang is the language of a document.
+ALTER TABLE documents ADD COLUMN lang text
+    GENERATED ALWAYS AS (document #>> '{document,lang}') STORED;
+
+-- instance_locale is the locale configured for this instance.
+CREATE TABLE instance_locale (
+    singleton bool PRIMARY KEY DEFAULT TRUE CHECK(singleton),
+    locale    text NOT NULL
+);
+
+INSERT INTO instance_locale (locale) VALUES ('en');
+
+-- lang_rank ranks how well a language tag matches a locale.
+-- Lower is better. English is the fallback.
+CREATE FUNCTION lang_rank(lang text, locale text) RETURNS int AS $$
+    SELECT CASE
+        WHEN lower(lang) = lower(locale) THEN 0
+        WHEN lower(split_part(lang, '-', 1)) = lower(split_part(locale, '-', 1)) THEN 1
+        WHEN lower(split_part(lang, '-', 1)) = 'en' THEN 2
+        ELSE 3
+    END
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- localized_title is the title of the document of an advisory
+-- matching the configured locale best. Ties are broken in favor
+-- of the latest document. languages lists the languages of the
+-- documents of an advisory.
+ALTER TABLE advisories ADD COLUMN localized_title text;
+ALTER TABLE advisories ADD COLUMN languages text;
+
+CREATE FUNCTION update_localized_title(adv_id int) RETURNS void AS $$
+    UPDATE advisories SET
+        localized_title = (
+            SELECT title FROM documents
+            WHERE advisories_id = adv_id
+            ORDER BY lang_rank(lang, (SELECT locale FROM instance_locale)),
+                latest DESC NULLS LAST,
+                current_release_date DESC NULLS LAST,
+                id DESC
+            LIMIT 1),
+        languages = (
+            SELECT string_agg(DISTINCT lang, ',' ORDER BY lang) FROM documents
+            WHERE advisories_id = adv_id AND coalesce(lang, '') <> '')
+    WHERE id = adv_id
+$$ LANGUAGE SQL;
+
+CREATE FUNCTION documents_localized_title() RETURNS trigger AS $$
+    BEGIN
+        IF TG_OP = 'INSERT' THEN
+            PERFORM update_localized_title(NEW.advisories_id);
+        ELSE
+            PERFORM update_localized_title(OLD.advisories_id);
+        END IF;
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER documents_localized_title
+    AFTER INSERT OR DELETE ON documents
+    FOR EACH ROW EXECUTE FUNCTION documents_localized_title();
+
+SELECT update_localized_title(id) FROM advisories;
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON instance_locale TO {{ .User | sanitize }};
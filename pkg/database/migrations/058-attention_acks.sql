@@ -0,0 +1,24 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- attention_acks records who acknowledged the attention flags
+-- of sources and aggregators in bulk.
+CREATE TABLE attention_acks (
+    id             int         PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    time           timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    actor          varchar     NOT NULL,
+    kind           varchar     NOT NULL CHECK (kind IN ('source', 'aggregator')),
+    sources_id     int         REFERENCES sources(id) ON DELETE SET NULL,
+    aggregators_id int         REFERENCES aggregators(id) ON DELETE SET NULL,
+    name           varchar     NOT NULL,
+    changed        timestamptz NOT NULL
+);
+
+CREATE INDEX attention_acks_time_idx ON attention_acks(time);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON attention_acks TO {{ .User | sanitize }};
@@ -0,0 +1,27 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- document_accesses records the downloads of documents with
+-- restricted TLP labels. The advisory is copied so that the
+-- records survive the deletion of the document.
+-- The records can only be added, not changed.
+CREATE TABLE document_accesses (
+    id           bigint      PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    time         timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    actor        varchar,    -- NULL for accesses via shared links
+    kind         varchar     NOT NULL CHECK (kind IN ('download', 'share')),
+    documents_id int         REFERENCES documents(id) ON DELETE SET NULL,
+    publisher    text        NOT NULL,
+    tracking_id  text        NOT NULL,
+    version      text        NOT NULL,
+    tlp          text        NOT NULL
+);
+
+CREATE INDEX document_accesses_time_idx ON document_accesses(time);
+
+GRANT INSERT, SELECT ON document_accesses TO {{ .User | sanitize }};
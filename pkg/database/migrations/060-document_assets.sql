@@ -0,0 +1,41 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- assets_mirrored is the time the files referenced by the document
+-- were mirrored. NULL if they are not mirrored yet.
+ALTER TABLE documents ADD COLUMN assets_mirrored timestamptz;
+
+CREATE INDEX documents_assets_pending_idx ON documents(id)
+    WHERE assets_mirrored IS NULL;
+
+-- assets are the mirrored files referenced by advisories.
+-- The same file referenced by several documents is stored only once.
+CREATE TABLE assets (
+    sha256       bytea   PRIMARY KEY,
+    size         bigint  NOT NULL,
+    content_type varchar,
+    data         bytea   COMPRESSION lz4 NOT NULL
+);
+
+-- document_assets are the results of mirroring the files
+-- referenced by the documents.
+CREATE TABLE document_assets (
+    documents_id  int         NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    url           varchar     NOT NULL,
+    fetched       timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    assets_sha256 bytea       REFERENCES assets(sha256),
+    error         varchar,
+    PRIMARY KEY (documents_id, url),
+    CHECK((assets_sha256 IS NULL) <> (error IS NULL))
+);
+
+CREATE INDEX document_assets_url_idx ON document_assets(url);
+CREATE INDEX document_assets_sha256_idx ON document_assets(assets_sha256);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON assets          TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON document_assets TO {{ .User | sanitize }};
@@ -0,0 +1,56 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- query_subscriptions are the stored queries users want to be
+-- notified about if newly imported documents match them.
+-- tlps are the TLP permissions of the user when subscribing.
+-- last_document is the highest id of the documents already matched.
+CREATE TABLE query_subscriptions (
+    id                int         PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    stored_queries_id int         NOT NULL REFERENCES stored_queries(id) ON DELETE CASCADE,
+    "user"            varchar     NOT NULL,
+    tlps              jsonb       NOT NULL,
+    webhook           varchar,
+    created           timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_document     int         NOT NULL DEFAULT 0,
+    UNIQUE (stored_queries_id, "user"),
+    CHECK(webhook <> '')
+);
+
+-- query_matches are the documents matching the subscribed queries.
+-- delivered is the time the match was sent to the webhook of the subscription.
+CREATE TABLE query_matches (
+    id                     bigint      PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    query_subscriptions_id int         NOT NULL REFERENCES query_subscriptions(id) ON DELETE CASCADE,
+    documents_id           int         NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    matched                timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seen                   boolean     NOT NULL DEFAULT FALSE,
+    delivered              timestamptz,
+    attempts               int         NOT NULL DEFAULT 0,
+    error                  varchar,
+    UNIQUE (query_subscriptions_id, documents_id)
+);
+
+CREATE INDEX query_matches_matched_idx ON query_matches(matched);
+CREATE INDEX query_matches_pending_idx ON query_matches(query_subscriptions_id)
+    WHERE delivered IS NULL;
+
+-- Notify the matching of subscribed queries about new documents.
+CREATE FUNCTION notify_documents_imported() RETURNS trigger AS $$
+    BEGIN
+        PERFORM pg_notify('documents_imported', '');
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER documents_imported
+    AFTER INSERT ON documents
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_documents_imported();
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON query_subscriptions TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON query_matches       TO {{ .User | sanitize }};
@@ -0,0 +1,72 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- document_changes records the imports, updates and deletions
+-- of the documents to be fetched incrementally by external
+-- synchronization jobs. The rows of deleted documents are kept
+-- as tombstones. Changes are ordered by (xid, id) as only the
+-- transactions older than all running ones are final.
+CREATE TYPE document_change AS ENUM (
+    'imported', 'updated', 'deleted'
+);
+
+CREATE TABLE document_changes (
+    id           bigint          PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    xid          xid8            NOT NULL DEFAULT pg_current_xact_id(),
+    documents_id int             NOT NULL,
+    kind         document_change NOT NULL,
+    changed      timestamptz     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    publisher    text            NOT NULL,
+    tracking_id  text            NOT NULL,
+    version      text            NOT NULL,
+    tlp          text
+);
+
+CREATE INDEX document_changes_cursor_idx ON document_changes(xid, id);
+CREATE INDEX document_changes_documents_id_idx ON document_changes(documents_id);
+
+-- record_document_change records a change of a document.
+-- Only changes of the latest flag count as updates. The initial
+-- setting of the flag is part of the import.
+CREATE FUNCTION record_document_change() RETURNS trigger AS $$
+    BEGIN
+        IF TG_OP = 'INSERT' THEN
+            INSERT INTO document_changes
+                (documents_id, kind, publisher, tracking_id, version, tlp)
+                SELECT NEW.id, 'imported', publisher, tracking_id, NEW.version, NEW.tlp
+                FROM advisories WHERE id = NEW.advisories_id;
+        ELSIF TG_OP = 'UPDATE' THEN
+            IF OLD.latest IS NOT NULL AND OLD.latest IS DISTINCT FROM NEW.latest THEN
+                INSERT INTO document_changes
+                    (documents_id, kind, publisher, tracking_id, version, tlp)
+                    SELECT NEW.id, 'updated', publisher, tracking_id, NEW.version, NEW.tlp
+                    FROM advisories WHERE id = NEW.advisories_id;
+            END IF;
+        ELSE
+            -- The advisory may already be gone so take it from the history.
+            INSERT INTO document_changes
+                (documents_id, kind, publisher, tracking_id, version, tlp)
+                SELECT OLD.id, 'deleted', publisher, tracking_id, OLD.version, OLD.tlp
+                FROM document_changes WHERE documents_id = OLD.id
+                ORDER BY id DESC LIMIT 1;
+        END IF;
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER record_document_changes
+    AFTER INSERT OR DELETE OR UPDATE OF latest ON documents
+    FOR EACH ROW EXECUTE FUNCTION record_document_change();
+
+-- The documents already present count as imported.
+INSERT INTO document_changes (documents_id, kind, publisher, tracking_id, version, tlp)
+    SELECT d.id, 'imported', a.publisher, a.tracking_id, d.version, d.tlp
+    FROM documents d JOIN advisories a ON d.advisories_id = a.id
+    ORDER BY d.id;
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON document_changes TO {{ .User | sanitize }};
@@ -0,0 +1,22 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- annotations are remarks of the analysts anchored to a part
+-- of a document addressed by a JSON pointer (RFC 6901).
+CREATE TABLE annotations (
+    id           int            PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    documents_id int            NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    pointer      text           NOT NULL,
+    time         timestamptz    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    author       varchar        NOT NULL,
+    message      varchar(10000) NOT NULL
+);
+
+CREATE INDEX ON annotations(documents_id);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON annotations TO {{ .User | sanitize }};
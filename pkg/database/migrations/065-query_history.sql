@@ -0,0 +1,23 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- query_history are the recently executed ad-hoc document
+-- queries of the users.
+CREATE TABLE query_history (
+    id         int         PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    "user"     varchar     NOT NULL,
+    executed   timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    advisories boolean     NOT NULL DEFAULT FALSE,
+    query      text        NOT NULL,
+    columns    text        NOT NULL DEFAULT '',
+    orders     text        NOT NULL DEFAULT ''
+);
+
+CREATE INDEX ON query_history("user", executed);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON query_history TO {{ .User | sanitize }};
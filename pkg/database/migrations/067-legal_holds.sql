@@ -0,0 +1,35 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+ALTER TYPE events ADD VALUE 'legal_hold';
+ALTER TYPE events ADD VALUE 'release_legal_hold';
+
+-- Legal holds exempt documents from deletion until they are released.
+-- The documents are given explicitly and/or by a documents query.
+CREATE TABLE legal_holds (
+    id       int         PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    name     varchar     NOT NULL,
+    reason   varchar,
+    query    varchar,
+    creator  varchar     NOT NULL,
+    created  timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    releaser varchar,
+    released timestamptz,
+    CHECK(name <> '')
+);
+
+CREATE TABLE legal_hold_documents (
+    legal_holds_id int NOT NULL REFERENCES legal_holds(id) ON DELETE CASCADE,
+    documents_id   int NOT NULL REFERENCES documents(id)   ON DELETE CASCADE,
+    PRIMARY KEY (legal_holds_id, documents_id)
+);
+
+CREATE INDEX ON legal_hold_documents(documents_id);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON legal_holds          TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON legal_hold_documents TO {{ .User | sanitize }};
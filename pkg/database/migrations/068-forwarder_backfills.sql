@@ -0,0 +1,24 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- Backfills queue the already imported documents matching
+-- the current filter of an automatic forward target.
+CREATE TABLE forwarder_backfills (
+    id            int          PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    forwarders_id int          NOT NULL REFERENCES forwarders(id) ON DELETE CASCADE,
+    creator       varchar,
+    started       timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished      timestamptz,
+    status        sweep_status NOT NULL DEFAULT 'running',
+    advisories    int          NOT NULL DEFAULT 0,
+    processed     int          NOT NULL DEFAULT 0,
+    queued        int          NOT NULL DEFAULT 0,
+    error         text
+);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON forwarder_backfills TO {{ .User | sanitize }};
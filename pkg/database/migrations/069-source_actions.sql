@@ -0,0 +1,21 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- source_actions records the state changes the system
+-- performs automatically on the sources.
+CREATE TABLE source_actions (
+    id         bigint      PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    time       timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sources_id int         NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    action     varchar     NOT NULL CHECK (action IN ('deactivated', 'attention', 'pin_mismatch')),
+    message    text        NOT NULL
+);
+
+CREATE INDEX source_actions_sources_id_idx ON source_actions(sources_id, time);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON source_actions TO {{ .User | sanitize }};
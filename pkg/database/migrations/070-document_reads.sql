@@ -0,0 +1,17 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- document_reads records which documents the users have read.
+CREATE TABLE document_reads (
+    documents_id int         NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    "user"       varchar     NOT NULL,
+    time         timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (documents_id, "user")
+);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON document_reads TO {{ .User | sanitize }};
@@ -0,0 +1,27 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- Severity buckets of the highest CVSS score of the documents
+-- following the qualitative rating scale of CVSS v3.
+CREATE TYPE severity AS ENUM (
+    'none', 'low', 'medium', 'high', 'critical');
+
+CREATE FUNCTION severity_bucket(float) RETURNS severity AS $$
+    SELECT CASE
+        WHEN $1 >= 9.0 THEN 'critical'
+        WHEN $1 >= 7.0 THEN 'high'
+        WHEN $1 >= 4.0 THEN 'medium'
+        WHEN $1 > 0.0  THEN 'low'
+        ELSE 'none'
+    END::severity
+$$ LANGUAGE SQL IMMUTABLE;
+
+ALTER TABLE documents ADD COLUMN severity severity GENERATED ALWAYS AS (
+    severity_bucket(coalesce(max_cvss3_score(document), max_cvss2_score(document)))) STORED;
+
+CREATE INDEX documents_severity_idx ON documents(severity);
@@ -0,0 +1,22 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- The events are numbered so that they can be exported in order.
+ALTER TABLE events_log ADD COLUMN id bigint GENERATED BY DEFAULT AS IDENTITY;
+
+CREATE UNIQUE INDEX events_log_id_idx ON events_log(id);
+
+-- siem_cursors stores the last record exported
+-- to the SIEM per kind of record.
+CREATE TABLE siem_cursors (
+    stream  varchar     PRIMARY KEY,
+    last_id bigint      NOT NULL,
+    changed timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON siem_cursors TO {{ .User | sanitize }};
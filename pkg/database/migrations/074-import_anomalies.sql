@@ -0,0 +1,21 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- import_anomalies records the days on which the number of
+-- documents imported from a source deviated strongly from the usual.
+CREATE TABLE import_anomalies (
+    sources_id int         NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    day        date        NOT NULL,
+    kind       varchar     NOT NULL CHECK (kind IN ('silence', 'spike')),
+    imports    bigint      NOT NULL,
+    average    float       NOT NULL,
+    detected   timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (sources_id, day)
+);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON import_anomalies TO {{ .User | sanitize }};
@@ -0,0 +1,23 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- feed_metrics are the outcomes of the downloads of the feeds
+-- summed up per minute.
+CREATE TABLE feed_metrics (
+    feeds_id    int         NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    time        timestamptz NOT NULL,
+    successes   int         NOT NULL DEFAULT 0,
+    failures    int         NOT NULL DEFAULT 0,
+    duration_ms bigint      NOT NULL DEFAULT 0,
+    bytes       bigint      NOT NULL DEFAULT 0,
+    PRIMARY KEY (feeds_id, time)
+);
+
+CREATE INDEX feed_metrics_time_idx ON feed_metrics(time);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON feed_metrics TO {{ .User | sanitize }};
@@ -0,0 +1,89 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- teams are the organizational units of the users.
+-- The members of a team are taken from its Keycloak group.
+-- leads are the users allowed to assign the work of the team.
+CREATE TABLE teams (
+    id          int       PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    name        varchar   NOT NULL UNIQUE,
+    description varchar,
+    group_name  varchar   UNIQUE,
+    parent_id   int       REFERENCES teams(id) ON DELETE SET NULL,
+    leads       varchar[] NOT NULL DEFAULT '{}',
+    CHECK(name <> ''),
+    CHECK(group_name <> ''),
+    CHECK(parent_id <> id)
+);
+
+-- team_members are the users of the teams as seen
+-- in their Keycloak groups on their last login.
+CREATE TABLE team_members (
+    teams_id int         NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    "user"   varchar     NOT NULL,
+    seen     timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (teams_id, "user")
+);
+
+CREATE INDEX team_members_user_idx ON team_members("user");
+
+-- team_sources are the sources a team is responsible for.
+CREATE TABLE team_sources (
+    teams_id   int NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    sources_id int NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    PRIMARY KEY (teams_id, sources_id)
+);
+
+-- team_subtree returns a team and all its sub-teams.
+CREATE FUNCTION team_subtree(team int) RETURNS SETOF int AS $$
+    WITH RECURSIVE tree(id) AS (
+        SELECT team
+        UNION
+        SELECT teams.id FROM teams JOIN tree ON teams.parent_id = tree.id
+    ) SELECT id FROM tree
+$$ LANGUAGE SQL STABLE;
+
+-- user_teams returns the teams a user is a member of
+-- together with all their parent teams.
+CREATE FUNCTION user_teams(member varchar) RETURNS SETOF int AS $$
+    WITH RECURSIVE tree(id) AS (
+        SELECT teams_id FROM team_members WHERE "user" = member
+        UNION
+        SELECT teams.parent_id FROM teams JOIN tree ON teams.id = tree.id
+        WHERE teams.parent_id IS NOT NULL
+    ) SELECT id FROM tree
+$$ LANGUAGE SQL STABLE;
+
+-- led_teams returns the teams led by a user together with all their sub-teams.
+CREATE FUNCTION led_teams(leader varchar) RETURNS SETOF int AS $$
+    WITH RECURSIVE tree(id) AS (
+        SELECT id FROM teams WHERE leader = ANY(leads)
+        UNION
+        SELECT teams.id FROM teams JOIN tree ON teams.parent_id = tree.id
+    ) SELECT id FROM tree
+$$ LANGUAGE SQL STABLE;
+
+-- Stored queries shared with a team are visible to its members
+-- and the members of its sub-teams.
+ALTER TABLE stored_queries
+    ADD COLUMN teams_id int REFERENCES teams(id) ON DELETE SET NULL;
+
+-- Subscriptions of a team are created by a team lead and
+-- their matches are shared by the members of the team.
+ALTER TABLE query_subscriptions
+    ADD COLUMN teams_id int REFERENCES teams(id) ON DELETE CASCADE;
+ALTER TABLE query_subscriptions
+    DROP CONSTRAINT query_subscriptions_stored_queries_id_user_key;
+CREATE UNIQUE INDEX query_subscriptions_user_idx
+    ON query_subscriptions(stored_queries_id, "user") WHERE teams_id IS NULL;
+CREATE UNIQUE INDEX query_subscriptions_team_idx
+    ON query_subscriptions(stored_queries_id, teams_id) WHERE teams_id IS NOT NULL;
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON teams        TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON team_members TO {{ .User | sanitize }};
+GRANT INSERT, DELETE, SELECT, UPDATE ON team_sources TO {{ .User | sanitize }};
@@ -0,0 +1,23 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- document_transformations are the fields of the documents changed
+-- by the transformation rules at import. original is the upstream value.
+CREATE TABLE document_transformations (
+    documents_id int     NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    num          int     NOT NULL,
+    rule         varchar NOT NULL,
+    path         varchar NOT NULL,
+    original     varchar NOT NULL,
+    replaced     varchar NOT NULL,
+    PRIMARY KEY (documents_id, num)
+);
+
+CREATE INDEX document_transformations_rule_idx ON document_transformations(rule);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON document_transformations TO {{ .User | sanitize }};
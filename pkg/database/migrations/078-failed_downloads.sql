@@ -0,0 +1,27 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- failed_downloads are the advisories of the feeds which could not
+-- be downloaded or imported after all retries.
+CREATE TABLE failed_downloads (
+    id           int         PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    feeds_id     int         NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    url          varchar     NOT NULL,
+    hash         varchar,
+    signature    varchar,
+    updated      timestamptz NOT NULL,
+    attempts     int         NOT NULL,
+    first_failed timestamptz NOT NULL DEFAULT current_timestamp,
+    last_failed  timestamptz NOT NULL DEFAULT current_timestamp,
+    error        varchar     NOT NULL,
+    dismissed    bool        NOT NULL DEFAULT FALSE,
+    UNIQUE(feeds_id, url),
+    CHECK(url <> '')
+);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON failed_downloads TO {{ .User | sanitize }};
@@ -0,0 +1,14 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- The priority class of a source influences the order of the downloads,
+-- the refresh frequency of its feeds and the order of the notifications.
+CREATE TYPE source_priority AS ENUM (
+    'critical', 'normal', 'low');
+
+ALTER TABLE sources ADD COLUMN priority source_priority NOT NULL DEFAULT 'normal';
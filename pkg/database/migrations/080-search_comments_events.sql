@@ -0,0 +1,37 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- Full text search in the comments and the event log.
+-- The simple configuration does no stemming as the analysts
+-- write in different languages.
+ALTER TABLE comments ADD COLUMN ts tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(message, ''))) STORED;
+
+CREATE INDEX comments_ts_idx ON comments USING gin(ts);
+
+-- The enums are not immutable so the text vector of
+-- the event log is maintained by a trigger.
+ALTER TABLE events_log ADD COLUMN ts tsvector;
+
+CREATE FUNCTION events_log_ts() RETURNS trigger AS $$
+    BEGIN
+        NEW.ts = to_tsvector('simple', concat_ws(' ', NEW.event, NEW.state, NEW.actor));
+        RETURN NEW;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER events_log_ts
+    BEFORE INSERT OR UPDATE OF event, state, actor
+    ON events_log
+    FOR EACH ROW EXECUTE FUNCTION events_log_ts();
+
+ALTER TABLE events_log DISABLE TRIGGER update_recent;
+UPDATE events_log SET ts = to_tsvector('simple', concat_ws(' ', event, state, actor));
+ALTER TABLE events_log ENABLE TRIGGER update_recent;
+
+CREATE INDEX events_log_ts_idx ON events_log USING gin(ts);
@@ -0,0 +1,32 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- receipt_url is the callback of the provider of a source
+-- acknowledging the successfully imported advisories.
+ALTER TABLE sources ADD COLUMN receipt_url varchar CHECK(receipt_url <> '');
+
+-- advisory_receipts are the receipts waiting to be sent to the
+-- providers or already sent. callback and url are the receipt URL
+-- of the source and the URL of the advisory at the time of the import.
+CREATE TABLE advisory_receipts (
+    id           bigint      PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    sources_id   int         NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    documents_id int         NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    callback     varchar     NOT NULL,
+    url          varchar     NOT NULL,
+    received     timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered    timestamptz,
+    attempts     int         NOT NULL DEFAULT 0,
+    error        varchar
+);
+
+CREATE INDEX advisory_receipts_received_idx ON advisory_receipts(received);
+CREATE INDEX advisory_receipts_pending_idx ON advisory_receipts(id)
+    WHERE delivered IS NULL;
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON advisory_receipts TO {{ .User | sanitize }};
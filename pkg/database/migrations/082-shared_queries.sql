@@ -0,0 +1,16 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- shared_roles shares the query with all users having
+-- at least one of the Keycloak roles.
+-- cloned_from is the shared query this query was cloned from.
+ALTER TABLE stored_queries
+    ADD COLUMN shared_roles varchar[] NOT NULL DEFAULT '{}',
+    ADD COLUMN cloned_from  int REFERENCES stored_queries(id) ON DELETE SET NULL;
+
+CREATE INDEX stored_queries_shared_roles_idx ON stored_queries USING gin(shared_roles);
@@ -0,0 +1,34 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- forward_log records every attempt to forward a document to a target.
+-- The document is kept by its identity to prove the delivery
+-- even after the document is deleted.
+CREATE TYPE forward_trigger AS ENUM (
+    'automatic', 'manual', 'replay');
+
+CREATE TABLE forward_log (
+    id           bigint          PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    time         timestamptz     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    target       varchar         NOT NULL,
+    documents_id int             REFERENCES documents(id) ON DELETE SET NULL,
+    publisher    varchar         NOT NULL,
+    tracking_id  varchar         NOT NULL,
+    version      varchar         NOT NULL,
+    trigger      forward_trigger NOT NULL,
+    actor        varchar,
+    delivered    boolean         NOT NULL,
+    status_code  int,
+    response     text,
+    error        text
+);
+
+CREATE INDEX forward_log_time_idx         ON forward_log(time);
+CREATE INDEX forward_log_documents_id_idx ON forward_log(documents_id);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON forward_log TO {{ .User | sanitize }};
@@ -0,0 +1,32 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- forward_targets are the forward targets managed via the API.
+-- The secrets are encrypted with the key of the sources.
+CREATE TABLE forward_targets (
+    forwarders_id       int         PRIMARY KEY REFERENCES forwarders(id) ON DELETE CASCADE,
+    name                varchar,
+    type                varchar     NOT NULL DEFAULT 'default',
+    automatic           boolean     NOT NULL DEFAULT FALSE,
+    strategy            varchar,
+    publisher           varchar,
+    headers             varchar[],
+    timeout             interval,
+    tlp                 varchar,
+    password            bytea,
+    passphrase          bytea,
+    client_cert_public  bytea,
+    client_cert_private bytea,
+    health_url          varchar,
+    health_method       varchar,
+    creator             varchar,
+    created             timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated             timestamptz
+);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON forward_targets TO {{ .User | sanitize }};
@@ -0,0 +1,17 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- tlp_allowed checks if the documents of a publisher with a TLP label
+-- are visible under the TLP rules of a user. The rules map publishers
+-- to lists of TLP labels. The publisher '*' stands for all publishers
+-- which are not listed. Documents without a TLP label are not visible.
+CREATE FUNCTION tlp_allowed(publisher text, tlp text, rules jsonb) RETURNS boolean AS $$
+    SELECT coalesce(
+        CASE WHEN rules ? publisher THEN rules -> publisher ELSE rules -> '*' END ? tlp,
+        false)
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
@@ -0,0 +1,27 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- recompute_jobs recompute the derived fields of the stored documents
+-- in batches. last_id is the highest id of the documents processed
+-- so far. Interrupted jobs are resumed from there.
+CREATE TABLE recompute_jobs (
+    id        int          PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    creator   varchar,
+    started   timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished  timestamptz,
+    status    sweep_status NOT NULL DEFAULT 'running',
+    fields    varchar[]    NOT NULL,
+    publisher text,
+    total     int          NOT NULL DEFAULT 0,
+    processed int          NOT NULL DEFAULT 0,
+    skipped   int          NOT NULL DEFAULT 0,
+    last_id   int          NOT NULL DEFAULT 0,
+    error     text
+);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON recompute_jobs TO {{ .User | sanitize }};
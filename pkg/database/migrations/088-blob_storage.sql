@@ -0,0 +1,43 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- The original uploads can be kept outside of the database.
+-- original_key is the key of the upload in the blob storage
+-- and original_size its size then.
+ALTER TABLE documents
+    ALTER COLUMN original DROP NOT NULL,
+    ADD COLUMN original_key varchar,
+    ADD COLUMN original_size int,
+    ADD CONSTRAINT documents_original_check
+        CHECK (original IS NOT NULL OR original_key IS NOT NULL);
+
+CREATE INDEX documents_original_key_idx ON documents (original_key)
+    WHERE original_key IS NOT NULL;
+
+-- deleted_blobs are the keys of the original uploads of deleted
+-- documents which have to be removed from the blob storage.
+CREATE TABLE deleted_blobs (
+    key     varchar     PRIMARY KEY,
+    deleted timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE FUNCTION record_deleted_blob() RETURNS trigger AS $$
+    BEGIN
+        INSERT INTO deleted_blobs (key) VALUES (OLD.original_key)
+            ON CONFLICT (key) DO UPDATE SET deleted = CURRENT_TIMESTAMP;
+        RETURN OLD;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER record_deleted_blob_trigger AFTER DELETE
+    ON documents
+    FOR EACH ROW
+    WHEN (OLD.original_key IS NOT NULL)
+    EXECUTE FUNCTION record_deleted_blob();
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON deleted_blobs TO {{ .User | sanitize }};
@@ -0,0 +1,33 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- group_tlps are the TLP levels of the publishers the members
+-- of a Keycloak group may see in addition to their own rules.
+-- '*' as publisher matches all publishers without own rules.
+CREATE TABLE group_tlps (
+    group_name varchar NOT NULL,
+    publisher  varchar NOT NULL,
+    tlps       varchar[] NOT NULL DEFAULT '{}',
+    PRIMARY KEY (group_name, publisher),
+    CHECK(group_name <> ''),
+    CHECK(publisher <> ''),
+    CHECK(tlps <@ '{WHITE,GREEN,AMBER,RED}'::varchar[])
+);
+
+CREATE FUNCTION notify_group_tlps_changes() RETURNS trigger AS $$
+    BEGIN
+        PERFORM pg_notify('group_tlps_changes', '');
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER group_tlps_changes
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON group_tlps
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_group_tlps_changes();
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON group_tlps TO {{ .User | sanitize }};
@@ -0,0 +1,26 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+ALTER TYPE events ADD VALUE 'sla_escalation';
+
+-- sla_escalations are the stays of the advisories in workflow
+-- states which took longer than the configured limits.
+-- Every stay in a state is escalated only once.
+CREATE TABLE sla_escalations (
+    id            int         PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    advisories_id int         NOT NULL REFERENCES advisories(id) ON DELETE CASCADE,
+    state         workflow    NOT NULL,
+    entered       timestamptz NOT NULL,
+    deadline      timestamptz NOT NULL,
+    escalated     timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (advisories_id, state, entered)
+);
+
+CREATE INDEX sla_escalations_escalated_idx ON sla_escalations(escalated);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON sla_escalations TO {{ .User | sanitize }};
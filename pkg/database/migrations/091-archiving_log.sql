@@ -0,0 +1,32 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+CREATE TYPE archiving_outcome AS ENUM (
+    'done', 'held', 'failed');
+
+-- archiving_log records what the archiving policies did with the
+-- advisories. Dry runs record what the policies would have done.
+-- Every stay of an advisory in a state is recorded once per outcome.
+CREATE TABLE archiving_log (
+    id          bigint            PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    time        timestamptz       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    policy      varchar           NOT NULL,
+    action      varchar           NOT NULL,
+    dry_run     boolean           NOT NULL,
+    outcome     archiving_outcome NOT NULL,
+    publisher   text              NOT NULL,
+    tracking_id text              NOT NULL,
+    state       workflow          NOT NULL,
+    entered     timestamptz       NOT NULL,
+    error       varchar,
+    UNIQUE (policy, publisher, tracking_id, entered, dry_run, outcome)
+);
+
+CREATE INDEX archiving_log_time_idx ON archiving_log(time);
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON archiving_log TO {{ .User | sanitize }};
@@ -0,0 +1,24 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- reason optionally explains why the SSVC vector was changed.
+ALTER TABLE ssvc_history ADD COLUMN reason text;
+
+-- The SSVC history is an audit trail. Its records are never modified.
+CREATE FUNCTION reject_ssvc_history_update()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    RAISE EXCEPTION 'ssvc_history records are immutable';
+END;
+$$;
+
+CREATE TRIGGER ssvc_history_immutable
+BEFORE UPDATE ON ssvc_history
+FOR EACH ROW EXECUTE FUNCTION reject_ssvc_history_update();
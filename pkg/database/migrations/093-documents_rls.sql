@@ -0,0 +1,49 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- session_tlp_allowed checks if the documents of a publisher with a TLP
+-- label are visible under the TLP rules in the setting isduba.tlp_rules
+-- of the session. Sessions without rules, like the ones of the background
+-- tasks, see all documents.
+CREATE FUNCTION session_tlp_allowed(publisher text, tlp text) RETURNS boolean AS $$
+    SELECT CASE coalesce(current_setting('isduba.tlp_rules', true), '')
+        WHEN '' THEN true
+        ELSE tlp_allowed(publisher, tlp, current_setting('isduba.tlp_rules', true)::jsonb)
+    END
+$$ LANGUAGE SQL STABLE PARALLEL SAFE;
+
+-- document_visible checks if a document of an advisory with
+-- a TLP label is visible under the TLP rules of the session.
+CREATE FUNCTION document_visible(adv_id int, tlp text) RETURNS boolean AS $$
+    SELECT session_tlp_allowed(
+        (SELECT publisher FROM advisories WHERE id = adv_id), tlp)
+$$ LANGUAGE SQL STABLE PARALLEL SAFE;
+
+-- The documents are only visible within the TLP rules of the session.
+-- Rows which are not visible can neither be updated nor deleted.
+ALTER TABLE documents ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY documents_visible ON documents FOR SELECT
+    USING (document_visible(advisories_id, tlp));
+CREATE POLICY documents_insert ON documents FOR INSERT
+    WITH CHECK (true);
+CREATE POLICY documents_update ON documents FOR UPDATE
+    USING (true);
+CREATE POLICY documents_delete ON documents FOR DELETE
+    USING (true);
+
+-- The book keeping of the advisories has to see all documents.
+ALTER FUNCTION update_advisory()            SECURITY DEFINER SET search_path = public, pg_temp;
+ALTER FUNCTION delete_advisory()            SECURITY DEFINER SET search_path = public, pg_temp;
+ALTER FUNCTION update_upstream_changed()    SECURITY DEFINER SET search_path = public, pg_temp;
+ALTER FUNCTION update_localized_title(int)  SECURITY DEFINER SET search_path = public, pg_temp;
+ALTER FUNCTION incr_comments()              SECURITY DEFINER SET search_path = public, pg_temp;
+ALTER FUNCTION decr_comments()              SECURITY DEFINER SET search_path = public, pg_temp;
+ALTER FUNCTION upd_recent()                 SECURITY DEFINER SET search_path = public, pg_temp;
+ALTER FUNCTION extract_cves()               SECURITY DEFINER SET search_path = public, pg_temp;
+ALTER FUNCTION log_ssvc_history_to_events() SECURITY DEFINER SET search_path = public, pg_temp;
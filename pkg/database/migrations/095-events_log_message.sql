@@ -0,0 +1,24 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- message describes events whose documents are gone,
+-- like the deletions done by the archiving policies.
+ALTER TABLE events_log ADD COLUMN message text;
+
+CREATE OR REPLACE FUNCTION events_log_ts() RETURNS trigger AS $$
+    BEGIN
+        NEW.ts = to_tsvector('simple', concat_ws(' ', NEW.event, NEW.state, NEW.actor, NEW.message));
+        RETURN NEW;
+    END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER events_log_ts ON events_log;
+CREATE TRIGGER events_log_ts
+    BEFORE INSERT OR UPDATE OF event, state, actor, message
+    ON events_log
+    FOR EACH ROW EXECUTE FUNCTION events_log_ts();
@@ -0,0 +1,12 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- The exports of restricted documents in signed archives are recorded, too.
+ALTER TABLE document_accesses DROP CONSTRAINT document_accesses_kind_check;
+ALTER TABLE document_accesses ADD CONSTRAINT document_accesses_kind_check
+    CHECK (kind IN ('download', 'share', 'export'));
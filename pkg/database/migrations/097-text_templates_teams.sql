@@ -0,0 +1,15 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- Text templates are shared with teams instead of roles.
+-- Templates shared with a role cannot be mapped to a team and
+-- are made private to their definers.
+ALTER TABLE text_templates
+    ADD COLUMN teams_id int REFERENCES teams(id) ON DELETE SET NULL;
+UPDATE text_templates SET global = FALSE WHERE role IS NOT NULL;
+ALTER TABLE text_templates DROP COLUMN role;
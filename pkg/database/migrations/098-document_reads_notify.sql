@@ -0,0 +1,31 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- Changed read states only invalidate the cached searches
+-- of the affected users which filter for the read state.
+DROP TRIGGER IF EXISTS document_reads_search_changes ON document_reads;
+
+CREATE FUNCTION notify_search_reads_changes() RETURNS trigger AS $$
+    BEGIN
+        IF TG_OP IN ('UPDATE', 'DELETE') THEN
+            PERFORM pg_notify('search_reads_changes', OLD."user");
+        END IF;
+        IF TG_OP IN ('INSERT', 'UPDATE') THEN
+            PERFORM pg_notify('search_reads_changes', NEW."user");
+        END IF;
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER document_reads_search_reads_changes
+    AFTER INSERT OR UPDATE OF documents_id, "user" OR DELETE ON document_reads
+    FOR EACH ROW EXECUTE FUNCTION notify_search_reads_changes();
+
+CREATE TRIGGER document_reads_search_changes
+    AFTER TRUNCATE ON document_reads
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_search_changes();
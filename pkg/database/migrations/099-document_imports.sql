@@ -0,0 +1,48 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- document_imports numbers the imported documents in the order their
+-- imports are committed. The documents ids are assigned when inserting
+-- and parallel imports may commit them out of order.
+CREATE TABLE document_imports (
+    seq          bigint PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    documents_id int    NOT NULL REFERENCES documents(id) ON DELETE CASCADE
+);
+
+-- The lock is held until the end of the importing transaction
+-- so the numbers are visible in the order they are drawn.
+-- Imports are only recorded if there are subscriptions to match.
+CREATE FUNCTION record_document_import() RETURNS trigger AS $$
+    BEGIN
+        LOCK TABLE document_imports IN EXCLUSIVE MODE;
+        INSERT INTO document_imports (documents_id)
+            SELECT NEW.id
+            WHERE EXISTS (SELECT 1 FROM query_subscriptions)
+            AND EXISTS (SELECT 1 FROM documents WHERE id = NEW.id);
+        RETURN NULL;
+    END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public, pg_temp;
+
+CREATE CONSTRAINT TRIGGER record_document_import
+    AFTER INSERT ON documents
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION record_document_import();
+
+-- The documents not matched by all subscriptions yet keep their order.
+INSERT INTO document_imports (documents_id)
+    SELECT id FROM documents
+    WHERE id > (SELECT min(last_document) FROM query_subscriptions)
+    ORDER BY id;
+
+-- last_import is the highest number of the imports already matched.
+ALTER TABLE query_subscriptions ADD COLUMN last_import bigint NOT NULL DEFAULT 0;
+UPDATE query_subscriptions qs SET last_import = coalesce(
+    (SELECT max(seq) FROM document_imports WHERE documents_id <= qs.last_document), 0);
+ALTER TABLE query_subscriptions DROP COLUMN last_document;
+
+GRANT INSERT, DELETE, SELECT, UPDATE ON document_imports TO {{ .User | sanitize }};
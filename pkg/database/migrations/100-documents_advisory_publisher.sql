@@ -0,0 +1,45 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+-- advisory_publisher is the publisher of the advisory of a document.
+-- It is kept with the document so the row level security does not
+-- have to look up the advisory of every row.
+ALTER TABLE documents ADD COLUMN advisory_publisher text;
+
+ALTER TABLE documents DISABLE TRIGGER USER;
+UPDATE documents SET advisory_publisher = advisories.publisher
+    FROM advisories WHERE advisories.id = documents.advisories_id;
+ALTER TABLE documents ENABLE TRIGGER USER;
+
+ALTER TABLE documents ALTER COLUMN advisory_publisher SET NOT NULL;
+
+CREATE FUNCTION set_advisory_publisher() RETURNS trigger AS $$
+    BEGIN
+        NEW.advisory_publisher := (
+            SELECT publisher FROM advisories WHERE id = NEW.advisories_id);
+        RETURN NEW;
+    END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER set_advisory_publisher
+    BEFORE INSERT OR UPDATE OF advisories_id ON documents
+    FOR EACH ROW EXECUTE FUNCTION set_advisory_publisher();
+
+-- session_tlp_rules returns the TLP rules of the session or NULL
+-- if there are none.
+CREATE FUNCTION session_tlp_rules() RETURNS jsonb AS $$
+    SELECT nullif(current_setting('isduba.tlp_rules', true), '')::jsonb
+$$ LANGUAGE SQL STABLE PARALLEL SAFE;
+
+-- The rules are sub-selects so they are only parsed once per statement.
+DROP POLICY documents_visible ON documents;
+CREATE POLICY documents_visible ON documents FOR SELECT
+    USING ((SELECT session_tlp_rules()) IS NULL
+        OR tlp_allowed(advisory_publisher, tlp, (SELECT session_tlp_rules())));
+
+DROP FUNCTION document_visible(int, text);
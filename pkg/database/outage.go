@@ -0,0 +1,208 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package database
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrUnavailable is returned by [DB.Run] while the database is not reachable.
+var ErrUnavailable = errors.New("database is unavailable")
+
+// OutageEvent reports a change of the availability of the database.
+type OutageEvent struct {
+	// Available is true if the database was recovered.
+	Available bool
+	// Since is the start of the outage.
+	Since time.Time
+	// Err is the error which revealed the outage.
+	Err error
+}
+
+// Status is the current availability of the database.
+type Status struct {
+	Available bool       `json:"available"`
+	Since     *time.Time `json:"since,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	Outages   int64      `json:"outages"`
+}
+
+// outage keeps track of the availability of the database.
+type outage struct {
+	mu        sync.Mutex
+	down      bool
+	since     time.Time
+	err       error
+	count     int64
+	recovered chan struct{}
+	listeners []func(OutageEvent)
+	done      chan struct{}
+}
+
+// IsConnectionError returns true if the error is caused
+// by a lost or refused connection to the database.
+func IsConnectionError(err error) bool {
+	if err == nil ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrUnavailable) {
+		return true
+	}
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "57P01", "57P02", "57P03": // admin_shutdown, crash_shutdown, cannot_connect_now
+			return true
+		}
+		// Class 08: connection exception
+		return strings.HasPrefix(pgErr.Code, "08")
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
+// OnOutage registers a function which is called when the database
+// becomes unavailable and when it is available again.
+// The function is called from a separate Go routine.
+func (db *DB) OnOutage(fn func(OutageEvent)) {
+	db.outage.mu.Lock()
+	defer db.outage.mu.Unlock()
+	db.outage.listeners = append(db.outage.listeners, fn)
+}
+
+// Available returns true if the database is reachable.
+func (db *DB) Available() bool {
+	db.outage.mu.Lock()
+	defer db.outage.mu.Unlock()
+	return !db.outage.down
+}
+
+// Status returns the current availability of the database.
+func (db *DB) Status() Status {
+	db.outage.mu.Lock()
+	defer db.outage.mu.Unlock()
+	s := Status{
+		Available: !db.outage.down,
+		Outages:   db.outage.count,
+	}
+	if db.outage.down {
+		since := db.outage.since
+		s.Since = &since
+		s.LastError = db.outage.err.Error()
+	}
+	return s
+}
+
+// WaitAvailable blocks until the database is reachable
+// or the context is cancelled.
+func (db *DB) WaitAvailable(ctx context.Context) error {
+	db.outage.mu.Lock()
+	if !db.outage.down {
+		db.outage.mu.Unlock()
+		return nil
+	}
+	recovered := db.outage.recovered
+	db.outage.mu.Unlock()
+	select {
+	case <-recovered:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-db.outage.done:
+		return ErrUnavailable
+	}
+}
+
+// failed checks if the error is caused by a lost connection.
+// If so the database is marked as unavailable and re-checked
+// in the background till it is reachable again.
+func (db *DB) failed(err error) {
+	if errors.Is(err, ErrUnavailable) || !IsConnectionError(err) {
+		return
+	}
+	db.outage.mu.Lock()
+	if db.outage.down {
+		db.outage.mu.Unlock()
+		return
+	}
+	db.outage.down = true
+	db.outage.since = time.Now()
+	db.outage.err = err
+	db.outage.count++
+	db.outage.recovered = make(chan struct{})
+	ev := OutageEvent{Since: db.outage.since, Err: err}
+	db.outage.mu.Unlock()
+
+	slog.Error("database unavailable, pausing", "error", err)
+	db.notify(ev)
+	go db.reconnect()
+}
+
+// reconnect pings the database with an exponential backoff
+// till it is reachable again.
+func (db *DB) reconnect() {
+	wait := db.retryMin
+	for {
+		select {
+		case <-db.outage.done:
+			return
+		case <-time.After(wait):
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), db.retryMax)
+		err := db.pool.Ping(ctx)
+		cancel()
+		if err == nil {
+			break
+		}
+		slog.Debug("database still unavailable", "error", err, "retry", wait)
+		db.outage.mu.Lock()
+		db.outage.err = err
+		db.outage.mu.Unlock()
+		wait = min(2*wait, db.retryMax)
+	}
+	db.outage.mu.Lock()
+	db.outage.down = false
+	close(db.outage.recovered)
+	ev := OutageEvent{Available: true, Since: db.outage.since}
+	db.outage.mu.Unlock()
+
+	slog.Info("database available again, resuming", "outage", time.Since(ev.Since))
+	db.notify(ev)
+}
+
+// notify calls the registered outage listeners.
+func (db *DB) notify(ev OutageEvent) {
+	db.outage.mu.Lock()
+	listeners := db.outage.listeners
+	db.outage.mu.Unlock()
+	for _, fn := range listeners {
+		go fn(ev)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ISDuBA/ISDuBA/pkg/itertools"
 )
@@ -29,6 +30,8 @@ type AdvancedSQLBuilder struct {
 	replToIdx    map[string]int
 	usedSources  columnSource
 	aggregate    bool
+	asOf         *time.Time
+	scope        SearchScope
 }
 
 type statementMode interface {
@@ -38,6 +41,7 @@ type statementMode interface {
 	searchWhere(sb *AdvancedSQLBuilder, e *Expr, b *strings.Builder)
 	mentionedWhere(sb *AdvancedSQLBuilder, e *Expr, b *strings.Builder)
 	involvedWhere(sb *AdvancedSQLBuilder, e *Expr, b *strings.Builder)
+	seenWhere(sb *AdvancedSQLBuilder, e *Expr, b *strings.Builder)
 	ilikePNameWhere(sb *AdvancedSQLBuilder, e *Expr, b *strings.Builder)
 	ilikePIDWhere(sb *AdvancedSQLBuilder, e *Expr, b *strings.Builder)
 	order(sb *AdvancedSQLBuilder, b *strings.Builder, name string)
@@ -54,7 +58,7 @@ func (classicMode) projectionCommon(
 	versionsCount, commentsCountDocuments string,
 ) {
 	switch name {
-	case "state", "event", "tracking_status":
+	case "state", "event", "tracking_status", "severity":
 		b.WriteString(name)
 		b.WriteString("::text")
 	case "event_state":
@@ -89,6 +93,10 @@ func (cm classicMode) projection(sb *AdvancedSQLBuilder, b *strings.Builder, nam
 		b.WriteString(name)
 	case "ssvc":
 		b.WriteString("ssvc_current.ssvc AS ssvc")
+	case "latest":
+		b.WriteString(sb.latestColumn() + " AS latest")
+	case "staleness":
+		b.WriteString(sb.stalenessColumn() + " AS staleness")
 	default:
 		cm.projectionCommon(sb, b, name,
 			versionsCountClassic, commentsCountDocumentsClassic)
@@ -119,12 +127,13 @@ func (cm cteMode) projection(sb *AdvancedSQLBuilder, b *strings.Builder, name st
 func (classicMode) from(sb *AdvancedSQLBuilder, b *strings.Builder) {
 	switch sb.mode() {
 	case AdvisoryMode, DocumentMode:
-		b.WriteString(`documents ` +
-			`JOIN advisories ON ` +
+		b.WriteString(sb.documentsSource() + ` ` +
+			`JOIN ` + sb.advisoriesSource() + ` ON ` +
 			`advisories.id = documents.advisories_id`)
 	case EventMode:
-		b.WriteString(`events_log JOIN documents ON events_log.documents_id = documents.id ` +
-			`JOIN advisories ON advisories.id = documents.advisories_id ` +
+		b.WriteString(sb.eventsSource() + ` ` +
+			`JOIN ` + sb.documentsSource() + ` ON events_log.documents_id = documents.id ` +
+			`JOIN ` + sb.advisoriesSource() + ` ON advisories.id = documents.advisories_id ` +
 			`LEFT JOIN (SELECT message, id FROM comments) AS comment ON events_log.comments_id = comment.id`)
 	}
 	// Add SSVC if exists
@@ -132,6 +141,7 @@ func (classicMode) from(sb *AdvancedSQLBuilder, b *strings.Builder) {
 		b.WriteString(` LEFT JOIN LATERAL ( ` +
 			`SELECT ssvc FROM ssvc_history ` +
 			`WHERE documents_id = documents.id ` +
+			sb.ssvcAsOf() +
 			`ORDER BY changedate DESC, change_number DESC LIMIT 1 ` +
 			`) AS ssvc_current ON TRUE`)
 	}
@@ -142,8 +152,9 @@ func (classicMode) from(sb *AdvancedSQLBuilder, b *strings.Builder) {
 }
 
 // createUnaliasedSearches creates a CROSS JOIN LATERAL to filter searches with no aliases.
+// Scoped searches are filtered in the WHERE clause instead.
 func (sb *AdvancedSQLBuilder) createUnaliasedSearches(b *strings.Builder) {
-	if sb.expr == nil {
+	if sb.expr == nil || sb.scoped() {
 		return
 	}
 	texts := slices.Sorted(sb.expr.UnaliasedSearches())
@@ -202,7 +213,7 @@ func (cteMode) from(sb *AdvancedSQLBuilder, b *strings.Builder) {
 	case AdvisoryMode, DocumentMode:
 		b.WriteString(`docads`)
 	case EventMode:
-		b.WriteString(`events_log JOIN docads ON events_log.documents_id = docads.id ` +
+		b.WriteString(sb.eventsSource() + ` JOIN docads ON events_log.documents_id = docads.id ` +
 			`LEFT JOIN (SELECT message, id FROM comments) AS comment ` +
 			`ON events_log.comments_id = comment.id`)
 	}
@@ -247,6 +258,10 @@ func (cm classicMode) accessWhere(sb *AdvancedSQLBuilder, e *Expr, b *strings.Bu
 		b.WriteString(column)
 	case "ssvc":
 		b.WriteString("ssvc_current.ssvc")
+	case "latest":
+		b.WriteString(sb.latestColumn())
+	case "staleness":
+		b.WriteString(sb.stalenessColumn())
 	default:
 		cm.accessWhereCommon(sb, e, b,
 			versionsCountClassic, commentsCountDocumentsClassic)
@@ -267,12 +282,24 @@ func (cm cteMode) accessWhere(sb *AdvancedSQLBuilder, e *Expr, b *strings.Builde
 	}
 }
 
-func (classicMode) searchWhere(_ *AdvancedSQLBuilder, _ *Expr, b *strings.Builder) {
+func (classicMode) searchWhere(sb *AdvancedSQLBuilder, e *Expr, b *strings.Builder) {
+	if sb.scoped() && e.alias == "" {
+		sb.scopedSearchWhere(e, b, "documents.id")
+		return
+	}
 	// The Filtering is done by a CROSS JOIN LATERAL so we insert a true here
 	// to be optimzed away by the query planner.
 	b.WriteString("TRUE")
 }
 
+func (cm cteMode) searchWhere(sb *AdvancedSQLBuilder, e *Expr, b *strings.Builder) {
+	if sb.scoped() && e.alias == "" {
+		sb.scopedSearchWhere(e, b, "docads.id")
+		return
+	}
+	cm.classicMode.searchWhere(sb, e, b)
+}
+
 func (classicMode) mentionedWhereCommon(sb *AdvancedSQLBuilder, e *Expr, b *strings.Builder) {
 	switch sb.mode() {
 	case EventMode:
@@ -345,6 +372,30 @@ func (cteMode) involvedWhere(sb *AdvancedSQLBuilder, e *Expr, b *strings.Builder
 	}
 }
 
+func (classicMode) seenWhereCommon(sb *AdvancedSQLBuilder, e *Expr, b *strings.Builder, docs string) {
+	fmt.Fprintf(b, "EXISTS(SELECT 1 FROM document_reads WHERE \"user\" = $%d "+
+		"AND document_reads.documents_id = %s)",
+		sb.replacementIndex(e.stringValue)+1, docs)
+}
+
+func (cm classicMode) seenWhere(sb *AdvancedSQLBuilder, e *Expr, b *strings.Builder) {
+	switch sb.mode() {
+	case EventMode:
+		cm.seenWhereCommon(sb, e, b, "events_log.documents_id")
+	default:
+		cm.seenWhereCommon(sb, e, b, "documents.id")
+	}
+}
+
+func (cm cteMode) seenWhere(sb *AdvancedSQLBuilder, e *Expr, b *strings.Builder) {
+	switch sb.mode() {
+	case EventMode:
+		cm.seenWhereCommon(sb, e, b, "events_log.documents_id")
+	default:
+		cm.seenWhereCommon(sb, e, b, "docads.id")
+	}
+}
+
 func (cm classicMode) ilikePNameWhere(sb *AdvancedSQLBuilder, e *Expr, b *strings.Builder) {
 	b.WriteString(`EXISTS (` +
 		`WITH product_names AS (SELECT jsonb_path_query(` +
@@ -409,7 +460,7 @@ func (cm cteMode) ilikePIDWhere(sb *AdvancedSQLBuilder, e *Expr, b *strings.Buil
 
 func (classicMode) orderCommon(b *strings.Builder, name string) {
 	switch name {
-	case "cvss_v2_score", "cvss_v3_score", "critical":
+	case "cvss_v2_score", "cvss_v3_score", "critical", "score":
 		b.WriteString("COALESCE(")
 		b.WriteString(name)
 		b.WriteString(",0)")
@@ -422,13 +473,17 @@ func (classicMode) orderCommon(b *strings.Builder, name string) {
 	}
 }
 
-func (cm classicMode) order(_ *AdvancedSQLBuilder, b *strings.Builder, name string) {
+func (cm classicMode) order(sb *AdvancedSQLBuilder, b *strings.Builder, name string) {
 	switch name {
 	case "tracking_id", "publisher", "id":
 		b.WriteString("advisories.")
 		b.WriteString(name)
 	case "ssvc":
 		b.WriteString("ssvc_current.ssvc")
+	case "latest":
+		b.WriteString(sb.latestColumn())
+	case "staleness":
+		b.WriteString(sb.stalenessColumn())
 	default:
 		cm.orderCommon(b, name)
 	}
@@ -563,6 +618,8 @@ func (sb *AdvancedSQLBuilder) castWhere(e *Expr, b *strings.Builder, sm statemen
 		b.WriteString("events")
 	case statusType:
 		b.WriteString("status")
+	case severityType:
+		b.WriteString("severity")
 	case durationType:
 		b.WriteString("interval")
 	}
@@ -602,6 +659,10 @@ func (sb *AdvancedSQLBuilder) cnstWhere(e *Expr, b *strings.Builder) {
 		b.WriteByte('\'')
 		b.WriteString(e.stringValue)
 		b.WriteString("'::status")
+	case severityType:
+		b.WriteByte('\'')
+		b.WriteString(e.stringValue)
+		b.WriteString("'::severity")
 	case durationType:
 		fmt.Fprintf(b, "'%.2f seconds'::interval", e.durationValue.Seconds())
 	}
@@ -621,6 +682,16 @@ func (sb *AdvancedSQLBuilder) notWhere(e *Expr, b *strings.Builder, sm statement
 	b.WriteByte(')')
 }
 
+func (sb *AdvancedSQLBuilder) tlpAllowedWhere(e *Expr, b *strings.Builder, sm statementMode) {
+	b.WriteString("tlp_allowed(")
+	sb.whereRecurse(e.children[0], b, sm)
+	b.WriteByte(',')
+	sb.whereRecurse(e.children[1], b, sm)
+	b.WriteByte(',')
+	sb.whereRecurse(e.children[2], b, sm)
+	b.WriteString("::jsonb)")
+}
+
 func (sb *AdvancedSQLBuilder) nowWhere(b *strings.Builder) {
 	b.WriteString("current_timestamp")
 }
@@ -669,6 +740,8 @@ func (sb *AdvancedSQLBuilder) whereRecurse(e *Expr, b *strings.Builder, sm state
 		sm.mentionedWhere(sb, e, b)
 	case involved:
 		sm.involvedWhere(sb, e, b)
+	case seen:
+		sm.seenWhere(sb, e, b)
 	case ilike:
 		sb.ilikeWhere(e, b, sm)
 	case ilikePName:
@@ -685,6 +758,8 @@ func (sb *AdvancedSQLBuilder) whereRecurse(e *Expr, b *strings.Builder, sm state
 		sb.binaryWhere(e, b, "*", sm)
 	case div:
 		sb.binaryWhere(e, b, "/", sm)
+	case tlpAllowed:
+		sb.tlpAllowedWhere(e, b, sm)
 	}
 	b.WriteByte(')')
 }
@@ -719,6 +794,30 @@ func (sb *AdvancedSQLBuilder) CreateCountSQL() string {
 	return b.String()
 }
 
+// CreateGroupCountSQL returns an SQL statement to count
+// the number of rows which are possible to fetch by the
+// given filter grouped by the values of the given column.
+// The column has to be checked for existence before.
+func (sb *AdvancedSQLBuilder) CreateGroupCountSQL(field string) string {
+	fields := sb.fields
+	sb.fields = []string{field}
+	defer func() { sb.fields = fields }()
+	var b strings.Builder
+	sm := statementMode(classicMode{})
+	if sb.usedSources.contains(documentsTable | advisoriesTable) {
+		sm = cteMode{}
+		sb.prefixCTE(&b)
+	}
+	b.WriteString("SELECT ")
+	sm.projection(sb, &b, field)
+	b.WriteString(", count(*) FROM ")
+	sm.from(sb, &b)
+	b.WriteString(" WHERE ")
+	sb.createWhere(&b, sm)
+	b.WriteString(" GROUP BY 1")
+	return b.String()
+}
+
 func (sb *AdvancedSQLBuilder) prefixCTE(b *strings.Builder) {
 	b.WriteString(`WITH docads AS (` +
 		`SELECT `)
@@ -746,16 +845,21 @@ func (sb *AdvancedSQLBuilder) prefixCTE(b *strings.Builder) {
 			b.WriteString("documents.id AS id")
 		case "versions":
 			b.WriteString(versionsCountClassic + ` AS versions`)
+		case "latest":
+			b.WriteString(sb.latestColumn() + ` AS latest`)
+		case "staleness":
+			b.WriteString(sb.stalenessColumn() + ` AS staleness`)
 		case "ssvc":
 			b.WriteString(`(` +
 				`SELECT ssvc FROM ssvc_history ` +
 				`WHERE documents_id = documents.id ` +
+				sb.ssvcAsOf() +
 				`ORDER BY changedate DESC, change_number DESC LIMIT 1)`)
 		default:
 			b.WriteString(field)
 		}
 	}
-	b.WriteString(` FROM documents JOIN advisories` +
+	b.WriteString(` FROM ` + sb.documentsSource() + ` JOIN ` + sb.advisoriesSource() +
 		` ON documents.advisories_id = advisories.id)`)
 }
 
@@ -0,0 +1,104 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package query
+
+import "time"
+
+// AdvancedSQLBuilderAsOf creates an option to create an advanced SQL builder
+// which evaluates the workflow states and SSVC values as they were
+// at the given time. Documents imported and events logged
+// after this time are left out.
+func AdvancedSQLBuilderAsOf(asOf *time.Time) AdvancedSQLBuilderOption {
+	return func(ab *AdvancedSQLBuilder) {
+		ab.asOf = asOf
+	}
+}
+
+// asOfLiteral returns the as-of time as an SQL literal.
+func (sb *AdvancedSQLBuilder) asOfLiteral() string {
+	return `'` + sb.asOf.UTC().Format("2006-01-02T15:04:05-0700") + `'::timestamptz`
+}
+
+// latestColumn returns the column flagging the latest document of an advisory.
+func (sb *AdvancedSQLBuilder) latestColumn() string {
+	if sb.asOf == nil {
+		return `latest`
+	}
+	return `latest_as_of`
+}
+
+// stalenessColumn returns the days since the last upstream
+// change of an advisory at the as-of time or now.
+func (sb *AdvancedSQLBuilder) stalenessColumn() string {
+	if sb.asOf == nil {
+		return stalenessDays
+	}
+	return `(extract(epoch FROM ` + sb.asOfLiteral() +
+		` - advisories.upstream_changed) / 86400)::float`
+}
+
+// documentsSource returns the documents table
+// restricted to the documents imported till the as-of time.
+// As newer documents are left out the latest document of an advisory
+// is re-evaluated like the import does.
+func (sb *AdvancedSQLBuilder) documentsSource() string {
+	if sb.asOf == nil {
+		return `documents`
+	}
+	return `(SELECT documents.*, row_number() OVER (` +
+		`PARTITION BY advisories_id ` +
+		`ORDER BY current_release_date DESC NULLS LAST, rev_history_length DESC, id) = 1 AS latest_as_of ` +
+		`FROM documents WHERE NOT EXISTS (` +
+		`SELECT 1 FROM events_log imports ` +
+		`WHERE imports.documents_id = documents.id ` +
+		`AND imports.event = 'import_document' ` +
+		`AND imports.time > ` + sb.asOfLiteral() + `)) AS documents`
+}
+
+// advisoriesSource returns the advisories table with the
+// workflow states valid at the as-of time.
+func (sb *AdvancedSQLBuilder) advisoriesSource() string {
+	if sb.asOf == nil {
+		return `advisories`
+	}
+	asOf := sb.asOfLiteral()
+	return `(SELECT advisories.id, advisories.tracking_id, advisories.publisher, ` +
+		`state_history.state, advisories.comments, advisories.recent, ` +
+		`advisories.localized_title, advisories.languages, ` +
+		`(SELECT max(current_release_date) FROM documents upstream ` +
+		`WHERE upstream.advisories_id = advisories.id AND NOT EXISTS (` +
+		`SELECT 1 FROM events_log imports ` +
+		`WHERE imports.documents_id = upstream.id ` +
+		`AND imports.event = 'import_document' ` +
+		`AND imports.time > ` + asOf + `)) AS upstream_changed ` +
+		`FROM advisories JOIN state_history ` +
+		`ON state_history.advisories_id = advisories.id ` +
+		`AND state_history.valid_from <= ` + asOf + ` ` +
+		`AND (state_history.valid_to IS NULL OR state_history.valid_to > ` + asOf + `)` +
+		`) AS advisories`
+}
+
+// eventsSource returns the events log restricted
+// to the events logged till the as-of time.
+func (sb *AdvancedSQLBuilder) eventsSource() string {
+	if sb.asOf == nil {
+		return `events_log`
+	}
+	return `(SELECT * FROM events_log WHERE time <= ` +
+		sb.asOfLiteral() + `) AS events_log`
+}
+
+// ssvcAsOf returns the condition to restrict the SSVC history
+// to the changes till the as-of time.
+func (sb *AdvancedSQLBuilder) ssvcAsOf() string {
+	if sb.asOf == nil {
+		return ``
+	}
+	return `AND changedate <= ` + sb.asOfLiteral() + ` `
+}
@@ -0,0 +1,63 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package query
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAsOf(t *testing.T) {
+	asOf := time.Date(2026, 3, 31, 23, 59, 59, 0, time.UTC)
+	for _, x := range []struct {
+		mode    ParserMode
+		query   string
+		fields  []string
+		expects []string
+	}{
+		{AdvisoryMode, `$state new workflow = $latest and`, []string{"id", "state", "ssvc"}, []string{
+			"JOIN state_history",
+			"state_history.valid_from <= '2026-03-31T23:59:59+0000'::timestamptz",
+			"AND changedate <= '2026-03-31T23:59:59+0000'::timestamptz",
+			"imports.event = 'import_document'",
+			"latest_as_of",
+		}},
+		{AdvisoryMode, `$staleness 30 float >`, []string{"id", "staleness", "upstream_changed"}, []string{
+			"'2026-03-31T23:59:59+0000'::timestamptz - advisories.upstream_changed",
+			"AS upstream_changed",
+		}},
+		{EventMode, `true`, []string{"id", "event"}, []string{
+			"(SELECT * FROM events_log WHERE time <= '2026-03-31T23:59:59+0000'::timestamptz) AS events_log",
+		}},
+	} {
+		parser := Parser{Mode: x.mode}
+		expr, err := parser.Parse(x.query)
+		if err != nil {
+			t.Fatalf("parsing %q failed: %v", x.query, err)
+		}
+		for _, asOfOpt := range []*time.Time{nil, &asOf} {
+			builder, err := NewAdvancedSQLBuilder(
+				AdvancedSQLBuilderExpr(expr),
+				AdvancedSQLBuilderFields(x.fields),
+				AdvancedSQLBuilderParser(&parser),
+				AdvancedSQLBuilderAsOf(asOfOpt))
+			if err != nil {
+				t.Fatalf("creating builder failed: %v", err)
+			}
+			sql := builder.CreateQuery(-1, -1)
+			for _, expect := range x.expects {
+				if found := strings.Contains(sql, expect); found != (asOfOpt != nil) {
+					t.Errorf("%q: as of %v: containing %q is %t",
+						x.query, asOfOpt != nil, expect, found)
+				}
+			}
+		}
+	}
+}
@@ -35,6 +35,7 @@ const (
 	search
 	mentioned
 	involved
+	seen
 	ilike
 	ilikePName
 	ilikePID
@@ -43,6 +44,7 @@ const (
 	sub
 	mul
 	div
+	tlpAllowed
 )
 
 type valueType int
@@ -57,6 +59,7 @@ const (
 	durationType
 	eventsType
 	statusType
+	severityType
 )
 
 // Expr encapsulates a parsed expression to be converted to an SQL WHERE clause.
@@ -103,6 +106,8 @@ func (vt valueType) String() string {
 		return "events"
 	case statusType:
 		return "status"
+	case severityType:
+		return "severity"
 	default:
 		return fmt.Sprintf("unknown value type %d", vt)
 	}
@@ -121,6 +126,19 @@ func FieldEqInt(field string, value int64) *Expr {
 	}
 }
 
+// FieldGtInt is a shortcut mainly for building expressions
+// selecting the rows after a given integer column value like an 'id'.
+func FieldGtInt(field string, value int64) *Expr {
+	return &Expr{
+		valueType: boolType,
+		exprType:  gt,
+		children: []*Expr{
+			{valueType: intType, exprType: access, stringValue: field},
+			{valueType: intType, exprType: cnst, intValue: value},
+		},
+	}
+}
+
 // FieldEqString is a shortcut mainly for building expressions
 // accessing a string column.
 func FieldEqString(field, value string) *Expr {
@@ -134,6 +152,21 @@ func FieldEqString(field, value string) *Expr {
 	}
 }
 
+// TLPAllowed returns an expression checking the publisher and the TLP
+// of the documents against TLP rules with the tlp_allowed SQL function.
+// rules are the JSON encoded rules mapping publishers to TLP labels.
+func TLPAllowed(publisher, rules string) *Expr {
+	return &Expr{
+		valueType: boolType,
+		exprType:  tlpAllowed,
+		children: []*Expr{
+			{valueType: stringType, exprType: access, stringValue: publisher},
+			{valueType: stringType, exprType: access, stringValue: "tlp"},
+			{valueType: stringType, exprType: cnst, stringValue: rules},
+		},
+	}
+}
+
 // BoolField returns an access term that returns a bool value.
 func BoolField(field string) *Expr {
 	return &Expr{
@@ -176,6 +209,8 @@ func (et exprType) String() string {
 		return "mentioned"
 	case involved:
 		return "involved"
+	case seen:
+		return "seen"
 	case ilike:
 		return "ilike"
 	case ilikePID:
@@ -190,6 +225,8 @@ func (et exprType) String() string {
 		return "*"
 	case div:
 		return "/"
+	case tlpAllowed:
+		return "tlpallowed"
 	default:
 		return fmt.Sprintf("unknown expression type %d", et)
 	}
@@ -242,6 +279,18 @@ func (e *Expr) Searches() iter.Seq[string] {
 	))
 }
 
+// Readers returns a sequence over the users whose read states
+// are filtered in the expression tree.
+func (e *Expr) Readers() iter.Seq[string] {
+	return itertools.Unique(itertools.Apply(itertools.Filter(
+		e.all(),
+		func(e *Expr) bool {
+			return e.exprType == seen
+		}),
+		(*Expr).getStringValue,
+	))
+}
+
 // And concats two expressions and-wise.
 func (e *Expr) And(o *Expr) *Expr {
 	if e.valueType != boolType || o.valueType != boolType {
@@ -0,0 +1,98 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package query
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFieldGtInt(t *testing.T) {
+	parser := Parser{Mode: DocumentMode}
+	expr, err := parser.Parse(`$publisher "Example" =`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expr = expr.And(FieldGtInt("id", 41)).And(FieldGtInt("id", 50).Not())
+	builder, err := NewAdvancedSQLBuilder(
+		AdvancedSQLBuilderExpr(expr),
+		AdvancedSQLBuilderFields([]string{"id"}),
+		AdvancedSQLBuilderParser(&parser))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql := builder.CreateQuery(-1, -1)
+	for _, expect := range []string{"((docads.id)>(41))", "NOT (((docads.id)>(50)))"} {
+		if !strings.Contains(sql, expect) {
+			t.Errorf("%q does not contain %q", sql, expect)
+		}
+	}
+}
+
+func TestSeen(t *testing.T) {
+	parser := Parser{Mode: DocumentMode, Me: "alice"}
+	expr, err := parser.Parse(`me seen not $critical 9 float >= and`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder, err := NewAdvancedSQLBuilder(
+		AdvancedSQLBuilderExpr(expr),
+		AdvancedSQLBuilderFields([]string{"id"}),
+		AdvancedSQLBuilderParser(&parser))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql := builder.CreateQuery(-1, -1)
+	expect := `NOT (EXISTS(SELECT 1 FROM document_reads WHERE "user" = $1 ` +
+		`AND document_reads.documents_id = documents.id))`
+	if !strings.Contains(sql, expect) {
+		t.Errorf("%q does not contain %q", sql, expect)
+	}
+	if len(builder.Replacements) == 0 || builder.Replacements[0] != "alice" {
+		t.Errorf("unexpected replacements %v", builder.Replacements)
+	}
+	if readers := slices.Collect(expr.Readers()); !slices.Equal(readers, []string{"alice"}) {
+		t.Errorf("unexpected readers %v", readers)
+	}
+	if _, err := parser.Parse(`42 integer seen`); err == nil {
+		t.Error("seen accepted an integer")
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	parser := Parser{Mode: DocumentMode}
+	expr, err := parser.Parse(`$severity high severity >=`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder, err := NewAdvancedSQLBuilder(
+		AdvancedSQLBuilderExpr(expr),
+		AdvancedSQLBuilderFields([]string{"id", "severity"}),
+		AdvancedSQLBuilderParser(&parser))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{"'high'::severity", "severity::text"} {
+		if sql := builder.CreateQuery(-1, -1); !strings.Contains(sql, expect) {
+			t.Errorf("%q does not contain %q", sql, expect)
+		}
+	}
+	sql := builder.CreateGroupCountSQL("severity")
+	if !strings.HasPrefix(sql, "SELECT severity::text, count(*) FROM ") ||
+		!strings.HasSuffix(sql, " GROUP BY 1") {
+		t.Errorf("unexpected group count %q", sql)
+	}
+	if fields := builder.Fields(); len(fields) != 2 {
+		t.Errorf("fields changed to %v", fields)
+	}
+	if _, err := parser.Parse(`$severity severe severity =`); err == nil {
+		t.Error("invalid severity accepted")
+	}
+}
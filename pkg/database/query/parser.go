@@ -107,17 +107,27 @@ var documentColumns = []documentColumn{
 	{"initial_release_date", timeType, docAdvEvtModes, false, documentsTable},
 	{"rev_history_length", intType, docAdvEvtModes, false, documentsTable},
 	{"title", stringType, docAdvEvtModes, false, documentsTable},
+	{"lang", stringType, docAdvEvtModes, false, documentsTable},
 	{"tlp", stringType, docAdvEvtModes, false, documentsTable},
 	{"ssvc", stringType, docAdvEvtModes, false, ssvcHistoryTable},
 	{"cvss_v2_score", floatType, docAdvEvtModes, false, documentsTable},
 	{"cvss_v3_score", floatType, docAdvEvtModes, false, documentsTable},
 	{"critical", floatType, docAdvEvtModes, false, documentsTable},
+	{"severity", severityType, docAdvEvtModes, false, documentsTable},
+	{"score", floatType, docAdvEvtModes, false, documentsTable},
+	{"notes", stringType, docAdvEvtModes, false, documentsTable},
+	{"remediations", stringType, docAdvEvtModes, false, documentsTable},
+	{"acknowledgments", stringType, docAdvEvtModes, false, documentsTable},
 	{"four_cves", stringType, docAdvEvtModes, true, documentsTable},
 	{"comments", intType, docAdvEvtModes, false, documentsTable},
 	{"tracking_status", statusType, docAdvEvtModes, false, documentsTable},
 	// Advisories only
 	{"state", workflowType, advModes, false, advisoriesTable},
 	{"recent", timeType, advModes, false, advisoriesTable},
+	{"upstream_changed", timeType, advModes, false, advisoriesTable},
+	{"staleness", floatType, advModes, false, advisoriesTable},
+	{"localized_title", stringType, advModes, false, advisoriesTable},
+	{"languages", stringType, advModes, false, advisoriesTable},
 	// ToDo: Column "versions" does not exist, but table versions does?
 	{"versions", intType, advModes, false, documentsTable | advisoriesTable},
 	// Events only
@@ -143,6 +153,7 @@ var (
 		"workflow":   pushEnum(workflowType, parseWorkflow),
 		"events":     pushEnum(eventsType, parseEvents),
 		"status":     pushEnum(statusType, parseStatus),
+		"severity":   pushEnum(severityType, parseSeverity),
 		"=":          curry3((*Parser).pushCmp, eq),
 		"!=":         curry3((*Parser).pushCmp, ne),
 		"<":          curry3((*Parser).pushCmp, lt),
@@ -161,6 +172,7 @@ var (
 		"me":         (*Parser).pushMe,
 		"mentioned":  (*Parser).pushMentioned,
 		"involved":   (*Parser).pushInvolved,
+		"seen":       (*Parser).pushSeen,
 		"search":     (*Parser).pushSearch,
 		"as":         (*Parser).pushAs,
 	}
@@ -542,6 +554,16 @@ func (p *Parser) pushInvolved(st *stack) {
 	})
 }
 
+func (*Parser) pushSeen(st *stack) {
+	term := st.pop()
+	term.checkValueType(stringType)
+	st.push(&Expr{
+		exprType:    seen,
+		valueType:   boolType,
+		stringValue: term.stringValue,
+	})
+}
+
 func (p *Parser) pushILike(st *stack) {
 	needle := st.pop()
 	haystack := st.pop()
@@ -763,6 +785,10 @@ var validEvents = []string{
 	"state_change",
 	"add_sscv", "change_sscv", "delete_sscv",
 	"add_comment", "change_comment", "delete_comment",
+	"request_state_change", "approve_state_change", "reject_state_change",
+	"share_document", "revoke_share", "access_share",
+	"legal_hold", "release_legal_hold",
+	"sla_escalation",
 }
 
 func parseEvents(s string) string {
@@ -781,6 +807,17 @@ func parseStatus(s string) string {
 	}
 }
 
+var validSeverities = []string{
+	"none", "low", "medium", "high", "critical",
+}
+
+func parseSeverity(s string) string {
+	if !slices.Contains(validSeverities, s) {
+		panic(parseError(fmt.Sprintf("%q is not a valid severity", s)))
+	}
+	return s
+}
+
 var aliasRe = regexp.MustCompile(`[a-zA-Z_0-9]+`)
 
 func validAlias(s string) {
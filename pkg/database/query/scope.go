@@ -0,0 +1,109 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SearchScope are the texts searched by the search operator.
+type SearchScope uint8
+
+const (
+	// SearchDocuments searches the texts of the documents.
+	SearchDocuments SearchScope = 1 << iota
+	// SearchComments searches the comments of the documents.
+	SearchComments
+	// SearchEvents searches the event log of the documents.
+	SearchEvents
+)
+
+// searchConfig is the text search configuration of the comments
+// and the event log. It does no stemming as the analysts write
+// in different languages.
+const searchConfig = `'simple'`
+
+// ParseSearchScope parses a comma or space separated list of
+// the scopes "documents", "comments" and "events".
+// An empty list searches the documents only.
+func ParseSearchScope(s string) (SearchScope, error) {
+	var scope SearchScope
+	for _, name := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		switch strings.ToLower(name) {
+		case "documents":
+			scope |= SearchDocuments
+		case "comments":
+			scope |= SearchComments
+		case "events":
+			scope |= SearchEvents
+		default:
+			return 0, fmt.Errorf("unknown search scope %q", name)
+		}
+	}
+	if scope == 0 {
+		scope = SearchDocuments
+	}
+	return scope, nil
+}
+
+// AdvancedSQLBuilderSearchScope creates an option to create an advanced
+// SQL builder which searches the given scope. The comments and the event
+// log are searched as full text. In advisory mode the comments and the
+// events of all versions of an advisory are searched.
+func AdvancedSQLBuilderSearchScope(scope SearchScope) AdvancedSQLBuilderOption {
+	return func(ab *AdvancedSQLBuilder) {
+		ab.scope = scope
+	}
+}
+
+// scoped returns true if more than the documents are searched.
+func (sb *AdvancedSQLBuilder) scoped() bool {
+	return sb.scope != 0 && sb.scope != SearchDocuments
+}
+
+// scopedSearchWhere writes the condition of a search over
+// the scope for the document referenced by docs.
+func (sb *AdvancedSQLBuilder) scopedSearchWhere(e *Expr, b *strings.Builder, docs string) {
+	// In advisory mode all versions of the advisory are searched.
+	versions := docs
+	if sb.mode() == AdvisoryMode {
+		versions = `(SELECT versions.id FROM documents versions ` +
+			`JOIN documents doc ON versions.advisories_id = doc.advisories_id ` +
+			`WHERE doc.id = ` + docs + `)`
+	}
+	in := func(column string) string {
+		if versions == docs {
+			return column + ` = ` + docs
+		}
+		return column + ` IN ` + versions
+	}
+	var parts []string
+	if sb.scope&SearchDocuments != 0 {
+		parts = append(parts, fmt.Sprintf(`EXISTS(SELECT 1 FROM documents_texts `+
+			`JOIN unique_texts ON unique_texts.id = documents_texts.txt_id `+
+			`WHERE documents_texts.documents_id = %s AND txt ILIKE $%d)`,
+			docs, sb.replacementIndex(LikeEscape(e.stringValue))+1))
+	}
+	if sb.scope&(SearchComments|SearchEvents) != 0 {
+		tsquery := fmt.Sprintf(`websearch_to_tsquery(%s, $%d)`,
+			searchConfig, sb.replacementIndex(e.stringValue)+1)
+		if sb.scope&SearchComments != 0 {
+			parts = append(parts, `EXISTS(SELECT 1 FROM comments `+
+				`WHERE `+in(`comments.documents_id`)+` AND comments.ts @@ `+tsquery+`)`)
+		}
+		if sb.scope&SearchEvents != 0 {
+			parts = append(parts, `EXISTS(SELECT 1 FROM `+sb.eventsSource()+` `+
+				`WHERE `+in(`events_log.documents_id`)+` AND events_log.ts @@ `+tsquery+`)`)
+		}
+	}
+	b.WriteString(strings.Join(parts, ` OR `))
+}
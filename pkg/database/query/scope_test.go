@@ -0,0 +1,88 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package query
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSearchScope(t *testing.T) {
+	for _, x := range []struct {
+		input string
+		scope SearchScope
+		fail  bool
+	}{
+		{"", SearchDocuments, false},
+		{"documents", SearchDocuments, false},
+		{"comments", SearchComments, false},
+		{"documents,comments events", SearchDocuments | SearchComments | SearchEvents, false},
+		{"Events", SearchEvents, false},
+		{"texts", 0, true},
+	} {
+		scope, err := ParseSearchScope(x.input)
+		if (err != nil) != x.fail || scope != x.scope {
+			t.Errorf("%q: got %d, %v", x.input, scope, err)
+		}
+	}
+}
+
+func TestSearchScope(t *testing.T) {
+	for _, x := range []struct {
+		mode     ParserMode
+		scope    SearchScope
+		expects  []string
+		excludes []string
+	}{
+		{DocumentMode, SearchDocuments, []string{
+			"CROSS JOIN LATERAL",
+		}, []string{
+			"websearch_to_tsquery",
+		}},
+		{DocumentMode, SearchDocuments | SearchComments, []string{
+			"txt ILIKE $",
+			"comments.documents_id = docads.id AND comments.ts @@ websearch_to_tsquery('simple', $",
+		}, []string{
+			"CROSS JOIN LATERAL",
+			"events_log.ts",
+		}},
+		{AdvisoryMode, SearchEvents, []string{
+			"events_log.documents_id IN (SELECT versions.id FROM documents versions",
+			"events_log.ts @@ websearch_to_tsquery",
+		}, []string{
+			"CROSS JOIN LATERAL",
+			"txt ILIKE",
+		}},
+	} {
+		parser := Parser{Mode: x.mode}
+		expr, err := parser.Parse(`"bad word" search`)
+		if err != nil {
+			t.Fatalf("parsing failed: %v", err)
+		}
+		builder, err := NewAdvancedSQLBuilder(
+			AdvancedSQLBuilderExpr(expr),
+			AdvancedSQLBuilderFields([]string{"id", "tracking_id", "publisher"}),
+			AdvancedSQLBuilderParser(&parser),
+			AdvancedSQLBuilderSearchScope(x.scope))
+		if err != nil {
+			t.Fatalf("creating builder failed: %v", err)
+		}
+		sql := builder.CreateQuery(-1, -1)
+		for _, expect := range x.expects {
+			if !strings.Contains(sql, expect) {
+				t.Errorf("scope %d: %q not found in %q", x.scope, expect, sql)
+			}
+		}
+		for _, exclude := range x.excludes {
+			if strings.Contains(sql, exclude) {
+				t.Errorf("scope %d: %q found in %q", x.scope, exclude, sql)
+			}
+		}
+	}
+}
@@ -144,6 +144,16 @@ func (sb *SQLBuilder) involvedWhere(e *Expr, b *strings.Builder) {
 	}
 }
 
+func (sb *SQLBuilder) seenWhere(e *Expr, b *strings.Builder) {
+	docs := "documents.id"
+	if sb.Mode == EventMode {
+		docs = "events_log.documents_id"
+	}
+	fmt.Fprintf(b, "EXISTS(SELECT 1 FROM document_reads WHERE \"user\" = $%d "+
+		"AND document_reads.documents_id = %s)",
+		sb.replacementIndex(e.stringValue)+1, docs)
+}
+
 func (sb *SQLBuilder) castWhere(e *Expr, b *strings.Builder) {
 	b.WriteString("CAST(")
 	sb.whereRecurse(e.children[0], b)
@@ -165,6 +175,8 @@ func (sb *SQLBuilder) castWhere(e *Expr, b *strings.Builder) {
 		b.WriteString("events")
 	case statusType:
 		b.WriteString("status")
+	case severityType:
+		b.WriteString("severity")
 	case durationType:
 		b.WriteString("interval")
 	}
@@ -204,6 +216,10 @@ func (sb *SQLBuilder) cnstWhere(e *Expr, b *strings.Builder) {
 		b.WriteByte('\'')
 		b.WriteString(e.stringValue)
 		b.WriteString("'::status")
+	case severityType:
+		b.WriteByte('\'')
+		b.WriteString(e.stringValue)
+		b.WriteString("'::severity")
 	case durationType:
 		fmt.Fprintf(b, "'%.2f seconds'::interval", e.durationValue.Seconds())
 	}
@@ -233,6 +249,7 @@ const (
 		`comments.documents_id = docads.id)`
 	commentsCountEvents = `(SELECT count(*) FROM comments WHERE ` +
 		`comments.documents_id = documents_id)`
+	stalenessDays = `(extract(epoch FROM current_timestamp - advisories.upstream_changed) / 86400)::float`
 )
 
 func (sb *SQLBuilder) accessWhere(e *Expr, b *strings.Builder) {
@@ -258,11 +275,23 @@ func (sb *SQLBuilder) accessWhere(e *Expr, b *strings.Builder) {
 		b.WriteString("events_log.state")
 	case "ssvc":
 		b.WriteString("ssvc_current.ssvc")
+	case "staleness":
+		b.WriteString(stalenessDays)
 	default:
 		b.WriteString(column)
 	}
 }
 
+func (sb *SQLBuilder) tlpAllowedWhere(e *Expr, b *strings.Builder) {
+	b.WriteString("tlp_allowed(")
+	sb.whereRecurse(e.children[0], b)
+	b.WriteByte(',')
+	sb.whereRecurse(e.children[1], b)
+	b.WriteByte(',')
+	sb.whereRecurse(e.children[2], b)
+	b.WriteString("::jsonb)")
+}
+
 func (sb *SQLBuilder) nowWhere(_ *Expr, b *strings.Builder) {
 	b.WriteString("current_timestamp")
 }
@@ -361,6 +390,8 @@ func (sb *SQLBuilder) whereRecurse(e *Expr, b *strings.Builder) {
 		sb.mentionedWhere(e, b)
 	case involved:
 		sb.involvedWhere(e, b)
+	case seen:
+		sb.seenWhere(e, b)
 	case ilike:
 		sb.ilikeWhere(e, b)
 	case ilikePName:
@@ -377,6 +408,8 @@ func (sb *SQLBuilder) whereRecurse(e *Expr, b *strings.Builder) {
 		sb.binaryWhere(e, b, "*")
 	case div:
 		sb.binaryWhere(e, b, "/")
+	case tlpAllowed:
+		sb.tlpAllowedWhere(e, b)
 	}
 	b.WriteByte(')')
 }
@@ -449,12 +482,14 @@ func (sb *SQLBuilder) CreateOrder(fields []string) (string, error) {
 		case "tracking_id", "publisher", "id":
 			b.WriteString("advisories.")
 			b.WriteString(field)
-		case "cvss_v2_score", "cvss_v3_score", "critical":
+		case "cvss_v2_score", "cvss_v3_score", "critical", "score":
 			b.WriteString("COALESCE(")
 			b.WriteString(field)
 			b.WriteString(",0)")
 		case "ssvc":
 			b.WriteString("ssvc_current.ssvc")
+		case "staleness":
+			b.WriteString(stalenessDays)
 		case "version":
 			// TODO: This is not optimal (SemVer).
 			b.WriteString(
@@ -537,7 +572,7 @@ func (sb *SQLBuilder) projectionsWithCasts(b *strings.Builder, proj []string) {
 			b.WriteString(p)
 			b.WriteString(` AS `)
 			b.WriteString(p)
-		case "state", "event", "tracking_status":
+		case "state", "event", "tracking_status", "severity":
 			b.WriteString(p)
 			b.WriteString("::text")
 		case "event_state":
@@ -546,6 +581,8 @@ func (sb *SQLBuilder) projectionsWithCasts(b *strings.Builder, proj []string) {
 			b.WriteString(versionsCountClassic + `AS versions`)
 		case "ssvc":
 			b.WriteString("ssvc_current.ssvc AS ssvc")
+		case "staleness":
+			b.WriteString(stalenessDays + ` AS staleness`)
 		case "comments":
 			switch sb.Mode {
 			case AdvisoryMode:
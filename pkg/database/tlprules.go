@@ -0,0 +1,71 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// resetTimeout limits the time to lift the TLP restriction
+// of a connection before it goes back to the pool.
+const resetTimeout = 5 * time.Second
+
+// tlpRulesKey is the context key of the TLP rules.
+type tlpRulesKey struct{}
+
+// WithTLPRules returns a context which restricts the documents seen by
+// the functions run with it by [DB.Run] to the ones allowed by the TLP rules.
+// The rules are JSON encoded as expected by the tlp_allowed function
+// of the database. The row level security of the documents table
+// applies them with the setting isduba.tlp_rules of the session.
+func WithTLPRules(ctx context.Context, rules string) context.Context {
+	return context.WithValue(ctx, tlpRulesKey{}, rules)
+}
+
+// TLPRules returns the TLP rules of the context.
+func TLPRules(ctx context.Context) (string, bool) {
+	rules, ok := ctx.Value(tlpRulesKey{}).(string)
+	return rules, ok
+}
+
+// restricted calls fn with the documents of the connection restricted
+// to the TLP rules of the context if there are any. The restriction
+// is lifted before the connection goes back to the pool. If this fails
+// the connection is closed so that it is not reused.
+func restricted(
+	ctx context.Context,
+	conn *pgxpool.Conn,
+	fn func(context.Context, *pgxpool.Conn) error,
+) error {
+	rules, ok := TLPRules(ctx)
+	if !ok {
+		return fn(ctx, conn)
+	}
+	const (
+		setSQL   = `SELECT set_config('isduba.tlp_rules', $1, false)`
+		resetSQL = `RESET isduba.tlp_rules`
+	)
+	if _, err := conn.Exec(ctx, setSQL, rules); err != nil {
+		return err
+	}
+	defer func() {
+		// The context of the request may already be canceled.
+		rctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), resetTimeout)
+		defer cancel()
+		if _, err := conn.Exec(rctx, resetSQL); err != nil {
+			slog.Warn("lifting TLP restriction failed", "err", err)
+			conn.Hijack().Close(rctx)
+		}
+	}()
+	return fn(ctx, conn)
+}
@@ -0,0 +1,65 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/ISDuBA/ISDuBA/pkg/requestid"
+)
+
+// requestTracer logs the queries issued on behalf of HTTP requests
+// so that they can be correlated with the requests by their ids.
+type requestTracer struct{}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	sql   string
+	start time.Time
+}
+
+// TraceQueryStart implements [pgx.QueryTracer].
+func (requestTracer) TraceQueryStart(
+	ctx context.Context,
+	_ *pgx.Conn,
+	data pgx.TraceQueryStartData,
+) context.Context {
+	if requestid.FromContext(ctx) == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, queryStartKey{}, &queryStart{
+		sql:   data.SQL,
+		start: time.Now(),
+	})
+}
+
+// TraceQueryEnd implements [pgx.QueryTracer].
+func (requestTracer) TraceQueryEnd(
+	ctx context.Context,
+	_ *pgx.Conn,
+	data pgx.TraceQueryEndData,
+) {
+	qs, ok := ctx.Value(queryStartKey{}).(*queryStart)
+	if !ok {
+		return
+	}
+	duration := time.Since(qs.start)
+	if data.Err != nil {
+		slog.DebugContext(ctx, "database query failed",
+			"sql", qs.sql, "duration", duration, "err", data.Err)
+		return
+	}
+	slog.DebugContext(ctx, "database query",
+		"sql", qs.sql, "duration", duration, "rows", data.CommandTag.RowsAffected())
+}
@@ -0,0 +1,285 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package demo loads a bundled set of anonymized advisories, sources
+// and assessments into a database for trainings and UI development.
+// The loaded data is recorded so that it can be wiped later
+// without touching other data.
+package demo
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/database"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// Actor is the name of the user the demo data is attributed to.
+const Actor = "demo"
+
+//go:embed data/demo.json data/advisories/*.json
+var data embed.FS
+
+// ErrAlreadyLoaded is returned if the demo data is already in the database.
+var ErrAlreadyLoaded = errors.New("demo data already loaded")
+
+type feed struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+	Rolie bool   `json:"rolie"`
+}
+
+type source struct {
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+	Feeds []feed `json:"feeds"`
+}
+
+type advisory struct {
+	File     string          `json:"file"`
+	State    models.Workflow `json:"state"`
+	SSVC     string          `json:"ssvc"`
+	Comments []string        `json:"comments"`
+}
+
+type manifest struct {
+	Sources    []source   `json:"sources"`
+	Advisories []advisory `json:"advisories"`
+}
+
+// progression is the order in which the workflow states are passed.
+var progression = []models.Workflow{
+	models.NewWorkflow,
+	models.ReadWorkflow,
+	models.AssessingWorkflow,
+	models.ReviewWorkflow,
+	models.ArchivedWorkflow,
+}
+
+func loadManifest() (*manifest, error) {
+	raw, err := data.ReadFile("data/demo.json")
+	if err != nil {
+		return nil, err
+	}
+	var m manifest
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("parsing demo manifest failed: %w", err)
+	}
+	return &m, m.validate()
+}
+
+func (m *manifest) validate() error {
+	for _, adv := range m.Advisories {
+		if adv.State != "" && !slices.Contains(progression, adv.State) {
+			return fmt.Errorf("%s: invalid state %q", adv.File, adv.State)
+		}
+		if adv.SSVC != "" {
+			if err := models.ValidateSSVCv2Vector(adv.SSVC); err != nil {
+				return fmt.Errorf("%s: invalid SSVC vector: %w", adv.File, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Load imports the demo data into the database.
+func Load(ctx context.Context, db *database.DB) error {
+	m, err := loadManifest()
+	if err != nil {
+		return err
+	}
+	return db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+		var loaded bool
+		if err := conn.QueryRow(rctx,
+			`SELECT EXISTS(SELECT 1 FROM demo_data)`).Scan(&loaded); err != nil {
+			return err
+		}
+		if loaded {
+			return ErrAlreadyLoaded
+		}
+		if err := loadSources(rctx, conn, m.Sources); err != nil {
+			return err
+		}
+		for i := range m.Advisories {
+			if err := loadAdvisory(rctx, conn, &m.Advisories[i]); err != nil {
+				return fmt.Errorf("loading %s failed: %w", m.Advisories[i].File, err)
+			}
+		}
+		return nil
+	}, 0)
+}
+
+func loadSources(ctx context.Context, conn *pgxpool.Conn, sources []source) error {
+	const (
+		insertSource = `INSERT INTO sources (name, url, active) ` +
+			`VALUES ($1, $2, false) RETURNING id`
+		insertFeed = `INSERT INTO feeds (label, sources_id, url, rolie) ` +
+			`VALUES ($1, $2, $3, $4)`
+		recordSource = `INSERT INTO demo_data (sources_id) VALUES ($1)`
+	)
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	for i := range sources {
+		src := &sources[i]
+		var id int64
+		if err := tx.QueryRow(ctx, insertSource, src.Name, src.URL).Scan(&id); err != nil {
+			return fmt.Errorf("inserting source %q failed: %w", src.Name, err)
+		}
+		for _, f := range src.Feeds {
+			if _, err := tx.Exec(ctx, insertFeed, f.Label, id, f.URL, f.Rolie); err != nil {
+				return fmt.Errorf("inserting feed %q failed: %w", f.Label, err)
+			}
+		}
+		if _, err := tx.Exec(ctx, recordSource, id); err != nil {
+			return err
+		}
+		slog.Info("demo source loaded", "name", src.Name)
+	}
+	return tx.Commit(ctx)
+}
+
+// recordAdvisory records the advisory of an imported document as demo data.
+func recordAdvisory(ctx context.Context, tx pgx.Tx, docID int64, duplicate bool) error {
+	if duplicate {
+		return nil
+	}
+	const insertSQL = `INSERT INTO demo_data (advisories_id) ` +
+		`SELECT advisories_id FROM documents WHERE id = $1 ` +
+		`ON CONFLICT DO NOTHING`
+	_, err := tx.Exec(ctx, insertSQL, docID)
+	return err
+}
+
+func loadAdvisory(ctx context.Context, conn *pgxpool.Conn, adv *advisory) error {
+	raw, err := data.ReadFile(path.Join("data/advisories", adv.File))
+	if err != nil {
+		return err
+	}
+	actor := Actor
+	docID, err := models.ImportDocument(
+		ctx, conn, bytes.NewReader(raw), &actor, nil,
+		models.ChainInTx(recordAdvisory, models.StoreFilename(adv.File)),
+		false)
+	if err != nil {
+		return err
+	}
+	slog.Info("demo advisory loaded", "file", adv.File, "id", docID)
+	if adv.State == "" || adv.State == models.NewWorkflow {
+		return nil
+	}
+	return assess(ctx, conn, docID, adv)
+}
+
+// assess walks the advisory of the given document through the workflow
+// up to the state of the demo advisory. Comments and the SSVC vector
+// are added while the advisory is assessed.
+func assess(ctx context.Context, conn *pgxpool.Conn, docID int64, adv *advisory) error {
+	const (
+		stateSQL = `UPDATE advisories SET state = $1::workflow ` +
+			`WHERE id = (SELECT advisories_id FROM documents WHERE id = $2)`
+		eventSQL = `INSERT INTO events_log (event, state, actor, documents_id, comments_id) ` +
+			`VALUES ($1::events, $2::workflow, $3, $4, $5)`
+		commentSQL = `INSERT INTO comments (documents_id, commentator, message) ` +
+			`VALUES ($1, $2, $3) RETURNING id`
+		ssvcSQL = `INSERT INTO ssvc_history (actor, documents_id, ssvc) ` +
+			`VALUES ($1::varchar, $2::integer, $3)`
+	)
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, state := range progression[1:] {
+		if _, err := tx.Exec(ctx, stateSQL, string(state), docID); err != nil {
+			return fmt.Errorf("changing state failed: %w", err)
+		}
+		if _, err := tx.Exec(ctx, eventSQL,
+			string(models.StateChangeEvent), string(state), Actor, docID, nil,
+		); err != nil {
+			return err
+		}
+		if state == models.AssessingWorkflow {
+			for _, msg := range adv.Comments {
+				var commentID int64
+				if err := tx.QueryRow(ctx, commentSQL, docID, Actor, msg).Scan(&commentID); err != nil {
+					return fmt.Errorf("adding comment failed: %w", err)
+				}
+				if _, err := tx.Exec(ctx, eventSQL,
+					string(models.AddCommentEvent), string(state), Actor, docID, commentID,
+				); err != nil {
+					return err
+				}
+			}
+			if adv.SSVC != "" {
+				if _, err := tx.Exec(ctx, ssvcSQL, Actor, docID, adv.SSVC); err != nil {
+					return fmt.Errorf("adding SSVC failed: %w", err)
+				}
+			}
+		}
+		if state == adv.State {
+			break
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// Wipe removes the demo data from the database.
+// Other advisories and sources are not touched.
+func Wipe(ctx context.Context, db *database.DB) error {
+	const (
+		eventsSQL = `DELETE FROM events_log WHERE documents_id IN (` +
+			`SELECT id FROM documents WHERE advisories_id IN (` +
+			`SELECT advisories_id FROM demo_data))`
+		// Deleting the last document of an advisory deletes
+		// the advisory and its demo_data record, too.
+		documentsSQL = `DELETE FROM documents WHERE advisories_id IN (` +
+			`SELECT advisories_id FROM demo_data)`
+		sourcesSQL = `DELETE FROM sources WHERE id IN (` +
+			`SELECT sources_id FROM demo_data)`
+	)
+	return db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+		tx, err := conn.Begin(rctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(rctx)
+		if _, err := tx.Exec(rctx, eventsSQL); err != nil {
+			return fmt.Errorf("deleting demo events failed: %w", err)
+		}
+		docs, err := tx.Exec(rctx, documentsSQL)
+		if err != nil {
+			return fmt.Errorf("deleting demo documents failed: %w", err)
+		}
+		srcs, err := tx.Exec(rctx, sourcesSQL)
+		if err != nil {
+			return fmt.Errorf("deleting demo sources failed: %w", err)
+		}
+		if err := tx.Commit(rctx); err != nil {
+			return err
+		}
+		slog.Info("demo data wiped",
+			"documents", docs.RowsAffected(),
+			"sources", srcs.RowsAffected())
+		return nil
+	}, 0)
+}
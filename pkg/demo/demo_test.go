@@ -0,0 +1,41 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package demo
+
+import (
+	"encoding/json"
+	"path"
+	"testing"
+
+	"github.com/gocsaf/csaf/v3/csaf"
+)
+
+func TestData(t *testing.T) {
+	m, err := loadManifest()
+	if err != nil {
+		t.Fatalf("loading manifest failed: %v", err)
+	}
+	for _, adv := range m.Advisories {
+		raw, err := data.ReadFile(path.Join("data/advisories", adv.File))
+		if err != nil {
+			t.Fatalf("reading %s failed: %v", adv.File, err)
+		}
+		var doc any
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			t.Fatalf("parsing %s failed: %v", adv.File, err)
+		}
+		msgs, err := csaf.ValidateCSAF(doc)
+		if err != nil {
+			t.Fatalf("validating %s failed: %v", adv.File, err)
+		}
+		if len(msgs) > 0 {
+			t.Errorf("%s is not valid: %v", adv.File, msgs)
+		}
+	}
+}
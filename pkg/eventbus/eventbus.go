@@ -0,0 +1,243 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package eventbus distributes the changes of documents
+// within the server to the interested consumers.
+package eventbus
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/database"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+const (
+	// backlogSize is the number of recent events kept
+	// for subscribers resuming after a disconnect.
+	backlogSize = 256
+	// queueSize is the number of published events
+	// waiting to be distributed.
+	queueSize = 1024
+	// subscriberSize is the number of events buffered for a subscriber.
+	subscriberSize = 64
+)
+
+// ForwardDocumentEvent is published when a document was
+// delivered to a target of the forwarder.
+const ForwardDocumentEvent models.Event = "forward_document"
+
+// Event is a change of a document.
+type Event struct {
+	ID         uint64       `json:"id"`
+	Type       models.Event `json:"type"`
+	Time       time.Time    `json:"time"`
+	DocumentID int64        `json:"document_id,omitempty"`
+	Publisher  string       `json:"publisher,omitempty"`
+	TrackingID string       `json:"tracking_id,omitempty"`
+	Version    string       `json:"version,omitempty"`
+	TLP        string       `json:"tlp,omitempty"`
+	State      string       `json:"state,omitempty"`
+	Actor      string       `json:"actor,omitempty"`
+	Target     string       `json:"target,omitempty"`
+}
+
+// Subscription receives the events published after subscribing.
+type Subscription struct {
+	bus *Bus
+	// Events delivers the events. It is closed when the subscriber
+	// was too slow to keep up or the bus is shut down.
+	Events <-chan Event
+	events chan Event
+}
+
+// Bus distributes the published events to the subscribers.
+// Before the events are distributed the document
+// details missing in the events are loaded.
+// A nil bus is valid and drops all events.
+type Bus struct {
+	db    *database.DB
+	queue chan Event
+
+	mu      sync.Mutex
+	lastID  uint64
+	backlog []Event
+	subs    map[*Subscription]struct{}
+}
+
+// NewBus returns a new event bus.
+func NewBus(db *database.DB) *Bus {
+	return &Bus{
+		db:    db,
+		queue: make(chan Event, queueSize),
+		subs:  map[*Subscription]struct{}{},
+	}
+}
+
+// Publish queues an event for distribution without blocking.
+// If the time is not set the current time is used.
+func (b *Bus) Publish(ev Event) {
+	if b == nil {
+		return
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now().UTC()
+	}
+	select {
+	case b.queue <- ev:
+	default:
+		slog.Warn("event bus is full, dropping event",
+			"type", ev.Type, "document", ev.DocumentID)
+	}
+}
+
+// Subscribe registers a new subscriber. The events of the
+// backlog newer than the given id are returned to be delivered first.
+// An id of zero does not return any backlog.
+func (b *Bus) Subscribe(lastID uint64) (*Subscription, []Event) {
+	events := make(chan Event, subscriberSize)
+	sub := &Subscription{bus: b, Events: events, events: events}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[sub] = struct{}{}
+	var missed []Event
+	if lastID > 0 {
+		for _, ev := range b.backlog {
+			if ev.ID > lastID {
+				missed = append(missed, ev)
+			}
+		}
+	}
+	return sub, missed
+}
+
+// Close unregisters the subscription.
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	if _, ok := s.bus.subs[s]; ok {
+		delete(s.bus.subs, s)
+		close(s.events)
+	}
+}
+
+// Run distributes the published events. To be used in a Go routine.
+func (b *Bus) Run(ctx context.Context) {
+	if b == nil {
+		return
+	}
+	defer b.shutdown()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-b.queue:
+			// Handle the events which arrived in the meantime together.
+			evs := []Event{ev}
+		drain:
+			for len(evs) < queueSize {
+				select {
+				case ev := <-b.queue:
+					evs = append(evs, ev)
+				default:
+					break drain
+				}
+			}
+			b.complete(ctx, evs)
+			b.distribute(evs)
+		}
+	}
+}
+
+// shutdown closes all subscriptions.
+func (b *Bus) shutdown() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		close(sub.events)
+	}
+}
+
+// complete loads the details of the documents which
+// are not already given in the events.
+func (b *Bus) complete(ctx context.Context, evs []Event) {
+	var ids []int64
+	for i := range evs {
+		if evs[i].DocumentID != 0 && evs[i].Publisher == "" {
+			ids = append(ids, evs[i].DocumentID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	const detailsSQL = `SELECT docs.id, ads.publisher, ads.tracking_id, docs.version, docs.tlp ` +
+		`FROM documents docs JOIN advisories ads ON docs.advisories_id = ads.id ` +
+		`WHERE docs.id = ANY($1)`
+	type details struct {
+		publisher, trackingID, version string
+		tlp                            *string
+	}
+	found := make(map[int64]details, len(ids))
+	if err := b.db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+		var (
+			id int64
+			d  details
+		)
+		rows, _ := conn.Query(rctx, detailsSQL, ids)
+		_, err := pgx.ForEachRow(rows, []any{&id, &d.publisher, &d.trackingID, &d.version, &d.tlp}, func() error {
+			found[id] = d
+			return nil
+		})
+		return err
+	}, 0); err != nil {
+		slog.Error("loading event details failed", "err", err)
+		return
+	}
+	for i := range evs {
+		ev := &evs[i]
+		if d, ok := found[ev.DocumentID]; ok && ev.Publisher == "" {
+			ev.Publisher, ev.TrackingID, ev.Version = d.publisher, d.trackingID, d.version
+			if d.tlp != nil {
+				ev.TLP = *d.tlp
+			}
+		}
+	}
+}
+
+// distribute numbers the events, stores them in the backlog
+// and hands them to the subscribers. Subscribers not
+// able to keep up are dropped.
+func (b *Bus) distribute(evs []Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, ev := range evs {
+		b.lastID++
+		ev.ID = b.lastID
+		if len(b.backlog) == backlogSize {
+			copy(b.backlog, b.backlog[1:])
+			b.backlog = b.backlog[:backlogSize-1]
+		}
+		b.backlog = append(b.backlog, ev)
+		for sub := range b.subs {
+			select {
+			case sub.events <- ev:
+			default:
+				slog.Warn("event subscriber too slow, dropping it")
+				delete(b.subs, sub)
+				close(sub.events)
+			}
+		}
+	}
+}
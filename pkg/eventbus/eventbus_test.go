@@ -0,0 +1,63 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package eventbus
+
+import (
+	"testing"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+func TestDistribute(t *testing.T) {
+	b := NewBus(nil)
+	ev := Event{Type: models.ImportDocumentEvent, DocumentID: 1, Publisher: "p"}
+
+	sub, missed := b.Subscribe(0)
+	if len(missed) != 0 {
+		t.Fatalf("got backlog without last id: %v", missed)
+	}
+	b.distribute([]Event{ev, ev, ev})
+	for want := uint64(1); want <= 3; want++ {
+		if got := <-sub.Events; got.ID != want {
+			t.Errorf("got event %d, want %d", got.ID, want)
+		}
+	}
+
+	// Resuming returns the events after the last seen one.
+	resumed, missed := b.Subscribe(1)
+	if len(missed) != 2 || missed[0].ID != 2 || missed[1].ID != 3 {
+		t.Errorf("unexpected backlog %v", missed)
+	}
+	resumed.Close()
+	resumed.Close() // Closing twice is harmless.
+
+	// The backlog is limited.
+	for range backlogSize {
+		b.distribute([]Event{ev})
+		<-sub.Events
+	}
+	if _, missed := b.Subscribe(1); len(missed) != backlogSize {
+		t.Errorf("backlog has %d events, want %d", len(missed), backlogSize)
+	}
+
+	// Slow subscribers are dropped.
+	slow, _ := b.Subscribe(0)
+	for range subscriberSize + 1 {
+		b.distribute([]Event{ev})
+		<-sub.Events
+	}
+	n := 0
+	for range slow.Events {
+		n++
+	}
+	if n != subscriberSize {
+		t.Errorf("slow subscriber got %d events, want %d", n, subscriberSize)
+	}
+	sub.Close()
+}
@@ -0,0 +1,234 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package forwarder
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// backfillBatchSize is the number of advisories handled per transaction.
+const backfillBatchSize = 100
+
+var (
+	// ErrNoSuchTarget is returned if a target does not exist.
+	ErrNoSuchTarget = errors.New("could not find target with specified id")
+	// ErrNotAutomatic is returned if a backfill is started for
+	// a target which does not forward automatically.
+	ErrNotAutomatic = errors.New("target does not forward automatically")
+	// ErrBackfillRunning is returned if a backfill is started
+	// for a target which already has a running one.
+	ErrBackfillRunning = errors.New("backfill already running")
+)
+
+// backfill is a job which queues the already imported documents
+// matching the current filter of an automatic target.
+type backfill struct {
+	id       int64
+	fw       *forwarder
+	canceled atomic.Bool
+}
+
+// interruptedBackfills marks the backfills left over from a previous run as failed.
+func (fm *Manager) interruptedBackfills(ctx context.Context) {
+	const updateSQL = `UPDATE forwarder_backfills ` +
+		`SET (status, finished, error) = ('failed', current_timestamp, 'interrupted') ` +
+		`WHERE status = 'running'`
+	if err := fm.db.Run(
+		ctx,
+		func(ctx context.Context, conn *pgxpool.Conn) error {
+			_, err := conn.Exec(ctx, updateSQL)
+			return err
+		}, 0,
+	); err != nil {
+		slog.Error("marking interrupted forwarder backfills failed", "err", err)
+	}
+}
+
+// StartBackfill starts a backfill for the specified automatic target.
+// The documents of the already imported advisories matching the current
+// publisher filter, rules and strategy of the target which were not queued
+// for the target before are queued to be forwarded.
+func (fm *Manager) StartBackfill(ctx context.Context, targetID int64, creator sql.NullString) (int64, error) {
+	type result struct {
+		id  int64
+		err error
+	}
+	resCh := make(chan result)
+	fm.fns <- func(fm *Manager) {
+		fw := fm.findForwarder(targetID)
+		if fw == nil {
+			resCh <- result{err: ErrNoSuchTarget}
+			return
+		}
+		if !fw.cfg.Automatic {
+			resCh <- result{err: ErrNotAutomatic}
+			return
+		}
+		if fm.backfills[fw] != nil {
+			resCh <- result{err: ErrBackfillRunning}
+			return
+		}
+		const insertSQL = `INSERT INTO forwarder_backfills ` +
+			`(forwarders_id, creator, advisories) ` +
+			`SELECT fw.id, $2, ` +
+			`(SELECT count(*) FROM advisories WHERE $3::text IS NULL OR publisher = $3) ` +
+			`FROM forwarders fw WHERE fw.url = $1 ` +
+			`RETURNING id`
+		var id int64
+		if err := fm.db.Run(
+			ctx,
+			func(rctx context.Context, conn *pgxpool.Conn) error {
+				return conn.QueryRow(rctx, insertSQL,
+					fw.cfg.URL, creator, fw.cfg.Publisher,
+				).Scan(&id)
+			}, 0,
+		); err != nil {
+			resCh <- result{err: fmt.Errorf("registering backfill failed: %w", err)}
+			return
+		}
+		b := &backfill{id: id, fw: fw}
+		fm.backfills[fw] = b
+		go fm.backfill(fm.ctx, b)
+		resCh <- result{id: id}
+	}
+	res := <-resCh
+	return res.id, res.err
+}
+
+// CancelBackfill cancels the running backfill with the given id.
+// Returns false if there is no such running backfill.
+func (fm *Manager) CancelBackfill(id int64) bool {
+	resCh := make(chan bool)
+	fm.fns <- func(fm *Manager) {
+		for _, b := range fm.backfills {
+			if b.id == id {
+				b.canceled.Store(true)
+				resCh <- true
+				return
+			}
+		}
+		resCh <- false
+	}
+	return <-resCh
+}
+
+// backfill queues the documents of the advisories in batches.
+func (fm *Manager) backfill(ctx context.Context, b *backfill) {
+	defer func() {
+		select {
+		case fm.fns <- func(fm *Manager) { delete(fm.backfills, b.fw) }:
+		case <-ctx.Done():
+		}
+	}()
+	slog.Info("forwarder backfill started", "id", b.id, "target", b.fw.cfg.URL)
+	var (
+		last   int64
+		status = "finished"
+		errMsg *string
+	)
+	for {
+		if b.canceled.Load() {
+			status = "canceled"
+			break
+		}
+		n, next, err := fm.backfillBatch(ctx, b, last)
+		if err != nil {
+			slog.Error("forwarder backfill failed", "id", b.id, "err", err)
+			msg := err.Error()
+			status, errMsg = "failed", &msg
+			break
+		}
+		if n < backfillBatchSize {
+			break
+		}
+		last = next
+	}
+	const finishSQL = `UPDATE forwarder_backfills ` +
+		`SET (status, finished, error) = ($1, current_timestamp, $2) ` +
+		`WHERE id = $3`
+	if err := fm.db.Run(
+		context.WithoutCancel(ctx),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			_, err := conn.Exec(rctx, finishSQL, status, errMsg, b.id)
+			return err
+		}, 0,
+	); err != nil {
+		slog.Error("finishing forwarder backfill failed", "id", b.id, "err", err)
+	}
+	slog.Info("forwarder backfill ended", "id", b.id, "status", status)
+}
+
+// backfillBatch queues the documents of the next batch of advisories after last.
+// Returns the number of advisories and the highest id of the batch.
+func (fm *Manager) backfillBatch(ctx context.Context, b *backfill, last int64) (int, int64, error) {
+	const (
+		advisoriesSQL = `SELECT id FROM advisories ` +
+			`WHERE id > $1 AND ($2::text IS NULL OR publisher = $2) ` +
+			`ORDER BY id LIMIT $3`
+		queueSQL = `INSERT INTO forwarders_queue (forwarders_id, documents_id) ` +
+			`SELECT fw.id, docs.id FROM forwarders fw, unnest($2::int[]) AS docs(id) ` +
+			`WHERE fw.url = $1 ` +
+			`ON CONFLICT (forwarders_id, documents_id) DO NOTHING`
+		progressSQL = `UPDATE forwarder_backfills SET ` +
+			`processed = processed + $1, queued = queued + $2 ` +
+			`WHERE id = $3`
+	)
+	var (
+		n      int
+		filter = filters[filterIndex[fm.strategy(b.fw)]]
+	)
+	if err := fm.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, advisoriesSQL, last, b.fw.cfg.Publisher, backfillBatchSize)
+			ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+			if err != nil {
+				return fmt.Errorf("loading advisories failed: %w", err)
+			}
+			if n = len(ids); n == 0 {
+				return nil
+			}
+			last = ids[n-1]
+			var docIDs []int64
+			for _, id := range ids {
+				vis, err := loadVersionInfos(rctx, conn, id)
+				if err != nil {
+					return err
+				}
+				for _, idx := range b.fw.acceptedIndices(vis, filter(vis)) {
+					docIDs = append(docIDs, vis[idx].id)
+				}
+			}
+			tx, err := conn.Begin(rctx)
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(rctx)
+			tags, err := tx.Exec(rctx, queueSQL, b.fw.cfg.URL, docIDs)
+			if err != nil {
+				return fmt.Errorf("queuing documents failed: %w", err)
+			}
+			if _, err := tx.Exec(rctx, progressSQL, n, tags.RowsAffected(), b.id); err != nil {
+				return fmt.Errorf("storing progress failed: %w", err)
+			}
+			return tx.Commit(rctx)
+		}, 0,
+	); err != nil {
+		return 0, 0, err
+	}
+	return n, last, nil
+}
@@ -0,0 +1,137 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package forwarder
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrTargetDown is returned if a document is forwarded
+// to a target whose circuit breaker is open.
+var ErrTargetDown = errors.New("target is down")
+
+// breakerState is the state of a circuit breaker.
+type breakerState int
+
+const (
+	// breakerClosed lets the deliveries pass.
+	breakerClosed breakerState = iota
+	// breakerOpen pauses the deliveries until a health check succeeds.
+	breakerOpen
+	// breakerHalfOpen lets the deliveries pass again
+	// but the next failure opens the breaker at once.
+	breakerHalfOpen
+)
+
+// String implements [fmt.Stringer].
+func (bs breakerState) String() string {
+	switch bs {
+	case breakerClosed:
+		return "closed"
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half_open"
+	default:
+		return fmt.Sprintf("unknown breaker state %d", bs)
+	}
+}
+
+// breaker is a circuit breaker pausing the deliveries
+// to a target after a number of consecutive failures.
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	state     breakerState
+	failures  int
+	since     time.Time
+	lastCheck time.Time
+	lastError string
+}
+
+// breakerStatus is a snapshot of a circuit breaker.
+type breakerStatus struct {
+	State     breakerState
+	Failures  int
+	Since     time.Time
+	LastCheck time.Time
+	LastError string
+}
+
+func newBreaker(threshold int) *breaker {
+	return &breaker{
+		threshold: max(1, threshold),
+		since:     time.Now().UTC(),
+	}
+}
+
+// allow returns true if deliveries should be attempted.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state != breakerOpen
+}
+
+// success records a successful delivery or health check.
+// A successful health check half opens an open breaker
+// so that the backlog is replayed. A successful delivery closes it.
+// It returns true if the deliveries were resumed.
+func (b *breaker) success(check bool) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now().UTC()
+	if check {
+		b.lastCheck = now
+	}
+	b.failures = 0
+	switch {
+	case b.state == breakerOpen:
+		b.state, b.since = breakerHalfOpen, now
+		return true
+	case b.state == breakerHalfOpen && !check:
+		b.state, b.since = breakerClosed, now
+		b.lastError = ""
+	}
+	return false
+}
+
+// failure records a failed delivery or health check.
+// It returns true if the breaker was opened by it.
+func (b *breaker) failure(check bool, err error) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now().UTC()
+	if check {
+		b.lastCheck = now
+	}
+	b.failures++
+	b.lastError = err.Error()
+	if b.state == breakerOpen ||
+		(b.state == breakerClosed && b.failures < b.threshold) {
+		return false
+	}
+	b.state, b.since = breakerOpen, now
+	return true
+}
+
+// status returns a snapshot of the breaker.
+func (b *breaker) status() breakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return breakerStatus{
+		State:     b.state,
+		Failures:  b.failures,
+		Since:     b.since,
+		LastCheck: b.lastCheck,
+		LastError: b.lastError,
+	}
+}
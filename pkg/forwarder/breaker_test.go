@@ -0,0 +1,59 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package forwarder
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBreaker(t *testing.T) {
+	errDown := errors.New("down")
+	b := newBreaker(2)
+
+	if b.failure(false, errDown) {
+		t.Fatal("opened below threshold")
+	}
+	if !b.failure(true, errDown) {
+		t.Fatal("not opened at threshold")
+	}
+	if b.allow() {
+		t.Fatal("open breaker allows deliveries")
+	}
+	if b.failure(true, errDown) {
+		t.Fatal("open breaker opened again")
+	}
+	if s := b.status(); s.Failures != 3 || s.LastError != "down" || s.LastCheck.IsZero() {
+		t.Fatalf("unexpected status %+v", s)
+	}
+
+	// A successful health check resumes the deliveries.
+	if !b.success(true) {
+		t.Fatal("health check did not resume")
+	}
+	if s := b.status().State; s != breakerHalfOpen || !b.allow() {
+		t.Fatalf("got state %s, want %s", s, breakerHalfOpen)
+	}
+	// The first failure reopens it at once.
+	if !b.failure(false, errDown) {
+		t.Fatal("half open breaker not reopened")
+	}
+	b.success(true)
+	// Further health checks keep it half open.
+	if b.success(true) || b.status().State != breakerHalfOpen {
+		t.Fatal("health check changed half open breaker")
+	}
+	// A successful delivery closes it.
+	if b.success(false) {
+		t.Fatal("delivery reported resume")
+	}
+	if s := b.status(); s.State != breakerClosed || s.LastError != "" || s.Failures != 0 {
+		t.Fatalf("unexpected status %+v", s)
+	}
+}
@@ -11,8 +11,10 @@ package forwarder
 import (
 	"context"
 	"crypto/tls"
+	"database/sql"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
@@ -20,44 +22,77 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ISDuBA/ISDuBA/pkg/blobs"
 	"github.com/ISDuBA/ISDuBA/pkg/config"
 	"github.com/ISDuBA/ISDuBA/pkg/database"
+	"github.com/ISDuBA/ISDuBA/pkg/eventbus"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // forwarderWakeupInterval is a saftey net wakeup interval for each
 // forwarder if a ping from the manager is missed some how.
 const forwarderWakeupInterval = 2 * time.Minute
 
+// updateQueueSQL stores the result of an upload in the queue.
+const updateQueueSQL = `` +
+	`UPDATE forwarders_queue` +
+	` SET state = $1::forward_state ` +
+	`WHERE` +
+	` documents_id = $2 AND` +
+	` forwarders_id = (SELECT id FROM forwarders WHERE url = $3)`
+
 type forwarder struct {
+	// id is the id of the target in the forwarders table.
+	id int64
+	// dynamic is the configuration of a target managed via the API.
+	dynamic     *TargetConfig
 	cfg         *config.ForwardTarget
+	banners     *config.Banners
 	externalURL *url.URL
 	db          *database.DB
+	blobs       *blobs.Store
+	bus         *eventbus.Bus
 	fns         chan (func(*forwarder))
-	done        bool
 	client      *http.Client
 	headers     http.Header
+	auth        string
+	breaker     *breaker
+	// ctx is canceled when the target is removed.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// loadClientCert loads the client certificate of a configured target.
+func loadClientCert(cfg *config.ForwardTarget) ([]tls.Certificate, error) {
+	if cfg.ClientPrivateCert == "" || cfg.ClientPublicCert == "" {
+		return nil, nil
+	}
+	clientCert, err := tls.LoadX509KeyPair(
+		cfg.ClientPublicCert,
+		cfg.ClientPrivateCert)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot load client cert for forward target %q: %w",
+			cfg.URL, err)
+	}
+	return []tls.Certificate{clientCert}, nil
 }
 
 func newForwarder(
 	cfg *config.ForwardTarget,
+	certs []tls.Certificate,
+	banners *config.Banners,
 	externalURL *url.URL,
 	db *database.DB,
+	bs *blobs.Store,
+	bus *eventbus.Bus,
+	threshold int,
 ) (*forwarder, error) {
 	// Init http clients
-	var tlsConfig tls.Config
-	if cfg.ClientPrivateCert != "" && cfg.ClientPublicCert != "" {
-		clientCert, err := tls.LoadX509KeyPair(
-			cfg.ClientPublicCert,
-			cfg.ClientPrivateCert)
-		if err != nil {
-			return nil, fmt.Errorf(
-				"cannot load client cert for forward target %q: %w",
-				cfg.URL, err)
-		}
-		tlsConfig.Certificates = []tls.Certificate{clientCert}
-	}
+	tlsConfig := tls.Config{Certificates: certs}
 	client := &http.Client{
 		Timeout: cfg.Timeout,
 		Transport: &http.Transport{
@@ -74,22 +109,56 @@ func newForwarder(
 			"header %q of forwarder target %q is missing ':'",
 			header, cfg.URL)
 	}
+	// The csaf_provider expects a bcrypt hash of its password.
+	var auth string
+	if cfg.Type == config.ForwardTargetTypeCSAFProvider && cfg.Password != nil {
+		hash, err := bcrypt.GenerateFromPassword([]byte(*cfg.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot hash password of forward target %q: %w", cfg.URL, err)
+		}
+		auth = string(hash)
+	}
 	return &forwarder{
 		cfg:         cfg,
+		banners:     banners,
 		externalURL: externalURL,
 		db:          db,
+		blobs:       bs,
+		bus:         bus,
 		fns:         make(chan func(*forwarder)),
 		client:      client,
 		headers:     headers,
+		auth:        auth,
+		breaker:     newBreaker(threshold),
 	}, nil
 }
 
+// start runs the forwarder until it is stopped or the context is canceled.
+func (f *forwarder) start(ctx context.Context, healthInterval time.Duration) {
+	f.ctx, f.cancel = context.WithCancel(ctx)
+	go f.monitor(f.ctx, healthInterval)
+	if f.cfg.Automatic {
+		go f.run(f.ctx)
+	}
+}
+
+// stop stops the forwarder.
+func (f *forwarder) stop() {
+	if f.cancel != nil {
+		f.cancel()
+	}
+}
+
 func (f *forwarder) run(ctx context.Context) {
 	ticker := time.NewTicker(forwarderWakeupInterval)
 	defer ticker.Stop()
-	for !f.done {
-		if err := f.forward(ctx); err != nil {
-			slog.Error("forwarder has issues", "error", err, "forwarder", f.cfg.URL)
+	for {
+		// Deliveries are paused while the target is down.
+		if f.breaker.allow() {
+			if err := f.forward(ctx); err != nil && f.breaker.allow() {
+				slog.Error("forwarder has issues", "error", err, "forwarder", f.cfg.URL)
+			}
 		}
 		select {
 		case fn := <-f.fns:
@@ -151,13 +220,25 @@ func (f *forwarder) loadDocIDs(ctx context.Context) ([]int64, error) {
 	return docIDs, nil
 }
 
-func (f *forwarder) forwardDocument(ctx context.Context, docID int64) error {
+func (f *forwarder) forwardDocument(
+	ctx context.Context,
+	docID int64,
+	trig trigger,
+	actor sql.NullString,
+) error {
 	const documentSQL = `` +
 		`SELECT` +
 		` original,` +
+		` original_key,` +
 		` filename,` +
 		` publisher,` +
-		` (filename_failed OR remote_failed OR checksum_failed OR signature_failed) ` +
+		` (filename_failed OR remote_failed OR checksum_failed OR signature_failed),` +
+		` tracking_id,` +
+		` version,` +
+		` tlp,` +
+		` coalesce(document #>> '{document,publisher,namespace}', ''),` +
+		` state::text,` +
+		` critical ` +
 		`FROM documents` +
 		` JOIN downloads ON documents.id = downloads.documents_id` +
 		` JOIN advisories ON documents.advisories_id = advisories.id ` +
@@ -165,18 +246,27 @@ func (f *forwarder) forwardDocument(ctx context.Context, docID int64) error {
 		` documents.id = $1`
 	var (
 		doc              []byte
+		key              *string
 		filename         *string
 		failedValidation *bool
-		publisher        string
+		meta             documentMeta
+		attrs            docAttrs
 	)
 	switch err := f.db.Run(
 		ctx,
 		func(rctx context.Context, conn *pgxpool.Conn) error {
 			return conn.QueryRow(rctx, documentSQL, docID).Scan(
 				&doc,
+				&key,
 				&filename,
-				&publisher,
-				&failedValidation)
+				&meta.publisher,
+				&failedValidation,
+				&meta.trackingID,
+				&meta.version,
+				&meta.tlp,
+				&attrs.namespace,
+				&attrs.state,
+				&attrs.critical)
 		}, 0,
 	); {
 	case errors.Is(err, pgx.ErrNoRows):
@@ -184,33 +274,139 @@ func (f *forwarder) forwardDocument(ctx context.Context, docID int64) error {
 	case err != nil:
 		return fmt.Errorf("loading document failed: %w", err)
 	}
-	if !f.acceptsPublisher(publisher) {
+	if !f.acceptsPublisher(meta.publisher) {
 		return errors.New("not allowed to forward to target")
 	}
+	if meta.tlp != nil {
+		attrs.tlp = *meta.tlp
+	}
+	if !f.acceptsDocument(&attrs) {
+		return ErrRulesNotMatched
+	}
+	if !f.breaker.allow() {
+		return ErrTargetDown
+	}
+	doc, err := f.blobs.Original(ctx, doc, key)
+	if err != nil {
+		return fmt.Errorf("loading original document failed: %w", err)
+	}
 	// Build the request.
-	req, err := buildRequest(
-		doc,
-		filename,
-		parseValidationStatus(failedValidation),
-		f.cfg.URL,
-		f.headers,
-		f.documentURL(docID))
+	req, err := f.buildRequest(doc, filename, failedValidation, docID, &meta)
 	if err != nil {
 		return fmt.Errorf("building request failed: %w", err)
 	}
 	// Try to forward.
-	res, err := f.client.Do(req)
+	dr, err := f.deliver(ctx, req, docID, &meta, trig, actor)
 	if err != nil {
-		return fmt.Errorf("sending request failed: %w", err)
+		err = fmt.Errorf("sending request failed: %w", err)
+		f.failed(false, err)
+		return err
 	}
-	defer res.Body.Close()
-	if res.StatusCode != http.StatusCreated {
-		return fmt.Errorf(
-			"forwarding failed: code: %d, status: %q", res.StatusCode, res.Status)
+	if !dr.Delivered {
+		err := fmt.Errorf(
+			"forwarding failed: code: %d, status: %q", dr.StatusCode, dr.Status)
+		if unavailable(dr.StatusCode) {
+			f.failed(false, err)
+		} else {
+			f.breaker.success(false)
+		}
+		return err
+	}
+	f.breaker.success(false)
+	f.published(docID, &meta)
+	if f.cfg.Automatic {
+		// A replayed document is not forwarded automatically again.
+		f.updateQueue(ctx, docID, "uploaded")
 	}
 	return nil
 }
 
+// published announces the delivery of a document to the target.
+func (f *forwarder) published(docID int64, meta *documentMeta) {
+	target := f.cfg.Name
+	if target == "" {
+		target = f.cfg.URL
+	}
+	ev := eventbus.Event{
+		Type:       eventbus.ForwardDocumentEvent,
+		DocumentID: docID,
+		Publisher:  meta.publisher,
+		TrackingID: meta.trackingID,
+		Version:    meta.version,
+		Target:     target,
+	}
+	if meta.tlp != nil {
+		ev.TLP = *meta.tlp
+	}
+	f.bus.Publish(ev)
+}
+
+// buildRequest builds the upload request matching the type of the target.
+func (f *forwarder) buildRequest(
+	doc []byte,
+	filename *string,
+	failedValidation *bool,
+	docID int64,
+	meta *documentMeta,
+) (*http.Request, error) {
+	if f.cfg.Type == config.ForwardTargetTypeCSAFProvider {
+		return buildProviderRequest(
+			doc,
+			filename,
+			f.cfg.TLP,
+			f.cfg.Passphrase,
+			f.cfg.URL,
+			f.headers,
+			f.auth)
+	}
+	return buildRequest(
+		doc,
+		filename,
+		parseValidationStatus(failedValidation),
+		f.cfg.URL,
+		f.headers,
+		f.documentURL(docID),
+		f.banner(docID, meta))
+}
+
+// documentMeta are the values of a document used in the banners.
+type documentMeta struct {
+	publisher  string
+	trackingID string
+	version    string
+	tlp        *string
+}
+
+// banner returns the distribution banner and the provenance note
+// for a forwarded document if configured.
+func (f *forwarder) banner(docID int64, meta *documentMeta) string {
+	if f.banners == nil || !f.banners.Forwarding {
+		return ""
+	}
+	var tlp string
+	if meta.tlp != nil {
+		tlp = *meta.tlp
+	}
+	vars := map[string]string{
+		"tracking_id": meta.trackingID,
+		"publisher":   meta.publisher,
+		"version":     meta.version,
+		"tlp":         tlp,
+		"url":         f.documentURL(docID),
+		"date":        time.Now().UTC().Format(time.DateOnly),
+	}
+	banner, provenance := f.banners.Texts(tlp, vars)
+	return models.WithBanner("", banner, provenance)
+}
+
+// accepted returns true if the status code signals a successful upload.
+func (f *forwarder) accepted(code int) bool {
+	if f.cfg.Type == config.ForwardTargetTypeCSAFProvider {
+		return code == http.StatusOK
+	}
+	return code == http.StatusCreated
+}
+
 func (f *forwarder) documentURL(docID int64) string {
 	if f.externalURL == nil {
 		return ""
@@ -223,36 +419,41 @@ func (f *forwarder) loadForwardDocuments(
 	ctx context.Context,
 	docIDs []int64,
 ) error {
-	const (
-		documentSQL = `` +
-			`SELECT` +
-			` original,` +
-			` filename,` +
-			` (filename_failed OR remote_failed OR checksum_failed OR signature_failed) ` +
-			`FROM documents` +
-			` JOIN downloads ON documents.id = downloads.documents_id ` +
-			`WHERE` +
-			` documents.id = $1`
-		updateQueueSQL = `` +
-			`UPDATE forwarders_queue` +
-			` SET state = $1::forward_state ` +
-			`WHERE` +
-			` documents_id = $2 AND` +
-			` forwarders_id = (SELECT id FROM forwarders WHERE url = $3)`
-	)
+	const documentSQL = `` +
+		`SELECT` +
+		` original,` +
+		` original_key,` +
+		` filename,` +
+		` (filename_failed OR remote_failed OR checksum_failed OR signature_failed),` +
+		` publisher,` +
+		` tracking_id,` +
+		` version,` +
+		` tlp ` +
+		`FROM documents` +
+		` JOIN downloads ON documents.id = downloads.documents_id` +
+		` JOIN advisories ON documents.advisories_id = advisories.id ` +
+		`WHERE` +
+		` documents.id = $1`
 	for _, docID := range docIDs {
 		var (
 			doc              []byte
+			key              *string
 			filename         *string
 			failedValidation *bool
+			meta             documentMeta
 		)
 		switch err := f.db.Run(
 			ctx,
 			func(rctx context.Context, conn *pgxpool.Conn) error {
 				return conn.QueryRow(rctx, documentSQL, docID).Scan(
 					&doc,
+					&key,
 					&filename,
-					&failedValidation)
+					&failedValidation,
+					&meta.publisher,
+					&meta.trackingID,
+					&meta.version,
+					&meta.tlp)
 			}, 0,
 		); {
 		case errors.Is(err, pgx.ErrNoRows):
@@ -261,36 +462,47 @@ func (f *forwarder) loadForwardDocuments(
 		case err != nil:
 			return fmt.Errorf("loading document failed: %w", err)
 		}
+		doc, err := f.blobs.Original(ctx, doc, key)
+		if err != nil {
+			return fmt.Errorf("loading original document failed: %w", err)
+		}
 		// Build the request.
-		req, err := buildRequest(
-			doc,
-			filename,
-			parseValidationStatus(failedValidation),
-			f.cfg.URL,
-			f.headers,
-			f.documentURL(docID))
+		req, err := f.buildRequest(doc, filename, failedValidation, docID, &meta)
 		if err != nil {
 			return fmt.Errorf("building request failed: %w", err)
 		}
 		doc = nil // Not needed any longer as it is wrapped in the request.
-		res, err := f.client.Do(req)
+		dr, err := f.deliver(ctx, req, docID, &meta, triggerAutomatic, sql.NullString{})
 		req = nil
 		if err != nil {
-			return fmt.Errorf("sending request failed: %w", err)
+			// The document stays pending and is replayed later.
+			err = fmt.Errorf("sending request failed: %w", err)
+			f.failed(false, err)
+			return err
 		}
 		var result string
-		if res.StatusCode == http.StatusCreated {
+		switch {
+		case dr.Delivered:
 			result = "uploaded"
-		} else {
+			f.breaker.success(false)
+			f.published(docID, &meta)
+		case unavailable(dr.StatusCode):
+			// The document stays pending and is replayed later.
+			err := fmt.Errorf(
+				"target unavailable: code: %d, status: %q", dr.StatusCode, dr.Status)
+			f.failed(false, err)
+			return err
+		default:
+			// The target is alive but rejected the document.
+			f.breaker.success(false)
 			slog.Warn(
 				"forwarder",
 				"error", "failed",
-				"code", res.StatusCode,
-				"status", res.Status)
+				"document", docID,
+				"code", dr.StatusCode,
+				"status", dr.Status)
 			result = "failed"
 		}
-		// Close body to prevent memory leak.
-		res.Body.Close()
 		// Update the queue to the result of the upload.
 		if err := f.db.Run(
 			ctx,
@@ -307,14 +519,141 @@ func (f *forwarder) loadForwardDocuments(
 	return nil
 }
 
-func (f *forwarder) kill() {
-	f.fns <- func(f *forwarder) { f.done = true }
+// testDelivery sends a synthetic document to the target
+// and reports the round-trip result.
+func (f *forwarder) testDelivery(ctx context.Context) *models.DeliveryResult {
+	var publisher string
+	if f.cfg.Publisher != nil {
+		publisher = *f.cfg.Publisher
+	}
+	var namespace string
+	if f.externalURL != nil {
+		namespace = f.externalURL.String()
+	}
+	start := time.Now()
+	doc, filename, err := testDocument(publisher, namespace, start)
+	if err != nil {
+		return models.NewDeliveryResult(f.cfg.URL, start, nil, err, f.accepted)
+	}
+	var req *http.Request
+	if f.cfg.Type == config.ForwardTargetTypeCSAFProvider {
+		req, err = buildProviderRequest(
+			doc,
+			&filename,
+			f.cfg.TLP,
+			f.cfg.Passphrase,
+			f.cfg.URL,
+			f.headers,
+			f.auth)
+	} else {
+		req, err = buildRequest(
+			doc,
+			&filename,
+			validValidationStatus,
+			f.cfg.URL,
+			f.headers,
+			"", "")
+	}
+	if err != nil {
+		return models.NewDeliveryResult(f.cfg.URL, start, nil,
+			fmt.Errorf("building request failed: %w", err), f.accepted)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set(models.TestDeliveryHeader, "true")
+	res, err := f.client.Do(req)
+	return models.NewDeliveryResult(req.URL.String(), start, res, err, f.accepted)
+}
+
+// unavailable returns true if the status code signals that
+// the target is temporarily not able to receive documents.
+func unavailable(code int) bool {
+	return code >= http.StatusInternalServerError ||
+		code == http.StatusTooManyRequests ||
+		code == http.StatusRequestTimeout
+}
+
+// failed records a failed delivery or health check
+// and reports if the target is considered down by it.
+func (f *forwarder) failed(check bool, err error) {
+	if f.breaker.failure(check, err) {
+		slog.Warn("forward target is down, pausing deliveries",
+			"forwarder", f.cfg.URL, "error", err)
+	}
+}
+
+// checkHealth probes the target. Without a configured health URL
+// the target URL is probed and every response below 500 counts
+// as alive as upload endpoints often refuse other methods.
+func (f *forwarder) checkHealth(ctx context.Context) error {
+	method := strings.ToUpper(f.cfg.HealthMethod)
+	if method == "" {
+		method = http.MethodHead
+	}
+	probeURL := f.cfg.HealthURL
+	if probeURL == "" {
+		probeURL = f.cfg.URL
+	}
+	req, err := http.NewRequestWithContext(ctx, method, probeURL, nil)
+	if err != nil {
+		return fmt.Errorf("building health check request failed: %w", err)
+	}
+	for k, vs := range f.headers {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	res, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	io.Copy(io.Discard, io.LimitReader(res.Body, 4096))
+	res.Body.Close()
+	if unavailable(res.StatusCode) ||
+		(f.cfg.HealthURL != "" && res.StatusCode >= http.StatusBadRequest) {
+		return fmt.Errorf(
+			"health check failed: code: %d, status: %q", res.StatusCode, res.Status)
+	}
+	return nil
+}
+
+// monitor periodically checks the health of the target.
+// If a check succeeds after the target was down an automatic
+// forwarder is woken up to replay the pending documents.
+func (f *forwarder) monitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := f.checkHealth(ctx); err != nil {
+			f.failed(true, err)
+			continue
+		}
+		if !f.breaker.success(true) {
+			continue
+		}
+		slog.Info("forward target is up again, resuming deliveries",
+			"forwarder", f.cfg.URL)
+		if f.cfg.Automatic {
+			select {
+			case f.fns <- func(*forwarder) {}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
 }
 
 // ping should be called by the manager to signal the fowarder
 // that there are documents to forward.
 func (f *forwarder) ping() {
-	f.fns <- func(*forwarder) {}
+	select {
+	case f.fns <- func(*forwarder) {}:
+	case <-f.ctx.Done():
+	}
 }
 
 func (f *forwarder) acceptsPublisher(publisher string) bool {
@@ -0,0 +1,185 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package forwarder
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// ErrNotForwarded is returned if a document to be replayed
+// was never forwarded to any of the configured targets.
+var ErrNotForwarded = errors.New("document was not forwarded to a configured target")
+
+// trigger is the reason of a forward attempt.
+type trigger string
+
+const (
+	triggerAutomatic trigger = "automatic"
+	triggerManual    trigger = "manual"
+	triggerReplay    trigger = "replay"
+)
+
+// deliver sends the request to the target and records
+// the attempt in the forward log. The response is closed.
+// The returned error is only set if the request failed.
+func (f *forwarder) deliver(
+	ctx context.Context,
+	req *http.Request,
+	docID int64,
+	meta *documentMeta,
+	trig trigger,
+	actor sql.NullString,
+) (*models.DeliveryResult, error) {
+	start := time.Now()
+	res, err := f.client.Do(req)
+	dr := models.NewDeliveryResult(f.cfg.URL, start, res, err, f.accepted)
+	f.logDelivery(ctx, docID, meta, trig, actor, dr)
+	return dr, err
+}
+
+// logDelivery records a forward attempt in the forward log.
+func (f *forwarder) logDelivery(
+	ctx context.Context,
+	docID int64,
+	meta *documentMeta,
+	trig trigger,
+	actor sql.NullString,
+	dr *models.DeliveryResult,
+) {
+	const insertSQL = `INSERT INTO forward_log ` +
+		`(target, documents_id, publisher, tracking_id, version, ` +
+		`trigger, actor, delivered, status_code, response, error) ` +
+		`VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	var statusCode *int
+	if dr.StatusCode != 0 {
+		statusCode = &dr.StatusCode
+	}
+	// Record the attempt even if the triggering request is gone.
+	if err := f.db.Run(
+		context.WithoutCancel(ctx),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			_, err := conn.Exec(rctx, insertSQL,
+				f.cfg.URL, docID, meta.publisher, meta.trackingID, meta.version,
+				string(trig), actor, dr.Delivered, statusCode,
+				nilIfEmpty(dr.Response), nilIfEmpty(dr.Error))
+			return err
+		}, 0,
+	); err != nil {
+		slog.Error("recording forward attempt failed",
+			"forwarder", f.cfg.URL, "document", docID, "err", err)
+	}
+}
+
+// nilIfEmpty returns nil for empty strings.
+func nilIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// updateQueue stores the result of an upload in the queue
+// if the document is queued for the target.
+func (f *forwarder) updateQueue(ctx context.Context, docID int64, state string) {
+	if err := f.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			_, err := conn.Exec(rctx, updateQueueSQL, state, docID, f.cfg.URL)
+			return err
+		}, 0,
+	); err != nil {
+		slog.Error("updating forward queue failed",
+			"forwarder", f.cfg.URL, "document", docID, "err", err)
+	}
+}
+
+// ReplayResult is the result of replaying a document to a target.
+type ReplayResult struct {
+	ID        int64  `json:"id"`
+	URL       string `json:"url"`
+	Name      string `json:"name,omitempty"`
+	Delivered bool   `json:"delivered"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Replay sends a document again. If targetID is negative the document
+// is sent to all configured targets it was forwarded to before
+// according to the forward log and the queue. Otherwise it is sent to the specified
+// target. The attempts are recorded in the forward log.
+func (fm *Manager) Replay(
+	ctx context.Context,
+	docID int64,
+	targetID int64,
+	actor sql.NullString,
+) ([]ReplayResult, error) {
+	result := make(chan []*forwarder)
+	fm.fns <- func(fm *Manager) { result <- slices.Clone(fm.forwarders) }
+	forwarders := <-result
+
+	var selected []*forwarder
+	if targetID >= 0 {
+		idx := slices.IndexFunc(forwarders, func(fw *forwarder) bool { return fw.id == targetID })
+		if idx == -1 {
+			return nil, ErrNoSuchTarget
+		}
+		selected = []*forwarder{forwarders[idx]}
+	} else {
+		// The queue covers the documents forwarded before the log existed.
+		const targetsSQL = `SELECT target FROM forward_log WHERE documents_id = $1 ` +
+			`UNION ` +
+			`SELECT fw.url FROM forwarders_queue fwq ` +
+			`JOIN forwarders fw ON fwq.forwarders_id = fw.id ` +
+			`WHERE fwq.documents_id = $1 AND fwq.state <> 'pending'`
+		var targets []string
+		if err := fm.db.Run(
+			ctx,
+			func(rctx context.Context, conn *pgxpool.Conn) error {
+				rows, _ := conn.Query(rctx, targetsSQL, docID)
+				var err error
+				targets, err = pgx.CollectRows(rows, pgx.RowTo[string])
+				return err
+			}, 0,
+		); err != nil {
+			return nil, fmt.Errorf("loading forwarded targets failed: %w", err)
+		}
+		for _, fw := range forwarders {
+			if slices.Contains(targets, fw.cfg.URL) {
+				selected = append(selected, fw)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, ErrNotForwarded
+		}
+	}
+
+	// Deliver outside the manager to not block it.
+	results := make([]ReplayResult, 0, len(selected))
+	for _, fw := range selected {
+		rr := ReplayResult{ID: fw.id, URL: fw.cfg.URL, Name: fw.cfg.Name}
+		if err := fw.forwardDocument(ctx, docID, triggerReplay, actor); err != nil {
+			rr.Error = err.Error()
+		} else {
+			rr.Delivered = true
+		}
+		results = append(results, rr)
+	}
+	return results, nil
+}
@@ -12,7 +12,7 @@ package forwarder
 import (
 	"cmp"
 	"context"
-	"errors"
+	"database/sql"
 	"fmt"
 	"log/slog"
 	"net/url"
@@ -24,18 +24,31 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/ISDuBA/ISDuBA/pkg/blobs"
 	"github.com/ISDuBA/ISDuBA/pkg/config"
 	"github.com/ISDuBA/ISDuBA/pkg/database"
+	"github.com/ISDuBA/ISDuBA/pkg/eventbus"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/scheduler"
 )
 
 // Manager forwards documents to specified targets.
 type Manager struct {
-	cfg        *config.Forwarder
-	db         *database.DB
-	fns        chan func(*Manager)
-	done       bool
-	forwarders []*forwarder
-	changes    changedAdvisories
+	cfg         *config.Forwarder
+	banners     *config.Banners
+	externalURL *url.URL
+	db          *database.DB
+	blobs       *blobs.Store
+	bus         *eventbus.Bus
+	cipher      Cipher
+	fns         chan func(*Manager)
+	done        bool
+	forwarders  []*forwarder
+	changes     changedAdvisories
+	pollTask    *scheduler.Task
+	polling     bool
+	backfills   map[*forwarder]*backfill
+	ctx         context.Context
 }
 
 type (
@@ -46,6 +59,7 @@ type (
 		historyLength int
 		current       *time.Time
 		initial       *time.Time
+		attrs         docAttrs
 	}
 	versionInfos []versionInfo
 )
@@ -76,9 +90,14 @@ func parseTrackingStatus(s *string) trackingStatus {
 }
 
 // NewManager creates a new forward manager.
+// The cipher is used for the secrets of the targets managed via the API.
 func NewManager(
 	cfg *config.Config,
 	db *database.DB,
+	bs *blobs.Store,
+	bus *eventbus.Bus,
+	tasks *scheduler.Registry,
+	cipher Cipher,
 ) (*Manager, error) {
 	// TODO: Move this parsing to config.
 	var extURL *url.URL
@@ -93,7 +112,12 @@ func NewManager(
 	forwarders := make([]*forwarder, 0, len(fwdCfg.Targets))
 	for i := range fwdCfg.Targets {
 		tcfg := &fwdCfg.Targets[i]
-		forwarder, err := newForwarder(tcfg, extURL, db)
+		certs, err := loadClientCert(tcfg)
+		if err != nil {
+			return nil, err
+		}
+		forwarder, err := newForwarder(
+			tcfg, certs, &cfg.Banners, extURL, db, bs, bus, fwdCfg.FailureThreshold)
 		if err != nil {
 			return nil,
 				fmt.Errorf("create automatic forwarder for %q failed: %w",
@@ -102,44 +126,45 @@ func NewManager(
 		forwarders = append(forwarders, forwarder)
 	}
 	return &Manager{
-		cfg:        fwdCfg,
-		db:         db,
-		fns:        make(chan func(manager *Manager)),
-		forwarders: forwarders,
+		cfg:         fwdCfg,
+		banners:     &cfg.Banners,
+		externalURL: extURL,
+		db:          db,
+		blobs:       bs,
+		bus:         bus,
+		cipher:      cipher,
+		fns:         make(chan func(manager *Manager)),
+		forwarders:  forwarders,
+		backfills:   map[*forwarder]*backfill{},
+		pollTask: tasks.Register("forwarder_poll",
+			"Looks for changed advisories to be forwarded automatically.",
+			fwdCfg.UpdateInterval),
 	}, nil
 }
 
 // Run runs the forward manager. To be used in a Go routine.
 func (fm *Manager) Run(ctx context.Context) {
-	hasAutomatic := false
-	// Start the automatic forwarders.
-	for _, forwarder := range fm.forwarders {
-		if forwarder.cfg.Automatic {
-			if err := fm.createForwarder(ctx, forwarder.cfg.URL); err != nil {
-				slog.Error("forwarder", "error", err)
-			} else {
-				hasAutomatic = true
-				go forwarder.run(ctx)
-				defer forwarder.kill()
-			}
+	// Stop the forwarders and the poller if the manager is killed.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	fm.ctx = ctx
+	fm.interruptedBackfills(ctx)
+	// The configured targets get their ids from the database.
+	configured := fm.forwarders
+	fm.forwarders = make([]*forwarder, 0, len(configured))
+	for _, forwarder := range configured {
+		id, err := fm.createForwarder(ctx, forwarder.cfg.URL)
+		if err != nil {
+			slog.Error("forwarder", "error", err)
+			continue
 		}
+		forwarder.id = id
+		fm.forwarders = append(fm.forwarders, forwarder)
 	}
-	// No need to poll if there are no automatic forwarders.
-	if !hasAutomatic {
-		for !fm.done {
-			select {
-			case fn := <-fm.fns:
-				fn(fm)
-			case <-ctx.Done():
-				return
-			}
-		}
-		return
+	fm.forwarders = append(fm.forwarders, fm.loadTargets(ctx)...)
+	for _, forwarder := range fm.forwarders {
+		fm.startForwarder(forwarder)
 	}
-	// Start the poller
-	poller := newPoller(fm)
-	go poller.run(ctx)
-	defer poller.kill()
 	// The poller should wake us up but in case wake up
 	// on our own timer based.
 	ticker := time.NewTicker(fm.cfg.UpdateInterval / 2)
@@ -156,22 +181,44 @@ func (fm *Manager) Run(ctx context.Context) {
 	}
 }
 
+// startForwarder starts a forwarder. The poller is started
+// with the first automatic forwarder as there is no need
+// to poll without them.
+func (fm *Manager) startForwarder(fw *forwarder) {
+	fw.start(fm.ctx, fm.cfg.HealthInterval)
+	if fw.cfg.Automatic && !fm.polling {
+		fm.polling = true
+		go newPoller(fm).run(fm.ctx)
+	}
+}
+
+// findForwarder returns the forwarder with the given id.
+func (fm *Manager) findForwarder(id int64) *forwarder {
+	for _, fw := range fm.forwarders {
+		if fw.id == id {
+			return fw
+		}
+	}
+	return nil
+}
+
 // createForwarder ensure the existence of a forwarder in the forwarder
-// lookup table.
-func (fm *Manager) createForwarder(ctx context.Context, url string) error {
+// lookup table and returns its id.
+func (fm *Manager) createForwarder(ctx context.Context, url string) (int64, error) {
 	const insertForwarderSQL = `` +
 		`INSERT INTO forwarders (url) VALUES ($1) ` +
-		`ON CONFLICT (url) DO NOTHING`
+		`ON CONFLICT (url) DO UPDATE SET url = EXCLUDED.url ` +
+		`RETURNING id`
+	var id int64
 	if err := fm.db.Run(
 		ctx,
 		func(rctx context.Context, conn *pgxpool.Conn) error {
-			_, err := conn.Exec(rctx, insertForwarderSQL, url)
-			return err
+			return conn.QueryRow(rctx, insertForwarderSQL, url).Scan(&id)
 		}, 0,
 	); err != nil {
-		return fmt.Errorf("inserting forwarder %q failed: %w", url, err)
+		return 0, fmt.Errorf("inserting forwarder %q failed: %w", url, err)
 	}
-	return nil
+	return id, nil
 }
 
 // changesDetected tries to deliver detected advisory changes to
@@ -251,6 +298,14 @@ func (vis versionInfos) filterNewAndMajor() []int {
 	return indices
 }
 
+// strategy returns the strategy of a forwarder.
+func (fm *Manager) strategy(fw *forwarder) config.ForwarderStrategy {
+	if fw.cfg.Strategy != nil {
+		return *fw.cfg.Strategy
+	}
+	return fm.cfg.Strategy
+}
+
 // fillForwarderQueues takes the advisory changes aggregated by the poller
 func (fm *Manager) fillForwarderQueues(ctx context.Context) {
 	if len(fm.changes) == 0 {
@@ -289,23 +344,20 @@ func (fm *Manager) fillForwarderQueues(ctx context.Context) {
 					if !fw.cfg.Automatic || !fw.acceptsPublisher(adv.publisher) {
 						continue
 					}
-					strategy := fm.cfg.Strategy
-					if fw.cfg.Strategy != nil {
-						strategy = *fw.cfg.Strategy
-					}
-					fi := filterIndex[strategy]
+					fi := filterIndex[fm.strategy(fw)]
 					cachedIndices := indicesCache[fi]
 					if indicesCache[fi] == nil {
 						cachedIndices = filters[fi](vis)
 						indicesCache[fi] = cachedIndices
 					}
+					indices := fw.acceptedIndices(vis, cachedIndices)
 					// Nothing to do.
-					if len(cachedIndices) == 0 {
+					if len(indices) == 0 {
 						continue
 					}
 					if err := storeIndicesInQueue(
 						ctx, conn,
-						vis, cachedIndices,
+						vis, indices,
 						fw.cfg.URL,
 					); err != nil {
 						return err
@@ -356,15 +408,20 @@ func loadVersionInfos(
 ) (versionInfos, error) {
 	const versionSQL = `` +
 		`SELECT` +
-		` id,` +
+		` docs.id,` +
 		` version,` +
 		` tracking_status::text,` +
 		` coalesce(rev_history_length, 0),` +
 		` current_release_date,` +
-		` initial_release_date ` +
-		`FROM documents ` +
+		` initial_release_date,` +
+		` coalesce(document #>> '{document,publisher,namespace}', ''),` +
+		` coalesce(tlp, ''),` +
+		` ads.state::text,` +
+		` critical ` +
+		`FROM documents docs` +
+		` JOIN advisories ads ON docs.advisories_id = ads.id ` +
 		`WHERE` +
-		` advisories_id = $1`
+		` docs.advisories_id = $1`
 	rows, _ := conn.Query(ctx, versionSQL, advisoryID)
 	vs, err := pgx.CollectRows(
 		rows,
@@ -377,7 +434,11 @@ func loadVersionInfos(
 				&status,
 				&vi.historyLength,
 				&vi.current,
-				&vi.initial)
+				&vi.initial,
+				&vi.attrs.namespace,
+				&vi.attrs.tlp,
+				&vi.attrs.state,
+				&vi.attrs.critical)
 			vi.status = parseTrackingStatus(status)
 			if vi.current != nil {
 				*vi.current = vi.current.UTC()
@@ -423,7 +484,7 @@ func (vis versionInfos) order() {
 type ForwardTarget struct {
 	URL  string `json:"url"`
 	Name string `json:"name,omitempty"`
-	ID   int    `json:"id"`
+	ID   int64  `json:"id"`
 }
 
 // Targets returns a list of forward targets.
@@ -431,11 +492,11 @@ func (fm *Manager) Targets() []ForwardTarget {
 	result := make(chan []ForwardTarget)
 	fm.fns <- func(fm *Manager) {
 		forwarders := make([]ForwardTarget, 0, len(fm.forwarders))
-		for i, forwarder := range fm.forwarders {
+		for _, forwarder := range fm.forwarders {
 			if !forwarder.cfg.Automatic {
 				forwarders = append(
 					forwarders, ForwardTarget{
-						ID:   i,
+						ID:   forwarder.id,
 						URL:  forwarder.cfg.URL,
 						Name: forwarder.cfg.Name,
 					})
@@ -447,18 +508,107 @@ func (fm *Manager) Targets() []ForwardTarget {
 }
 
 // ForwardDocument sends the document to the specified target.
-func (fm *Manager) ForwardDocument(ctx context.Context, targetID int, docID int64) error {
+// The attempt is recorded in the forward log with the given actor.
+func (fm *Manager) ForwardDocument(
+	ctx context.Context,
+	targetID int64,
+	docID int64,
+	actor sql.NullString,
+) error {
 	result := make(chan error)
 	fm.fns <- func(fm *Manager) {
-		if targetID < 0 || targetID >= len(fm.forwarders) || fm.forwarders[targetID].cfg.Automatic {
-			result <- errors.New("could not find target with specified id")
+		fw := fm.findForwarder(targetID)
+		if fw == nil || fw.cfg.Automatic {
+			result <- ErrNoSuchTarget
 			return
 		}
-		result <- fm.forwarders[targetID].forwardDocument(ctx, docID)
+		result <- fw.forwardDocument(ctx, docID, triggerManual, actor)
 	}
 	return <-result
 }
 
+// TestTarget sends a synthetic test document to the specified target
+// and returns the round-trip result. Automatic targets can be tested, too.
+func (fm *Manager) TestTarget(ctx context.Context, targetID int64) (*models.DeliveryResult, error) {
+	result := make(chan *forwarder)
+	fm.fns <- func(fm *Manager) { result <- fm.findForwarder(targetID) }
+	fw := <-result
+	if fw == nil {
+		return nil, ErrNoSuchTarget
+	}
+	// Deliver outside the manager to not block it.
+	return fw.testDelivery(ctx), nil
+}
+
+// TargetStatus is the health of a forward target.
+type TargetStatus struct {
+	ID        int64      `json:"id"`
+	URL       string     `json:"url"`
+	Name      string     `json:"name,omitempty"`
+	Automatic bool       `json:"automatic"`
+	Dynamic   bool       `json:"dynamic"`
+	State     string     `json:"state"`
+	Since     time.Time  `json:"since"`
+	Failures  int        `json:"failures"`
+	LastCheck *time.Time `json:"last_check,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	Pending   int64      `json:"pending"`
+}
+
+// TargetStatuses returns the health of all forward targets
+// together with the number of documents waiting to be forwarded.
+func (fm *Manager) TargetStatuses(ctx context.Context) ([]TargetStatus, error) {
+	result := make(chan []*forwarder)
+	fm.fns <- func(fm *Manager) { result <- slices.Clone(fm.forwarders) }
+	forwarders := <-result
+
+	const pendingSQL = `` +
+		`SELECT fw.url, count(*) ` +
+		`FROM forwarders_queue fwq ` +
+		`JOIN forwarders fw ON fwq.forwarders_id = fw.id ` +
+		`WHERE fwq.state = 'pending' ` +
+		`GROUP BY fw.url`
+	pending := map[string]int64{}
+	if err := fm.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			var (
+				url   string
+				count int64
+			)
+			rows, _ := conn.Query(rctx, pendingSQL)
+			_, err := pgx.ForEachRow(rows, []any{&url, &count}, func() error {
+				pending[url] = count
+				return nil
+			})
+			return err
+		}, 0,
+	); err != nil {
+		return nil, fmt.Errorf("counting pending documents failed: %w", err)
+	}
+
+	statuses := make([]TargetStatus, len(forwarders))
+	for i, fw := range forwarders {
+		bs := fw.breaker.status()
+		statuses[i] = TargetStatus{
+			ID:        fw.id,
+			URL:       fw.cfg.URL,
+			Name:      fw.cfg.Name,
+			Automatic: fw.cfg.Automatic,
+			Dynamic:   fw.dynamic != nil,
+			State:     bs.State.String(),
+			Since:     bs.Since,
+			Failures:  bs.Failures,
+			LastError: bs.LastError,
+			Pending:   pending[fw.cfg.URL],
+		}
+		if !bs.LastCheck.IsZero() {
+			statuses[i].LastCheck = &bs.LastCheck
+		}
+	}
+	return statuses, nil
+}
+
 // Kill shuts down the forward manager.
 func (fm *Manager) Kill() {
 	fm.fns <- func(fm *Manager) { fm.done = true }
@@ -49,8 +49,9 @@ func newPoller(manager *Manager) *poller {
 }
 
 func (p *poller) run(ctx context.Context) {
+	task := p.manager.pollTask
 	// Do an initial poll to fill the manager early.
-	p.poll(ctx)
+	p.scheduledPoll(ctx)
 	ticker := time.NewTicker(p.manager.cfg.UpdateInterval)
 	defer ticker.Stop()
 	for !p.done {
@@ -60,16 +61,26 @@ func (p *poller) run(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			p.poll(ctx)
+			if !task.Paused() {
+				p.scheduledPoll(ctx)
+			}
+		case <-task.Triggered():
+			p.scheduledPoll(ctx)
 		}
 	}
 }
 
-func (p *poller) kill() {
-	p.fns <- func(p *poller) { p.done = true }
+// scheduledPoll polls and records the run in the scheduler task.
+func (p *poller) scheduledPoll(ctx context.Context) {
+	// Wait for the next round if the database is unavailable.
+	if !p.manager.db.Available() {
+		return
+	}
+	done := p.manager.pollTask.Start()
+	done(p.poll(ctx))
 }
 
-func (p *poller) poll(ctx context.Context) {
+func (p *poller) poll(ctx context.Context) error {
 	const recentSQL = `` +
 		`SELECT` +
 		` ads.id AS id,` +
@@ -127,7 +138,7 @@ func (p *poller) poll(ctx context.Context) {
 		// Instead do so the next time we wake up.
 		// The db will be up then again, hopefully.
 		slog.Error("forwarder", "error", err)
-		return
+		return err
 	}
 
 	// Try to deliver changes to manager.
@@ -138,6 +149,7 @@ func (p *poller) poll(ctx context.Context) {
 		// the changes when it is not busy.
 		p.changes = p.manager.changesDetected(p.changes)
 	}
+	return nil
 }
 
 func (cas changedAdvisories) order() orderedAdvisories {
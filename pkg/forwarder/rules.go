@@ -0,0 +1,97 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package forwarder
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// ErrRulesNotMatched is returned if a document is forwarded
+// to a target whose rules it does not match.
+var ErrRulesNotMatched = errors.New("document does not match the rules of the target")
+
+// docAttrs are the attributes of a document checked by the forward rules.
+type docAttrs struct {
+	namespace string
+	tlp       string
+	state     models.Workflow
+	critical  *float64
+}
+
+// normalizeTLP returns the upper case TLP label.
+// The TLP v2 label CLEAR stands in for the TLP v1 label WHITE.
+func normalizeTLP(tlp string) string {
+	if tlp = strings.ToUpper(tlp); tlp == "CLEAR" {
+		return string(models.TLPWhite)
+	}
+	return tlp
+}
+
+// sameNamespace compares two publisher namespaces ignoring
+// the case and trailing slashes.
+func sameNamespace(a, b string) bool {
+	return strings.EqualFold(strings.TrimRight(a, "/"), strings.TrimRight(b, "/"))
+}
+
+// ruleMatches checks if a document meets all the conditions of a rule.
+func ruleMatches(rule *config.ForwardRule, attrs *docAttrs) bool {
+	if len(rule.Namespaces) > 0 && !slices.ContainsFunc(rule.Namespaces, func(ns string) bool {
+		return sameNamespace(ns, attrs.namespace)
+	}) {
+		return false
+	}
+	if len(rule.TLPs) > 0 && !slices.ContainsFunc(rule.TLPs, func(tlp string) bool {
+		return attrs.tlp != "" && normalizeTLP(tlp) == normalizeTLP(attrs.tlp)
+	}) {
+		return false
+	}
+	if len(rule.States) > 0 && !slices.Contains(rule.States, attrs.state) {
+		return false
+	}
+	return rule.MinCVSS == nil || (attrs.critical != nil && *attrs.critical >= *rule.MinCVSS)
+}
+
+// matchRules checks if a document matches one of the rules.
+// Without rules all documents match.
+func matchRules(rules []config.ForwardRule, attrs *docAttrs) bool {
+	if len(rules) == 0 {
+		return true
+	}
+	for i := range rules {
+		if ruleMatches(&rules[i], attrs) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsDocument checks if a document matches the rules of the target.
+func (f *forwarder) acceptsDocument(attrs *docAttrs) bool {
+	return matchRules(f.cfg.Rules, attrs)
+}
+
+// acceptedIndices returns the indices of the versions
+// matching the rules of the target.
+func (f *forwarder) acceptedIndices(vis versionInfos, indices []int) []int {
+	if len(f.cfg.Rules) == 0 {
+		return indices
+	}
+	accepted := make([]int, 0, len(indices))
+	for _, idx := range indices {
+		if f.acceptsDocument(&vis[idx].attrs) {
+			accepted = append(accepted, idx)
+		}
+	}
+	return accepted
+}
@@ -0,0 +1,59 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package forwarder
+
+import (
+	"testing"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+func TestMatchRules(t *testing.T) {
+	score := func(f float64) *float64 { return &f }
+	doc := docAttrs{
+		namespace: "https://example.com/",
+		tlp:       "CLEAR",
+		state:     models.NewWorkflow,
+		critical:  score(7.5),
+	}
+	for _, tc := range []struct {
+		name  string
+		rules []config.ForwardRule
+		attrs docAttrs
+		want  bool
+	}{
+		{"no rules", nil, doc, true},
+		{"empty rule", []config.ForwardRule{{}}, doc, true},
+		{"namespace", []config.ForwardRule{{Namespaces: []string{"https://EXAMPLE.com"}}}, doc, true},
+		{"other namespace", []config.ForwardRule{{Namespaces: []string{"https://example.org"}}}, doc, false},
+		{"white is clear", []config.ForwardRule{{TLPs: []string{"white"}}}, doc, true},
+		{"other tlp", []config.ForwardRule{{TLPs: []string{"RED"}}}, doc, false},
+		{"no tlp", []config.ForwardRule{{TLPs: []string{"WHITE"}}}, docAttrs{}, false},
+		{"state", []config.ForwardRule{{States: []models.Workflow{models.NewWorkflow}}}, doc, true},
+		{"other state", []config.ForwardRule{{States: []models.Workflow{models.ReviewWorkflow}}}, doc, false},
+		{"cvss", []config.ForwardRule{{MinCVSS: score(7.5)}}, doc, true},
+		{"cvss too low", []config.ForwardRule{{MinCVSS: score(9)}}, doc, false},
+		{"no cvss", []config.ForwardRule{{MinCVSS: score(0)}}, docAttrs{}, false},
+		{"all conditions", []config.ForwardRule{{
+			TLPs:    []string{"WHITE"},
+			MinCVSS: score(9),
+		}}, doc, false},
+		{"any rule", []config.ForwardRule{
+			{TLPs: []string{"RED"}},
+			{MinCVSS: score(7)},
+		}, doc, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := matchRules(tc.rules, &tc.attrs); got != tc.want {
+				t.Errorf("got %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,507 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package forwarder
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+)
+
+var (
+	// ErrTargetConfigured is returned if a target defined
+	// in the configuration is to be changed via the API.
+	ErrTargetConfigured = errors.New("target is defined in the configuration")
+	// ErrTargetExists is returned if a target with the same URL already exists.
+	ErrTargetExists = errors.New("target with this URL already exists")
+	// ErrInvalidTarget is returned if the configuration of a target is invalid.
+	ErrInvalidTarget = errors.New("invalid forward target")
+)
+
+// Cipher encrypts and decrypts the secrets of the targets
+// managed via the API.
+type Cipher interface {
+	EncryptSecret([]byte) ([]byte, error)
+	DecryptSecret([]byte) ([]byte, error)
+}
+
+// TargetConfig is the configuration of a target managed via the API.
+// The client certificate is given as PEM data instead of file names.
+// If a secret is nil when updating a target the stored one is kept.
+// Setting the password or the passphrase to an empty string removes it.
+// The client certificate is removed if its public part is nil.
+type TargetConfig struct {
+	config.ForwardTarget
+	ClientCertPublic  []byte
+	ClientCertPrivate []byte
+}
+
+// TargetInfo is the configuration of a target without its secrets.
+type TargetInfo struct {
+	ID            int64
+	Dynamic       bool
+	Target        config.ForwardTarget
+	HasPassword   bool
+	HasPassphrase bool
+	HasClientCert bool
+}
+
+// targetRow is a target managed via the API as stored in the database.
+type targetRow struct {
+	TargetConfig
+	id       int64
+	strategy *string
+	typ      string
+	timeout  *time.Duration
+}
+
+// dynamicForwarder creates the forwarder of a target managed via the API.
+func (fm *Manager) dynamicForwarder(id int64, tc *TargetConfig) (*forwarder, error) {
+	var certs []tls.Certificate
+	if tc.ClientCertPublic != nil {
+		cert, err := tls.X509KeyPair(tc.ClientCertPublic, tc.ClientCertPrivate)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot load client cert for forward target %q: %w", tc.URL, err)
+		}
+		certs = []tls.Certificate{cert}
+	}
+	cfg := tc.ForwardTarget
+	// These are files of configured targets only.
+	cfg.ClientPublicCert, cfg.ClientPrivateCert = "", ""
+	fw, err := newForwarder(
+		&cfg, certs, fm.banners, fm.externalURL, fm.db, fm.blobs, fm.bus, fm.cfg.FailureThreshold)
+	if err != nil {
+		return nil, err
+	}
+	fw.id = id
+	fw.dynamic = tc
+	return fw, nil
+}
+
+// loadTargets loads the targets managed via the API.
+// Targets which cannot be loaded are logged and skipped.
+func (fm *Manager) loadTargets(ctx context.Context) []*forwarder {
+	const selectSQL = `SELECT fw.id, fw.url, ` +
+		`ft.name, ft.type, ft.automatic, ft.strategy, ft.publisher, ft.headers, ` +
+		`ft.timeout, ft.tlp, ft.password, ft.passphrase, ` +
+		`ft.client_cert_public, ft.client_cert_private, ` +
+		`ft.health_url, ft.health_method, ft.rules ` +
+		`FROM forward_targets ft JOIN forwarders fw ON ft.forwarders_id = fw.id ` +
+		`ORDER BY fw.id`
+	var rows []*targetRow
+	if err := fm.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rs, _ := conn.Query(rctx, selectSQL)
+			var err error
+			rows, err = pgx.CollectRows(rs, func(row pgx.CollectableRow) (*targetRow, error) {
+				var (
+					tr         targetRow
+					name, tlp  *string
+					healthURL  *string
+					healthMeth *string
+					password   []byte
+					passphrase []byte
+				)
+				if err := row.Scan(
+					&tr.id, &tr.URL,
+					&name, &tr.typ, &tr.Automatic, &tr.strategy, &tr.Publisher, &tr.Header,
+					&tr.timeout, &tlp, &password, &passphrase,
+					&tr.ClientCertPublic, &tr.ClientCertPrivate,
+					&healthURL, &healthMeth, &tr.Rules,
+				); err != nil {
+					return nil, err
+				}
+				tr.Name = deref(name)
+				tr.TLP = deref(tlp)
+				tr.HealthURL = deref(healthURL)
+				tr.HealthMethod = deref(healthMeth)
+				tr.Password = bytesPtr(password)
+				tr.Passphrase = bytesPtr(passphrase)
+				return &tr, nil
+			})
+			return err
+		}, 0,
+	); err != nil {
+		slog.Error("loading forward targets failed", "err", err)
+		return nil
+	}
+	forwarders := make([]*forwarder, 0, len(rows))
+	for _, tr := range rows {
+		fw, err := fm.openTarget(tr)
+		if err != nil {
+			slog.Error("loading forward target failed", "target", tr.URL, "err", err)
+			continue
+		}
+		forwarders = append(forwarders, fw)
+	}
+	return forwarders
+}
+
+// openTarget decrypts the secrets of a stored target and creates its forwarder.
+func (fm *Manager) openTarget(tr *targetRow) (*forwarder, error) {
+	typ, err := config.ParseForwardTargetType(tr.typ)
+	if err != nil {
+		return nil, err
+	}
+	tr.Type = typ
+	if tr.strategy != nil {
+		strategy, err := config.ParseForwarderStrategy(*tr.strategy)
+		if err != nil {
+			return nil, err
+		}
+		tr.Strategy = &strategy
+	}
+	if tr.timeout != nil {
+		tr.Timeout = *tr.timeout
+	}
+	for _, secret := range []*string{tr.Password, tr.Passphrase} {
+		if secret != nil {
+			plain, err := fm.cipher.DecryptSecret([]byte(*secret))
+			if err != nil {
+				return nil, err
+			}
+			*secret = string(plain)
+		}
+	}
+	if tr.ClientCertPrivate != nil {
+		if tr.ClientCertPrivate, err = fm.cipher.DecryptSecret(tr.ClientCertPrivate); err != nil {
+			return nil, err
+		}
+	}
+	return fm.dynamicForwarder(tr.id, &tr.TargetConfig)
+}
+
+// bytesPtr returns nil for nil slices.
+func bytesPtr(b []byte) *string {
+	if b == nil {
+		return nil
+	}
+	s := string(b)
+	return &s
+}
+
+// deref returns the empty string for nil.
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// nilIfEmptyBytes returns nil for empty slices.
+func nilIfEmptyBytes(b []byte) []byte {
+	if len(b) == 0 {
+		return nil
+	}
+	return b
+}
+
+// encryptSecrets returns the encrypted secrets of a target.
+func (fm *Manager) encryptSecrets(tc *TargetConfig) (password, passphrase, private []byte, err error) {
+	for _, x := range []struct {
+		dst   *[]byte
+		plain []byte
+	}{
+		{&password, secretBytes(tc.Password)},
+		{&passphrase, secretBytes(tc.Passphrase)},
+		{&private, nilIfEmptyBytes(tc.ClientCertPrivate)},
+	} {
+		if *x.dst, err = fm.cipher.EncryptSecret(x.plain); err != nil {
+			return nil, nil, nil, fmt.Errorf("encrypting secret failed: %w", err)
+		}
+	}
+	return password, passphrase, private, nil
+}
+
+// secretBytes returns nil for missing or empty secrets.
+func secretBytes(s *string) []byte {
+	if s == nil || *s == "" {
+		return nil
+	}
+	return []byte(*s)
+}
+
+// storeArgs returns the arguments to store a target in the database
+// in the order of the columns of the forward_targets table.
+func (fm *Manager) storeArgs(tc *TargetConfig) ([]any, error) {
+	password, passphrase, private, err := fm.encryptSecrets(tc)
+	if err != nil {
+		return nil, err
+	}
+	var strategy *string
+	if tc.Strategy != nil {
+		s := tc.Strategy.String()
+		strategy = &s
+	}
+	var timeout *time.Duration
+	if tc.Timeout != 0 {
+		timeout = &tc.Timeout
+	}
+	var rules []config.ForwardRule
+	if len(tc.Rules) > 0 {
+		rules = tc.Rules
+	}
+	return []any{
+		nilIfEmpty(tc.Name), tc.Type.String(), tc.Automatic, strategy,
+		tc.Publisher, tc.Header, timeout, nilIfEmpty(tc.TLP),
+		password, passphrase,
+		nilIfEmptyBytes(tc.ClientCertPublic), private,
+		nilIfEmpty(tc.HealthURL), nilIfEmpty(tc.HealthMethod), rules,
+	}, nil
+}
+
+// checkTarget validates the configuration of a target
+// and checks that its URL is not used by another target.
+// Empty secrets are removed.
+func (fm *Manager) checkTarget(tc *TargetConfig, id int64) error {
+	if tc.Password != nil && *tc.Password == "" {
+		tc.Password = nil
+	}
+	if tc.Passphrase != nil && *tc.Passphrase == "" {
+		tc.Passphrase = nil
+	}
+	if err := tc.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidTarget, err)
+	}
+	if slices.ContainsFunc(fm.forwarders, func(fw *forwarder) bool {
+		return fw.id != id && fw.cfg.URL == tc.URL
+	}) {
+		return ErrTargetExists
+	}
+	return nil
+}
+
+// AddTarget adds a target managed via the API and starts forwarding to it.
+// It returns the id of the new target.
+func (fm *Manager) AddTarget(
+	ctx context.Context,
+	tc *TargetConfig,
+	creator sql.NullString,
+) (int64, error) {
+	type result struct {
+		id  int64
+		err error
+	}
+	resCh := make(chan result)
+	fm.fns <- func(fm *Manager) {
+		if err := fm.checkTarget(tc, -1); err != nil {
+			resCh <- result{err: err}
+			return
+		}
+		// Check the configuration before storing it.
+		fw, err := fm.dynamicForwarder(0, tc)
+		if err != nil {
+			resCh <- result{err: fmt.Errorf("%w: %w", ErrInvalidTarget, err)}
+			return
+		}
+		args, err := fm.storeArgs(tc)
+		if err != nil {
+			resCh <- result{err: err}
+			return
+		}
+		const insertSQL = `INSERT INTO forward_targets (` +
+			`forwarders_id, name, type, automatic, strategy, publisher, headers, ` +
+			`timeout, tlp, password, passphrase, client_cert_public, client_cert_private, ` +
+			`health_url, health_method, rules, creator) ` +
+			`VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`
+		var id int64
+		if err := fm.db.Run(
+			ctx,
+			func(rctx context.Context, conn *pgxpool.Conn) error {
+				tx, err := conn.Begin(rctx)
+				if err != nil {
+					return err
+				}
+				defer tx.Rollback(rctx)
+				// The forwarder may exist from an earlier configured target.
+				if err := tx.QueryRow(rctx,
+					`INSERT INTO forwarders (url) VALUES ($1) `+
+						`ON CONFLICT (url) DO UPDATE SET url = EXCLUDED.url `+
+						`RETURNING id`, tc.URL,
+				).Scan(&id); err != nil {
+					return err
+				}
+				values := append([]any{id}, args...)
+				values = append(values, creator)
+				if _, err := tx.Exec(rctx, insertSQL, values...); err != nil {
+					return err
+				}
+				return tx.Commit(rctx)
+			}, 0,
+		); err != nil {
+			resCh <- result{err: fmt.Errorf("storing forward target failed: %w", err)}
+			return
+		}
+		fw.id = id
+		fm.forwarders = append(fm.forwarders, fw)
+		fm.startForwarder(fw)
+		resCh <- result{id: id}
+	}
+	res := <-resCh
+	return res.id, res.err
+}
+
+// UpdateTarget replaces the configuration of a target managed via the API.
+// The forwarder is restarted with the new configuration.
+func (fm *Manager) UpdateTarget(ctx context.Context, id int64, tc *TargetConfig) error {
+	resCh := make(chan error)
+	fm.fns <- func(fm *Manager) {
+		idx := slices.IndexFunc(fm.forwarders, func(fw *forwarder) bool { return fw.id == id })
+		if idx == -1 {
+			resCh <- ErrNoSuchTarget
+			return
+		}
+		old := fm.forwarders[idx]
+		if old.dynamic == nil {
+			resCh <- ErrTargetConfigured
+			return
+		}
+		// Keep the secrets which are not given.
+		if tc.Password == nil {
+			tc.Password = old.cfg.Password
+		}
+		if tc.Passphrase == nil {
+			tc.Passphrase = old.cfg.Passphrase
+		}
+		if tc.ClientCertPublic != nil && tc.ClientCertPrivate == nil {
+			tc.ClientCertPrivate = old.dynamic.ClientCertPrivate
+		}
+		if tc.ClientCertPublic == nil {
+			tc.ClientCertPrivate = nil
+		}
+		if err := fm.checkTarget(tc, id); err != nil {
+			resCh <- err
+			return
+		}
+		// Check the configuration before storing it.
+		fw, err := fm.dynamicForwarder(id, tc)
+		if err != nil {
+			resCh <- fmt.Errorf("%w: %w", ErrInvalidTarget, err)
+			return
+		}
+		args, err := fm.storeArgs(tc)
+		if err != nil {
+			resCh <- err
+			return
+		}
+		const updateSQL = `UPDATE forward_targets SET (` +
+			`name, type, automatic, strategy, publisher, headers, ` +
+			`timeout, tlp, password, passphrase, client_cert_public, client_cert_private, ` +
+			`health_url, health_method, rules, updated) = ` +
+			`($2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, current_timestamp) ` +
+			`WHERE forwarders_id = $1`
+		if err := fm.db.Run(
+			ctx,
+			func(rctx context.Context, conn *pgxpool.Conn) error {
+				tx, err := conn.Begin(rctx)
+				if err != nil {
+					return err
+				}
+				defer tx.Rollback(rctx)
+				if _, err := tx.Exec(rctx,
+					`UPDATE forwarders SET url = $2 WHERE id = $1`, id, tc.URL,
+				); err != nil {
+					return err
+				}
+				if _, err := tx.Exec(rctx, updateSQL, append([]any{id}, args...)...); err != nil {
+					return err
+				}
+				return tx.Commit(rctx)
+			}, 0,
+		); err != nil {
+			resCh <- fmt.Errorf("updating forward target failed: %w", err)
+			return
+		}
+		fm.stopForwarder(old)
+		fm.forwarders[idx] = fw
+		fm.startForwarder(fw)
+		resCh <- nil
+	}
+	return <-resCh
+}
+
+// RemoveTarget removes a target managed via the API.
+// Its queue and backfills are removed, too.
+// The attempts in the forward log are kept.
+func (fm *Manager) RemoveTarget(ctx context.Context, id int64) error {
+	resCh := make(chan error)
+	fm.fns <- func(fm *Manager) {
+		idx := slices.IndexFunc(fm.forwarders, func(fw *forwarder) bool { return fw.id == id })
+		if idx == -1 {
+			resCh <- ErrNoSuchTarget
+			return
+		}
+		fw := fm.forwarders[idx]
+		if fw.dynamic == nil {
+			resCh <- ErrTargetConfigured
+			return
+		}
+		if err := fm.db.Run(
+			ctx,
+			func(rctx context.Context, conn *pgxpool.Conn) error {
+				_, err := conn.Exec(rctx, `DELETE FROM forwarders WHERE id = $1`, id)
+				return err
+			}, 0,
+		); err != nil {
+			resCh <- fmt.Errorf("removing forward target failed: %w", err)
+			return
+		}
+		fm.stopForwarder(fw)
+		fm.forwarders = slices.Delete(fm.forwarders, idx, idx+1)
+		resCh <- nil
+	}
+	return <-resCh
+}
+
+// stopForwarder stops a forwarder and cancels its running backfill.
+func (fm *Manager) stopForwarder(fw *forwarder) {
+	if b := fm.backfills[fw]; b != nil {
+		b.canceled.Store(true)
+	}
+	fw.stop()
+}
+
+// Target returns the configuration of a target without its secrets.
+func (fm *Manager) Target(id int64) (*TargetInfo, error) {
+	resCh := make(chan *TargetInfo)
+	fm.fns <- func(fm *Manager) {
+		fw := fm.findForwarder(id)
+		if fw == nil {
+			resCh <- nil
+			return
+		}
+		ti := &TargetInfo{
+			ID:            fw.id,
+			Dynamic:       fw.dynamic != nil,
+			Target:        *fw.cfg,
+			HasPassword:   fw.cfg.Password != nil,
+			HasPassphrase: fw.cfg.Passphrase != nil,
+			HasClientCert: fw.cfg.ClientPublicCert != "" ||
+				(fw.dynamic != nil && fw.dynamic.ClientCertPublic != nil),
+		}
+		ti.Target.Password, ti.Target.Passphrase = nil, nil
+		resCh <- ti
+	}
+	if ti := <-resCh; ti != nil {
+		return ti, nil
+	}
+	return nil, ErrNoSuchTarget
+}
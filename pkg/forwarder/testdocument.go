@@ -0,0 +1,69 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package forwarder
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// testDocument returns a minimal synthetic CSAF document in
+// draft status and its filename to be used in test deliveries.
+func testDocument(publisher, namespace string, now time.Time) ([]byte, string, error) {
+	if publisher == "" {
+		publisher = "ISDuBA"
+	}
+	if namespace == "" {
+		namespace = "https://isduba.invalid"
+	}
+	var (
+		date       = now.UTC().Format(time.RFC3339)
+		trackingID = "ISDUBA-TEST-" + strconv.FormatInt(now.Unix(), 10)
+	)
+	type obj = map[string]any
+	doc := obj{
+		"document": obj{
+			"category":     "csaf_base",
+			"csaf_version": "2.0",
+			"lang":         "en",
+			"title":        "ISDuBA test delivery",
+			"distribution": obj{
+				"tlp": obj{"label": "WHITE"},
+			},
+			"notes": []obj{{
+				"category": "general",
+				"text":     "This document was sent to test the delivery. It can be discarded.",
+			}},
+			"publisher": obj{
+				"category":  "other",
+				"name":      publisher,
+				"namespace": namespace,
+			},
+			"tracking": obj{
+				"id":                   trackingID,
+				"current_release_date": date,
+				"initial_release_date": date,
+				"revision_history": []obj{{
+					"date":    date,
+					"number":  "1",
+					"summary": "Test delivery",
+				}},
+				"status":  "draft",
+				"version": "1",
+			},
+		},
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, "", err
+	}
+	return data, strings.ToLower(trackingID) + ".json", nil
+}
@@ -61,6 +61,7 @@ func buildRequest(
 	url string,
 	headers http.Header,
 	documentURL string,
+	banner string,
 ) (*http.Request, error) {
 	body := new(bytes.Buffer)
 	writer := multipart.NewWriter(body)
@@ -90,6 +91,9 @@ func buildRequest(
 	if documentURL != "" {
 		part("document_url", "", "text/plain", documentURL)
 	}
+	if banner != "" {
+		part("banner", "", "text/plain", banner)
+	}
 
 	if err := errors.Join(err, writer.Close()); err != nil {
 		return nil, err
@@ -108,3 +112,56 @@ func buildRequest(
 	req.Header.Set("Content-Type", contentType)
 	return req, nil
 }
+
+// buildProviderRequest builds an upload request for
+// the API of a gocsaf csaf_provider.
+func buildProviderRequest(
+	doc []byte,
+	filename *string,
+	tlp string,
+	passphrase *string,
+	url string,
+	headers http.Header,
+	auth string,
+) (*http.Request, error) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+
+	fn := "document.json"
+	if filename != nil {
+		fn = filepath.Base(*filename)
+	}
+	// The csaf_provider only accepts uploads with mime type "application/json".
+	w, err := createFormFile(writer, "csaf", fn, "application/json")
+	if err == nil {
+		_, err = w.Write(doc)
+	}
+	if tlp == "" {
+		tlp = "csaf"
+	}
+	if err == nil {
+		err = writer.WriteField("tlp", strings.ToLower(tlp))
+	}
+	if err == nil && passphrase != nil {
+		err = writer.WriteField("passphrase", *passphrase)
+	}
+	if err := errors.Join(err, writer.Close()); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(
+		http.MethodPost, strings.TrimSuffix(url, "/")+"/api/upload", body)
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range headers {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	if auth != "" {
+		req.Header.Set("X-CSAF-PROVIDER-AUTH", auth)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req, nil
+}
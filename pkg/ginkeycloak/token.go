@@ -36,6 +36,7 @@ type KeycloakToken struct {
 	FamilyName        string                 `json:"family_name,omitempty"`
 	Email             string                 `json:"email,omitempty"`
 	RealmAccess       ServiceRole            `json:"realm_access,omitempty"`
+	Groups            []string               `json:"groups,omitempty"`
 	CustomClaims      any                    `json:"custom_claims,omitempty"`
 }
 
@@ -55,6 +56,15 @@ func (sr *ServiceRole) ContainsAny(roles []string) bool {
 	return false
 }
 
+// Expires returns the time the token expires.
+// The zero time is returned for tokens which do not expire.
+func (kct *KeycloakToken) Expires() time.Time {
+	if kct.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(kct.Exp, 0)
+}
+
 func (kct *KeycloakToken) isExpired() bool {
 	if kct.Exp == 0 {
 		return false
@@ -0,0 +1,136 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package grouptlps keeps the TLP rules of the Keycloak groups
+// which are administered at runtime in the database.
+package grouptlps
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/database"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// Rules are the TLP rules of the groups. They are reloaded
+// whenever the database reports changes. A nil rules set is valid
+// and grants nothing.
+type Rules struct {
+	db     *database.DB
+	mu     sync.RWMutex
+	groups map[string]models.PublishersTLPs
+	wake   chan struct{}
+}
+
+// NewRules returns a new empty set of group rules.
+func NewRules(db *database.DB) *Rules {
+	return &Rules{
+		db:     db,
+		groups: map[string]models.PublishersTLPs{},
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Run loads the rules and reloads them on changes.
+// To be used in a Go routine.
+func (r *Rules) Run(ctx context.Context) {
+	if r == nil {
+		return
+	}
+	if err := r.db.WaitAvailable(ctx); err != nil {
+		return
+	}
+	r.wakeUp()
+	go r.db.Listen(ctx, database.GroupTLPsChangesChannel, r.wakeUp)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.wake:
+			if err := r.Reload(ctx); err != nil {
+				slog.Error("loading TLP rules of groups failed", "error", err)
+			}
+		}
+	}
+}
+
+// wakeUp requests a reload without blocking.
+func (r *Rules) wakeUp() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Reload loads the rules from the database.
+func (r *Rules) Reload(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+	const selectSQL = `SELECT group_name, publisher, tlps FROM group_tlps`
+	groups := map[string]models.PublishersTLPs{}
+	if err := r.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, selectSQL)
+			defer rows.Close()
+			for rows.Next() {
+				var (
+					group     string
+					publisher models.Publisher
+					tlps      []string
+				)
+				if err := rows.Scan(&group, &publisher, &tlps); err != nil {
+					return err
+				}
+				rules := groups[group]
+				if rules == nil {
+					rules = models.PublishersTLPs{}
+					groups[group] = rules
+				}
+				rules[publisher] = make([]models.TLP, len(tlps))
+				for i, tlp := range tlps {
+					rules[publisher][i] = models.TLP(tlp)
+				}
+			}
+			return rows.Err()
+		}, 0,
+	); err != nil {
+		return err
+	}
+	r.set(groups)
+	return nil
+}
+
+// set replaces the rules of all groups.
+func (r *Rules) set(groups map[string]models.PublishersTLPs) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.groups = groups
+}
+
+// ForGroups returns the merged rules of the given groups.
+// If none of the groups has rules nil is returned.
+func (r *Rules) ForGroups(groups []string) models.PublishersTLPs {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var merged models.PublishersTLPs
+	for _, group := range groups {
+		if rules, ok := r.groups[group]; ok {
+			merged = rules.Merge(merged)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,40 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package grouptlps
+
+import (
+	"testing"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+func TestForGroups(t *testing.T) {
+	r := NewRules(nil)
+	r.set(map[string]models.PublishersTLPs{
+		"cert":        {"*": {models.TLPWhite, models.TLPGreen}},
+		"cert/vendor": {"ACME": {models.TLPAmber}},
+	})
+	for _, x := range []struct {
+		groups   []string
+		expected string
+	}{
+		{nil, `null`},
+		{[]string{"unknown"}, `null`},
+		{[]string{"cert"}, `{"*":["GREEN","WHITE"]}`},
+		{[]string{"cert/vendor"}, `{"ACME":["AMBER"]}`},
+		{[]string{"cert", "cert/vendor", "unknown"}, `{"*":["GREEN","WHITE"],"ACME":["AMBER","GREEN","WHITE"]}`},
+	} {
+		if have := r.ForGroups(x.groups).Rules(); have != x.expected {
+			t.Errorf("%q: have %s expected %s", x.groups, have, x.expected)
+		}
+	}
+	if (*Rules)(nil).ForGroups([]string{"cert"}) != nil {
+		t.Error("nil rules grant access")
+	}
+}
@@ -0,0 +1,498 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package httpcache implements a size bounded HTTP cache shared by
+// the clients fetching provider metadata, keys, feeds and aggregators.
+// The freshness of the entries follows the Cache-Control, Expires and
+// Last-Modified headers of the responses. Stale entries are revalidated
+// with conditional requests.
+package httpcache
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxHeuristicFreshness caps the freshness guessed from Last-Modified.
+const maxHeuristicFreshness = time.Hour
+
+// Stats are the numbers of the cache.
+type Stats struct {
+	Entries int   `json:"entries"`
+	Size    int64 `json:"size"`
+	Limit   int64 `json:"limit"`
+	// Hits are the requests served without contacting the server.
+	Hits int64 `json:"hits"`
+	// Revalidations are the requests served after the server
+	// confirmed that the entry is unchanged.
+	Revalidations int64 `json:"revalidations"`
+	Misses        int64 `json:"misses"`
+	Stores        int64 `json:"stores"`
+	Evictions     int64 `json:"evictions"`
+	// HitRate is the share of the hits and revalidations in all requests.
+	HitRate float64 `json:"hit_rate"`
+}
+
+// Cache is a size bounded HTTP cache. The least recently used
+// entries are evicted first. A nil cache is valid and caches nothing.
+type Cache struct {
+	limit int64
+
+	mu      sync.Mutex
+	size    int64
+	entries map[string]*list.Element
+	lru     list.List
+
+	hits          atomic.Int64
+	revalidations atomic.Int64
+	misses        atomic.Int64
+	stores        atomic.Int64
+	evictions     atomic.Int64
+}
+
+// entry is a cached response. Entries are not modified after
+// they are stored so they can be shared without locking.
+type entry struct {
+	key          string
+	url          string
+	header       http.Header
+	body         []byte
+	stored       time.Time
+	freshness    time.Duration
+	noCache      bool
+	etag         string
+	lastModified string
+}
+
+// New returns a new cache holding up to limit bytes.
+// If limit is not positive nil is returned.
+func New(limit int64) *Cache {
+	if limit <= 0 {
+		return nil
+	}
+	return &Cache{
+		limit:   limit,
+		entries: map[string]*list.Element{},
+	}
+}
+
+// Transport returns a round tripper which answers GET requests from
+// the cache and forwards the others to base. The partition separates
+// the entries of clients with different transport settings like
+// client certificates. Requests with different Authorization headers
+// never share entries.
+func (c *Cache) Transport(base http.RoundTripper, partition string) http.RoundTripper {
+	if c == nil {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{cache: c, base: base, partition: partition}
+}
+
+// Stats returns the current numbers of the cache.
+func (c *Cache) Stats() Stats {
+	if c == nil {
+		return Stats{}
+	}
+	c.mu.Lock()
+	st := Stats{
+		Entries: len(c.entries),
+		Size:    c.size,
+		Limit:   c.limit,
+	}
+	c.mu.Unlock()
+	st.Hits = c.hits.Load()
+	st.Revalidations = c.revalidations.Load()
+	st.Misses = c.misses.Load()
+	st.Stores = c.stores.Load()
+	st.Evictions = c.evictions.Load()
+	if total := st.Hits + st.Revalidations + st.Misses; total > 0 {
+		st.HitRate = float64(st.Hits+st.Revalidations) / float64(total)
+	}
+	return st
+}
+
+// Evict removes the entries of the given URL in all partitions.
+// If url is empty all entries are removed.
+// Returns the number of removed entries.
+func (c *Cache) Evict(url string) int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, el := range c.entries {
+		if e := el.Value.(*entry); url == "" || e.url == url {
+			c.remove(el)
+			n++
+		}
+	}
+	return n
+}
+
+// get returns the entry of the given key and marks it as recently used.
+func (c *Cache) get(key string) *entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el := c.entries[key]
+	if el == nil {
+		return nil
+	}
+	c.lru.MoveToFront(el)
+	return el.Value.(*entry)
+}
+
+// put stores an entry and evicts the least recently used
+// entries if the cache is full.
+func (c *Cache) put(e *entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el := c.entries[e.key]; el != nil {
+		c.remove(el)
+	}
+	c.entries[e.key] = c.lru.PushFront(e)
+	c.size += e.size()
+	c.stores.Add(1)
+	for c.size > c.limit {
+		back := c.lru.Back()
+		if back == nil {
+			break
+		}
+		c.remove(back)
+		c.evictions.Add(1)
+	}
+}
+
+// remove removes an element. Expects the lock to be held.
+func (c *Cache) remove(el *list.Element) {
+	e := c.lru.Remove(el).(*entry)
+	delete(c.entries, e.key)
+	c.size -= e.size()
+}
+
+// maxEntrySize is the largest response body to be cached.
+// A single response must not flush the whole cache.
+func (c *Cache) maxEntrySize() int64 {
+	return c.limit / 4
+}
+
+// size returns the approximated memory used by the entry.
+func (e *entry) size() int64 {
+	n := len(e.key) + len(e.body)
+	for k, vs := range e.header {
+		n += len(k)
+		for _, v := range vs {
+			n += len(v)
+		}
+	}
+	return int64(n)
+}
+
+// isFresh checks if the entry can be served without revalidation.
+func (e *entry) isFresh(now time.Time) bool {
+	return !e.noCache && now.Sub(e.stored) < e.freshness
+}
+
+// hasValidators checks if the entry can be revalidated.
+func (e *entry) hasValidators() bool {
+	return e.etag != "" || e.lastModified != ""
+}
+
+// response creates a response from the entry.
+func (e *entry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+// notModifiedResponse creates a 304 response from the entry.
+func (e *entry) notModifiedResponse(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:     "304 Not Modified",
+		StatusCode: http.StatusNotModified,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     e.header.Clone(),
+		Body:       http.NoBody,
+		Request:    req,
+	}
+}
+
+// notModified checks if the conditional headers of
+// the request are satisfied by the entry.
+func (e *entry) notModified(req *http.Request) bool {
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		if e.etag == "" {
+			return false
+		}
+		for tag := range strings.SplitSeq(inm, ",") {
+			if tag = strings.TrimSpace(tag); tag == "*" || tag == e.etag {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := req.Header.Get("If-Modified-Since"); ims != "" && e.lastModified != "" {
+		since, err1 := http.ParseTime(ims)
+		modified, err2 := http.ParseTime(e.lastModified)
+		return err1 == nil && err2 == nil && !modified.After(since)
+	}
+	return false
+}
+
+// hasConditionals checks if the request has own conditional headers.
+func hasConditionals(req *http.Request) bool {
+	return req.Header.Get("If-None-Match") != "" ||
+		req.Header.Get("If-Modified-Since") != ""
+}
+
+// cacheControl are the directives of a Cache-Control header.
+type cacheControl map[string]string
+
+func parseCacheControl(header http.Header) cacheControl {
+	cc := cacheControl{}
+	for _, line := range header.Values("Cache-Control") {
+		for directive := range strings.SplitSeq(line, ",") {
+			k, v, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+				cc[k] = strings.Trim(strings.TrimSpace(v), `"`)
+			}
+		}
+	}
+	return cc
+}
+
+func (cc cacheControl) has(directive string) bool {
+	_, ok := cc[directive]
+	return ok
+}
+
+// seconds returns the value of a directive in seconds.
+func (cc cacheControl) seconds(directive string) (time.Duration, bool) {
+	v, ok := cc[directive]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+// freshness returns the freshness lifetime of a response.
+func freshness(header http.Header, cc cacheControl, now time.Time) time.Duration {
+	date := now
+	if d, err := http.ParseTime(header.Get("Date")); err == nil {
+		date = d
+	}
+	var lifetime time.Duration
+	if maxAge, ok := cc.seconds("max-age"); ok {
+		lifetime = maxAge
+	} else if expires := header.Get("Expires"); expires != "" {
+		// Invalid dates mean already expired.
+		if exp, err := http.ParseTime(expires); err == nil {
+			lifetime = exp.Sub(date)
+		}
+	} else if lm, err := http.ParseTime(header.Get("Last-Modified")); err == nil {
+		lifetime = min(date.Sub(lm)/10, maxHeuristicFreshness)
+	}
+	if age, err := strconv.ParseInt(header.Get("Age"), 10, 64); err == nil && age > 0 {
+		lifetime -= time.Duration(age) * time.Second
+	}
+	return max(lifetime, 0)
+}
+
+// cacheable checks if the response may be stored.
+func cacheable(header http.Header, cc cacheControl) bool {
+	if cc.has("no-store") {
+		return false
+	}
+	// The requests of the clients only differ in the encoding.
+	for _, vary := range header.Values("Vary") {
+		for field := range strings.SplitSeq(vary, ",") {
+			if f := strings.TrimSpace(field); f != "" && !strings.EqualFold(f, "Accept-Encoding") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// transport is the round tripper of a partition of the cache.
+type transport struct {
+	cache     *Cache
+	base      http.RoundTripper
+	partition string
+}
+
+// key returns the cache key of the request.
+func (t *transport) key(req *http.Request) string {
+	var b strings.Builder
+	b.WriteString(t.partition)
+	b.WriteByte(' ')
+	b.WriteString(req.URL.String())
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		h := sha256.Sum256([]byte(auth))
+		b.WriteByte(' ')
+		b.WriteString(hex.EncodeToString(h[:]))
+	}
+	return b.String()
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.base.RoundTrip(req)
+	}
+	reqCC := parseCacheControl(req.Header)
+	if reqCC.has("no-store") {
+		return t.base.RoundTrip(req)
+	}
+	c := t.cache
+	key := t.key(req)
+	now := time.Now()
+	e := c.get(key)
+	if e != nil && !reqCC.has("no-cache") && e.isFresh(now) {
+		c.hits.Add(1)
+		if hasConditionals(req) && e.notModified(req) {
+			return e.notModifiedResponse(req), nil
+		}
+		return e.response(req), nil
+	}
+	own := hasConditionals(req)
+	out := req
+	if e != nil && !own && e.hasValidators() {
+		out = req.Clone(req.Context())
+		if e.etag != "" {
+			out.Header.Set("If-None-Match", e.etag)
+		}
+		if e.lastModified != "" {
+			out.Header.Set("If-Modified-Since", e.lastModified)
+		}
+	}
+	resp, err := t.base.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		if e == nil || (own && resp.Header.Get("Etag") != e.etag) {
+			c.misses.Add(1)
+			return resp, nil
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		c.revalidations.Add(1)
+		e = t.revalidated(e, resp.Header, now)
+		if own {
+			return e.notModifiedResponse(req), nil
+		}
+		return e.response(req), nil
+	case http.StatusOK:
+		c.misses.Add(1)
+		return t.store(key, req, resp, now)
+	default:
+		c.misses.Add(1)
+		return resp, nil
+	}
+}
+
+// CloseIdleConnections closes the idle connections of the base transport.
+func (t *transport) CloseIdleConnections() {
+	if ci, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		ci.CloseIdleConnections()
+	}
+}
+
+// revalidated stores a copy of the entry with the
+// headers updated by a 304 response.
+func (t *transport) revalidated(e *entry, header http.Header, now time.Time) *entry {
+	updated := *e
+	updated.header = e.header.Clone()
+	for k, vs := range header {
+		updated.header[k] = vs
+	}
+	cc := parseCacheControl(updated.header)
+	updated.stored = now
+	updated.freshness = freshness(updated.header, cc, now)
+	updated.noCache = cc.has("no-cache")
+	updated.etag = updated.header.Get("Etag")
+	updated.lastModified = updated.header.Get("Last-Modified")
+	t.cache.put(&updated)
+	return &updated
+}
+
+// store reads the body of the response and stores it if it is cacheable.
+func (t *transport) store(
+	key string,
+	req *http.Request,
+	resp *http.Response,
+	now time.Time,
+) (*http.Response, error) {
+	cc := parseCacheControl(resp.Header)
+	if !cacheable(resp.Header, cc) {
+		return resp, nil
+	}
+	e := &entry{
+		key:          key,
+		url:          req.URL.String(),
+		header:       resp.Header.Clone(),
+		stored:       now,
+		freshness:    freshness(resp.Header, cc, now),
+		noCache:      cc.has("no-cache"),
+		etag:         resp.Header.Get("Etag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}
+	// Entries which are neither fresh nor can be revalidated are useless.
+	if !e.isFresh(now) && !e.hasValidators() {
+		return resp, nil
+	}
+	maxSize := t.cache.maxEntrySize()
+	if resp.ContentLength > maxSize {
+		return resp, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if int64(len(body)) > maxSize {
+		// Too large. Hand out what was read and the rest.
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	e.body = body
+	t.cache.put(e)
+	return e.response(req), nil
+}
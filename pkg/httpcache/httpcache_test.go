@@ -0,0 +1,170 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func get(t *testing.T, client *http.Client, url string, header ...string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestCache(t *testing.T) {
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/etag":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("Etag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/nostore":
+			w.Header().Set("Cache-Control", "no-store, max-age=60")
+		case "/large":
+			w.Header().Set("Cache-Control", "max-age=60")
+			io.WriteString(w, strings.Repeat("x", 1000))
+			return
+		}
+		io.WriteString(w, r.URL.Path)
+	}))
+	defer srv.Close()
+
+	cache := New(1024)
+	client := &http.Client{Transport: cache.Transport(http.DefaultTransport, "")}
+
+	check := func(path string, wantRequests int64, header ...string) {
+		t.Helper()
+		before := requests.Load()
+		status, body := get(t, client, srv.URL+path, header...)
+		if status != http.StatusOK || body != path {
+			t.Errorf("%s: got %d %q", path, status, body)
+		}
+		if got := requests.Load() - before; got != wantRequests {
+			t.Errorf("%s: got %d upstream requests, want %d", path, got, wantRequests)
+		}
+	}
+
+	check("/fresh", 1)
+	check("/fresh", 0)
+	// Different credentials do not share entries.
+	check("/fresh", 1, "Authorization", "Basic eDp5")
+
+	check("/etag", 1)
+	check("/etag", 1) // revalidated
+
+	check("/nostore", 1)
+	check("/nostore", 1)
+
+	// Too large for the cache.
+	for range 2 {
+		before := requests.Load()
+		if _, body := get(t, client, srv.URL+"/large"); len(body) != 1000 {
+			t.Errorf("/large: got %d bytes", len(body))
+		}
+		if requests.Load()-before != 1 {
+			t.Error("/large: expected upstream request")
+		}
+	}
+
+	// A fresh entry answers the conditional requests of the caller.
+	client.Transport = cache.Transport(http.DefaultTransport, "other")
+	get(t, client, srv.URL+"/fresh")
+	before := requests.Load()
+	status, _ := get(t, client, srv.URL+"/fresh", "If-Modified-Since", time.Now().Format(http.TimeFormat))
+	if status != http.StatusOK || requests.Load() != before {
+		t.Errorf("fresh without validators: got %d", status)
+	}
+
+	st := cache.Stats()
+	if st.Hits != 2 || st.Revalidations != 1 {
+		t.Errorf("stats: got %+v", st)
+	}
+	if n := cache.Evict(srv.URL + "/fresh"); n != 3 {
+		t.Errorf("evicted %d entries, want 3", n)
+	}
+	if cache.Evict("") != 1 || cache.Stats().Size != 0 {
+		t.Errorf("cache not empty: %+v", cache.Stats())
+	}
+}
+
+func TestEviction(t *testing.T) {
+	cache := New(1000)
+	for _, key := range []string{"a", "b", "c"} {
+		cache.put(&entry{key: key, url: key, body: make([]byte, 400)})
+	}
+	if cache.get("a") != nil {
+		t.Error("least recently used entry not evicted")
+	}
+	cache.get("b")
+	cache.put(&entry{key: "d", url: "d", body: make([]byte, 400)})
+	if cache.get("b") == nil || cache.get("c") != nil {
+		t.Error("recently used entry evicted")
+	}
+	if st := cache.Stats(); st.Evictions != 2 || st.Entries != 2 {
+		t.Errorf("stats: got %+v", st)
+	}
+}
+
+func TestFreshness(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		header http.Header
+		want   time.Duration
+	}{
+		{http.Header{"Cache-Control": {"max-age=120"}}, 2 * time.Minute},
+		{http.Header{"Cache-Control": {"max-age=120"}, "Age": {"20"}}, 100 * time.Second},
+		{http.Header{
+			"Date":    {now.Format(http.TimeFormat)},
+			"Expires": {now.Add(time.Hour).Format(http.TimeFormat)},
+		}, time.Hour},
+		{http.Header{"Expires": {"0"}}, 0},
+		{http.Header{
+			"Date":          {now.Format(http.TimeFormat)},
+			"Last-Modified": {now.Add(-10 * time.Minute).Format(http.TimeFormat)},
+		}, time.Minute},
+		{http.Header{
+			"Date":          {now.Format(http.TimeFormat)},
+			"Last-Modified": {now.Add(-100 * time.Hour).Format(http.TimeFormat)},
+		}, maxHeuristicFreshness},
+		{http.Header{}, 0},
+	} {
+		if got := freshness(tc.header, parseCacheControl(tc.header), now); got != tc.want {
+			t.Errorf("%v: got %v, want %v", tc.header, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package logbuffer keeps the most recent log records in memory
+// so they can be handed out e.g. in support bundles.
+package logbuffer
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSize is the number of records kept by [Recent].
+const DefaultSize = 500
+
+// Recent keeps the most recent warnings and errors of the default logger.
+var Recent = NewRing(DefaultSize)
+
+// Entry is a recorded log record.
+type Entry struct {
+	Time    time.Time         `json:"time"`
+	Level   string            `json:"level"`
+	Message string            `json:"msg"`
+	Attrs   map[string]string `json:"attrs,omitempty"`
+}
+
+// Ring is a fixed size buffer of log entries.
+// The oldest entries are overwritten first.
+type Ring struct {
+	mu      sync.Mutex
+	entries []Entry
+	next    int
+	full    bool
+}
+
+// NewRing returns a ring keeping the given number of entries.
+func NewRing(size int) *Ring {
+	return &Ring{entries: make([]Entry, max(1, size))}
+}
+
+// Add records an entry.
+func (r *Ring) Add(e Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[r.next] = e
+	if r.next++; r.next == len(r.entries) {
+		r.next, r.full = 0, true
+	}
+}
+
+// Entries returns the recorded entries, the oldest first.
+func (r *Ring) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]Entry(nil), r.entries[:r.next]...)
+	}
+	entries := make([]Entry, 0, len(r.entries))
+	entries = append(entries, r.entries[r.next:]...)
+	return append(entries, r.entries[:r.next]...)
+}
+
+// Handler is a [slog.Handler] which records the records of
+// at least a minimum level in a ring before passing them on.
+type Handler struct {
+	next   slog.Handler
+	ring   *Ring
+	level  slog.Level
+	attrs  []slog.Attr
+	prefix string
+}
+
+// NewHandler returns a handler recording the records of
+// at least the given level in the ring.
+func NewHandler(next slog.Handler, ring *Ring, level slog.Level) *Handler {
+	return &Handler{next: next, ring: ring, level: level}
+}
+
+// Enabled implements [slog.Handler].
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements [slog.Handler].
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= h.level {
+		e := Entry{
+			Time:    r.Time.UTC(),
+			Level:   r.Level.String(),
+			Message: r.Message,
+		}
+		add := func(prefix string, a slog.Attr) bool {
+			if e.Attrs == nil {
+				e.Attrs = map[string]string{}
+			}
+			flatten(e.Attrs, prefix, a)
+			return true
+		}
+		for _, a := range h.attrs {
+			add("", a)
+		}
+		r.Attrs(func(a slog.Attr) bool { return add(h.prefix, a) })
+		h.ring.Add(e)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// flatten stores the attribute with its groups joined by dots.
+func flatten(attrs map[string]string, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	key := prefix + a.Key
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			key += "."
+		}
+		for _, ga := range a.Value.Group() {
+			flatten(attrs, key, ga)
+		}
+		return
+	}
+	attrs[key] = a.Value.String()
+}
+
+// WithAttrs implements [slog.Handler].
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	nh := *h
+	nh.next = h.next.WithAttrs(attrs)
+	nh.attrs = make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	nh.attrs = append(nh.attrs, h.attrs...)
+	for _, a := range attrs {
+		if h.prefix != "" {
+			a = slog.Group(strings.TrimSuffix(h.prefix, "."), a)
+		}
+		nh.attrs = append(nh.attrs, a)
+	}
+	return &nh
+}
+
+// WithGroup implements [slog.Handler].
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	nh := *h
+	nh.next = h.next.WithGroup(name)
+	nh.prefix = h.prefix + name + "."
+	return &nh
+}
@@ -0,0 +1,57 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package logbuffer
+
+import (
+	"io"
+	"log/slog"
+	"maps"
+	"strconv"
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	r := NewRing(3)
+	if n := len(r.Entries()); n != 0 {
+		t.Fatalf("empty ring has %d entries", n)
+	}
+	for i := range 5 {
+		r.Add(Entry{Message: strconv.Itoa(i)})
+	}
+	var got string
+	for _, e := range r.Entries() {
+		got += e.Message
+	}
+	if got != "234" {
+		t.Errorf("got %q, want %q", got, "234")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRing(10)
+	next := slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug})
+	logger := slog.New(NewHandler(next, r, slog.LevelWarn))
+
+	logger.Info("ignored")
+	logger.With("a", 1).WithGroup("g").With("b", 2).Error("failed", "c", "x",
+		slog.Group("h", "d", true))
+
+	entries := r.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Level != "ERROR" || e.Message != "failed" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	want := map[string]string{"a": "1", "g.b": "2", "g.c": "x", "g.h.d": "true"}
+	if !maps.Equal(e.Attrs, want) {
+		t.Errorf("got attrs %v, want %v", e.Attrs, want)
+	}
+}
@@ -0,0 +1,53 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import "strings"
+
+// DistributionBanner returns the banner configured for a TLP label.
+// The labels are compared case-insensitively and the TLP v2 label
+// CLEAR and the TLP v1 label WHITE stand in for each other.
+func DistributionBanner(banners map[string]string, tlp string) string {
+	if len(banners) == 0 || tlp == "" {
+		return ""
+	}
+	lookup := func(label string) (string, bool) {
+		for k, v := range banners {
+			if strings.EqualFold(k, label) {
+				return v, true
+			}
+		}
+		return "", false
+	}
+	if banner, ok := lookup(tlp); ok {
+		return banner
+	}
+	switch strings.ToUpper(tlp) {
+	case "CLEAR":
+		banner, _ := lookup(string(TLPWhite))
+		return banner
+	case string(TLPWhite):
+		banner, _ := lookup("CLEAR")
+		return banner
+	}
+	return ""
+}
+
+// WithBanner puts the banner in front of the text and the
+// provenance note after it separated by empty lines.
+// Empty parts are left out.
+func WithBanner(text, banner, provenance string) string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{banner, text, provenance} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
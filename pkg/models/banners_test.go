@@ -0,0 +1,56 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import "testing"
+
+func TestDistributionBanner(t *testing.T) {
+	banners := map[string]string{
+		"WHITE": "public",
+		"amber": "limited",
+	}
+	for _, x := range []struct {
+		tlp  string
+		want string
+	}{
+		{"WHITE", "public"},
+		{"CLEAR", "public"},
+		{"AMBER", "limited"},
+		{"Amber", "limited"},
+		{"RED", ""},
+		{"", ""},
+	} {
+		if got := DistributionBanner(banners, x.tlp); got != x.want {
+			t.Errorf("%q: got %q, want %q", x.tlp, got, x.want)
+		}
+	}
+	if got := DistributionBanner(map[string]string{"CLEAR": "clear"}, "WHITE"); got != "clear" {
+		t.Errorf("WHITE: got %q, want %q", got, "clear")
+	}
+	if got := DistributionBanner(nil, "RED"); got != "" {
+		t.Errorf("no banners: got %q", got)
+	}
+}
+
+func TestWithBanner(t *testing.T) {
+	for _, x := range []struct {
+		text, banner, provenance string
+		want                     string
+	}{
+		{"text", "", "", "text"},
+		{"text", "TLP:RED", "", "TLP:RED\n\ntext"},
+		{"text\n", "TLP:RED\n", " from ISDuBA ", "TLP:RED\n\ntext\n\nfrom ISDuBA"},
+		{"", "TLP:RED", "from ISDuBA", "TLP:RED\n\nfrom ISDuBA"},
+	} {
+		if got := WithBanner(x.text, x.banner, x.provenance); got != x.want {
+			t.Errorf("%q/%q/%q: got %q, want %q",
+				x.text, x.banner, x.provenance, got, x.want)
+		}
+	}
+}
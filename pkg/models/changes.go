@@ -0,0 +1,72 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DocumentChange is an import, update or deletion of a document.
+type DocumentChange struct {
+	Cursor     ChangeCursor `json:"cursor" swaggertype:"string"`
+	DocumentID int64        `json:"document_id"`
+	Kind       string       `json:"kind"`
+	Changed    time.Time    `json:"changed"`
+	Publisher  string       `json:"publisher"`
+	TrackingID string       `json:"tracking_id"`
+	Version    string       `json:"version"`
+	TLP        *string      `json:"tlp,omitempty"`
+}
+
+// ChangeCursor is a position in the stream of the document changes.
+// The zero value is the position before the first change.
+type ChangeCursor struct {
+	// XID is the id of the transaction of the change.
+	XID uint64
+	// ID is the id of the change.
+	ID int64
+}
+
+// ParseChangeCursor parses a cursor of the form "xid.id".
+// An empty string is the zero cursor.
+func ParseChangeCursor(s string) (ChangeCursor, error) {
+	if s == "" {
+		return ChangeCursor{}, nil
+	}
+	xid, id, ok := strings.Cut(s, ".")
+	if !ok {
+		return ChangeCursor{}, errors.New("cursor has no separator")
+	}
+	x, err := strconv.ParseUint(xid, 10, 64)
+	if err != nil {
+		return ChangeCursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	i, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return ChangeCursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if i < 0 {
+		return ChangeCursor{}, errors.New("invalid cursor: negative id")
+	}
+	return ChangeCursor{XID: x, ID: i}, nil
+}
+
+// String implements [fmt.Stringer].
+func (cc ChangeCursor) String() string {
+	return strconv.FormatUint(cc.XID, 10) + "." + strconv.FormatInt(cc.ID, 10)
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (cc ChangeCursor) MarshalText() ([]byte, error) {
+	return []byte(cc.String()), nil
+}
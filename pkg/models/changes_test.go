@@ -0,0 +1,46 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import "testing"
+
+func TestParseChangeCursor(t *testing.T) {
+	for _, x := range []struct {
+		input string
+		want  ChangeCursor
+		fail  bool
+	}{
+		{"", ChangeCursor{}, false},
+		{"0.0", ChangeCursor{}, false},
+		{"1234.56", ChangeCursor{XID: 1234, ID: 56}, false},
+		{"18446744073709551615.1", ChangeCursor{XID: 18446744073709551615, ID: 1}, false},
+		{"1234", ChangeCursor{}, true},
+		{"a.1", ChangeCursor{}, true},
+		{"1.b", ChangeCursor{}, true},
+		{"1.-1", ChangeCursor{}, true},
+	} {
+		got, err := ParseChangeCursor(x.input)
+		if x.fail {
+			if err == nil {
+				t.Errorf("%q: expected error", x.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", x.input, err)
+			continue
+		}
+		if got != x.want {
+			t.Errorf("%q: got %+v, want %+v", x.input, got, x.want)
+		}
+		if x.input != "" && got.String() != x.input {
+			t.Errorf("%q: round trip gave %q", x.input, got.String())
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// ConfigSnapshotVersion is the version of the format of the configuration snapshots.
+const ConfigSnapshotVersion = 1
+
+// ConfigSnapshot is a normalized snapshot of the runtime relevant
+// configuration of an instance. The entries are flat paths like
+// "sources/<name>/rate" mapped to their JSON encoded values.
+// Instance specific values like database ids and secrets are not recorded.
+type ConfigSnapshot struct {
+	Version int               `json:"version"`
+	Hash    string            `json:"hash"`
+	Entries map[string]string `json:"entries"`
+}
+
+// ConfigDifference is a configuration entry which differs
+// between two snapshots. A missing value is nil.
+type ConfigDifference struct {
+	Key    string  `json:"key"`
+	Local  *string `json:"local,omitempty"`
+	Remote *string `json:"remote,omitempty"`
+}
+
+// NewConfigSnapshot returns an empty configuration snapshot.
+func NewConfigSnapshot() *ConfigSnapshot {
+	return &ConfigSnapshot{
+		Version: ConfigSnapshotVersion,
+		Entries: map[string]string{},
+	}
+}
+
+// Set records the value for the given key. Durations are
+// stored in their textual form, everything else JSON encoded.
+func (cs *ConfigSnapshot) Set(key string, value any) {
+	switch v := value.(type) {
+	case time.Duration:
+		value = v.String()
+	case *time.Duration:
+		if v != nil {
+			value = v.String()
+		}
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		data = []byte(fmt.Sprintf("%q", fmt.Sprint(value)))
+	}
+	cs.Entries[key] = string(data)
+}
+
+// ComputeHash returns the SHA256 of the sorted entries as a hex string.
+func (cs *ConfigSnapshot) ComputeHash() string {
+	h := sha256.New()
+	for _, key := range slices.Sorted(maps.Keys(cs.Entries)) {
+		fmt.Fprintf(h, "%s\x00%s\n", key, cs.Entries[key])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Seal stores the hash of the entries in the snapshot.
+func (cs *ConfigSnapshot) Seal() {
+	cs.Hash = cs.ComputeHash()
+}
+
+// Diff returns the entries which differ between the snapshot
+// and the other one ordered by key.
+func (cs *ConfigSnapshot) Diff(other *ConfigSnapshot) []ConfigDifference {
+	keys := slices.Collect(maps.Keys(cs.Entries))
+	for key := range other.Entries {
+		if _, ok := cs.Entries[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	diffs := []ConfigDifference{}
+	for _, key := range keys {
+		local, lok := cs.Entries[key]
+		remote, rok := other.Entries[key]
+		if lok && rok && local == remote {
+			continue
+		}
+		diff := ConfigDifference{Key: key}
+		if lok {
+			diff.Local = &local
+		}
+		if rok {
+			diff.Remote = &remote
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
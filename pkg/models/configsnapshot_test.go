@@ -0,0 +1,64 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfigSnapshot(t *testing.T) {
+	age := 2 * time.Hour
+	a := NewConfigSnapshot()
+	a.Set("sources/a/rate", 1.5)
+	a.Set("sources/a/age", &age)
+	a.Set("sources/a/slots", (*int)(nil))
+	a.Set("forwarder/update_interval", 5*time.Minute)
+
+	if got := a.Entries["sources/a/age"]; got != `"2h0m0s"` {
+		t.Errorf("duration pointer: got %s", got)
+	}
+	if got := a.Entries["sources/a/slots"]; got != "null" {
+		t.Errorf("nil pointer: got %s", got)
+	}
+
+	b := NewConfigSnapshot()
+	b.Set("forwarder/update_interval", 5*time.Minute)
+	b.Set("sources/a/slots", (*int)(nil))
+	b.Set("sources/a/age", &age)
+	b.Set("sources/a/rate", 1.5)
+	a.Seal()
+	b.Seal()
+	if a.Hash != b.Hash {
+		t.Errorf("hashes differ for equal entries: %s != %s", a.Hash, b.Hash)
+	}
+	if diffs := a.Diff(b); len(diffs) != 0 {
+		t.Errorf("equal snapshots: got %v", diffs)
+	}
+
+	b.Set("sources/a/rate", 2)
+	b.Set("sources/b/rate", 1)
+	delete(b.Entries, "sources/a/age")
+	if b.ComputeHash() == a.Hash {
+		t.Error("hash did not change")
+	}
+	diffs := a.Diff(b)
+	if len(diffs) != 3 {
+		t.Fatalf("got %d differences, want 3: %v", len(diffs), diffs)
+	}
+	if d := diffs[0]; d.Key != "sources/a/age" || d.Local == nil || d.Remote != nil {
+		t.Errorf("removed entry: got %+v", d)
+	}
+	if d := diffs[1]; d.Key != "sources/a/rate" || *d.Local != "1.5" || *d.Remote != "2" {
+		t.Errorf("changed entry: got %+v", d)
+	}
+	if d := diffs[2]; d.Key != "sources/b/rate" || d.Local != nil || *d.Remote != "1" {
+		t.Errorf("added entry: got %+v", d)
+	}
+}
@@ -0,0 +1,81 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// TestDeliveryHeader marks the requests of test deliveries
+// so that the receivers can tell them apart from real ones.
+const TestDeliveryHeader = "X-ISDuBA-Test"
+
+// maxDeliveryResponse limits the length of the recorded response body.
+const maxDeliveryResponse = 4096
+
+// DeliveryResult is the round-trip result of a test delivery
+// to a forwarder target or a webhook.
+type DeliveryResult struct {
+	URL        string        `json:"url"`
+	Delivered  bool          `json:"delivered"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Status     string        `json:"status,omitempty"`
+	Duration   time.Duration `json:"duration" swaggertype:"integer"`
+	Response   string        `json:"response,omitempty"`
+	Truncated  bool          `json:"truncated,omitempty"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// NewDeliveryResult records the outcome of a delivery started at start.
+// The delivery succeeded if the request did not fail and accepted
+// returns true for the status code of the response.
+// The body of the response is recorded up to a limit and closed.
+func NewDeliveryResult(
+	url string,
+	start time.Time,
+	res *http.Response,
+	err error,
+	accepted func(int) bool,
+) *DeliveryResult {
+	dr := &DeliveryResult{
+		URL:      url,
+		Duration: time.Since(start),
+	}
+	if err != nil {
+		dr.Error = err.Error()
+		return dr
+	}
+	defer res.Body.Close()
+	dr.StatusCode = res.StatusCode
+	dr.Status = res.Status
+	dr.Delivered = accepted(res.StatusCode)
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxDeliveryResponse+1))
+	if len(body) > maxDeliveryResponse {
+		body, dr.Truncated = body[:maxDeliveryResponse], true
+	}
+	dr.Response = string(body)
+	if err != nil {
+		dr.Error = "reading response failed: " + err.Error()
+	}
+	return dr
+}
+
+// Redacted returns only if and with which status code the delivery
+// was accepted. The response, the error and the duration are left out
+// as they reveal details about the target to users who are not
+// allowed to see them.
+func (dr *DeliveryResult) Redacted() *DeliveryResult {
+	return &DeliveryResult{
+		URL:        dr.URL,
+		Delivered:  dr.Delivered,
+		StatusCode: dr.StatusCode,
+	}
+}
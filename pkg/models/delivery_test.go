@@ -0,0 +1,55 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewDeliveryResult(t *testing.T) {
+	created := func(code int) bool { return code == http.StatusCreated }
+	response := func(code int, body string) *http.Response {
+		rec := httptest.NewRecorder()
+		rec.WriteHeader(code)
+		rec.WriteString(body)
+		return rec.Result()
+	}
+	start := time.Now()
+
+	dr := NewDeliveryResult("u", start, nil, errors.New("refused"), created)
+	if dr.Delivered || dr.Error != "refused" || dr.StatusCode != 0 {
+		t.Errorf("failed request: got %+v", dr)
+	}
+
+	dr = NewDeliveryResult("u", start, response(http.StatusCreated, "ok"), nil, created)
+	if !dr.Delivered || dr.StatusCode != http.StatusCreated || dr.Response != "ok" || dr.Truncated {
+		t.Errorf("accepted: got %+v", dr)
+	}
+
+	dr = NewDeliveryResult("u", start, response(http.StatusOK, "ok"), nil, created)
+	if dr.Delivered || dr.Error != "" {
+		t.Errorf("not accepted: got %+v", dr)
+	}
+
+	long := strings.Repeat("x", maxDeliveryResponse+10)
+	dr = NewDeliveryResult("u", start, response(http.StatusCreated, long), nil, created)
+	if !dr.Truncated || len(dr.Response) != maxDeliveryResponse {
+		t.Errorf("truncated: got %d bytes, truncated %t", len(dr.Response), dr.Truncated)
+	}
+
+	if r := dr.Redacted(); r.Response != "" || r.Truncated || r.Duration != 0 ||
+		!r.Delivered || r.StatusCode != http.StatusCreated {
+		t.Errorf("redacted: got %+v", r)
+	}
+}
@@ -8,6 +8,8 @@
 
 package models
 
+import "fmt"
+
 // Event is an event in the event log.
 type Event string
 
@@ -22,4 +24,43 @@ const (
 	AddCommentEvent     Event = "add_comment"     // AddCommentEvent represents the addition of a comment.
 	ChangeCommentEvent  Event = "change_comment"  // ChangeCommentEvent represents the change of a comment.
 	DeleteCommentEvent  Event = "delete_comment"  // DeleteCommentEvent represents the deletion of a comment.
+
+	RequestStateChangeEvent Event = "request_state_change" // RequestStateChangeEvent represents a state change waiting for approval.
+	ApproveStateChangeEvent Event = "approve_state_change" // ApproveStateChangeEvent represents the approval of a state change.
+	RejectStateChangeEvent  Event = "reject_state_change"  // RejectStateChangeEvent represents the rejection of a state change.
+
+	ShareDocumentEvent Event = "share_document" // ShareDocumentEvent represents the creation of a shared link.
+	RevokeShareEvent   Event = "revoke_share"   // RevokeShareEvent represents the revocation of a shared link.
+	AccessShareEvent   Event = "access_share"   // AccessShareEvent represents an access by a shared link.
+
+	LegalHoldEvent        Event = "legal_hold"         // LegalHoldEvent represents the creation of a legal hold.
+	ReleaseLegalHoldEvent Event = "release_legal_hold" // ReleaseLegalHoldEvent represents the release of a legal hold.
+
+	SLAEscalationEvent Event = "sla_escalation" // SLAEscalationEvent represents an advisory staying too long in a state.
 )
+
+// Valid returns true if the event is a known event type.
+func (e Event) Valid() bool {
+	switch e {
+	case ImportDocumentEvent, DeleteDocumentEvent, StateChangeEvent,
+		AddSSVCEvent, ChangeSSVCEvent, DeleteSSVCEvent,
+		AddCommentEvent, ChangeCommentEvent, DeleteCommentEvent,
+		RequestStateChangeEvent, ApproveStateChangeEvent, RejectStateChangeEvent,
+		ShareDocumentEvent, RevokeShareEvent, AccessShareEvent,
+		LegalHoldEvent, ReleaseLegalHoldEvent,
+		SLAEscalationEvent:
+		return true
+	default:
+		return false
+	}
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (e *Event) UnmarshalText(text []byte) error {
+	x := Event(text)
+	if !x.Valid() {
+		return fmt.Errorf("%q is not a valid event type", text)
+	}
+	*e = x
+	return nil
+}
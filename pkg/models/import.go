@@ -320,48 +320,16 @@ func ImportDocumentData(
 	dry bool,
 ) (int64, error) {
 
+	di, err := indexDocument(document)
+	if err != nil {
+		return 0, err
+	}
 	var (
-		tlp, tlpOk               = "", false
-		publisher, publisherOK   = "", false
-		trackingID, trackingIDOK = "", false
+		tlp        = di.tlp
+		publisher  = di.publisher
+		trackingID = di.trackingID
 	)
 
-	idxer := newIndexer[string]()
-
-	var bad []string
-	var reps []replacer
-
-	transformJSON(document, chainReplacers(
-		append(reps,
-			badStrings(&bad),
-			storer(&tlp, &tlpOk, "document", "distribution", "tlp", "label"),
-			storer(&publisher, &publisherOK, "document", "publisher", "name"),
-			storer(&trackingID, &trackingIDOK, "document", "tracking", "id"),
-			keepAndIndex(idxer.index, "document", "publisher", "name"),
-			keepAndIndex(idxer.index, "document", "title"),
-			keepAndIndexSuffix(idxer.index, "vulnerabilities", "cve"),
-			keepByKeys(excludeKeys),
-			keepByValues(excludeValues),
-			replaceByIndex(idxer.index),
-		)...))
-
-	// Check if there where some string decoding errors.
-	if len(bad) > 0 {
-		return 0, fmt.Errorf("invalid strings found: %+v", bad)
-	}
-
-	if !publisherOK {
-		return 0, errors.New("missing /document/publisher/name")
-	}
-
-	if !trackingIDOK {
-		return 0, errors.New("missing /document/tracking/id")
-	}
-
-	if !tlpOk {
-		return 0, errors.New("missing /document/distribution/tlp/label")
-	}
-
 	if pstlps != nil && !pstlps.Allowed(publisher, TLP(tlp)) {
 		return 0, ErrNotAllowed
 	}
@@ -390,13 +358,6 @@ func ImportDocumentData(
 		releaseSavepointDoc  = `RELEASE SAVEPOINT insert_document`
 		insertDoc            = `INSERT INTO documents (document, original, advisories_id) VALUES ($1, $2, $3) RETURNING id`
 		insertLog            = `INSERT INTO events_log (event, state, actor, documents_id) VALUES ('import_document', 'new', $1, $2)`
-		queryText            = `SELECT id FROM unique_texts WHERE txt = $1`
-		insertText           = `INSERT INTO unique_texts (txt) VALUES ($1) RETURNING id`
-		insertDocText        = `INSERT INTO documents_texts (documents_id, num, txt_id) VALUES ($1, $2, $3)`
-		loadTexts            = `SELECT u.id, txt FROM documents d JOIN documents_texts t ` +
-			`ON d.id = t.documents_id JOIN unique_texts u ` +
-			`ON t.txt_id = u.id ` +
-			`WHERE d.advisories_id = $1`
 	)
 
 	// We need an advisory before we insert a document.
@@ -460,7 +421,94 @@ func ImportDocumentData(
 		return 0, fmt.Errorf("inserting log failed: %w", err)
 	}
 
-	txtIDs := make([]int64, len(idxer.elements))
+	if err := storeDocumentTexts(ctx, tx, id, advisoryID, di.texts); err != nil {
+		return 0, err
+	}
+
+	if inTx != nil {
+		if err := inTx(ctx, tx, id, false); err != nil {
+			return 0, fmt.Errorf("in transaction failed: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commiting transaction failed: %w", err)
+	}
+	return id, nil
+}
+
+// documentIndex is the result of replacing the strings of
+// a document by the indices of its texts.
+type documentIndex struct {
+	texts      *indexer[string]
+	tlp        string
+	publisher  string
+	trackingID string
+}
+
+// indexDocument replaces the strings of the document in place by the
+// indices of its texts. Strings which are queried directly are kept.
+func indexDocument(document any) (*documentIndex, error) {
+	var (
+		tlpOk, publisherOK, trackingIDOK bool
+
+		di   = documentIndex{texts: newIndexer[string]()}
+		bad  []string
+		reps []replacer
+	)
+
+	transformJSON(document, chainReplacers(
+		append(reps,
+			badStrings(&bad),
+			storer(&di.tlp, &tlpOk, "document", "distribution", "tlp", "label"),
+			storer(&di.publisher, &publisherOK, "document", "publisher", "name"),
+			storer(&di.trackingID, &trackingIDOK, "document", "tracking", "id"),
+			keepAndIndex(di.texts.index, "document", "publisher", "name"),
+			keepAndIndex(di.texts.index, "document", "title"),
+			keepAndIndexSuffix(di.texts.index, "vulnerabilities", "cve"),
+			keepByKeys(excludeKeys),
+			keepByValues(excludeValues),
+			replaceByIndex(di.texts.index),
+		)...))
+
+	// Check if there where some string decoding errors.
+	if len(bad) > 0 {
+		return nil, fmt.Errorf("invalid strings found: %+v", bad)
+	}
+
+	if !publisherOK {
+		return nil, errors.New("missing /document/publisher/name")
+	}
+
+	if !trackingIDOK {
+		return nil, errors.New("missing /document/tracking/id")
+	}
+
+	if !tlpOk {
+		return nil, errors.New("missing /document/distribution/tlp/label")
+	}
+	return &di, nil
+}
+
+// storeDocumentTexts stores the texts of a document.
+// The texts are numbered by their indices in the document.
+func storeDocumentTexts(
+	ctx context.Context,
+	tx pgx.Tx,
+	id, advisoryID int64,
+	texts *indexer[string],
+) error {
+	const (
+		queryText     = `SELECT id FROM unique_texts WHERE txt = $1`
+		insertText    = `INSERT INTO unique_texts (txt) VALUES ($1) RETURNING id`
+		insertDocText = `INSERT INTO documents_texts (documents_id, num, txt_id) VALUES ($1, $2, $3)`
+		loadTexts     = `SELECT u.id, txt FROM documents d JOIN documents_texts t ` +
+			`ON d.id = t.documents_id JOIN unique_texts u ` +
+			`ON t.txt_id = u.id ` +
+			`WHERE d.advisories_id = $1`
+	)
+
+	txtIDs := make([]int64, len(texts.elements))
 	for i := range txtIDs {
 		txtIDs[i] = -1
 	}
@@ -479,13 +527,13 @@ func ImportDocumentData(
 			if err := rows.Scan(&textID, &text); err != nil {
 				return err
 			}
-			if idx, ok := idxer.indexToElements[text]; ok {
+			if idx, ok := texts.indexToElements[text]; ok {
 				txtIDs[idx] = textID
 			}
 		}
 		return rows.Err()
 	}(); err != nil {
-		return 0, fmt.Errorf("loading old texts failed: %w", err)
+		return fmt.Errorf("loading old texts failed: %w", err)
 	}
 
 	insertTextBatch := &pgx.Batch{}
@@ -496,14 +544,14 @@ func ImportDocumentData(
 				if !errors.Is(err, pgx.ErrNoRows) {
 					return fmt.Errorf("finding unique text failed: %w", err)
 				}
-				insertTextBatch.Queue(insertText, idxer.elements[idx]).QueryRow(
+				insertTextBatch.Queue(insertText, texts.elements[idx]).QueryRow(
 					func(row pgx.Row) error { return row.Scan(&txtIDs[idx]) })
 			}
 			return nil
 		}
 	}
 	textIDsBatch := &pgx.Batch{}
-	for i, txt := range idxer.elements {
+	for i, txt := range texts.elements {
 		if txtIDs[i] == -1 {
 			// Only ask for strings we have not found already.
 			textIDsBatch.Queue(queryText, txt).QueryRow(scanText(i))
@@ -511,13 +559,13 @@ func ImportDocumentData(
 	}
 
 	if err := tx.SendBatch(ctx, textIDsBatch).Close(); err != nil {
-		return 0, fmt.Errorf("finding txt failed: %w", err)
+		return fmt.Errorf("finding txt failed: %w", err)
 	}
 
 	// We need to insert some
 	if insertTextBatch.Len() > 0 {
 		if err := tx.SendBatch(ctx, insertTextBatch).Close(); err != nil {
-			return 0, fmt.Errorf("inserting txt failed: %w", err)
+			return fmt.Errorf("inserting txt failed: %w", err)
 		}
 	}
 
@@ -526,17 +574,7 @@ func ImportDocumentData(
 		batch.Queue(insertDocText, id, i, txtID)
 	}
 	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
-		return 0, fmt.Errorf("inserting txt failed: %w", err)
+		return fmt.Errorf("inserting txt failed: %w", err)
 	}
-
-	if inTx != nil {
-		if err := inTx(ctx, tx, id, false); err != nil {
-			return 0, fmt.Errorf("in transaction failed: %w", err)
-		}
-	}
-
-	if err := tx.Commit(ctx); err != nil {
-		return 0, fmt.Errorf("commiting transaction failed: %w", err)
-	}
-	return id, nil
+	return nil
 }
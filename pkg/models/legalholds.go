@@ -0,0 +1,97 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/ISDuBA/ISDuBA/pkg/database/query"
+)
+
+// ErrLegalHold is returned if documents to be deleted
+// are under an active legal hold.
+var ErrLegalHold = errors.New("under legal hold")
+
+// LegalHoldExpr returns the expression selecting the documents
+// of a query based legal hold. If ids are given the selection
+// is restricted to these documents.
+func LegalHoldExpr(q, creator string, ids []int64) (*query.Expr, *query.Parser, error) {
+	parser := query.Parser{Mode: query.DocumentMode, Me: creator}
+	expr, err := parser.Parse(q)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(ids) > 0 {
+		some := query.False()
+		for _, id := range ids {
+			some = some.Or(query.FieldEqInt("id", id))
+		}
+		expr = expr.And(some)
+	}
+	return expr, &parser, nil
+}
+
+// CheckLegalHolds returns ErrLegalHold if one of the given
+// documents is held explicitly or matches the query
+// of an active legal hold.
+func CheckLegalHolds(ctx context.Context, tx pgx.Tx, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	const (
+		explicitSQL = `SELECT EXISTS(SELECT 1 FROM legal_hold_documents lhd ` +
+			`JOIN legal_holds lh ON lhd.legal_holds_id = lh.id ` +
+			`WHERE lh.released IS NULL AND lhd.documents_id = ANY($1))`
+		queriesSQL = `SELECT query, creator FROM legal_holds ` +
+			`WHERE released IS NULL AND query IS NOT NULL`
+	)
+	var held bool
+	if err := tx.QueryRow(ctx, explicitSQL, ids).Scan(&held); err != nil {
+		return fmt.Errorf("checking legal holds failed: %w", err)
+	}
+	if held {
+		return ErrLegalHold
+	}
+	type hold struct{ query, creator string }
+	rows, _ := tx.Query(ctx, queriesSQL)
+	holds, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (hold, error) {
+		var h hold
+		err := row.Scan(&h.query, &h.creator)
+		return h, err
+	})
+	if err != nil {
+		return fmt.Errorf("loading legal holds failed: %w", err)
+	}
+	for _, h := range holds {
+		expr, parser, err := LegalHoldExpr(h.query, h.creator, ids)
+		if err != nil {
+			// The queries are checked when the holds are created.
+			return fmt.Errorf("invalid legal hold query %q: %w", h.query, err)
+		}
+		builder, err := query.NewAdvancedSQLBuilder(
+			query.AdvancedSQLBuilderExpr(expr),
+			query.AdvancedSQLBuilderFields([]string{"id"}),
+			query.AdvancedSQLBuilderParser(parser))
+		if err != nil {
+			return err
+		}
+		existsSQL := `SELECT EXISTS(` + builder.CreateQuery(-1, -1) + `)`
+		if err := tx.QueryRow(ctx, existsSQL, builder.Replacements...).Scan(&held); err != nil {
+			return fmt.Errorf("checking legal hold query failed: %w", err)
+		}
+		if held {
+			return ErrLegalHold
+		}
+	}
+	return nil
+}
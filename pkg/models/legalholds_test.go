@@ -0,0 +1,31 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ISDuBA/ISDuBA/pkg/database/query"
+)
+
+func TestLegalHoldExpr(t *testing.T) {
+	if _, _, err := LegalHoldExpr("$publisher", "alice", nil); err == nil {
+		t.Error("invalid query: expected error")
+	}
+	expr, _, err := LegalHoldExpr(`$publisher "Example" =`, "alice", []int64{1, 2})
+	if err != nil {
+		t.Fatalf("valid query: unexpected error: %v", err)
+	}
+	builder := query.SQLBuilder{}
+	where := builder.CreateWhere(expr)
+	if n := strings.Count(where, "(documents.id)="); n != 2 {
+		t.Errorf("restriction to ids: got %d id comparisons in %q", n, where)
+	}
+}
@@ -0,0 +1,51 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// StoreLocale stores the configured locale into the database.
+// If the locale has changed the localized titles of all
+// advisories are selected again.
+func StoreLocale(
+	ctx context.Context,
+	conn *pgxpool.Conn,
+	locale string,
+) error {
+	const (
+		updateSQL = `UPDATE instance_locale SET locale = $1 ` +
+			`WHERE locale IS DISTINCT FROM $1`
+		recomputeSQL = `SELECT update_localized_title(id) FROM advisories`
+	)
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tags, err := tx.Exec(ctx, updateSQL, locale)
+	if err != nil {
+		return fmt.Errorf("storing locale failed: %w", err)
+	}
+	if tags.RowsAffected() == 0 {
+		return nil
+	}
+	slog.Info("locale changed, selecting localized titles", "locale", locale)
+	if _, err := tx.Exec(ctx, recomputeSQL); err != nil {
+		return fmt.Errorf("selecting localized titles failed: %w", err)
+	}
+	return tx.Commit(ctx)
+}
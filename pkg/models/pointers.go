@@ -0,0 +1,53 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ParseJSONPointer splits a JSON pointer (RFC 6901) into its
+// unescaped reference tokens. The empty pointer refers to the
+// whole document and results in no tokens.
+func ParseJSONPointer(s string) ([]string, error) {
+	if s == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(s, "/") {
+		return nil, errors.New("JSON pointer has to start with '/'")
+	}
+	tokens := strings.Split(s[1:], "/")
+	for i, token := range tokens {
+		if !strings.Contains(token, "~") {
+			continue
+		}
+		var b strings.Builder
+		for j := 0; j < len(token); j++ {
+			if token[j] != '~' {
+				b.WriteByte(token[j])
+				continue
+			}
+			if j++; j == len(token) {
+				return nil, fmt.Errorf("invalid escape in JSON pointer token %q", token)
+			}
+			switch token[j] {
+			case '0':
+				b.WriteByte('~')
+			case '1':
+				b.WriteByte('/')
+			default:
+				return nil, fmt.Errorf("invalid escape in JSON pointer token %q", token)
+			}
+		}
+		tokens[i] = b.String()
+	}
+	return tokens, nil
+}
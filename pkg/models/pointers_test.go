@@ -0,0 +1,46 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseJSONPointer(t *testing.T) {
+	for _, x := range []struct {
+		pointer string
+		want    []string
+		fail    bool
+	}{
+		{"", []string{}, false},
+		{"/", []string{""}, false},
+		{"/vulnerabilities/0/remediations/1", []string{"vulnerabilities", "0", "remediations", "1"}, false},
+		{"/a~1b/c~0d", []string{"a/b", "c~d"}, false},
+		{"/~01", []string{"~1"}, false},
+		{"document", nil, true},
+		{"/a~", nil, true},
+		{"/a~2", nil, true},
+	} {
+		got, err := ParseJSONPointer(x.pointer)
+		if x.fail {
+			if err == nil {
+				t.Errorf("%q: expected error", x.pointer)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", x.pointer, err)
+			continue
+		}
+		if !slices.Equal(got, x.want) {
+			t.Errorf("%q: got %q, want %q", x.pointer, got, x.want)
+		}
+	}
+}
@@ -27,4 +27,10 @@ type StoredQuery struct {
 	Dashboard    bool             `json:"dashboard"`
 	Role         *WorkflowRole    `json:"role,omitempty"`
 	DefaultQuery bool             `json:"default_query"`
+	// Team is the team the query is shared with.
+	Team *int64 `json:"team,omitempty"`
+	// SharedRoles are the Keycloak roles the query is shared with.
+	SharedRoles []string `json:"shared_roles,omitempty"`
+	// ClonedFrom is the shared query this query was cloned from.
+	ClonedFrom *int64 `json:"cloned_from,omitempty"`
 }
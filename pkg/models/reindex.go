@@ -0,0 +1,57 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReindexDocument re-extracts the texts of a stored document.
+// The document has to be decoded from the original upload with the
+// transformations applied at import. Storing the re-extracted document
+// recomputes the generated columns like the CVSS scores, too.
+func ReindexDocument(
+	ctx context.Context,
+	conn *pgxpool.Conn,
+	id, advisoryID int64,
+	document any,
+) error {
+	di, err := indexDocument(document)
+	if err != nil {
+		return err
+	}
+
+	// The unique texts are shared with the imports.
+	globalInsertLock.Lock()
+	defer globalInsertLock.Unlock()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	const (
+		deleteTexts    = `DELETE FROM documents_texts WHERE documents_id = $1`
+		updateDocument = `UPDATE documents SET document = $1 WHERE id = $2`
+	)
+	if _, err := tx.Exec(ctx, deleteTexts, id); err != nil {
+		return fmt.Errorf("deleting old texts failed: %w", err)
+	}
+	if err := storeDocumentTexts(ctx, tx, id, advisoryID, di.texts); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, updateDocument, document, id); err != nil {
+		return fmt.Errorf("updating document failed: %w", err)
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,75 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ReportPeriod is the length of the time range covered by a report.
+type ReportPeriod string
+
+// The different report periods.
+const (
+	WeekPeriod    ReportPeriod = "week"    // WeekPeriod is a calendar week starting on Monday.
+	MonthPeriod   ReportPeriod = "month"   // MonthPeriod is a calendar month.
+	QuarterPeriod ReportPeriod = "quarter" // QuarterPeriod is a calendar quarter.
+	YearPeriod    ReportPeriod = "year"    // YearPeriod is a calendar year.
+)
+
+// ParseReportPeriod parses a report period from a string.
+func ParseReportPeriod(s string) (ReportPeriod, error) {
+	switch rp := ReportPeriod(strings.ToLower(s)); rp {
+	case WeekPeriod, MonthPeriod, QuarterPeriod, YearPeriod:
+		return rp, nil
+	default:
+		return "", fmt.Errorf("unknown report period %q", s)
+	}
+}
+
+// start returns the begin of the period containing t.
+func (rp ReportPeriod) start(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch rp {
+	case WeekPeriod:
+		// Weeks start on Monday.
+		wd := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-wd, 0, 0, 0, 0, time.UTC)
+	case QuarterPeriod:
+		return time.Date(y, m-(m-1)%3, 1, 0, 0, 0, 0, time.UTC)
+	case YearPeriod:
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// add moves the begin of a period n periods forward.
+func (rp ReportPeriod) add(t time.Time, n int) time.Time {
+	switch rp {
+	case WeekPeriod:
+		return t.AddDate(0, 0, 7*n)
+	case QuarterPeriod:
+		return t.AddDate(0, 3*n, 0)
+	case YearPeriod:
+		return t.AddDate(n, 0, 0)
+	default:
+		return t.AddDate(0, n, 0)
+	}
+}
+
+// Bounds returns the time range [from, to) of the period which lies
+// offset periods before the one containing now. An offset of 0 is the
+// current period, 1 the last completed one.
+func (rp ReportPeriod) Bounds(now time.Time, offset int) (time.Time, time.Time) {
+	from := rp.add(rp.start(now.UTC()), -offset)
+	return from, rp.add(from, 1)
+}
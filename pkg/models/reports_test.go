@@ -0,0 +1,57 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseReportPeriod(t *testing.T) {
+	for _, s := range []string{"week", "Month", "QUARTER", "year"} {
+		if _, err := ParseReportPeriod(s); err != nil {
+			t.Errorf("%q: unexpected error: %v", s, err)
+		}
+	}
+	if _, err := ParseReportPeriod("decade"); err == nil {
+		t.Error("decade: expected error")
+	}
+}
+
+func TestReportPeriodBounds(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	// A Wednesday.
+	now := time.Date(2026, time.February, 11, 13, 37, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		period   ReportPeriod
+		offset   int
+		from, to time.Time
+	}{
+		{WeekPeriod, 0, date(2026, time.February, 9), date(2026, time.February, 16)},
+		{WeekPeriod, 1, date(2026, time.February, 2), date(2026, time.February, 9)},
+		{MonthPeriod, 0, date(2026, time.February, 1), date(2026, time.March, 1)},
+		{MonthPeriod, 2, date(2025, time.December, 1), date(2026, time.January, 1)},
+		{QuarterPeriod, 0, date(2026, time.January, 1), date(2026, time.April, 1)},
+		{QuarterPeriod, 1, date(2025, time.October, 1), date(2026, time.January, 1)},
+		{YearPeriod, 1, date(2025, time.January, 1), date(2026, time.January, 1)},
+	} {
+		from, to := tc.period.Bounds(now, tc.offset)
+		if !from.Equal(tc.from) || !to.Equal(tc.to) {
+			t.Errorf("%s/%d: have [%s, %s) want [%s, %s)",
+				tc.period, tc.offset, from, to, tc.from, tc.to)
+		}
+	}
+	// Sunday belongs to the week started on Monday before.
+	sunday := date(2026, time.February, 15)
+	if from, _ := WeekPeriod.Bounds(sunday, 0); !from.Equal(date(2026, time.February, 9)) {
+		t.Errorf("sunday: unexpected start %s", from)
+	}
+}
@@ -0,0 +1,55 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"cmp"
+	"encoding/binary"
+	"hash/fnv"
+	"slices"
+)
+
+// sampleKey returns the pseudo random rank of an id for a seed.
+func sampleKey(seed string, id int64) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(seed))
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(id)))
+	// Finalizer of SplitMix64 to spread the bits.
+	x := h.Sum64()
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// SampleIDs returns a pseudo random sample of at most n of the given ids
+// in ascending order. The same seed results in the same sample.
+// As each id is ranked independently the sample of a grown set
+// only changes if new ids are ranked higher than the sampled ones.
+func SampleIDs(ids []int64, n int, seed string) []int64 {
+	if n <= 0 {
+		return []int64{}
+	}
+	type ranked struct {
+		id  int64
+		key uint64
+	}
+	ranks := make([]ranked, len(ids))
+	for i, id := range ids {
+		ranks[i] = ranked{id: id, key: sampleKey(seed, id)}
+	}
+	slices.SortFunc(ranks, func(a, b ranked) int {
+		return cmp.Or(cmp.Compare(a.key, b.key), cmp.Compare(a.id, b.id))
+	})
+	sample := make([]int64, min(n, len(ranks)))
+	for i := range sample {
+		sample[i] = ranks[i].id
+	}
+	slices.Sort(sample)
+	return sample
+}
@@ -0,0 +1,46 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSampleIDs(t *testing.T) {
+	ids := make([]int64, 1000)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+	a := SampleIDs(ids, 10, "qa")
+	if len(a) != 10 {
+		t.Fatalf("got %d ids, want 10", len(a))
+	}
+	if !slices.IsSorted(a) {
+		t.Errorf("sample %v is not sorted", a)
+	}
+	if b := SampleIDs(ids, 10, "qa"); !slices.Equal(a, b) {
+		t.Errorf("same seed: got %v and %v", a, b)
+	}
+	if b := SampleIDs(ids, 10, "other"); slices.Equal(a, b) {
+		t.Errorf("different seeds result in same sample %v", a)
+	}
+	// The order of the input does not matter.
+	reversed := slices.Clone(ids)
+	slices.Reverse(reversed)
+	if b := SampleIDs(reversed, 10, "qa"); !slices.Equal(a, b) {
+		t.Errorf("reversed input: got %v, want %v", b, a)
+	}
+	if got := SampleIDs(ids[:3], 10, "qa"); !slices.Equal(got, ids[:3]) {
+		t.Errorf("small set: got %v, want %v", got, ids[:3])
+	}
+	if got := SampleIDs(ids, 0, "qa"); len(got) != 0 {
+		t.Errorf("empty sample: got %v", got)
+	}
+}
@@ -0,0 +1,146 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ScoringFactor is a factor which contributes to the score of a document.
+type ScoringFactor string
+
+// The different scoring factors.
+const (
+	CVSSFactor      ScoringFactor = "cvss"      // CVSSFactor is the highest CVSS score.
+	EPSSFactor      ScoringFactor = "epss"      // EPSSFactor is the highest EPSS probability of the CVEs.
+	KEVFactor       ScoringFactor = "kev"       // KEVFactor tells if a CVE is known to be exploited.
+	AssetsFactor    ScoringFactor = "assets"    // AssetsFactor is the relevance for the own assets.
+	PublisherFactor ScoringFactor = "publisher" // PublisherFactor is the trust into the publisher.
+)
+
+// ScoringFactors are all known scoring factors.
+var ScoringFactors = []ScoringFactor{
+	CVSSFactor,
+	EPSSFactor,
+	KEVFactor,
+	AssetsFactor,
+	PublisherFactor,
+}
+
+type (
+	// ScoringWeights are the weights of the scoring factors.
+	ScoringWeights map[ScoringFactor]float64
+	// PublishersTrust is the trust into the publishers in the range of 0 to 1.
+	PublishersTrust map[Publisher]float64
+)
+
+// ParseScoringFactor parses a scoring factor from a string.
+func ParseScoringFactor(s string) (ScoringFactor, error) {
+	switch sf := ScoringFactor(strings.ToLower(s)); sf {
+	case CVSSFactor, EPSSFactor, KEVFactor, AssetsFactor, PublisherFactor:
+		return sf, nil
+	default:
+		return "", fmt.Errorf("unknown scoring factor %q", s)
+	}
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (sf *ScoringFactor) UnmarshalText(text []byte) error {
+	x, err := ParseScoringFactor(string(text))
+	if err != nil {
+		return err
+	}
+	*sf = x
+	return nil
+}
+
+// Validate checks if the weights are usable.
+func (sw ScoringWeights) Validate() error {
+	var total float64
+	for factor, weight := range sw {
+		if _, err := ParseScoringFactor(string(factor)); err != nil {
+			return err
+		}
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return fmt.Errorf("weight of %q has to be a non-negative number", factor)
+		}
+		total += weight
+	}
+	if total == 0 {
+		return errors.New("at least one weight has to be positive")
+	}
+	return nil
+}
+
+// Validate checks if the trust values are in the range of 0 to 1.
+func (pt PublishersTrust) Validate() error {
+	for publisher, trust := range pt {
+		if publisher == "" {
+			return errors.New("missing publisher")
+		}
+		if !(trust >= 0 && trust <= 1) {
+			return fmt.Errorf("trust of %q has to be between 0 and 1", publisher)
+		}
+	}
+	return nil
+}
+
+// StoreScoringDefaults stores the given weights and publishers trust
+// into the database if there are no weights stored yet.
+// Weights adjusted later on by an admin are kept.
+// The result reports if the defaults were stored so that
+// the scores of the documents have to be recomputed.
+func StoreScoringDefaults(
+	ctx context.Context,
+	conn *pgxpool.Conn,
+	weights ScoringWeights,
+	trust PublishersTrust,
+) (bool, error) {
+	const (
+		countSQL        = `SELECT count(*) FROM scoring_weights`
+		insertWeightSQL = `INSERT INTO scoring_weights (factor, weight) ` +
+			`VALUES ($1::scoring_factor, $2)`
+		insertTrustSQL = `INSERT INTO publishers_trust (publisher, trust) ` +
+			`VALUES ($1, $2) ON CONFLICT (publisher) DO NOTHING`
+	)
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var count int64
+	if err := tx.QueryRow(ctx, countSQL).Scan(&count); err != nil {
+		return false, fmt.Errorf("counting scoring weights failed: %w", err)
+	}
+	if count > 0 {
+		return false, nil
+	}
+	for factor, weight := range weights {
+		if _, err := tx.Exec(ctx, insertWeightSQL, string(factor), weight); err != nil {
+			return false, fmt.Errorf("storing scoring weight failed: %w", err)
+		}
+	}
+	for publisher, value := range trust {
+		if _, err := tx.Exec(ctx, insertTrustSQL, string(publisher), value); err != nil {
+			return false, fmt.Errorf("storing publisher trust failed: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
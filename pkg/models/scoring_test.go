@@ -0,0 +1,67 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScoringWeightsValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		weights ScoringWeights
+		fail    bool
+	}{
+		{"default", ScoringWeights{CVSSFactor: 1}, false},
+		{"mixed", ScoringWeights{CVSSFactor: 2, EPSSFactor: 1, KEVFactor: 0}, false},
+		{"empty", ScoringWeights{}, true},
+		{"all zero", ScoringWeights{CVSSFactor: 0, KEVFactor: 0}, true},
+		{"negative", ScoringWeights{CVSSFactor: 1, EPSSFactor: -1}, true},
+		{"nan", ScoringWeights{CVSSFactor: math.NaN()}, true},
+		{"unknown", ScoringWeights{"unknown": 1}, true},
+	} {
+		if err := tc.weights.Validate(); (err != nil) != tc.fail {
+			t.Errorf("%s: unexpected result: %v", tc.name, err)
+		}
+	}
+}
+
+func TestPublishersTrustValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		trust PublishersTrust
+		fail  bool
+	}{
+		{"nil", nil, false},
+		{"wildcard", PublishersTrust{"*": 0.5, "BSI": 1}, false},
+		{"too big", PublishersTrust{"BSI": 1.5}, true},
+		{"negative", PublishersTrust{"BSI": -0.1}, true},
+		{"nan", PublishersTrust{"BSI": math.NaN()}, true},
+		{"empty publisher", PublishersTrust{"": 1}, true},
+	} {
+		if err := tc.trust.Validate(); (err != nil) != tc.fail {
+			t.Errorf("%s: unexpected result: %v", tc.name, err)
+		}
+	}
+}
+
+func TestParseScoringFactor(t *testing.T) {
+	for _, f := range ScoringFactors {
+		if got, err := ParseScoringFactor(string(f)); err != nil || got != f {
+			t.Errorf("ParseScoringFactor(%q): got %q, %v", f, got, err)
+		}
+	}
+	if got, err := ParseScoringFactor("EPSS"); err != nil || got != EPSSFactor {
+		t.Errorf("ParseScoringFactor(\"EPSS\"): got %q, %v", got, err)
+	}
+	if _, err := ParseScoringFactor("unknown"); err == nil {
+		t.Error("ParseScoringFactor(\"unknown\"): expected error")
+	}
+}
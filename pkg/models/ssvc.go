@@ -68,6 +68,7 @@ type SSVCHistoryEntry struct {
 	Actor            *string   `json:"actor,omitempty"`
 	DocumentsID      int64     `json:"documents_id"`
 	DocumentsVersion *string   `json:"documents_version"`
+	Reason           *string   `json:"reason,omitempty"`
 }
 
 // SSVCChange bundles a SSVCHistoryEntry with the previous SSVC
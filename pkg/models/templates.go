@@ -0,0 +1,105 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TemplateKind is the kind of text a template is made for.
+type TemplateKind string
+
+// The different template kinds.
+const (
+	CommentTemplate    TemplateKind = "comment"    // CommentTemplate is used for comments.
+	AssessmentTemplate TemplateKind = "assessment" // AssessmentTemplate is used for assessments.
+)
+
+// TextTemplate is a reusable text with placeholders.
+type TextTemplate struct {
+	ID          int64        `json:"id"`
+	Kind        TemplateKind `json:"kind"`
+	Definer     string       `json:"definer"`
+	Global      bool         `json:"global"`
+	Team        *int64       `json:"team,omitempty"`
+	Name        string       `json:"name"`
+	Description *string      `json:"description,omitempty"`
+	Body        string       `json:"body"`
+}
+
+// ParseTemplateKind parses a template kind from a string.
+func ParseTemplateKind(s string) (TemplateKind, error) {
+	switch k := TemplateKind(strings.ToLower(s)); k {
+	case CommentTemplate, AssessmentTemplate:
+		return k, nil
+	default:
+		return "", fmt.Errorf("unknown template kind %q", s)
+	}
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (tk *TemplateKind) UnmarshalText(text []byte) error {
+	x, err := ParseTemplateKind(string(text))
+	if err != nil {
+		return err
+	}
+	*tk = x
+	return nil
+}
+
+// Scan implements [sql.Scanner].
+func (tk *TemplateKind) Scan(src any) error {
+	if s, ok := src.(string); ok {
+		x, err := ParseTemplateKind(s)
+		if err != nil {
+			return err
+		}
+		*tk = x
+		return nil
+	}
+	return errors.New("unsupported type")
+}
+
+// ExpandTemplate replaces the placeholders of the form {name} in body
+// with the values found in vars. Unknown placeholders are kept as they are.
+// A literal brace can be written as {{ or }}.
+func ExpandTemplate(body string, vars map[string]string) string {
+	var b strings.Builder
+	b.Grow(len(body))
+	for len(body) > 0 {
+		switch body[0] {
+		case '{':
+			if strings.HasPrefix(body, "{{") {
+				b.WriteByte('{')
+				body = body[2:]
+				continue
+			}
+			end := strings.IndexAny(body[1:], "{}")
+			if end >= 0 && body[1+end] == '}' {
+				name := body[1 : 1+end]
+				if value, ok := vars[name]; ok {
+					b.WriteString(value)
+					body = body[2+end:]
+					continue
+				}
+			}
+		case '}':
+			if strings.HasPrefix(body, "}}") {
+				b.WriteByte('}')
+				body = body[2:]
+				continue
+			}
+		}
+		b.WriteByte(body[0])
+		body = body[1:]
+	}
+	return b.String()
+}
@@ -0,0 +1,36 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import "testing"
+
+func TestExpandTemplate(t *testing.T) {
+	vars := map[string]string{
+		"tracking_id": "CSAF-2026-0001",
+		"max_cvss":    "9.8",
+	}
+	for _, tc := range []struct {
+		body string
+		want string
+	}{
+		{"", ""},
+		{"no placeholders", "no placeholders"},
+		{"{tracking_id}", "CSAF-2026-0001"},
+		{"{tracking_id} scored {max_cvss}.", "CSAF-2026-0001 scored 9.8."},
+		{"{unknown} stays", "{unknown} stays"},
+		{"{{tracking_id}}", "{tracking_id}"},
+		{"open {tracking_id", "open {tracking_id"},
+		{"{ {max_cvss}", "{ 9.8"},
+		{"}{max_cvss}{", "}9.8{"},
+	} {
+		if got := ExpandTemplate(tc.body, vars); got != tc.want {
+			t.Errorf("ExpandTemplate(%q): got %q, want %q", tc.body, got, tc.want)
+		}
+	}
+}
@@ -9,6 +9,7 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
 	"slices"
 
@@ -44,6 +45,17 @@ func (tlp *TLP) UnmarshalText(text []byte) error {
 	}
 }
 
+// Restricted reports if the sharing of documents with this TLP label
+// is restricted. Documents without a label are not restricted.
+func (tlp TLP) Restricted() bool {
+	switch tlp {
+	case "", TLPWhite, "CLEAR":
+		return false
+	default:
+		return true
+	}
+}
+
 // Allowed checks if a pair of publisher/tlp is allowed.
 func (ptlps PublishersTLPs) Allowed(publisher string, tlp TLP) bool {
 	if p, ok := ptlps[Publisher(publisher)]; ok {
@@ -53,17 +65,15 @@ func (ptlps PublishersTLPs) Allowed(publisher string, tlp TLP) bool {
 	return ok && slices.Contains(wildcard, tlp)
 }
 
-// or transforms a slice of string kinds into list of or-ed string field accesses.
-func or[T ~string](field string, tlps []T) *query.Expr {
-	var ts *query.Expr
-	for _, t := range tlps {
-		if tlp := query.FieldEqString(field, string(t)); ts == nil {
-			ts = tlp
-		} else {
-			ts = ts.Or(tlp)
-		}
+// Rules returns the TLP rules JSON encoded as expected
+// by the tlp_allowed function of the database.
+func (ptlps PublishersTLPs) Rules() string {
+	rules, err := json.Marshal(ptlps)
+	if err != nil {
+		// Maps of strings to lists of strings always encode.
+		panic(err)
 	}
-	return ts
+	return string(rules)
 }
 
 // AsExpr returns the list of TLP rules as an expression tree.
@@ -72,52 +82,42 @@ func (ptlps PublishersTLPs) AsExpr() *query.Expr {
 }
 
 // AsExprPublisher returns the list of TLP rules as an expression tree with a given
-// publisher field name.
+// publisher field name. The rules are checked by the tlp_allowed function
+// of the database which implements the same rules as [PublishersTLPs.Allowed].
 func (ptlps PublishersTLPs) AsExprPublisher(publisher string) *query.Expr {
-	// Make build process deterministic.
-	pubs := make([]Publisher, 0, len(ptlps))
-	for pub := range ptlps {
-		if pub != "*" {
-			pubs = append(pubs, pub)
+	for _, tlps := range ptlps {
+		if len(tlps) > 0 {
+			return query.TLPAllowed(publisher, ptlps.Rules())
 		}
 	}
-	slices.Sort(pubs)
-
-	var root *query.Expr
-	for _, pub := range pubs {
-		ts := or("tlp", ptlps[pub])
-		if ts == nil {
-			// List is empty.
-			continue
-		}
-		curr := query.FieldEqString(publisher, string(pub)).
-			And(ts)
+	// Nothing is allowed without rules.
+	return query.False()
+}
 
-		if root == nil {
-			root = curr
-		} else {
-			root = root.Or(curr)
+// Merge returns the union of the rules. A publisher is allowed to be seen
+// with a TLP level if one of the rules allows it. The rules of explicitly
+// listed publishers still take precedence over the wildcard of the same
+// rule set, so the wildcards of the other rule sets are merged in.
+func (ptlps PublishersTLPs) Merge(other PublishersTLPs) PublishersTLPs {
+	effective := func(p PublishersTLPs, publisher Publisher) []TLP {
+		if tlps, ok := p[publisher]; ok {
+			return tlps
 		}
+		return p["*"]
 	}
-
-	// Do we have a wildcard?
-	if tlps, ok := ptlps["*"]; ok {
-		if ts := or("tlp", tlps); ts != nil {
-			// If we have other publishers,
-			// don't apply wildcard in these cases.
-			if len(pubs) > 0 {
-				ts = ts.And(or(publisher, pubs).Not())
+	merged := make(PublishersTLPs, len(ptlps)+len(other))
+	for _, p := range []PublishersTLPs{ptlps, other} {
+		for publisher := range p {
+			if _, ok := merged[publisher]; ok {
+				continue
 			}
-			if root == nil {
-				root = ts
-			} else {
-				root = root.Or(ts)
+			tlps := slices.Concat(effective(ptlps, publisher), effective(other, publisher))
+			slices.Sort(tlps)
+			merged[publisher] = slices.Compact(tlps)
+			if merged[publisher] == nil {
+				merged[publisher] = []TLP{}
 			}
 		}
 	}
-
-	if root == nil {
-		return query.False()
-	}
-	return root
+	return merged
 }
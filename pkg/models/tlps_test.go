@@ -24,32 +24,28 @@ func TestAsConditions(t *testing.T) {
 	}{
 		{
 			`{"*": [ "WHITE", "GREEN" ]}`,
-			`(((((tlp)=($1)))OR(((tlp)=($2)))))`,
-			[]any{"WHITE", "GREEN"},
+			`(tlp_allowed((advisories.publisher),(tlp),($1)::jsonb))`,
+			[]any{`{"*":["WHITE","GREEN"]}`},
 		}, {
 			`{}`,
 			`(FALSE)`,
 			[]any{},
+		}, {
+			`{"A": []}`,
+			`(FALSE)`,
+			[]any{},
 		}, {
 			`{"A": [ "WHITE", "GREEN" ]}`,
-			`(((((advisories.publisher)=($1)))AND(((((tlp)=($2)))OR(((tlp)=($3)))))))`,
-			[]any{"A", "WHITE", "GREEN"},
+			`(tlp_allowed((advisories.publisher),(tlp),($1)::jsonb))`,
+			[]any{`{"A":["WHITE","GREEN"]}`},
 		}, {
 			`{"A": [ "AMBER", "RED" ], "*": ["WHITE"]}`,
-			`(((((((advisories.publisher)=($1)))AND(((((tlp)=($2)))OR(((tlp)=($3)))))))OR(((((tlp)=($4)))AND((NOT (((advisories.publisher)=($1)))))))))`,
-			[]any{"A", "AMBER", "RED", "WHITE"},
-		}, {
-			`{"A": [ "AMBER", "RED" ], "*": ["WHITE", "GREEN"]}`,
-			`(((((((advisories.publisher)=($1)))AND(((((tlp)=($2)))OR(((tlp)=($3)))))))OR(((((((tlp)=($4)))OR(((tlp)=($5)))))AND((NOT (((advisories.publisher)=($1)))))))))`,
-			[]any{"A", "AMBER", "RED", "WHITE", "GREEN"},
+			`(tlp_allowed((advisories.publisher),(tlp),($1)::jsonb))`,
+			[]any{`{"*":["WHITE"],"A":["AMBER","RED"]}`},
 		}, {
 			`{"A": [ "AMBER" ], "B": ["RED"], "*": ["WHITE"]}`,
-			`(((((((((advisories.publisher)=($1)))AND(((tlp)=($2)))))OR(((((advisories.publisher)=($3)))AND(((tlp)=($4)))))))OR(((((tlp)=($5)))AND((NOT (((((advisories.publisher)=($1)))OR(((advisories.publisher)=($3)))))))))))`,
-			[]any{"A", "AMBER", "B", "RED", "WHITE"},
-		}, {
-			`{"*": ["WHITE"], "A": [ "AMBER" ], "B": ["RED"]}`,
-			`(((((((((advisories.publisher)=($1)))AND(((tlp)=($2)))))OR(((((advisories.publisher)=($3)))AND(((tlp)=($4)))))))OR(((((tlp)=($5)))AND((NOT (((((advisories.publisher)=($1)))OR(((advisories.publisher)=($3)))))))))))`,
-			[]any{"A", "AMBER", "B", "RED", "WHITE"},
+			`(tlp_allowed((advisories.publisher),(tlp),($1)::jsonb))`,
+			[]any{`{"*":["WHITE"],"A":["AMBER"],"B":["RED"]}`},
 		},
 	} {
 		var ptlps PublishersTLPs
@@ -68,6 +64,34 @@ func TestAsConditions(t *testing.T) {
 	}
 }
 
+func TestAllowed(t *testing.T) {
+	ptlps := PublishersTLPs{
+		"A": {TLPAmber, TLPRed},
+		"B": {},
+		"*": {TLPWhite},
+	}
+	for _, x := range []struct {
+		publisher string
+		tlp       TLP
+		expected  bool
+	}{
+		{"A", TLPRed, true},
+		// Explicit publishers take precedence over the wildcard.
+		{"A", TLPWhite, false},
+		{"B", TLPWhite, false},
+		{"C", TLPWhite, true},
+		{"C", TLPGreen, false},
+		{"C", "", false},
+	} {
+		if have := ptlps.Allowed(x.publisher, x.tlp); have != x.expected {
+			t.Errorf("%s/%q: have %t expected %t", x.publisher, x.tlp, have, x.expected)
+		}
+	}
+	if (PublishersTLPs{}).Allowed("A", TLPWhite) {
+		t.Error("empty rules allow documents")
+	}
+}
+
 func TestUnmarshalText(t *testing.T) {
 	for _, x := range []struct {
 		input    string
@@ -92,3 +116,50 @@ func TestUnmarshalText(t *testing.T) {
 		}
 	}
 }
+
+func TestRestricted(t *testing.T) {
+	for _, x := range []struct {
+		input    TLP
+		expected bool
+	}{
+		{"", false},
+		{TLPWhite, false},
+		{"CLEAR", false},
+		{TLPGreen, true},
+		{TLPAmber, true},
+		{"AMBER+STRICT", true},
+		{TLPRed, true},
+	} {
+		if have := x.input.Restricted(); have != x.expected {
+			t.Errorf("%q: have %t expected %t", x.input, have, x.expected)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	for _, x := range []struct {
+		a, b     string
+		expected string
+	}{
+		{`{}`, `{}`, `{}`},
+		{`{"*":["WHITE"]}`, `{}`, `{"*":["WHITE"]}`},
+		{`{"*":["WHITE"]}`, `{"*":["GREEN","WHITE"]}`, `{"*":["GREEN","WHITE"]}`},
+		// The wildcard of the other rules still applies to A.
+		{`{"*":["WHITE"]}`, `{"A":["RED"]}`, `{"*":["WHITE"],"A":["RED","WHITE"]}`},
+		{`{"A":[]}`, `{"*":["GREEN"]}`, `{"*":["GREEN"],"A":["GREEN"]}`},
+		{`{"A":[],"*":["WHITE"]}`, `{"B":["AMBER"]}`, `{"*":["WHITE"],"A":[],"B":["AMBER","WHITE"]}`},
+	} {
+		var a, b PublishersTLPs
+		if err := json.Unmarshal([]byte(x.a), &a); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		if err := json.Unmarshal([]byte(x.b), &b); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		for _, have := range []string{a.Merge(b).Rules(), b.Merge(a).Rules()} {
+			if have != x.expected {
+				t.Errorf("%s + %s: have %s expected %s", x.a, x.b, have, x.expected)
+			}
+		}
+	}
+}
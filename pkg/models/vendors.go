@@ -0,0 +1,41 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// legalForms are the name parts ignored when comparing vendor names.
+var legalForms = []string{
+	"ag", "co", "corp", "corporation", "gmbh", "inc", "incorporated",
+	"kg", "limited", "llc", "ltd", "plc", "sa", "se",
+}
+
+// VendorTokens splits a vendor name into lower case words
+// without punctuation and legal forms.
+func VendorTokens(name string) []string {
+	tokens := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return slices.DeleteFunc(tokens, func(t string) bool {
+		return slices.Contains(legalForms, t)
+	})
+}
+
+// VendorMatches checks if the name of a publisher refers to a vendor.
+// Publishers often add the name of their security team to the vendor
+// name, e.g. "Example ProductCERT" for the vendor "Example Inc.",
+// so the vendor name only has to be a prefix of the publisher name.
+func VendorMatches(vendor, publisher string) bool {
+	v, p := VendorTokens(vendor), VendorTokens(publisher)
+	return len(v) > 0 && len(v) <= len(p) && slices.Equal(v, p[:len(v)])
+}
@@ -0,0 +1,48 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestVendorTokens(t *testing.T) {
+	for _, x := range []struct {
+		name string
+		want []string
+	}{
+		{"Example Inc.", []string{"example"}},
+		{"Example Systems GmbH & Co. KG", []string{"example", "systems"}},
+		{"  ", []string{}},
+	} {
+		if got := VendorTokens(x.name); !slices.Equal(got, x.want) {
+			t.Errorf("%q: got %q, want %q", x.name, got, x.want)
+		}
+	}
+}
+
+func TestVendorMatches(t *testing.T) {
+	for _, x := range []struct {
+		vendor    string
+		publisher string
+		want      bool
+	}{
+		{"Example Inc.", "Example ProductCERT", true},
+		{"example", "Example, Inc.", true},
+		{"Example Systems", "Example PSIRT", false},
+		{"Example", "Other Example", false},
+		{"Inc.", "Example Inc.", false},
+		{"", "Example", false},
+	} {
+		if got := VendorMatches(x.vendor, x.publisher); got != x.want {
+			t.Errorf("%q/%q: got %t, want %t", x.vendor, x.publisher, got, x.want)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package receipts sends the receipts acknowledging the imported
+// advisories to the providers of the sources.
+package receipts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+	"github.com/ISDuBA/ISDuBA/pkg/database"
+	"github.com/ISDuBA/ISDuBA/pkg/scheduler"
+	"github.com/ISDuBA/ISDuBA/pkg/sources"
+)
+
+// ReceivedType is the type of the receipts acknowledging
+// that an advisory was received and validated.
+const ReceivedType = "advisory_received"
+
+// maxDeliveryBatch limits the number of receipts sent in one run.
+const maxDeliveryBatch = 500
+
+// Receipt is the payload sent to the receipt URL of a source.
+type Receipt struct {
+	Type       string    `json:"type"`
+	Receiver   string    `json:"receiver,omitempty"`
+	Source     string    `json:"source"`
+	Publisher  string    `json:"publisher"`
+	TrackingID string    `json:"tracking_id"`
+	Version    string    `json:"version"`
+	URL        string    `json:"url"`
+	Received   time.Time `json:"received"`
+	Validated  bool      `json:"validated"`
+}
+
+// Sender delivers the queued receipts to the providers.
+// A nil sender is valid and does nothing.
+type Sender struct {
+	cfg    *config.Receipts
+	db     *database.DB
+	task   *scheduler.Task
+	client *http.Client
+	wake   chan struct{}
+}
+
+// NewSender returns a new sender. If the receipts are
+// not enabled in the configuration nil is returned.
+func NewSender(cfg *config.Config, db *database.DB, tasks *scheduler.Registry) *Sender {
+	if !cfg.Receipts.Enabled {
+		return nil
+	}
+	client := &http.Client{Transport: cfg.General.Transport()}
+	if cfg.Receipts.Timeout > 0 {
+		client.Timeout = cfg.Receipts.Timeout
+	}
+	return &Sender{
+		cfg:    &cfg.Receipts,
+		db:     db,
+		client: client,
+		wake:   make(chan struct{}, 1),
+		task: tasks.Register("receipts",
+			"Sends receipts for imported advisories to the providers.",
+			cfg.Receipts.Interval),
+	}
+}
+
+// Run sends the receipts as soon as the documents are imported
+// and retries failed deliveries periodically. To be used in a Go routine.
+func (s *Sender) Run(ctx context.Context) {
+	if s == nil {
+		return
+	}
+	go s.db.Listen(ctx, database.DocumentsImportedChannel, s.wakeUp)
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if s.db.Available() && !s.task.Paused() {
+				s.scheduledSend(ctx)
+			}
+		case <-s.wake:
+			if !s.task.Paused() {
+				s.scheduledSend(ctx)
+			}
+		case <-s.task.Triggered():
+			s.scheduledSend(ctx)
+		}
+	}
+}
+
+// wakeUp requests a run without blocking.
+func (s *Sender) wakeUp() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// scheduledSend sends and records the run in the scheduler task.
+func (s *Sender) scheduledSend(ctx context.Context) {
+	done := s.task.Start()
+	done(errors.Join(s.deliver(ctx), s.cleanup(ctx)))
+}
+
+// pendingReceipt is a queued receipt with its callback.
+type pendingReceipt struct {
+	id       int64
+	callback string
+	receipt  Receipt
+}
+
+// deliver sends the pending receipts to the callbacks of the sources.
+func (s *Sender) deliver(ctx context.Context) error {
+	const (
+		pendingSQL = `SELECT ar.id, ar.callback, s.name, ` +
+			`a.publisher, a.tracking_id, d.version, ar.url, ar.received ` +
+			`FROM advisory_receipts ar ` +
+			`JOIN sources s ON ar.sources_id = s.id ` +
+			`JOIN documents d ON ar.documents_id = d.id ` +
+			`JOIN advisories a ON d.advisories_id = a.id ` +
+			`WHERE ar.delivered IS NULL AND ar.attempts < $1 ` +
+			`ORDER BY ar.id ` +
+			`LIMIT $2`
+		deliveredSQL = `UPDATE advisory_receipts SET delivered = $2, error = NULL, ` +
+			`attempts = attempts + 1 ` +
+			`WHERE id = $1`
+		failedSQL = `UPDATE advisory_receipts SET error = $2, attempts = attempts + 1 ` +
+			`WHERE id = $1`
+	)
+	var pending []pendingReceipt
+	if err := s.db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+		rows, err := conn.Query(rctx, pendingSQL, s.cfg.MaxAttempts, maxDeliveryBatch)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var p pendingReceipt
+			if err := rows.Scan(
+				&p.id, &p.callback, &p.receipt.Source,
+				&p.receipt.Publisher, &p.receipt.TrackingID, &p.receipt.Version,
+				&p.receipt.URL, &p.receipt.Received,
+			); err != nil {
+				return err
+			}
+			p.receipt.Type = ReceivedType
+			p.receipt.Receiver = s.cfg.Receiver
+			p.receipt.Received = p.receipt.Received.UTC()
+			p.receipt.Validated = true
+			pending = append(pending, p)
+		}
+		return rows.Err()
+	}, 0); err != nil {
+		return fmt.Errorf("loading pending receipts failed: %w", err)
+	}
+	for i := range pending {
+		p := &pending[i]
+		var (
+			sql  = deliveredSQL
+			arg  any
+			derr = s.send(ctx, p)
+		)
+		if derr != nil {
+			slog.Warn("delivering receipt failed",
+				"source", p.receipt.Source, "url", p.receipt.URL, "error", derr)
+			sql, arg = failedSQL, derr.Error()
+		} else {
+			arg = time.Now().UTC()
+		}
+		if err := s.db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+			_, err := conn.Exec(rctx, sql, p.id, arg)
+			return err
+		}, 0); err != nil {
+			return fmt.Errorf("storing delivery state failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// newRequest returns the request to post a receipt to a callback.
+func newRequest(ctx context.Context, p *pendingReceipt) (*http.Request, error) {
+	body, err := json.Marshal(&p.receipt)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.callback, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", sources.UserAgent)
+	return req, nil
+}
+
+// send posts a receipt to the callback of its source.
+func (s *Sender) send(ctx context.Context, p *pendingReceipt) error {
+	req, err := newRequest(ctx, p)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("status code %d (%s)", resp.StatusCode, resp.Status)
+	}
+	return nil
+}
+
+// cleanup removes the receipts older than the configured retention.
+func (s *Sender) cleanup(ctx context.Context) error {
+	if s.cfg.Retention <= 0 {
+		return nil
+	}
+	const deleteSQL = `DELETE FROM advisory_receipts WHERE received < $1`
+	return s.db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+		_, err := conn.Exec(rctx, deleteSQL, time.Now().Add(-s.cfg.Retention))
+		return err
+	}, 0)
+}
@@ -0,0 +1,559 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package recompute recomputes the derived fields of the stored documents
+// in the background, e.g. after the extraction logic has changed.
+package recompute
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/blobs"
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+	"github.com/ISDuBA/ISDuBA/pkg/database"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/transform"
+)
+
+// Field is a group of derived fields of the documents.
+type Field string
+
+const (
+	// Texts is the index of the texts of the documents used by the
+	// searches, including the product names and product ids.
+	Texts Field = "texts"
+	// Columns are the columns generated from the documents like the
+	// CVSS scores, the severity buckets and the localized titles.
+	Columns Field = "columns"
+	// CVEs is the index of the CVEs of the documents.
+	CVEs Field = "cves"
+	// Notes are the notes, remediations and acknowledgments
+	// used by the full text search.
+	Notes Field = "notes"
+	// Score is the weighted score of the documents.
+	Score Field = "score"
+)
+
+// Fields are all fields in the order they are recomputed.
+var Fields = []Field{Texts, Columns, CVEs, Notes, Score}
+
+// ParseFields parses a list of fields separated by spaces or commas.
+// An empty list selects all fields. The result is in the order
+// the fields are recomputed.
+func ParseFields(s string) ([]Field, error) {
+	names := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	if len(names) == 0 {
+		return slices.Clone(Fields), nil
+	}
+	var fields []Field
+	for _, name := range names {
+		f := Field(strings.ToLower(name))
+		if !slices.Contains(Fields, f) {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		fields = append(fields, f)
+	}
+	// Keep the order of the recomputation.
+	return slices.DeleteFunc(slices.Clone(Fields), func(f Field) bool {
+		return !slices.Contains(fields, f)
+	}), nil
+}
+
+var (
+	// ErrRunning is returned if a job is started while another one is running.
+	ErrRunning = errors.New("recompute job already running")
+	// ErrNotFound is returned if a job to resume is not found.
+	ErrNotFound = errors.New("no such failed or canceled recompute job")
+)
+
+type job struct {
+	id        int64
+	fields    []Field
+	publisher *string
+	last      int64
+	canceled  atomic.Bool
+}
+
+// has checks if the given field is recomputed by the job.
+func (j *job) has(f Field) bool {
+	return slices.Contains(j.fields, f)
+}
+
+// Recomputer runs the recompute jobs one after the other.
+type Recomputer struct {
+	cfg   *config.Recompute
+	db    *database.DB
+	blobs *blobs.Store
+	jobs  chan *job
+
+	mu      sync.Mutex
+	current *job
+	// queued are the jobs waiting for the current one to finish.
+	queued []*job
+}
+
+// NewRecomputer creates a new recomputer.
+func NewRecomputer(cfg *config.Recompute, db *database.DB, bs *blobs.Store) *Recomputer {
+	return &Recomputer{
+		cfg:   cfg,
+		db:    db,
+		blobs: bs,
+		jobs:  make(chan *job),
+	}
+}
+
+// Run runs the recomputer till the context is canceled.
+// Jobs interrupted by a previous shutdown are resumed first.
+func (r *Recomputer) Run(ctx context.Context) {
+	interrupted := r.interrupted(ctx)
+	r.mu.Lock()
+	r.queued = append(interrupted, r.queued...)
+	if r.current == nil {
+		r.next()
+	}
+	r.mu.Unlock()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case j := <-r.jobs:
+			r.recompute(ctx, j)
+		}
+	}
+}
+
+// scanJob scans the fields of a job to be run.
+func scanJob(row pgx.CollectableRow) (*job, error) {
+	var (
+		j      job
+		fields []string
+	)
+	if err := row.Scan(&j.id, &fields, &j.publisher, &j.last); err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		j.fields = append(j.fields, Field(f))
+	}
+	return &j, nil
+}
+
+// interrupted loads the jobs which were running at the last shutdown.
+func (r *Recomputer) interrupted(ctx context.Context) []*job {
+	const selectSQL = `SELECT id, fields, publisher, last_id ` +
+		`FROM recompute_jobs WHERE status = 'running' ORDER BY id`
+	var jobs []*job
+	if err := r.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, selectSQL)
+			var err error
+			jobs, err = pgx.CollectRows(rows, scanJob)
+			return err
+		}, 0,
+	); err != nil {
+		slog.Error("loading interrupted recompute jobs failed", "err", err)
+	}
+	return jobs
+}
+
+// Start registers a new job and hands it over to the background worker.
+// If publisher is given only the documents of this publisher are recomputed.
+func (r *Recomputer) Start(
+	ctx context.Context,
+	creator sql.NullString,
+	fields []Field,
+	publisher *string,
+) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil {
+		return 0, ErrRunning
+	}
+	j, err := r.register(ctx, creator, fields, publisher)
+	if err != nil {
+		return 0, err
+	}
+	r.current = j
+	go func() { r.jobs <- j }()
+	return j.id, nil
+}
+
+// Schedule registers a new job recomputing the given fields of all documents.
+// If another job is running it is run after it. A job with the same
+// fields which is still waiting is not registered a second time.
+func (r *Recomputer) Schedule(
+	ctx context.Context,
+	creator sql.NullString,
+	fields []Field,
+) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, q := range r.queued {
+		if q.publisher == nil && q.last == 0 && slices.Equal(q.fields, fields) {
+			return q.id, nil
+		}
+	}
+	j, err := r.register(ctx, creator, fields, nil)
+	if err != nil {
+		return 0, err
+	}
+	r.queued = append(r.queued, j)
+	if r.current == nil {
+		r.next()
+	}
+	return j.id, nil
+}
+
+// next hands the next waiting job over to the background worker.
+// Expects the mutex to be locked.
+func (r *Recomputer) next() {
+	if len(r.queued) == 0 {
+		return
+	}
+	j := r.queued[0]
+	r.queued = r.queued[1:]
+	r.current = j
+	go func() { r.jobs <- j }()
+}
+
+// register stores a new job in the database.
+func (r *Recomputer) register(
+	ctx context.Context,
+	creator sql.NullString,
+	fields []Field,
+	publisher *string,
+) (*job, error) {
+	const insertSQL = `INSERT INTO recompute_jobs ` +
+		`(creator, fields, publisher, total) ` +
+		`SELECT $1, $2, $3, count(*) ` +
+		`FROM documents JOIN advisories ON documents.advisories_id = advisories.id ` +
+		`WHERE $3::text IS NULL OR advisories.publisher = $3 ` +
+		`RETURNING id`
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = string(f)
+	}
+	var id int64
+	if err := r.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			return conn.QueryRow(rctx, insertSQL, creator, names, publisher).Scan(&id)
+		}, 0,
+	); err != nil {
+		return nil, err
+	}
+	return &job{id: id, fields: fields, publisher: publisher}, nil
+}
+
+// Resume resumes a failed or canceled job where it stopped.
+func (r *Recomputer) Resume(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil {
+		return ErrRunning
+	}
+	const updateSQL = `UPDATE recompute_jobs ` +
+		`SET (status, finished, error) = ('running', NULL, NULL) ` +
+		`WHERE id = $1 AND status IN ('failed', 'canceled') ` +
+		`RETURNING id, fields, publisher, last_id`
+	var j *job
+	switch err := r.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, updateSQL, id)
+			var err error
+			j, err = pgx.CollectExactlyOneRow(rows, scanJob)
+			return err
+		}, 0,
+	); {
+	case errors.Is(err, pgx.ErrNoRows):
+		return ErrNotFound
+	case err != nil:
+		return err
+	}
+	r.current = j
+	go func() { r.jobs <- j }()
+	return nil
+}
+
+// Cancel cancels the running or waiting job with the given id.
+// Returns false if there is no such job.
+func (r *Recomputer) Cancel(id int64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil && r.current.id == id {
+		r.current.canceled.Store(true)
+		return true
+	}
+	// Waiting jobs end as canceled when it is their turn.
+	for _, q := range r.queued {
+		if q.id == id {
+			q.canceled.Store(true)
+			return true
+		}
+	}
+	return false
+}
+
+// recompute recomputes the documents of the job in batches.
+// The next waiting job is started afterwards.
+func (r *Recomputer) recompute(ctx context.Context, j *job) {
+	defer func() {
+		r.mu.Lock()
+		r.current = nil
+		if ctx.Err() == nil {
+			r.next()
+		}
+		r.mu.Unlock()
+	}()
+	slog.Info("recompute job started", "id", j.id, "fields", j.fields, "after", j.last)
+	var (
+		status = "finished"
+		errMsg *string
+	)
+loop:
+	for {
+		if j.canceled.Load() {
+			status = "canceled"
+			break
+		}
+		n, err := r.recomputeBatch(ctx, j)
+		switch {
+		case ctx.Err() != nil:
+			// Shutting down. The job is resumed with the next start.
+			slog.Info("recompute job interrupted", "id", j.id, "after", j.last)
+			return
+		case err != nil:
+			slog.Error("recompute job failed", "id", j.id, "err", err)
+			msg := err.Error()
+			status, errMsg = "failed", &msg
+			break loop
+		case n < r.cfg.BatchSize:
+			break loop
+		}
+		// Give the other database users some room.
+		if r.cfg.Pause > 0 {
+			select {
+			case <-ctx.Done():
+				slog.Info("recompute job interrupted", "id", j.id, "after", j.last)
+				return
+			case <-time.After(r.cfg.Pause):
+			}
+		}
+	}
+	const finishSQL = `UPDATE recompute_jobs ` +
+		`SET (status, finished, error) = ($1, current_timestamp, $2) ` +
+		`WHERE id = $3`
+	if err := r.db.Run(
+		context.WithoutCancel(ctx),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			_, err := conn.Exec(rctx, finishSQL, status, errMsg, j.id)
+			return err
+		}, 0,
+	); err != nil {
+		slog.Error("finishing recompute job failed", "id", j.id, "err", err)
+	}
+	slog.Info("recompute job ended", "id", j.id, "status", status)
+}
+
+type candidate struct {
+	id         int64
+	advisoryID int64
+	// key is the key of the original upload if kept in the blob storage.
+	key *string
+}
+
+// recomputeBatch recomputes the next batch of documents after
+// the last processed one and records the progress.
+// Returns the number of documents of the batch.
+func (r *Recomputer) recomputeBatch(ctx context.Context, j *job) (int, error) {
+	const selectSQL = `SELECT documents.id, documents.advisories_id, documents.original_key ` +
+		`FROM documents JOIN advisories ON documents.advisories_id = advisories.id ` +
+		`WHERE documents.id > $1 ` +
+		`AND ($2::text IS NULL OR advisories.publisher = $2) ` +
+		`ORDER BY documents.id ` +
+		`LIMIT $3`
+	var candidates []candidate
+	if err := r.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, selectSQL, j.last, j.publisher, r.cfg.BatchSize)
+			var err error
+			candidates, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (candidate, error) {
+				var c candidate
+				err := row.Scan(&c.id, &c.advisoryID, &c.key)
+				return c, err
+			})
+			return err
+		}, 0,
+	); err != nil {
+		return 0, fmt.Errorf("loading documents failed: %w", err)
+	}
+	if len(candidates) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int64, len(candidates))
+	for i := range candidates {
+		ids[i] = candidates[i].id
+	}
+
+	// The texts are recomputed first as the other fields depend on them.
+	var skipped int
+	if j.has(Texts) {
+		for i := range candidates {
+			ok, err := r.reindex(ctx, &candidates[i])
+			if err != nil {
+				return 0, err
+			}
+			if !ok {
+				skipped++
+			}
+		}
+	}
+
+	var batch pgx.Batch
+	if j.has(Columns) {
+		// Stored generated columns are recomputed with every update.
+		batch.Queue(`UPDATE documents SET document = document WHERE id = ANY($1)`, ids)
+		batch.Queue(`SELECT update_localized_title(adv) FROM `+
+			`(SELECT DISTINCT advisories_id AS adv FROM documents WHERE id = ANY($1)) AS advs`, ids)
+	}
+	if j.has(CVEs) {
+		const cvesSQL = `SELECT documents.id AS doc_id, cves.cve ` +
+			`FROM documents, jsonb_array_elements_text(` +
+			`jsonb_path_query_array(document, '$.vulnerabilities."cve"')) AS cves(cve) ` +
+			`WHERE documents.id = ANY($1)`
+		batch.Queue(`DELETE FROM documents_cves WHERE documents_id = ANY($1)`, ids)
+		batch.Queue(`INSERT INTO unique_cves (cve) `+
+			`SELECT DISTINCT cve FROM (`+cvesSQL+`) AS cves `+
+			`ON CONFLICT DO NOTHING`, ids)
+		batch.Queue(`INSERT INTO documents_cves (documents_id, cve_id) `+
+			`SELECT DISTINCT cves.doc_id, unique_cves.id FROM (`+cvesSQL+`) AS cves `+
+			`JOIN unique_cves ON unique_cves.cve = cves.cve `+
+			`ON CONFLICT DO NOTHING`, ids)
+	}
+	if j.has(Notes) {
+		const notesSQL = `UPDATE documents SET (notes, remediations, acknowledgments) = (` +
+			`SELECT document_notes(o.doc), document_remediations(o.doc), document_acknowledgments(o.doc) ` +
+			`FROM original_json(coalesce($1, original)) AS o(doc)) `
+		batch.Queue(notesSQL+`WHERE id = ANY($2) AND original IS NOT NULL`, nil, ids)
+		// The original uploads kept in the blob storage are passed in.
+		for i := range candidates {
+			c := &candidates[i]
+			if c.key == nil {
+				continue
+			}
+			original, err := r.blobs.Original(ctx, nil, c.key)
+			if err != nil {
+				return 0, fmt.Errorf("loading document %d failed: %w", c.id, err)
+			}
+			batch.Queue(notesSQL+`WHERE id = $2`, original, c.id)
+		}
+	}
+	if j.has(Score) {
+		batch.Queue(`UPDATE documents SET score = document_score(document, advisories_id, id) `+
+			`WHERE id = ANY($1)`, ids)
+	}
+	last := candidates[len(candidates)-1].id
+	batch.Queue(`UPDATE recompute_jobs SET `+
+		`processed = processed + $1, skipped = skipped + $2, last_id = $3 `+
+		`WHERE id = $4`, len(candidates), skipped, last, j.id)
+
+	if err := r.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tx, err := conn.Begin(rctx)
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(rctx)
+			if err := tx.SendBatch(rctx, &batch).Close(); err != nil {
+				return err
+			}
+			return tx.Commit(rctx)
+		}, 0,
+	); err != nil {
+		return 0, fmt.Errorf("storing recomputed fields failed: %w", err)
+	}
+	j.last = last
+	return len(candidates), nil
+}
+
+// reindex re-extracts the texts of a document from its original upload
+// with the transformations applied at import replayed.
+// Returns false if the document has to be skipped.
+func (r *Recomputer) reindex(ctx context.Context, c *candidate) (bool, error) {
+	const (
+		originalSQL = `SELECT original, original_key FROM documents WHERE id = $1`
+		changesSQL  = `SELECT rule, path, original, replaced ` +
+			`FROM document_transformations WHERE documents_id = $1 ORDER BY num`
+	)
+	skipped := false
+	err := r.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			var (
+				original []byte
+				key      *string
+			)
+			switch err := conn.QueryRow(rctx, originalSQL, c.id).Scan(&original, &key); {
+			case errors.Is(err, pgx.ErrNoRows):
+				// Deleted in the meantime.
+				skipped = true
+				return nil
+			case err != nil:
+				return fmt.Errorf("loading document failed: %w", err)
+			}
+			original, err := r.blobs.Original(rctx, original, key)
+			if err != nil {
+				return fmt.Errorf("loading document failed: %w", err)
+			}
+			rows, _ := conn.Query(rctx, changesSQL, c.id)
+			changes, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (transform.Change, error) {
+				var ch transform.Change
+				err := row.Scan(&ch.Rule, &ch.Path, &ch.Original, &ch.Replaced)
+				return ch, err
+			})
+			if err != nil {
+				return fmt.Errorf("loading transformations failed: %w", err)
+			}
+			var document any
+			if err := json.Unmarshal(original, &document); err != nil {
+				slog.Warn("recompute: decoding document failed", "id", c.id, "err", err)
+				skipped = true
+				return nil
+			}
+			if err := transform.Replay(document, changes); err != nil {
+				slog.Warn("recompute: replaying transformations failed", "id", c.id, "err", err)
+				skipped = true
+				return nil
+			}
+			return models.ReindexDocument(rctx, conn, c.id, c.advisoryID, document)
+		}, 0,
+	)
+	if err != nil {
+		return false, fmt.Errorf("recomputing texts of document %d failed: %w", c.id, err)
+	}
+	return !skipped, nil
+}
@@ -0,0 +1,44 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package recompute
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseFields(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		want  []Field
+		fail  bool
+	}{
+		{input: "", want: Fields},
+		{input: " , ", want: Fields},
+		{input: "score", want: []Field{Score}},
+		{input: "score,texts", want: []Field{Texts, Score}},
+		{input: "CVEs notes, cves", want: []Field{CVEs, Notes}},
+		{input: "texts,summary", fail: true},
+	} {
+		got, err := ParseFields(tc.input)
+		if tc.fail {
+			if err == nil {
+				t.Errorf("%q: expected error", tc.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.input, err)
+			continue
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%q: got %v, want %v", tc.input, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package requestid carries the identifiers of HTTP requests through
+// contexts into log entries, database calls and outgoing requests
+// to correlate them.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+)
+
+// Header is the HTTP header carrying the request id.
+const Header = "X-Request-ID"
+
+// LogKey is the key of the request id in log entries.
+const LogKey = "request_id"
+
+// maxLength is the maximal length of accepted request ids.
+const maxLength = 128
+
+type contextKey struct{}
+
+// WithID returns a context carrying the given request id.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request id of the context.
+// Empty if there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// New generates a new random request id.
+func New() string {
+	var buf [16]byte
+	rand.Read(buf[:])
+	return hex.EncodeToString(buf[:])
+}
+
+// Valid checks if a request id given by a client may be used.
+// To prevent injections into the logs only short ids of
+// letters, digits and the characters '-', '_' and '.' are accepted.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, c := range []byte(id) {
+		if !('a' <= c && c <= 'z' ||
+			'A' <= c && c <= 'Z' ||
+			'0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// handler adds the request id of the context to the log records.
+type handler struct {
+	slog.Handler
+}
+
+// NewHandler wraps a log handler to add the request ids
+// of the contexts to the log records.
+func NewHandler(h slog.Handler) slog.Handler {
+	return handler{h}
+}
+
+// Handle implements [slog.Handler].
+func (h handler) Handle(ctx context.Context, r slog.Record) error {
+	if id := FromContext(ctx); id != "" {
+		r = r.Clone()
+		r.AddAttrs(slog.String(LogKey, id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs implements [slog.Handler].
+func (h handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return handler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements [slog.Handler].
+func (h handler) WithGroup(name string) slog.Handler {
+	return handler{h.Handler.WithGroup(name)}
+}
+
+// transport sets the request id header of outgoing requests.
+type transport struct {
+	http.RoundTripper
+}
+
+// NewTransport wraps a round tripper to forward the request ids
+// of the contexts of the outgoing requests.
+func NewTransport(rt http.RoundTripper) http.RoundTripper {
+	return transport{rt}
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (t transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if id := FromContext(req.Context()); id != "" && req.Header.Get(Header) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(Header, id)
+	}
+	return t.RoundTripper.RoundTrip(req)
+}
@@ -0,0 +1,88 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package requestid
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	for _, x := range []struct {
+		id   string
+		want bool
+	}{
+		{"", false},
+		{New(), true},
+		{"4f1c2a-req_1.2", true},
+		{"with space", false},
+		{"new\nline", false},
+		{"quote\"", false},
+		{strings.Repeat("a", maxLength), true},
+		{strings.Repeat("a", maxLength+1), false},
+	} {
+		if got := Valid(x.id); got != x.want {
+			t.Errorf("Valid(%q) = %t, want %t", x.id, got, x.want)
+		}
+	}
+	if New() == New() {
+		t.Error("New returned the same id twice")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewTextHandler(&buf, nil))).With("a", 1)
+
+	logger.InfoContext(WithID(context.Background(), "abc"), "with id")
+	logger.InfoContext(context.Background(), "without id")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d log lines, want 2", len(lines))
+	}
+	if !strings.Contains(lines[0], "a=1 request_id=abc") {
+		t.Errorf("missing request id: %s", lines[0])
+	}
+	if strings.Contains(lines[1], LogKey) {
+		t.Errorf("unexpected request id: %s", lines[1])
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestTransport(t *testing.T) {
+	var got string
+	rt := NewTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		got = req.Header.Get(Header)
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}))
+
+	req, _ := http.NewRequestWithContext(
+		WithID(context.Background(), "abc"), http.MethodGet, "https://example.com", nil)
+	rt.RoundTrip(req)
+	if got != "abc" {
+		t.Errorf("got header %q, want %q", got, "abc")
+	}
+	if req.Header.Get(Header) != "" {
+		t.Error("original request was modified")
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "https://example.com", nil)
+	rt.RoundTrip(req)
+	if got != "" {
+		t.Errorf("got header %q, want none", got)
+	}
+}
@@ -0,0 +1,102 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// clamd scans with the INSTREAM command of a ClamAV daemon.
+type clamd struct {
+	network string
+	address string
+}
+
+// newClamd creates a clamd backend from an address of the
+// form "tcp://host:port" or "unix:///path/to/socket".
+func newClamd(address string) (*clamd, error) {
+	network, addr, ok := strings.Cut(address, "://")
+	if !ok || (network != "tcp" && network != "unix") || addr == "" {
+		return nil, fmt.Errorf("invalid clamd address %q", address)
+	}
+	return &clamd{network: network, address: addr}, nil
+}
+
+func (c *clamd) scan(ctx context.Context, r io.Reader) (string, error) {
+	conn, err := dial(ctx, c.network, c.address)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	w := bufio.NewWriterSize(conn, chunkSize+4)
+	if _, err := w.WriteString("zINSTREAM\x00"); err != nil {
+		return "", err
+	}
+	// Stream the file in chunks prefixed by their length
+	// terminated by an empty chunk.
+	var (
+		buf  = make([]byte, chunkSize)
+		size [4]byte
+	)
+	for {
+		n, rerr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size[:], uint32(n))
+			if _, err := w.Write(size[:]); err != nil {
+				return "", err
+			}
+			if _, err := w.Write(buf[:n]); err != nil {
+				return "", err
+			}
+		}
+		if errors.Is(rerr, io.EOF) {
+			break
+		}
+		if rerr != nil {
+			return "", rerr
+		}
+	}
+	binary.BigEndian.PutUint32(size[:], 0)
+	if _, err := w.Write(size[:]); err != nil {
+		return "", err
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return parseClamdReply(reply)
+}
+
+// parseClamdReply parses the reply of clamd to a scan command
+// like "stream: OK" or "stream: Eicar-Signature FOUND".
+func parseClamdReply(reply string) (string, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	_, result, ok := strings.Cut(reply, ": ")
+	switch {
+	case !ok:
+		return "", fmt.Errorf("unexpected clamd reply %q", reply)
+	case result == "OK":
+		return "", nil
+	case strings.HasSuffix(result, " FOUND"):
+		return strings.TrimSuffix(result, " FOUND"), nil
+	case strings.HasSuffix(result, " ERROR"):
+		return "", fmt.Errorf("clamd: %s", strings.TrimSuffix(result, " ERROR"))
+	default:
+		return "", fmt.Errorf("unexpected clamd reply %q", reply)
+	}
+}
@@ -0,0 +1,134 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strings"
+)
+
+// defaultICAPPort is the default port of ICAP servers.
+const defaultICAPPort = "1344"
+
+// encapsulatedHeader is the HTTP response header of the
+// file embedded in the ICAP request.
+const encapsulatedHeader = "HTTP/1.1 200 OK\r\n" +
+	"Content-Type: application/octet-stream\r\n" +
+	"\r\n"
+
+// icap scans with a RESPMOD request to an ICAP server (RFC 3507).
+type icap struct {
+	url  string
+	host string
+}
+
+// newICAP creates an ICAP backend from an address
+// of the form "icap://host[:port]/service".
+func newICAP(address string) (*icap, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "icap" || u.Host == "" {
+		return nil, fmt.Errorf("invalid ICAP address %q", address)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), defaultICAPPort)
+	}
+	return &icap{url: u.String(), host: host}, nil
+}
+
+func (ic *icap) scan(ctx context.Context, r io.Reader) (string, error) {
+	conn, err := dial(ctx, "tcp", ic.host)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	w := bufio.NewWriterSize(conn, chunkSize+16)
+	fmt.Fprintf(w, "RESPMOD %s ICAP/1.0\r\n"+
+		"Host: %s\r\n"+
+		"Allow: 204\r\n"+
+		"Encapsulated: res-hdr=0, res-body=%d\r\n"+
+		"\r\n"+
+		"%s",
+		ic.url, ic.host, len(encapsulatedHeader), encapsulatedHeader)
+	// Send the file with chunked transfer encoding.
+	buf := make([]byte, chunkSize)
+	for {
+		n, rerr := r.Read(buf)
+		if n > 0 {
+			fmt.Fprintf(w, "%x\r\n", n)
+			w.Write(buf[:n])
+			w.WriteString("\r\n")
+		}
+		if errors.Is(rerr, io.EOF) {
+			break
+		}
+		if rerr != nil {
+			return "", rerr
+		}
+	}
+	w.WriteString("0\r\n\r\n")
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	tp := textproto.NewReader(bufio.NewReader(conn))
+	status, err := tp.ReadLine()
+	if err != nil {
+		return "", err
+	}
+	header, err := tp.ReadMIMEHeader()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return parseICAPResponse(status, header)
+}
+
+// parseICAPResponse parses the status line and the header of
+// the response of an ICAP server. A "204 No Content" signals
+// a clean file. A "200 OK" signals that the server modified the
+// file because of a found threat which is reported in the
+// X-Infection-Found, X-Virus-ID or X-Violations-Found headers.
+func parseICAPResponse(status string, header textproto.MIMEHeader) (string, error) {
+	fields := strings.Fields(status)
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "ICAP/") {
+		return "", fmt.Errorf("unexpected ICAP status %q", status)
+	}
+	switch fields[1] {
+	case "204":
+		return "", nil
+	case "200":
+	default:
+		return "", fmt.Errorf("ICAP status %q", strings.Join(fields[1:], " "))
+	}
+	// e.g. "Type=0; Resolution=2; Threat=Eicar-Test-Signature;"
+	for part := range strings.SplitSeq(header.Get("X-Infection-Found"), ";") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(part), "="); ok &&
+			strings.EqualFold(k, "Threat") && v != "" {
+			return v, nil
+		}
+	}
+	if id := strings.TrimSpace(header.Get("X-Virus-ID")); id != "" {
+		return id, nil
+	}
+	// The second line of the first violation is the threat.
+	if lines := strings.Fields(header.Get("X-Violations-Found")); len(lines) > 2 {
+		return lines[2], nil
+	}
+	// The file was modified without telling why.
+	return "unknown", nil
+}
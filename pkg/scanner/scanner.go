@@ -0,0 +1,113 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package scanner scans files for malware with an external
+// ClamAV daemon or ICAP server before they are accepted.
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+)
+
+// chunkSize is the size of the chunks the files are streamed in.
+const chunkSize = 32 * 1024
+
+// InfectedError is returned if malware is found in a file.
+type InfectedError struct {
+	Name   string
+	Threat string
+}
+
+// Error implements [error].
+func (ie *InfectedError) Error() string {
+	return fmt.Sprintf("malware %q found in %q", ie.Threat, ie.Name)
+}
+
+// backend is a service to scan files with.
+type backend interface {
+	// scan returns the name of the found threat or
+	// the empty string if the file is clean.
+	scan(ctx context.Context, r io.Reader) (string, error)
+}
+
+// Scanner scans files for malware.
+// A nil scanner is valid and accepts all files.
+type Scanner struct {
+	cfg     *config.Scanner
+	backend backend
+}
+
+// NewScanner returns a new scanner. If no scanner is
+// configured nil is returned.
+func NewScanner(cfg *config.Scanner) (*Scanner, error) {
+	var (
+		b   backend
+		err error
+	)
+	switch cfg.Type {
+	case config.ScannerTypeClamd:
+		b, err = newClamd(cfg.Address)
+	case config.ScannerTypeICAP:
+		b, err = newICAP(cfg.Address)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating %s scanner failed: %w", cfg.Type, err)
+	}
+	return &Scanner{cfg: cfg, backend: b}, nil
+}
+
+// Scan scans the content of a file. The name is only used
+// for logging. If malware is found an [*InfectedError] is returned.
+// If the scan fails the file is only accepted if the scanner
+// is configured to fail open.
+func (s *Scanner) Scan(ctx context.Context, name string, r io.Reader) error {
+	if s == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+	threat, err := s.backend.scan(ctx, r)
+	switch {
+	case err != nil && s.cfg.FailOpen:
+		slog.WarnContext(ctx, "malware scan failed, accepting file",
+			"file", name, "scanner", s.cfg.Type, "error", err)
+		return nil
+	case err != nil:
+		return fmt.Errorf("malware scan failed: %w", err)
+	case threat != "":
+		slog.WarnContext(ctx, "malware found",
+			"file", name, "threat", threat, "scanner", s.cfg.Type)
+		return &InfectedError{Name: name, Threat: threat}
+	}
+	return nil
+}
+
+// dial connects to the scanning service and sets the deadline
+// of the connection to the one of the context.
+func dial(ctx context.Context, network, address string) (net.Conn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Time{})
+	}
+	return conn, nil
+}
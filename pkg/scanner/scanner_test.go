@@ -0,0 +1,176 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+)
+
+func TestParseClamdReply(t *testing.T) {
+	for _, x := range []struct {
+		reply  string
+		threat string
+		fail   bool
+	}{
+		{"stream: OK\x00", "", false},
+		{"stream: Eicar-Signature FOUND\x00", "Eicar-Signature", false},
+		{"INSTREAM size limit exceeded. ERROR", "", true},
+		{"stream: Can't allocate memory ERROR", "", true},
+		{"garbage", "", true},
+	} {
+		threat, err := parseClamdReply(x.reply)
+		if x.fail != (err != nil) {
+			t.Errorf("%q: unexpected error state: %v", x.reply, err)
+		}
+		if threat != x.threat {
+			t.Errorf("%q: got %q, want %q", x.reply, threat, x.threat)
+		}
+	}
+}
+
+func TestParseICAPResponse(t *testing.T) {
+	header := func(k, v string) textproto.MIMEHeader {
+		h := textproto.MIMEHeader{}
+		if k != "" {
+			h.Set(k, v)
+		}
+		return h
+	}
+	for _, x := range []struct {
+		status string
+		header textproto.MIMEHeader
+		threat string
+		fail   bool
+	}{
+		{"ICAP/1.0 204 No Content", header("", ""), "", false},
+		{"ICAP/1.0 200 OK", header("X-Infection-Found",
+			"Type=0; Resolution=2; Threat=Eicar-Test-Signature;"), "Eicar-Test-Signature", false},
+		{"ICAP/1.0 200 OK", header("X-Virus-ID", "EICAR"), "EICAR", false},
+		{"ICAP/1.0 200 OK", header("X-Violations-Found", "1 file.json EICAR 0 0"), "EICAR", false},
+		{"ICAP/1.0 200 OK", header("", ""), "unknown", false},
+		{"ICAP/1.0 500 Server Error", header("", ""), "", true},
+		{"HTTP/1.1 200 OK", header("", ""), "", true},
+	} {
+		threat, err := parseICAPResponse(x.status, x.header)
+		if x.fail != (err != nil) {
+			t.Errorf("%q: unexpected error state: %v", x.status, err)
+		}
+		if threat != x.threat {
+			t.Errorf("%q: got %q, want %q", x.status, threat, x.threat)
+		}
+	}
+}
+
+// fakeClamd answers INSTREAM commands and reports files
+// containing "EICAR" as infected.
+func fakeClamd(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				if cmd, err := r.ReadString(0); err != nil || cmd != "zINSTREAM\x00" {
+					return
+				}
+				var data bytes.Buffer
+				for {
+					var size uint32
+					if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+						return
+					}
+					if size == 0 {
+						break
+					}
+					if _, err := io.CopyN(&data, r, int64(size)); err != nil {
+						return
+					}
+				}
+				if strings.Contains(data.String(), "EICAR") {
+					io.WriteString(conn, "stream: Eicar-Signature FOUND\x00")
+				} else {
+					io.WriteString(conn, "stream: OK\x00")
+				}
+			}()
+		}
+	}()
+	return "tcp://" + l.Addr().String()
+}
+
+func TestClamdScan(t *testing.T) {
+	s, err := NewScanner(&config.Scanner{
+		Type:    config.ScannerTypeClamd,
+		Address: fakeClamd(t),
+		Timeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	big := strings.Repeat("x", 3*chunkSize+17)
+	if err := s.Scan(ctx, "clean", strings.NewReader(big)); err != nil {
+		t.Errorf("clean file: %v", err)
+	}
+	err = s.Scan(ctx, "infected", strings.NewReader(big+"EICAR"))
+	var ie *InfectedError
+	if !errors.As(err, &ie) || ie.Threat != "Eicar-Signature" {
+		t.Errorf("infected file: got %v", err)
+	}
+}
+
+func TestScannerFailOpen(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	// Nothing is listening any more.
+	addr := "tcp://" + l.Addr().String()
+	l.Close()
+	cfg := &config.Scanner{
+		Type:    config.ScannerTypeClamd,
+		Address: addr,
+		Timeout: time.Second,
+	}
+	s, err := NewScanner(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Scan(context.Background(), "file", strings.NewReader("x")); err == nil {
+		t.Error("fail closed: expected error")
+	}
+	cfg.FailOpen = true
+	if err := s.Scan(context.Background(), "file", strings.NewReader("x")); err != nil {
+		t.Errorf("fail open: unexpected error: %v", err)
+	}
+	var none *Scanner
+	if err := none.Scan(context.Background(), "file", strings.NewReader("x")); err != nil {
+		t.Errorf("nil scanner: unexpected error: %v", err)
+	}
+}
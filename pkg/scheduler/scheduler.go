@@ -0,0 +1,193 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package scheduler keeps track of the periodic background tasks.
+package scheduler
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Status is the state of a task.
+type Status struct {
+	Name         string        `json:"name"`
+	Description  string        `json:"description"`
+	Interval     time.Duration `json:"interval" swaggertype:"primitive,integer"`
+	Paused       bool          `json:"paused"`
+	Running      bool          `json:"running"`
+	Runs         int64         `json:"runs"`
+	LastRun      *time.Time    `json:"last_run,omitempty"`
+	NextRun      *time.Time    `json:"next_run,omitempty"`
+	LastDuration time.Duration `json:"last_duration" swaggertype:"primitive,integer"`
+	LastError    *string       `json:"last_error,omitempty"`
+	LastErrorAt  *time.Time    `json:"last_error_at,omitempty"`
+}
+
+// Task is a periodic background task.
+// The owner of the task reports the runs, the registry
+// allows to pause it and to request an immediate run.
+type Task struct {
+	name        string
+	description string
+	interval    time.Duration
+	trigger     chan struct{}
+
+	mu        sync.Mutex
+	paused    bool
+	running   bool
+	runs      int64
+	lastRun   time.Time
+	nextRun   time.Time
+	duration  time.Duration
+	lastErr   string
+	lastErrAt time.Time
+}
+
+// Registry is the collection of all tasks.
+type Registry struct {
+	mu    sync.Mutex
+	tasks []*Task
+}
+
+// NewRegistry returns a new registry.
+func NewRegistry() *Registry {
+	return new(Registry)
+}
+
+// Register adds a task to the registry.
+// On a nil registry the task is created but not listed.
+func (r *Registry) Register(name, description string, interval time.Duration) *Task {
+	t := &Task{
+		name:        name,
+		description: description,
+		interval:    interval,
+		trigger:     make(chan struct{}, 1),
+	}
+	if interval > 0 {
+		t.nextRun = time.Now().Add(interval)
+	}
+	if r != nil {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.tasks = append(r.tasks, t)
+	}
+	return t
+}
+
+// Task returns the task with the given name or nil if there is no such task.
+func (r *Registry) Task(name string) *Task {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if idx := slices.IndexFunc(r.tasks, func(t *Task) bool {
+		return t.name == name
+	}); idx != -1 {
+		return r.tasks[idx]
+	}
+	return nil
+}
+
+// Status returns the states of all tasks.
+func (r *Registry) Status() []Status {
+	r.mu.Lock()
+	tasks := slices.Clone(r.tasks)
+	r.mu.Unlock()
+	states := make([]Status, 0, len(tasks))
+	for _, t := range tasks {
+		states = append(states, t.Status())
+	}
+	return states
+}
+
+// Status returns the state of the task.
+func (t *Task) Status() Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	optTime := func(t time.Time) *time.Time {
+		if t.IsZero() {
+			return nil
+		}
+		t = t.UTC()
+		return &t
+	}
+	s := Status{
+		Name:         t.name,
+		Description:  t.description,
+		Interval:     t.interval,
+		Paused:       t.paused,
+		Running:      t.running,
+		Runs:         t.runs,
+		LastRun:      optTime(t.lastRun),
+		LastDuration: t.duration,
+		LastErrorAt:  optTime(t.lastErrAt),
+	}
+	if !t.paused {
+		s.NextRun = optTime(t.nextRun)
+	}
+	if t.lastErr != "" {
+		msg := t.lastErr
+		s.LastError = &msg
+	}
+	return s
+}
+
+// Paused returns true if the task is paused.
+func (t *Task) Paused() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.paused
+}
+
+// SetPaused pauses or resumes the task.
+func (t *Task) SetPaused(paused bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.paused = paused
+}
+
+// Trigger requests an immediate run of the task.
+// Returns false if there is already a pending request.
+func (t *Task) Trigger() bool {
+	select {
+	case t.trigger <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Triggered returns a channel which receives the requests for immediate runs.
+func (t *Task) Triggered() <-chan struct{} {
+	return t.trigger
+}
+
+// Start records the start of a run. The returned function
+// has to be called with the result of the run when it is done.
+func (t *Task) Start() func(error) {
+	start := time.Now()
+	t.mu.Lock()
+	t.running = true
+	t.lastRun = start
+	if t.interval > 0 {
+		t.nextRun = start.Add(t.interval)
+	}
+	t.mu.Unlock()
+	return func(err error) {
+		end := time.Now()
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.running = false
+		t.runs++
+		t.duration = end.Sub(start)
+		if err != nil {
+			t.lastErr = err.Error()
+			t.lastErrAt = end
+		}
+	}
+}
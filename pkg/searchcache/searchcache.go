@@ -0,0 +1,219 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package searchcache caches the results of expensive search queries.
+// The cache is invalidated by the database if documents are imported
+// or the workflow data of the advisories changes. Results depending
+// on the read states of users are invalidated if these change.
+package searchcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ISDuBA/ISDuBA/pkg/cache"
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+	"github.com/ISDuBA/ISDuBA/pkg/database"
+)
+
+// Cache caches search results. A nil cache is valid and caches nothing.
+type Cache struct {
+	cfg        *config.SearchCache
+	db         *database.DB
+	entries    *cache.ExpirationCache[string, []byte]
+	generation atomic.Uint64
+
+	readsMu sync.Mutex
+	reads   map[string]uint64
+}
+
+// Key identifies a search result in a given generation of the cache.
+type Key struct {
+	generation uint64
+	readers    map[string]uint64
+	digest     string
+}
+
+// NewCache returns a new search cache. If the cache is
+// not enabled in the configuration nil is returned.
+func NewCache(cfg *config.SearchCache, db *database.DB) *Cache {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &Cache{
+		cfg:     cfg,
+		db:      db,
+		entries: cache.NewExpirationCache[string, []byte](cfg.Expiration),
+		reads:   map[string]uint64{},
+	}
+}
+
+// Run listens for changes in the database and cleans up
+// expired entries. To be used in a Go routine.
+func (c *Cache) Run(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	go c.db.Listen(ctx, database.SearchChangesChannel, c.Invalidate)
+	go c.db.ListenPayloads(ctx, database.SearchReadsChangesChannel,
+		c.InvalidateReads, c.Invalidate)
+	ticker := time.NewTicker(c.cfg.Expiration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.entries.Cleanup()
+		}
+	}
+}
+
+// Invalidate drops all cached results.
+func (c *Cache) Invalidate() {
+	if c == nil {
+		return
+	}
+	c.generation.Add(1)
+	c.entries.Clear()
+	c.readsMu.Lock()
+	clear(c.reads)
+	c.readsMu.Unlock()
+	slog.Debug("search cache invalidated")
+}
+
+// InvalidateReads drops the cached results which depend
+// on the read states of the given user.
+func (c *Cache) InvalidateReads(user string) {
+	if c == nil {
+		return
+	}
+	c.readsMu.Lock()
+	c.reads[user]++
+	c.readsMu.Unlock()
+	slog.Debug("search cache invalidated", "reader", user)
+}
+
+// Len returns the number of cached results.
+func (c *Cache) Len() int {
+	if c == nil {
+		return 0
+	}
+	return c.entries.Len()
+}
+
+// Key returns the key for the given parts of a normalized query.
+// The parts have to include everything which influences the result
+// like the visibility of the documents for the user.
+func (c *Cache) Key(parts ...any) Key {
+	if c == nil {
+		return Key{}
+	}
+	return c.key(nil, parts)
+}
+
+// ReadsKey is like [Cache.Key] for results which depend
+// on the read states of the given users, too.
+func (c *Cache) ReadsKey(readers []string, parts ...any) Key {
+	if c == nil {
+		return Key{}
+	}
+	if len(readers) == 0 {
+		return c.key(nil, parts)
+	}
+	gens := make(map[string]uint64, len(readers))
+	c.readsMu.Lock()
+	for _, reader := range readers {
+		gens[reader] = c.reads[reader]
+	}
+	c.readsMu.Unlock()
+	return c.key(gens, parts)
+}
+
+func (c *Cache) key(readers map[string]uint64, parts []any) Key {
+	generation := c.generation.Load()
+	h := sha256.New()
+	for _, part := range parts {
+		fmt.Fprintf(h, "%#v\x00", part)
+	}
+	// Results of older read states must not be found.
+	for _, reader := range slices.Sorted(maps.Keys(readers)) {
+		fmt.Fprintf(h, "%q:%d\x00", reader, readers[reader])
+	}
+	return Key{
+		generation: generation,
+		readers:    readers,
+		digest:     hex.EncodeToString(h.Sum(nil)),
+	}
+}
+
+// current checks if the key belongs to the current generation
+// and the read states it depends on are unchanged.
+func (c *Cache) current(k Key) bool {
+	if k.generation != c.generation.Load() {
+		return false
+	}
+	if len(k.readers) == 0 {
+		return true
+	}
+	c.readsMu.Lock()
+	defer c.readsMu.Unlock()
+	for reader, gen := range k.readers {
+		if c.reads[reader] != gen {
+			return false
+		}
+	}
+	return true
+}
+
+func (k Key) String() string {
+	return strconv.FormatUint(k.generation, 10) + ":" + k.digest
+}
+
+// Get returns the cached result for the given key.
+func (c *Cache) Get(k Key) ([]byte, bool) {
+	if c == nil || !c.current(k) {
+		return nil, false
+	}
+	return c.entries.Get(k.String())
+}
+
+// Set stores a result for the given key. Results which are
+// too large or were calculated before the last invalidation
+// are not stored.
+func (c *Cache) Set(k Key, data []byte) {
+	if c == nil ||
+		int64(len(data)) > int64(c.cfg.MaxEntrySize) ||
+		!c.current(k) {
+		return
+	}
+	if c.entries.Len() >= c.cfg.MaxEntries {
+		c.entries.Cleanup()
+		if c.entries.Len() >= c.cfg.MaxEntries {
+			return
+		}
+	}
+	c.entries.Set(k.String(), data)
+}
+
+// MaxEntrySize returns the size of the largest result to be cached.
+func (c *Cache) MaxEntrySize() int64 {
+	if c == nil {
+		return 0
+	}
+	return int64(c.cfg.MaxEntrySize)
+}
@@ -0,0 +1,58 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package searchcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+)
+
+func TestInvalidateReads(t *testing.T) {
+	c := NewCache(&config.SearchCache{
+		Enabled:      true,
+		Expiration:   time.Hour,
+		MaxEntries:   10,
+		MaxEntrySize: 1024,
+	}, nil)
+
+	plain := c.Key("plain")
+	alice := c.ReadsKey([]string{"alice"}, "seen")
+	bob := c.ReadsKey([]string{"bob"}, "seen")
+	c.Set(plain, []byte("p"))
+	c.Set(alice, []byte("a"))
+	c.Set(bob, []byte("b"))
+
+	c.InvalidateReads("alice")
+
+	if _, ok := c.Get(plain); !ok {
+		t.Error("result without read states was invalidated")
+	}
+	if _, ok := c.Get(bob); !ok {
+		t.Error("result of other reader was invalidated")
+	}
+	if _, ok := c.Get(alice); ok {
+		t.Error("result of changed reader is still cached")
+	}
+	// The old result must not be found with the new key.
+	alice = c.ReadsKey([]string{"alice"}, "seen")
+	if _, ok := c.Get(alice); ok {
+		t.Error("old result found with new key")
+	}
+	c.Set(alice, []byte("a2"))
+	if data, ok := c.Get(alice); !ok || string(data) != "a2" {
+		t.Errorf("unexpected result %q %t", data, ok)
+	}
+
+	c.Invalidate()
+	if _, ok := c.Get(bob); ok {
+		t.Error("result is cached after invalidation")
+	}
+}
@@ -0,0 +1,170 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package siem
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+)
+
+const (
+	vendor  = "ISDuBA"
+	product = "ISDuBA"
+)
+
+// Field is a named value of a record.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// Record is an entry of the audit or event history.
+type Record struct {
+	// Stream is the kind of the record.
+	Stream string
+	// ID is the number of the record within its stream.
+	ID   int64
+	Time time.Time
+	// Name identifies the kind of event like "state_change".
+	Name string
+	// Severity is the importance of the event from 0 to 10.
+	Severity int
+	// Fields are the details of the event.
+	Fields []Field
+}
+
+// add appends a field if the value is not empty.
+func (r *Record) add(key, value string) {
+	if value != "" {
+		r.Fields = append(r.Fields, Field{Key: key, Value: value})
+	}
+}
+
+// defaultMappings are the keys of the fields in the
+// formats if they are not configured otherwise.
+var defaultMappings = map[config.SIEMFormat]map[string]string{
+	config.SIEMFormatCEF: {
+		"actor":   "suser",
+		"message": "msg",
+	},
+	config.SIEMFormatLEEF: {
+		"actor":   "usrName",
+		"message": "msg",
+	},
+}
+
+// Formatter turns records into messages of a SIEM format.
+type Formatter struct {
+	format  config.SIEMFormat
+	version string
+	mapping map[string]string
+}
+
+// NewFormatter returns a formatter for the given format.
+// The mapping renames the fields of the records. Fields
+// mapped to an empty string are left out.
+func NewFormatter(format config.SIEMFormat, version string, mapping map[string]string) *Formatter {
+	m := make(map[string]string, len(defaultMappings[format])+len(mapping))
+	for k, v := range defaultMappings[format] {
+		m[k] = v
+	}
+	for k, v := range mapping {
+		m[k] = v
+	}
+	return &Formatter{format: format, version: version, mapping: m}
+}
+
+// Format returns the record as a message in the format of the formatter.
+func (f *Formatter) Format(r *Record) string {
+	if f.format == config.SIEMFormatLEEF {
+		return f.leef(r)
+	}
+	return f.cef(r)
+}
+
+// key returns the mapped key of a field.
+func (f *Formatter) key(name string) string {
+	if key, ok := f.mapping[name]; ok {
+		return key
+	}
+	return name
+}
+
+// title returns a human readable name of the event.
+func title(name string) string {
+	return strings.ReplaceAll(name, "_", " ")
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+	leefHeaderEscaper   = strings.NewReplacer(`|`, ` `, "\n", " ", "\r", " ")
+	leefValueEscaper    = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+)
+
+// cef formats the record in the Common Event Format.
+func (f *Formatter) cef(r *Record) string {
+	var b strings.Builder
+	b.WriteString("CEF:0|")
+	for _, s := range []string{vendor, product, f.version, r.Name, title(r.Name)} {
+		b.WriteString(cefHeaderEscaper.Replace(s))
+		b.WriteByte('|')
+	}
+	b.WriteString(strconv.Itoa(r.Severity))
+	b.WriteString("|rt=")
+	b.WriteString(strconv.FormatInt(r.Time.UnixMilli(), 10))
+	b.WriteString(" externalId=")
+	b.WriteString(r.Stream + "/" + strconv.FormatInt(r.ID, 10))
+	for _, field := range r.Fields {
+		if key := f.key(field.Key); key != "" {
+			b.WriteByte(' ')
+			b.WriteString(key)
+			b.WriteByte('=')
+			b.WriteString(cefExtensionEscaper.Replace(field.Value))
+		}
+	}
+	return b.String()
+}
+
+// leefTimeFormat is the format of the device time in LEEF messages.
+const leefTimeFormat = "yyyy-MM-dd'T'HH:mm:ss.SSSZ"
+
+// leef formats the record in the Log Event Extended Format.
+func (f *Formatter) leef(r *Record) string {
+	var b strings.Builder
+	b.WriteString("LEEF:1.0|")
+	for _, s := range []string{vendor, product, f.version, r.Name} {
+		b.WriteString(leefHeaderEscaper.Replace(s))
+		b.WriteByte('|')
+	}
+	attr := func(key, value string) {
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(leefValueEscaper.Replace(value))
+	}
+	attr("devTime", r.Time.UTC().Format("2006-01-02T15:04:05.000-0700"))
+	b.WriteByte('\t')
+	attr("devTimeFormat", leefTimeFormat)
+	b.WriteByte('\t')
+	attr("sev", strconv.Itoa(max(r.Severity, 1)))
+	b.WriteByte('\t')
+	attr("cat", r.Stream)
+	b.WriteByte('\t')
+	attr("externalId", r.Stream+"/"+strconv.FormatInt(r.ID, 10))
+	for _, field := range r.Fields {
+		if key := f.key(field.Key); key != "" {
+			b.WriteByte('\t')
+			attr(key, field.Value)
+		}
+	}
+	return b.String()
+}
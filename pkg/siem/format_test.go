@@ -0,0 +1,61 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package siem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+)
+
+func testRecord() *Record {
+	r := &Record{
+		Stream:   "source_actions",
+		ID:       42,
+		Time:     time.Date(2026, 5, 4, 3, 2, 1, 0, time.UTC),
+		Name:     "source_pin_mismatch",
+		Severity: 8,
+	}
+	r.add("actor", "")
+	r.add("source", "a|b=c")
+	r.add("message", "line1\nline2\tend")
+	return r
+}
+
+func TestFormatCEF(t *testing.T) {
+	f := NewFormatter(config.SIEMFormatCEF, "1.2.3", map[string]string{"source": "cs1"})
+	got := f.Format(testRecord())
+	want := `CEF:0|ISDuBA|ISDuBA|1.2.3|source_pin_mismatch|source pin mismatch|8|` +
+		`rt=1777863721000 externalId=source_actions/42 cs1=a|b\=c msg=line1\nline2` + "\tend"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormatLEEF(t *testing.T) {
+	f := NewFormatter(config.SIEMFormatLEEF, "1.2.3", map[string]string{"message": ""})
+	got := f.Format(testRecord())
+	want := "LEEF:1.0|ISDuBA|ISDuBA|1.2.3|source_pin_mismatch|" +
+		"devTime=2026-05-04T03:02:01.000+0000\t" +
+		"devTimeFormat=yyyy-MM-dd'T'HH:mm:ss.SSSZ\t" +
+		"sev=8\tcat=source_actions\texternalId=source_actions/42\tsource=a|b=c"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSyslogMessage(t *testing.T) {
+	msg := message{time: time.Date(2026, 5, 4, 3, 2, 1, 0, time.UTC), text: "CEF:0|x"}
+	got := octetCounted(syslogMessage("host", msg))
+	want := "53 <110>1 2026-05-04T03:02:01Z host isduba - - - CEF:0|x"
+	if got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+}
@@ -0,0 +1,150 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package siem
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// syslogPriority is the priority of the syslog messages:
+// facility log audit (13) and severity informational (6).
+const syslogPriority = 13*8 + 6
+
+// message is a formatted record.
+type message struct {
+	time time.Time
+	text string
+}
+
+// sender transmits messages to the SIEM.
+type sender interface {
+	send(ctx context.Context, msgs []message) error
+}
+
+// syslogSender sends the messages as syslog messages (RFC 5424).
+type syslogSender struct {
+	network  string
+	address  string
+	hostname string
+	timeout  time.Duration
+}
+
+// httpSender posts the messages to an HTTP event collector.
+type httpSender struct {
+	url        string
+	token      string
+	sourceType string
+	client     *http.Client
+}
+
+// syslogMessage frames the text as a syslog message.
+func syslogMessage(hostname string, msg message) string {
+	return "<" + strconv.Itoa(syslogPriority) + ">1 " +
+		msg.time.UTC().Format(time.RFC3339Nano) + " " +
+		hostname + " isduba - - - " + msg.text
+}
+
+// octetCounted frames a syslog message for stream
+// transports as described in RFC 6587.
+func octetCounted(s string) string {
+	return strconv.Itoa(len(s)) + " " + s
+}
+
+func (ss *syslogSender) send(ctx context.Context, msgs []message) error {
+	dialer := net.Dialer{Timeout: ss.timeout}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if ss.network == "tls" {
+		td := tls.Dialer{NetDialer: &dialer}
+		conn, err = td.DialContext(ctx, "tcp", ss.address)
+	} else {
+		conn, err = dialer.DialContext(ctx, ss.network, ss.address)
+	}
+	if err != nil {
+		return fmt.Errorf("connecting to syslog failed: %w", err)
+	}
+	defer conn.Close()
+	if ss.network == "udp" {
+		// Every datagram carries one message.
+		for _, msg := range msgs {
+			if _, err := io.WriteString(conn, syslogMessage(ss.hostname, msg)); err != nil {
+				return fmt.Errorf("sending to syslog failed: %w", err)
+			}
+		}
+		return nil
+	}
+	if ss.timeout > 0 {
+		conn.SetWriteDeadline(time.Now().Add(ss.timeout))
+	}
+	w := bufio.NewWriter(conn)
+	for _, msg := range msgs {
+		if _, err := w.WriteString(octetCounted(syslogMessage(ss.hostname, msg))); err != nil {
+			return fmt.Errorf("sending to syslog failed: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("sending to syslog failed: %w", err)
+	}
+	return nil
+}
+
+// collectorEvent is an event in the format of an HTTP event collector.
+type collectorEvent struct {
+	Time       float64 `json:"time"`
+	SourceType string  `json:"sourcetype"`
+	Source     string  `json:"source"`
+	Event      string  `json:"event"`
+}
+
+func (hs *httpSender) send(ctx context.Context, msgs []message) error {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, msg := range msgs {
+		if err := enc.Encode(collectorEvent{
+			Time:       float64(msg.time.UnixMilli()) / 1000,
+			SourceType: hs.sourceType,
+			Source:     "isduba",
+			Event:      msg.text,
+		}); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hs.url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if hs.token != "" {
+		req.Header.Set("Authorization", "Splunk "+hs.token)
+	}
+	resp, err := hs.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting to event collector failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("event collector returned %q: %s",
+			resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package siem exports the audit and event history to a
+// security information and event management (SIEM).
+package siem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+	"github.com/ISDuBA/ISDuBA/pkg/database"
+	"github.com/ISDuBA/ISDuBA/pkg/scheduler"
+	"github.com/ISDuBA/ISDuBA/pkg/version"
+)
+
+// settleTime is how old a record has to be to be exported.
+// Records are numbered when they are inserted but become visible
+// when their transaction commits, so the youngest records
+// may still be preceded by records not visible yet.
+const settleTime = 10 * time.Second
+
+// Exporter exports the records of the audit and event
+// history to a SIEM in the order they were recorded.
+// A nil exporter is valid and does nothing.
+type Exporter struct {
+	cfg       *config.SIEM
+	db        *database.DB
+	task      *scheduler.Task
+	formatter *Formatter
+	sender    sender
+}
+
+// NewExporter returns a new exporter. If the export is
+// not enabled in the configuration nil is returned.
+func NewExporter(cfg *config.Config, db *database.DB, tasks *scheduler.Registry) *Exporter {
+	if !cfg.SIEM.Enabled {
+		return nil
+	}
+	var snd sender
+	scheme, rest, _ := strings.Cut(cfg.SIEM.Address, "://")
+	switch scheme {
+	case "http", "https":
+		client := &http.Client{Transport: cfg.General.Transport()}
+		if cfg.SIEM.Timeout > 0 {
+			client.Timeout = cfg.SIEM.Timeout
+		}
+		snd = &httpSender{
+			url:        cfg.SIEM.Address,
+			token:      cfg.SIEM.Token,
+			sourceType: cfg.SIEM.Format.String(),
+			client:     client,
+		}
+	default:
+		hostname, err := os.Hostname()
+		if err != nil || hostname == "" {
+			hostname = "-"
+		}
+		snd = &syslogSender{
+			network:  scheme,
+			address:  rest,
+			hostname: hostname,
+			timeout:  cfg.SIEM.Timeout,
+		}
+	}
+	return &Exporter{
+		cfg:       &cfg.SIEM,
+		db:        db,
+		formatter: NewFormatter(cfg.SIEM.Format, version.SemVersion, cfg.SIEM.Fields),
+		sender:    snd,
+		task: tasks.Register("siem",
+			"Exports the audit and event history to the SIEM.",
+			cfg.SIEM.Interval),
+	}
+}
+
+// Run exports the new records periodically. To be used in a Go routine.
+func (e *Exporter) Run(ctx context.Context) {
+	if e == nil {
+		return
+	}
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if e.db.Available() && !e.task.Paused() {
+				e.scheduledExport(ctx)
+			}
+		case <-e.task.Triggered():
+			e.scheduledExport(ctx)
+		}
+	}
+}
+
+// scheduledExport exports and records the run in the scheduler task.
+func (e *Exporter) scheduledExport(ctx context.Context) {
+	done := e.task.Start()
+	var errs []error
+	for i := range streams {
+		if err := e.exportStream(ctx, &streams[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	done(errors.Join(errs...))
+}
+
+// exportStream exports the records of a stream recorded
+// since the last export in batches.
+func (e *Exporter) exportStream(ctx context.Context, s *stream) error {
+	const (
+		cursorSQL  = `SELECT last_id FROM siem_cursors WHERE stream = $1`
+		advanceSQL = `INSERT INTO siem_cursors (stream, last_id) VALUES ($1, $2) ` +
+			`ON CONFLICT (stream) DO UPDATE SET last_id = $2, changed = CURRENT_TIMESTAMP`
+	)
+	var lastID int64
+	if err := e.db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+		err := conn.QueryRow(rctx, cursorSQL, s.name).Scan(&lastID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}, 0); err != nil {
+		return fmt.Errorf("loading cursor of %s failed: %w", s.name, err)
+	}
+	before := time.Now().Add(-settleTime)
+	for ctx.Err() == nil {
+		var records []Record
+		if err := e.db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+			var err error
+			records, err = s.fetch(rctx, conn, lastID, e.cfg.BatchSize, before)
+			return err
+		}, 0); err != nil {
+			return fmt.Errorf("loading %s failed: %w", s.name, err)
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		msgs := make([]message, len(records))
+		for i := range records {
+			msgs[i] = message{time: records[i].Time, text: e.formatter.Format(&records[i])}
+		}
+		if err := e.sender.send(ctx, msgs); err != nil {
+			// The records are sent again with the next run.
+			return fmt.Errorf("exporting %s failed: %w", s.name, err)
+		}
+		lastID = records[len(records)-1].ID
+		if err := e.db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+			_, err := conn.Exec(rctx, advanceSQL, s.name, lastID)
+			return err
+		}, 0); err != nil {
+			return fmt.Errorf("storing cursor of %s failed: %w", s.name, err)
+		}
+		slog.Debug("exported to SIEM", "stream", s.name, "records", len(records))
+		if len(records) < e.cfg.BatchSize {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
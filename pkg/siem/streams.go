@@ -0,0 +1,204 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package siem
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// stream is a kind of record which is exported.
+type stream struct {
+	name string
+	// fetchSQL selects the records with an id greater than $1
+	// created before $3 ordered by id limited to $2 records.
+	fetchSQL string
+	scan     func(pgx.CollectableRow) (Record, error)
+}
+
+// severities are the severities of the events which
+// are more important than the default.
+var severities = map[string]int{
+	"delete_document":       5,
+	"delete_comment":        5,
+	"delete_sscv":           5,
+	"reject_state_change":   4,
+	"share_document":        5,
+	"revoke_share":          4,
+	"access_share":          5,
+	"legal_hold":            4,
+	"release_legal_hold":    5,
+	"sla_escalation":        5,
+	"download_document":     4,
+	"source_deactivated":    7,
+	"source_attention":      4,
+	"source_pin_mismatch":   8,
+	"acknowledge_attention": 3,
+}
+
+// defaultSeverity is the severity of the events not listed in severities.
+const defaultSeverity = 3
+
+// severity returns the severity of an event.
+func severity(name string) int {
+	if sev, ok := severities[name]; ok {
+		return sev
+	}
+	return defaultSeverity
+}
+
+// formatID returns the id as a string or an empty string if not set.
+func formatID(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
+
+// str returns the string or an empty string if not set.
+func str(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// streams are the exported kinds of records.
+var streams = []stream{{
+	name: "events",
+	fetchSQL: `SELECT ev.id, ev.time, ev.event::text, ev.actor, ev.state::text, ` +
+		`ev.documents_id, ev.comments_id, ads.publisher, ads.tracking_id, docs.version, docs.tlp, ` +
+		`ev.message ` +
+		`FROM events_log ev ` +
+		`LEFT JOIN documents docs ON ev.documents_id = docs.id ` +
+		`LEFT JOIN advisories ads ON docs.advisories_id = ads.id ` +
+		`WHERE ev.id > $1 AND ev.time < $3 ORDER BY ev.id LIMIT $2`,
+	scan: func(row pgx.CollectableRow) (Record, error) {
+		var (
+			r                                           = Record{Stream: "events"}
+			actor, state, publisher, trackingID, v, tlp *string
+			message                                     *string
+			documentID, commentID                       *int64
+		)
+		if err := row.Scan(
+			&r.ID, &r.Time, &r.Name, &actor, &state,
+			&documentID, &commentID, &publisher, &trackingID, &v, &tlp,
+			&message,
+		); err != nil {
+			return r, err
+		}
+		r.Severity = severity(r.Name)
+		r.add("actor", str(actor))
+		r.add("state", str(state))
+		r.add("document_id", formatID(documentID))
+		r.add("publisher", str(publisher))
+		r.add("tracking_id", str(trackingID))
+		r.add("version", str(v))
+		r.add("tlp", str(tlp))
+		r.add("comment_id", formatID(commentID))
+		r.add("message", str(message))
+		return r, nil
+	},
+}, {
+	name: "accesses",
+	fetchSQL: `SELECT id, time, kind, actor, documents_id, publisher, tracking_id, version, tlp ` +
+		`FROM document_accesses ` +
+		`WHERE id > $1 AND time < $3 ORDER BY id LIMIT $2`,
+	scan: func(row pgx.CollectableRow) (Record, error) {
+		var (
+			r                           = Record{Stream: "accesses"}
+			kind, publisher, trackingID string
+			v, tlp                      string
+			actor                       *string
+			documentID                  *int64
+		)
+		if err := row.Scan(
+			&r.ID, &r.Time, &kind, &actor, &documentID,
+			&publisher, &trackingID, &v, &tlp,
+		); err != nil {
+			return r, err
+		}
+		r.Name = kind + "_document"
+		if kind == "share" {
+			r.Name = "access_share"
+		}
+		r.Severity = severity(r.Name)
+		r.add("actor", str(actor))
+		r.add("document_id", formatID(documentID))
+		r.add("publisher", publisher)
+		r.add("tracking_id", trackingID)
+		r.add("version", v)
+		r.add("tlp", tlp)
+		return r, nil
+	},
+}, {
+	name: "source_actions",
+	fetchSQL: `SELECT sa.id, sa.time, sa.action, sa.sources_id, s.name, sa.message ` +
+		`FROM source_actions sa JOIN sources s ON sa.sources_id = s.id ` +
+		`WHERE sa.id > $1 AND sa.time < $3 ORDER BY sa.id LIMIT $2`,
+	scan: func(row pgx.CollectableRow) (Record, error) {
+		var (
+			r                     = Record{Stream: "source_actions"}
+			action, name, message string
+			sourceID              int64
+		)
+		if err := row.Scan(&r.ID, &r.Time, &action, &sourceID, &name, &message); err != nil {
+			return r, err
+		}
+		r.Name = "source_" + action
+		r.Severity = severity(r.Name)
+		r.add("source_id", formatID(&sourceID))
+		r.add("source", name)
+		r.add("message", message)
+		return r, nil
+	},
+}, {
+	name: "attention_acks",
+	fetchSQL: `SELECT id, time, actor, kind, sources_id, aggregators_id, name, changed ` +
+		`FROM attention_acks ` +
+		`WHERE id > $1 AND time < $3 ORDER BY id LIMIT $2`,
+	scan: func(row pgx.CollectableRow) (Record, error) {
+		var (
+			r                      = Record{Stream: "attention_acks", Name: "acknowledge_attention"}
+			actor, kind, name      string
+			sourceID, aggregatorID *int64
+			changed                time.Time
+		)
+		if err := row.Scan(
+			&r.ID, &r.Time, &actor, &kind, &sourceID, &aggregatorID, &name, &changed,
+		); err != nil {
+			return r, err
+		}
+		r.Severity = severity(r.Name)
+		r.add("actor", actor)
+		r.add("kind", kind)
+		r.add("source_id", formatID(sourceID))
+		r.add("aggregator_id", formatID(aggregatorID))
+		r.add("name", name)
+		r.add("changed", changed.UTC().Format(time.RFC3339))
+		return r, nil
+	},
+}}
+
+// fetch loads the next records of the stream after the given id.
+// Only records created before the given time are loaded.
+func (s *stream) fetch(
+	ctx context.Context,
+	conn *pgxpool.Conn,
+	after int64,
+	limit int,
+	before time.Time,
+) ([]Record, error) {
+	rows, _ := conn.Query(ctx, s.fetchSQL, after, limit, before)
+	return pgx.CollectRows(rows, s.scan)
+}
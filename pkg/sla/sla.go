@@ -0,0 +1,160 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package sla escalates the advisories which stay longer
+// in a workflow state than allowed by the configured limits.
+package sla
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+	"github.com/ISDuBA/ISDuBA/pkg/database"
+	"github.com/ISDuBA/ISDuBA/pkg/eventbus"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/scheduler"
+)
+
+// Checker looks for overdue advisories periodically.
+// A nil checker is valid and does nothing.
+type Checker struct {
+	cfg  *config.SLA
+	db   *database.DB
+	bus  *eventbus.Bus
+	task *scheduler.Task
+}
+
+// NewChecker returns a new checker. If no limits
+// are configured nil is returned.
+func NewChecker(
+	cfg *config.Config,
+	db *database.DB,
+	bus *eventbus.Bus,
+	tasks *scheduler.Registry,
+) *Checker {
+	if len(cfg.SLA.Limits) == 0 {
+		return nil
+	}
+	return &Checker{
+		cfg: &cfg.SLA,
+		db:  db,
+		bus: bus,
+		task: tasks.Register("sla",
+			"Escalates advisories staying longer in a workflow state than allowed.",
+			cfg.SLA.Interval),
+	}
+}
+
+// Run checks the advisories periodically. To be used in a Go routine.
+func (c *Checker) Run(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if c.db.Available() && !c.task.Paused() {
+				c.scheduledCheck(ctx)
+			}
+		case <-c.task.Triggered():
+			c.scheduledCheck(ctx)
+		}
+	}
+}
+
+// scheduledCheck checks and records the run in the scheduler task.
+func (c *Checker) scheduledCheck(ctx context.Context) {
+	done := c.task.Start()
+	done(c.check(ctx))
+}
+
+// Limits returns the states with limits ordered
+// by name and their limits in seconds.
+func Limits(cfg *config.SLA) ([]string, []float64) {
+	states := make([]string, 0, len(cfg.Limits))
+	for state := range cfg.Limits {
+		states = append(states, state)
+	}
+	slices.Sort(states)
+	seconds := make([]float64, len(states))
+	for i, state := range states {
+		seconds[i] = cfg.Limits[state].Seconds()
+	}
+	return states, seconds
+}
+
+// escalateSQL records the advisories which are longer in their
+// current state than allowed and logs the escalations for the
+// latest documents of the advisories. Every stay in a state
+// is only escalated once.
+const escalateSQL = `WITH limits (state, seconds) AS (` +
+	`SELECT * FROM unnest($1::text[], $2::float8[])), ` +
+	`escalated AS (` +
+	`INSERT INTO sla_escalations (advisories_id, state, entered, deadline) ` +
+	`SELECT sh.advisories_id, sh.state, sh.valid_from, ` +
+	`sh.valid_from + limits.seconds * interval '1 second' ` +
+	`FROM state_history sh JOIN limits ON sh.state::text = limits.state ` +
+	`WHERE sh.valid_to IS NULL ` +
+	`AND sh.valid_from + limits.seconds * interval '1 second' < current_timestamp ` +
+	`ON CONFLICT (advisories_id, state, entered) DO NOTHING ` +
+	`RETURNING advisories_id, state), ` +
+	`latest AS (` +
+	`SELECT escalated.state, docs.id AS documents_id FROM escalated ` +
+	`JOIN documents docs ON docs.advisories_id = escalated.advisories_id AND docs.latest), ` +
+	`logged AS (` +
+	`INSERT INTO events_log (event, state, documents_id) ` +
+	`SELECT 'sla_escalation', state, documents_id FROM latest) ` +
+	`SELECT state::text, documents_id FROM latest`
+
+// escalation is an escalated stay of an advisory.
+type escalation struct {
+	state      string
+	documentID int64
+}
+
+// check escalates the overdue advisories.
+func (c *Checker) check(ctx context.Context) error {
+	states, seconds := Limits(c.cfg)
+	var escalations []escalation
+	if err := c.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, escalateSQL, states, seconds)
+			var err error
+			escalations, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (escalation, error) {
+				var e escalation
+				err := row.Scan(&e.state, &e.documentID)
+				return e, err
+			})
+			return err
+		}, 0,
+	); err != nil {
+		return err
+	}
+	if len(escalations) > 0 {
+		slog.Info("escalated overdue advisories", "count", len(escalations))
+	}
+	for _, e := range escalations {
+		c.bus.Publish(eventbus.Event{
+			Type:       models.SLAEscalationEvent,
+			DocumentID: e.documentID,
+			State:      e.state,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sla
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+)
+
+func TestLimits(t *testing.T) {
+	states, seconds := Limits(&config.SLA{Limits: map[string]time.Duration{
+		"read": 2 * time.Hour,
+		"new":  48 * time.Hour,
+	}})
+	if want := []string{"new", "read"}; !slices.Equal(states, want) {
+		t.Errorf("states: have %q expected %q", states, want)
+	}
+	if want := []float64{48 * 3600, 2 * 3600}; !slices.Equal(seconds, want) {
+		t.Errorf("seconds: have %v expected %v", seconds, want)
+	}
+	if states, seconds := Limits(&config.SLA{}); len(states) != 0 || len(seconds) != 0 {
+		t.Errorf("no limits: have %q %v", states, seconds)
+	}
+}
@@ -0,0 +1,62 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SourceAction is a state change the system performs automatically on a source.
+type SourceAction string
+
+const (
+	// DeactivatedAction is recorded if a source is deactivated
+	// because its credentials or trust settings are unusable.
+	DeactivatedAction SourceAction = "deactivated"
+	// AttentionAction is recorded if the attention flag of
+	// a source is raised.
+	AttentionAction SourceAction = "attention"
+	// PinMismatchAction is recorded if the server presents a
+	// certificate which is not pinned for the source.
+	PinMismatchAction SourceAction = "pin_mismatch"
+)
+
+// insertSourceActionSQL records an automated action on a source.
+const insertSourceActionSQL = `INSERT INTO source_actions ` +
+	`(sources_id, action, message) VALUES ($1, $2, $3)`
+
+// recordAction stores an automated action performed on a source.
+func (m *Manager) recordAction(ctx context.Context, sourceID int64, action SourceAction, message string) {
+	if err := m.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			_, err := conn.Exec(rctx, insertSourceActionSQL, sourceID, string(action), message)
+			return err
+		}, 0,
+	); err != nil {
+		slog.Error("recording source action failed",
+			"id", sourceID, "action", action, "err", err)
+	}
+}
+
+// pinMismatchAlert returns a function which records a pin mismatch of
+// the source once per presented certificate.
+func (s *source) pinMismatchAlert(m *Manager) func(string) {
+	return func(fingerprint string) {
+		if old := s.pinMismatch.Swap(&fingerprint); old != nil && *old == fingerprint {
+			return
+		}
+		slog.Warn("server certificate is not pinned", "id", s.id, "fingerprint", fingerprint)
+		go m.recordAction(context.Background(), s.id, PinMismatchAction,
+			"Server presented certificate "+fingerprint+" which is not pinned.")
+	}
+}
@@ -0,0 +1,227 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+)
+
+const (
+	// silenceAnomaly is a day without imports from a source
+	// which usually delivers documents.
+	silenceAnomaly = "silence"
+	// spikeAnomaly is a day with many more imports than usual.
+	spikeAnomaly = "spike"
+)
+
+const oneDay = 24 * time.Hour
+
+// importAnomaly is a day on which the number of imports
+// of a source deviated strongly from the usual.
+type importAnomaly struct {
+	sourceID int64
+	day      time.Time
+	kind     string
+	imports  int64
+	average  float64
+}
+
+// message describes the anomaly for the source actions.
+func (ia *importAnomaly) message() string {
+	date := ia.day.Format(time.DateOnly)
+	if ia.kind == silenceAnomaly {
+		return fmt.Sprintf(
+			"No documents imported on %s, usually %.1f per day.", date, ia.average)
+	}
+	return fmt.Sprintf(
+		"%d documents imported on %s, usually %.1f per day.", ia.imports, date, ia.average)
+}
+
+// detectAnomaly judges the number of imports of a day against the
+// numbers of the days before, which are given oldest first starting
+// with the first day with imports. It returns the kind of the
+// anomaly or an empty string and the average of the days before.
+func detectAnomaly(cfg *config.Anomalies, history []int64, imports int64) (string, float64) {
+	if len(history) < cfg.MinHistory {
+		return "", 0
+	}
+	var sum int64
+	for _, n := range history {
+		sum += n
+	}
+	average := float64(sum) / float64(len(history))
+	switch {
+	case imports == 0 && average >= cfg.SilenceAverage:
+		return silenceAnomaly, average
+	case imports >= int64(cfg.MinSpike) && float64(imports) > cfg.SpikeFactor*average:
+		return spikeAnomaly, average
+	}
+	return "", average
+}
+
+// dailyHistory returns the numbers of imports of the days
+// from the first day with imports up to the last day.
+// The given counts are indexed by the number of days since start.
+func dailyHistory(counts map[int]int64, days int) []int64 {
+	first := -1
+	for d := range days {
+		if counts[d] > 0 {
+			first = d
+			break
+		}
+	}
+	if first < 0 {
+		return nil
+	}
+	history := make([]int64, 0, days-first)
+	for d := first; d < days; d++ {
+		history = append(history, counts[d])
+	}
+	return history
+}
+
+// enableAnomalyDetection allows the next run of the anomaly detection.
+func (m *Manager) enableAnomalyDetection(context.Context) {
+	m.blockAnomalyDetection = false
+}
+
+// detectAnomalies checks the import volumes of the active sources
+// of the last complete day in the background.
+func (m *Manager) detectAnomalies(ctx context.Context) {
+	if m.anomalyTask == nil || m.blockAnomalyDetection {
+		return
+	}
+	// Prevent stacking calls.
+	m.blockAnomalyDetection = true
+	done := m.anomalyTask.Start()
+	ids := make([]int64, 0, len(m.sources))
+	for _, s := range m.sources {
+		// Sources in shadow mode do not import.
+		if s.id != 0 && s.active && !s.shadow {
+			ids = append(ids, s.id)
+		}
+	}
+	go func() {
+		defer func() { m.fns <- (*Manager).enableAnomalyDetection }()
+		found, err := m.findAnomalies(ctx, ids, time.Now().UTC())
+		if err != nil {
+			slog.Error("detecting import anomalies failed", "err", err)
+			done(err)
+			return
+		}
+		if len(found) > 0 {
+			m.fns <- func(m *Manager, ctx context.Context) {
+				m.raiseAnomalies(ctx, found)
+			}
+		}
+		done(nil)
+	}()
+}
+
+// findAnomalies judges the imports of the given sources on the day
+// before now. Only the anomalies not detected before are returned.
+func (m *Manager) findAnomalies(ctx context.Context, ids []int64, now time.Time) ([]importAnomaly, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	const (
+		countSQL = `SELECT f.sources_id, (d.time AT TIME ZONE 'UTC')::date, count(*) ` +
+			`FROM downloads d JOIN feeds f ON d.feeds_id = f.id ` +
+			`WHERE d.documents_id IS NOT NULL AND f.sources_id = ANY($1) ` +
+			`AND d.time >= $2 AND d.time < $3 ` +
+			`GROUP BY 1, 2`
+		insertSQL = `INSERT INTO import_anomalies (sources_id, day, kind, imports, average) ` +
+			`VALUES ($1, $2, $3, $4, $5) ON CONFLICT DO NOTHING`
+	)
+	cfg := &m.cfg.Anomalies
+	end := now.Truncate(oneDay)
+	judged := end.Add(-oneDay)
+	start := judged.Add(-time.Duration(cfg.History) * oneDay)
+
+	var found []importAnomaly
+	err := m.db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+		counts := make(map[int64]map[int]int64, len(ids))
+		var (
+			sourceID int64
+			date     time.Time
+			n        int64
+		)
+		rows, _ := conn.Query(rctx, countSQL, ids, start, end)
+		if _, err := pgx.ForEachRow(rows, []any{&sourceID, &date, &n}, func() error {
+			if counts[sourceID] == nil {
+				counts[sourceID] = map[int]int64{}
+			}
+			counts[sourceID][int(date.Sub(start)/oneDay)] = n
+			return nil
+		}); err != nil {
+			return fmt.Errorf("counting imports failed: %w", err)
+		}
+		for _, id := range ids {
+			history := dailyHistory(counts[id], cfg.History)
+			imports := counts[id][cfg.History]
+			kind, average := detectAnomaly(cfg, history, imports)
+			if kind == "" {
+				continue
+			}
+			tag, err := conn.Exec(rctx, insertSQL, id, judged, kind, imports, average)
+			if err != nil {
+				return fmt.Errorf("storing import anomaly failed: %w", err)
+			}
+			// Already detected by an earlier run.
+			if tag.RowsAffected() == 0 {
+				continue
+			}
+			found = append(found, importAnomaly{
+				sourceID: id,
+				day:      judged,
+				kind:     kind,
+				imports:  imports,
+				average:  average,
+			})
+		}
+		return nil
+	}, 0)
+	return found, err
+}
+
+// raiseAnomalies raises the attention flags of the sources with anomalies.
+func (m *Manager) raiseAnomalies(ctx context.Context, found []importAnomaly) {
+	const updateSQL = `UPDATE sources SET checksum_updated = $1 WHERE id = $2`
+	now := time.Now().UTC()
+	for i := range found {
+		ia := &found[i]
+		s := m.findSourceByID(ia.sourceID)
+		if s == nil {
+			continue
+		}
+		slog.Warn("import anomaly detected",
+			"id", s.id, "kind", ia.kind, "imports", ia.imports, "average", ia.average)
+		if err := m.db.Run(
+			ctx,
+			func(ctx context.Context, conn *pgxpool.Conn) error {
+				batch := &pgx.Batch{}
+				batch.Queue(updateSQL, now, s.id)
+				batch.Queue(insertSourceActionSQL, s.id, string(AttentionAction), ia.message())
+				return conn.SendBatch(ctx, batch).Close()
+			}, 0,
+		); err != nil {
+			slog.Error("raising attention failed", "id", s.id, "err", err)
+			continue
+		}
+		s.checksumUpdated = now
+	}
+}
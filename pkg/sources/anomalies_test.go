@@ -0,0 +1,55 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+)
+
+func TestDetectAnomaly(t *testing.T) {
+	cfg := &config.Anomalies{
+		MinHistory:     3,
+		SpikeFactor:    10,
+		MinSpike:       20,
+		SilenceAverage: 1,
+	}
+	for _, tc := range []struct {
+		name    string
+		history []int64
+		imports int64
+		want    string
+	}{
+		{"too short", []int64{5, 5}, 0, ""},
+		{"usual", []int64{5, 4, 6}, 5, ""},
+		{"silence", []int64{5, 4, 6}, 0, silenceAnomaly},
+		{"quiet source", []int64{1, 0, 0, 0}, 0, ""},
+		{"spike", []int64{5, 4, 6}, 51, spikeAnomaly},
+		{"no spike", []int64{5, 4, 6}, 50, ""},
+		{"small spike", []int64{1, 0, 0}, 19, ""},
+		{"spike from nothing", []int64{1, 0, 0}, 20, spikeAnomaly},
+	} {
+		if got, _ := detectAnomaly(cfg, tc.history, tc.imports); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestDailyHistory(t *testing.T) {
+	if got := dailyHistory(nil, 5); got != nil {
+		t.Errorf("got %v, want nil", got)
+	}
+	// The judged day at index 5 is not part of the history.
+	got := dailyHistory(map[int]int64{2: 3, 4: 1, 5: 7}, 5)
+	if want := []int64{3, 0, 1}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
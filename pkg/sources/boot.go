@@ -3,155 +3,358 @@
 //
 // SPDX-License-Identifier: Apache-2.0
 //
-// SPDX-FileCopyrightText: 2024 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
-// Software-Engineering: 2024 Intevation GmbH <https://intevation.de>
+// SPDX-FileCopyrightText: 2024, 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2024, 2026 Intevation GmbH <https://intevation.de>
 
 package sources
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ISDuBA/ISDuBA/pkg/config"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// bootProgressInterval is the interval in which the progress
+// of preparing the sources is logged while booting.
+const bootProgressInterval = 5 * time.Second
+
+// bootSource is a source as loaded from the database
+// before its secrets are decrypted.
+type bootSource struct {
+	s                                       *source
+	patterns, windows                       []string
+	clientCertPrivate, clientCertPassphrase []byte
+	oauth2ClientSecret, basicAuthPassword   []byte
+	tlsCABundle, tlsPinnedCerts             []byte
+	bad                                     bool
+}
+
+// bootFeed is a feed as loaded from the database.
+type bootFeed struct {
+	f        *feed
+	sourceID int64
+}
+
 // Boot loads the sources from database.
+// The sources and the feeds are loaded concurrently and the sources
+// are prepared by a bounded number of workers as decrypting their
+// secrets and parsing their certificates is expensive.
 func (m *Manager) Boot(ctx context.Context) error {
-	const (
-		sourcesSQL = `SELECT id, name, url, rate, slots, active, headers, ` +
-			`strict_mode, secure, signature_check, age, ignore_patterns, ` +
-			`client_cert_public, client_cert_private, client_cert_passphrase, ` +
-			`checksum, checksum_ack, checksum_updated ` +
-			`FROM sources ORDER BY id`
-		feedsSQL = `SELECT id, label, sources_id, url, rolie, log_lvl::text FROM feeds`
+	started := time.Now()
+	var (
+		wg                   sync.WaitGroup
+		loaded               []*bootSource
+		feeds                []bootFeed
+		sourcesErr, feedsErr error
 	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		loaded, sourcesErr = m.loadBootSources(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		feeds, feedsErr = m.loadBootFeeds(ctx)
+	}()
+	wg.Wait()
+	if err := errors.Join(sourcesErr, feedsErr); err != nil {
+		return err
+	}
+	slog.Info("loaded sources and feeds",
+		"sources", len(loaded), "feeds", len(feeds), "took", time.Since(started))
+
+	if err := m.prepareBootSources(ctx, loaded); err != nil {
+		return err
+	}
+
+	m.sources = make([]*source, len(loaded))
+	byID := make(map[int64]*source, len(loaded))
+	var bads []*source
+	for i, bs := range loaded {
+		m.sources[i] = bs.s
+		byID[bs.s.id] = bs.s
+		if bs.bad {
+			bads = append(bads, bs.s)
+		}
+	}
+	// If we have sources with bad crypto deactivate these.
+	if len(bads) > 0 {
+		if err := m.deactivateBootSources(ctx, bads); err != nil {
+			return err
+		}
+	}
+	// Add to list of active feeds.
+	for _, bf := range feeds {
+		s := byID[bf.sourceID]
+		if s == nil {
+			// Should really not happen! Considering a panic.
+			return fmt.Errorf("cannot find source id %d", bf.sourceID)
+		}
+		s.feeds = append(s.feeds, bf.f)
+		bf.f.source = s
+	}
+
+	activeFeeds := m.numActiveFeeds()
+
+	slog.Info("number of sources", "num", len(m.sources))
+	slog.Info("number of active feeds", "num", activeFeeds)
+	slog.Info("booting source manager finished", "took", time.Since(started))
+
+	// Trigger a refresh of the loaded feeds.
+	if activeFeeds > 0 {
+		m.backgroundPing()
+	}
+	return nil
+}
+
+// loadBootSources loads the sources from the database.
+func (m *Manager) loadBootSources(ctx context.Context) ([]*bootSource, error) {
+	const sourcesSQL = `SELECT id, name, url, rate, slots, weight, active, shadow, priority::text, headers, ` +
+		`strict_mode, secure, signature_check, age, ignore_patterns, fetch_windows, ` +
+		`client_cert_public, client_cert_private, client_cert_passphrase, ` +
+		`oauth2_token_url, oauth2_client_id, oauth2_client_secret, oauth2_scopes, ` +
+		`basic_auth_user, basic_auth_password, ` +
+		`tls_ca_bundle, tls_pinned_certs, receipt_url, ` +
+		`checksum, checksum_ack, checksum_updated ` +
+		`FROM sources ORDER BY id`
+	var loaded []*bootSource
 	if err := m.db.Run(
 		ctx,
 		func(rctx context.Context, con *pgxpool.Conn) error {
-			tx, err := con.Begin(rctx)
-			if err != nil {
-				return fmt.Errorf("starting transaction failed: %w", err)
-			}
-			defer tx.Rollback(rctx)
-			// Collect sources.
-			srows, err := tx.Query(rctx, sourcesSQL)
+			rows, err := con.Query(rctx, sourcesSQL)
 			if err != nil {
 				return fmt.Errorf("querying sources failed: %w", err)
 			}
-			var bads []int64
-			m.sources, err = pgx.CollectRows(srows, func(row pgx.CollectableRow) (*source, error) {
+			loaded, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*bootSource, error) {
 				var (
-					s                                       source
-					patterns                                []string
-					clientCertPrivate, clientCertPassphrase []byte
+					s  source
+					bs = bootSource{s: &s}
 				)
-				if err := row.Scan(
-					&s.id, &s.name, &s.url, &s.rate, &s.slots, &s.active, &s.headers,
-					&s.strictMode, &s.secure, &s.signatureCheck, &s.age, &patterns,
-					&s.clientCertPublic, &clientCertPrivate, &clientCertPassphrase,
+				err := row.Scan(
+					&s.id, &s.name, &s.url, &s.rate, &s.slots, &s.weight, &s.active, &s.shadow, &s.priority, &s.headers,
+					&s.strictMode, &s.secure, &s.signatureCheck, &s.age, &bs.patterns, &bs.windows,
+					&s.clientCertPublic, &bs.clientCertPrivate, &bs.clientCertPassphrase,
+					&s.oauth2TokenURL, &s.oauth2ClientID, &bs.oauth2ClientSecret, &s.oauth2Scopes,
+					&s.basicAuthUser, &bs.basicAuthPassword,
+					&bs.tlsCABundle, &bs.tlsPinnedCerts, &s.receiptURL,
 					&s.checksum, &s.checksumAck, &s.checksumUpdated,
-				); err != nil {
-					return nil, err
-				}
-				regexps, err := AsRegexps(patterns)
-				if err != nil {
-					return nil, err
-				}
-				s.ignorePatterns = regexps
-
-				var bad bool
-				if s.clientCertPrivate, err = m.decrypt(clientCertPrivate); err != nil {
-					bad = true
-				}
-				if s.clientCertPassphrase, err = m.decrypt(clientCertPassphrase); err != nil {
-					bad = true
-				}
-				if !bad {
-					if err := s.updateCertificate(); err != nil {
-						bad = true
-					}
-				}
-				if bad && s.active {
-					s.status = []string{deactivatedDueToClientCertIssue}
-					s.active = false
-					bads = append(bads, s.id)
-				}
-				return &s, nil
+				)
+				return &bs, err
 			})
 			if err != nil {
 				return fmt.Errorf("collecting sources failed: %w", err)
 			}
-			// If we have sources with bad crypto deactivate these.
-			if len(bads) > 0 {
-				const deactivateSQL = `UPDATE sources SET active = FALSE WHERE id = $1`
-				batch := &pgx.Batch{}
-				for _, id := range bads {
-					batch.Queue(deactivateSQL, id)
-				}
-				if err := tx.SendBatch(ctx, batch).Close(); err != nil {
-					return fmt.Errorf("deactivating bad sources failed: %w", err)
-				}
-			}
+			return nil
+		}, 0,
+	); err != nil {
+		return nil, err
+	}
+	return loaded, nil
+}
 
-			// Collect feeds.
-			frows, err := tx.Query(rctx, feedsSQL)
+// loadBootFeeds loads the feeds from the database.
+func (m *Manager) loadBootFeeds(ctx context.Context) ([]bootFeed, error) {
+	const feedsSQL = `SELECT id, label, sources_id, url, rolie, log_lvl::text, ` +
+		`(SELECT max(time) FROM feed_metrics WHERE feeds_id = feeds.id) ` +
+		`FROM feeds ORDER BY id`
+	var feeds []bootFeed
+	if err := m.db.Run(
+		ctx,
+		func(rctx context.Context, con *pgxpool.Conn) error {
+			rows, err := con.Query(rctx, feedsSQL)
 			if err != nil {
-				return fmt.Errorf("querying sources failed: %w", err)
+				return fmt.Errorf("querying feeds failed: %w", err)
 			}
-			defer frows.Close()
-			for frows.Next() {
+			feeds, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (bootFeed, error) {
 				var (
 					f        feed
 					sid      int64
 					raw      string
 					logLevel config.FeedLogLevel
+					last     *time.Time
 				)
-				if err := frows.Scan(
+				if err := row.Scan(
 					&f.id,
 					&f.label,
 					&sid,
 					&raw,
 					&f.rolie,
 					&logLevel,
+					&last,
 				); err != nil {
-					return err
+					return bootFeed{}, err
 				}
 				parsed, err := url.Parse(raw)
 				if err != nil {
-					return fmt.Errorf("invalid URL: %w", err)
+					return bootFeed{}, fmt.Errorf("invalid URL: %w", err)
 				}
 				f.url = parsed
 				f.logLevel.Store(int32(logLevel))
-				// Add to list of active feeds.
-				s := m.findSourceByID(sid)
-				if s == nil {
-					// Should really not happen! Considering a panic.
-					return fmt.Errorf("cannot find source id %d", sid)
+				if last != nil {
+					// The metrics are bucketed so this is roughly the last download.
+					f.metrics.last = last.UTC()
 				}
-				s.feeds = append(s.feeds, &f)
-				f.source = s
-			}
-			if err := frows.Err(); err != nil {
+				return bootFeed{f: &f, sourceID: sid}, nil
+			})
+			if err != nil {
 				return fmt.Errorf("collecting feeds failed: %w", err)
 			}
-			return tx.Commit(rctx)
+			return nil
 		}, 0,
 	); err != nil {
-		return err
+		return nil, err
 	}
+	return feeds, nil
+}
 
-	activeFeeds := m.numActiveFeeds()
+// prepareBootSources compiles the patterns, decrypts the secrets
+// and loads the certificates of the sources with a bounded number
+// of workers. Sources with broken secrets are marked as bad and
+// are deactivated.
+func (m *Manager) prepareBootSources(ctx context.Context, loaded []*bootSource) error {
+	if len(loaded) == 0 {
+		return nil
+	}
+	var (
+		jobs       = make(chan *bootSource)
+		numWorkers = min(m.cfg.Sources.BootWorkers, len(loaded))
+		wg         sync.WaitGroup
+		done       atomic.Int64
+		errMu      sync.Mutex
+		errs       []error
+		started    = time.Now()
+	)
+	prepare := func() {
+		defer wg.Done()
+		for bs := range jobs {
+			if err := m.prepareBootSource(bs); err != nil {
+				errMu.Lock()
+				errs = append(errs, fmt.Errorf("source %q: %w", bs.s.name, err))
+				errMu.Unlock()
+			}
+			done.Add(1)
+		}
+	}
+	for range numWorkers {
+		wg.Add(1)
+		go prepare()
+	}
+	// Log the progress as long as the workers are busy.
+	finished := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(bootProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-finished:
+				return
+			case <-ticker.C:
+				slog.Info("preparing sources",
+					"done", done.Load(), "total", len(loaded))
+			}
+		}
+	}()
+feed:
+	for _, bs := range loaded {
+		select {
+		case jobs <- bs:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	close(finished)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("preparing sources failed: %w", err)
+	}
+	slog.Info("prepared sources",
+		"num", len(loaded), "workers", numWorkers, "took", time.Since(started))
+	return nil
+}
 
-	slog.Info("number of sources", "num", len(m.sources))
-	slog.Info("number of active feeds", "num", activeFeeds)
+// prepareBootSource compiles the patterns, decrypts the secrets
+// and loads the certificates of a source.
+func (m *Manager) prepareBootSource(bs *bootSource) error {
+	s := bs.s
+	regexps, err := AsRegexps(bs.patterns)
+	if err != nil {
+		return err
+	}
+	s.ignorePatterns = regexps
+	if s.fetchWindows, err = ParseFetchWindows(bs.windows); err != nil {
+		return err
+	}
 
-	// Trigger a refresh of the loaded feeds.
-	if activeFeeds > 0 {
-		m.backgroundPing()
+	var bad bool
+	if s.clientCertPrivate, err = m.decrypt(bs.clientCertPrivate); err != nil {
+		bad = true
+	}
+	if s.clientCertPassphrase, err = m.decrypt(bs.clientCertPassphrase); err != nil {
+		bad = true
+	}
+	if !bad {
+		if err := s.updateCertificate(); err != nil {
+			bad = true
+		}
+	}
+	if bad && s.active {
+		s.status = []string{deactivatedDueToClientCertIssue}
+		s.active = false
+		bs.bad = true
+	}
+	if s.oauth2ClientSecret, err = m.decrypt(bs.oauth2ClientSecret); err != nil && s.active {
+		s.status = []string{deactivatedDueToOAuth2Issue}
+		s.active = false
+		bs.bad = true
+	}
+	if s.basicAuthPassword, err = m.decrypt(bs.basicAuthPassword); err != nil && s.active {
+		s.status = []string{deactivatedDueToBasicAuthIssue}
+		s.active = false
+		bs.bad = true
+	}
+	if err := m.loadTrust(s, bs.tlsCABundle, bs.tlsPinnedCerts); err != nil && s.active {
+		s.status = []string{deactivatedDueToTLSIssue}
+		s.active = false
+		bs.bad = true
 	}
 	return nil
 }
+
+// deactivateBootSources deactivates the sources with broken secrets.
+func (m *Manager) deactivateBootSources(ctx context.Context, bads []*source) error {
+	const deactivateSQL = `UPDATE sources SET active = FALSE WHERE id = $1`
+	return m.db.Run(
+		ctx,
+		func(rctx context.Context, con *pgxpool.Conn) error {
+			tx, err := con.Begin(rctx)
+			if err != nil {
+				return fmt.Errorf("starting transaction failed: %w", err)
+			}
+			defer tx.Rollback(rctx)
+			batch := &pgx.Batch{}
+			for _, s := range bads {
+				batch.Queue(deactivateSQL, s.id)
+				batch.Queue(insertSourceActionSQL,
+					s.id, string(DeactivatedAction), strings.Join(s.status, " "))
+			}
+			if err := tx.SendBatch(rctx, batch).Close(); err != nil {
+				return fmt.Errorf("deactivating bad sources failed: %w", err)
+			}
+			return tx.Commit(rctx)
+		}, 0,
+	)
+}
@@ -0,0 +1,105 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+
+	"github.com/ISDuBA/ISDuBA/pkg/httpcache"
+)
+
+// CacheEviction are the numbers of entries removed from the caches.
+type CacheEviction struct {
+	PMDs int `json:"pmds"`
+	Keys int `json:"keys"`
+	// HTTP are the responses of the evicted PMDs removed
+	// from the shared HTTP cache.
+	HTTP int `json:"http"`
+}
+
+// CacheStats are the numbers of the caches.
+type CacheStats struct {
+	PMDs int             `json:"pmds"`
+	Keys int             `json:"keys"`
+	HTTP httpcache.Stats `json:"http"`
+}
+
+// CacheStats returns the numbers of the caches.
+func (m *Manager) CacheStats() CacheStats {
+	return CacheStats{
+		PMDs: m.pmdCache.Len(),
+		Keys: m.keysCache.Len(),
+		HTTP: m.httpCache.Stats(),
+	}
+}
+
+// EvictHTTPCache removes the responses of the given URL from
+// the shared HTTP cache. If url is empty all responses are removed.
+func (m *Manager) EvictHTTPCache(url string) int {
+	return m.httpCache.Evict(url)
+}
+
+// EvictCaches removes entries from the caches of the provider
+// metadata and the OpenPGP keys of the sources.
+// If sourceID is not 0 only the entries of this source are removed.
+// Otherwise if url is not empty only the entries of the sources
+// with this PMD URL are removed. Without both all entries are removed.
+// The responses of the evicted PMDs are removed from the HTTP cache, too.
+func (m *Manager) EvictCaches(pmds, keys bool, url string, sourceID int64) (CacheEviction, error) {
+	var (
+		ev  CacheEviction
+		err error
+	)
+	m.inManager(func(m *Manager, _ context.Context) {
+		var ids []int64
+		switch {
+		case sourceID != 0:
+			s := m.findSourceByID(sourceID)
+			if s == nil {
+				err = NoSuchEntryError("no such source")
+				return
+			}
+			url, ids = s.url, []int64{s.id}
+		case url != "":
+			for _, s := range m.sources {
+				if s.url == url {
+					ids = append(ids, s.id)
+				}
+			}
+		}
+		if pmds {
+			urls := map[string]struct{}{}
+			if url != "" {
+				urls[url] = struct{}{}
+			}
+			ev.PMDs = m.pmdCache.DeleteFunc(func(k string, _ *CachedProviderMetadata) bool {
+				// The keys carry a hash of the credentials.
+				if url == "" || k == url || strings.HasPrefix(k, url+"|") {
+					pmdURL, _, _ := strings.Cut(k, "|")
+					urls[pmdURL] = struct{}{}
+					return true
+				}
+				return false
+			})
+			for u := range urls {
+				ev.HTTP += m.httpCache.Evict(u)
+			}
+		}
+		if keys {
+			ev.Keys = m.keysCache.DeleteFunc(func(id int64, _ *crypto.KeyRing) bool {
+				return url == "" || slices.Contains(ids, id)
+			})
+		}
+	})
+	return ev, err
+}
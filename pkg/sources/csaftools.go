@@ -0,0 +1,238 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ImportedSource is a source derived from the configuration
+// of a csaf_downloader or a csaf_aggregator.
+type ImportedSource struct {
+	Name           string
+	URL            string
+	Rate           *float64
+	Slots          *int
+	Headers        []string
+	StrictMode     *bool
+	Secure         *bool
+	SignatureCheck *bool
+	Age            *time.Duration
+	IgnorePatterns []string
+	Warnings       []string
+}
+
+// csafToolProvider are the fields of a provider of a csaf_aggregator
+// configuration which can be mapped to a source.
+type csafToolProvider struct {
+	Name             string              `toml:"name"`
+	Domain           string              `toml:"domain"`
+	Rate             *float64            `toml:"rate"`
+	Insecure         *bool               `toml:"insecure"`
+	IgnorePattern    []string            `toml:"ignore_pattern"`
+	Header           map[string][]string `toml:"header"`
+	ClientCert       *string             `toml:"client_cert"`
+	ClientKey        *string             `toml:"client_key"`
+	ClientPassphrase *string             `toml:"client_passphrase"`
+	TimeRange        *string             `toml:"time_range"`
+}
+
+// csafToolConfig are the fields of the csaf_downloader and
+// csaf_aggregator configurations which can be mapped to sources.
+type csafToolConfig struct {
+	csafToolProvider
+	Worker         int                 `toml:"worker"`
+	IgnoreSigcheck bool                `toml:"ignore_sigcheck"`
+	ValidationMode string              `toml:"validation_mode"`
+	Providers      []*csafToolProvider `toml:"providers"`
+}
+
+// PMDURLFromDomain returns the well-known PMD URL of a domain.
+// Full URLs are returned unchanged.
+func PMDURLFromDomain(domain string) string {
+	if strings.HasPrefix(domain, "https://") || strings.HasPrefix(domain, "http://") {
+		return domain
+	}
+	return "https://" + strings.Trim(domain, "/") + "/.well-known/csaf/provider-metadata.json"
+}
+
+// ParseCSAFToolConfig reads a TOML configuration of a csaf_downloader or
+// a csaf_aggregator and maps it to sources. A configuration with providers
+// is treated as an aggregator configuration. As the domains of a downloader
+// are given on the command line they have to be passed in.
+// Settings which cannot be mapped are reported as warnings of the sources.
+func ParseCSAFToolConfig(r io.Reader, domains []string, maxSlots int) ([]ImportedSource, error) {
+	var cfg csafToolConfig
+	if _, err := toml.NewDecoder(r).Decode(&cfg); err != nil {
+		return nil, InvalidArgumentError(fmt.Sprintf("invalid configuration: %v", err))
+	}
+	global := &cfg.csafToolProvider
+
+	var providers []*csafToolProvider
+	if len(cfg.Providers) > 0 {
+		providers = cfg.Providers
+	} else {
+		if len(domains) == 0 {
+			return nil, InvalidArgumentError("downloader configuration needs domains")
+		}
+		for _, domain := range domains {
+			providers = append(providers, &csafToolProvider{Domain: domain})
+		}
+	}
+
+	imported := make([]ImportedSource, 0, len(providers))
+	for _, p := range providers {
+		if p == nil || p.Domain == "" {
+			continue
+		}
+		is := ImportedSource{
+			Name: p.Name,
+			URL:  PMDURLFromDomain(p.Domain),
+			Rate: firstOf(p.Rate, global.Rate),
+		}
+		if is.Name == "" {
+			is.Name = p.Domain
+			if u, err := url.Parse(is.URL); err == nil && u.Host != "" {
+				is.Name = u.Host
+			}
+		}
+		if is.Rate != nil && *is.Rate <= 0 {
+			is.Rate = nil
+		}
+		if cfg.Worker > 0 {
+			slots := cfg.Worker
+			if maxSlots > 0 && slots > maxSlots {
+				is.Warnings = append(is.Warnings,
+					fmt.Sprintf("worker %d reduced to %d slots", slots, maxSlots))
+				slots = maxSlots
+			}
+			is.Slots = &slots
+		}
+		if insecure := firstOf(p.Insecure, global.Insecure); insecure != nil {
+			secure := !*insecure
+			is.Secure = &secure
+		}
+		if cfg.IgnoreSigcheck {
+			is.SignatureCheck = new(bool)
+		}
+		if cfg.ValidationMode == "unsafe" {
+			is.StrictMode = new(bool)
+		}
+		is.IgnorePatterns = slices.Concat(global.IgnorePattern, p.IgnorePattern)
+		is.Headers = mapHeaders(global.Header, p.Header)
+		if tr := firstOf(p.TimeRange, global.TimeRange); tr != nil {
+			if age, err := time.ParseDuration(*tr); err == nil && age > 0 {
+				is.Age = &age
+			} else {
+				is.Warnings = append(is.Warnings,
+					fmt.Sprintf("time_range %q cannot be mapped to an age", *tr))
+			}
+		}
+		if firstOf(p.ClientCert, global.ClientCert) != nil ||
+			firstOf(p.ClientKey, global.ClientKey) != nil ||
+			firstOf(p.ClientPassphrase, global.ClientPassphrase) != nil {
+			is.Warnings = append(is.Warnings,
+				"client certificates are files and have to be uploaded manually")
+		}
+		imported = append(imported, is)
+	}
+	return imported, nil
+}
+
+// firstOf returns the first non-nil value.
+func firstOf[T any](values ...*T) *T {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// mapHeaders converts the headers into the "key: value" form of the sources.
+// The specific headers override the global ones.
+func mapHeaders(global, specific map[string][]string) []string {
+	merged := map[string][]string{}
+	for k, v := range global {
+		merged[k] = v
+	}
+	for k, v := range specific {
+		merged[k] = v
+	}
+	var headers []string
+	for k, vs := range merged {
+		for _, v := range vs {
+			headers = append(headers, k+": "+v)
+		}
+	}
+	slices.Sort(headers)
+	return headers
+}
+
+// AvailableFeed is a feed offered by the PMD of a source.
+type AvailableFeed struct {
+	Label string
+	URL   string
+}
+
+// AvailableFeeds returns the feeds offered by the PMD of the given source.
+// The labels are derived from the TLP labels of the ROLIE feeds
+// and are unique.
+func (m *Manager) AvailableFeeds(sourceID int64) ([]AvailableFeed, error) {
+	cpmd := m.SourcePMD(sourceID)
+	if cpmd == nil {
+		return nil, NoSuchEntryError("no such source")
+	}
+	pmd, err := cpmd.Model()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		feeds []AvailableFeed
+		used  = map[string]int{}
+	)
+	add := func(label, url string) {
+		if slices.ContainsFunc(feeds, func(af AvailableFeed) bool { return af.URL == url }) {
+			return
+		}
+		used[label]++
+		if n := used[label]; n > 1 {
+			label = fmt.Sprintf("%s-%d", label, n)
+		}
+		feeds = append(feeds, AvailableFeed{Label: label, URL: url})
+	}
+	for _, d := range pmd.Distributions {
+		if d.Rolie == nil {
+			continue
+		}
+		for _, f := range d.Rolie.Feeds {
+			if f.URL == nil {
+				continue
+			}
+			label := "rolie"
+			if f.TLPLabel != nil {
+				label = strings.ToLower(string(*f.TLPLabel))
+			}
+			add(label, string(*f.URL))
+		}
+	}
+	for _, d := range pmd.Distributions {
+		if d.Rolie == nil && d.DirectoryURL != "" {
+			add("directory", d.DirectoryURL)
+		}
+	}
+	return feeds, nil
+}
@@ -0,0 +1,94 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DeletePreview summarizes the data affected by deleting a source.
+type DeletePreview struct {
+	// Feeds is the number of feeds which are removed.
+	Feeds int64 `json:"feeds"`
+	// FeedLogs is the number of feed log entries which are removed.
+	FeedLogs int64 `json:"feed_logs"`
+	// Changes is the number of remembered entry changes which are removed.
+	Changes int64 `json:"changes"`
+	// Downloads is the number of download records losing the reference to their feed.
+	Downloads int64 `json:"downloads"`
+	// Documents is the number of imported documents which are kept
+	// but lose the information from which feed they were downloaded.
+	Documents int64 `json:"documents"`
+	// ForwardsPending is the number of pending forwards of these documents.
+	ForwardsPending int64 `json:"forwards_pending"`
+	// ForwardsFailed is the number of failed forwards of these documents.
+	ForwardsFailed int64 `json:"forwards_failed"`
+	// Waiting is the number of queued downloads which are dropped.
+	Waiting int `json:"waiting"`
+	// Downloading is the number of running downloads which are dropped.
+	Downloading int `json:"downloading"`
+}
+
+// SourceDeletePreview returns a summary of the data which would be
+// affected if the source with the given id is removed.
+func (m *Manager) SourceDeletePreview(ctx context.Context, sourceID int64) (*DeletePreview, error) {
+	if sourceID == 0 {
+		return nil, InvalidArgumentError("cannot remove this source")
+	}
+	var (
+		preview DeletePreview
+		found   bool
+	)
+	m.inManager(func(m *Manager, _ context.Context) {
+		if s := m.findSourceByID(sourceID); s != nil {
+			found = true
+			var st Stats
+			s.addStats(&st)
+			preview.Waiting, preview.Downloading = st.Waiting, st.Downloading
+		}
+	})
+	if !found {
+		return nil, NoSuchEntryError("no such source")
+	}
+	const previewSQL = `WITH source_feeds AS (` +
+		`SELECT id FROM feeds WHERE sources_id = $1), ` +
+		`source_documents AS (` +
+		`SELECT DISTINCT documents_id AS id FROM downloads ` +
+		`WHERE feeds_id IN (SELECT id FROM source_feeds) AND documents_id IS NOT NULL) ` +
+		`SELECT ` +
+		`(SELECT count(*) FROM source_feeds), ` +
+		`(SELECT count(*) FROM feed_logs WHERE feeds_id IN (SELECT id FROM source_feeds)), ` +
+		`(SELECT count(*) FROM changes WHERE feeds_id IN (SELECT id FROM source_feeds)), ` +
+		`(SELECT count(*) FROM downloads WHERE feeds_id IN (SELECT id FROM source_feeds)), ` +
+		`(SELECT count(*) FROM source_documents), ` +
+		`(SELECT count(*) FROM forwarders_queue ` +
+		`WHERE state = 'pending' AND documents_id IN (SELECT id FROM source_documents)), ` +
+		`(SELECT count(*) FROM forwarders_queue ` +
+		`WHERE state = 'failed' AND documents_id IN (SELECT id FROM source_documents))`
+	if err := m.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			return conn.QueryRow(rctx, previewSQL, sourceID).Scan(
+				&preview.Feeds,
+				&preview.FeedLogs,
+				&preview.Changes,
+				&preview.Downloads,
+				&preview.Documents,
+				&preview.ForwardsPending,
+				&preview.ForwardsFailed,
+			)
+		}, 0,
+	); err != nil {
+		return nil, fmt.Errorf("collecting delete preview failed: %w", err)
+	}
+	return &preview, nil
+}
@@ -23,9 +23,13 @@ import (
 	"net/url"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/ISDuBA/ISDuBA/pkg/config"
+	"github.com/ISDuBA/ISDuBA/pkg/database"
+	"github.com/ISDuBA/ISDuBA/pkg/eventbus"
 	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/transform"
 	"github.com/ProtonMail/gopenpgp/v2/crypto"
 	"github.com/gocsaf/csaf/v3/csaf"
 	"github.com/gocsaf/csaf/v3/util"
@@ -77,36 +81,76 @@ func (i *inserter) sql(table string) string {
 		table, strings.Join(i.keys, ","), placeholders(len(i.values)))
 }
 
-// download fetches the files of a document and stores
-// them into the database.
-func (l *location) download(m *Manager, f *feed) {
-
-	var (
-		strictMode     bool                     // All checks have to be fulfilled.
-		signatureCheck bool                     // Take signature check seriously.
-		filename       string                   // We need it later to check it against the tracking id.
-		writers        []io.Writer              // Enables to decode JSON and calculating the checksum at once.
-		checks         []func(*dlStatus, *feed) // List of checks to pass.
-		data           bytes.Buffer             // The raw data will be stored in the database.
-		signatureData  []byte                   // The signature will be stored in the database.
-		client         *http.Client
-	)
+// pending is a document passing the stages of the import pipeline.
+type pending struct {
+	job            downloadJob
+	l              *location
+	f              *feed
+	strictMode     bool                     // All checks have to be fulfilled.
+	shadow         bool                     // Only check but don't import.
+	signatureCheck bool                     // Take signature check seriously.
+	receiptURL     *string                  // Acknowledge the import to the provider.
+	client         *http.Client             // Client with the settings of the source.
+	filename       string                   // We need it later to check it against the tracking id.
+	checks         []func(*dlStatus, *feed) // List of checks to pass.
+	data           spool                    // The raw data will be stored in the database.
+	raw            []byte                   // The raw data loaded from the spool.
+	doc            any                      // The decoded document.
+	signatureData  []byte                   // The signature will be stored in the database.
+	status         dlStatus                 // The results of the checks.
+	reserved       int64                    // Bytes reserved from the memory budget.
+	duration       time.Duration            // How long the download took.
+}
+
+// cleanup releases the resources held by the pending document.
+func (p *pending) cleanup(m *Manager) {
+	if p.data.spooled() {
+		m.pipeline.spooled.Add(-1)
+	}
+	p.data.close()
+	p.raw, p.doc = nil, nil
+	m.pipeline.memory.release(p.reserved)
+	p.reserved = 0
+	p.client.CloseIdleConnections()
+}
+
+// finish releases the resources and frees the download slot.
+func (p *pending) finish(m *Manager) {
+	p.cleanup(m)
+	p.job.finish(m)
+}
+
+// fail releases the resources and lets the download be retried.
+func (p *pending) fail(m *Manager, err error) {
+	p.cleanup(m)
+	p.job.failed(m, err)
+}
+
+// fetch downloads a document and its hashes.
+// Returns an error if the download failed.
+func (l *location) fetch(m *Manager, f *feed) (*pending, error) {
+
+	p := &pending{l: l, f: f}
+
+	var writers []io.Writer // Enables calculating the checksums at once.
 
 	// The manager owns the configuration so extract the parameters beforehand.
 	m.inManager(func(m *Manager, _ context.Context) {
-		strictMode = f.source.useStrictMode(m)
-		signatureCheck = f.source.checkSignature(m)
-		client = f.source.httpClient(m)
+		p.strictMode = f.source.useStrictMode(m)
+		p.shadow = f.source.shadow
+		p.receiptURL = f.source.receiptURL
+		p.signatureCheck = f.source.checkSignature(m)
+		p.client = f.source.httpClient(m)
 	})
-	defer client.CloseIdleConnections()
+	client := p.client
 
 	// checks is a list of checks to have to be passed in strict mode.
-	checks = []func(ds *dlStatus, f *feed){
+	p.checks = []func(ds *dlStatus, f *feed){
 		// Ignore advisories with none conforming file names.
 		func(ds *dlStatus, f *feed) {
-			if filename = filepath.Base(l.doc.String()); !util.ConformingFileName(filename) {
+			if p.filename = filepath.Base(l.doc.String()); !util.ConformingFileName(p.filename) {
 				ds.set(filenameFailed)
-				f.log(m, config.WarnFeedLogLevel, "File name %q is not conforming", filename)
+				f.log(m, config.WarnFeedLogLevel, "File name %q is not conforming", p.filename)
 			}
 		},
 	}
@@ -137,7 +181,7 @@ func (l *location) download(m *Manager, f *feed) {
 					}
 				}
 			}
-			checks = append(checks, check)
+			p.checks = append(p.checks, check)
 		}
 	} else if !f.rolie { // If we are directory based, do some guessing
 		var checksum hash.Hash
@@ -170,50 +214,90 @@ func (l *location) download(m *Manager, f *feed) {
 				f.log(m, config.WarnFeedLogLevel, "Fetching hash for %q failed", l.doc)
 			}
 		}
-		checks = append(checks, check)
+		p.checks = append(p.checks, check)
 	}
 
-	// Keep the raw data.
-	writers = append(writers, &data)
+	// Keep the raw data in memory if it fits into the spool threshold.
+	// Waits if the memory budget is exhausted.
+	p.reserved = m.pipeline.memory.reserve(m.pipeline.threshold)
 
 	// Download the CSAF document.
 	resp, err := f.source.httpGet(client, m, l.doc.String())
 	if err != nil {
 		f.log(m, config.ErrorFeedLogLevel, "downloading %q failed: %v", l.doc, err)
-		return
+		p.cleanup(m)
+		return nil, err
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		resp.Body.Close()
 		f.log(m, config.ErrorFeedLogLevel, "downloading %q failed: %s (%d)",
 			l.doc, http.StatusText(resp.StatusCode), resp.StatusCode)
-		return
+		p.cleanup(m)
+		return nil, fmt.Errorf("%s (%d)", http.StatusText(resp.StatusCode), resp.StatusCode)
+	}
+
+	// Give back what is announced to be not needed.
+	if cl := resp.ContentLength; cl >= 0 && cl < p.reserved {
+		m.pipeline.memory.release(p.reserved - cl)
+		p.reserved = cl
+	}
+	p.data.limit = p.reserved
+	writers = append(writers, &p.data)
+
+	// Prevent over-sized downloads.
+	limited := io.LimitReader(resp.Body, int64(m.cfg.General.AdvisoryUploadLimit))
+	_, err = io.Copy(io.MultiWriter(writers...), limited)
+	if p.data.spooled() {
+		m.pipeline.spooled.Add(1)
+	}
+	if err != nil {
+		f.log(m, config.ErrorFeedLogLevel, "downloading %q failed: %v", l.doc, err)
+		p.cleanup(m)
+		return nil, err
+	}
+	// Give back what was not used.
+	if used := int64(p.data.buf.Len()); used < p.reserved {
+		m.pipeline.memory.release(p.reserved - used)
+		p.reserved = used
+	}
+	return p, nil
+}
+
+// validate decodes the document and runs the checks.
+// Returns an error if the document cannot be imported at all.
+func (p *pending) validate(m *Manager) error {
+	l, f := p.l, p.f
+
+	// Spooled data has to be loaded into memory now.
+	if p.data.spooled() {
+		p.reserved += m.pipeline.memory.charge(p.data.size)
 	}
+	raw, err := p.data.load()
+	if err != nil {
+		f.log(m, config.ErrorFeedLogLevel, "loading document %q failed: %v", l.doc, err)
+		return err
+	}
+	p.raw = raw
 
 	// Decode document into JSON.
-	var doc any
-	if err := func() error {
-		defer resp.Body.Close()
-		// Prevent over-sized downloads.
-		limited := io.LimitReader(resp.Body, int64(m.cfg.General.AdvisoryUploadLimit))
-		tee := io.TeeReader(limited, io.MultiWriter(writers...))
-		return json.NewDecoder(tee).Decode(&doc)
-	}(); err != nil {
+	if err := json.NewDecoder(bytes.NewReader(raw)).Decode(&p.doc); err != nil {
 		// If it is not JSON there is no way to carry on.
 		f.log(m, config.ErrorFeedLogLevel, "decoding document %q failed: %v", l.doc, err)
-		return
+		return err
 	}
+	doc := p.doc
 
 	// Check if the tracking id matches the filename.
-	checks = append(checks, func(ds *dlStatus, f *feed) {
+	p.checks = append(p.checks, func(ds *dlStatus, f *feed) {
 		expr := util.NewPathEval()
-		if err := util.IDMatchesFilename(expr, doc, filename); err != nil {
+		if err := util.IDMatchesFilename(expr, doc, p.filename); err != nil {
 			ds.set(filenameFailed)
 			f.log(m, config.ErrorFeedLogLevel, "Tracking ID in %q is not conforming: %v", l.doc, err)
 		}
 	})
 
 	// Check document against schema.
-	checks = append(checks, func(ds *dlStatus, f *feed) {
+	p.checks = append(p.checks, func(ds *dlStatus, f *feed) {
 		if errors, err := csaf.ValidateCSAF(doc); err != nil || len(errors) > 0 {
 			ds.set(schemaValidationFailed)
 			if err != nil {
@@ -229,7 +313,7 @@ func (l *location) download(m *Manager, f *feed) {
 
 	// Check against remote validator if configured.
 	if m.val != nil {
-		checks = append(checks, func(ds *dlStatus, f *feed) {
+		p.checks = append(p.checks, func(ds *dlStatus, f *feed) {
 			switch rvr, err := m.val.Validate(doc); {
 			case err != nil:
 				ds.set(remoteValidationFailed)
@@ -251,7 +335,7 @@ func (l *location) download(m *Manager, f *feed) {
 		f.log(m, config.ErrorFeedLogLevel, "Loading OpenPGP keys failed: %v", err)
 	} else if keys.CountEntities() > 0 {
 		// Only check signature if we have something in the key ring.
-		checks = append(checks, func(ds *dlStatus, f *feed) {
+		p.checks = append(p.checks, func(ds *dlStatus, f *feed) {
 			var sign *url.URL
 			switch {
 			case l.signature != nil: // from ROLIE feed.
@@ -265,16 +349,16 @@ func (l *location) download(m *Manager, f *feed) {
 			}
 			var err error
 			var signature *crypto.PGPSignature
-			if signature, signatureData, err = f.source.loadSignature(client, m, sign); err != nil {
-				if signatureCheck {
+			if signature, p.signatureData, err = f.source.loadSignature(p.client, m, sign); err != nil {
+				if p.signatureCheck {
 					ds.set(signatureFailed)
 					f.log(m, config.ErrorFeedLogLevel,
 						"Loading OpenPGP signature for %q failed: %v", l.doc, err)
 				}
 			} else {
-				pm := crypto.NewPlainMessage(data.Bytes())
+				pm := crypto.NewPlainMessage(raw)
 				if err := keys.VerifyDetached(pm, signature, crypto.GetUnixTime()); err != nil {
-					if signatureCheck {
+					if p.signatureCheck {
 						ds.set(signatureFailed)
 						f.log(m, config.ErrorFeedLogLevel,
 							"Verifying OpenPGP signature of %q failed: %v", l.doc, err)
@@ -285,14 +369,38 @@ func (l *location) download(m *Manager, f *feed) {
 	}
 
 	// Run the checks.
-	status := allSucceeded
-	for _, check := range checks {
-		check(&status, f)
+	p.status = allSucceeded
+	for _, check := range p.checks {
+		check(&p.status, f)
+	}
+	return nil
+}
+
+// runPersistent runs fn against the database. If the connection
+// to the database is lost it waits for the recovery and tries again
+// so that no documents get lost during an outage.
+func (m *Manager) runPersistent(fn func(context.Context, *pgxpool.Conn) error) error {
+	const maxAttempts = 5
+	for attempt := 1; ; attempt++ {
+		err := m.db.Run(context.Background(), fn, 0)
+		if attempt == maxAttempts || !database.IsConnectionError(err) {
+			return err
+		}
+		if err := m.db.WaitAvailable(context.Background()); err != nil {
+			return err
+		}
 	}
+}
 
-	if strictMode && status != allSucceeded {
+// store writes the document and the download stats into the database.
+// Returns true if the document passed all checks and was stored.
+// Returns an error if the document could not be stored at all.
+func (p *pending) store(m *Manager) (bool, error) {
+	l, f, status := p.l, p.f, p.status
+
+	if p.strictMode && status != allSucceeded {
 		// Don't import, only write the stats.
-		if err := m.db.Run(context.Background(), func(ctx context.Context, conn *pgxpool.Conn) error {
+		if err := m.runPersistent(func(ctx context.Context, conn *pgxpool.Conn) error {
 			var i inserter
 			status.toInserter(&i)
 			if !f.invalid.Load() {
@@ -301,10 +409,42 @@ func (l *location) download(m *Manager, f *feed) {
 			sql := i.sql("downloads")
 			_, err := conn.Exec(ctx, sql, i.values...)
 			return err
-		}, 0); err != nil {
+		}); err != nil {
+			f.log(m, config.ErrorFeedLogLevel, "storing stats of %q failed: %v", l.doc, err)
+		}
+		return false, nil
+	}
+
+	if p.shadow {
+		// Only write the stats and remember the document
+		// as seen to not download it again.
+		if err := m.runPersistent(func(ctx context.Context, conn *pgxpool.Conn) error {
+			tx, err := conn.Begin(ctx)
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(ctx)
+			var i inserter
+			status.toInserter(&i)
+			if !f.invalid.Load() {
+				i.add("feeds_id", f.id)
+			}
+			if _, err := tx.Exec(ctx, i.sql("downloads"), i.values...); err != nil {
+				return err
+			}
+			if err := f.storeLastChanges(l)(ctx, tx, 0, false); err != nil {
+				return err
+			}
+			if err := f.removeFailedDownload(l)(ctx, tx, 0, false); err != nil {
+				return err
+			}
+			return tx.Commit(ctx)
+		}); err != nil {
 			f.log(m, config.ErrorFeedLogLevel, "storing stats of %q failed: %v", l.doc, err)
+			return false, err
 		}
-		return
+		f.log(m, config.InfoFeedLogLevel, "checking %q in shadow mode done", l.doc)
+		return status == allSucceeded, nil
 	}
 
 	// Store stats in database.
@@ -332,31 +472,60 @@ func (l *location) download(m *Manager, f *feed) {
 		const insertSQL = `UPDATE documents ` +
 			`SET (signature, filename) = ($1, $2)` +
 			`WHERE id = $3`
-		_, err := tx.Exec(ctx, insertSQL, signatureData, filename, docID)
+		_, err := tx.Exec(ctx, insertSQL, p.signatureData, p.filename, docID)
 		return err
 	}
 
+	// Normalize the known quirks of the provider.
+	changes, err := m.tf.Transform(p.doc)
+	if err != nil {
+		f.log(m, config.ErrorFeedLogLevel, "transforming %q failed: %v", l.doc, err)
+		return false, err
+	}
+	for i := range changes {
+		c := &changes[i]
+		f.log(m, config.InfoFeedLogLevel, "rule %q changed %s of %q from %q to %q",
+			c.Rule, c.Path, l.doc, c.Original, c.Replaced)
+	}
+
 	var importer *string
 	if !m.cfg.General.AnonymousEventLogging {
 		importer = &m.cfg.Sources.FeedImporter
 	}
 
-	switch err := m.db.Run(context.Background(), func(ctx context.Context, conn *pgxpool.Conn) error {
-		_, err := models.ImportDocumentData(
+	var docID int64
+	switch err := m.runPersistent(func(ctx context.Context, conn *pgxpool.Conn) error {
+		var err error
+		docID, err = models.ImportDocumentData(
 			ctx, conn,
-			doc, data.Bytes(),
+			p.doc, p.raw,
 			importer,
 			m.cfg.Sources.PublishersTLPs,
-			models.ChainInTx(storeStats, storeSignature, f.storeLastChanges(l)),
+			models.ChainInTx(
+				storeStats, storeSignature, f.storeLastChanges(l),
+				f.removeFailedDownload(l), transform.Store(changes),
+				p.queueReceipt(m), m.blobs.Offload(p.raw)),
 			false)
 		return err
-	}, 0); {
+	}); {
 	case errors.Is(err, models.ErrAlreadyInDatabase):
 		f.log(m, config.InfoFeedLogLevel, "not storing duplicate %q: %v", l.doc, err)
 	case err != nil:
 		f.log(m, config.ErrorFeedLogLevel, "storing %q failed: %v", l.doc, err)
-		return
+		return false, err
+	default:
+		ev := eventbus.Event{
+			Type:       models.ImportDocumentEvent,
+			DocumentID: docID,
+			State:      string(models.NewWorkflow),
+		}
+		if importer != nil {
+			ev.Actor = *importer
+		}
+		m.bus.Publish(ev)
 	}
 
 	f.log(m, config.InfoFeedLogLevel, "downloading %q done", l.doc)
+	// Duplicates are no failures of the source.
+	return status&^duplicateFailed == allSucceeded, nil
 }
@@ -0,0 +1,97 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"context"
+	"fmt"
+)
+
+// SourceCredentials are the certificates and secrets
+// of a source which are not part of the SourceInfo.
+type SourceCredentials struct {
+	ClientCertPublic []byte
+	TLSCABundle      []byte
+	// The secrets are encrypted with the key of the manager.
+	ClientCertPrivate    []byte
+	ClientCertPassphrase []byte
+	OAuth2ClientSecret   []byte
+	BasicAuthPassword    []byte
+}
+
+// SourceCredentials returns the certificates of a source.
+// If withSecrets is true the secrets encrypted with
+// the key of the manager are included.
+func (m *Manager) SourceCredentials(
+	ctx context.Context,
+	sourceID int64,
+	withSecrets bool,
+) (*SourceCredentials, error) {
+	var (
+		sc  *SourceCredentials
+		err error
+	)
+	if cerr := m.inManagerContext(ctx, func(m *Manager, _ context.Context) {
+		s := m.findSourceByID(sourceID)
+		if s == nil {
+			err = NoSuchEntryError("no such source")
+			return
+		}
+		sc = &SourceCredentials{
+			ClientCertPublic: clone(s.clientCertPublic),
+			TLSCABundle:      clone(s.tlsCABundle),
+		}
+		if !withSecrets {
+			return
+		}
+		for _, x := range []struct {
+			dst   *[]byte
+			plain []byte
+		}{
+			{&sc.ClientCertPrivate, s.clientCertPrivate},
+			{&sc.ClientCertPassphrase, s.clientCertPassphrase},
+			{&sc.OAuth2ClientSecret, s.oauth2ClientSecret},
+			{&sc.BasicAuthPassword, s.basicAuthPassword},
+		} {
+			if *x.dst, err = m.encrypt(x.plain); err != nil {
+				err = fmt.Errorf("encrypting secret failed: %w", err)
+				return
+			}
+		}
+	}); cerr != nil {
+		return nil, cerr
+	}
+	return sc, err
+}
+
+// EncryptSecret encrypts a secret with the key of the manager.
+func (m *Manager) EncryptSecret(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return m.encrypt(data)
+}
+
+// DecryptSecret decrypts a secret encrypted with the key of the manager.
+// Secrets exported by other instances can only be decrypted
+// if they share the same key.
+func (m *Manager) DecryptSecret(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	// The nonce of AES-GCM precedes the cipher text.
+	if len(data) < 12 {
+		return nil, InvalidArgumentError("encrypted secret is too short")
+	}
+	plain, err := m.decrypt(data)
+	if err != nil {
+		return nil, InvalidArgumentError(fmt.Sprintf("decrypting secret failed: %v", err))
+	}
+	return plain, nil
+}
@@ -0,0 +1,281 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+)
+
+// maxRetryShift limits the exponential growth of the retry delay.
+const maxRetryShift = 10
+
+// FailedDownload is an advisory which could not be downloaded
+// or imported after all retries.
+type FailedDownload struct {
+	ID          int64     `json:"id"`
+	SourceID    int64     `json:"source_id"`
+	Source      string    `json:"source"`
+	FeedID      int64     `json:"feed_id"`
+	Feed        string    `json:"feed"`
+	URL         string    `json:"url"`
+	Updated     time.Time `json:"updated"`
+	Attempts    int       `json:"attempts"`
+	FirstFailed time.Time `json:"first_failed"`
+	LastFailed  time.Time `json:"last_failed"`
+	Error       string    `json:"error"`
+	Dismissed   bool      `json:"dismissed"`
+}
+
+// retryDelay returns the delay before the given attempt.
+// The delay doubles with every attempt.
+func retryDelay(delay time.Duration, attempts int) time.Duration {
+	return delay << min(max(attempts-1, 0), maxRetryShift)
+}
+
+// failed handles a download which could not be downloaded or imported.
+// The download is retried later until the retries are exhausted.
+// After that it is recorded in the failed downloads.
+func (dj *downloadJob) failed(m *Manager, err error) {
+	l, f := &dj.l, dj.f
+	if retries := m.cfg.Sources.DownloadRetries; l.attempts < retries {
+		f.log(m, config.WarnFeedLogLevel, "retrying %q later (attempt %d of %d)",
+			l.doc, l.attempts+1, retries)
+		dj.release(m, true)
+		return
+	}
+	f.log(m, config.ErrorFeedLogLevel, "giving up on %q after %d attempts", l.doc, l.attempts+1)
+	if !f.invalid.Load() {
+		if err := m.storeFailedDownload(f, l, err); err != nil {
+			f.log(m, config.ErrorFeedLogLevel,
+				"storing failed download %q failed: %v", l.doc, err)
+		}
+	}
+	dj.finish(m)
+}
+
+// nullableURL returns the string of an optional URL.
+func nullableURL(u *url.URL) *string {
+	if u == nil {
+		return nil
+	}
+	s := u.String()
+	return &s
+}
+
+// storeFailedDownload records a download which exhausted its retries.
+// A dismissed download is only reported again for a newer version.
+func (m *Manager) storeFailedDownload(f *feed, l *location, failure error) error {
+	const upsertSQL = `INSERT INTO failed_downloads ` +
+		`(feeds_id, url, hash, signature, updated, attempts, error) ` +
+		`VALUES ($1, $2, $3, $4, $5, $6, $7) ` +
+		`ON CONFLICT (feeds_id, url) DO UPDATE SET ` +
+		`hash = EXCLUDED.hash, ` +
+		`signature = EXCLUDED.signature, ` +
+		`updated = EXCLUDED.updated, ` +
+		`attempts = failed_downloads.attempts + EXCLUDED.attempts, ` +
+		`last_failed = current_timestamp, ` +
+		`error = EXCLUDED.error, ` +
+		`dismissed = failed_downloads.dismissed AND failed_downloads.updated >= EXCLUDED.updated`
+	msg := "unknown error"
+	if failure != nil {
+		msg = failure.Error()
+	}
+	return m.runPersistent(func(ctx context.Context, conn *pgxpool.Conn) error {
+		_, err := conn.Exec(ctx, upsertSQL,
+			f.id, l.doc.String(),
+			nullableURL(l.hash), nullableURL(l.signature),
+			l.updated, l.attempts+1, msg)
+		return err
+	})
+}
+
+// removeFailedDownload is intended to be called in the transaction
+// storing the imported document. A successful download resolves
+// an earlier failure of the location.
+func (f *feed) removeFailedDownload(l *location) func(context.Context, pgx.Tx, int64, bool) error {
+	return func(ctx context.Context, tx pgx.Tx, _ int64, _ bool) error {
+		if f.invalid.Load() {
+			return nil
+		}
+		const deleteSQL = `DELETE FROM failed_downloads WHERE feeds_id = $1 AND url = $2`
+		_, err := tx.Exec(ctx, deleteSQL, f.id, l.doc.String())
+		return err
+	}
+}
+
+// FailedDownloads returns the downloads which exhausted their retries.
+// If sourceID or feedID are given only the failed downloads of
+// this source or feed are returned. Dismissed downloads are only
+// returned if dismissed is set.
+func (m *Manager) FailedDownloads(
+	ctx context.Context,
+	sourceID, feedID *int64,
+	dismissed bool,
+) ([]FailedDownload, error) {
+	var (
+		where strings.Builder
+		args  []any
+	)
+	where.WriteString(`TRUE`)
+	if sourceID != nil {
+		args = append(args, *sourceID)
+		fmt.Fprintf(&where, ` AND s.id = $%d`, len(args))
+	}
+	if feedID != nil {
+		args = append(args, *feedID)
+		fmt.Fprintf(&where, ` AND f.id = $%d`, len(args))
+	}
+	if !dismissed {
+		where.WriteString(` AND NOT fd.dismissed`)
+	}
+	sql := `SELECT fd.id, s.id, s.name, f.id, f.label, fd.url, fd.updated, ` +
+		`fd.attempts, fd.first_failed, fd.last_failed, fd.error, fd.dismissed ` +
+		`FROM failed_downloads fd ` +
+		`JOIN feeds f ON fd.feeds_id = f.id ` +
+		`JOIN sources s ON f.sources_id = s.id ` +
+		`WHERE ` + where.String() + ` ` +
+		`ORDER BY fd.last_failed DESC, fd.id`
+	var failed []FailedDownload
+	if err := m.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, sql, args...)
+			var err error
+			failed, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (FailedDownload, error) {
+				var fd FailedDownload
+				err := row.Scan(
+					&fd.ID, &fd.SourceID, &fd.Source, &fd.FeedID, &fd.Feed,
+					&fd.URL, &fd.Updated, &fd.Attempts,
+					&fd.FirstFailed, &fd.LastFailed, &fd.Error, &fd.Dismissed)
+				return fd, err
+			})
+			return err
+		}, 0,
+	); err != nil {
+		return nil, fmt.Errorf("loading failed downloads failed: %w", err)
+	}
+	return failed, nil
+}
+
+// RequeueFailedDownloads puts the failed downloads with the given ids
+// back into the queues of their feeds. They are retried with the full
+// number of retries. Returns the ids of the requeued downloads.
+func (m *Manager) RequeueFailedDownloads(ctx context.Context, ids []int64) ([]int64, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	type failedLocation struct {
+		id     int64
+		feedID int64
+		loc    location
+	}
+	const (
+		selectSQL = `SELECT id, feeds_id, url, hash, signature, updated ` +
+			`FROM failed_downloads WHERE id = ANY($1)`
+		deleteSQL = `DELETE FROM failed_downloads WHERE id = ANY($1)`
+	)
+	var failed []failedLocation
+	if err := m.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, selectSQL, ids)
+			var err error
+			failed, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (failedLocation, error) {
+				var (
+					fl              failedLocation
+					doc             string
+					hash, signature *string
+				)
+				if err := row.Scan(&fl.id, &fl.feedID, &doc, &hash, &signature, &fl.loc.updated); err != nil {
+					return fl, err
+				}
+				var err error
+				if fl.loc.doc, err = url.Parse(doc); err != nil {
+					return fl, fmt.Errorf("invalid URL %q: %w", doc, err)
+				}
+				if hash != nil {
+					// A broken hash URL only fails the checksum check.
+					fl.loc.hash, _ = url.Parse(*hash)
+				}
+				if signature != nil {
+					fl.loc.signature, _ = url.Parse(*signature)
+				}
+				return fl, nil
+			})
+			return err
+		}, 0,
+	); err != nil {
+		return nil, fmt.Errorf("loading failed downloads failed: %w", err)
+	}
+	var requeued []int64
+	m.inManager(func(m *Manager, _ context.Context) {
+		for i := range failed {
+			fl := &failed[i]
+			f := m.findFeedByID(fl.feedID)
+			if f == nil || f.invalid.Load() || f.draining {
+				continue
+			}
+			requeued = append(requeued, fl.id)
+			// Already waiting with the same or a newer version.
+			if f.sameOrNewer()(&fl.loc) {
+				continue
+			}
+			f.queue = append(f.queue, fl.loc)
+			slices.SortFunc(f.queue, func(a, b location) int {
+				return a.updated.Compare(b.updated)
+			})
+			f.log(m, config.InfoFeedLogLevel, "requeued failed download %q", fl.loc.doc)
+		}
+	})
+	if len(requeued) == 0 {
+		return nil, nil
+	}
+	if err := m.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			_, err := conn.Exec(rctx, deleteSQL, requeued)
+			return err
+		}, 0,
+	); err != nil {
+		return nil, fmt.Errorf("removing requeued downloads failed: %w", err)
+	}
+	return requeued, nil
+}
+
+// DismissFailedDownloads marks the failed downloads with the given ids
+// as dismissed. Returns the number of dismissed downloads.
+func (m *Manager) DismissFailedDownloads(ctx context.Context, ids []int64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	const updateSQL = `UPDATE failed_downloads SET dismissed = TRUE ` +
+		`WHERE id = ANY($1) AND NOT dismissed`
+	var dismissed int64
+	if err := m.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tag, err := conn.Exec(rctx, updateSQL, ids)
+			dismissed = tag.RowsAffected()
+			return err
+		}, 0,
+	); err != nil {
+		return 0, fmt.Errorf("dismissing failed downloads failed: %w", err)
+	}
+	return dismissed, nil
+}
@@ -0,0 +1,46 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	for _, tc := range []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, time.Minute},
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{100, time.Minute << maxRetryShift},
+	} {
+		if got := retryDelay(time.Minute, tc.attempts); got != tc.want {
+			t.Errorf("attempts %d: got %v, want %v", tc.attempts, got, tc.want)
+		}
+	}
+}
+
+func TestFindWaitingRetry(t *testing.T) {
+	f := &feed{queue: []location{
+		{id: 1, state: waiting},
+		{id: 2, state: waiting, attempts: 1, retryAt: time.Now().Add(time.Hour)},
+	}}
+	// The failed download is not ready before its retry time.
+	if l := f.findWaiting(); l == nil || l.id != 1 {
+		t.Fatalf("expected location 1, got %+v", l)
+	}
+	f.queue[1].retryAt = time.Now().Add(-time.Second)
+	if l := f.findWaiting(); l == nil || l.id != 2 {
+		t.Fatalf("expected location 2, got %+v", l)
+	}
+}
@@ -0,0 +1,254 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FetchWindow is a period in which the documents of a source are downloaded.
+// It is either a daily time range in UTC like "22:00-06:00" or a cron
+// expression with the five fields minute, hour, day of month, month and
+// day of week like "* 0-5 * * 1-5" matching the minutes of the period.
+type FetchWindow struct {
+	expr string
+	// from and to are the minutes of the day of a time range.
+	from, to int
+	cron     *cronExpr
+}
+
+// FetchWindows are the periods in which the documents of a source are downloaded.
+// Without any windows the documents are downloaded at any time.
+type FetchWindows []*FetchWindow
+
+// cronExpr are the allowed values of the fields of a cron expression.
+type cronExpr struct {
+	minutes, hours, days, months, weekdays uint64
+	// anyDay and anyWeekday tell if the fields are unrestricted.
+	anyDay, anyWeekday bool
+}
+
+// ParseFetchWindow parses a time range or a cron expression.
+func ParseFetchWindow(s string) (*FetchWindow, error) {
+	s = strings.TrimSpace(s)
+	if fields := strings.Fields(s); len(fields) == 5 {
+		cron, err := parseCron(fields)
+		if err != nil {
+			return nil, InvalidArgumentError(
+				fmt.Sprintf("invalid fetch window %q: %v", s, err))
+		}
+		return &FetchWindow{expr: strings.Join(fields, " "), cron: cron}, nil
+	}
+	start, end, ok := strings.Cut(s, "-")
+	if !ok {
+		return nil, InvalidArgumentError(
+			fmt.Sprintf("invalid fetch window %q: neither time range nor cron expression", s))
+	}
+	from, err := parseClock(start)
+	if err != nil {
+		return nil, InvalidArgumentError(fmt.Sprintf("invalid fetch window %q: %v", s, err))
+	}
+	to, err := parseClock(end)
+	if err != nil {
+		return nil, InvalidArgumentError(fmt.Sprintf("invalid fetch window %q: %v", s, err))
+	}
+	if from == to {
+		return nil, InvalidArgumentError(fmt.Sprintf("invalid fetch window %q: empty time range", s))
+	}
+	return &FetchWindow{
+		expr: fmt.Sprintf("%02d:%02d-%02d:%02d", from/60, from%60, to/60, to%60),
+		from: from,
+		to:   to,
+	}, nil
+}
+
+// ParseFetchWindows parses a list of fetch windows. Empty entries are ignored.
+func ParseFetchWindows(s []string) (FetchWindows, error) {
+	if s == nil {
+		return nil, nil
+	}
+	fws := make(FetchWindows, 0, len(s))
+	for _, x := range s {
+		if strings.TrimSpace(x) == "" {
+			continue
+		}
+		fw, err := ParseFetchWindow(x)
+		if err != nil {
+			return nil, err
+		}
+		fws = append(fws, fw)
+	}
+	return fws, nil
+}
+
+// String returns the normalized form of the window.
+func (fw *FetchWindow) String() string {
+	return fw.expr
+}
+
+// Contains checks if the given time lies in the window.
+func (fw *FetchWindow) Contains(t time.Time) bool {
+	t = t.UTC()
+	if fw.cron != nil {
+		return fw.cron.matches(t)
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if fw.from < fw.to {
+		return minute >= fw.from && minute < fw.to
+	}
+	// The range wraps around midnight.
+	return minute >= fw.from || minute < fw.to
+}
+
+// Open checks if downloads are allowed at the given time.
+func (fws FetchWindows) Open(t time.Time) bool {
+	if len(fws) == 0 {
+		return true
+	}
+	for _, fw := range fws {
+		if fw.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Strings returns the windows as strings.
+func (fws FetchWindows) Strings() []string {
+	if fws == nil {
+		return nil
+	}
+	s := make([]string, len(fws))
+	for i, fw := range fws {
+		s[i] = fw.String()
+	}
+	return s
+}
+
+// parseClock parses a time of the day in the form HH:MM.
+// 24:00 is accepted as the end of the day.
+func parseClock(s string) (int, error) {
+	hs, ms, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, fmt.Errorf("time %q is not in the form HH:MM", s)
+	}
+	h, err := strconv.Atoi(hs)
+	if err != nil {
+		return 0, fmt.Errorf("invalid hour in %q", s)
+	}
+	m, err := strconv.Atoi(ms)
+	if err != nil {
+		return 0, fmt.Errorf("invalid minute in %q", s)
+	}
+	if h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("time %q out of range", s)
+	}
+	return (h*60 + m) % (24 * 60), nil
+}
+
+// parseCron parses the five fields of a cron expression.
+func parseCron(fields []string) (*cronExpr, error) {
+	var (
+		ce  cronExpr
+		err error
+	)
+	if ce.minutes, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if ce.hours, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if ce.days, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	if ce.months, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	if ce.weekdays, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+	// Sunday is 0 and 7.
+	if ce.weekdays&(1<<7) != 0 {
+		ce.weekdays |= 1
+	}
+	ce.anyDay = fields[2] == "*"
+	ce.anyWeekday = fields[4] == "*"
+	return &ce, nil
+}
+
+// parseCronField parses a comma separated list of values,
+// ranges and steps into a bit set.
+func parseCronField(field string, lo, hi int) (uint64, error) {
+	var bits uint64
+	for part := range strings.SplitSeq(field, ",") {
+		rng, stepS, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepS); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepS)
+			}
+		}
+		var from, to int
+		switch {
+		case rng == "*":
+			from, to = lo, hi
+		case strings.Contains(rng, "-"):
+			fs, ts, _ := strings.Cut(rng, "-")
+			var err1, err2 error
+			from, err1 = strconv.Atoi(fs)
+			to, err2 = strconv.Atoi(ts)
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+		default:
+			v, err := strconv.Atoi(rng)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", rng)
+			}
+			from, to = v, v
+			if hasStep {
+				to = hi
+			}
+		}
+		if from < lo || to > hi || from > to {
+			return 0, fmt.Errorf("%q out of range [%d, %d]", part, lo, hi)
+		}
+		for v := from; v <= to; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// matches checks if the minute of the given time matches the expression.
+// As in cron a restricted day of month and a restricted day
+// of week match if either of them matches.
+func (ce *cronExpr) matches(t time.Time) bool {
+	if ce.minutes&(1<<t.Minute()) == 0 ||
+		ce.hours&(1<<t.Hour()) == 0 ||
+		ce.months&(1<<int(t.Month())) == 0 {
+		return false
+	}
+	day := ce.days&(1<<t.Day()) != 0
+	weekday := ce.weekdays&(1<<int(t.Weekday())) != 0
+	switch {
+	case ce.anyDay && ce.anyWeekday:
+		return true
+	case ce.anyDay:
+		return weekday
+	case ce.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
@@ -0,0 +1,106 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFetchWindow(t *testing.T) {
+	// 2026-10-14 is a Wednesday.
+	at := func(s string) time.Time {
+		t.Helper()
+		v, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	for _, tc := range []struct {
+		window string
+		time   string
+		want   bool
+	}{
+		{"22:00-06:00", "2026-10-14T23:30:00Z", true},
+		{"22:00-06:00", "2026-10-14T05:59:00Z", true},
+		{"22:00-06:00", "2026-10-14T06:00:00Z", false},
+		{"22:00-06:00", "2026-10-14T12:00:00+02:00", false},
+		{"22:00-06:00", "2026-10-15T01:00:00+02:00", true},
+		{"08:00-24:00", "2026-10-14T23:59:00Z", true},
+		{"08:00-24:00", "2026-10-14T07:59:00Z", false},
+		{"* 0-5 * * *", "2026-10-14T05:59:00Z", true},
+		{"* 0-5 * * *", "2026-10-14T06:00:00Z", false},
+		{"*/15 * * * *", "2026-10-14T10:30:00Z", true},
+		{"*/15 * * * *", "2026-10-14T10:31:00Z", false},
+		{"* * * * 6,7", "2026-10-18T10:00:00Z", true},
+		{"* * * * 6,7", "2026-10-14T10:00:00Z", false},
+		{"* * 1 * 3", "2026-10-14T10:00:00Z", true},
+		{"* * 1 * 3", "2026-10-01T10:00:00Z", true},
+		{"* * 1 * 3", "2026-10-02T10:00:00Z", false},
+		{"* * * 11-12 *", "2026-10-14T10:00:00Z", false},
+	} {
+		fw, err := ParseFetchWindow(tc.window)
+		if err != nil {
+			t.Fatalf("parsing %q failed: %v", tc.window, err)
+		}
+		if got := fw.Contains(at(tc.time)); got != tc.want {
+			t.Errorf("%q contains %s: got %t, want %t", tc.window, tc.time, got, tc.want)
+		}
+	}
+}
+
+func TestParseFetchWindow(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"22:00",
+		"22:00-22:00",
+		"25:00-06:00",
+		"22:60-06:00",
+		"24:01-06:00",
+		"* * * *",
+		"60 * * * *",
+		"* * 0 * *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+	} {
+		if _, err := ParseFetchWindow(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+	fw, err := ParseFetchWindow(" 6:5 - 8:00 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fw.String(); got != "06:05-08:00" {
+		t.Errorf("got %q, want %q", got, "06:05-08:00")
+	}
+}
+
+func TestFetchWindowsOpen(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	var none FetchWindows
+	if !none.Open(now) {
+		t.Error("no windows must be open")
+	}
+	fws, err := ParseFetchWindows([]string{"22:00-06:00", "", "* 12 * * *"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fws) != 2 {
+		t.Fatalf("got %d windows, want 2", len(fws))
+	}
+	if !fws.Open(now) {
+		t.Error("expected open at noon")
+	}
+	if fws.Open(now.Add(2 * time.Hour)) {
+		t.Error("expected closed in the afternoon")
+	}
+}
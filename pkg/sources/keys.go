@@ -38,7 +38,7 @@ func (m *Manager) openPGPKeys(source *source) (*crypto.KeyRing, error) {
 		return keys, nil
 	}
 	keys, _ := crypto.NewKeyRing(nil)
-	cpmd := m.pmdCache.pmd(source.url, m.cfg)
+	cpmd := m.pmdCache.pmd(source.url, m.cfg, source.credentials())
 	if !cpmd.Valid() {
 		// Try again soon.
 		m.keysCache.SetWithExpiration(source.id, keys, holdingPMDsDuration)
@@ -56,7 +56,7 @@ func (m *Manager) openPGPKeys(source *source) (*crypto.KeyRing, error) {
 		m.keysCache.SetWithExpiration(source.id, keys, holdingPMDsDuration)
 		return nil, fmt.Errorf("invalid PMD url: %q", source.url)
 	}
-	client := source.httpClient(m)
+	client := source.cachedHTTPClient(m)
 	defer client.CloseIdleConnections()
 	for i := range pmd.PGPKeys {
 		key := &pmd.PGPKeys[i]
@@ -0,0 +1,174 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gocsaf/csaf/v3/csaf"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AggregatorListing is the entry of a provider or publisher
+// as published by an aggregator.
+type AggregatorListing struct {
+	// Aggregator is the URL of the listing aggregator.
+	Aggregator string
+	// URL is the URL of the listed PMD.
+	URL string
+	// Publisher is the publisher as published by the aggregator.
+	Publisher *csaf.Publisher
+	// Role is the role as published by the aggregator.
+	Role *csaf.MetadataRole
+	// Mirrors are the URLs of the PMDs mirrored by the aggregator.
+	Mirrors []string
+}
+
+// VerifyListings checks the PMDs of the active sources against the
+// metadata the listing aggregators publish for them. As the aggregators
+// do not publish hashes of the PMDs the publisher and the role are compared
+// and the fingerprints of the OpenPGP keys of mirrored PMDs have to match.
+// The attention flag of a source is raised if new mismatches are found.
+func (m *Manager) VerifyListings(listings []AggregatorListing) {
+	type candidate struct {
+		id    int64
+		url   string
+		creds *credentials
+	}
+	var candidates []candidate
+	m.inManager(func(m *Manager, _ context.Context) {
+		for _, s := range m.sources {
+			if s.active && slices.ContainsFunc(listings, func(l AggregatorListing) bool {
+				return l.URL == s.url
+			}) {
+				candidates = append(candidates, candidate{
+					id:    s.id,
+					url:   s.url,
+					creds: s.credentials(),
+				})
+			}
+		}
+	})
+	issues := map[int64][]string{}
+	for _, c := range candidates {
+		cpmd := m.pmdCache.pmd(c.url, m.cfg, c.creds)
+		pmd, err := cpmd.Model()
+		if err != nil {
+			// Invalid PMDs are reported by the regular source checks.
+			continue
+		}
+		var found []string
+		for i := range listings {
+			if l := &listings[i]; l.URL == c.url {
+				found = append(found, m.verifyListing(pmd, l)...)
+			}
+		}
+		issues[c.id] = found
+	}
+	m.fns <- func(m *Manager, ctx context.Context) {
+		m.applyListingIssues(ctx, issues)
+	}
+}
+
+// verifyListing returns the differences between the PMD and the listing.
+func (m *Manager) verifyListing(pmd *csaf.ProviderMetadata, l *AggregatorListing) []string {
+	var issues []string
+	mismatch := func(format string, args ...any) {
+		issues = append(issues,
+			fmt.Sprintf("aggregator %s: ", l.Aggregator)+fmt.Sprintf(format, args...))
+	}
+	if !samePublisher(pmd.Publisher, l.Publisher) {
+		mismatch("publisher does not match")
+	}
+	if l.Role != nil && (pmd.Role == nil || *pmd.Role != *l.Role) {
+		mismatch("role does not match")
+	}
+	fingerprints := keyFingerprints(pmd.PGPKeys)
+	for _, mirror := range l.Mirrors {
+		mpmd, err := m.PMD(mirror).Model()
+		if err != nil {
+			mismatch("mirror %s is not loadable", mirror)
+			continue
+		}
+		if !slices.Equal(fingerprints, keyFingerprints(mpmd.PGPKeys)) {
+			mismatch("OpenPGP keys of mirror %s do not match", mirror)
+		}
+	}
+	return issues
+}
+
+// samePublisher checks if the identifying parts of the publishers are equal.
+func samePublisher(a, b *csaf.Publisher) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	equal := func(x, y *string) bool {
+		return x == nil && y == nil || x != nil && y != nil && *x == *y
+	}
+	return equal(a.Name, b.Name) &&
+		equal(a.Namespace, b.Namespace) &&
+		(a.Category == nil && b.Category == nil ||
+			a.Category != nil && b.Category != nil && *a.Category == *b.Category)
+}
+
+// keyFingerprints returns the sorted upper case fingerprints of the keys.
+func keyFingerprints(keys []csaf.PGPKey) []string {
+	fingerprints := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key.Fingerprint != "" {
+			fingerprints = append(fingerprints, strings.ToUpper(string(key.Fingerprint)))
+		}
+	}
+	slices.Sort(fingerprints)
+	return fingerprints
+}
+
+// applyListingIssues stores the found issues at the sources
+// and raises the attention of the sources with new issues.
+func (m *Manager) applyListingIssues(ctx context.Context, issues map[int64][]string) {
+	const updateSQL = `UPDATE sources SET checksum_updated = $1 WHERE id = $2`
+	message := func(issues []string) string {
+		return "Source does not match aggregator listing: " + strings.Join(issues, " ")
+	}
+	now := time.Now().UTC()
+	for id, found := range issues {
+		s := m.findSourceByID(id)
+		if s == nil {
+			continue
+		}
+		raise := slices.ContainsFunc(found, func(issue string) bool {
+			return !slices.Contains(s.aggregatorIssues, issue)
+		})
+		s.aggregatorIssues = found
+		if !raise {
+			continue
+		}
+		slog.Warn("source does not match aggregator listing",
+			"id", s.id, "url", s.url, "issues", found)
+		if err := m.db.Run(
+			ctx,
+			func(ctx context.Context, conn *pgxpool.Conn) error {
+				batch := &pgx.Batch{}
+				batch.Queue(updateSQL, now, s.id)
+				batch.Queue(insertSourceActionSQL, s.id, string(AttentionAction), message(found))
+				return conn.SendBatch(ctx, batch).Close()
+			}, 0,
+		); err != nil {
+			slog.Error("raising attention failed", "id", s.id, "err", err)
+			continue
+		}
+		s.checksumUpdated = now
+	}
+}
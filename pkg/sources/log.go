@@ -14,6 +14,7 @@ import (
 	"log/slog"
 
 	"github.com/ISDuBA/ISDuBA/pkg/config"
+	"github.com/ISDuBA/ISDuBA/pkg/database"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -30,7 +31,8 @@ func (f *feed) log(m *Manager, level config.FeedLogLevel, format string, args ..
 			_, err := con.Exec(ctx, sql, f.id, level.String(), message)
 			return err
 		}, 0,
-	); err != nil {
+	); err != nil && !database.IsConnectionError(err) {
+		// Outages of the database are reported centrally.
 		slog.Error("database error", "err", err)
 	}
 }
@@ -14,18 +14,21 @@ import (
 	"fmt"
 	"iter"
 	"log/slog"
-	"math/rand/v2"
 	"net/url"
 	"regexp"
 	"slices"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
+	"github.com/ISDuBA/ISDuBA/pkg/blobs"
 	"github.com/ISDuBA/ISDuBA/pkg/config"
 	"github.com/ISDuBA/ISDuBA/pkg/database"
 	"github.com/ISDuBA/ISDuBA/pkg/database/query"
+	"github.com/ISDuBA/ISDuBA/pkg/eventbus"
+	"github.com/ISDuBA/ISDuBA/pkg/httpcache"
+	"github.com/ISDuBA/ISDuBA/pkg/scheduler"
+	"github.com/ISDuBA/ISDuBA/pkg/transform"
 	"github.com/gocsaf/csaf/v3/csaf"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -72,10 +75,12 @@ type downloadJob struct {
 type Manager struct {
 	cfg  *config.Config
 	db   *database.DB
+	bus  *eventbus.Bus
 	fns  chan func(*Manager, context.Context)
 	jobs chan downloadJob
-	done bool
-	rnd  *rand.Rand
+
+	pipeline *pipeline
+	done     bool
 
 	cipherKey []byte
 
@@ -84,13 +89,29 @@ type Manager struct {
 	pmdCache  *pmdCache
 	keysCache *keysCache
 
-	val csaf.RemoteValidator
+	val       csaf.RemoteValidator
+	tf        *transform.Transformer
+	blobs     *blobs.Store
+	httpCache *httpcache.Cache
 
 	usedSlots int
 	uniqueID  int64
 
-	blockSourceChecking  bool
-	blockFeedLogCleaning bool
+	// nextSource is the position of the round robin over the sources.
+	nextSource int
+	// quantumGranted tells if the source at nextSource got its share.
+	quantumGranted bool
+
+	blockSourceChecking   bool
+	blockFeedLogCleaning  bool
+	blockAnomalyDetection bool
+	blockMetricsFlushing  bool
+
+	refreshTask  *scheduler.Task
+	checkingTask *scheduler.Task
+	cleaningTask *scheduler.Task
+	// anomalyTask is nil if the anomaly detection is disabled.
+	anomalyTask *scheduler.Task
 }
 
 // SourceUpdateResult is return by UpdateSource.
@@ -106,10 +127,16 @@ const (
 )
 
 // Stats are some statistics about feeds and sources.
+// The successes, failures and bytes of the downloads are counted
+// since the start of the server. Their history is recorded in
+// the database and can be queried by time range.
 type Stats struct {
-	Downloading int  `json:"downloading"`
-	Waiting     int  `json:"waiting"`
-	Healthy     bool `json:"healthy"`
+	Downloading int   `json:"downloading"`
+	Waiting     int   `json:"waiting"`
+	Healthy     bool  `json:"healthy"`
+	Successes   int64 `json:"successes"`
+	Failures    int64 `json:"failures"`
+	Bytes       int64 `json:"bytes"`
 }
 
 // SourceInfo are infos about a source.
@@ -118,19 +145,34 @@ type SourceInfo struct {
 	Name                    string
 	URL                     string
 	Active                  bool
+	Shadow                  bool
+	Priority                Priority
 	Attention               bool
 	Status                  []string
+	AggregatorIssues        []string
 	Rate                    *float64
 	Slots                   *int
+	Weight                  *int
 	Headers                 []string
 	StrictMode              *bool
 	Secure                  *bool
 	SignatureCheck          *bool
 	Age                     *time.Duration
 	IgnorePatterns          []*regexp.Regexp
+	FetchWindows            []string
 	HasClientCertPublic     bool
 	HasClientCertPrivate    bool
 	HasClientCertPassphrase bool
+	OAuth2TokenURL          *string
+	OAuth2ClientID          *string
+	HasOAuth2ClientSecret   bool
+	OAuth2Scopes            []string
+	BasicAuthUser           *string
+	HasBasicAuthPassword    bool
+	HasTLSCABundle          bool
+	TLSPinnedCerts          []string
+	ReceiptURL              *string
+	LastActivity            time.Time
 	Stats                   *Stats
 }
 
@@ -156,12 +198,13 @@ type SourceSubscriptions struct {
 
 // FeedInfo are infos about a feed.
 type FeedInfo struct {
-	ID    int64
-	Label string
-	URL   *url.URL
-	Rolie bool
-	Lvl   config.FeedLogLevel
-	Stats *Stats
+	ID           int64
+	Label        string
+	URL          *url.URL
+	Rolie        bool
+	Lvl          config.FeedLogLevel
+	LastActivity time.Time
+	Stats        *Stats
 }
 
 func (sur SourceUpdateResult) String() string {
@@ -181,23 +224,53 @@ func (sur SourceUpdateResult) String() string {
 func NewManager(
 	cfg *config.Config,
 	db *database.DB,
+	bus *eventbus.Bus,
 	val csaf.RemoteValidator,
+	tf *transform.Transformer,
+	bs *blobs.Store,
+	hc *httpcache.Cache,
+	tasks *scheduler.Registry,
 ) (*Manager, error) {
 	cipherKey, err := createCipherKey(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("creating cipher failed: %w", err)
 	}
-	return &Manager{
+	m := &Manager{
 		cfg:       cfg,
 		db:        db,
+		bus:       bus,
 		fns:       make(chan func(*Manager, context.Context)),
 		jobs:      make(chan downloadJob),
-		rnd:       rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+		pipeline:  newPipeline(&cfg.Sources),
 		cipherKey: cipherKey,
-		pmdCache:  newPMDCache(),
+		pmdCache:  newPMDCache(hc),
 		keysCache: newKeysCache(cfg.Sources.OpenPGPCaching),
 		val:       val,
-	}, nil
+		tf:        tf,
+		blobs:     bs,
+		httpCache: hc,
+		refreshTask: tasks.Register("feed_refresh",
+			"Refreshes the indices of the active feeds.",
+			cfg.Sources.FeedRefresh),
+		checkingTask: tasks.Register("source_checking",
+			"Checks the provider metadata of the sources for changes.",
+			cfg.Sources.Checking),
+		cleaningTask: tasks.Register("feed_log_cleaning",
+			"Removes outdated feed log entries and feed metrics.",
+			feedLogCleaningDuration),
+	}
+	if cfg.Anomalies.Enabled {
+		m.anomalyTask = tasks.Register("import_anomalies",
+			"Detects unusual daily import volumes of the sources.",
+			cfg.Anomalies.Interval)
+	}
+	// Resume the imports as soon as the database is back.
+	db.OnOutage(func(ev database.OutageEvent) {
+		if ev.Available {
+			m.backgroundPing()
+		}
+	})
+	return m, nil
 }
 
 func (m *Manager) numActiveFeeds() int {
@@ -225,27 +298,6 @@ func (m *Manager) activeFeeds() iter.Seq[*feed] {
 	}
 }
 
-// shuffledActiveFeeds iterates in a shuffled order over
-// the feeds of the active sources.
-func (m *Manager) shuffledActiveFeeds() iter.Seq[*feed] {
-	return func(yield func(*feed) bool) {
-		var active []*feed
-		for _, s := range m.sources {
-			if s.active {
-				active = append(active, s.feeds...)
-			}
-		}
-		m.rnd.Shuffle(len(active), func(i, j int) {
-			active[i], active[j] = active[j], active[i]
-		})
-		for _, f := range active {
-			if !yield(f) {
-				return
-			}
-		}
-	}
-}
-
 func (m *Manager) allFeeds() iter.Seq[*feed] {
 	return func(yield func(*feed) bool) {
 		for _, s := range m.sources {
@@ -282,47 +334,84 @@ func (m *Manager) findSourceByName(name string) *source {
 }
 
 // refreshFeeds checks if there are feeds that need reloading
-// and does so in that case.
-func (m *Manager) refreshFeeds() {
+// and does so in that case. If forced all active feeds are reloaded.
+func (m *Manager) refreshFeeds(force bool) {
+	if !force && m.refreshTask.Paused() {
+		return
+	}
+	var done func(error)
 	now := time.Now()
 	for f := range m.activeFeeds() {
 		// Does the feed need a refresh?
-		if !f.refreshBlocked && (f.nextCheck.IsZero() || !now.Before(f.nextCheck)) {
+		if !f.invalid.Load() && !f.refreshBlocked && (force || f.nextCheck.IsZero() || !now.Before(f.nextCheck)) {
+			if done == nil {
+				done = m.refreshTask.Start()
+			}
 			slog.Debug("refreshing feed", "feed", f.id, "source", f.source.name)
 			f.refresh(m)
 			// Even if there was an error try again later.
-			f.nextCheck = time.Now().Add(m.cfg.Sources.FeedRefresh)
+			f.nextCheck = time.Now().Add(f.source.priority.feedRefresh(&m.cfg.Sources))
 		}
 	}
+	// The errors of the feeds are recorded in the feed logs.
+	if done != nil {
+		done(nil)
+	}
 }
 
 // startDownloads starts downloads if there are enough slots and
-// there are things to download.
+// there are things to download. The slots are shared between the
+// active sources by a deficit round robin weighted by the weights
+// of the sources, so that the backlog of a large source cannot
+// starve the other sources. Sources outside of their fetch windows
+// are skipped. The sources of a lower priority class only get slots
+// if the sources of the higher classes have nothing to download.
 func (m *Manager) startDownloads() {
-	for m.usedSlots < m.cfg.Sources.DownloadSlots {
+	total := m.cfg.Sources.DownloadSlots
+	now := time.Now()
+	for m.usedSlots < total && len(m.sources) > 0 {
 		started := false
-		for f := range m.shuffledActiveFeeds() {
-			// Has this feed a free slot?
-			maxSlots := min(m.cfg.Sources.MaxSlotsPerSource, m.cfg.Sources.DownloadSlots)
-			if f.source.slots != nil {
-				maxSlots = min(maxSlots, *f.source.slots)
-			}
-			if f.source.usedSlots >= maxSlots {
+		top := m.topPriority(now)
+		for range len(m.sources) {
+			m.nextSource %= len(m.sources)
+			s := m.sources[m.nextSource]
+			if s.priority.rank() > top {
+				m.advanceSource()
 				continue
 			}
-			// Find a candidate to download.
-			loc := f.findWaiting()
-			if loc == nil {
+			maxSlots := s.maxSlots(&m.cfg.Sources)
+			open := s.fetchWindows.Open(now)
+			if !s.active || !open || s.usedSlots >= maxSlots || !s.hasWaiting() {
+				if !s.active || !open || !s.hasWaiting() {
+					s.deficit = 0
+				}
+				m.advanceSource()
 				continue
 			}
-			m.usedSlots++
-			f.source.usedSlots++
-			loc.state = running
-			loc.id = m.generateID()
-			started = true
-			m.jobs <- downloadJob{l: *loc, f: f}
-			if m.usedSlots >= m.cfg.Sources.DownloadSlots {
-				break
+			if !m.quantumGranted {
+				s.deficit += s.effectiveWeight()
+				m.quantumGranted = true
+			}
+			for s.deficit > 0 && s.usedSlots < maxSlots && m.usedSlots < total {
+				f, loc := s.nextWaiting()
+				if loc == nil {
+					break
+				}
+				m.startDownload(f, loc)
+				s.deficit--
+				started = true
+			}
+			waiting := s.hasWaiting()
+			if m.usedSlots >= total && s.deficit > 0 && s.usedSlots < maxSlots && waiting {
+				// Continue with this source when the next slot is freed.
+				return
+			}
+			if !waiting {
+				s.deficit = 0
+			}
+			m.advanceSource()
+			if m.usedSlots >= total {
+				return
 			}
 		}
 		if !started {
@@ -331,21 +420,64 @@ func (m *Manager) startDownloads() {
 	}
 }
 
-func (dj *downloadJob) finish(m *Manager) {
-	m.fns <- func(m *Manager, _ context.Context) {
-		dj.f.source.usedSlots = max(0, dj.f.source.usedSlots-1)
-		m.usedSlots = max(0, m.usedSlots-1)
-		if l := dj.f.findLocationByID(dj.l.id); l != nil {
-			l.state = done
+// topPriority returns the rank of the highest priority class
+// of the sources which are able to start a download.
+func (m *Manager) topPriority(now time.Time) int {
+	top := LowPriority.rank()
+	for _, s := range m.sources {
+		if s.active && s.usedSlots < s.maxSlots(&m.cfg.Sources) &&
+			s.fetchWindows.Open(now) && s.hasWaiting() {
+			top = min(top, s.priority.rank())
 		}
 	}
+	return top
+}
+
+// advanceSource moves the round robin to the next source.
+func (m *Manager) advanceSource() {
+	m.nextSource++
+	m.quantumGranted = false
+}
+
+// startDownload passes a location of a feed to the download slots.
+func (m *Manager) startDownload(f *feed, loc *location) {
+	m.usedSlots++
+	f.source.usedSlots++
+	loc.state = running
+	loc.id = m.generateID()
+	m.jobs <- downloadJob{l: *loc, f: f}
+}
+
+func (dj *downloadJob) finish(m *Manager) {
+	dj.release(m, false)
 }
 
-func (m *Manager) download(wg *sync.WaitGroup) {
-	defer wg.Done()
-	for job := range m.jobs {
-		job.l.download(m, job.f)
-		job.finish(m)
+// release frees the download slot. If retry is set the location
+// waits for its next attempt instead of being done.
+func (dj *downloadJob) release(m *Manager, retry bool) {
+	m.fns <- func(m *Manager, ctx context.Context) {
+		f := dj.f
+		f.source.usedSlots = max(0, f.source.usedSlots-1)
+		m.usedSlots = max(0, m.usedSlots-1)
+		if l := f.findLocationByID(dj.l.id); l != nil {
+			if retry && !f.draining && !f.invalid.Load() {
+				l.attempts++
+				l.state = waiting
+				l.retryAt = time.Now().Add(retryDelay(m.cfg.Sources.RetryDelay, l.attempts))
+			} else {
+				l.state = done
+			}
+		}
+		// The last running download of a drained feed.
+		if f.draining && !f.hasRunning() {
+			f.draining = false
+			if err := m.deleteFeed(ctx, f); err != nil {
+				// The feed is active again and the deletion can be retried.
+				f.invalid.Store(false)
+				slog.Error("deleting drained feed failed", "feed", f.id, "err", err)
+				f.log(m, config.ErrorFeedLogLevel, "Deleting drained feed failed: %v", err)
+			}
+		}
 	}
 }
 
@@ -366,12 +498,7 @@ func (m *Manager) generateID() int64 {
 
 // Run runs the manager. To be used in a Go routine.
 func (m *Manager) Run(ctx context.Context) {
-	var wg sync.WaitGroup
-
-	for range m.cfg.Sources.DownloadSlots {
-		wg.Add(1)
-		go m.download(&wg)
-	}
+	drain := m.runPipeline()
 
 	// Cleaning feed logs at start.
 	m.cleanFeedLogs(ctx)
@@ -382,28 +509,68 @@ func (m *Manager) Run(ctx context.Context) {
 	defer checkingTicker.Stop()
 	feedLogCleaningTicker := time.NewTicker(feedLogCleaningDuration)
 	defer feedLogCleaningTicker.Stop()
+	metricsTicker := time.NewTicker(metricsFlushDuration)
+	defer metricsTicker.Stop()
+
+	// Without anomaly detection these channels stay nil and never fire.
+	var anomalyTicks <-chan time.Time
+	var anomalyTriggered <-chan struct{}
+	if m.anomalyTask != nil {
+		anomalyTicker := time.NewTicker(m.cfg.Anomalies.Interval)
+		defer anomalyTicker.Stop()
+		anomalyTicks = anomalyTicker.C
+		anomalyTriggered = m.anomalyTask.Triggered()
+	}
 
 out:
 	for !m.done {
 		m.pmdCache.Cleanup()
 		m.keysCache.Cleanup()
 		m.compactDone()
-		m.refreshFeeds()
-		m.startDownloads()
+		// Pause the imports while the database is unavailable.
+		available := m.db.Available()
+		if available {
+			m.refreshFeeds(false)
+			m.startDownloads()
+		}
 		select {
 		case fn := <-m.fns:
 			fn(m, ctx)
 		case <-ctx.Done():
 			break out
 		case <-checkingTicker.C:
+			if available && !m.checkingTask.Paused() {
+				m.checkSources()
+			}
+		case <-m.checkingTask.Triggered():
 			m.checkSources()
 		case <-feedLogCleaningTicker.C:
+			if available && !m.cleaningTask.Paused() {
+				m.cleanFeedLogs(ctx)
+			}
+		case <-m.cleaningTask.Triggered():
 			m.cleanFeedLogs(ctx)
+		case <-anomalyTicks:
+			if available && !m.anomalyTask.Paused() {
+				m.detectAnomalies(ctx)
+			}
+		case <-anomalyTriggered:
+			m.detectAnomalies(ctx)
+		case <-m.refreshTask.Triggered():
+			m.refreshFeeds(true)
+		case <-metricsTicker.C:
+			if available {
+				m.flushMetrics(ctx)
+			}
 		case <-refreshTicker.C:
 		}
 	}
 	close(m.jobs)
-	wg.Wait()
+	drain()
+	// Write the metrics of the last downloads.
+	if collected := m.takeMetrics(); len(collected) > 0 {
+		m.writeMetrics(context.Background(), collected)
+	}
 }
 
 func (m *Manager) enableFeedLogCleaning(context.Context) {
@@ -411,8 +578,9 @@ func (m *Manager) enableFeedLogCleaning(context.Context) {
 }
 
 func (m *Manager) cleanFeedLogs(ctx context.Context) {
+	keepLogs, keepMetrics := m.cfg.Sources.KeepFeedLogs, m.cfg.Sources.KeepFeedMetrics
 	// Check if feed log cleaning is forbidden.
-	if m.cfg.Sources.KeepFeedLogs <= 0 {
+	if keepLogs <= 0 && keepMetrics <= 0 {
 		return
 	}
 	// Check if we are already cleaning the logs.
@@ -421,26 +589,44 @@ func (m *Manager) cleanFeedLogs(ctx context.Context) {
 	}
 	// Prevent stacking calls.
 	m.blockFeedLogCleaning = true
+	done := m.cleaningTask.Start()
 	go func() {
 		// Re-enable log cleaning.
 		defer func() { m.fns <- (*Manager).enableFeedLogCleaning }()
-		const deleteSQL = `DELETE FROM feed_logs ` +
-			`WHERE time < current_timestamp - $1::interval`
+		const (
+			deleteSQL = `DELETE FROM feed_logs ` +
+				`WHERE time < current_timestamp - $1::interval`
+			deleteMetricsSQL = `DELETE FROM feed_metrics ` +
+				`WHERE time < current_timestamp - $1::interval`
+		)
 		if err := m.db.Run(
 			ctx,
 			func(ctx context.Context, conn *pgxpool.Conn) error {
-				_, err := conn.Exec(ctx, deleteSQL, m.cfg.Sources.KeepFeedLogs)
-				return err
+				if keepLogs > 0 {
+					if _, err := conn.Exec(ctx, deleteSQL, keepLogs); err != nil {
+						return err
+					}
+				}
+				if keepMetrics > 0 {
+					if _, err := conn.Exec(ctx, deleteMetricsSQL, keepMetrics); err != nil {
+						return err
+					}
+				}
+				return nil
 			}, 0,
 		); err != nil {
 			slog.Error("Cleaning feed logs failed", "err", err)
+			done(err)
+			return
 		}
+		done(nil)
 	}()
 }
 
 type prefetchedPMD struct {
 	id       int64
 	url      string
+	creds    *credentials
 	checksum []byte
 }
 
@@ -451,6 +637,7 @@ func (m *Manager) checkSources() {
 	}
 	// prevent stacking checks.
 	m.blockSourceChecking = true
+	done := m.checkingTask.Start()
 
 	// The loading of the PMD is time consuming
 	// so the fetching is off-loaded from the main loop.
@@ -460,13 +647,13 @@ func (m *Manager) checkSources() {
 		if s.id == 0 {
 			continue
 		}
-		urls = append(urls, prefetchedPMD{id: s.id, url: s.url})
+		urls = append(urls, prefetchedPMD{id: s.id, url: s.url, creds: s.credentials()})
 	}
 	go func() {
 		prefetched := make([]prefetchedPMD, 0, len(urls))
 		for i := range urls {
 			s := &urls[i]
-			cpmd := m.PMD(s.url)
+			cpmd := m.pmdCache.pmd(s.url, m.cfg, s.creds)
 			if !cpmd.Valid() {
 				slog.Warn("invalid PMD", "url", s.url, "id", s.id)
 				continue
@@ -484,14 +671,14 @@ func (m *Manager) checkSources() {
 		// Run the real checking in the manager.
 		m.fns <- func(m *Manager, ctx context.Context) {
 			// Only check the sources where prefetching worked.
-			m.realCheckSources(ctx, prefetched)
+			done(m.realCheckSources(ctx, prefetched))
 			// re-enable checking
 			m.blockSourceChecking = false
 		}
 	}()
 }
 
-func (m *Manager) realCheckSources(ctx context.Context, prefetched []prefetchedPMD) {
+func (m *Manager) realCheckSources(ctx context.Context, prefetched []prefetchedPMD) error {
 	now := time.Now().UTC()
 	updates := pgx.Batch{}
 
@@ -510,6 +697,8 @@ func (m *Manager) realCheckSources(ctx context.Context, prefetched []prefetchedP
 		}
 		if !bytes.Equal(pre.checksum, s.checksum) {
 			updates.Queue(sql, pre.checksum, now, pre.id)
+			updates.Queue(insertSourceActionSQL, pre.id,
+				string(AttentionAction), "Provider metadata changed.")
 			apply = append(apply, func() {
 				s.checksum = pre.checksum
 				s.checksumUpdated = now
@@ -533,22 +722,23 @@ func (m *Manager) realCheckSources(ctx context.Context, prefetched []prefetchedP
 			}, 0,
 		); err != nil {
 			slog.Error("Storing source checksums failed", "err", err)
-			return
+			return err
 		}
 		// Apply after db operations have succeeded.
 		for _, fn := range apply {
 			fn()
 		}
 	}
+	return nil
 }
 
 // Source returns infos about a source.
-func (m *Manager) Source(id int64, stats bool) *SourceInfo {
-	siCh := make(chan *SourceInfo)
-	m.fns <- func(m *Manager, _ context.Context) {
+// It returns nil if there is no such source or the context is canceled.
+func (m *Manager) Source(ctx context.Context, id int64, stats bool) *SourceInfo {
+	var si *SourceInfo
+	m.inManagerContext(ctx, func(m *Manager, _ context.Context) {
 		s := m.findSourceByID(id)
 		if s == nil {
-			siCh <- nil
 			return
 		}
 		var st *Stats
@@ -556,28 +746,43 @@ func (m *Manager) Source(id int64, stats bool) *SourceInfo {
 			st = new(Stats)
 			s.addStats(st)
 		}
-		siCh <- &SourceInfo{
+		si = &SourceInfo{
 			ID:                      s.id,
 			Name:                    s.name,
 			URL:                     s.url,
 			Active:                  s.active,
-			Attention:               s.checksumAck.Before(s.checksumUpdated),
+			Shadow:                  s.shadow,
+			Priority:                s.priority,
+			Attention:               s.needsAttention(),
 			Status:                  s.status,
+			AggregatorIssues:        s.aggregatorIssues,
 			Rate:                    s.rate,
 			Slots:                   s.slots,
+			Weight:                  s.weight,
 			Headers:                 s.headers,
 			StrictMode:              s.strictMode,
 			Secure:                  s.secure,
 			SignatureCheck:          s.signatureCheck,
 			Age:                     s.age,
 			IgnorePatterns:          s.ignorePatterns,
+			FetchWindows:            s.fetchWindows.Strings(),
 			HasClientCertPublic:     s.clientCertPublic != nil,
 			HasClientCertPrivate:    s.clientCertPrivate != nil,
 			HasClientCertPassphrase: s.clientCertPassphrase != nil,
+			OAuth2TokenURL:          s.oauth2TokenURL,
+			OAuth2ClientID:          s.oauth2ClientID,
+			HasOAuth2ClientSecret:   s.oauth2ClientSecret != nil,
+			OAuth2Scopes:            s.oauth2Scopes,
+			BasicAuthUser:           s.basicAuthUser,
+			HasBasicAuthPassword:    s.basicAuthPassword != nil,
+			HasTLSCABundle:          s.tlsCABundle != nil,
+			TLSPinnedCerts:          s.tlsPinnedCerts,
+			ReceiptURL:              s.receiptURL,
+			LastActivity:            s.lastActivity(),
 			Stats:                   st,
 		}
-	}
-	return <-siCh
+	})
+	return si
 }
 
 // Subscriptions return a list of subscription infos for a given list of source URLs.
@@ -657,11 +862,18 @@ func (m *Manager) Subscriptions(urls []string) []SourceSubscriptions {
 	return <-result
 }
 
-// Sources iterates over all sources and passes infos to a given function.
-func (m *Manager) Sources(fn func(*SourceInfo), stats bool) {
-	m.inManager(func(m *Manager, _ context.Context) {
+// Sources iterates over all sources in the given order
+// and passes infos to a given function.
+// It returns the error of the context if it is canceled before.
+func (m *Manager) Sources(
+	ctx context.Context,
+	fn func(*SourceInfo),
+	stats bool,
+	order Order,
+) error {
+	return m.inManagerContext(ctx, func(m *Manager, _ context.Context) {
 		si := new(SourceInfo)
-		for _, s := range m.sources {
+		for _, s := range orderedSources(m.sources, order) {
 			var st *Stats
 			if stats {
 				st = new(Stats)
@@ -672,18 +884,33 @@ func (m *Manager) Sources(fn func(*SourceInfo), stats bool) {
 				Name:                    s.name,
 				URL:                     s.url,
 				Active:                  s.active,
-				Attention:               s.checksumAck.Before(s.checksumUpdated),
+				Shadow:                  s.shadow,
+				Priority:                s.priority,
+				Attention:               s.needsAttention(),
+				AggregatorIssues:        s.aggregatorIssues,
 				Rate:                    s.rate,
 				Slots:                   s.slots,
+				Weight:                  s.weight,
 				Headers:                 s.headers,
 				StrictMode:              s.strictMode,
 				Secure:                  s.secure,
 				SignatureCheck:          s.signatureCheck,
 				Age:                     s.age,
 				IgnorePatterns:          s.ignorePatterns,
+				FetchWindows:            s.fetchWindows.Strings(),
 				HasClientCertPublic:     s.clientCertPublic != nil,
 				HasClientCertPrivate:    s.clientCertPrivate != nil,
 				HasClientCertPassphrase: s.clientCertPassphrase != nil,
+				OAuth2TokenURL:          s.oauth2TokenURL,
+				OAuth2ClientID:          s.oauth2ClientID,
+				HasOAuth2ClientSecret:   s.oauth2ClientSecret != nil,
+				OAuth2Scopes:            s.oauth2Scopes,
+				BasicAuthUser:           s.basicAuthUser,
+				HasBasicAuthPassword:    s.basicAuthPassword != nil,
+				HasTLSCABundle:          s.tlsCABundle != nil,
+				TLSPinnedCerts:          s.tlsPinnedCerts,
+				ReceiptURL:              s.receiptURL,
+				LastActivity:            s.lastActivity(),
 				Stats:                   st,
 			}
 			fn(si)
@@ -691,17 +918,29 @@ func (m *Manager) Sources(fn func(*SourceInfo), stats bool) {
 	})
 }
 
-// Feeds passes the fields of the feeds of a given source to a given function.
-func (m *Manager) Feeds(sourceID int64, fn func(*FeedInfo), stats bool) error {
-	errCh := make(chan error)
-	m.fns <- func(m *Manager, _ context.Context) {
+// Feeds passes the fields of the feeds of a given source
+// in the given order to a given function.
+func (m *Manager) Feeds(
+	ctx context.Context,
+	sourceID int64,
+	fn func(*FeedInfo),
+	stats bool,
+	order Order,
+) error {
+	var err error
+	if cerr := m.inManagerContext(ctx, func(m *Manager, _ context.Context) {
 		s := m.findSourceByID(sourceID)
 		if s == nil {
-			errCh <- NoSuchEntryError("no such source")
+			err = NoSuchEntryError("no such source")
+			return
+		}
+		feeds, oerr := orderedFeeds(s.feeds, order)
+		if oerr != nil {
+			err = oerr
 			return
 		}
 		fi := new(FeedInfo)
-		for _, f := range s.feeds {
+		for _, f := range feeds {
 			if f.invalid.Load() {
 				continue
 			}
@@ -711,27 +950,29 @@ func (m *Manager) Feeds(sourceID int64, fn func(*FeedInfo), stats bool) error {
 				f.addStats(st)
 			}
 			*fi = FeedInfo{
-				ID:    f.id,
-				Label: f.label,
-				URL:   f.url,
-				Rolie: f.rolie,
-				Lvl:   config.FeedLogLevel(f.logLevel.Load()),
-				Stats: st,
+				ID:           f.id,
+				Label:        f.label,
+				URL:          f.url,
+				Rolie:        f.rolie,
+				Lvl:          config.FeedLogLevel(f.logLevel.Load()),
+				LastActivity: f.metrics.lastActivity(),
+				Stats:        st,
 			}
 			fn(fi)
 		}
-		errCh <- nil
+	}); cerr != nil {
+		return cerr
 	}
-	return <-errCh
+	return err
 }
 
 // Feed returns the infos of a feed.
-func (m *Manager) Feed(feedID int64, stats bool) *FeedInfo {
-	fiCh := make(chan *FeedInfo)
-	m.fns <- func(m *Manager, _ context.Context) {
+// It returns nil if there is no such feed or the context is canceled.
+func (m *Manager) Feed(ctx context.Context, feedID int64, stats bool) *FeedInfo {
+	var fi *FeedInfo
+	m.inManagerContext(ctx, func(m *Manager, _ context.Context) {
 		f := m.findFeedByID(feedID)
 		if f == nil || f.invalid.Load() {
-			fiCh <- nil
 			return
 		}
 		var st *Stats
@@ -739,16 +980,17 @@ func (m *Manager) Feed(feedID int64, stats bool) *FeedInfo {
 			st = new(Stats)
 			f.addStats(st)
 		}
-		fiCh <- &FeedInfo{
-			ID:    f.id,
-			Label: f.label,
-			URL:   f.url,
-			Rolie: f.rolie,
-			Lvl:   config.FeedLogLevel(f.logLevel.Load()),
-			Stats: st,
+		fi = &FeedInfo{
+			ID:           f.id,
+			Label:        f.label,
+			URL:          f.url,
+			Rolie:        f.rolie,
+			Lvl:          config.FeedLogLevel(f.logLevel.Load()),
+			LastActivity: f.metrics.lastActivity(),
+			Stats:        st,
 		}
-	}
-	return <-fiCh
+	})
+	return fi
 }
 
 // FeedLogInfo is an entry in the log of a feed.
@@ -760,14 +1002,19 @@ type FeedLogInfo struct {
 }
 
 // StreamFeedLog returns a sequence of feed log entries.
+// If feedID is given only the entries of this feed are returned.
+// If sourceID is given only the entries of the feeds of this source are returned.
+// If count is given it is called with the exact number of matching entries.
+// If estimate is given it is called with the number of matching entries
+// estimated by the query planner which is cheaper on large logs.
 func (m *Manager) StreamFeedLog(
 	ctx context.Context,
-	feedID *int64,
+	feedID, sourceID *int64,
 	from, to *time.Time,
 	search string,
 	limit, offset int64,
 	logLevels []config.FeedLogLevel,
-	count func(int64),
+	count, estimate func(int64),
 ) (iter.Seq[FeedLogInfo], error) {
 	const (
 		countSQL  = `SELECT count(*) FROM feed_logs WHERE `
@@ -784,6 +1031,12 @@ func (m *Manager) StreamFeedLog(
 		cond.WriteString(`TRUE`)
 	}
 
+	if sourceID != nil {
+		fmt.Fprintf(&cond,
+			" AND feeds_id IN (SELECT id FROM feeds WHERE sources_id = $%d)", len(args)+1)
+		args = append(args, *sourceID)
+	}
+
 	if from != nil && to != nil && from.After(*to) {
 		from, to = to, from
 	}
@@ -821,15 +1074,18 @@ func (m *Manager) StreamFeedLog(
 		args = append(args, keepFeedLogs)
 	}
 
-	var cntSQL string
+	var cntSQL, estSQL string
 	var cntArgs []any
 
+	// Counting ignores limit, offset and order.
 	if count != nil {
-		// Counting ignores limit, offset and order.
 		cntSQL = countSQL + cond.String()
-		cntArgs = args
 		slog.Debug("feed log count", "stmt", cntSQL)
 	}
+	if estimate != nil {
+		estSQL = selectSQL + cond.String()
+	}
+	cntArgs = args
 
 	cond.WriteString(` ORDER by time DESC`)
 
@@ -859,6 +1115,20 @@ func (m *Manager) StreamFeedLog(
 		count(counter)
 	}
 
+	if estimate != nil {
+		var estimated int64
+		if err := m.db.Run(
+			ctx,
+			func(ctx context.Context, con *pgxpool.Conn) error {
+				var err error
+				estimated, err = database.EstimateCount(ctx, con, estSQL, cntArgs...)
+				return err
+			}, 0); err != nil {
+			return nil, fmt.Errorf("estimating feed logs failed: %w", err)
+		}
+		estimate(estimated)
+	}
+
 	return func(yield func(FeedLogInfo) bool) {
 		if err := m.db.Run(
 			ctx,
@@ -935,20 +1205,48 @@ func (m *Manager) removeSource(ctx context.Context, sourceID int64) error {
 	return nil
 }
 
-func (m *Manager) removeFeed(ctx context.Context, feedID int64) error {
+// removeFeed removes a feed in two phases. First the feed is invalidated
+// so that no new downloads are started and its waiting locations are dropped.
+// If there are no running downloads the feed is deleted right away.
+// Otherwise it is drained and deleted when the last running download
+// has finished so that the imports in flight can still reference it.
+// If the deletion fails the feed is valid again so it can be retried.
+// Returns true if the feed was deleted.
+func (m *Manager) removeFeed(ctx context.Context, feedID int64) (bool, error) {
 	f := m.findFeedByID(feedID)
 	if f == nil {
-		return NoSuchEntryError("no such feed")
+		return false, NoSuchEntryError("no such feed")
 	}
 	if f.source.id == 0 {
-		return InvalidArgumentError("cannot delete this feed")
+		return false, InvalidArgumentError("cannot delete this feed")
+	}
+	// The feed is already drained for its deletion.
+	if f.draining {
+		return false, nil
 	}
 	f.invalid.Store(true)
+	f.queue = slices.DeleteFunc(f.queue, func(l location) bool {
+		return l.state == waiting
+	})
+	if f.hasRunning() {
+		f.draining = true
+		slog.Info("draining feed before deletion", "feed", f.id, "source", f.source.name)
+		return false, nil
+	}
+	if err := m.deleteFeed(ctx, f); err != nil {
+		f.invalid.Store(false)
+		return false, err
+	}
+	return true, nil
+}
+
+// deleteFeed deletes an invalidated feed.
+func (m *Manager) deleteFeed(ctx context.Context, f *feed) error {
 	const sql = `DELETE FROM feeds WHERE id = $1`
 	if err := m.db.Run(
 		ctx,
 		func(ctx context.Context, con *pgxpool.Conn) error {
-			_, err := con.Exec(ctx, sql, feedID)
+			_, err := con.Exec(ctx, sql, f.id)
 			return err
 		}, 0,
 	); err != nil {
@@ -970,18 +1268,52 @@ func (m *Manager) inManager(fn func(*Manager, context.Context)) {
 	<-done
 }
 
-func (m *Manager) asManager(fn func(*Manager, context.Context, int64) error, id int64) error {
-	err := make(chan error)
-	m.fns <- func(m *Manager, ctx context.Context) { err <- fn(m, ctx, id) }
-	return <-err
+// inManagerContext calls the given function inside the main loop
+// of the manager and waits for it to return. The function is passed
+// the given context instead of the one of the manager so that its
+// database work is canceled if e.g. the requesting client is gone.
+// If the context is canceled before the manager picks the function up
+// the function is not called and the error of the context is returned.
+func (m *Manager) inManagerContext(ctx context.Context, fn func(*Manager, context.Context)) error {
+	done := make(chan struct{})
+	var err error
+	select {
+	case m.fns <- func(m *Manager, _ context.Context) {
+		defer close(done)
+		if err = ctx.Err(); err == nil {
+			fn(m, ctx)
+		}
+	}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	<-done
+	return err
+}
+
+func (m *Manager) asManager(
+	ctx context.Context,
+	fn func(*Manager, context.Context, int64) error,
+	id int64,
+) error {
+	var err error
+	if cerr := m.inManagerContext(ctx, func(m *Manager, ctx context.Context) {
+		err = fn(m, ctx, id)
+	}); cerr != nil {
+		return cerr
+	}
+	return err
 }
 
 // AddSource registers a new source.
 func (m *Manager) AddSource(
+	ctx context.Context,
 	name string,
 	url string,
 	rate *float64,
 	slots *int,
+	weight *int,
+	priority Priority,
 	headers []string,
 	strictMode *bool,
 	secure *bool,
@@ -991,22 +1323,24 @@ func (m *Manager) AddSource(
 	clientCertPublic []byte,
 	clientCertPrivate []byte,
 	clientCertPassphrase []byte,
+	oauth2TokenURL *string,
+	oauth2ClientID *string,
+	oauth2ClientSecret []byte,
+	oauth2Scopes []string,
+	basicAuthUser *string,
+	basicAuthPassword []byte,
+	tlsCABundle []byte,
+	tlsPinnedCerts []string,
+	receiptURL *string,
 ) (int64, error) {
-	cpmd := m.PMD(url)
-	if !cpmd.Valid() {
-		return 0, InvalidArgumentError("PMD is invalid")
-	}
-	model, err := cpmd.Model()
-	if err != nil {
-		return 0, InvalidArgumentError("PMD model is invalid")
-	}
 	now := time.Now().UTC()
-	errCh := make(chan error)
 	s := &source{
 		name:                 name,
 		url:                  url,
 		rate:                 rate,
 		slots:                slots,
+		weight:               weight,
+		priority:             priority,
 		headers:              headers,
 		strictMode:           strictMode,
 		secure:               secure,
@@ -1016,10 +1350,30 @@ func (m *Manager) AddSource(
 		clientCertPublic:     clientCertPublic,
 		clientCertPrivate:    clientCertPrivate,
 		clientCertPassphrase: clientCertPassphrase,
-		checksum:             checksumPMD(model),
+		oauth2TokenURL:       oauth2TokenURL,
+		oauth2ClientID:       oauth2ClientID,
+		oauth2ClientSecret:   oauth2ClientSecret,
+		oauth2Scopes:         oauth2Scopes,
+		basicAuthUser:        basicAuthUser,
+		basicAuthPassword:    basicAuthPassword,
+		tlsCABundle:          tlsCABundle,
+		tlsPinnedCerts:       tlsPinnedCerts,
+		receiptURL:           receiptURL,
 		checksumAck:          now.Add(-time.Second),
 		checksumUpdated:      now,
 	}
+	if err := s.updateTrust(); err != nil {
+		return 0, err
+	}
+	cpmd := m.pmdCache.pmd(url, m.cfg, s.credentials())
+	if !cpmd.Valid() {
+		return 0, InvalidArgumentError("PMD is invalid")
+	}
+	model, err := cpmd.Model()
+	if err != nil {
+		return 0, InvalidArgumentError("PMD model is invalid")
+	}
+	s.checksum = checksumPMD(model)
 	if clientCertPrivate != nil {
 		var err error
 		if clientCertPrivate, err = m.encrypt(clientCertPrivate); err != nil {
@@ -1032,73 +1386,106 @@ func (m *Manager) AddSource(
 			return 0, err
 		}
 	}
-	m.fns <- func(m *Manager, ctx context.Context) {
+	if oauth2ClientSecret != nil {
+		var err error
+		if oauth2ClientSecret, err = m.encrypt(oauth2ClientSecret); err != nil {
+			return 0, err
+		}
+	}
+	if basicAuthPassword != nil {
+		var err error
+		if basicAuthPassword, err = m.encrypt(basicAuthPassword); err != nil {
+			return 0, err
+		}
+	}
+	if tlsCABundle, err = m.encrypt(tlsCABundle); err != nil {
+		return 0, err
+	}
+	storedPins, err := m.encrypt(joinPins(tlsPinnedCerts))
+	if err != nil {
+		return 0, err
+	}
+	var addErr error
+	if err := m.inManagerContext(ctx, func(m *Manager, ctx context.Context) {
 		if m.findSourceByName(name) != nil {
-			errCh <- InvalidArgumentError("source already exists")
+			addErr = InvalidArgumentError("source already exists")
 			return
 		}
 		const sql = `INSERT INTO sources (` +
-			`name, url, rate, slots, headers, ` +
+			`name, url, rate, slots, weight, priority, headers, ` +
 			`strict_mode, secure, signature_check, age, ignore_patterns, ` +
 			`client_cert_public, client_cert_private, client_cert_passphrase, ` +
+			`oauth2_token_url, oauth2_client_id, oauth2_client_secret, oauth2_scopes, ` +
+			`basic_auth_user, basic_auth_password, ` +
+			`tls_ca_bundle, tls_pinned_certs, receipt_url, ` +
 			`checksum, checksum_ack, checksum_updated) ` +
 			`VALUES (` +
-			`$1, $2, $3, $4, $5, ` +
-			`$6, $7, $8, $9, $10, ` +
-			`$11, $12, $13, ` +
-			`$14, $15, $16) ` +
+			`$1, $2, $3, $4, $5, $6, $7, ` +
+			`$8, $9, $10, $11, $12, ` +
+			`$13, $14, $15, ` +
+			`$16, $17, $18, $19, ` +
+			`$20, $21, ` +
+			`$22, $23, $24, ` +
+			`$25, $26, $27) ` +
 			`RETURNING id`
 		if err := m.db.Run(
 			ctx,
 			func(rctx context.Context, con *pgxpool.Conn) error {
 				return con.QueryRow(rctx, sql,
-					name, url, rate, slots, headers,
+					name, url, rate, slots, weight, priority, headers,
 					strictMode, secure, signatureCheck, age, ignorePatterns,
 					clientCertPublic, clientCertPrivate, clientCertPassphrase,
+					oauth2TokenURL, oauth2ClientID, oauth2ClientSecret, oauth2Scopes,
+					basicAuthUser, basicAuthPassword,
+					tlsCABundle, storedPins, receiptURL,
 					s.checksum, s.checksumAck, s.checksumUpdated,
 				).Scan(&s.id)
 			}, 0,
 		); err != nil {
-			errCh <- fmt.Errorf("adding source to database failed: %w", err)
+			addErr = fmt.Errorf("adding source to database failed: %w", err)
 			return
 		}
 		m.sources = append(m.sources, s)
-		errCh <- nil
+	}); err != nil {
+		return 0, err
 	}
-	return s.id, <-errCh
+	return s.id, addErr
 }
 
 // AddFeed adds a new feed to a source.
 func (m *Manager) AddFeed(
+	ctx context.Context,
 	sourceID int64,
 	label string,
 	url *url.URL,
 	logLevel config.FeedLogLevel,
 ) (int64, error) {
-	var feedID int64
-	errCh := make(chan error)
-	m.fns <- func(m *Manager, ctx context.Context) {
+	var (
+		feedID int64
+		addErr error
+	)
+	if err := m.inManagerContext(ctx, func(m *Manager, ctx context.Context) {
 		s := m.findSourceByID(sourceID)
 		if s == nil {
-			errCh <- NoSuchEntryError("no such source")
+			addErr = NoSuchEntryError("no such source")
 			return
 		}
 		if s.id == 0 {
-			errCh <- InvalidArgumentError("cannot update this source")
+			addErr = InvalidArgumentError("cannot update this source")
 			return
 		}
 		if slices.ContainsFunc(s.feeds, func(f *feed) bool { return f.label == label }) {
-			errCh <- InvalidArgumentError("label already exists")
+			addErr = InvalidArgumentError("label already exists")
 			return
 		}
-		pmd, err := m.PMD(s.url).Model()
+		pmd, err := m.pmdCache.pmd(s.url, m.cfg, s.credentials()).Model()
 		if err != nil {
-			errCh <- err
+			addErr = err
 			return
 		}
 		rolie := isROLIEFeed(pmd, url.String())
 		if !rolie && !isDirectoryFeed(pmd, url.String()) {
-			errCh <- InvalidArgumentError("feed is neither ROLIE nor directory based")
+			addErr = InvalidArgumentError("feed is neither ROLIE nor directory based")
 			return
 		}
 		const sql = `INSERT INTO feeds (label, sources_id, url, rolie, log_lvl) ` +
@@ -1116,7 +1503,7 @@ func (m *Manager) AddFeed(
 				).Scan(&feedID)
 			}, 0,
 		); err != nil {
-			errCh <- fmt.Errorf("inserting feed failed: %w", err)
+			addErr = fmt.Errorf("inserting feed failed: %w", err)
 			return
 		}
 		f := &feed{
@@ -1131,27 +1518,64 @@ func (m *Manager) AddFeed(
 		if s.active {
 			m.backgroundPing()
 		}
-		errCh <- nil
-	}
-	if err := <-errCh; err != nil {
+	}); err != nil {
 		return 0, err
 	}
+	if addErr != nil {
+		return 0, addErr
+	}
 	return feedID, nil
 }
 
 // RemoveSource removes a sources from manager.
-func (m *Manager) RemoveSource(sourceID int64) error {
-	return m.asManager((*Manager).removeSource, sourceID)
+func (m *Manager) RemoveSource(ctx context.Context, sourceID int64) error {
+	return m.asManager(ctx, (*Manager).removeSource, sourceID)
 }
 
-// RemoveFeed removes a feed from a source.
-func (m *Manager) RemoveFeed(feedID int64) error {
-	return m.asManager((*Manager).removeFeed, feedID)
+// RemoveFeed removes a feed from a source. Feeds with running downloads
+// are drained first. Returns false if the feed is deleted after draining.
+func (m *Manager) RemoveFeed(ctx context.Context, feedID int64) (bool, error) {
+	var (
+		deleted bool
+		err     error
+	)
+	if cerr := m.inManagerContext(ctx, func(m *Manager, ctx context.Context) {
+		deleted, err = m.removeFeed(ctx, feedID)
+	}); cerr != nil {
+		return false, cerr
+	}
+	return deleted, err
 }
 
 // PMD returns the provider metadata from the given url.
 func (m *Manager) PMD(url string) *CachedProviderMetadata {
-	return m.pmdCache.pmd(url, m.cfg)
+	return m.pmdCache.pmd(url, m.cfg, nil)
+}
+
+// ProxyPMD returns the provider metadata from the given url
+// fetched only from the domains allowed for the PMD proxy.
+func (m *Manager) ProxyPMD(url string) *CachedProviderMetadata {
+	return m.pmdCache.proxyPMD(url, m.cfg)
+}
+
+// SourcePMD returns the provider metadata of the given source.
+// The credentials of the source are used to fetch it.
+// Returns nil if there is no such source.
+func (m *Manager) SourcePMD(sourceID int64) *CachedProviderMetadata {
+	var (
+		url   string
+		creds *credentials
+		found bool
+	)
+	m.inManager(func(m *Manager, _ context.Context) {
+		if s := m.findSourceByID(sourceID); s != nil {
+			url, creds, found = s.url, s.credentials(), true
+		}
+	})
+	if !found {
+		return nil
+	}
+	return m.pmdCache.pmd(url, m.cfg, creds)
 }
 
 // updater collects updates so that only the first update on
@@ -1222,7 +1646,9 @@ func placeholders(n int) string {
 type SourceUpdater struct {
 	updater[*source]
 	clientCertUpdated bool
+	trustUpdated      bool
 	doBackgroundPing  bool
+	promoted          bool
 }
 
 // applyChanges overwrites base to only issue one background ping.
@@ -1279,6 +1705,22 @@ func (su *SourceUpdater) UpdateSlots(slots *int) error {
 	return nil
 }
 
+// UpdateWeight requests a weight update.
+func (su *SourceUpdater) UpdateWeight(weight *int) error {
+	if weight == nil && su.updatable.weight == nil {
+		return nil
+	}
+	if weight != nil && su.updatable.weight != nil && *weight == *su.updatable.weight {
+		return nil
+	}
+	if weight != nil && (*weight < 1 || *weight > MaxWeight) {
+		msg := fmt.Sprintf("weight value out of range: %d not in [1, %d]", *weight, MaxWeight)
+		return InvalidArgumentError(msg)
+	}
+	su.addChange(func(s *source) { s.weight = weight }, "weight", weight)
+	return nil
+}
+
 // UpdateActive requests an active update.
 func (su *SourceUpdater) UpdateActive(active bool) error {
 	if active == su.updatable.active {
@@ -1294,6 +1736,40 @@ func (su *SourceUpdater) UpdateActive(active bool) error {
 	return nil
 }
 
+// UpdateShadow requests a shadow mode update.
+// Leaving the shadow mode promotes the source and
+// the documents of its feeds are downloaded again to be imported.
+func (su *SourceUpdater) UpdateShadow(shadow bool) error {
+	if shadow == su.updatable.shadow {
+		return nil
+	}
+	su.promoted = !shadow
+	su.addChange(func(s *source) { s.shadow = shadow }, "shadow", shadow)
+	return nil
+}
+
+// UpdatePriority requests a priority update.
+// Raising the priority refreshes the feeds of the source
+// with the next check.
+func (su *SourceUpdater) UpdatePriority(priority Priority) error {
+	if priority == su.updatable.priority {
+		return nil
+	}
+	if _, err := ParsePriority(string(priority)); err != nil {
+		return err
+	}
+	raised := priority.rank() < su.updatable.priority.rank()
+	su.addChange(func(s *source) {
+		s.priority = priority
+		if raised {
+			for _, f := range s.feeds {
+				f.nextCheck = time.Time{}
+			}
+		}
+	}, "priority", priority)
+	return nil
+}
+
 // UpdateAttention requests an attention update.
 func (su *SourceUpdater) UpdateAttention(att bool) error {
 	if old := su.updatable.checksumAck.Before(su.updatable.checksumUpdated); old == att {
@@ -1391,6 +1867,17 @@ func (su *SourceUpdater) UpdateIgnorePatterns(ignorePatterns []*regexp.Regexp) e
 	return nil
 }
 
+// UpdateFetchWindows requests an update on the fetch windows.
+func (su *SourceUpdater) UpdateFetchWindows(fetchWindows FetchWindows) error {
+	if slices.Equal(su.updatable.fetchWindows.Strings(), fetchWindows.Strings()) {
+		return nil
+	}
+	fetchWindows = clone(fetchWindows)
+	su.addChange(func(s *source) { s.fetchWindows = fetchWindows },
+		"fetch_windows", fetchWindows.Strings())
+	return nil
+}
+
 // UpdateClientCertPublic requests an update ob client cert public part.
 func (su *SourceUpdater) UpdateClientCertPublic(data []byte) error {
 	if data == nil && su.updatable.clientCertPublic == nil {
@@ -1449,39 +1936,221 @@ func (su *SourceUpdater) UpdateClientCertPassphrase(data []byte) error {
 	return nil
 }
 
+// UpdateOAuth2TokenURL requests an update of the OAuth2 token URL.
+// A nil value disables the OAuth2 client credentials flow.
+func (su *SourceUpdater) UpdateOAuth2TokenURL(tokenURL *string) error {
+	if tokenURL == nil && su.updatable.oauth2TokenURL == nil {
+		return nil
+	}
+	if tokenURL != nil && su.updatable.oauth2TokenURL != nil && *tokenURL == *su.updatable.oauth2TokenURL {
+		return nil
+	}
+	if tokenURL != nil {
+		if err := ValidateTokenURL(*tokenURL); err != nil {
+			return err
+		}
+	}
+	su.addChange(func(s *source) { s.oauth2TokenURL = tokenURL }, "oauth2_token_url", tokenURL)
+	return nil
+}
+
+// UpdateOAuth2ClientID requests an update of the OAuth2 client ID.
+func (su *SourceUpdater) UpdateOAuth2ClientID(clientID *string) error {
+	if clientID == nil && su.updatable.oauth2ClientID == nil {
+		return nil
+	}
+	if clientID != nil && su.updatable.oauth2ClientID != nil && *clientID == *su.updatable.oauth2ClientID {
+		return nil
+	}
+	su.addChange(func(s *source) { s.oauth2ClientID = clientID }, "oauth2_client_id", clientID)
+	return nil
+}
+
+// UpdateOAuth2ClientSecret requests an update of the OAuth2 client secret.
+func (su *SourceUpdater) UpdateOAuth2ClientSecret(data []byte) error {
+	orig := su.updatable.oauth2ClientSecret
+	if data == nil && orig == nil {
+		return nil
+	}
+	if data != nil && orig != nil && slices.Equal(data, orig) {
+		return nil
+	}
+	encrypted, err := su.manager.encrypt(data)
+	if err != nil {
+		return err
+	}
+	data = clone(data)
+	su.addChange(func(s *source) { s.oauth2ClientSecret = data }, "oauth2_client_secret", encrypted)
+	return nil
+}
+
+// UpdateOAuth2Scopes requests an update of the OAuth2 scopes.
+func (su *SourceUpdater) UpdateOAuth2Scopes(scopes []string) error {
+	if slices.Equal(scopes, su.updatable.oauth2Scopes) {
+		return nil
+	}
+	scopes = clone(scopes)
+	su.addChange(func(s *source) { s.oauth2Scopes = scopes }, "oauth2_scopes", scopes)
+	return nil
+}
+
+// UpdateBasicAuthUser requests an update of the basic auth user.
+// A nil value disables the basic authentication.
+func (su *SourceUpdater) UpdateBasicAuthUser(user *string) error {
+	if user == nil && su.updatable.basicAuthUser == nil {
+		return nil
+	}
+	if user != nil && su.updatable.basicAuthUser != nil && *user == *su.updatable.basicAuthUser {
+		return nil
+	}
+	su.addChange(func(s *source) { s.basicAuthUser = user }, "basic_auth_user", user)
+	return nil
+}
+
+// UpdateBasicAuthPassword requests an update of the basic auth password.
+func (su *SourceUpdater) UpdateBasicAuthPassword(data []byte) error {
+	orig := su.updatable.basicAuthPassword
+	if data == nil && orig == nil {
+		return nil
+	}
+	if data != nil && orig != nil && slices.Equal(data, orig) {
+		return nil
+	}
+	encrypted, err := su.manager.encrypt(data)
+	if err != nil {
+		return err
+	}
+	data = clone(data)
+	su.addChange(func(s *source) { s.basicAuthPassword = data }, "basic_auth_password", encrypted)
+	return nil
+}
+
+// UpdateTLSCABundle requests an update of the CA bundle used to verify the server.
+// A nil value restores the system roots.
+func (su *SourceUpdater) UpdateTLSCABundle(data []byte) error {
+	orig := su.updatable.tlsCABundle
+	if data == nil && orig == nil {
+		return nil
+	}
+	if data != nil && orig != nil && slices.Equal(data, orig) {
+		return nil
+	}
+	if data != nil {
+		if _, err := ParseCABundle(data); err != nil {
+			return err
+		}
+	}
+	encrypted, err := su.manager.encrypt(data)
+	if err != nil {
+		return err
+	}
+	data = clone(data)
+	su.addChange(func(s *source) {
+		su.trustUpdated = true
+		s.tlsCABundle = data
+	}, "tls_ca_bundle", encrypted)
+	return nil
+}
+
+// UpdateTLSPinnedCerts requests an update of the pinned server certificates.
+// The fingerprints have to be normalized with [NormalizeFingerprints].
+func (su *SourceUpdater) UpdateTLSPinnedCerts(pins []string) error {
+	if slices.Equal(pins, su.updatable.tlsPinnedCerts) {
+		return nil
+	}
+	encrypted, err := su.manager.encrypt(joinPins(pins))
+	if err != nil {
+		return err
+	}
+	pins = clone(pins)
+	su.addChange(func(s *source) {
+		su.trustUpdated = true
+		s.tlsPinnedCerts = pins
+		s.pinMismatch.Store(nil)
+	}, "tls_pinned_certs", encrypted)
+	return nil
+}
+
+// UpdateReceiptURL requests an update of the receipt URL.
+func (su *SourceUpdater) UpdateReceiptURL(receiptURL *string) error {
+	if receiptURL == nil && su.updatable.receiptURL == nil {
+		return nil
+	}
+	if receiptURL != nil && su.updatable.receiptURL != nil && *receiptURL == *su.updatable.receiptURL {
+		return nil
+	}
+	if receiptURL != nil {
+		if err := ValidateReceiptURL(*receiptURL); err != nil {
+			return err
+		}
+	}
+	su.addChange(func(s *source) { s.receiptURL = receiptURL }, "receipt_url", receiptURL)
+	return nil
+}
+
+// forgetChanges removes the recorded changes of the documents
+// of the feeds of a source so that they are downloaded again.
+func (m *Manager) forgetChanges(ctx context.Context, s *source) error {
+	const deleteSQL = `DELETE FROM changes ` +
+		`WHERE feeds_id IN (SELECT id FROM feeds WHERE sources_id = $1)`
+	return m.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			_, err := conn.Exec(rctx, deleteSQL, s.id)
+			return err
+		}, 0,
+	)
+}
+
 // UpdateSource passes an updater to manipulate a source with a given id to a given callback.
+// The database work is canceled with the context. The follow-ups
+// of a committed update are done even if the context is canceled.
 func (m *Manager) UpdateSource(
+	ctx context.Context,
 	sourceID int64,
 	updates func(*SourceUpdater) error,
 ) (SourceUpdateResult, error) {
 	if sourceID == 0 {
 		return SourceUnchanged, InvalidArgumentError("cannot update this source")
 	}
-	type result struct {
-		v   SourceUpdateResult
-		err error
-	}
-	resCh := make(chan result)
-	m.fns <- func(m *Manager, ctx context.Context) {
+	var res SourceUpdateResult
+	var updErr error
+	if err := m.inManagerContext(ctx, func(m *Manager, ctx context.Context) {
 		s := m.findSourceByID(sourceID)
 		if s == nil {
-			resCh <- result{err: NoSuchEntryError("no such source")}
+			updErr = NoSuchEntryError("no such source")
 			return
 		}
 		su := SourceUpdater{updater: updater[*source]{updatable: s, manager: m}}
 		if err := updates(&su); err != nil {
-			resCh <- result{err: fmt.Errorf("updates failed: %w", err)}
+			updErr = fmt.Errorf("updates failed: %w", err)
 			return
 		}
 		if err := su.updateDB(ctx, "sources", s.id); err != nil {
-			resCh <- result{err: fmt.Errorf("updating database failed: %w", err)}
+			updErr = fmt.Errorf("updating database failed: %w", err)
 			return
 		}
 		// Only apply changes if database updates went through.
+		// The follow-ups must not be canceled any longer.
+		ctx = context.WithoutCancel(ctx)
 		if !su.applyChanges() {
-			resCh <- result{v: SourceUnchanged}
+			res = SourceUnchanged
 			return
 		}
+		if su.promoted {
+			if err := m.forgetChanges(ctx, s); err != nil {
+				slog.Error("resetting changes of promoted source failed", "err", err)
+			}
+			s.forceIndexRefresh()
+		}
+		// Credentials or TLS settings may have changed.
+		s.tokenSource = nil
+		if su.trustUpdated {
+			// The CA bundle was checked before so this should not fail.
+			if err := s.updateTrust(); err != nil {
+				slog.Warn("updating TLS trust settings failed", "warn", err)
+			}
+		}
 		if su.clientCertUpdated {
 			if err := s.updateCertificate(); err != nil {
 				slog.Warn("updating client cert failed", "warn", err)
@@ -1492,18 +2161,21 @@ func (m *Manager) UpdateSource(
 					x.addChange(nil, "active", false)
 					if err := x.updateDB(ctx, "sources", s.id); err != nil {
 						slog.Error("deactivating source failed", "err", err)
+					} else {
+						m.recordAction(ctx, s.id, DeactivatedAction, deactivatedDueToClientCertIssue)
 					}
-					resCh <- result{v: SourceDeactivated}
+					res = SourceDeactivated
 					return
 				}
 			} else {
 				s.status = nil
 			}
 		}
-		resCh <- result{v: SourceUpdated}
+		res = SourceUpdated
+	}); err != nil {
+		return SourceUnchanged, err
 	}
-	res := <-resCh
-	return res.v, res.err
+	return res, updErr
 }
 
 // FeedUpdater offers a protocol to update a source. Call the UpdateX
@@ -1537,47 +2209,123 @@ func (fu *FeedUpdater) UpdateLabel(label string) error {
 
 // UpdateFeed passes an updater to manipulate a feed with a given id to a given callback.
 func (m *Manager) UpdateFeed(
+	ctx context.Context,
 	feedID int64,
 	updates func(*FeedUpdater) error,
 ) (bool, error) {
-	type result struct {
+	var (
 		updated bool
-		err     error
-	}
-	resCh := make(chan result)
-	m.fns <- func(m *Manager, ctx context.Context) {
+		updErr  error
+	)
+	if err := m.inManagerContext(ctx, func(m *Manager, ctx context.Context) {
 		f := m.findFeedByID(feedID)
-		if f == nil {
-			resCh <- result{err: NoSuchEntryError("no such feed")}
+		if f == nil || f.invalid.Load() {
+			updErr = NoSuchEntryError("no such feed")
 			return
 		}
 		if f.source.id == 0 {
-			resCh <- result{err: InvalidArgumentError("cannot update this feed")}
+			updErr = InvalidArgumentError("cannot update this feed")
 		}
 		fu := FeedUpdater{updater: updater[*feed]{updatable: f, manager: m}}
 		if err := updates(&fu); err != nil {
-			resCh <- result{err: fmt.Errorf("updates failed: %w", err)}
+			updErr = fmt.Errorf("updates failed: %w", err)
 			return
 		}
 		if err := fu.updateDB(ctx, "feeds", f.id); err != nil {
-			resCh <- result{err: fmt.Errorf("updating database failed: %w", err)}
+			updErr = fmt.Errorf("updating database failed: %w", err)
 			return
 		}
 		// Only apply changes if database updates went through.
-		resCh <- result{updated: fu.applyChanges()}
+		updated = fu.applyChanges()
+	}); err != nil {
+		return false, err
 	}
-	res := <-resCh
-	return res.updated, res.err
+	return updated, updErr
 }
 
 // AttentionSources calls given callback for each active source which needs attention.
 // If the all flag is not set only the active sources are evaluated.
-func (m *Manager) AttentionSources(all bool, fn func(id int64, name string)) {
-	m.inManager(func(m *Manager, _ context.Context) {
+func (m *Manager) AttentionSources(
+	ctx context.Context,
+	all bool,
+	fn func(id int64, name string),
+) error {
+	return m.inManagerContext(ctx, func(m *Manager, _ context.Context) {
 		for _, s := range m.sources {
-			if (all || s.active) && s.checksumAck.Before(s.checksumUpdated) {
+			if (all || s.active) && s.needsAttention() {
 				fn(s.id, s.name)
 			}
 		}
 	})
 }
+
+// AcknowledgedSource is a source whose attention flag was acknowledged.
+type AcknowledgedSource struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// AcknowledgeAttention acknowledges the attention flags of all sources
+// which need attention in one go. If ids are given only these sources
+// are considered. If the all flag is not set only the active sources
+// are considered. The acknowledgements are recorded for the given actor.
+func (m *Manager) AcknowledgeAttention(
+	ctx context.Context,
+	actor string,
+	all bool,
+	ids []int64,
+) ([]AcknowledgedSource, error) {
+	const (
+		updateSQL = `UPDATE sources SET checksum_ack = $1 WHERE id = $2`
+		auditSQL  = `INSERT INTO attention_acks ` +
+			`(actor, kind, sources_id, name, changed) ` +
+			`VALUES ($1, 'source', $2, $3, $4)`
+	)
+	var (
+		acked []AcknowledgedSource
+		err   error
+	)
+	if cerr := m.inManagerContext(ctx, func(m *Manager, ctx context.Context) {
+		var srcs []*source
+		for _, s := range m.sources {
+			if (all || s.active) &&
+				s.needsAttention() &&
+				(len(ids) == 0 || slices.Contains(ids, s.id)) {
+				srcs = append(srcs, s)
+			}
+		}
+		if len(srcs) == 0 {
+			return
+		}
+		if err = m.db.Run(
+			ctx,
+			func(rctx context.Context, conn *pgxpool.Conn) error {
+				batch := &pgx.Batch{}
+				for _, s := range srcs {
+					batch.Queue(updateSQL, s.checksumUpdated, s.id)
+					batch.Queue(auditSQL, actor, s.id, s.name, s.checksumUpdated)
+				}
+				tx, err := conn.Begin(rctx)
+				if err != nil {
+					return err
+				}
+				defer tx.Rollback(rctx)
+				if err := tx.SendBatch(rctx, batch).Close(); err != nil {
+					return err
+				}
+				return tx.Commit(rctx)
+			}, 0,
+		); err != nil {
+			return
+		}
+		// Only apply changes if database updates went through.
+		acked = make([]AcknowledgedSource, 0, len(srcs))
+		for _, s := range srcs {
+			s.checksumAck = s.checksumUpdated
+			acked = append(acked, AcknowledgedSource{ID: s.id, Name: s.name})
+		}
+	}); cerr != nil {
+		return nil, cerr
+	}
+	return acked, err
+}
@@ -0,0 +1,39 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestInManagerContext(t *testing.T) {
+	m := &Manager{fns: make(chan func(*Manager, context.Context))}
+
+	// No manager loop is running so the call has to give up.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.inManagerContext(ctx, func(*Manager, context.Context) {
+		t.Error("function called with canceled context")
+	}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want %v", err, context.Canceled)
+	}
+
+	go func() {
+		fn := <-m.fns
+		fn(m, context.Background())
+	}()
+	called := false
+	if err := m.inManagerContext(context.Background(), func(*Manager, context.Context) {
+		called = true
+	}); err != nil || !called {
+		t.Fatalf("got %v and called %t", err, called)
+	}
+}
@@ -0,0 +1,181 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// metricsFlushDuration is the interval the metrics are written to the database.
+const metricsFlushDuration = time.Minute
+
+// downloadMetrics are the summed up outcomes of downloads.
+type downloadMetrics struct {
+	successes int64
+	failures  int64
+	duration  time.Duration
+	bytes     int64
+}
+
+// feedMetrics records the outcomes of the downloads of a feed.
+// It is used by the stages of the pipeline concurrently.
+type feedMetrics struct {
+	mu sync.Mutex
+	// pending are the metrics not yet written to the database.
+	pending downloadMetrics
+	// total are the metrics since the start of the server.
+	total downloadMetrics
+	// last is the time of the last download.
+	last time.Time
+}
+
+// pendingMetrics are the metrics of a feed to be written to the database.
+type pendingMetrics struct {
+	f  *feed
+	dm downloadMetrics
+}
+
+func (dm *downloadMetrics) add(o *downloadMetrics) {
+	dm.successes += o.successes
+	dm.failures += o.failures
+	dm.duration += o.duration
+	dm.bytes += o.bytes
+}
+
+func (dm *downloadMetrics) empty() bool {
+	return dm.successes == 0 && dm.failures == 0
+}
+
+// record adds the outcome of a download.
+func (fm *feedMetrics) record(success bool, duration time.Duration, bytes int64) {
+	dm := downloadMetrics{duration: duration, bytes: bytes}
+	if success {
+		dm.successes = 1
+	} else {
+		dm.failures = 1
+	}
+	now := time.Now().UTC()
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	fm.pending.add(&dm)
+	fm.total.add(&dm)
+	fm.last = now
+}
+
+// take returns the pending metrics and resets them.
+func (fm *feedMetrics) take() downloadMetrics {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	dm := fm.pending
+	fm.pending = downloadMetrics{}
+	return dm
+}
+
+// restore gives back pending metrics which could not be written.
+func (fm *feedMetrics) restore(dm *downloadMetrics) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	fm.pending.add(dm)
+}
+
+// totals returns the metrics since the start of the server.
+func (fm *feedMetrics) totals() downloadMetrics {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	return fm.total
+}
+
+// lastActivity returns the time of the last download.
+// It is zero if there was no download.
+func (fm *feedMetrics) lastActivity() time.Time {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	return fm.last
+}
+
+// recordDownload records the outcome of the download of a pending document.
+func (p *pending) recordDownload(success bool) {
+	p.f.metrics.record(success, p.duration, p.data.size)
+}
+
+// takeMetrics collects the pending metrics of all feeds.
+func (m *Manager) takeMetrics() []pendingMetrics {
+	var collected []pendingMetrics
+	for _, s := range m.sources {
+		for _, f := range s.feeds {
+			if dm := f.metrics.take(); !dm.empty() && !f.invalid.Load() {
+				collected = append(collected, pendingMetrics{f: f, dm: dm})
+			}
+		}
+	}
+	return collected
+}
+
+// enableMetricsFlushing allows the next flush of the metrics.
+func (m *Manager) enableMetricsFlushing(context.Context) {
+	m.blockMetricsFlushing = false
+}
+
+// flushMetrics writes the pending metrics of the feeds
+// into the database in the background.
+func (m *Manager) flushMetrics(ctx context.Context) {
+	if m.blockMetricsFlushing {
+		return
+	}
+	collected := m.takeMetrics()
+	if len(collected) == 0 {
+		return
+	}
+	// Prevent stacking calls.
+	m.blockMetricsFlushing = true
+	go func() {
+		defer func() { m.fns <- (*Manager).enableMetricsFlushing }()
+		m.writeMetrics(ctx, collected)
+	}()
+}
+
+// writeMetrics writes the collected metrics into the database.
+// If this fails the metrics are given back to be written with the next flush.
+func (m *Manager) writeMetrics(ctx context.Context, collected []pendingMetrics) {
+	const upsertSQL = `INSERT INTO feed_metrics ` +
+		`(feeds_id, time, successes, failures, duration_ms, bytes) ` +
+		`VALUES ($1, $2, $3, $4, $5, $6) ` +
+		`ON CONFLICT (feeds_id, time) DO UPDATE SET ` +
+		`successes = feed_metrics.successes + EXCLUDED.successes, ` +
+		`failures = feed_metrics.failures + EXCLUDED.failures, ` +
+		`duration_ms = feed_metrics.duration_ms + EXCLUDED.duration_ms, ` +
+		`bytes = feed_metrics.bytes + EXCLUDED.bytes`
+	bucket := time.Now().UTC().Truncate(time.Minute)
+	if err := m.db.Run(
+		ctx,
+		func(ctx context.Context, conn *pgxpool.Conn) error {
+			batch := &pgx.Batch{}
+			for i := range collected {
+				pm := &collected[i]
+				batch.Queue(upsertSQL,
+					pm.f.id, bucket,
+					pm.dm.successes, pm.dm.failures,
+					pm.dm.duration.Milliseconds(), pm.dm.bytes)
+			}
+			return conn.SendBatch(ctx, batch).Close()
+		}, 0,
+	); err != nil {
+		slog.Error("writing feed metrics failed", "err", err)
+		for i := range collected {
+			pm := &collected[i]
+			pm.f.metrics.restore(&pm.dm)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFeedMetrics(t *testing.T) {
+	var fm feedMetrics
+	fm.record(true, 2*time.Second, 100)
+	fm.record(false, time.Second, 0)
+
+	dm := fm.take()
+	want := downloadMetrics{successes: 1, failures: 1, duration: 3 * time.Second, bytes: 100}
+	if dm != want {
+		t.Errorf("take: got %+v, want %+v", dm, want)
+	}
+	if pending := fm.take(); !pending.empty() {
+		t.Errorf("expected no pending metrics, got %+v", pending)
+	}
+
+	// Metrics which could not be written are taken again.
+	fm.restore(&dm)
+	fm.record(true, time.Second, 50)
+	want = downloadMetrics{successes: 2, failures: 1, duration: 4 * time.Second, bytes: 150}
+	if got := fm.take(); got != want {
+		t.Errorf("take after restore: got %+v, want %+v", got, want)
+	}
+
+	// Restoring does not count twice.
+	if got := fm.totals(); got != want {
+		t.Errorf("totals: got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,54 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"context"
+	"net/url"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// oauth2TokenSource returns the source of OAuth2 tokens if the
+// source is configured to use the client credentials flow.
+// The returned token source refreshes expired tokens automatically.
+func (s *source) oauth2TokenSource(m *Manager) oauth2.TokenSource {
+	if s.oauth2TokenURL == nil {
+		return nil
+	}
+	if s.tokenSource == nil {
+		var clientID string
+		if s.oauth2ClientID != nil {
+			clientID = *s.oauth2ClientID
+		}
+		cfg := clientcredentials.Config{
+			ClientID:     clientID,
+			ClientSecret: string(s.oauth2ClientSecret),
+			TokenURL:     *s.oauth2TokenURL,
+			Scopes:       s.oauth2Scopes,
+		}
+		// Fetch the tokens with the same TLS settings as the documents.
+		ctx := context.WithValue(context.Background(), oauth2.HTTPClient, s.httpClient(m))
+		s.tokenSource = cfg.TokenSource(ctx)
+	}
+	return s.tokenSource
+}
+
+// ValidateTokenURL checks if the given OAuth2 token URL is usable.
+func ValidateTokenURL(tokenURL string) error {
+	u, err := url.Parse(tokenURL)
+	if err != nil {
+		return InvalidArgumentError("invalid OAuth2 token URL: " + err.Error())
+	}
+	if u.Scheme != "https" && u.Scheme != "http" || u.Host == "" {
+		return InvalidArgumentError("OAuth2 token URL has to be an absolute http(s) URL")
+	}
+	return nil
+}
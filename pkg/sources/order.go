@@ -0,0 +1,133 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Order is the order in which the sources or the feeds are iterated.
+// Ties are broken by the ID so that the order is stable.
+type Order string
+
+const (
+	// OrderByID orders by ascending ID.
+	OrderByID Order = "id"
+	// OrderByName orders by the name of the source or the label of the feed.
+	OrderByName Order = "name"
+	// OrderByAttention orders the sources needing attention first.
+	// Feeds cannot be ordered by attention.
+	OrderByAttention Order = "attention"
+	// OrderByActivity orders by the last download, the most recent first.
+	OrderByActivity Order = "activity"
+)
+
+// ParseOrder parses an order. An empty string orders by ID.
+func ParseOrder(s string) (Order, error) {
+	switch o := Order(strings.ToLower(strings.TrimSpace(s))); o {
+	case "":
+		return OrderByID, nil
+	case OrderByID, OrderByName, OrderByAttention, OrderByActivity:
+		return o, nil
+	default:
+		return "", InvalidArgumentError(fmt.Sprintf("invalid order %q", s))
+	}
+}
+
+// needsAttention returns true if the changes of the source
+// were not acknowledged.
+func (s *source) needsAttention() bool {
+	return s.checksumAck.Before(s.checksumUpdated)
+}
+
+// lastActivity returns the time of the last download of the feeds of the source.
+func (s *source) lastActivity() time.Time {
+	var last time.Time
+	for _, f := range s.feeds {
+		if !f.invalid.Load() {
+			if l := f.metrics.lastActivity(); l.After(last) {
+				last = l
+			}
+		}
+	}
+	return last
+}
+
+// orderedSources returns a sorted copy of the sources.
+func orderedSources(srcs []*source, order Order) []*source {
+	var cmpFn func(a, b *source) int
+	switch order {
+	case OrderByName:
+		cmpFn = func(a, b *source) int { return strings.Compare(a.name, b.name) }
+	case OrderByAttention:
+		cmpFn = func(a, b *source) int {
+			return cmpBool(b.needsAttention(), a.needsAttention())
+		}
+	case OrderByActivity:
+		// Collect the times before as they are read under locks.
+		last := make(map[*source]time.Time, len(srcs))
+		for _, s := range srcs {
+			last[s] = s.lastActivity()
+		}
+		cmpFn = func(a, b *source) int { return last[b].Compare(last[a]) }
+	}
+	sorted := slices.Clone(srcs)
+	slices.SortStableFunc(sorted, func(a, b *source) int {
+		if cmpFn != nil {
+			if c := cmpFn(a, b); c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(a.id, b.id)
+	})
+	return sorted
+}
+
+// orderedFeeds returns a sorted copy of the feeds.
+func orderedFeeds(feeds []*feed, order Order) ([]*feed, error) {
+	var cmpFn func(a, b *feed) int
+	switch order {
+	case OrderByName:
+		cmpFn = func(a, b *feed) int { return strings.Compare(a.label, b.label) }
+	case OrderByAttention:
+		return nil, InvalidArgumentError("feeds cannot be ordered by attention")
+	case OrderByActivity:
+		last := make(map[*feed]time.Time, len(feeds))
+		for _, f := range feeds {
+			last[f] = f.metrics.lastActivity()
+		}
+		cmpFn = func(a, b *feed) int { return last[b].Compare(last[a]) }
+	}
+	sorted := slices.Clone(feeds)
+	slices.SortStableFunc(sorted, func(a, b *feed) int {
+		if cmpFn != nil {
+			if c := cmpFn(a, b); c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(a.id, b.id)
+	})
+	return sorted, nil
+}
+
+// cmpBool orders false before true.
+func cmpBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
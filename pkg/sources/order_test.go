@@ -0,0 +1,68 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOrderedSources(t *testing.T) {
+	now := time.Now()
+	newSrc := func(id int64, name string, attention bool, last time.Time) *source {
+		s := &source{id: id, name: name}
+		if attention {
+			s.checksumUpdated = now
+		}
+		f := &feed{id: id}
+		f.metrics.last = last
+		s.feeds = []*feed{f}
+		return s
+	}
+	srcs := []*source{
+		newSrc(3, "b", false, now.Add(-time.Hour)),
+		newSrc(1, "c", true, time.Time{}),
+		newSrc(2, "a", true, now),
+		newSrc(4, "d", false, now.Add(-time.Hour)),
+	}
+	for _, tc := range []struct {
+		order Order
+		want  []int64
+	}{
+		{OrderByID, []int64{1, 2, 3, 4}},
+		{OrderByName, []int64{2, 3, 1, 4}},
+		{OrderByAttention, []int64{1, 2, 3, 4}},
+		{OrderByActivity, []int64{2, 3, 4, 1}},
+	} {
+		sorted := orderedSources(srcs, tc.order)
+		for i, s := range sorted {
+			if s.id != tc.want[i] {
+				t.Errorf("%s: got %d at %d, want %d", tc.order, s.id, i, tc.want[i])
+			}
+		}
+	}
+	if srcs[0].id != 3 {
+		t.Error("sources sorted in place")
+	}
+}
+
+func TestParseOrder(t *testing.T) {
+	if o, err := ParseOrder(""); err != nil || o != OrderByID {
+		t.Errorf("got %q, %v, want %q", o, err, OrderByID)
+	}
+	if o, err := ParseOrder(" Name "); err != nil || o != OrderByName {
+		t.Errorf("got %q, %v, want %q", o, err, OrderByName)
+	}
+	if _, err := ParseOrder("size"); err == nil {
+		t.Error("invalid order accepted")
+	}
+	if _, err := orderedFeeds(nil, OrderByAttention); err == nil {
+		t.Error("feeds ordered by attention")
+	}
+}
@@ -0,0 +1,295 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+)
+
+// The import of a document is split into three stages:
+//
+//  1. fetch: Downloading the document, its hashes and checksumming it.
+//     Run by the download slots.
+//  2. validate: Decoding the document and running the checks.
+//  3. import: Storing the document into the database.
+//
+// The stages are connected by bounded queues. If a queue is full
+// the previous stage blocks. As the download slots are only freed
+// when a document left the pipeline no new downloads are started.
+// The raw data of the documents in the pipeline is accounted
+// against a memory budget. Only the fetch stage waits for the budget.
+// Documents larger than the spool threshold are written to temporary
+// files while fetched and queued and are charged when they are loaded
+// by the validation.
+
+// PipelineStats are the current numbers of the import pipeline.
+type PipelineStats struct {
+	Fetching        int64 `json:"fetching"`
+	ValidationQueue int   `json:"validation_queue"`
+	Validating      int64 `json:"validating"`
+	ImportQueue     int   `json:"import_queue"`
+	Importing       int64 `json:"importing"`
+	QueueCapacity   int   `json:"queue_capacity"`
+	Spooled         int64 `json:"spooled"`
+	MemoryUsed      int64 `json:"memory_used"`
+	MemoryLimit     int64 `json:"memory_limit"`
+}
+
+// pipeline connects the stages of the import.
+type pipeline struct {
+	validation chan *pending
+	imports    chan *pending
+	memory     *memoryBudget
+	threshold  int64
+
+	fetching   atomic.Int64
+	validating atomic.Int64
+	importing  atomic.Int64
+	spooled    atomic.Int64
+}
+
+func newPipeline(cfg *config.Sources) *pipeline {
+	return &pipeline{
+		validation: make(chan *pending, cfg.StageQueueSize),
+		imports:    make(chan *pending, cfg.StageQueueSize),
+		memory:     newMemoryBudget(int64(cfg.PipelineMemory)),
+		threshold:  int64(cfg.SpoolThreshold),
+	}
+}
+
+// stats returns the current numbers of the pipeline.
+func (p *pipeline) stats() *PipelineStats {
+	return &PipelineStats{
+		Fetching:        p.fetching.Load(),
+		ValidationQueue: len(p.validation),
+		Validating:      p.validating.Load(),
+		ImportQueue:     len(p.imports),
+		Importing:       p.importing.Load(),
+		QueueCapacity:   cap(p.validation),
+		Spooled:         p.spooled.Load(),
+		MemoryUsed:      p.memory.inUse(),
+		MemoryLimit:     p.memory.limit,
+	}
+}
+
+// PipelineStats returns the current numbers of the import pipeline.
+func (m *Manager) PipelineStats() *PipelineStats {
+	return m.pipeline.stats()
+}
+
+// fetchWorker runs the fetch stage. To be used in a Go routine.
+func (m *Manager) fetchWorker(wg *sync.WaitGroup) {
+	defer wg.Done()
+	for job := range m.jobs {
+		m.pipeline.fetching.Add(1)
+		start := time.Now()
+		p, err := job.l.fetch(m, job.f)
+		duration := time.Since(start)
+		m.pipeline.fetching.Add(-1)
+		if err != nil {
+			job.f.metrics.record(false, duration, 0)
+			job.failed(m, err)
+			continue
+		}
+		p.job = job
+		p.duration = duration
+		// Blocks if the validation is congested.
+		m.pipeline.validation <- p
+	}
+}
+
+// validationWorker runs the validation stage. To be used in a Go routine.
+func (m *Manager) validationWorker(wg *sync.WaitGroup) {
+	defer wg.Done()
+	for p := range m.pipeline.validation {
+		m.pipeline.validating.Add(1)
+		err := p.validate(m)
+		m.pipeline.validating.Add(-1)
+		if err != nil {
+			p.recordDownload(false)
+			p.fail(m, err)
+			continue
+		}
+		// Blocks if the import is congested.
+		m.pipeline.imports <- p
+	}
+}
+
+// importWorker runs the import stage. To be used in a Go routine.
+func (m *Manager) importWorker(wg *sync.WaitGroup) {
+	defer wg.Done()
+	for p := range m.pipeline.imports {
+		m.pipeline.importing.Add(1)
+		ok, err := p.store(m)
+		p.recordDownload(ok)
+		m.pipeline.importing.Add(-1)
+		if err != nil {
+			p.fail(m, err)
+			continue
+		}
+		p.finish(m)
+	}
+}
+
+// runPipeline starts the workers of the stages. The returned function
+// has to be called after the jobs channel is closed to wait for the
+// pipeline to drain.
+func (m *Manager) runPipeline() func() {
+	var fetchers, validators, importers sync.WaitGroup
+	for range m.cfg.Sources.DownloadSlots {
+		fetchers.Add(1)
+		go m.fetchWorker(&fetchers)
+	}
+	for range m.cfg.Sources.ValidationWorkers {
+		validators.Add(1)
+		go m.validationWorker(&validators)
+	}
+	for range m.cfg.Sources.ImportWorkers {
+		importers.Add(1)
+		go m.importWorker(&importers)
+	}
+	return func() {
+		fetchers.Wait()
+		close(m.pipeline.validation)
+		validators.Wait()
+		close(m.pipeline.imports)
+		importers.Wait()
+	}
+}
+
+// memoryBudget limits the amount of document data in the pipeline.
+type memoryBudget struct {
+	limit int64
+	mu    sync.Mutex
+	cond  *sync.Cond
+	used  int64
+}
+
+func newMemoryBudget(limit int64) *memoryBudget {
+	mb := &memoryBudget{limit: limit}
+	mb.cond = sync.NewCond(&mb.mu)
+	return mb
+}
+
+// reserve waits until n bytes are available and reserves them.
+// Requests larger than the limit are capped to the limit.
+// Returns the reserved number of bytes.
+func (mb *memoryBudget) reserve(n int64) int64 {
+	n = max(0, min(n, mb.limit))
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	for mb.used+n > mb.limit {
+		mb.cond.Wait()
+	}
+	mb.used += n
+	return n
+}
+
+// charge reserves n bytes without waiting. This is used by the later
+// stages which must not block on the budget as this could dead lock
+// with the fetch stage. Exceeding the limit lets the fetch stage wait.
+func (mb *memoryBudget) charge(n int64) int64 {
+	n = max(0, n)
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	mb.used += n
+	return n
+}
+
+// release gives n reserved bytes back.
+func (mb *memoryBudget) release(n int64) {
+	if n <= 0 {
+		return
+	}
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	mb.used = max(0, mb.used-n)
+	mb.cond.Broadcast()
+}
+
+// inUse returns the number of reserved bytes.
+func (mb *memoryBudget) inUse() int64 {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	return mb.used
+}
+
+// spool keeps written data in memory up to a limit.
+// Beyond that the data is moved to a temporary file.
+type spool struct {
+	limit int64
+	buf   bytes.Buffer
+	file  *os.File
+	size  int64
+}
+
+// Write implements [io.Writer].
+func (s *spool) Write(p []byte) (int, error) {
+	if s.file == nil && int64(s.buf.Len()+len(p)) > s.limit {
+		f, err := os.CreateTemp("", "isduba-spool-*.json")
+		if err != nil {
+			return 0, fmt.Errorf("creating spool file failed: %w", err)
+		}
+		s.file = f
+		if _, err := s.buf.WriteTo(f); err != nil {
+			return 0, fmt.Errorf("writing spool file failed: %w", err)
+		}
+		s.buf = bytes.Buffer{}
+	}
+	var (
+		n   int
+		err error
+	)
+	if s.file != nil {
+		n, err = s.file.Write(p)
+	} else {
+		n, err = s.buf.Write(p)
+	}
+	s.size += int64(n)
+	return n, err
+}
+
+// spooled returns true if the data is written to a file.
+func (s *spool) spooled() bool {
+	return s.file != nil
+}
+
+// load returns the spooled data. The data of a file is loaded into memory.
+func (s *spool) load() ([]byte, error) {
+	if s.file == nil {
+		return s.buf.Bytes(), nil
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data := make([]byte, s.size)
+	if _, err := io.ReadFull(s.file, data); err != nil {
+		return nil, fmt.Errorf("reading spool file failed: %w", err)
+	}
+	return data, nil
+}
+
+// close removes the temporary file if there is one.
+func (s *spool) close() {
+	if s.file != nil {
+		name := s.file.Name()
+		s.file.Close()
+		os.Remove(name)
+		s.file = nil
+	}
+	s.buf = bytes.Buffer{}
+}
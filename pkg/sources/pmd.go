@@ -11,6 +11,10 @@ package sources
 import (
 	"context"
 	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -20,6 +24,7 @@ import (
 
 	"github.com/ISDuBA/ISDuBA/pkg/cache"
 	"github.com/ISDuBA/ISDuBA/pkg/config"
+	"github.com/ISDuBA/ISDuBA/pkg/httpcache"
 	"github.com/gocsaf/csaf/v3/csaf"
 	"github.com/gocsaf/csaf/v3/util"
 )
@@ -37,6 +42,40 @@ type CachedProviderMetadata struct {
 
 type pmdCache struct {
 	*cache.ExpirationCache[string, *CachedProviderMetadata]
+	http *httpcache.Cache
+}
+
+// credentials are the basic auth credentials and
+// the trust settings to access a PMD.
+type credentials struct {
+	basicAuth bool
+	user      string
+	password  []byte
+	tls       *tls.Config
+	tlsID     []byte
+}
+
+// partition returns the partition of the HTTP cache for these credentials.
+// The basic auth credentials are separated by the cache itself.
+func (c *credentials) partition() string {
+	if c == nil || c.tls == nil {
+		return ""
+	}
+	return hex.EncodeToString(c.tlsID)
+}
+
+// key returns the cache key of a PMD fetched with these credentials.
+func (c *credentials) key(url string) string {
+	if c == nil {
+		return url
+	}
+	h := sha256.New()
+	h.Write([]byte(c.user))
+	h.Write([]byte{0})
+	h.Write(c.password)
+	h.Write([]byte{0})
+	h.Write(c.tlsID)
+	return url + "|" + hex.EncodeToString(h.Sum(nil))
 }
 
 type resolvedPMD struct {
@@ -46,27 +85,68 @@ type resolvedPMD struct {
 
 type resolvedPMDs []resolvedPMD
 
-func newPMDCache() *pmdCache {
+func newPMDCache(hc *httpcache.Cache) *pmdCache {
 	return &pmdCache{
 		ExpirationCache: cache.NewExpirationCache[string, *CachedProviderMetadata](holdingPMDsDuration),
+		http:            hc,
 	}
 }
 
-func (pc *pmdCache) pmd(url string, cfg *config.Config) *CachedProviderMetadata {
+func (pc *pmdCache) pmd(url string, cfg *config.Config, creds *credentials) *CachedProviderMetadata {
+	return pc.fetch(creds.key(url), url, cfg, creds, nil)
+}
+
+// proxyPMD fetches a PMD for the PMD proxy. All the requests
+// including the redirects have to go to the configured domains.
+func (pc *pmdCache) proxyPMD(url string, cfg *config.Config) *CachedProviderMetadata {
+	return pc.fetch("proxy|"+url, url, cfg, nil, cfg.Sources.PMDProxyAllowed)
+}
 
-	if cpmd, ok := pc.Get(url); ok {
+// fetch returns the cached PMD of the key or loads it.
+// If allowed is given it has to accept the hosts of all requests.
+func (pc *pmdCache) fetch(
+	key, url string,
+	cfg *config.Config,
+	creds *credentials,
+	allowed func(host string) bool,
+) *CachedProviderMetadata {
+	if cpmd, ok := pc.Get(key); ok {
 		return cpmd
 	}
 
 	header := http.Header{}
 	header.Add("User-Agent", UserAgent)
+	if creds != nil && creds.basicAuth {
+		auth := base64.StdEncoding.EncodeToString(
+			append([]byte(creds.user+":"), creds.password...))
+		header.Add("Authorization", "Basic "+auth)
+	}
 
+	transport := cfg.General.Transport()
+	if creds != nil && creds.tls != nil {
+		transport.TLSClientConfig = creds.tls
+	}
 	baseClient := &http.Client{
-		Transport: cfg.General.Transport(),
+		Transport: pc.http.Transport(transport, creds.partition()),
 	}
 	if timeout := cfg.Sources.Timeout; timeout > 0 {
 		baseClient.Timeout = timeout
 	}
+	if allowed != nil {
+		baseClient.Transport = &allowedTransport{
+			RoundTripper: baseClient.Transport,
+			allowed:      allowed,
+		}
+		baseClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if !allowed(req.URL.Hostname()) {
+				return fmt.Errorf("redirect to disallowed host %q", req.URL.Hostname())
+			}
+			return nil
+		}
+	}
 
 	client := util.Client(&util.HeaderClient{
 		Client: baseClient,
@@ -84,10 +164,28 @@ func (pc *pmdCache) pmd(url string, cfg *config.Config) *CachedProviderMetadata
 	pmdLoader := csaf.NewProviderMetadataLoader(client)
 	lpmd := pmdLoader.Load(url)
 	cpmd := &CachedProviderMetadata{Loaded: lpmd}
-	pc.Set(url, cpmd)
+	pc.Set(key, cpmd)
 	return cpmd
 }
 
+// maxRedirects is the number of redirects followed when fetching a PMD.
+const maxRedirects = 10
+
+// allowedTransport only sends requests to allowed hosts,
+// e.g. the ones found in the security.txt of a domain.
+type allowedTransport struct {
+	http.RoundTripper
+	allowed func(host string) bool
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (at *allowedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !at.allowed(req.URL.Hostname()) {
+		return nil, fmt.Errorf("request to disallowed host %q", req.URL.Hostname())
+	}
+	return at.RoundTripper.RoundTrip(req)
+}
+
 // Valid returns true if the loaded PMD is valid.
 func (cpmd *CachedProviderMetadata) Valid() bool {
 	return cpmd != nil && cpmd.Loaded.Valid()
@@ -203,7 +301,7 @@ func (rps resolvedPMDs) resolve(cache *pmdCache, cfg *config.Config) {
 	worker := func() {
 		defer wg.Done()
 		for tr := range toResolve {
-			cpmd := cache.pmd(tr.url, cfg)
+			cpmd := cache.pmd(tr.url, cfg, nil)
 			if !cpmd.Valid() {
 				slog.Debug("Invalid PMD", "url", tr.url)
 				continue
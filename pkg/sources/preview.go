@@ -0,0 +1,65 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// FetchDocument downloads a document of a feed without importing it.
+// The download is done with the HTTP settings of the source
+// the feed belongs to. The document has to be hosted on the
+// same host as the feed. fn is called with the body of the response.
+func (m *Manager) FetchDocument(feedID int64, docURL string, fn func(io.Reader) error) error {
+	u, err := url.Parse(docURL)
+	if err != nil {
+		return InvalidArgumentError(fmt.Sprintf("invalid URL: %v", err))
+	}
+	if scheme := strings.ToLower(u.Scheme); scheme != "http" && scheme != "https" {
+		return InvalidArgumentError("URL has to be http or https")
+	}
+
+	var (
+		f      *feed
+		client *http.Client
+	)
+	m.inManager(func(m *Manager, _ context.Context) {
+		if f = m.findFeedByID(feedID); f == nil || f.invalid.Load() {
+			f = nil
+			return
+		}
+		client = f.source.httpClient(m)
+	})
+	if f == nil {
+		return NoSuchEntryError("no such feed")
+	}
+	defer client.CloseIdleConnections()
+
+	if !strings.EqualFold(u.Host, f.url.Host) {
+		return InvalidArgumentError("document is not hosted by the feed provider")
+	}
+
+	resp, err := f.source.httpGet(client, m, u.String())
+	if err != nil {
+		return fmt.Errorf("downloading %q failed: %w", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("downloading %q failed: %s (%d)",
+			u, http.StatusText(resp.StatusCode), resp.StatusCode)
+	}
+	// Prevent over-sized downloads.
+	limited := io.LimitReader(resp.Body, int64(m.cfg.General.AdvisoryUploadLimit))
+	return fn(limited)
+}
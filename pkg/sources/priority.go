@@ -0,0 +1,69 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+)
+
+// Priority is the priority class of a source.
+// The advisories of critical sources are downloaded before the
+// advisories of the other sources and their feeds are refreshed
+// more often. The advisories of low sources are downloaded only
+// if there is nothing else to download.
+type Priority string
+
+const (
+	// CriticalPriority is the priority class of the most important sources.
+	CriticalPriority Priority = "critical"
+	// NormalPriority is the priority class of the sources by default.
+	NormalPriority Priority = "normal"
+	// LowPriority is the priority class of the least important sources.
+	LowPriority Priority = "low"
+)
+
+// ParsePriority parses a priority class.
+func ParsePriority(s string) (Priority, error) {
+	switch p := Priority(strings.ToLower(strings.TrimSpace(s))); p {
+	case CriticalPriority, NormalPriority, LowPriority:
+		return p, nil
+	default:
+		return "", InvalidArgumentError(fmt.Sprintf("invalid priority %q", s))
+	}
+}
+
+// rank returns the position of the priority class in the
+// download order. Lower ranks are downloaded first.
+func (p Priority) rank() int {
+	switch p {
+	case CriticalPriority:
+		return 0
+	case LowPriority:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// feedRefresh returns the duration between the refreshes of the
+// feeds of a source of this priority class.
+func (p Priority) feedRefresh(cfg *config.Sources) time.Duration {
+	switch p {
+	case CriticalPriority:
+		return cfg.CriticalRefresh
+	case LowPriority:
+		return cfg.LowRefresh
+	default:
+		return cfg.FeedRefresh
+	}
+}
@@ -0,0 +1,88 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+)
+
+func TestParsePriority(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want Priority
+		ok   bool
+	}{
+		{"critical", CriticalPriority, true},
+		{" Normal ", NormalPriority, true},
+		{"low", LowPriority, true},
+		{"", "", false},
+		{"urgent", "", false},
+	} {
+		got, err := ParsePriority(tc.in)
+		if (err == nil) != tc.ok || got != tc.want {
+			t.Errorf("%q: got %q, %v", tc.in, got, err)
+		}
+	}
+}
+
+func TestPriorityFeedRefresh(t *testing.T) {
+	cfg := config.Sources{
+		FeedRefresh:     15 * time.Minute,
+		CriticalRefresh: 5 * time.Minute,
+		LowRefresh:      time.Hour,
+	}
+	for p, want := range map[Priority]time.Duration{
+		CriticalPriority: 5 * time.Minute,
+		NormalPriority:   15 * time.Minute,
+		LowPriority:      time.Hour,
+		"":               15 * time.Minute,
+	} {
+		if got := p.feedRefresh(&cfg); got != want {
+			t.Errorf("%q: got %v, want %v", p, got, want)
+		}
+	}
+}
+
+func TestPriorityDownloadOrder(t *testing.T) {
+	newSource := func(name string, p Priority, n int) *source {
+		s := &source{name: name, active: true, priority: p}
+		f := &feed{source: s}
+		for range n {
+			f.queue = append(f.queue, location{state: waiting})
+		}
+		s.feeds = []*feed{f}
+		return s
+	}
+	m := &Manager{
+		cfg: &config.Config{Sources: config.Sources{
+			DownloadSlots:     3,
+			MaxSlotsPerSource: 2,
+		}},
+		jobs: make(chan downloadJob, 10),
+		sources: []*source{
+			newSource("low", LowPriority, 5),
+			newSource("normal", NormalPriority, 5),
+			newSource("critical", CriticalPriority, 5),
+		},
+	}
+	m.startDownloads()
+	close(m.jobs)
+	got := map[string]int{}
+	for job := range m.jobs {
+		got[job.f.source.name]++
+	}
+	// The critical source gets all the slots it may use
+	// and the low source nothing while the others are waiting.
+	if got["critical"] != 2 || got["normal"] != 1 || got["low"] != 0 {
+		t.Errorf("unexpected distribution of the slots: %v", got)
+	}
+}
@@ -0,0 +1,47 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ValidateReceiptURL checks if the receipt URL is an absolute http(s) URL.
+func ValidateReceiptURL(receiptURL string) error {
+	u, err := url.Parse(receiptURL)
+	if err != nil {
+		return InvalidArgumentError("invalid receipt URL: " + err.Error())
+	}
+	if u.Scheme != "https" && u.Scheme != "http" || u.Host == "" {
+		return InvalidArgumentError("receipt URL has to be an absolute http(s) URL")
+	}
+	return nil
+}
+
+// queueReceipt is intended to be called in the transaction storing
+// the imported document. If the source has a receipt URL a receipt
+// is queued to acknowledge the import to the provider.
+// Only documents which passed all checks are acknowledged.
+func (p *pending) queueReceipt(m *Manager) func(context.Context, pgx.Tx, int64, bool) error {
+	return func(ctx context.Context, tx pgx.Tx, docID int64, duplicate bool) error {
+		if duplicate || p.receiptURL == nil || p.status != allSucceeded ||
+			!m.cfg.Receipts.Enabled || p.f.invalid.Load() {
+			return nil
+		}
+		const insertSQL = `INSERT INTO advisory_receipts ` +
+			`(sources_id, documents_id, callback, url) ` +
+			`VALUES ($1, $2, $3, $4)`
+		_, err := tx.Exec(ctx, insertSQL,
+			p.f.source.id, docID, *p.receiptURL, p.l.doc.String())
+		return err
+	}
+}
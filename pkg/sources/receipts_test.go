@@ -0,0 +1,29 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import "testing"
+
+func TestValidateReceiptURL(t *testing.T) {
+	for _, tc := range []struct {
+		in string
+		ok bool
+	}{
+		{"https://provider.example.com/receipts", true},
+		{"http://localhost:8080/ack", true},
+		{"ftp://provider.example.com/receipts", false},
+		{"/receipts", false},
+		{"https://", false},
+		{"://broken", false},
+	} {
+		if err := ValidateReceiptURL(tc.in); (err == nil) != tc.ok {
+			t.Errorf("%q: got %v", tc.in, err)
+		}
+	}
+}
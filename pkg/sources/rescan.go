@@ -0,0 +1,102 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ProposedFeed is a feed advertised in the PMD of a source
+// which is not configured yet.
+type ProposedFeed struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// StaleFeed is a configured feed which is not
+// advertised in the PMD of its source any more.
+type StaleFeed struct {
+	ID    int64  `json:"id"`
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// FeedRescan are the changes of the feeds of a source proposed
+// after comparing the configured feeds with the ones advertised in the PMD.
+type FeedRescan struct {
+	SourceID  int64          `json:"source_id"`
+	Checked   time.Time      `json:"checked"`
+	Additions []ProposedFeed `json:"additions"`
+	Removals  []StaleFeed    `json:"removals"`
+}
+
+// RescanFeeds re-reads the PMD of a source bypassing the cache and
+// compares the advertised feeds with the configured ones.
+// The labels of the proposed additions do not clash with
+// the labels of the configured feeds.
+func (m *Manager) RescanFeeds(sourceID int64) (*FeedRescan, error) {
+	var configured []StaleFeed
+	m.inManager(func(m *Manager, _ context.Context) {
+		s := m.findSourceByID(sourceID)
+		if s == nil {
+			return
+		}
+		configured = make([]StaleFeed, 0, len(s.feeds))
+		for _, f := range s.feeds {
+			if f.invalid.Load() {
+				continue
+			}
+			configured = append(configured, StaleFeed{
+				ID:    f.id,
+				Label: f.label,
+				URL:   f.url.String(),
+			})
+		}
+	})
+	if configured == nil {
+		return nil, NoSuchEntryError("no such source")
+	}
+	if _, err := m.EvictCaches(true, false, "", sourceID); err != nil {
+		return nil, err
+	}
+	available, err := m.AvailableFeeds(sourceID)
+	if err != nil {
+		return nil, err
+	}
+	rescan := &FeedRescan{
+		SourceID:  sourceID,
+		Checked:   time.Now().UTC(),
+		Additions: []ProposedFeed{},
+		Removals:  []StaleFeed{},
+	}
+	labels := make(map[string]bool, len(configured))
+	for _, cf := range configured {
+		labels[cf.Label] = true
+	}
+	for _, af := range available {
+		if slices.ContainsFunc(configured, func(cf StaleFeed) bool { return cf.URL == af.URL }) {
+			continue
+		}
+		label := af.Label
+		for n := 2; labels[label]; n++ {
+			label = fmt.Sprintf("%s-%d", af.Label, n)
+		}
+		labels[label] = true
+		rescan.Additions = append(rescan.Additions, ProposedFeed{Label: label, URL: af.URL})
+	}
+	for _, cf := range configured {
+		if !slices.ContainsFunc(available, func(af AvailableFeed) bool { return af.URL == cf.URL }) {
+			rescan.Removals = append(rescan.Removals, cf)
+		}
+	}
+	return rescan, nil
+}
@@ -11,7 +11,10 @@ package sources
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -24,14 +27,28 @@ import (
 
 	"github.com/ISDuBA/ISDuBA/pkg/config"
 	"github.com/ISDuBA/ISDuBA/pkg/database"
+	"github.com/ISDuBA/ISDuBA/pkg/requestid"
 	"github.com/ISDuBA/ISDuBA/pkg/version"
 	"github.com/gocsaf/csaf/v3/util"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/oauth2"
 	"golang.org/x/time/rate"
 )
 
-const deactivatedDueToClientCertIssue = `Deactivated due to client cert issue.`
+const (
+	deactivatedDueToClientCertIssue = `Deactivated due to client cert issue.`
+	deactivatedDueToOAuth2Issue     = `Deactivated due to OAuth2 client secret issue.`
+	deactivatedDueToBasicAuthIssue  = `Deactivated due to basic auth password issue.`
+	deactivatedDueToTLSIssue        = `Deactivated due to TLS CA bundle or pinning issue.`
+)
+
+const (
+	// MaxWeight is the maximal weight of a source in sharing the download slots.
+	MaxWeight = 100
+	// DefaultWeight is the weight of the sources without an explicit one.
+	DefaultWeight = 1
+)
 
 // UserAgent is the name of the http client
 var UserAgent = "isduba/" + version.SemVersion
@@ -51,6 +68,10 @@ type location struct {
 	signature *url.URL
 	state     state
 	id        int64
+	// attempts is the number of failed downloads of the location.
+	attempts int
+	// retryAt is the earliest time to retry a failed download.
+	retryAt time.Time
 }
 
 type feed struct {
@@ -61,6 +82,9 @@ type feed struct {
 	logLevel atomic.Int32
 
 	invalid atomic.Bool
+	// draining tells that the invalidated feed is deleted
+	// as soon as its running downloads are finished.
+	draining bool
 
 	nextCheck time.Time
 	queue     []location
@@ -69,6 +93,8 @@ type feed struct {
 	refreshBlocked bool
 	lastETag       string
 	lastModified   time.Time
+
+	metrics feedMetrics
 }
 
 type ignorePatterns []*regexp.Regexp
@@ -78,28 +104,56 @@ type source struct {
 	name      string
 	url       string
 	active    bool
+	shadow    bool
+	priority  Priority
 	feeds     []*feed
 	usedSlots int
+	deficit   int
+	nextFeed  int
 	status    []string
 
 	rate           *float64
 	limiter        *rate.Limiter
 	slots          *int
+	weight         *int
 	headers        []string
 	strictMode     *bool
 	secure         *bool
 	signatureCheck *bool
 	age            *time.Duration
 	ignorePatterns ignorePatterns
+	fetchWindows   FetchWindows
 
 	clientCertPublic     []byte
 	clientCertPrivate    []byte
 	clientCertPassphrase []byte
 	tlsCertificates      []tls.Certificate
 
+	oauth2TokenURL     *string
+	oauth2ClientID     *string
+	oauth2ClientSecret []byte
+	oauth2Scopes       []string
+	tokenSource        oauth2.TokenSource
+
+	basicAuthUser     *string
+	basicAuthPassword []byte
+
+	tlsCABundle    []byte
+	tlsPinnedCerts []string
+	tlsRootCAs     *x509.CertPool
+	// pinMismatch is the fingerprint of the last reported
+	// certificate which was not pinned.
+	pinMismatch atomic.Pointer[string]
+
+	// receiptURL is the callback of the provider
+	// acknowledging the imported advisories.
+	receiptURL *string
+
 	checksum        []byte
 	checksumAck     time.Time
 	checksumUpdated time.Time
+
+	aggregatorIssues []string
 }
 
 // ignore returns true if the given url should be ignored.
@@ -219,7 +273,7 @@ func (f *feed) fetchIndex(m *Manager, fn func([]location, error)) {
 	if !f.lastModified.IsZero() {
 		req.Header.Add("If-Modified-Since", f.lastModified.Format(http.TimeFormat))
 	}
-	client := f.source.httpClient(m)
+	client := f.source.cachedHTTPClient(m)
 	// Copy relevant data to avoid races.
 	fi := feedIndex{
 		base:           f.url,
@@ -348,11 +402,58 @@ func (f *feed) findLocationByID(id int64) *location {
 	return nil
 }
 
+// effectiveWeight returns the weight of the source
+// in sharing the download slots.
+func (s *source) effectiveWeight() int {
+	if s.weight != nil {
+		return *s.weight
+	}
+	return DefaultWeight
+}
+
+// maxSlots returns the number of download slots the source may use.
+func (s *source) maxSlots(cfg *config.Sources) int {
+	slots := min(cfg.MaxSlotsPerSource, cfg.DownloadSlots)
+	if s.slots != nil {
+		slots = min(slots, *s.slots)
+	}
+	return slots
+}
+
+// hasWaiting checks if there are locations ready to download.
+func (s *source) hasWaiting() bool {
+	return slices.ContainsFunc(s.feeds, func(f *feed) bool {
+		return f.findWaiting() != nil
+	})
+}
+
+// nextWaiting returns the next location ready to download.
+// The feeds of the source take turns.
+func (s *source) nextWaiting() (*feed, *location) {
+	for i := range len(s.feeds) {
+		idx := (s.nextFeed + i) % len(s.feeds)
+		if loc := s.feeds[idx].findWaiting(); loc != nil {
+			s.nextFeed = idx + 1
+			return s.feeds[idx], loc
+		}
+	}
+	return nil, nil
+}
+
+// hasRunning checks if there are running downloads of the feed.
+func (f *feed) hasRunning() bool {
+	return slices.ContainsFunc(f.queue, func(l location) bool {
+		return l.state == running
+	})
+}
+
 // findWaiting looks for a location ready to download.
+// Failed downloads are not ready before their retry time.
 func (f *feed) findWaiting() *location {
+	now := time.Now()
 	// Backwards because the new ones are at the end.
 	for i := len(f.queue) - 1; i >= 0; i-- {
-		if location := &f.queue[i]; location.state == waiting {
+		if location := &f.queue[i]; location.state == waiting && !location.retryAt.After(now) {
 			return location
 		}
 	}
@@ -360,6 +461,10 @@ func (f *feed) findWaiting() *location {
 }
 
 func (f *feed) addStats(st *Stats) {
+	total := f.metrics.totals()
+	st.Successes += total.successes
+	st.Failures += total.failures
+	st.Bytes += total.bytes
 	for i := range f.queue {
 		switch f.queue[i].state {
 		case waiting:
@@ -463,16 +568,50 @@ func (s *source) httpClient(m *Manager) *http.Client {
 		tlsConfig.Certificates = s.tlsCertificates
 	}
 
+	s.applyTrust(&tlsConfig, s.pinMismatchAlert(m))
+
 	transport := m.cfg.General.Transport()
 	transport.TLSClientConfig = &tlsConfig
 
-	client := http.Client{Transport: transport}
+	client := http.Client{Transport: requestid.NewTransport(transport)}
 	if m.cfg.Sources.Timeout > 0 {
 		client.Timeout = m.cfg.Sources.Timeout
 	}
 	return &client
 }
 
+// cachedHTTPClient returns a client for the source which answers from
+// the shared HTTP cache. It is used for the metadata and the feed
+// indices but not for the documents.
+func (s *source) cachedHTTPClient(m *Manager) *http.Client {
+	client := s.httpClient(m)
+	client.Transport = m.httpCache.Transport(client.Transport, s.cachePartition(m))
+	return client
+}
+
+// cachePartition returns the partition of the shared HTTP cache.
+// Sources with the default transport settings and without extra
+// headers share their responses with the other fetches.
+func (s *source) cachePartition(m *Manager) string {
+	secure := m.cfg.Sources.Secure
+	if s.secure != nil {
+		secure = *s.secure
+	}
+	if secure && len(s.tlsCertificates) == 0 && !s.hasTrust() && len(s.headers) == 0 {
+		return ""
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%t\x00", secure)
+	h.Write(s.clientCertPublic)
+	h.Write([]byte{0})
+	h.Write(s.trustID())
+	for _, header := range s.headers {
+		h.Write([]byte{0})
+		h.Write([]byte(header))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 func (s *source) applyHeaders(req *http.Request) {
 	for _, header := range s.headers {
 		if k, v, ok := strings.Cut(header, ":"); ok {
@@ -482,6 +621,28 @@ func (s *source) applyHeaders(req *http.Request) {
 	if req.Header.Get("User-Agent") == "" {
 		req.Header.Add("User-Agent", UserAgent)
 	}
+	if s.basicAuthUser != nil {
+		req.SetBasicAuth(*s.basicAuthUser, string(s.basicAuthPassword))
+	}
+}
+
+// credentials returns the basic auth credentials and the trust settings
+// of the source if there are any.
+func (s *source) credentials() *credentials {
+	var creds credentials
+	if s.basicAuthUser != nil {
+		creds.basicAuth = true
+		creds.user = *s.basicAuthUser
+		creds.password = s.basicAuthPassword
+	}
+	if s.hasTrust() {
+		creds.tls = s.trustConfig()
+		creds.tlsID = s.trustID()
+	}
+	if !creds.basicAuth && creds.tls == nil {
+		return nil
+	}
+	return &creds
 }
 
 // doRequest executes an HTTP request with the source specific parameters.
@@ -489,7 +650,10 @@ func (s *source) doRequest(client *http.Client, m *Manager, req *http.Request) (
 	// The manager owns the configuration.
 	// So we let the manager do the adjustment of the request.
 
-	var limiter *rate.Limiter
+	var (
+		limiter *rate.Limiter
+		ts      oauth2.TokenSource
+	)
 
 	m.inManager(func(m *Manager, _ context.Context) {
 		s.applyHeaders(req)
@@ -497,8 +661,19 @@ func (s *source) doRequest(client *http.Client, m *Manager, req *http.Request) (
 			client = s.httpClient(m)
 		}
 		limiter = s.wait()
+		ts = s.oauth2TokenSource(m)
 	})
 
+	// Fetching the token may need a round trip
+	// so it is done outside the manager.
+	if ts != nil {
+		token, err := ts.Token()
+		if err != nil {
+			return nil, fmt.Errorf("fetching OAuth2 token failed: %w", err)
+		}
+		token.SetAuthHeader(req)
+	}
+
 	if limiter != nil {
 		limiter.Wait(context.Background())
 	}
@@ -0,0 +1,124 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sourcetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gocsaf/csaf/v3/csaf"
+)
+
+// Document describes a CSAF document to be generated by [NewDocument].
+type Document struct {
+	Publisher  string
+	Namespace  string
+	TrackingID string
+	Title      string
+	TLP        csaf.TLPLabel
+	Version    int
+	Released   time.Time
+	Lang       string
+}
+
+// NewDocument generates a minimal valid CSAF document (csaf_base profile).
+// Empty fields are filled with defaults.
+func NewDocument(d Document) ([]byte, error) {
+	if d.TrackingID == "" {
+		return nil, fmt.Errorf("missing tracking id")
+	}
+	if d.Publisher == "" {
+		d.Publisher = "Example Provider"
+	}
+	if d.Namespace == "" {
+		d.Namespace = "https://example.com"
+	}
+	if d.Title == "" {
+		d.Title = "Test advisory " + d.TrackingID
+	}
+	if d.TLP == "" {
+		d.TLP = csaf.TLPLabelWhite
+	}
+	if d.Version < 1 {
+		d.Version = 1
+	}
+	if d.Released.IsZero() {
+		d.Released = time.Now()
+	}
+	if d.Lang == "" {
+		d.Lang = "en"
+	}
+	released := d.Released.UTC().Truncate(time.Second).Format(time.RFC3339)
+	history := make([]map[string]any, 0, d.Version)
+	for v := 1; v <= d.Version; v++ {
+		summary := "Initial version."
+		if v > 1 {
+			summary = "Update."
+		}
+		history = append(history, map[string]any{
+			"date":    released,
+			"number":  fmt.Sprint(v),
+			"summary": summary,
+		})
+	}
+	doc := map[string]any{
+		"document": map[string]any{
+			"category":     "csaf_base",
+			"csaf_version": "2.0",
+			"lang":         d.Lang,
+			"distribution": map[string]any{
+				"tlp": map[string]any{"label": string(d.TLP)},
+			},
+			"publisher": map[string]any{
+				"category":  "vendor",
+				"name":      d.Publisher,
+				"namespace": d.Namespace,
+			},
+			"title": d.Title,
+			"tracking": map[string]any{
+				"current_release_date": released,
+				"id":                   d.TrackingID,
+				"initial_release_date": released,
+				"revision_history":     history,
+				"status":               "final",
+				"version":              fmt.Sprint(d.Version),
+			},
+		},
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// fileNameRe matches the characters to be replaced in file names.
+var fileNameRe = regexp.MustCompile(`[^+\-a-z0-9]+`)
+
+// FileName returns the file name of a CSAF document with the
+// given tracking id as required by the CSAF standard.
+func FileName(trackingID string) string {
+	return fileNameRe.ReplaceAllString(strings.ToLower(trackingID), "_") + ".json"
+}
+
+// meta are the parts of a CSAF document needed to serve it.
+type meta struct {
+	Document struct {
+		Distribution struct {
+			TLP struct {
+				Label csaf.TLPLabel `json:"label"`
+			} `json:"tlp"`
+		} `json:"distribution"`
+		Title    string `json:"title"`
+		Tracking struct {
+			ID                 string         `json:"id"`
+			CurrentReleaseDate csaf.TimeStamp `json:"current_release_date"`
+			InitialReleaseDate csaf.TimeStamp `json:"initial_release_date"`
+		} `json:"tracking"`
+	} `json:"document"`
+}
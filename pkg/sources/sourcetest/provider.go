@@ -0,0 +1,489 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package sourcetest provides an in-process fake CSAF provider which
+// serves a provider-metadata.json, ROLIE feeds and directory layouts
+// with hashes and OpenPGP signatures. It is used to test the download
+// pipeline without real providers.
+package sourcetest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/gocsaf/csaf/v3/csaf"
+)
+
+// Layout is the distribution layout served by a provider.
+type Layout int
+
+const (
+	// ROLIE serves the documents via ROLIE feeds.
+	ROLIE Layout = 1 << iota
+	// Directory serves the documents via a directory based distribution.
+	Directory
+)
+
+// Options are the options of a [Provider].
+type Options struct {
+	// Layout is the served layout. Defaults to ROLIE.
+	Layout Layout
+	// Publisher is the name of the publisher in the provider metadata.
+	Publisher string
+	// PlainHTTP serves HTTP instead of HTTPS.
+	PlainHTTP bool
+	// Addr is the address to listen on. Defaults to a random port on localhost.
+	Addr string
+	// Unsigned serves no OpenPGP signatures.
+	Unsigned bool
+	// BadHashes serves hashes which do not match the documents.
+	BadHashes bool
+	// BadSignatures serves signatures which do not match the documents.
+	BadSignatures bool
+}
+
+// Provider is a fake CSAF provider.
+type Provider struct {
+	opts        Options
+	server      *httptest.Server
+	keyRing     *crypto.KeyRing
+	fingerprint string
+	publicKey   string
+
+	mu          sync.RWMutex
+	docs        []*served
+	lastUpdated time.Time
+}
+
+// served is a document served by the provider.
+type served struct {
+	tlp       string // lower case TLP label
+	path      string // <tlp>/<year>/<file name>
+	id        string
+	title     string
+	released  time.Time
+	updated   time.Time
+	data      []byte
+	sha256    []byte
+	sha512    []byte
+	signature []byte
+}
+
+// prefix is the path where the provider serves its files.
+const prefix = "/.well-known/csaf"
+
+// NewProvider creates and starts a new fake provider.
+// Call [Provider.Close] to stop it.
+func NewProvider(opts Options) (*Provider, error) {
+	if opts.Layout == 0 {
+		opts.Layout = ROLIE
+	}
+	if opts.Publisher == "" {
+		opts.Publisher = "Example Provider"
+	}
+	p := &Provider{opts: opts, lastUpdated: time.Now().UTC()}
+	if !opts.Unsigned {
+		key, err := crypto.GenerateKey(opts.Publisher, "csaf@example.com", "x25519", 0)
+		if err != nil {
+			return nil, fmt.Errorf("generating OpenPGP key failed: %w", err)
+		}
+		if p.publicKey, err = key.GetArmoredPublicKey(); err != nil {
+			return nil, err
+		}
+		p.fingerprint = key.GetFingerprint()
+		if p.keyRing, err = crypto.NewKeyRing(key); err != nil {
+			return nil, err
+		}
+	}
+	p.server = httptest.NewUnstartedServer(p)
+	if opts.Addr != "" {
+		l, err := net.Listen("tcp", opts.Addr)
+		if err != nil {
+			return nil, fmt.Errorf("listening on %q failed: %w", opts.Addr, err)
+		}
+		p.server.Listener.Close()
+		p.server.Listener = l
+	}
+	if opts.PlainHTTP {
+		p.server.Start()
+	} else {
+		p.server.StartTLS()
+	}
+	return p, nil
+}
+
+// Close stops the provider.
+func (p *Provider) Close() {
+	p.server.Close()
+}
+
+// URL returns the base URL of the provider.
+func (p *Provider) URL() string {
+	return p.server.URL
+}
+
+// PMDURL returns the URL of the provider metadata.
+func (p *Provider) PMDURL() string {
+	return p.server.URL + prefix + "/provider-metadata.json"
+}
+
+// Client returns a HTTP client which trusts the provider.
+func (p *Provider) Client() *http.Client {
+	return p.server.Client()
+}
+
+// CertificatePEM returns the PEM encoded TLS certificate of the provider.
+// Returns nil if the provider serves plain HTTP.
+func (p *Provider) CertificatePEM() []byte {
+	cert := p.server.Certificate()
+	if cert == nil {
+		return nil
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
+
+// Fingerprint returns the fingerprint of the OpenPGP key
+// used to sign the documents. Empty if the provider is unsigned.
+func (p *Provider) Fingerprint() string {
+	return p.fingerprint
+}
+
+// Add serves the given CSAF document. A document with the same
+// TLP label and tracking id is replaced. Returns the URL of the document.
+func (p *Provider) Add(data []byte) (string, error) {
+	var m meta
+	if err := json.Unmarshal(data, &m); err != nil {
+		return "", fmt.Errorf("parsing document failed: %w", err)
+	}
+	tracking := &m.Document.Tracking
+	if tracking.ID == "" {
+		return "", errors.New("document has no tracking id")
+	}
+	tlp := strings.ToLower(string(m.Document.Distribution.TLP.Label))
+	if tlp == "" {
+		tlp = strings.ToLower(csaf.TLPLabelWhite)
+	}
+	released := time.Time(tracking.InitialReleaseDate).UTC()
+	updated := time.Time(tracking.CurrentReleaseDate).UTC()
+	if updated.IsZero() {
+		updated = released
+	}
+	doc := &served{
+		tlp:      tlp,
+		path:     path.Join(tlp, strconv.Itoa(released.Year()), FileName(tracking.ID)),
+		id:       tracking.ID,
+		title:    m.Document.Title,
+		released: released,
+		updated:  updated,
+		data:     data,
+	}
+	sum256, sum512 := sha256.Sum256(data), sha512.Sum512(data)
+	doc.sha256, doc.sha512 = sum256[:], sum512[:]
+	if p.opts.BadHashes {
+		doc.sha256[0] ^= 0xff
+		doc.sha512[0] ^= 0xff
+	}
+	if p.keyRing != nil {
+		signed := data
+		if p.opts.BadSignatures {
+			signed = append(slices.Clip(data), '\n')
+		}
+		sig, err := p.keyRing.SignDetached(crypto.NewPlainMessage(signed))
+		if err != nil {
+			return "", fmt.Errorf("signing document failed: %w", err)
+		}
+		armored, err := sig.GetArmored()
+		if err != nil {
+			return "", err
+		}
+		doc.signature = []byte(armored)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if idx := slices.IndexFunc(p.docs, func(d *served) bool {
+		return d.path == doc.path
+	}); idx >= 0 {
+		p.docs[idx] = doc
+	} else {
+		p.docs = append(p.docs, doc)
+	}
+	p.lastUpdated = time.Now().UTC()
+	return p.documentURL(doc), nil
+}
+
+// Remove stops serving the document with the given URL.
+// Returns false if there is no such document.
+func (p *Provider) Remove(url string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	idx := slices.IndexFunc(p.docs, func(d *served) bool {
+		return p.documentURL(d) == url
+	})
+	if idx < 0 {
+		return false
+	}
+	p.docs = slices.Delete(p.docs, idx, idx+1)
+	p.lastUpdated = time.Now().UTC()
+	return true
+}
+
+// Documents returns the URLs of the served documents.
+func (p *Provider) Documents() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	urls := make([]string, len(p.docs))
+	for i, doc := range p.docs {
+		urls[i] = p.documentURL(doc)
+	}
+	return urls
+}
+
+func (p *Provider) documentURL(doc *served) string {
+	return p.server.URL + prefix + "/" + doc.path
+}
+
+// tlps returns the lower case TLP labels of the served documents.
+// If there are no documents WHITE is returned.
+func (p *Provider) tlps() []string {
+	var tlps []string
+	for _, doc := range p.docs {
+		if !slices.Contains(tlps, doc.tlp) {
+			tlps = append(tlps, doc.tlp)
+		}
+	}
+	if len(tlps) == 0 {
+		tlps = append(tlps, strings.ToLower(csaf.TLPLabelWhite))
+	}
+	slices.Sort(tlps)
+	return tlps
+}
+
+// ServeHTTP implements [http.Handler].
+func (p *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	rest, ok := strings.CutPrefix(r.URL.Path, prefix+"/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	switch {
+	case rest == "provider-metadata.json":
+		p.servePMD(w)
+		return
+	case p.keyRing != nil && rest == "openpgp/"+p.fingerprint+".asc":
+		serve(w, "application/pgp-keys", []byte(p.publicKey))
+		return
+	}
+	tlp, file, _ := strings.Cut(rest, "/")
+	if !slices.Contains(p.tlps(), tlp) {
+		http.NotFound(w, r)
+		return
+	}
+	switch {
+	case p.opts.Layout&ROLIE != 0 && file == feedName(tlp):
+		p.serveFeed(w, tlp)
+		return
+	case p.opts.Layout&Directory != 0 && file == "index.txt":
+		p.serveIndex(w, tlp)
+		return
+	case p.opts.Layout&Directory != 0 && file == "changes.csv":
+		p.serveChanges(w, tlp)
+		return
+	}
+	docPath, ext := rest, ""
+	for _, e := range []string{".sha256", ".sha512", ".asc"} {
+		if trimmed, ok := strings.CutSuffix(rest, e); ok {
+			docPath, ext = trimmed, e
+			break
+		}
+	}
+	idx := slices.IndexFunc(p.docs, func(d *served) bool { return d.path == docPath })
+	if idx < 0 {
+		http.NotFound(w, r)
+		return
+	}
+	doc := p.docs[idx]
+	name := path.Base(doc.path)
+	switch ext {
+	case "":
+		serve(w, "application/json", doc.data)
+	case ".sha256":
+		serve(w, "text/plain", fmt.Appendf(nil, "%s  %s\n", hex.EncodeToString(doc.sha256), name))
+	case ".sha512":
+		serve(w, "text/plain", fmt.Appendf(nil, "%s  %s\n", hex.EncodeToString(doc.sha512), name))
+	case ".asc":
+		if doc.signature == nil {
+			http.NotFound(w, r)
+			return
+		}
+		serve(w, "application/pgp-signature", doc.signature)
+	}
+}
+
+func serve(w http.ResponseWriter, contentType string, data []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Write(data)
+}
+
+func serveJSON(w http.ResponseWriter, v io.WriterTo) {
+	var buf bytes.Buffer
+	if _, err := v.WriteTo(&buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	serve(w, "application/json", buf.Bytes())
+}
+
+func feedName(tlp string) string {
+	return "csaf-feed-tlp-" + tlp + ".json"
+}
+
+func (p *Provider) servePMD(w http.ResponseWriter) {
+	pmd := csaf.NewProviderMetadata(p.PMDURL())
+	pmd.SetLastUpdated(p.lastUpdated)
+	var (
+		category  = csaf.CSAFCategoryVendor
+		name      = p.opts.Publisher
+		namespace = p.server.URL
+	)
+	pmd.Publisher = &csaf.Publisher{
+		Category:  &category,
+		Name:      &name,
+		Namespace: &namespace,
+	}
+	tlps := p.tlps()
+	if p.opts.Layout&ROLIE != 0 {
+		feeds := make([]csaf.Feed, 0, len(tlps))
+		for _, tlp := range tlps {
+			label := csaf.TLPLabel(strings.ToUpper(tlp))
+			url := csaf.JSONURL(p.server.URL + prefix + "/" + tlp + "/" + feedName(tlp))
+			feeds = append(feeds, csaf.Feed{
+				Summary:  "TLP:" + string(label) + " advisories",
+				TLPLabel: &label,
+				URL:      &url,
+			})
+		}
+		pmd.Distributions = append(pmd.Distributions, csaf.Distribution{
+			Rolie: &csaf.ROLIE{Feeds: feeds},
+		})
+	}
+	if p.opts.Layout&Directory != 0 {
+		for _, tlp := range tlps {
+			pmd.AddDirectoryDistribution(p.server.URL + prefix + "/" + tlp + "/")
+		}
+	}
+	if p.keyRing != nil {
+		pmd.SetPGP(p.fingerprint, p.server.URL+prefix+"/openpgp/"+p.fingerprint+".asc")
+	}
+	serveJSON(w, pmd)
+}
+
+// tlpDocs returns the documents of a TLP label, the latest first.
+func (p *Provider) tlpDocs(tlp string) []*served {
+	var docs []*served
+	for _, doc := range p.docs {
+		if doc.tlp == tlp {
+			docs = append(docs, doc)
+		}
+	}
+	slices.SortStableFunc(docs, func(a, b *served) int {
+		return b.updated.Compare(a.updated)
+	})
+	return docs
+}
+
+func (p *Provider) serveFeed(w http.ResponseWriter, tlp string) {
+	docs := p.tlpDocs(tlp)
+	feedURL := p.server.URL + prefix + "/" + tlp + "/" + feedName(tlp)
+	entries := make([]*csaf.Entry, 0, len(docs))
+	for _, doc := range docs {
+		docURL := p.documentURL(doc)
+		links := []csaf.Link{
+			{Rel: "self", HRef: docURL},
+			{Rel: "hash", HRef: docURL + ".sha256"},
+			{Rel: "hash", HRef: docURL + ".sha512"},
+		}
+		if doc.signature != nil {
+			links = append(links, csaf.Link{Rel: "signature", HRef: docURL + ".asc"})
+		}
+		entries = append(entries, &csaf.Entry{
+			ID:        doc.id,
+			Titel:     doc.title,
+			Link:      links,
+			Published: csaf.TimeStamp(doc.released),
+			Updated:   csaf.TimeStamp(doc.updated),
+			Content:   csaf.Content{Type: "application/json", Src: docURL},
+			Format: csaf.Format{
+				Schema:  "https://docs.oasis-open.org/csaf/csaf/v2.0/csaf_json_schema.json",
+				Version: "2.0",
+			},
+		})
+	}
+	label := strings.ToUpper(tlp)
+	serveJSON(w, &csaf.ROLIEFeed{
+		Feed: csaf.FeedData{
+			ID:    "csaf-feed-tlp-" + tlp,
+			Title: "CSAF feed (TLP:" + label + ")",
+			Link:  []csaf.Link{{Rel: "self", HRef: feedURL}},
+			Category: []csaf.ROLIECategory{{
+				Scheme: "urn:ietf:params:rolie:category:information-type",
+				Term:   "csaf",
+			}},
+			Updated: csaf.TimeStamp(p.lastUpdated),
+			Entry:   entries,
+		},
+	})
+}
+
+func (p *Provider) serveIndex(w http.ResponseWriter, tlp string) {
+	var buf bytes.Buffer
+	for _, doc := range p.tlpDocs(tlp) {
+		buf.WriteString(strings.TrimPrefix(doc.path, tlp+"/"))
+		buf.WriteByte('\n')
+	}
+	serve(w, "text/plain", buf.Bytes())
+}
+
+func (p *Provider) serveChanges(w http.ResponseWriter, tlp string) {
+	var buf bytes.Buffer
+	out := csv.NewWriter(&buf)
+	for _, doc := range p.tlpDocs(tlp) {
+		out.Write([]string{
+			strings.TrimPrefix(doc.path, tlp+"/"),
+			doc.updated.Format(time.RFC3339),
+		})
+	}
+	out.Flush()
+	serve(w, "text/csv", buf.Bytes())
+}
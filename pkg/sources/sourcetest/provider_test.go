@@ -0,0 +1,215 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sourcetest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/gocsaf/csaf/v3/csaf"
+)
+
+func get(t *testing.T, client *http.Client, url string) []byte {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: status %d", url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	return data
+}
+
+func TestNewDocument(t *testing.T) {
+	data, err := NewDocument(Document{TrackingID: "EX-2026-0001", Version: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	errs, err := csaf.ValidateCSAF(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) > 0 {
+		t.Errorf("document is invalid: %v", errs)
+	}
+	if _, err := NewDocument(Document{}); err == nil {
+		t.Error("expected error for missing tracking id")
+	}
+}
+
+func TestFileName(t *testing.T) {
+	for _, x := range []struct{ id, want string }{
+		{"EX-2026-0001", "ex-2026-0001.json"},
+		{"Ex:2026/1 a", "ex_2026_1_a.json"},
+		{"a+b", "a+b.json"},
+	} {
+		if got := FileName(x.id); got != x.want {
+			t.Errorf("FileName(%q) = %q, want %q", x.id, got, x.want)
+		}
+	}
+}
+
+func TestProvider(t *testing.T) {
+	p, err := NewProvider(Options{Layout: ROLIE | Directory})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	client := p.Client()
+
+	released := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var urls []string
+	for _, d := range []Document{
+		{TrackingID: "EX-2026-0001", Released: released},
+		{TrackingID: "EX-2026-0002", Released: released.Add(time.Hour), TLP: csaf.TLPLabelGreen},
+	} {
+		data, err := NewDocument(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		url, err := p.Add(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		urls = append(urls, url)
+	}
+	if n := len(p.Documents()); n != 2 {
+		t.Fatalf("got %d documents, want 2", n)
+	}
+
+	pmd, err := csaf.LoadProviderMetadata(bytes.NewReader(get(t, client, p.PMDURL())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pmd.Validate(); err != nil {
+		t.Fatalf("invalid provider metadata: %v", err)
+	}
+	if len(pmd.PGPKeys) != 1 || pmd.PGPKeys[0].URL == nil {
+		t.Fatal("missing OpenPGP key")
+	}
+	key, err := crypto.NewKeyFromArmored(string(get(t, client, *pmd.PGPKeys[0].URL)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyRing, err := crypto.NewKeyRing(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var feeds, dirs int
+	for _, dist := range pmd.Distributions {
+		if dist.Rolie != nil {
+			feeds += len(dist.Rolie.Feeds)
+			for _, feed := range dist.Rolie.Feeds {
+				rolie, err := csaf.LoadROLIEFeed(bytes.NewReader(get(t, client, string(*feed.URL))))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if n := len(rolie.Feed.Entry); n != 1 {
+					t.Errorf("feed %s has %d entries, want 1", *feed.URL, n)
+				}
+			}
+		}
+		if dist.DirectoryURL != "" {
+			dirs++
+			changes := get(t, client, dist.DirectoryURL+"changes.csv")
+			records, err := csv.NewReader(bytes.NewReader(changes)).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != 1 || !strings.HasPrefix(records[0][0], "2026/") {
+				t.Errorf("unexpected changes.csv: %q", changes)
+			}
+		}
+	}
+	if feeds != 2 || dirs != 2 {
+		t.Errorf("got %d feeds and %d directories, want 2 each", feeds, dirs)
+	}
+
+	for _, url := range urls {
+		data := get(t, client, url)
+		sum := sha256.Sum256(data)
+		hash, _, _ := strings.Cut(string(get(t, client, url+".sha256")), " ")
+		if hash != hex.EncodeToString(sum[:]) {
+			t.Errorf("hash mismatch for %s", url)
+		}
+		sig, err := crypto.NewPGPSignatureFromArmored(string(get(t, client, url+".asc")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := keyRing.VerifyDetached(
+			crypto.NewPlainMessage(data), sig, crypto.GetUnixTime(),
+		); err != nil {
+			t.Errorf("signature of %s does not verify: %v", url, err)
+		}
+	}
+
+	if !p.Remove(urls[0]) || len(p.Documents()) != 1 {
+		t.Error("removing document failed")
+	}
+}
+
+func TestProviderBadSignatures(t *testing.T) {
+	p, err := NewProvider(Options{BadHashes: true, BadSignatures: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	data, err := NewDocument(Document{TrackingID: "EX-2026-0003"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	url, err := p.Add(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := p.Client()
+	served := get(t, client, url)
+	sum := sha256.Sum256(served)
+	hash, _, _ := strings.Cut(string(get(t, client, url+".sha256")), " ")
+	if hash == hex.EncodeToString(sum[:]) {
+		t.Error("expected hash mismatch")
+	}
+	key, err := crypto.NewKeyFromArmored(p.publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyRing, err := crypto.NewKeyRing(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := crypto.NewPGPSignatureFromArmored(string(get(t, client, url+".asc")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := keyRing.VerifyDetached(
+		crypto.NewPlainMessage(served), sig, crypto.GetUnixTime(),
+	); err == nil {
+		t.Error("expected signature verification to fail")
+	}
+}
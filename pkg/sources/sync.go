@@ -0,0 +1,179 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MissingEntry is an entry of the upstream feed which is not
+// in the local holdings with the same or a newer update time.
+type MissingEntry struct {
+	URL     string     `json:"url"`
+	Updated time.Time  `json:"updated"`
+	Local   *time.Time `json:"local,omitempty"`
+	Queued  bool       `json:"queued"`
+}
+
+// SyncStatus compares the upstream content of a feed with the local holdings.
+type SyncStatus struct {
+	FeedID         int64          `json:"feed_id"`
+	Checked        time.Time      `json:"checked"`
+	Upstream       int            `json:"upstream"`
+	Local          int64          `json:"local"`
+	Missing        int            `json:"missing"`
+	Queued         int            `json:"queued"`
+	UpstreamLatest *time.Time     `json:"upstream_latest,omitempty"`
+	LocalLatest    *time.Time     `json:"local_latest,omitempty"`
+	CaughtUp       bool           `json:"caught_up"`
+	MissingEntries []MissingEntry `json:"missing_entries"`
+}
+
+// FeedSync fetches the index of a feed and compares it with the
+// entries already imported from this feed. The age and the ignore
+// patterns of the source are applied to the upstream entries.
+// At most limit missing entries are listed. A negative limit lists all.
+// Failures fetching the upstream index are reported as InvalidArgumentError.
+func (m *Manager) FeedSync(ctx context.Context, feedID int64, limit int) (*SyncStatus, error) {
+	var (
+		f      *feed
+		client *http.Client
+		fi     feedIndex
+		queued map[string]time.Time
+	)
+	m.inManager(func(m *Manager, _ context.Context) {
+		if f = m.findFeedByID(feedID); f == nil || f.invalid.Load() {
+			f = nil
+			return
+		}
+		client = f.source.cachedHTTPClient(m)
+		fi = feedIndex{
+			base:           f.url,
+			age:            f.source.age,
+			ignorePatterns: f.source.ignorePatterns,
+			sameOrNewer:    func(*location) bool { return false },
+		}
+		queued = make(map[string]time.Time, len(f.queue))
+		for i := range f.queue {
+			if l := &f.queue[i]; l.state == waiting || l.state == running {
+				queued[l.doc.String()] = l.updated
+			}
+		}
+	})
+	if f == nil {
+		return nil, NoSuchEntryError("no such feed")
+	}
+	defer client.CloseIdleConnections()
+
+	locations, err := f.fetchFullIndex(client, m, &fi)
+	if err != nil {
+		// Upstream problems are reported as invalid feeds.
+		return nil, InvalidArgumentError(err.Error())
+	}
+
+	status := SyncStatus{
+		FeedID:         feedID,
+		Checked:        time.Now().UTC(),
+		Upstream:       len(locations),
+		MissingEntries: []MissingEntry{},
+	}
+
+	const (
+		localSQL = `SELECT count(*), max(time) FROM changes WHERE feeds_id = $1`
+		timeSQL  = `SELECT time FROM changes WHERE feeds_id = $1 AND url = $2`
+	)
+	local := make([]*time.Time, len(locations))
+	if err := m.db.Run(
+		ctx,
+		func(ctx context.Context, conn *pgxpool.Conn) error {
+			if err := conn.QueryRow(ctx, localSQL, feedID).Scan(
+				&status.Local, &status.LocalLatest,
+			); err != nil {
+				return fmt.Errorf("counting local entries failed: %w", err)
+			}
+			batch := pgx.Batch{}
+			for i := range locations {
+				batch.Queue(timeSQL, feedID, locations[i].doc.String()).QueryRow(
+					func(row pgx.Row) error {
+						var t time.Time
+						switch err := row.Scan(&t); {
+						case err == nil:
+							local[i] = &t
+						case !errors.Is(err, pgx.ErrNoRows):
+							return err
+						}
+						return nil
+					})
+			}
+			return conn.SendBatch(ctx, &batch).Close()
+		}, 0,
+	); err != nil {
+		return nil, err
+	}
+
+	for i := range locations {
+		l := &locations[i]
+		if status.UpstreamLatest == nil || l.updated.After(*status.UpstreamLatest) {
+			status.UpstreamLatest = &l.updated
+		}
+		if local[i] != nil && !local[i].Before(l.updated) {
+			continue
+		}
+		doc := l.doc.String()
+		t, ok := queued[doc]
+		isQueued := ok && !t.Before(l.updated)
+		status.Missing++
+		if isQueued {
+			status.Queued++
+		}
+		if limit < 0 || len(status.MissingEntries) < limit {
+			status.MissingEntries = append(status.MissingEntries, MissingEntry{
+				URL:     doc,
+				Updated: l.updated,
+				Local:   local[i],
+				Queued:  isQueued,
+			})
+		}
+	}
+	status.CaughtUp = status.Missing == 0
+	return &status, nil
+}
+
+// fetchFullIndex fetches the index of the feed without
+// using the cache tags of the regular refreshing.
+func (f *feed) fetchFullIndex(client *http.Client, m *Manager, fi *feedIndex) ([]location, error) {
+	indexURL := f.url.String()
+	if !f.rolie {
+		var err error
+		if indexURL, err = url.JoinPath(indexURL, "changes.csv"); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := f.source.httpGet(client, m, indexURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetching feed index failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching feed index failed: %s (%d)",
+			http.StatusText(resp.StatusCode), resp.StatusCode)
+	}
+	if f.rolie {
+		return fi.rolieLocations(resp.Body)
+	}
+	return fi.directoryLocations(resp.Body)
+}
@@ -0,0 +1,156 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package sources
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ParseCABundle parses a PEM encoded bundle of CA certificates.
+func ParseCABundle(data []byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, InvalidArgumentError("CA bundle contains no certificates")
+	}
+	return pool, nil
+}
+
+// NormalizeFingerprints checks that the given strings are SHA-256
+// fingerprints of certificates and brings them into lower case hex
+// without separators. Empty strings are ignored.
+func NormalizeFingerprints(fingerprints []string) ([]string, error) {
+	var normalized []string
+	for _, fp := range fingerprints {
+		n := strings.ToLower(strings.NewReplacer(":", "", " ", "").Replace(fp))
+		if n == "" {
+			continue
+		}
+		if data, err := hex.DecodeString(n); err != nil || len(data) != sha256.Size {
+			return nil, InvalidArgumentError(
+				fmt.Sprintf("%q is not a SHA-256 fingerprint", fp))
+		}
+		if !slices.Contains(normalized, n) {
+			normalized = append(normalized, n)
+		}
+	}
+	return normalized, nil
+}
+
+// certFingerprint returns the SHA-256 fingerprint of a certificate.
+func certFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// joinPins serializes the pinned fingerprints for storing.
+func joinPins(pins []string) []byte {
+	if len(pins) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(pins, "\n"))
+}
+
+// splitPins deserializes the stored pinned fingerprints.
+func splitPins(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	return strings.Split(string(data), "\n")
+}
+
+// updateTrust rebuilds the certificate pool from the CA bundle.
+func (s *source) updateTrust() error {
+	if s.tlsCABundle == nil {
+		s.tlsRootCAs = nil
+		return nil
+	}
+	pool, err := ParseCABundle(s.tlsCABundle)
+	if err != nil {
+		s.tlsRootCAs = nil
+		return err
+	}
+	s.tlsRootCAs = pool
+	return nil
+}
+
+// hasTrust returns true if the source has its own trust settings.
+func (s *source) hasTrust() bool {
+	return s.tlsRootCAs != nil || len(s.tlsPinnedCerts) > 0
+}
+
+// trustID returns a digest of the trust settings.
+func (s *source) trustID() []byte {
+	h := sha256.New()
+	h.Write(s.tlsCABundle)
+	h.Write([]byte{0})
+	h.Write(joinPins(s.tlsPinnedCerts))
+	return h.Sum(nil)
+}
+
+// applyTrust applies the trust settings of the source to a TLS configuration.
+// A CA bundle replaces the system roots. Pinned certificates without
+// a CA bundle replace the verification of the certificate chain.
+// If alert is not nil it is called with the fingerprints of
+// presented certificates which are not pinned.
+func (s *source) applyTrust(cfg *tls.Config, alert func(string)) {
+	if s.tlsRootCAs != nil {
+		cfg.RootCAs = s.tlsRootCAs
+	}
+	if len(s.tlsPinnedCerts) == 0 {
+		return
+	}
+	if s.tlsRootCAs == nil {
+		cfg.InsecureSkipVerify = true
+	}
+	pins := s.tlsPinnedCerts
+	cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("server presented no certificate")
+		}
+		if fp := certFingerprint(cs.PeerCertificates[0]); !slices.Contains(pins, fp) {
+			if alert != nil {
+				alert(fp)
+			}
+			return fmt.Errorf("server certificate %s is not pinned", fp)
+		}
+		return nil
+	}
+}
+
+// trustConfig returns a TLS configuration with only the trust settings
+// of the source or nil if there are none.
+func (s *source) trustConfig() *tls.Config {
+	if !s.hasTrust() {
+		return nil
+	}
+	var cfg tls.Config
+	s.applyTrust(&cfg, nil)
+	return &cfg
+}
+
+// loadTrust decrypts and applies the stored trust settings.
+func (m *Manager) loadTrust(s *source, caBundle, pinnedCerts []byte) error {
+	var err error
+	if s.tlsCABundle, err = m.decrypt(caBundle); err != nil {
+		return err
+	}
+	pins, err := m.decrypt(pinnedCerts)
+	if err != nil {
+		return err
+	}
+	s.tlsPinnedCerts = splitPins(pins)
+	return s.updateTrust()
+}
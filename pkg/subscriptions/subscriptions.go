@@ -0,0 +1,552 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package subscriptions matches newly imported documents against
+// subscribed stored queries and notifies the subscribers.
+package subscriptions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+	"github.com/ISDuBA/ISDuBA/pkg/database"
+	"github.com/ISDuBA/ISDuBA/pkg/database/query"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/scheduler"
+	"github.com/ISDuBA/ISDuBA/pkg/sources"
+)
+
+// maxDeliveryBatch limits the number of matches sent in one webhook call.
+const maxDeliveryBatch = 100
+
+// Notifier matches the new documents against the subscribed
+// queries and delivers the matches to the webhooks.
+// A nil notifier is valid and does nothing.
+type Notifier struct {
+	cfg         *config.Subscriptions
+	db          *database.DB
+	task        *scheduler.Task
+	client      *http.Client
+	externalURL *url.URL
+	wake        chan struct{}
+}
+
+// NewNotifier returns a new notifier. If the subscriptions are
+// not enabled in the configuration nil is returned.
+func NewNotifier(cfg *config.Config, db *database.DB, tasks *scheduler.Registry) *Notifier {
+	if !cfg.Subscriptions.Enabled {
+		return nil
+	}
+	client := &http.Client{
+		Transport: cfg.General.Transport(),
+		// Redirects must not lead to hosts outside the webhook domains.
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return checkWebhook(&cfg.Subscriptions, req.URL)
+		},
+	}
+	if cfg.Subscriptions.WebhookTimeout > 0 {
+		client.Timeout = cfg.Subscriptions.WebhookTimeout
+	}
+	var externalURL *url.URL
+	if cfg.Web.ExternalURL != "" {
+		if u, err := url.Parse(cfg.Web.ExternalURL); err == nil {
+			externalURL = u
+		} else {
+			slog.Warn("external URL is invalid", "error", err)
+		}
+	}
+	return &Notifier{
+		cfg:         &cfg.Subscriptions,
+		db:          db,
+		client:      client,
+		externalURL: externalURL,
+		wake:        make(chan struct{}, 1),
+		task: tasks.Register("subscriptions",
+			"Notifies about new documents matching subscribed queries.",
+			cfg.Subscriptions.Interval),
+	}
+}
+
+// Run matches the new documents as soon as they are imported
+// and retries failed deliveries periodically. To be used in a Go routine.
+func (n *Notifier) Run(ctx context.Context) {
+	if n == nil {
+		return
+	}
+	go n.db.Listen(ctx, database.DocumentsImportedChannel, n.wakeUp)
+	ticker := time.NewTicker(n.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n.db.Available() && !n.task.Paused() {
+				n.scheduledNotify(ctx)
+			}
+		case <-n.wake:
+			if !n.task.Paused() {
+				n.scheduledNotify(ctx)
+			}
+		case <-n.task.Triggered():
+			n.scheduledNotify(ctx)
+		}
+	}
+}
+
+// wakeUp requests a run without blocking. Imports in quick
+// succession are handled by a single run.
+func (n *Notifier) wakeUp() {
+	select {
+	case n.wake <- struct{}{}:
+	default:
+	}
+}
+
+// scheduledNotify notifies and records the run in the scheduler task.
+func (n *Notifier) scheduledNotify(ctx context.Context) {
+	done := n.task.Start()
+	done(errors.Join(n.match(ctx), n.deliver(ctx), n.cleanup(ctx)))
+}
+
+// subscription is a subscribed stored query.
+type subscription struct {
+	id         int64
+	user       string
+	tlps       models.PublishersTLPs
+	lastImport int64
+	kind       string
+	query      string
+}
+
+// match matches the documents imported since the last run
+// against all subscribed queries. The imports are numbered in the
+// order they are committed so no document is skipped.
+func (n *Notifier) match(ctx context.Context) error {
+	const (
+		// Waits for the imports which are committing right now.
+		lockSQL          = `LOCK TABLE document_imports IN SHARE MODE`
+		maxSQL           = `SELECT coalesce(max(seq), 0) FROM document_imports`
+		subscriptionsSQL = `SELECT qs.id, qs."user", qs.tlps, qs.last_import, ` +
+			`sq.kind::text, sq.query ` +
+			`FROM query_subscriptions qs ` +
+			`JOIN stored_queries sq ON qs.stored_queries_id = sq.id ` +
+			`WHERE qs.last_import < $1`
+	)
+	var (
+		maxSeq int64
+		subs   []subscription
+	)
+	if err := n.db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+		tx, err := conn.Begin(rctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(rctx)
+		if _, err := tx.Exec(rctx, lockSQL); err != nil {
+			return err
+		}
+		if err := tx.QueryRow(rctx, maxSQL).Scan(&maxSeq); err != nil {
+			return err
+		}
+		if err := tx.Commit(rctx); err != nil {
+			return err
+		}
+		rows, _ := conn.Query(rctx, subscriptionsSQL, maxSeq)
+		subs, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (subscription, error) {
+			var s subscription
+			err := row.Scan(&s.id, &s.user, &s.tlps, &s.lastImport, &s.kind, &s.query)
+			return s, err
+		})
+		return err
+	}, 0); err != nil {
+		return fmt.Errorf("loading subscriptions failed: %w", err)
+	}
+	for i := range subs {
+		if err := n.matchSubscription(ctx, &subs[i], maxSeq); err != nil {
+			return fmt.Errorf("matching subscription %d failed: %w", subs[i].id, err)
+		}
+	}
+	return nil
+}
+
+// matchSubscription matches the documents of the imports numbered
+// in the range (last import, maxSeq] against a subscribed query.
+// Only the new documents are searched and not the whole database.
+func (n *Notifier) matchSubscription(ctx context.Context, sub *subscription, maxSeq int64) error {
+	const (
+		importsSQL = `SELECT documents_id FROM document_imports ` +
+			`WHERE seq > $1 AND seq <= $2`
+		insertSQL = `INSERT INTO query_matches (query_subscriptions_id, documents_id) ` +
+			`SELECT $1, unnest($2::int[]) ` +
+			`ON CONFLICT DO NOTHING`
+		advanceSQL = `UPDATE query_subscriptions SET last_import = $2 ` +
+			`WHERE id = $1 AND last_import < $2`
+	)
+	return n.db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+		tx, err := conn.Begin(rctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(rctx)
+		rows, _ := tx.Query(rctx, importsSQL, sub.lastImport, maxSeq)
+		imported, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+		if err != nil {
+			return err
+		}
+		if len(imported) > 0 {
+			ids, err := sub.matchDocuments(rctx, tx, imported)
+			if err != nil {
+				return err
+			}
+			if len(ids) > 0 {
+				if _, err := tx.Exec(rctx, insertSQL, sub.id, ids); err != nil {
+					return err
+				}
+				slog.Debug("subscribed query matched",
+					"subscription", sub.id, "documents", len(ids))
+			}
+		}
+		if _, err := tx.Exec(rctx, advanceSQL, sub.id, maxSeq); err != nil {
+			return err
+		}
+		return tx.Commit(rctx)
+	}, 0)
+}
+
+// matchDocuments returns the ids of the imported documents
+// matching the subscribed query.
+func (sub *subscription) matchDocuments(
+	ctx context.Context,
+	tx pgx.Tx,
+	imported []int64,
+) ([]int64, error) {
+	sql, replacements, err := sub.matchSQL(slices.Min(imported), slices.Max(imported))
+	if err != nil {
+		// The query cannot be evaluated so skip the documents.
+		slog.Warn("subscribed query is invalid",
+			"subscription", sub.id, "error", err)
+		return nil, nil
+	}
+	rows, _ := tx.Query(ctx, sql, replacements...)
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, err
+	}
+	// The id range may contain documents of other imports.
+	news := make(map[int64]bool, len(imported))
+	for _, id := range imported {
+		news[id] = true
+	}
+	return slices.DeleteFunc(ids, func(id int64) bool { return !news[id] }), nil
+}
+
+// matchSQL returns the SQL to select the ids of the documents
+// in the range [minID, maxID] matching the query and the
+// TLP permissions of the subscriber.
+func (sub *subscription) matchSQL(minID, maxID int64) (string, []any, error) {
+	var mode query.ParserMode
+	switch sub.kind {
+	case "documents":
+		mode = query.DocumentMode
+	case "advisories":
+		mode = query.AdvisoryMode
+	default:
+		return "", nil, fmt.Errorf("queries of kind %q cannot be subscribed", sub.kind)
+	}
+	parser := query.Parser{Mode: mode, Me: sub.user}
+	expr, err := parser.Parse(sub.query)
+	if err != nil {
+		return "", nil, err
+	}
+	expr = expr.
+		And(query.FieldGtInt("id", minID-1)).
+		And(query.FieldGtInt("id", maxID).Not()).
+		And(sub.tlps.AsExpr())
+	if mode == query.AdvisoryMode {
+		expr = expr.And(query.BoolField("latest"))
+	}
+	builder, err := query.NewAdvancedSQLBuilder(
+		query.AdvancedSQLBuilderExpr(expr),
+		query.AdvancedSQLBuilderFields([]string{"id"}),
+		query.AdvancedSQLBuilderParser(&parser))
+	if err != nil {
+		return "", nil, err
+	}
+	return builder.CreateQuery(-1, -1), builder.Replacements, nil
+}
+
+// Document is a matched document as sent to the webhooks.
+type Document struct {
+	ID         int64    `json:"id"`
+	Publisher  string   `json:"publisher"`
+	TrackingID string   `json:"tracking_id"`
+	Version    string   `json:"version"`
+	Title      *string  `json:"title,omitempty"`
+	TLP        *string  `json:"tlp,omitempty"`
+	Critical   *float64 `json:"critical,omitempty"`
+	URL        string   `json:"url,omitempty"`
+}
+
+// Match is a document matching a subscribed query.
+type Match struct {
+	ID       int64     `json:"id"`
+	Matched  time.Time `json:"matched"`
+	Document Document  `json:"document"`
+}
+
+// Notification is the payload sent to the webhooks.
+type Notification struct {
+	Subscription int64   `json:"subscription"`
+	QueryID      int64   `json:"query_id"`
+	QueryName    string  `json:"query_name"`
+	Matches      []Match `json:"matches"`
+	// Priority is the highest priority class of the sources
+	// the documents of the matches were downloaded from.
+	Priority sources.Priority `json:"priority,omitempty"`
+	// Test is true if the notification is a test delivery
+	// with a synthetic match.
+	Test bool `json:"test,omitempty"`
+}
+
+// delivery is a notification for a webhook.
+type delivery struct {
+	webhook      string
+	notification Notification
+}
+
+// deliver sends the pending matches to the webhooks of the subscriptions.
+// The matches of documents from critical sources are delivered first
+// and separately from the others.
+func (n *Notifier) deliver(ctx context.Context) error {
+	const (
+		pendingSQL = `SELECT qs.id, qs.webhook, sq.id, sq.name, ` +
+			`coalesce(sp.priority, 'normal')::text, ` +
+			`qm.id, qm.matched, ` +
+			`d.id, a.publisher, a.tracking_id, d.version, d.title, d.tlp, d.critical ` +
+			`FROM query_matches qm ` +
+			`JOIN query_subscriptions qs ON qm.query_subscriptions_id = qs.id ` +
+			`JOIN stored_queries sq ON qs.stored_queries_id = sq.id ` +
+			`JOIN documents d ON qm.documents_id = d.id ` +
+			`JOIN advisories a ON d.advisories_id = a.id ` +
+			`LEFT JOIN LATERAL (SELECT min(s.priority) AS priority ` +
+			`FROM downloads dl ` +
+			`JOIN feeds f ON dl.feeds_id = f.id ` +
+			`JOIN sources s ON f.sources_id = s.id ` +
+			`WHERE dl.documents_id = d.id) sp ON TRUE ` +
+			`WHERE qm.delivered IS NULL AND qs.webhook IS NOT NULL AND qm.attempts < $1 ` +
+			`ORDER BY coalesce(sp.priority, 'normal'), qs.id, qm.id`
+		deliveredSQL = `UPDATE query_matches SET delivered = $2, error = NULL, ` +
+			`attempts = attempts + 1 ` +
+			`WHERE id = ANY($1)`
+		failedSQL = `UPDATE query_matches SET error = $2, attempts = attempts + 1 ` +
+			`WHERE id = ANY($1)`
+	)
+	var deliveries []*delivery
+	if err := n.db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+		rows, err := conn.Query(rctx, pendingSQL, n.cfg.MaxAttempts)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		var current *delivery
+		for rows.Next() {
+			var (
+				subID   int64
+				webhook string
+				n       Notification
+				m       Match
+			)
+			if err := rows.Scan(
+				&subID, &webhook, &n.QueryID, &n.QueryName, &n.Priority,
+				&m.ID, &m.Matched,
+				&m.Document.ID, &m.Document.Publisher, &m.Document.TrackingID,
+				&m.Document.Version, &m.Document.Title, &m.Document.TLP,
+				&m.Document.Critical,
+			); err != nil {
+				return err
+			}
+			if current == nil || current.notification.Subscription != subID ||
+				current.notification.Priority != n.Priority ||
+				len(current.notification.Matches) >= maxDeliveryBatch {
+				n.Subscription = subID
+				current = &delivery{webhook: webhook, notification: n}
+				deliveries = append(deliveries, current)
+			}
+			m.Matched = m.Matched.UTC()
+			current.notification.Matches = append(current.notification.Matches, m)
+		}
+		return rows.Err()
+	}, 0); err != nil {
+		return fmt.Errorf("loading pending matches failed: %w", err)
+	}
+	for _, d := range deliveries {
+		for i := range d.notification.Matches {
+			d.notification.Matches[i].Document.URL = n.documentURL(
+				d.notification.Matches[i].Document.ID)
+		}
+		ids := make([]int64, len(d.notification.Matches))
+		for i := range d.notification.Matches {
+			ids[i] = d.notification.Matches[i].ID
+		}
+		var (
+			sql  = deliveredSQL
+			arg  any
+			derr = n.send(ctx, d)
+		)
+		if derr != nil {
+			slog.Warn("delivering notification failed",
+				"subscription", d.notification.Subscription, "error", derr)
+			sql, arg = failedSQL, derr.Error()
+		} else {
+			arg = time.Now().UTC()
+		}
+		if err := n.db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+			_, err := conn.Exec(rctx, sql, ids, arg)
+			return err
+		}, 0); err != nil {
+			return fmt.Errorf("storing delivery state failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// documentURL returns the API URL of a document if the
+// external URL is configured.
+func (n *Notifier) documentURL(docID int64) string {
+	if n.externalURL == nil {
+		return ""
+	}
+	return n.externalURL.JoinPath(
+		"api", "documents", strconv.FormatInt(docID, 10)).String()
+}
+
+// ErrWebhookNotAllowed is returned if a webhook does not
+// post to one of the configured webhook domains.
+var ErrWebhookNotAllowed = errors.New("webhook host is not allowed")
+
+// checkWebhook checks if the webhook posts to one of the configured domains.
+func checkWebhook(cfg *config.Subscriptions, u *url.URL) error {
+	if !cfg.WebhookAllowed(u.Hostname()) {
+		return fmt.Errorf("%w: %q", ErrWebhookNotAllowed, u.Hostname())
+	}
+	return nil
+}
+
+// newRequest returns the request to post a notification to a webhook.
+func (n *Notifier) newRequest(ctx context.Context, d *delivery) (*http.Request, error) {
+	body, err := json.Marshal(&d.notification)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.webhook, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkWebhook(n.cfg, req.URL); err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", sources.UserAgent)
+	return req, nil
+}
+
+// accepted returns true if the status code signals a successful delivery.
+func accepted(code int) bool {
+	return code >= 200 && code <= 299
+}
+
+// send posts a notification to a webhook.
+func (n *Notifier) send(ctx context.Context, d *delivery) error {
+	req, err := n.newRequest(ctx, d)
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if !accepted(resp.StatusCode) {
+		return fmt.Errorf("status code %d (%s)", resp.StatusCode, resp.Status)
+	}
+	return nil
+}
+
+// ErrDisabled is returned if the subscriptions are not enabled.
+var ErrDisabled = errors.New("subscriptions are disabled")
+
+// TestWebhook posts a notification with a synthetic match to the
+// webhook and returns the round-trip result. The notification is
+// marked as a test and the match and its document have the id 0.
+func (n *Notifier) TestWebhook(
+	ctx context.Context,
+	webhook string,
+	notification Notification,
+) (*models.DeliveryResult, error) {
+	if n == nil {
+		return nil, ErrDisabled
+	}
+	now := time.Now().UTC()
+	title := "ISDuBA test delivery"
+	notification.Test = true
+	notification.Matches = []Match{{
+		Matched: now,
+		Document: Document{
+			Publisher:  "ISDuBA",
+			TrackingID: "ISDUBA-TEST-" + strconv.FormatInt(now.Unix(), 10),
+			Version:    "1",
+			Title:      &title,
+		},
+	}}
+	req, err := n.newRequest(ctx, &delivery{webhook: webhook, notification: notification})
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(models.TestDeliveryHeader, "true")
+	resp, err := n.client.Do(req)
+	return models.NewDeliveryResult(webhook, now, resp, err, accepted), nil
+}
+
+// cleanup removes the imports matched by all subscriptions
+// and the matches older than the configured retention.
+func (n *Notifier) cleanup(ctx context.Context) error {
+	const (
+		importsSQL = `DELETE FROM document_imports WHERE seq <= ` +
+			`(SELECT coalesce(min(last_import), 0) FROM query_subscriptions)`
+		deleteSQL = `DELETE FROM query_matches WHERE matched < $1`
+	)
+	return n.db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+		if _, err := conn.Exec(rctx, importsSQL); err != nil {
+			return err
+		}
+		if n.cfg.Retention <= 0 {
+			return nil
+		}
+		_, err := conn.Exec(rctx, deleteSQL, time.Now().Add(-n.cfg.Retention))
+		return err
+	}, 0)
+}
@@ -0,0 +1,340 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package sweeper re-validates the stored documents in the background.
+package sweeper
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gocsaf/csaf/v3/csaf"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/blobs"
+	"github.com/ISDuBA/ISDuBA/pkg/database"
+)
+
+// batchSize is the number of documents validated per transaction.
+const batchSize = 100
+
+// ErrRunning is returned if a sweep is started while another one is running.
+var ErrRunning = errors.New("validation sweep already running")
+
+// Filter restricts the documents of a sweep.
+type Filter struct {
+	// Publisher restricts the sweep to the advisories of this publisher.
+	Publisher *string
+	// Since restricts the sweep to documents released at or after this time.
+	Since *time.Time
+	// Until restricts the sweep to documents released before this time.
+	Until *time.Time
+	// Remote enables the re-validation with the remote validator.
+	Remote bool
+}
+
+type job struct {
+	id       int64
+	filter   Filter
+	canceled atomic.Bool
+}
+
+// Sweeper runs the validation sweeps one after the other.
+type Sweeper struct {
+	db    *database.DB
+	blobs *blobs.Store
+	val   csaf.RemoteValidator
+	jobs  chan *job
+
+	mu      sync.Mutex
+	current *job
+}
+
+// NewSweeper creates a new sweeper.
+func NewSweeper(db *database.DB, bs *blobs.Store, val csaf.RemoteValidator) *Sweeper {
+	return &Sweeper{
+		db:    db,
+		blobs: bs,
+		val:   val,
+		jobs:  make(chan *job),
+	}
+}
+
+// HasRemoteValidator returns true if a remote validator is configured.
+func (s *Sweeper) HasRemoteValidator() bool {
+	return s.val != nil
+}
+
+// Run runs the sweeper till the context is canceled.
+func (s *Sweeper) Run(ctx context.Context) {
+	s.interrupted(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case j := <-s.jobs:
+			s.sweep(ctx, j)
+		}
+	}
+}
+
+// interrupted marks the sweeps left over from a previous run as failed.
+func (s *Sweeper) interrupted(ctx context.Context) {
+	const updateSQL = `UPDATE validation_sweeps ` +
+		`SET (status, finished, error) = ('failed', current_timestamp, 'interrupted') ` +
+		`WHERE status = 'running'`
+	if err := s.db.Run(
+		ctx,
+		func(ctx context.Context, conn *pgxpool.Conn) error {
+			_, err := conn.Exec(ctx, updateSQL)
+			return err
+		}, 0,
+	); err != nil {
+		slog.Error("marking interrupted validation sweeps failed", "err", err)
+	}
+}
+
+// Start registers a new sweep and hands it over to the background job.
+func (s *Sweeper) Start(ctx context.Context, creator sql.NullString, filter Filter) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current != nil {
+		return 0, ErrRunning
+	}
+	const insertSQL = `INSERT INTO validation_sweeps ` +
+		`(creator, publisher, since, until, remote) ` +
+		`VALUES ($1, $2, $3, $4, $5) ` +
+		`RETURNING id`
+	var id int64
+	if err := s.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			return conn.QueryRow(rctx, insertSQL,
+				creator, filter.Publisher, filter.Since, filter.Until,
+				filter.Remote && s.val != nil,
+			).Scan(&id)
+		}, 0,
+	); err != nil {
+		return 0, err
+	}
+	j := &job{id: id, filter: filter}
+	j.filter.Remote = filter.Remote && s.val != nil
+	s.current = j
+	go func() { s.jobs <- j }()
+	return id, nil
+}
+
+// Cancel cancels the running sweep with the given id.
+// Returns false if there is no such running sweep.
+func (s *Sweeper) Cancel(id int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current == nil || s.current.id != id {
+		return false
+	}
+	s.current.canceled.Store(true)
+	return true
+}
+
+type verdict struct {
+	schemaFailed bool
+	remoteFailed *bool
+	messages     []string
+}
+
+type candidate struct {
+	id             int64
+	original       []byte
+	key            *string
+	previousSchema bool
+	previousRemote *bool
+}
+
+// sweep validates the documents matching the filter of the job in batches.
+func (s *Sweeper) sweep(ctx context.Context, j *job) {
+	defer func() {
+		s.mu.Lock()
+		s.current = nil
+		s.mu.Unlock()
+	}()
+	slog.Info("validation sweep started", "id", j.id)
+	var (
+		last   int64
+		status = "finished"
+		errMsg *string
+	)
+	for {
+		if j.canceled.Load() {
+			status = "canceled"
+			break
+		}
+		n, next, err := s.sweepBatch(ctx, j, last)
+		if err != nil {
+			slog.Error("validation sweep failed", "id", j.id, "err", err)
+			msg := err.Error()
+			status, errMsg = "failed", &msg
+			break
+		}
+		if n < batchSize {
+			break
+		}
+		last = next
+	}
+	const finishSQL = `UPDATE validation_sweeps ` +
+		`SET (status, finished, error) = ($1, current_timestamp, $2) ` +
+		`WHERE id = $3`
+	if err := s.db.Run(
+		context.WithoutCancel(ctx),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			_, err := conn.Exec(rctx, finishSQL, status, errMsg, j.id)
+			return err
+		}, 0,
+	); err != nil {
+		slog.Error("finishing validation sweep failed", "id", j.id, "err", err)
+	}
+	slog.Info("validation sweep ended", "id", j.id, "status", status)
+}
+
+// sweepBatch validates the next batch of documents after the last id.
+// Returns the number of documents and the highest id of the batch.
+func (s *Sweeper) sweepBatch(ctx context.Context, j *job, last int64) (int, int64, error) {
+	const selectSQL = `SELECT documents.id, documents.original, documents.original_key, ` +
+		`coalesce(downloads.schema_failed, false), downloads.remote_failed ` +
+		`FROM documents JOIN advisories ON documents.advisories_id = advisories.id ` +
+		`LEFT JOIN LATERAL (` +
+		`SELECT schema_failed, remote_failed FROM downloads ` +
+		`WHERE downloads.documents_id = documents.id ` +
+		`ORDER BY time DESC LIMIT 1) AS downloads ON TRUE ` +
+		`WHERE documents.id > $1 ` +
+		`AND ($2::text IS NULL OR advisories.publisher = $2) ` +
+		`AND ($3::timestamptz IS NULL OR documents.current_release_date >= $3) ` +
+		`AND ($4::timestamptz IS NULL OR documents.current_release_date < $4) ` +
+		`ORDER BY documents.id ` +
+		`LIMIT $5`
+	var candidates []candidate
+	if err := s.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, selectSQL,
+				last, j.filter.Publisher, j.filter.Since, j.filter.Until, batchSize)
+			var err error
+			candidates, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (candidate, error) {
+				var c candidate
+				err := row.Scan(&c.id, &c.original, &c.key, &c.previousSchema, &c.previousRemote)
+				return c, err
+			})
+			return err
+		}, 0,
+	); err != nil {
+		return 0, 0, fmt.Errorf("loading documents failed: %w", err)
+	}
+	if len(candidates) == 0 {
+		return 0, last, nil
+	}
+
+	var (
+		batch                pgx.Batch
+		regressions, fixedUp int
+	)
+	const insertSQL = `INSERT INTO validation_results (` +
+		`validation_sweeps_id, documents_id, ` +
+		`schema_failed, remote_failed, ` +
+		`previous_schema_failed, previous_remote_failed, ` +
+		`regression, messages) ` +
+		`VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	for i := range candidates {
+		c := &candidates[i]
+		original, err := s.blobs.Original(ctx, c.original, c.key)
+		if err != nil {
+			return 0, 0, fmt.Errorf("loading document %d failed: %w", c.id, err)
+		}
+		v, err := s.validate(original, j.filter.Remote)
+		if err != nil {
+			return 0, 0, err
+		}
+		// Without remote validation only the schema verdicts are comparable.
+		failedBefore := c.previousSchema ||
+			j.filter.Remote && c.previousRemote != nil && *c.previousRemote
+		failedNow := v.schemaFailed || v.remoteFailed != nil && *v.remoteFailed
+		if failedBefore == failedNow {
+			continue
+		}
+		if failedNow {
+			regressions++
+		} else {
+			fixedUp++
+		}
+		batch.Queue(insertSQL,
+			j.id, c.id,
+			v.schemaFailed, v.remoteFailed,
+			c.previousSchema, c.previousRemote,
+			failedNow, v.messages)
+	}
+	const updateSQL = `UPDATE validation_sweeps SET ` +
+		`checked = checked + $1, regressions = regressions + $2, fixed = fixed + $3 ` +
+		`WHERE id = $4`
+	batch.Queue(updateSQL, len(candidates), regressions, fixedUp, j.id)
+	if err := s.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tx, err := conn.Begin(rctx)
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(rctx)
+			if err := tx.SendBatch(rctx, &batch).Close(); err != nil {
+				return err
+			}
+			return tx.Commit(rctx)
+		}, 0,
+	); err != nil {
+		return 0, 0, fmt.Errorf("storing validation results failed: %w", err)
+	}
+	return len(candidates), candidates[len(candidates)-1].id, nil
+}
+
+// validate checks the original document against the schema
+// and optionally against the remote validator.
+func (s *Sweeper) validate(original []byte, remote bool) (*verdict, error) {
+	var doc any
+	if err := json.Unmarshal(original, &doc); err != nil {
+		return &verdict{
+			schemaFailed: true,
+			messages:     []string{"invalid JSON: " + err.Error()},
+		}, nil
+	}
+	var v verdict
+	switch msgs, err := csaf.ValidateCSAF(doc); {
+	case err != nil:
+		v.schemaFailed = true
+		v.messages = append(v.messages, "schema validation failed: "+err.Error())
+	case len(msgs) > 0:
+		v.schemaFailed = true
+		v.messages = append(v.messages, msgs...)
+	}
+	if remote {
+		rvr, err := s.val.Validate(doc)
+		if err != nil {
+			return nil, fmt.Errorf("remote validation failed: %w", err)
+		}
+		failed := !rvr.Valid
+		v.remoteFailed = &failed
+		if failed {
+			v.messages = append(v.messages, "remote validator classifies document as invalid")
+		}
+	}
+	return &v, nil
+}
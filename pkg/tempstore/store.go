@@ -17,9 +17,12 @@ import (
 	"fmt"
 	"io"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/ISDuBA/ISDuBA/pkg/config"
+	"github.com/ISDuBA/ISDuBA/pkg/scanner"
+	"github.com/ISDuBA/ISDuBA/pkg/scheduler"
 	"github.com/gocsaf/csaf/v3/util"
 )
 
@@ -34,7 +37,10 @@ type Store struct {
 	fns     chan func(*Store)
 	done    bool
 	total   int
+	size    int64
 	entries map[string][]entry
+	task    *scheduler.Task
+	scanner *scanner.Scanner
 }
 
 // Entry represents a file hold in the store.
@@ -43,20 +49,41 @@ type Entry struct {
 	Expired  time.Time `json:"expired"`
 	Filename string    `json:"filename"`
 	Length   int64     `json:"length"`
+	Size     int64     `json:"size"`
 	ID       int64     `json:"id"`
 }
 
+// Usage summarizes the files a user holds in the store
+// and how much room is left for the user.
+type Usage struct {
+	User      string  `json:"user"`
+	Files     []Entry `json:"files"`
+	Size      int64   `json:"size"`
+	FreeFiles int     `json:"free_files"`
+	FreeSize  int64   `json:"free_size"`
+}
+
 type entry struct {
 	Entry
+	ttl  time.Duration
 	data []byte
 }
 
-// NewStore returns a new store.
-func NewStore(cfg *config.TempStore) *Store {
+// NewStore returns a new store. If a scanner is given
+// the files are scanned for malware before they are stored.
+func NewStore(
+	cfg *config.TempStore,
+	tasks *scheduler.Registry,
+	scanner *scanner.Scanner,
+) *Store {
 	return &Store{
 		cfg:     cfg,
 		fns:     make(chan func(*Store)),
 		entries: make(map[string][]entry),
+		scanner: scanner,
+		task: tasks.Register("tempstore_cleanup",
+			"Removes expired documents from the temporary store.",
+			cleanupDuration),
 	}
 }
 
@@ -71,11 +98,22 @@ func (st *Store) Run(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case t := <-ticker.C:
-			st.cleanup(t)
+			if !st.task.Paused() {
+				st.scheduledCleanup(t)
+			}
+		case <-st.task.Triggered():
+			st.scheduledCleanup(time.Now())
 		}
 	}
 }
 
+// scheduledCleanup cleans up and records the run in the scheduler task.
+func (st *Store) scheduledCleanup(now time.Time) {
+	done := st.task.Start()
+	st.cleanup(now)
+	done(nil)
+}
+
 func (st *Store) kill() { st.done = true }
 
 // Kill shuts down the store.
@@ -90,61 +128,80 @@ func (st *Store) Total() int {
 
 // List lists the entries for a given user.
 func (st *Store) List(user string) []Entry {
-	result := make(chan []Entry)
+	return st.Usage(user).Files
+}
+
+// Usage returns the usage of the store for a given user.
+func (st *Store) Usage(user string) Usage {
+	result := make(chan Usage)
 	st.fns <- func(st *Store) {
 		now := time.Now()
-		userEntries := st.entries[user]
-		entries := slices.DeleteFunc(userEntries, func(e entry) bool {
-			return e.Expired.Before(now)
-		})
-		if diff := len(userEntries) - len(entries); diff > 0 {
-			st.total -= diff
-			if len(entries) > 0 {
-				st.entries[user] = entries
-			} else {
-				delete(st.entries, user)
-			}
-		}
-		list := make([]Entry, len(entries))
-		for i := range entries {
-			list[i] = entries[i].Entry
+		st.remove(user, func(e *entry) bool { return e.Expired.Before(now) })
+		result <- st.usage(user)
+	}
+	return <-result
+}
+
+// Usages returns the usages of all users holding files in the store
+// ordered by user name.
+func (st *Store) Usages() []Usage {
+	result := make(chan []Usage)
+	st.fns <- func(st *Store) {
+		st.cleanup(time.Now())
+		usages := make([]Usage, 0, len(st.entries))
+		for user := range st.entries {
+			usages = append(usages, st.usage(user))
 		}
-		result <- list
+		slices.SortFunc(usages, func(a, b Usage) int {
+			return strings.Compare(a.User, b.User)
+		})
+		result <- usages
 	}
 	return <-result
 }
 
+// usage summarizes the entries of a given user.
+func (st *Store) usage(user string) Usage {
+	userEntries := st.entries[user]
+	files := make([]Entry, len(userEntries))
+	var size int64
+	for i := range userEntries {
+		files[i] = userEntries[i].Entry
+		size += userEntries[i].Size
+	}
+	filesUser, sizeUser := st.cfg.UserLimits(user)
+	return Usage{
+		User:  user,
+		Files: files,
+		Size:  size,
+		FreeFiles: max(0, min(
+			st.cfg.FilesTotal-st.total,
+			filesUser-len(files))),
+		FreeSize: max(0, min(
+			int64(st.cfg.SizeTotal)-st.size,
+			int64(sizeUser)-size)),
+	}
+}
+
 // Delete deletes a given file for a given user.
 // Returns true is file was really deleted.
 func (st *Store) Delete(user string, id int64) bool {
 	result := make(chan bool)
 	st.fns <- func(st *Store) {
-		userEntries := st.entries[user]
-		if len(userEntries) == 0 {
-			result <- false
-			return
-		}
 		deleted := false
 		now := time.Now()
-		entries := slices.DeleteFunc(userEntries, func(e entry) bool {
+		st.remove(user, func(e *entry) bool {
 			found := e.ID == id
 			deleted = deleted || found
 			return found || e.Expired.Before(now)
 		})
-		if diff := len(userEntries) - len(entries); diff > 0 {
-			st.total -= diff
-			if len(entries) > 0 {
-				st.entries[user] = entries
-			} else {
-				delete(st.entries, user)
-			}
-		}
 		result <- deleted
 	}
 	return <-result
 }
 
 // Fetch fetches a stored file for a given user and id.
+// The expiry of the file is extended by its storage duration.
 func (st *Store) Fetch(user string, id int64) (r io.Reader, result Entry, err error) {
 	done := make(chan struct{})
 	st.fns <- func(st *Store) {
@@ -160,7 +217,7 @@ func (st *Store) Fetch(user string, id int64) (r io.Reader, result Entry, err er
 				if entry.Expired.Before(now) {
 					err = ErrFileNotFound
 				} else {
-					entry.Expired = now.Add(st.cfg.StorageDuration)
+					entry.Expired = now.Add(entry.ttl)
 					result = entry.Entry
 					r, err = gzip.NewReader(bytes.NewReader(entry.data))
 				}
@@ -174,8 +231,22 @@ func (st *Store) Fetch(user string, id int64) (r io.Reader, result Entry, err er
 }
 
 // Store stores a file with a filename for a given user.
+// The file is kept for ttl after its last access. If ttl is not
+// positive the configured storage duration is used. It is capped
+// by the configured max storage duration.
 // Returns a unique id to fetch it afterwards.
-func (st *Store) Store(user, filename string, store func(io.Writer) error) (id int64, err error) {
+// Files in which malware is found are rejected.
+func (st *Store) Store(
+	ctx context.Context,
+	user, filename string,
+	ttl time.Duration,
+	store func(io.Writer) error,
+) (id int64, err error) {
+	if ttl <= 0 {
+		ttl = st.cfg.StorageDuration
+	}
+	ttl = min(ttl, st.cfg.MaxStorageDuration)
+
 	var buf bytes.Buffer
 	var w *gzip.Writer
 	if w, err = gzip.NewWriterLevel(&buf, gzip.BestSpeed); err != nil {
@@ -192,33 +263,47 @@ func (st *Store) Store(user, filename string, store func(io.Writer) error) (id i
 		return
 	}
 	data := bytes.Clone(buf.Bytes())
+	size := int64(len(data))
+
+	if st.scanner != nil {
+		var r io.Reader
+		if r, err = gzip.NewReader(bytes.NewReader(data)); err != nil {
+			return
+		}
+		if err = st.scanner.Scan(ctx, filename, r); err != nil {
+			return
+		}
+	}
 
 	done := make(chan struct{})
 	st.fns <- func(st *Store) {
 		defer close(done)
 		now := time.Now()
 
-		if st.total >= st.cfg.FilesTotal {
-			// Try to make some room.
-			st.cleanup(now)
-			if st.total >= st.cfg.FilesTotal {
-				err = errors.New("too many files total")
-				return
-			}
+		// Make some room.
+		st.cleanup(now)
+
+		var (
+			userEntries         = st.entries[user]
+			filesUser, sizeUser = st.cfg.UserLimits(user)
+			userSize            int64
+		)
+		for i := range userEntries {
+			userSize += userEntries[i].Size
 		}
-		userEntries := st.entries[user]
-		if len(userEntries) >= st.cfg.FilesUser {
-			// Try to make some room.
-			entries := slices.DeleteFunc(userEntries, func(e entry) bool {
-				return e.Expired.Before(now)
-			})
-			if diff := len(userEntries) - len(entries); diff > 0 {
-				st.total -= diff
-				userEntries = entries
-			} else {
-				err = errors.New("too many files per user")
-				return
-			}
+		switch {
+		case st.total >= st.cfg.FilesTotal:
+			err = errors.New("too many files total")
+			return
+		case st.size+size > int64(st.cfg.SizeTotal):
+			err = errors.New("total storage size exceeded")
+			return
+		case len(userEntries) >= filesUser:
+			err = errors.New("too many files per user")
+			return
+		case userSize+size > int64(sizeUser):
+			err = errors.New("storage size per user exceeded")
+			return
 		}
 		id = -1
 		for i := range userEntries {
@@ -228,32 +313,50 @@ func (st *Store) Store(user, filename string, store func(io.Writer) error) (id i
 		st.entries[user] = append(userEntries, entry{
 			Entry: Entry{
 				Inserted: now,
-				Expired:  now.Add(st.cfg.StorageDuration),
+				Expired:  now.Add(ttl),
 				Filename: filename,
 				Length:   nw.N,
+				Size:     size,
 				ID:       id,
 			},
+			ttl:  ttl,
 			data: data,
 		})
 		st.total++
+		st.size += size
 	}
 	<-done
 	return
 }
 
+// remove removes the entries of a given user matching pred.
+func (st *Store) remove(user string, pred func(*entry) bool) {
+	userEntries := st.entries[user]
+	if len(userEntries) == 0 {
+		return
+	}
+	var size int64
+	entries := slices.DeleteFunc(userEntries, func(e entry) bool {
+		if pred(&e) {
+			size += e.Size
+			return true
+		}
+		return false
+	})
+	if diff := len(userEntries) - len(entries); diff > 0 {
+		st.total -= diff
+		st.size -= size
+		if len(entries) > 0 {
+			st.entries[user] = entries
+		} else {
+			delete(st.entries, user)
+		}
+	}
+}
+
 // cleanup removes files from store which were idle for too long.
 func (st *Store) cleanup(now time.Time) {
-	for user, userEntries := range st.entries {
-		entries := slices.DeleteFunc(userEntries, func(e entry) bool {
-			return e.Expired.Before(now)
-		})
-		if diff := len(userEntries) - len(entries); diff > 0 {
-			st.total -= diff
-			if len(entries) > 0 {
-				st.entries[user] = entries
-			} else {
-				delete(st.entries, user)
-			}
-		}
+	for user := range st.entries {
+		st.remove(user, func(e *entry) bool { return e.Expired.Before(now) })
 	}
 }
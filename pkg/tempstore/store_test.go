@@ -0,0 +1,91 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2024 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2024 Intevation GmbH <https://intevation.de>
+
+package tempstore
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+	"github.com/ISDuBA/ISDuBA/pkg/scheduler"
+)
+
+func TestStoreQuotas(t *testing.T) {
+	cfg := &config.TempStore{
+		FilesTotal:         3,
+		FilesUser:          1,
+		SizeTotal:          1024 * 1024,
+		SizeUser:           1024 * 1024,
+		StorageDuration:    time.Minute,
+		MaxStorageDuration: time.Hour,
+		Quotas: map[string]config.TempStoreQuota{
+			"alice": {Files: 2},
+			"bob":   {Size: 1},
+		},
+	}
+	st := NewStore(cfg, scheduler.NewRegistry(), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go st.Run(ctx)
+
+	store := func(user string, ttl time.Duration) (int64, error) {
+		return st.Store(ctx, user, "doc.json", ttl, func(w io.Writer) error {
+			_, err := io.Copy(w, strings.NewReader(`{"document":{}}`))
+			return err
+		})
+	}
+
+	if _, err := store("alice", 2*time.Hour); err != nil {
+		t.Fatalf("first file of alice: %v", err)
+	}
+	if _, err := store("alice", 0); err != nil {
+		t.Fatalf("second file of alice: %v", err)
+	}
+	if _, err := store("alice", 0); err == nil {
+		t.Error("third file of alice should exceed the quota")
+	}
+	if _, err := store("bob", 0); err == nil {
+		t.Error("file of bob should exceed the size quota")
+	}
+	if _, err := store("carol", 0); err != nil {
+		t.Fatalf("file of carol: %v", err)
+	}
+	if _, err := store("dave", 0); err == nil {
+		t.Error("file of dave should exceed the total number of files")
+	}
+
+	usages := st.Usages()
+	if len(usages) != 2 || usages[0].User != "alice" || usages[1].User != "carol" {
+		t.Fatalf("unexpected usages: %+v", usages)
+	}
+	alice := usages[0]
+	if len(alice.Files) != 2 || alice.FreeFiles != 0 {
+		t.Errorf("alice: got %d files and %d free, want 2 and 0",
+			len(alice.Files), alice.FreeFiles)
+	}
+	if ttl := alice.Files[0].Expired.Sub(alice.Files[0].Inserted); ttl != time.Hour {
+		t.Errorf("ttl: got %s, want it capped to %s", ttl, time.Hour)
+	}
+	if ttl := alice.Files[1].Expired.Sub(alice.Files[1].Inserted); ttl != time.Minute {
+		t.Errorf("ttl: got %s, want the default %s", ttl, time.Minute)
+	}
+
+	if !st.Delete("alice", alice.Files[0].ID) {
+		t.Fatal("deleting file of alice failed")
+	}
+	if u := st.Usage("alice"); len(u.Files) != 1 || u.FreeFiles != 1 || u.Size != alice.Size-alice.Files[0].Size {
+		t.Errorf("alice after delete: %+v", u)
+	}
+	if _, err := store("dave", 0); err != nil {
+		t.Errorf("file of dave after delete: %v", err)
+	}
+}
@@ -0,0 +1,298 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package transform normalizes known quirks of the documents of
+// the providers with configured rules before they are imported.
+package transform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gocsaf/csaf/v3/csaf"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// Change is the provenance of a field changed by a rule.
+type Change struct {
+	// Rule is the name of the rule.
+	Rule string `json:"rule"`
+	// Path is the JSON pointer of the field.
+	Path     string `json:"path"`
+	Original string `json:"original"`
+	Replaced string `json:"replaced"`
+}
+
+// rule is a compiled transformation rule.
+type rule struct {
+	name        string
+	publisher   string
+	path        []string
+	category    string
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// Transformer applies the transformation rules to documents.
+// A nil transformer is valid and leaves the documents untouched.
+type Transformer struct {
+	rules []rule
+}
+
+// NewTransformer returns a new transformer. If no rules
+// are configured nil is returned.
+func NewTransformer(cfg *config.Transformations) (*Transformer, error) {
+	if len(cfg.Rules) == 0 {
+		return nil, nil
+	}
+	rules := make([]rule, 0, len(cfg.Rules))
+	for i := range cfg.Rules {
+		r := &cfg.Rules[i]
+		pattern, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+		rules = append(rules, rule{
+			name:        r.Name,
+			publisher:   r.Publisher,
+			path:        splitPointer(r.Path),
+			category:    r.Category,
+			pattern:     pattern,
+			replacement: r.Replacement,
+		})
+	}
+	return &Transformer{rules: rules}, nil
+}
+
+var (
+	unescapePointer = strings.NewReplacer("~1", "/", "~0", "~")
+	escapePointer   = strings.NewReplacer("~", "~0", "/", "~1")
+)
+
+// splitPointer splits a JSON pointer into its unescaped segments.
+func splitPointer(pointer string) []string {
+	pointer = strings.TrimPrefix(pointer, "/")
+	if pointer == "" {
+		return nil
+	}
+	segments := strings.Split(pointer, "/")
+	for i, s := range segments {
+		segments[i] = unescapePointer.Replace(s)
+	}
+	return segments
+}
+
+// joinPointer joins segments to an escaped JSON pointer.
+func joinPointer(segments []string) string {
+	var b strings.Builder
+	for _, s := range segments {
+		b.WriteByte('/')
+		b.WriteString(escapePointer.Replace(s))
+	}
+	return b.String()
+}
+
+// matchPath checks if a path matches a pattern.
+// "*" matches one segment and "**" any number of segments.
+func matchPath(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case "**":
+			for i := len(path); i >= 0; i-- {
+				if matchPath(pattern[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		case "*":
+			if len(path) == 0 {
+				return false
+			}
+		default:
+			if len(path) == 0 || path[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
+
+// publisher returns the name of the publisher of a document.
+func publisher(document any) string {
+	doc, _ := document.(map[string]any)
+	d, _ := doc["document"].(map[string]any)
+	p, _ := d["publisher"].(map[string]any)
+	name, _ := p["name"].(string)
+	return name
+}
+
+// Apply applies the rules to the string fields of the document in place
+// and returns the changes. The rules of a publisher are selected by the
+// publisher name of the document before any rule is applied.
+func (t *Transformer) Apply(document any) []Change {
+	if t == nil {
+		return nil
+	}
+	name := publisher(document)
+	rules := slices.DeleteFunc(slices.Clone(t.rules), func(r rule) bool {
+		return r.publisher != "" && r.publisher != name
+	})
+	if len(rules) == 0 {
+		return nil
+	}
+	var (
+		changes []Change
+		path    []string
+	)
+	// replace applies the rules to a string value.
+	replace := func(value string, category string) (string, bool) {
+		changed := false
+		for i := range rules {
+			r := &rules[i]
+			if (r.category != "" && r.category != category) || !matchPath(r.path, path) {
+				continue
+			}
+			if replaced := r.pattern.ReplaceAllString(value, r.replacement); replaced != value {
+				changes = append(changes, Change{
+					Rule:     r.name,
+					Path:     joinPointer(path),
+					Original: value,
+					Replaced: replaced,
+				})
+				value, changed = replaced, true
+			}
+		}
+		return value, changed
+	}
+	var walk func(v any, category string) (any, bool)
+	walk = func(v any, category string) (any, bool) {
+		switch x := v.(type) {
+		case string:
+			return replace(x, category)
+		case []any:
+			for i, e := range x {
+				path = append(path, strconv.Itoa(i))
+				if y, ok := walk(e, ""); ok {
+					x[i] = y
+				}
+				path = path[:len(path)-1]
+			}
+		case map[string]any:
+			// Walk in key order to get stable changes.
+			keys := make([]string, 0, len(x))
+			for k := range x {
+				keys = append(keys, k)
+			}
+			slices.Sort(keys)
+			cat, _ := x["category"].(string)
+			for _, k := range keys {
+				path = append(path, k)
+				if y, ok := walk(x[k], cat); ok {
+					x[k] = y
+				}
+				path = path[:len(path)-1]
+			}
+		}
+		return v, false
+	}
+	walk(document, "")
+	return changes
+}
+
+// Transform applies the rules to the document and checks if the
+// changed document is still a valid CSAF document.
+func (t *Transformer) Transform(document any) ([]Change, error) {
+	changes := t.Apply(document)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	msgs, err := csaf.ValidateCSAF(document)
+	if err != nil {
+		return nil, fmt.Errorf("schema validation of transformed document failed: %w", err)
+	}
+	if len(msgs) > 0 {
+		return nil, errors.New("transformed document is not valid: " + strings.Join(msgs, ", "))
+	}
+	return changes, nil
+}
+
+// Replay applies recorded changes to the document in place.
+// The changes have to be in the order they were recorded.
+// It fails if a field does not hold the original value of its change.
+func Replay(document any, changes []Change) error {
+	for i := range changes {
+		c := &changes[i]
+		segments := splitPointer(c.Path)
+		if len(segments) == 0 {
+			return fmt.Errorf("invalid path %q", c.Path)
+		}
+		parent := document
+		for _, s := range segments[:len(segments)-1] {
+			var ok bool
+			if parent, ok = child(parent, s); !ok {
+				return fmt.Errorf("path %q not found", c.Path)
+			}
+		}
+		last := segments[len(segments)-1]
+		if value, _ := child(parent, last); value != c.Original {
+			return fmt.Errorf("field %q does not hold the original value", c.Path)
+		}
+		switch x := parent.(type) {
+		case map[string]any:
+			x[last] = c.Replaced
+		case []any:
+			idx, _ := strconv.Atoi(last)
+			x[idx] = c.Replaced
+		}
+	}
+	return nil
+}
+
+// child returns the element of an object or array with the given key.
+func child(v any, key string) (any, bool) {
+	switch x := v.(type) {
+	case map[string]any:
+		e, ok := x[key]
+		return e, ok
+	case []any:
+		idx, err := strconv.Atoi(key)
+		if err != nil || idx < 0 || idx >= len(x) {
+			return nil, false
+		}
+		return x[idx], true
+	}
+	return nil, false
+}
+
+// Store returns a function to store the provenance of
+// the changes along side the document.
+func Store(changes []Change) models.DocumentStoreChainFunc {
+	return func(ctx context.Context, tx pgx.Tx, docID int64, duplicate bool) error {
+		if duplicate || len(changes) == 0 {
+			return nil
+		}
+		const insertSQL = `INSERT INTO document_transformations ` +
+			`(documents_id, num, rule, path, original, replaced) ` +
+			`VALUES ($1, $2, $3, $4, $5, $6)`
+		batch := &pgx.Batch{}
+		for i := range changes {
+			c := &changes[i]
+			batch.Queue(insertSQL, docID, i, c.Rule, c.Path, c.Original, c.Replaced)
+		}
+		return tx.SendBatch(ctx, batch).Close()
+	}
+}
@@ -0,0 +1,174 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package transform
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+)
+
+func TestMatchPath(t *testing.T) {
+	for _, x := range []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"/document/publisher/namespace", "/document/publisher/namespace", true},
+		{"/document/publisher/namespace", "/document/publisher/name", false},
+		{"/document/publisher", "/document/publisher/namespace", false},
+		{"/product_tree/branches/*/name", "/product_tree/branches/0/name", true},
+		{"/product_tree/branches/*/name", "/product_tree/branches/0/branches/1/name", false},
+		{"/product_tree/**/name", "/product_tree/branches/0/branches/1/name", true},
+		{"/product_tree/**/name", "/product_tree/name", true},
+		{"/**", "/document/title", true},
+		{"/a~1b/c", "/a~1b/c", true},
+	} {
+		if got := matchPath(splitPointer(x.pattern), splitPointer(x.path)); got != x.match {
+			t.Errorf("matchPath(%q, %q): got %t, want %t", x.pattern, x.path, got, x.match)
+		}
+	}
+}
+
+func TestPointer(t *testing.T) {
+	segments := []string{"a/b", "c~d", "0"}
+	pointer := joinPointer(segments)
+	if pointer != "/a~1b/c~0d/0" {
+		t.Fatalf("unexpected pointer %q", pointer)
+	}
+	if got := splitPointer(pointer); !reflect.DeepEqual(got, segments) {
+		t.Errorf("got %q, want %q", got, segments)
+	}
+}
+
+func TestApply(t *testing.T) {
+	const input = `{
+  "document": {
+    "publisher": {"name": "ACME", "namespace": "acme.example"}
+  },
+  "product_tree": {
+    "branches": [{
+      "category": "vendor",
+      "name": "v1 Vendor",
+      "branches": [
+        {"category": "product_version", "name": "v1.2"},
+        {"category": "product_name", "name": "v2"}
+      ]
+    }]
+  }
+}`
+	var document any
+	if err := json.Unmarshal([]byte(input), &document); err != nil {
+		t.Fatal(err)
+	}
+	tf, err := NewTransformer(&config.Transformations{Rules: []config.TransformationRule{{
+		Name:        "namespace",
+		Publisher:   "ACME",
+		Path:        "/document/publisher/namespace",
+		Pattern:     `^([^:/]+)$`,
+		Replacement: "https://$1",
+	}, {
+		Name:        "versions",
+		Path:        "/product_tree/**/name",
+		Category:    "product_version",
+		Pattern:     `^v(\d)`,
+		Replacement: "$1",
+	}, {
+		Name:        "other",
+		Publisher:   "Other",
+		Path:        "/**",
+		Pattern:     `.*`,
+		Replacement: "x",
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := tf.Apply(document)
+	want := []Change{{
+		Rule:     "namespace",
+		Path:     "/document/publisher/namespace",
+		Original: "acme.example",
+		Replaced: "https://acme.example",
+	}, {
+		Rule:     "versions",
+		Path:     "/product_tree/branches/0/branches/0/name",
+		Original: "v1.2",
+		Replaced: "1.2",
+	}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("got %+v, want %+v", changes, want)
+	}
+	doc := document.(map[string]any)
+	ns := doc["document"].(map[string]any)["publisher"].(map[string]any)["namespace"]
+	if ns != "https://acme.example" {
+		t.Errorf("namespace not replaced: %v", ns)
+	}
+	vendor := doc["product_tree"].(map[string]any)["branches"].([]any)[0].(map[string]any)
+	if vendor["name"] != "v1 Vendor" {
+		t.Errorf("vendor name changed: %v", vendor["name"])
+	}
+	if name := vendor["branches"].([]any)[1].(map[string]any)["name"]; name != "v2" {
+		t.Errorf("product name changed: %v", name)
+	}
+}
+
+func TestNilTransformer(t *testing.T) {
+	tf, err := NewTransformer(&config.Transformations{})
+	if err != nil || tf != nil {
+		t.Fatalf("expected nil transformer: %v", err)
+	}
+	if changes := tf.Apply(map[string]any{"a": "b"}); changes != nil {
+		t.Errorf("nil transformer changed document: %v", changes)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	const input = `{
+  "document": {"publisher": {"name": "ACME", "namespace": "http://acme.example"}},
+  "product_tree": {"branches": [{"name": "Vendor GmbH", "category": "vendor"}]}
+}`
+	var doc any
+	if err := json.Unmarshal([]byte(input), &doc); err != nil {
+		t.Fatal(err)
+	}
+	changes := []Change{
+		{Rule: "https", Path: "/document/publisher/namespace",
+			Original: "http://acme.example", Replaced: "https://acme.example"},
+		{Rule: "vendor", Path: "/product_tree/branches/0/name",
+			Original: "Vendor GmbH", Replaced: "Vendor"},
+		{Rule: "lower", Path: "/product_tree/branches/0/name",
+			Original: "Vendor", Replaced: "vendor"},
+	}
+	if err := Replay(doc, changes); err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	var want any
+	if err := json.Unmarshal([]byte(`{
+  "document": {"publisher": {"name": "ACME", "namespace": "https://acme.example"}},
+  "product_tree": {"branches": [{"name": "vendor", "category": "vendor"}]}
+}`), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("got %v, want %v", doc, want)
+	}
+
+	for _, c := range []Change{
+		{Path: "/document/publisher/namespace", Original: "http://acme.example"},
+		{Path: "/product_tree/branches/1/name", Original: "Vendor"},
+		{Path: "/document/title", Original: "Title"},
+		{Path: "", Original: "x"},
+	} {
+		if err := Replay(doc, []Change{c}); err == nil {
+			t.Errorf("replay of %q: expected error", c.Path)
+		}
+	}
+}
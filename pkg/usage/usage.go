@@ -0,0 +1,191 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package usage records the API calls and the transferred data per user.
+// The counters are accumulated in memory and periodically added
+// to the daily totals in the database.
+package usage
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+	"github.com/ISDuBA/ISDuBA/pkg/database"
+	"github.com/ISDuBA/ISDuBA/pkg/scheduler"
+)
+
+// Recorder records the API usage. A nil recorder is valid and records nothing.
+type Recorder struct {
+	cfg      *config.APIUsage
+	db       *database.DB
+	task     *scheduler.Task
+	mu       sync.Mutex
+	counters map[key]*counts
+}
+
+type key struct {
+	day      time.Time
+	user     string
+	method   string
+	endpoint string
+}
+
+type counts struct {
+	requests int64
+	bytesIn  int64
+	bytesOut int64
+}
+
+// NewRecorder returns a new recorder. If recording is
+// not enabled in the configuration nil is returned.
+func NewRecorder(cfg *config.APIUsage, db *database.DB, tasks *scheduler.Registry) *Recorder {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &Recorder{
+		cfg:      cfg,
+		db:       db,
+		counters: map[key]*counts{},
+		task: tasks.Register("api_usage_flush",
+			"Stores the recorded API usage and removes outdated usage data.",
+			cfg.FlushInterval),
+	}
+}
+
+// Record counts an API call of a user.
+func (r *Recorder) Record(user, method, endpoint string, bytesIn, bytesOut int64) {
+	if r == nil {
+		return
+	}
+	k := key{
+		day:      time.Now().UTC().Truncate(24 * time.Hour),
+		user:     user,
+		method:   method,
+		endpoint: endpoint,
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := r.counters[k]
+	if c == nil {
+		c = new(counts)
+		r.counters[k] = c
+	}
+	c.requests++
+	c.bytesIn += max(bytesIn, 0)
+	c.bytesOut += max(bytesOut, 0)
+}
+
+// Run periodically stores the recorded usage. To be used in a Go routine.
+func (r *Recorder) Run(ctx context.Context) {
+	if r == nil {
+		return
+	}
+	ticker := time.NewTicker(r.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// Don't lose the counters of the last interval.
+			sctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			r.flush(sctx)
+			cancel()
+			return
+		case <-ticker.C:
+			if !r.task.Paused() {
+				r.scheduledFlush(ctx)
+			}
+		case <-r.task.Triggered():
+			r.scheduledFlush(ctx)
+		}
+	}
+}
+
+// scheduledFlush flushes and records the run in the scheduler task.
+func (r *Recorder) scheduledFlush(ctx context.Context) {
+	done := r.task.Start()
+	err := r.flush(ctx)
+	if err == nil {
+		err = r.expire(ctx)
+	}
+	done(err)
+}
+
+// flush adds the recorded counters to the totals in the database.
+// If this fails the counters are kept for the next try.
+func (r *Recorder) flush(ctx context.Context) error {
+	r.mu.Lock()
+	counters := r.counters
+	r.counters = map[key]*counts{}
+	r.mu.Unlock()
+	if len(counters) == 0 {
+		return nil
+	}
+	const upsertSQL = `INSERT INTO api_usage ` +
+		`(day, "user", method, endpoint, requests, bytes_in, bytes_out) ` +
+		`VALUES ($1, $2, $3, $4, $5, $6, $7) ` +
+		`ON CONFLICT (day, "user", method, endpoint) DO UPDATE SET ` +
+		`requests = api_usage.requests + EXCLUDED.requests, ` +
+		`bytes_in = api_usage.bytes_in + EXCLUDED.bytes_in, ` +
+		`bytes_out = api_usage.bytes_out + EXCLUDED.bytes_out`
+	err := r.db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+		batch := &pgx.Batch{}
+		for k, c := range counters {
+			batch.Queue(upsertSQL,
+				k.day, k.user, k.method, k.endpoint,
+				c.requests, c.bytesIn, c.bytesOut)
+		}
+		tx, err := conn.Begin(rctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(rctx)
+		if err := tx.SendBatch(rctx, batch).Close(); err != nil {
+			return err
+		}
+		return tx.Commit(rctx)
+	}, 0)
+	if err != nil {
+		slog.Warn("storing API usage failed", "error", err)
+		r.restore(counters)
+	}
+	return err
+}
+
+// restore merges counters which could not be stored back.
+func (r *Recorder) restore(counters map[key]*counts) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, c := range counters {
+		if o := r.counters[k]; o != nil {
+			o.requests += c.requests
+			o.bytesIn += c.bytesIn
+			o.bytesOut += c.bytesOut
+		} else {
+			r.counters[k] = c
+		}
+	}
+}
+
+// expire removes the usage data older than the retention period.
+func (r *Recorder) expire(ctx context.Context) error {
+	if r.cfg.Retention <= 0 {
+		return nil
+	}
+	const deleteSQL = `DELETE FROM api_usage WHERE day < $1`
+	before := time.Now().UTC().Add(-r.cfg.Retention).Truncate(24 * time.Hour)
+	return r.db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+		_, err := conn.Exec(rctx, deleteSQL, before)
+		return err
+	}, 0)
+}
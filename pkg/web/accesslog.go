@@ -0,0 +1,218 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// accessLogDefaultInterval is the default time range of the access log export.
+const accessLogDefaultInterval = 30 * 24 * time.Hour
+
+// The kinds of recorded document accesses.
+const (
+	downloadAccess = "download"
+	shareAccess    = "share"
+	exportAccess   = "export"
+)
+
+type documentAccess struct {
+	ID         int64     `json:"id"`
+	Time       time.Time `json:"time"`
+	Actor      *string   `json:"actor,omitempty"`
+	Kind       string    `json:"kind"`
+	DocumentID *int64    `json:"document_id,omitempty"`
+	Publisher  string    `json:"publisher"`
+	TrackingID string    `json:"tracking_id"`
+	Version    string    `json:"version"`
+	TLP        string    `json:"tlp"`
+}
+
+// accessLogExporters write the document accesses in the different export formats.
+var accessLogExporters = map[string]struct {
+	contentType string
+	write       func(io.Writer, iter.Seq2[*documentAccess, error]) error
+}{
+	"csv":    {"text/csv; charset=utf-8", writeAccessLogCSV},
+	"ndjson": {"application/x-ndjson", writeAccessLogNDJSON},
+}
+
+// logDocumentAccessSQL records the access of a document with a
+// restricted TLP label. The actor is NULL if the document was accessed
+// via a shared link. Parameters: actor, kind, document id.
+const logDocumentAccessSQL = `INSERT INTO document_accesses ` +
+	`(actor, kind, documents_id, publisher, tracking_id, version, tlp) ` +
+	`SELECT $1, $2, docs.id, ads.publisher, ads.tracking_id, docs.version, docs.tlp ` +
+	`FROM documents docs JOIN advisories ads ON docs.advisories_id = ads.id ` +
+	`WHERE docs.id = $3`
+
+func writeAccessLogCSV(w io.Writer, accesses iter.Seq2[*documentAccess, error]) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{
+		"time", "actor", "kind", "document_id",
+		"publisher", "tracking_id", "version", "tlp",
+	}); err != nil {
+		return err
+	}
+	optString := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	optInt := func(i *int64) string {
+		if i == nil {
+			return ""
+		}
+		return strconv.FormatInt(*i, 10)
+	}
+	for access, err := range accesses {
+		if err != nil {
+			return err
+		}
+		if err := out.Write([]string{
+			access.Time.Format(time.RFC3339Nano),
+			optString(access.Actor),
+			access.Kind,
+			optInt(access.DocumentID),
+			access.Publisher,
+			access.TrackingID,
+			access.Version,
+			access.TLP,
+		}); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func writeAccessLogNDJSON(w io.Writer, accesses iter.Seq2[*documentAccess, error]) error {
+	enc := json.NewEncoder(w)
+	for access, err := range accesses {
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(access); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportAccessLog is an endpoint that exports the recorded accesses
+// of documents with restricted TLP labels.
+//
+//	@Summary		Exports the document access log.
+//	@Description	Exports who downloaded documents with TLP labels other than
+//	@Description	WHITE or CLEAR as CSV or NDJSON. The oldest entries come first.
+//	@Param			format		query	string	false	"csv or ndjson, defaults to csv"
+//	@Param			from		query	string	false	"Timerange start"
+//	@Param			to			query	string	false	"Timerange end"
+//	@Param			actor		query	string	false	"User"
+//	@Param			document	query	int		false	"Document ID"
+//	@Produce		text/csv
+//	@Produce		application/x-ndjson
+//	@Success		200	{string}	string
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		403
+//	@Failure		500	{object}	models.Error
+//	@Router			/accesses/export [get]
+func (c *Controller) exportAccessLog(ctx *gin.Context) {
+	format := strings.ToLower(ctx.DefaultQuery("format", "csv"))
+	exporter, found := accessLogExporters[format]
+	if !found {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "format has to be csv or ndjson")
+		return
+	}
+	from, to, _, ok := importStatsInterval(ctx, accessLogDefaultInterval)
+	if !ok {
+		return
+	}
+	var (
+		conds  = []string{"time BETWEEN $1 AND $2"}
+		values = []any{from, to}
+	)
+	if actor := ctx.Query("actor"); actor != "" {
+		values = append(values, actor)
+		conds = append(conds, fmt.Sprintf("actor = $%d", len(values)))
+	}
+	if value := ctx.Query("document"); value != "" {
+		docID, ok := parse(ctx, toInt64, value)
+		if !ok {
+			return
+		}
+		values = append(values, docID)
+		conds = append(conds, fmt.Sprintf("documents_id = $%d", len(values)))
+	}
+	selectSQL := `SELECT id, time, actor, kind, documents_id, ` +
+		`publisher, tracking_id, version, tlp ` +
+		`FROM document_accesses WHERE ` + strings.Join(conds, " AND ") +
+		` ORDER BY time, id`
+
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, err := conn.Query(rctx, selectSQL, values...)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			ctx.Header("Content-Type", exporter.contentType)
+			ctx.Header("Content-Disposition",
+				fmt.Sprintf("attachment; filename=\"access-log.%s\"", format))
+			ctx.Status(http.StatusOK)
+			accesses := func(yield func(*documentAccess, error) bool) {
+				var access documentAccess
+				for rows.Next() {
+					if err := rows.Scan(
+						&access.ID, &access.Time, &access.Actor, &access.Kind,
+						&access.DocumentID,
+						&access.Publisher, &access.TrackingID, &access.Version,
+						&access.TLP,
+					); err != nil {
+						yield(nil, err)
+						return
+					}
+					access.Time = access.Time.UTC()
+					if !yield(&access, nil) {
+						return
+					}
+				}
+				if err := rows.Err(); err != nil {
+					yield(nil, err)
+				}
+			}
+			if err := exporter.write(ctx.Writer, accesses); err != nil {
+				// The header is already sent, so only log the error.
+				slog.ErrorContext(ctx, "exporting access log failed", "error", err)
+			}
+			return nil
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "error", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/ISDuBA/ISDuBA/pkg/eventbus"
 	"github.com/ISDuBA/ISDuBA/pkg/models"
 )
 
@@ -26,7 +27,7 @@ type advisoryStates []models.AdvisoryState
 
 func (c *Controller) changeStatusAll(ctx *gin.Context, inputs advisoryStates) {
 	const (
-		findAdvisory = `SELECT docs.id, state::text, tlp ` +
+		findAdvisory = `SELECT ads.id, docs.id, state::text, critical ` +
 			`FROM advisories ads ` +
 			`JOIN documents docs ON ads.id = docs.advisories_id ` +
 			`WHERE ads.publisher = $1 AND ads.tracking_id = $2 ` +
@@ -34,12 +35,20 @@ func (c *Controller) changeStatusAll(ctx *gin.Context, inputs advisoryStates) {
 		updateState = `UPDATE advisories SET state = $1::workflow WHERE (tracking_id, publisher) = ($2, $3)`
 		insertLog   = `INSERT INTO events_log (event, state, actor, documents_id) ` +
 			`VALUES ('state_change', $1::workflow, $2, $3)`
+		requestApproval = `INSERT INTO state_approvals ` +
+			`(advisories_id, documents_id, from_state, to_state, requester) ` +
+			`VALUES ($1, $2, $3::workflow, $4::workflow, $5) ` +
+			`ON CONFLICT (advisories_id) WHERE status = 'pending' DO NOTHING`
+		requestLog = `INSERT INTO events_log (event, state, actor, documents_id) ` +
+			`VALUES ('request_state_change', $1::workflow, $2, $3)`
 	)
 
 	actor := c.currentUser(ctx)
-	tlps := c.tlps(ctx)
 
-	var forbidden, noTransition, bad bool
+	var (
+		forbidden, noTransition, bad, pending bool
+		evs                                   []eventbus.Event
+	)
 
 	if err := c.db.Run(
 		ctx.Request.Context(),
@@ -53,9 +62,10 @@ func (c *Controller) changeStatusAll(ctx *gin.Context, inputs advisoryStates) {
 			for i := range inputs {
 				var (
 					input      = &inputs[i]
+					advisoryID int64
 					documentID int64
 					current    string
-					tlp        string
+					critical   *float64
 				)
 
 				if input.Publisher == "" || input.TrackingID == "" {
@@ -63,24 +73,18 @@ func (c *Controller) changeStatusAll(ctx *gin.Context, inputs advisoryStates) {
 					return nil
 				}
 
-				slog.Debug("state change",
+				slog.DebugContext(ctx, "state change",
 					"publisher", input.Publisher,
 					"tracking_id", input.TrackingID,
 					"state", input.State)
 
 				if err := tx.QueryRow(rctx, findAdvisory, input.Publisher, input.TrackingID).Scan(
-					&documentID, &current, &tlp,
+					&advisoryID, &documentID, &current, &critical,
 				); err != nil {
 					return err
 				}
 
-				// Check if we are allowed to access it.
-				if len(tlps) > 0 && !tlps.Allowed(input.Publisher, models.TLP(tlp)) {
-					forbidden = true
-					return nil
-				}
-
-				slog.Debug("current state", "state", current)
+				slog.DebugContext(ctx, "current state", "state", current)
 
 				// Check if the transition is allowed to user.
 				roles := models.Workflow(current).TransitionsRoles(input.State)
@@ -93,6 +97,24 @@ func (c *Controller) changeStatusAll(ctx *gin.Context, inputs advisoryStates) {
 					return nil
 				}
 
+				// Some transitions need the approval of a second user.
+				if c.cfg.Workflow.RequiresApproval(input.State, critical) {
+					tag, err := tx.Exec(rctx, requestApproval,
+						advisoryID, documentID, current, string(input.State), ctx.GetString("uid"))
+					if err != nil {
+						return err
+					}
+					if tag.RowsAffected() > 0 {
+						if _, err := tx.Exec(rctx, requestLog, string(input.State), actor, documentID); err != nil {
+							return err
+						}
+						evs = append(evs, c.documentEvent(
+							ctx, models.RequestStateChangeEvent, documentID, input.State))
+					}
+					pending = true
+					continue
+				}
+
 				// At this point the state change can be done.
 				if _, err := tx.Exec(rctx, updateState,
 					string(input.State), input.TrackingID, input.Publisher,
@@ -104,15 +126,20 @@ func (c *Controller) changeStatusAll(ctx *gin.Context, inputs advisoryStates) {
 				if _, err := tx.Exec(rctx, insertLog, string(input.State), actor, documentID); err != nil {
 					return err
 				}
+				evs = append(evs, c.documentEvent(
+					ctx, models.StateChangeEvent, documentID, input.State))
 			}
 
 			return tx.Commit(rctx)
 		}, 0,
 	); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
 			models.SendErrorMessage(ctx, http.StatusNotFound, "advisory not found")
-		} else {
-			slog.Error("state change failed", "err", err)
+		case errors.Is(err, models.ErrLegalHold):
+			models.SendErrorMessage(ctx, http.StatusConflict, "advisory is under legal hold")
+		default:
+			slog.ErrorContext(ctx, "state change failed", "err", err)
 			models.SendError(ctx, http.StatusInternalServerError, err)
 		}
 		return
@@ -124,7 +151,11 @@ func (c *Controller) changeStatusAll(ctx *gin.Context, inputs advisoryStates) {
 		models.SendErrorMessage(ctx, http.StatusForbidden, "access denied")
 	case noTransition:
 		models.SendErrorMessage(ctx, http.StatusBadRequest, "state transition not possible")
+	case pending:
+		c.publishEvents(evs)
+		models.SendSuccess(ctx, http.StatusAccepted, "transition waits for approval")
 	default:
+		c.publishEvents(evs)
 		models.SendSuccess(ctx, http.StatusOK, "transition done")
 	}
 }
@@ -138,6 +169,7 @@ func (c *Controller) changeStatusAll(ctx *gin.Context, inputs advisoryStates) {
 //	@Param			state		path	string	true	"Advisory status"
 //	@Produce		json
 //	@Success		200	{object}	models.Success
+//	@Success		202	{object}	models.Success	"transition waits for approval"
 //	@Failure		400	{object}	models.Error
 //	@Failure		401
 //	@Failure		403	{object}	models.Error
@@ -161,6 +193,7 @@ func (c *Controller) changeStatus(ctx *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //	@Success		200	{object}	models.Success
+//	@Success		202	{object}	models.Success	"transition waits for approval"
 //	@Failure		400	{object}	models.Error
 //	@Failure		401
 //	@Failure		403	{object}	models.Error
@@ -186,8 +219,8 @@ func (c *Controller) changeStatusBulk(ctx *gin.Context) {
 //	@Success		200	{array}		web.viewEvents.event
 //	@Failure		400	{object}	models.Error
 //	@Failure		401
-//	@Failure		403	{object}	models.Error
 //	@Failure		404	{object}	models.Error
+//	@Failure		409	{object}	models.Error	"under legal hold"
 //	@Failure		500	{object}	models.Error
 //	@Router			/advisory/{publisher}/{trackingid} [delete]
 func (c *Controller) deleteAdvisory(ctx *gin.Context) {
@@ -203,17 +236,20 @@ func (c *Controller) deleteAdvisory(ctx *gin.Context) {
 	}
 
 	const (
-		tlpSQL = `SELECT tlp ` +
+		latestSQL = `SELECT docs.id ` +
 			`FROM advisories ads ` +
 			`JOIN documents docs ON ads.id = docs.advisories_id ` +
 			`WHERE ads.publisher = $1 AND ads.tracking_id = $2 ` +
 			`AND latest`
+		idsSQL = `SELECT id FROM documents WHERE ` +
+			`advisories_id = (` +
+			`SELECT id FROM advisories WHERE publisher = $1 AND tracking_id = $2)`
 		deleteSQL = `DELETE FROM documents WHERE ` +
 			`advisories_id = (` +
 			`SELECT id FROM advisories WHERE publisher = $1 AND tracking_id = $2)`
 	)
 
-	var forbidden, deleted bool
+	var deleted bool
 
 	if err := c.db.Run(
 		ctx.Request.Context(),
@@ -224,13 +260,19 @@ func (c *Controller) deleteAdvisory(ctx *gin.Context) {
 			}
 			defer tx.Rollback(rctx)
 
-			var tlp string
-			if err := tx.QueryRow(rctx, tlpSQL, key.Publisher, key.TrackingID).Scan(&tlp); err != nil {
-				return fmt.Errorf("finding latest tlp failed: %w", err)
+			// The latest document has to be visible to the user.
+			var latest int64
+			if err := tx.QueryRow(rctx, latestSQL, key.Publisher, key.TrackingID).Scan(&latest); err != nil {
+				return fmt.Errorf("finding latest document failed: %w", err)
+			}
+
+			rows, _ := tx.Query(rctx, idsSQL, key.Publisher, key.TrackingID)
+			ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+			if err != nil {
+				return fmt.Errorf("selecting advisory documents failed: %w", err)
 			}
-			if tlps := c.tlps(ctx); !tlps.Allowed(key.Publisher, models.TLP(tlp)) {
-				forbidden = true
-				return nil
+			if err := models.CheckLegalHolds(rctx, tx, ids); err != nil {
+				return err
 			}
 
 			tags, err := tx.Exec(rctx, deleteSQL, key.Publisher, key.TrackingID)
@@ -254,14 +296,12 @@ func (c *Controller) deleteAdvisory(ctx *gin.Context) {
 		if errors.Is(err, pgx.ErrNoRows) {
 			models.SendErrorMessage(ctx, http.StatusNotFound, "advisory not found")
 		} else {
-			slog.Error("deleting advisory failed", "err", err)
+			slog.ErrorContext(ctx, "deleting advisory failed", "err", err)
 			models.SendError(ctx, http.StatusInternalServerError, err)
 		}
 		return
 	}
 	switch {
-	case forbidden:
-		models.SendErrorMessage(ctx, http.StatusForbidden, "not allowed to delete advisory")
 	case !deleted:
 		models.SendErrorMessage(ctx, http.StatusNotFound, "advisory not found")
 	default:
@@ -51,7 +51,7 @@ type argumentedAggregator struct {
 //	@Router			/aggregator [get]
 func (c *Controller) aggregatorProxy(ctx *gin.Context) {
 	url := ctx.Query("url")
-	ca, err := c.am.Cache.GetAggregator(url, c.cfg)
+	ca, err := c.am.Cache.GetAggregator(ctx.Request.Context(), url, c.cfg)
 	if err != nil {
 		models.SendError(ctx, http.StatusBadRequest, err)
 		return
@@ -71,7 +71,7 @@ func (c *Controller) aggregatorProxy(ctx *gin.Context) {
 			return conn.QueryRow(rctx, sql, url).Scan(&id, &name, &attention)
 		}, 0,
 	); err != nil && !errors.Is(err, pgx.ErrNoRows) {
-		slog.Error("fetching aggregator failed", "err", err)
+		slog.ErrorContext(ctx, "fetching aggregator failed", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -124,7 +124,7 @@ func (c *Controller) viewAggregators(ctx *gin.Context) {
 			return err
 		}, 0,
 	); err != nil {
-		slog.Error("fetching aggregators failed", "error", err)
+		slog.ErrorContext(ctx, "fetching aggregators failed", "error", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -168,11 +168,11 @@ func (c *Controller) viewAggregator(ctx *gin.Context) {
 		models.SendErrorMessage(ctx, http.StatusNotFound, "not found")
 		return
 	case err != nil:
-		slog.Error("fetching aggregator failed", "err", err)
+		slog.ErrorContext(ctx, "fetching aggregator failed", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
-	ca, err := c.am.Cache.GetAggregator(url, c.cfg)
+	ca, err := c.am.Cache.GetAggregator(ctx.Request.Context(), url, c.cfg)
 	if err != nil {
 		models.SendError(ctx, http.StatusBadRequest, err)
 		return
@@ -239,7 +239,7 @@ func (c *Controller) createAggregator(ctx *gin.Context) {
 			models.SendErrorMessage(ctx, http.StatusBadRequest,
 				fmt.Sprintf("not a unique value: %v", err.Error()))
 		} else {
-			slog.Error("inserting aggregator failed", "error", err)
+			slog.ErrorContext(ctx, "inserting aggregator failed", "error", err)
 			models.SendError(ctx, http.StatusInternalServerError, err)
 		}
 		return
@@ -274,7 +274,7 @@ func (c *Controller) deleteAggregator(ctx *gin.Context) {
 			return err
 		}, 0,
 	); err != nil {
-		slog.Error("delete aggregator failed", "error", err)
+		slog.ErrorContext(ctx, "delete aggregator failed", "error", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -307,7 +307,73 @@ func (c *Controller) attentionAggregators(ctx *gin.Context) {
 			return err
 		}, 0,
 	); err != nil {
-		slog.Error("fetching aggregator failed", "error", err)
+		slog.ErrorContext(ctx, "fetching aggregator failed", "error", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, list)
+}
+
+// aggregatorsStats is an endpoint that returns statistics about fetching the aggregators.
+//
+//	@Summary		Returns statistics about fetching the aggregators.
+//	@Description	Returns the configured workers and rate limit, the state of
+//	@Description	the aggregator cache and the outcome of the last refresh.
+//	@Produce		json
+//	@Success		200	{object}	aggregators.Stats
+//	@Failure		401
+//	@Router			/aggregators/stats [get]
+func (c *Controller) aggregatorsStats(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.am.Stats())
+}
+
+// acknowledgeAttentionAggregators acknowledges the attention flags of aggregators in bulk.
+//
+//	@Summary		Acknowledges the attention flags of aggregators.
+//	@Description	Resets the attention flags of all aggregators that need attention
+//	@Description	and records who acknowledged them. If ids are given only these
+//	@Description	aggregators are acknowledged.
+//	@Param			ids	formData	[]int	false	"Aggregator IDs"	collectionFormat(multi)
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Success		200	{array}		web.acknowledgeAttentionAggregators.acknowledged
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/aggregators/attention/ack [post]
+func (c *Controller) acknowledgeAttentionAggregators(ctx *gin.Context) {
+	const sql = `WITH acked AS (` +
+		`UPDATE aggregators SET checksum_ack = checksum_updated ` +
+		`WHERE checksum_ack < checksum_updated ` +
+		`AND ($1::int[] IS NULL OR id = ANY($1)) ` +
+		`RETURNING id, name, checksum_updated), ` +
+		`audit AS (` +
+		`INSERT INTO attention_acks (actor, kind, aggregators_id, name, changed) ` +
+		`SELECT $2, 'aggregator', id, name, checksum_updated FROM acked) ` +
+		`SELECT id, name FROM acked ORDER BY name`
+	ids, ok := parseIDs(ctx, "ids")
+	if !ok {
+		return
+	}
+	type acknowledged struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+	list := []acknowledged{}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, sql, ids, ctx.GetString("uid"))
+			var err error
+			list, err = pgx.AppendRows(list, rows, func(row pgx.CollectableRow) (acknowledged, error) {
+				var ack acknowledged
+				err := row.Scan(&ack.ID, &ack.Name)
+				return ack, err
+			})
+			return err
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "acknowledging aggregator attention failed", "error", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -393,7 +459,7 @@ func (c *Controller) updateAggregator(ctx *gin.Context) {
 	var changed bool
 
 	updateSQL := prefix + strings.Join(fields, ",") + suffix
-	slog.Debug("update aggregators", "sql", updateSQL, "values", values)
+	slog.DebugContext(ctx, "update aggregators", "sql", updateSQL, "values", values)
 
 	if err := c.db.Run(
 		ctx.Request.Context(),
@@ -412,7 +478,7 @@ func (c *Controller) updateAggregator(ctx *gin.Context) {
 			models.SendError(ctx, http.StatusBadRequest, err)
 			return
 		}
-		slog.Error("updating aggregator failed", "error", err)
+		slog.ErrorContext(ctx, "updating aggregator failed", "error", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
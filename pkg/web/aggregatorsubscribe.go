@@ -0,0 +1,138 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/sources"
+)
+
+// aggregatorSubscription selects the publishers of an aggregator to subscribe.
+type aggregatorSubscription struct {
+	// Publishers are the URLs of the PMDs or the namespaces of the publishers.
+	Publishers []string `json:"publishers" binding:"required,min=1"`
+	// Mirrors subscribes the mirrors of the aggregator if available.
+	Mirrors bool `json:"mirrors"`
+	DryRun  bool `json:"dry_run"`
+}
+
+// subscribeAggregator is an endpoint that creates sources for
+// publishers listed by an aggregator.
+//
+//	@Summary		Creates sources for publishers listed by an aggregator.
+//	@Description	Reads the cached aggregator and creates a source with all feeds
+//	@Description	of its PMD for every selected publisher. The publishers are selected
+//	@Description	by the URLs of their PMDs or their namespaces. The sources are named
+//	@Description	after the publishers. If mirrors is true the first mirror of the
+//	@Description	aggregator is subscribed instead of the PMD of the publisher.
+//	@Description	Publishers not listed, already subscribed or with names in use
+//	@Description	are reported as errors.
+//	@Param			id				path	int						true	"Aggregator ID"
+//	@Param			subscription	body	aggregatorSubscription	true	"Selected publishers"
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{array}		importedSource
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/aggregators/{id}/subscribe [post]
+func (c *Controller) subscribeAggregator(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	var input aggregatorSubscription
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	var url string
+	const sql = `SELECT url FROM aggregators WHERE id = $1`
+	switch err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			return conn.QueryRow(rctx, sql, id).Scan(&url)
+		}, 0,
+	); {
+	case errors.Is(err, pgx.ErrNoRows):
+		models.SendErrorMessage(ctx, http.StatusNotFound, "not found")
+		return
+	case err != nil:
+		slog.ErrorContext(ctx, "fetching aggregator failed", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ca, err := c.am.Cache.GetAggregator(ctx.Request.Context(), url, c.cfg)
+	if err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	listings := ca.Listings(url)
+
+	results := make([]importedSource, 0, len(input.Publishers))
+	for _, selected := range input.Publishers {
+		results = append(results, c.subscribeListing(ctx.Request.Context(), listings, selected, input.Mirrors, input.DryRun))
+	}
+	ctx.JSON(http.StatusOK, results)
+}
+
+// subscribeListing creates a source for the publisher selected
+// by the URL of its PMD or its namespace.
+func (c *Controller) subscribeListing(
+	ctx context.Context,
+	listings []sources.AggregatorListing,
+	selected string,
+	mirrors, dryRun bool,
+) importedSource {
+	fail := func(format string, args ...any) importedSource {
+		msg := fmt.Sprintf(format, args...)
+		return importedSource{URL: selected, Error: &msg}
+	}
+	idx := slices.IndexFunc(listings, func(al sources.AggregatorListing) bool {
+		if al.URL == selected {
+			return true
+		}
+		p := al.Publisher
+		return p != nil && p.Namespace != nil && *p.Namespace == selected
+	})
+	if idx < 0 {
+		return fail("%q is not listed by the aggregator", selected)
+	}
+	listing := &listings[idx]
+	pmdURL := listing.URL
+	if mirrors && len(listing.Mirrors) > 0 {
+		pmdURL = listing.Mirrors[0]
+	}
+	name := pmdURL
+	if p := listing.Publisher; p != nil && p.Name != nil && *p.Name != "" {
+		name = *p.Name
+	}
+	for _, sub := range c.sm.Subscriptions([]string{pmdURL}) {
+		if len(sub.Subscriptions) > 0 {
+			return fail("%q is already subscribed by source %q",
+				pmdURL, sub.Subscriptions[0].Name)
+		}
+	}
+	return c.importSource(ctx, &sources.ImportedSource{
+		Name: name,
+		URL:  pmdURL,
+	}, dryRun)
+}
@@ -0,0 +1,253 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/database/query"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// maxAnnotationLength is the maximal length of an annotation message.
+const maxAnnotationLength = 10_000
+
+type annotation struct {
+	ID      int64     `json:"id"`
+	Pointer string    `json:"pointer"`
+	Time    time.Time `json:"time"`
+	Author  string    `json:"author"`
+	Message string    `json:"message"`
+}
+
+// viewAnnotations is an endpoint that returns the annotations of a document.
+//
+//	@Summary		Returns the annotations of a document.
+//	@Description	Returns the annotations of the specified document. Each annotation
+//	@Description	is anchored to a part of the document by a JSON pointer (RFC 6901).
+//	@Param			id	path	int	true	"Document ID"
+//	@Produce		json
+//	@Success		200	{array}		annotation
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/documents/{id}/annotations [get]
+func (c *Controller) viewAnnotations(ctx *gin.Context) {
+	docID, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	expr := query.FieldEqInt("id", docID)
+	builder := query.SQLBuilder{}
+	builder.CreateWhere(expr)
+
+	var (
+		exists      bool
+		annotations = []annotation{}
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			existsSQL := `SELECT EXISTS(` +
+				`SELECT FROM documents JOIN advisories ON documents.advisories_id = advisories.id WHERE ` +
+				builder.WhereClause + `)`
+			if err := conn.QueryRow(
+				rctx, existsSQL, builder.Replacements...).Scan(&exists); err != nil || !exists {
+				return err
+			}
+			const fetchSQL = `SELECT id, pointer, time, author, message ` +
+				`FROM annotations WHERE documents_id = $1 ORDER BY time, id`
+			rows, _ := conn.Query(rctx, fetchSQL, docID)
+			var err error
+			annotations, err = pgx.CollectRows(rows,
+				func(row pgx.CollectableRow) (annotation, error) {
+					var a annotation
+					err := row.Scan(&a.ID, &a.Pointer, &a.Time, &a.Author, &a.Message)
+					a.Time = a.Time.UTC()
+					return a, err
+				})
+			return err
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if !exists {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "document not found")
+		return
+	}
+	if annotations == nil {
+		annotations = []annotation{}
+	}
+	ctx.JSON(http.StatusOK, annotations)
+}
+
+// createAnnotation is an endpoint that creates an annotation.
+//
+//	@Summary		Creates an annotation.
+//	@Description	Creates an annotation anchored to the part of the specified
+//	@Description	document addressed by the JSON pointer (RFC 6901). The pointer
+//	@Description	has to exist in the document. Annotations can be created in
+//	@Description	the same workflow states as comments.
+//	@Param			id		path		int		true	"Document ID"
+//	@Param			pointer	formData	string	true	"JSON pointer"
+//	@Param			message	formData	string	true	"Annotation message"
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Success		201	{object}	annotation
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/documents/{id}/annotations [post]
+func (c *Controller) createAnnotation(ctx *gin.Context) {
+	docID, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	pointer, _ := ctx.GetPostForm("pointer")
+	path, ok := parse(ctx, models.ParseJSONPointer, pointer)
+	if !ok {
+		return
+	}
+	message, _ := ctx.GetPostForm("message")
+	switch {
+	case message == "":
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "missing message")
+		return
+	case len(message) > maxAnnotationLength:
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "message too long")
+		return
+	}
+
+	expr := query.FieldEqInt("id", docID)
+	builder := query.SQLBuilder{}
+	builder.CreateWhere(expr)
+
+	var (
+		exists            bool
+		commentingAllowed bool
+		found             bool
+		result            = annotation{
+			Pointer: pointer,
+			Time:    time.Now().UTC(),
+			Author:  ctx.GetString("uid"),
+			Message: message,
+		}
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			n := len(builder.Replacements)
+			stateSQL := `SELECT state, document #> $` + strconv.Itoa(n+1) + `::text[] IS NOT NULL ` +
+				`FROM documents JOIN advisories ` +
+				`ON documents.advisories_id = advisories.id ` +
+				`WHERE ` + builder.WhereClause
+			var stateS string
+			if err := conn.QueryRow(
+				rctx, stateSQL, append(builder.Replacements, path)...,
+			).Scan(&stateS, &found); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return nil
+				}
+				return err
+			}
+			exists = true
+			if commentingAllowed = c.isCommentingAllowed(
+				ctx, models.Workflow(stateS)); !commentingAllowed || !found {
+				return nil
+			}
+			const insertSQL = `INSERT INTO annotations ` +
+				`(documents_id, pointer, time, author, message) ` +
+				`VALUES ($1, $2, $3, $4, $5) ` +
+				`RETURNING id`
+			return conn.QueryRow(rctx, insertSQL,
+				docID, result.Pointer, result.Time, result.Author, result.Message,
+			).Scan(&result.ID)
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	switch {
+	case !exists:
+		models.SendErrorMessage(ctx, http.StatusNotFound, "document not found")
+	case !commentingAllowed:
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "invalid state to annotate")
+	case !found:
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "pointer not found in document")
+	default:
+		ctx.JSON(http.StatusCreated, &result)
+	}
+}
+
+// deleteAnnotation is an endpoint that deletes an annotation.
+//
+//	@Summary		Deletes an annotation.
+//	@Description	Deletes the annotation with the specified ID.
+//	@Description	Only the author and administrators can delete an annotation.
+//	@Param			id	path	int	true	"Annotation ID"
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/annotations/{id} [delete]
+func (c *Controller) deleteAnnotation(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	expr := query.FieldEqInt("annotations.id", id)
+	builder := query.SQLBuilder{}
+	builder.CreateWhere(expr)
+
+	var (
+		deleted bool
+		n       = len(builder.Replacements)
+		values  = append(builder.Replacements,
+			ctx.GetString("uid"), c.hasAnyRole(ctx, models.Admin))
+	)
+	deleteSQL := `DELETE FROM annotations USING documents, advisories ` +
+		`WHERE annotations.documents_id = documents.id ` +
+		`AND documents.advisories_id = advisories.id ` +
+		`AND (annotations.author = $` + strconv.Itoa(n+1) + ` OR $` + strconv.Itoa(n+2) + `) ` +
+		`AND ` + builder.WhereClause
+
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tags, err := conn.Exec(rctx, deleteSQL, values...)
+			deleted = err == nil && tags.RowsAffected() > 0
+			return err
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if !deleted {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "annotation not found")
+		return
+	}
+	models.SendSuccess(ctx, http.StatusOK, "annotation deleted")
+}
@@ -0,0 +1,126 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// apiVersion is a version of the response shapes of the API.
+type apiVersion int
+
+const (
+	// apiV1 is the version served below the unversioned /api, too.
+	apiV1 apiVersion = 1
+	// apiV2 is the version which receives the reworked responses.
+	apiV2 apiVersion = 2
+)
+
+// String implements [fmt.Stringer].
+func (v apiVersion) String() string {
+	return "v" + strconv.Itoa(int(v))
+}
+
+// apiVersionLifecycle is the deprecation of an API version.
+type apiVersionLifecycle struct {
+	// deprecated is the point in time the version is deprecated.
+	deprecated time.Time
+	// sunset is the point in time the version will be removed.
+	sunset time.Time
+	// successor is the version to migrate to.
+	successor apiVersion
+}
+
+// apiVersions are the supported API versions.
+// Deprecated versions have a lifecycle.
+var apiVersions = map[apiVersion]*apiVersionLifecycle{
+	apiV1: {
+		deprecated: time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC),
+		sunset:     time.Date(2027, time.November, 1, 0, 0, 0, 0, time.UTC),
+		successor:  apiV2,
+	},
+	apiV2: nil,
+}
+
+// apiVersionKey is the key of the API version in the request context.
+type apiVersionKey struct{}
+
+// apiVersionFrom returns the API version of a request.
+// Handlers use it to keep the response shapes of the older versions.
+func apiVersionFrom(ctx context.Context) apiVersion {
+	if v, ok := ctx.Value(apiVersionKey{}).(apiVersion); ok {
+		return v
+	}
+	return apiV1
+}
+
+// splitAPIVersion splits the version from a path below the API prefix.
+// If the path has no version found is false.
+func splitAPIVersion(rest string) (version apiVersion, path string, found bool) {
+	segment, path, _ := strings.Cut(rest, "/")
+	if len(segment) < 2 || segment[0] != 'v' {
+		return 0, rest, false
+	}
+	n, err := strconv.Atoi(segment[1:])
+	if err != nil || n < 1 || strconv.Itoa(n) != segment[1:] {
+		return 0, rest, false
+	}
+	return apiVersion(n), path, true
+}
+
+// versioned serves the versioned API paths like /api/v2/documents
+// by the handler of the unversioned path. The requested version is
+// stored in the request context. The responses of deprecated versions
+// carry the Deprecation, Sunset and Link headers of RFC 9745 and RFC 8594.
+func (c *Controller) versioned(next http.Handler) http.Handler {
+	prefix := c.cfg.Web.BasePath + "/api/"
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest, ok := strings.CutPrefix(r.URL.Path, prefix)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		version, path, found := splitAPIVersion(rest)
+		if !found {
+			version = apiV1
+		} else {
+			if _, supported := apiVersions[version]; !supported {
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(models.Error{
+					Error: fmt.Sprintf("unsupported API version %s", version),
+					Code:  http.StatusNotFound,
+				})
+				return
+			}
+			// Route the request like the unversioned one.
+			r2 := r.Clone(r.Context())
+			r2.URL.Path = prefix + path
+			r2.URL.RawPath = ""
+			r = r2
+		}
+		if lifecycle := apiVersions[version]; lifecycle != nil {
+			h := w.Header()
+			h.Set("Deprecation", "@"+strconv.FormatInt(lifecycle.deprecated.Unix(), 10))
+			h.Set("Sunset", lifecycle.sunset.Format(http.TimeFormat))
+			h.Set("Link", fmt.Sprintf("<%s%s/%s>; rel=\"successor-version\"",
+				prefix, lifecycle.successor, path))
+		}
+		next.ServeHTTP(w, r.WithContext(
+			context.WithValue(r.Context(), apiVersionKey{}, version)))
+	})
+}
@@ -0,0 +1,276 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/eventbus"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+type stateApproval struct {
+	ID         int64           `json:"id"`
+	Publisher  string          `json:"publisher"`
+	TrackingID string          `json:"tracking_id"`
+	DocumentID *int64          `json:"document_id,omitempty"`
+	FromState  models.Workflow `json:"from_state"`
+	ToState    models.Workflow `json:"to_state"`
+	Requester  string          `json:"requester"`
+	Requested  time.Time       `json:"requested"`
+	Approver   *string         `json:"approver,omitempty"`
+	Decided    *time.Time      `json:"decided,omitempty"`
+	Status     string          `json:"status"`
+}
+
+// viewApprovals is an endpoint that returns the state changes waiting for approval.
+//
+//	@Summary		Returns state change approvals.
+//	@Description	Returns the state changes which need the approval of a second user.
+//	@Param			all	query	bool	false	"Include already decided approvals"
+//	@Produce		json
+//	@Success		200	{array}		stateApproval
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/approvals [get]
+func (c *Controller) viewApprovals(ctx *gin.Context) {
+	all, ok := parse(ctx, strconv.ParseBool, ctx.DefaultQuery("all", "false"))
+	if !ok {
+		return
+	}
+	// The join with the latest documents hides the approvals
+	// of advisories the user is not allowed to see.
+	const selectSQL = `SELECT sa.id, ads.publisher, ads.tracking_id, sa.documents_id, ` +
+		`sa.from_state::text, sa.to_state::text, sa.requester, sa.requested, ` +
+		`sa.approver, sa.decided, sa.status::text ` +
+		`FROM state_approvals sa ` +
+		`JOIN advisories ads ON sa.advisories_id = ads.id ` +
+		`JOIN documents docs ON docs.advisories_id = ads.id AND docs.latest ` +
+		`WHERE $1 OR sa.status = 'pending' ` +
+		`ORDER BY sa.requested DESC`
+
+	approvals := []stateApproval{}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, selectSQL, all)
+			defer rows.Close()
+			for rows.Next() {
+				var (
+					sa        stateApproval
+					from, to  string
+					requested time.Time
+				)
+				if err := rows.Scan(
+					&sa.ID, &sa.Publisher, &sa.TrackingID, &sa.DocumentID,
+					&from, &to, &sa.Requester, &requested,
+					&sa.Approver, &sa.Decided, &sa.Status,
+				); err != nil {
+					return err
+				}
+				sa.FromState, sa.ToState = models.Workflow(from), models.Workflow(to)
+				sa.Requested = requested.UTC()
+				approvals = append(approvals, sa)
+			}
+			return rows.Err()
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, approvals)
+}
+
+// approveStateChange is an endpoint that approves a pending state change.
+//
+//	@Summary		Approves a state change.
+//	@Description	Approves the pending state change and performs it.
+//	@Description	The approver has to be a different user than the requester.
+//	@Param			id	path	int	true	"Approval ID"
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		403	{object}	models.Error
+//	@Failure		404	{object}	models.Error
+//	@Failure		409	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/approvals/{id}/approve [put]
+func (c *Controller) approveStateChange(ctx *gin.Context) {
+	c.decideStateChange(ctx, true)
+}
+
+// rejectStateChange is an endpoint that rejects a pending state change.
+//
+//	@Summary		Rejects a state change.
+//	@Description	Rejects the pending state change. The requester is allowed
+//	@Description	to withdraw the request this way.
+//	@Param			id	path	int	true	"Approval ID"
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		403	{object}	models.Error
+//	@Failure		404	{object}	models.Error
+//	@Failure		409	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/approvals/{id}/reject [put]
+func (c *Controller) rejectStateChange(ctx *gin.Context) {
+	c.decideStateChange(ctx, false)
+}
+
+func (c *Controller) decideStateChange(ctx *gin.Context, approve bool) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+
+	const (
+		findSQL = `SELECT sa.advisories_id, sa.documents_id, ` +
+			`sa.from_state::text, sa.to_state::text, sa.requester, sa.status::text, ` +
+			`ads.state::text ` +
+			`FROM state_approvals sa ` +
+			`JOIN advisories ads ON sa.advisories_id = ads.id ` +
+			`JOIN documents docs ON docs.advisories_id = ads.id AND docs.latest ` +
+			`WHERE sa.id = $1 ` +
+			`FOR UPDATE OF sa`
+		decideSQL = `UPDATE state_approvals ` +
+			`SET (status, approver, decided) = ($1::approval_status, $2, $3) ` +
+			`WHERE id = $4`
+		updateState = `UPDATE advisories SET state = $1::workflow WHERE id = $2`
+		insertLog   = `INSERT INTO events_log (event, state, time, actor, documents_id) ` +
+			`VALUES ($1::events, $2::workflow, $3, $4, $5)`
+	)
+
+	var (
+		actor    = c.currentUser(ctx)
+		user     = ctx.GetString("uid")
+		now      = time.Now().UTC()
+		notFound bool
+		conflict string
+		denied   string
+		evs      []eventbus.Event
+	)
+
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tx, err := conn.BeginTx(rctx, pgx.TxOptions{})
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(rctx)
+
+			var (
+				advisoryID          int64
+				documentID          *int64
+				from, to, requester string
+				status, current     string
+			)
+			switch err := tx.QueryRow(rctx, findSQL, id).Scan(
+				&advisoryID, &documentID,
+				&from, &to, &requester, &status,
+				&current,
+			); {
+			case errors.Is(err, pgx.ErrNoRows):
+				notFound = true
+				return nil
+			case err != nil:
+				return err
+			}
+			if status != "pending" {
+				conflict = "already " + status
+				return nil
+			}
+
+			fromState, toState := models.Workflow(from), models.Workflow(to)
+			roles := fromState.TransitionsRoles(toState)
+
+			var event models.Event
+			if approve {
+				if requester == user {
+					denied = "requester is not allowed to approve"
+					return nil
+				}
+				if !c.hasAnyRole(ctx, roles...) {
+					denied = "access denied"
+					return nil
+				}
+				if models.Workflow(current) != fromState {
+					conflict = "advisory state changed in the meantime"
+					return nil
+				}
+				if _, err := tx.Exec(rctx, updateState, to, advisoryID); err != nil {
+					return err
+				}
+				event = models.ApproveStateChangeEvent
+			} else {
+				if requester != user && !c.hasAnyRole(ctx, roles...) {
+					denied = "access denied"
+					return nil
+				}
+				event = models.RejectStateChangeEvent
+			}
+
+			newStatus := "rejected"
+			if approve {
+				newStatus = "approved"
+			}
+			if _, err := tx.Exec(rctx, decideSQL, newStatus, user, now, id); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(rctx, insertLog, string(event), to, now, actor, documentID); err != nil {
+				return err
+			}
+			if documentID != nil {
+				evs = append(evs, c.documentEvent(ctx, event, *documentID, toState))
+			}
+			if approve {
+				if _, err := tx.Exec(
+					rctx, insertLog, string(models.StateChangeEvent), to, now, actor, documentID,
+				); err != nil {
+					return err
+				}
+				if documentID != nil {
+					evs = append(evs, c.documentEvent(ctx, models.StateChangeEvent, *documentID, toState))
+				}
+			}
+			return tx.Commit(rctx)
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	switch {
+	case notFound:
+		models.SendErrorMessage(ctx, http.StatusNotFound, "approval not found")
+	case conflict != "":
+		models.SendErrorMessage(ctx, http.StatusConflict, conflict)
+	case denied != "":
+		models.SendErrorMessage(ctx, http.StatusForbidden, denied)
+	case approve:
+		c.publishEvents(evs)
+		models.SendSuccess(ctx, http.StatusOK, "transition done")
+	default:
+		c.publishEvents(evs)
+		models.SendSuccess(ctx, http.StatusOK, "transition rejected")
+	}
+}
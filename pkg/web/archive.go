@@ -0,0 +1,406 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/database/query"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// archiveDocument is the record of a document in an archive.
+type archiveDocument struct {
+	ID             int64                  `json:"id"`
+	Publisher      string                 `json:"publisher"`
+	TrackingID     string                 `json:"tracking_id"`
+	Version        string                 `json:"version"`
+	Title          *string                `json:"title,omitempty"`
+	TLP            *string                `json:"tlp,omitempty"`
+	State          string                 `json:"state"`
+	SSVC           *string                `json:"ssvc,omitempty"`
+	Comments       []archiveComment       `json:"comments"`
+	Annotations    []annotation           `json:"annotations"`
+	SSVCHistory    []archiveSSVCChange    `json:"ssvc_history"`
+	StateApprovals []archiveStateApproval `json:"state_approvals"`
+	Events         []archiveEvent         `json:"events"`
+}
+
+type archiveComment struct {
+	ID          int64     `json:"id"`
+	Time        time.Time `json:"time"`
+	Commentator string    `json:"commentator"`
+	Message     *string   `json:"message,omitempty"`
+}
+
+type archiveSSVCChange struct {
+	ChangeNumber int64     `json:"change_number"`
+	Time         time.Time `json:"time"`
+	Actor        *string   `json:"actor,omitempty"`
+	SSVC         *string   `json:"ssvc,omitempty"`
+	Reason       *string   `json:"reason,omitempty"`
+}
+
+type archiveStateApproval struct {
+	From      string     `json:"from"`
+	To        string     `json:"to"`
+	Requester string     `json:"requester"`
+	Requested time.Time  `json:"requested"`
+	Approver  *string    `json:"approver,omitempty"`
+	Decided   *time.Time `json:"decided,omitempty"`
+	Status    string     `json:"status"`
+}
+
+type archiveEvent struct {
+	Event     string    `json:"event"`
+	State     *string   `json:"state,omitempty"`
+	Time      time.Time `json:"time"`
+	Actor     *string   `json:"actor,omitempty"`
+	CommentID *int64    `json:"comment_id,omitempty"`
+}
+
+// archiveFile is an entry of the manifest of an archive.
+type archiveFile struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+}
+
+// archiveManifest describes the content of an archive.
+// It is signed to make the archive tamper-evident.
+type archiveManifest struct {
+	Created   time.Time     `json:"created"`
+	Creator   string        `json:"creator"`
+	Query     string        `json:"query"`
+	Documents int           `json:"documents"`
+	Files     []archiveFile `json:"files"`
+}
+
+// archiveSigningKey loads the configured key to sign the archives.
+func (c *Controller) archiveSigningKey() (*crypto.KeyRing, error) {
+	cfg := &c.cfg.Archive
+	if cfg.SigningKey == "" {
+		return nil, errors.New("no signing key configured")
+	}
+	f, err := os.Open(cfg.SigningKey)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	key, err := crypto.NewKeyFromArmoredReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("loading signing key failed: %w", err)
+	}
+	if locked, err := key.IsLocked(); err != nil {
+		return nil, err
+	} else if locked {
+		if key, err = key.Unlock([]byte(cfg.Passphrase)); err != nil {
+			return nil, fmt.Errorf("unlocking signing key failed: %w", err)
+		}
+	}
+	return crypto.NewKeyRing(key)
+}
+
+// loadArchiveDocument loads the workflow data of a document.
+func loadArchiveDocument(rctx context.Context, tx pgx.Tx, id int64) (*archiveDocument, error) {
+	const (
+		documentSQL = `SELECT docs.id, ads.publisher, ads.tracking_id, docs.version, ` +
+			`docs.title, docs.tlp, ads.state::text, docs.ssvc ` +
+			`FROM documents docs JOIN advisories ads ON docs.advisories_id = ads.id ` +
+			`WHERE docs.id = $1`
+		commentsSQL = `SELECT id, time, commentator, message FROM comments ` +
+			`WHERE documents_id = $1 ORDER BY time, id`
+		annotationsSQL = `SELECT id, pointer, time, author, message FROM annotations ` +
+			`WHERE documents_id = $1 ORDER BY time, id`
+		ssvcSQL = `SELECT change_number, changedate, actor, ssvc, reason FROM ssvc_history ` +
+			`WHERE documents_id = $1 ORDER BY change_number`
+		approvalsSQL = `SELECT from_state::text, to_state::text, requester, requested, ` +
+			`approver, decided, status::text FROM state_approvals ` +
+			`WHERE documents_id = $1 ORDER BY requested, id`
+		eventsSQL = `SELECT event::text, state::text, time, actor, comments_id FROM events_log ` +
+			`WHERE documents_id = $1 ORDER BY time`
+	)
+	var doc archiveDocument
+	if err := tx.QueryRow(rctx, documentSQL, id).Scan(
+		&doc.ID, &doc.Publisher, &doc.TrackingID, &doc.Version,
+		&doc.Title, &doc.TLP, &doc.State, &doc.SSVC,
+	); err != nil {
+		return nil, err
+	}
+	var err error
+	if doc.Comments, err = collect(rctx, tx, commentsSQL, id,
+		func(row pgx.CollectableRow) (archiveComment, error) {
+			var c archiveComment
+			err := row.Scan(&c.ID, &c.Time, &c.Commentator, &c.Message)
+			c.Time = c.Time.UTC()
+			return c, err
+		}); err != nil {
+		return nil, err
+	}
+	if doc.Annotations, err = collect(rctx, tx, annotationsSQL, id,
+		func(row pgx.CollectableRow) (annotation, error) {
+			var a annotation
+			err := row.Scan(&a.ID, &a.Pointer, &a.Time, &a.Author, &a.Message)
+			a.Time = a.Time.UTC()
+			return a, err
+		}); err != nil {
+		return nil, err
+	}
+	if doc.SSVCHistory, err = collect(rctx, tx, ssvcSQL, id,
+		func(row pgx.CollectableRow) (archiveSSVCChange, error) {
+			var s archiveSSVCChange
+			err := row.Scan(&s.ChangeNumber, &s.Time, &s.Actor, &s.SSVC, &s.Reason)
+			s.Time = s.Time.UTC()
+			return s, err
+		}); err != nil {
+		return nil, err
+	}
+	if doc.StateApprovals, err = collect(rctx, tx, approvalsSQL, id,
+		func(row pgx.CollectableRow) (archiveStateApproval, error) {
+			var a archiveStateApproval
+			err := row.Scan(&a.From, &a.To, &a.Requester, &a.Requested,
+				&a.Approver, &a.Decided, &a.Status)
+			a.Requested = a.Requested.UTC()
+			if a.Decided != nil {
+				*a.Decided = a.Decided.UTC()
+			}
+			return a, err
+		}); err != nil {
+		return nil, err
+	}
+	if doc.Events, err = collect(rctx, tx, eventsSQL, id,
+		func(row pgx.CollectableRow) (archiveEvent, error) {
+			var e archiveEvent
+			err := row.Scan(&e.Event, &e.State, &e.Time, &e.Actor, &e.CommentID)
+			e.Time = e.Time.UTC()
+			return e, err
+		}); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// collect runs a query with the given id and collects the rows.
+// The result is never nil to be encoded as an empty JSON array.
+func collect[T any](
+	rctx context.Context,
+	tx pgx.Tx,
+	sql string,
+	id int64,
+	fn pgx.RowToFunc[T],
+) ([]T, error) {
+	rows, _ := tx.Query(rctx, sql, id)
+	items, err := pgx.CollectRows(rows, fn)
+	if items == nil {
+		items = []T{}
+	}
+	return items, err
+}
+
+// archiveWriter writes files into a zip archive and
+// records their checksums for the manifest.
+type archiveWriter struct {
+	zip   *zip.Writer
+	files []archiveFile
+}
+
+func (aw *archiveWriter) write(name string, modified time.Time, data []byte) error {
+	w, err := aw.zip.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	aw.files = append(aw.files, archiveFile{
+		Name:   name,
+		SHA256: hex.EncodeToString(sum[:]),
+	})
+	return nil
+}
+
+// exportArchive is an endpoint that exports the assessments of documents
+// as a signed archive.
+//
+//	@Summary		Exports the assessments of documents as a signed archive.
+//	@Description	Exports the comments, the annotations, the SSVC history,
+//	@Description	the state approvals and the events of the documents selected by
+//	@Description	the query together with the original documents as a ZIP archive
+//	@Description	for long-term records management. The archive contains a manifest
+//	@Description	with the SHA256 checksums of all files and a detached OpenPGP
+//	@Description	signature of the manifest. The export of documents with
+//	@Description	restricted TLP labels is recorded in the access log.
+//	@Param			query	query	string	false	"Query to select the documents"
+//	@Produce		application/zip
+//	@Success		200	{file}		binary
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		403
+//	@Failure		500	{object}	models.Error
+//	@Router			/documents/archive [get]
+func (c *Controller) exportArchive(ctx *gin.Context) {
+	keyRing, err := c.archiveSigningKey()
+	if err != nil {
+		slog.ErrorContext(ctx, "cannot sign archive", "err", err)
+		models.SendErrorMessage(ctx, http.StatusInternalServerError, "archive signing not configured")
+		return
+	}
+	defer keyRing.ClearPrivateParams()
+
+	parser := query.Parser{
+		Mode:            query.DocumentMode,
+		MinSearchLength: MinSearchLength,
+		Me:              ctx.GetString("uid"),
+	}
+	queryString := ctx.DefaultQuery("query", "true")
+	expr, ok := parse(ctx, parser.Parse, queryString)
+	if !ok {
+		return
+	}
+	builder, err := query.NewAdvancedSQLBuilder(
+		query.AdvancedSQLBuilderExpr(expr),
+		query.AdvancedSQLBuilderOrderFields([]string{"id"}),
+		query.AdvancedSQLBuilderFields([]string{"id"}),
+		query.AdvancedSQLBuilderParser(&parser))
+	if err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	idsSQL := builder.CreateQuery(-1, -1)
+
+	manifest := archiveManifest{
+		Created: time.Now().UTC(),
+		Creator: ctx.GetString("uid"),
+		Query:   queryString,
+	}
+
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			// All data of the archive have to be from the same snapshot.
+			// The exports of restricted documents are recorded in it.
+			tx, err := conn.BeginTx(rctx, pgx.TxOptions{
+				IsoLevel: pgx.RepeatableRead,
+			})
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(rctx)
+
+			rows, _ := tx.Query(rctx, idsSQL, builder.Replacements...)
+			ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+			if err != nil {
+				return err
+			}
+
+			ctx.Header("Content-Type", "application/zip")
+			ctx.Header("Content-Disposition", fmt.Sprintf(
+				"attachment; filename=\"archive-%s.zip\"",
+				manifest.Created.Format("20060102T150405Z")))
+			ctx.Status(http.StatusOK)
+
+			aw := archiveWriter{zip: zip.NewWriter(ctx.Writer)}
+			for _, id := range ids {
+				doc, err := loadArchiveDocument(rctx, tx, id)
+				if err != nil {
+					return err
+				}
+				if doc.TLP != nil && models.TLP(*doc.TLP).Restricted() {
+					if _, err := tx.Exec(rctx, logDocumentAccessSQL,
+						manifest.Creator, exportAccess, id,
+					); err != nil {
+						return err
+					}
+				}
+				record, err := json.MarshalIndent(doc, "", "  ")
+				if err != nil {
+					return err
+				}
+				dir := fmt.Sprintf("documents/%d/", id)
+				if err := aw.write(dir+"record.json", manifest.Created, record); err != nil {
+					return err
+				}
+				var (
+					original []byte
+					key      *string
+				)
+				if err := tx.QueryRow(rctx,
+					`SELECT original, original_key FROM documents WHERE id = $1`, id,
+				).Scan(&original, &key); err != nil {
+					return err
+				}
+				if original, err = c.bs.Original(rctx, original, key); err != nil {
+					return err
+				}
+				if err := aw.write(dir+"csaf.json", manifest.Created, original); err != nil {
+					return err
+				}
+			}
+			manifest.Documents = len(ids)
+			manifest.Files = aw.files
+			data, err := json.MarshalIndent(&manifest, "", "  ")
+			if err != nil {
+				return err
+			}
+			sig, err := keyRing.SignDetached(crypto.NewPlainMessage(data))
+			if err != nil {
+				return err
+			}
+			armored, err := sig.GetArmored()
+			if err != nil {
+				return err
+			}
+			if err := aw.write("manifest.json", manifest.Created, data); err != nil {
+				return err
+			}
+			w, err := aw.zip.Create("manifest.json.asc")
+			if err != nil {
+				return err
+			}
+			if _, err := io.WriteString(w, armored); err != nil {
+				return err
+			}
+			// The archive is not complete without the recorded exports.
+			if err := tx.Commit(rctx); err != nil {
+				return err
+			}
+			return aw.zip.Close()
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "exporting archive failed", "err", err)
+		if !ctx.Writer.Written() {
+			models.SendError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	slog.InfoContext(ctx, "archive exported",
+		"user", manifest.Creator,
+		"query", manifest.Query,
+		"documents", manifest.Documents)
+}
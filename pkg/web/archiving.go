@@ -0,0 +1,247 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/archiving"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+const (
+	// defaultArchivingLimit is the page size if no limit is given.
+	defaultArchivingLimit = 100
+	// maxArchivingLimit is the largest page size.
+	maxArchivingLimit = 1000
+)
+
+// viewArchivingPreview is an endpoint that returns what the archiving policies would do.
+//
+//	@Summary		Returns the advisories the archiving policies apply to.
+//	@Description	Returns the advisories which the archiving policies would archive
+//	@Description	or delete now, the longest in the state first. Legal holds are only
+//	@Description	checked when the policies are applied.
+//	@Param			policy	query	string	false	"Name of the policy"
+//	@Param			limit	query	int		false	"Maximum number of advisories per policy"
+//	@Produce		json
+//	@Success		200	{array}		archiving.Candidate
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/archiving/preview [get]
+func (c *Controller) viewArchivingPreview(ctx *gin.Context) {
+	limit := int64(defaultArchivingLimit)
+	if lim := ctx.Query("limit"); lim != "" {
+		var ok bool
+		if limit, ok = parse(ctx, toInt64, lim); !ok {
+			return
+		}
+		if limit < 1 || limit > maxArchivingLimit {
+			models.SendErrorMessage(ctx, http.StatusBadRequest,
+				fmt.Sprintf("limit has to be between 1 and %d", maxArchivingLimit))
+			return
+		}
+	}
+	name := ctx.Query("policy")
+	candidates := []archiving.Candidate{}
+	var found bool
+	for i := range c.cfg.Archiving.Policies {
+		p := &c.cfg.Archiving.Policies[i]
+		if name != "" && p.Name != name {
+			continue
+		}
+		found = true
+		cs, err := archiving.Candidates(ctx.Request.Context(), c.db, p, false, int(limit))
+		if err != nil {
+			slog.ErrorContext(ctx, "database error", "err", err)
+			models.SendError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		candidates = append(candidates, cs...)
+	}
+	if name != "" && !found {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "policy not found")
+		return
+	}
+	ctx.JSON(http.StatusOK, candidates)
+}
+
+// archivingLogEntry is a recorded application of an archiving policy.
+type archivingLogEntry struct {
+	ID         int64           `json:"id"`
+	Time       time.Time       `json:"time"`
+	Policy     string          `json:"policy"`
+	Action     string          `json:"action"`
+	DryRun     bool            `json:"dry_run"`
+	Outcome    string          `json:"outcome"`
+	Publisher  string          `json:"publisher"`
+	TrackingID string          `json:"tracking_id"`
+	State      models.Workflow `json:"state"`
+	Entered    time.Time       `json:"entered"`
+	Error      *string         `json:"error,omitempty"`
+}
+
+// viewArchivingLog is an endpoint that returns the archiving log.
+//
+//	@Summary		Returns the archiving log.
+//	@Description	Returns what the archiving policies did with the advisories, newest first.
+//	@Description	Dry runs record what the policies would have done.
+//	@Param			policy		query	string	false	"Name of the policy"
+//	@Param			outcome		query	string	false	"done, held, failed or pending"
+//	@Param			dry_run		query	bool	false	"Only dry runs or real runs"
+//	@Param			from		query	string	false	"Timerange start"
+//	@Param			to			query	string	false	"Timerange end"
+//	@Param			limit		query	int		false	"Maximum number of entries"
+//	@Param			offset		query	int		false	"Number of entries to skip"
+//	@Param			count		query	bool	false	"Also return the number of matching entries"
+//	@Produce		json
+//	@Success		200	{object}	web.viewArchivingLog.archivingLog
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/archiving/log [get]
+func (c *Controller) viewArchivingLog(ctx *gin.Context) {
+	var (
+		values []any
+		conds  []string
+		ok     bool
+	)
+	add := func(cond string, value any) {
+		values = append(values, value)
+		conds = append(conds, strings.ReplaceAll(cond, "$?", "$"+strconv.Itoa(len(values))))
+	}
+
+	if policy := ctx.Query("policy"); policy != "" {
+		add(`policy = $?`, policy)
+	}
+	if outcome := ctx.Query("outcome"); outcome != "" {
+		switch outcome {
+		case "done", "held", "failed", "pending":
+		default:
+			models.SendErrorMessage(ctx, http.StatusBadRequest,
+				fmt.Sprintf("unknown outcome %q", outcome))
+			return
+		}
+		add(`outcome = $?::archiving_outcome`, outcome)
+	}
+	if value := ctx.Query("dry_run"); value != "" {
+		dryRun, ok := parse(ctx, strconv.ParseBool, value)
+		if !ok {
+			return
+		}
+		add(`dry_run = $?`, dryRun)
+	}
+	if from := ctx.Query("from"); from != "" {
+		t, ok := parse(ctx, parseTime, from)
+		if !ok {
+			return
+		}
+		add(`time >= $?`, t)
+	}
+	if to := ctx.Query("to"); to != "" {
+		t, ok := parse(ctx, parseTime, to)
+		if !ok {
+			return
+		}
+		add(`time <= $?`, t)
+	}
+
+	var limit, offset int64 = defaultArchivingLimit, 0
+	if lim := ctx.Query("limit"); lim != "" {
+		if limit, ok = parse(ctx, toInt64, lim); !ok {
+			return
+		}
+		if limit < 1 || limit > maxArchivingLimit {
+			models.SendErrorMessage(ctx, http.StatusBadRequest,
+				fmt.Sprintf("limit has to be between 1 and %d", maxArchivingLimit))
+			return
+		}
+	}
+	if ofs := ctx.Query("offset"); ofs != "" {
+		if offset, ok = parse(ctx, toInt64, ofs); !ok {
+			return
+		}
+		if offset < 0 {
+			models.SendErrorMessage(ctx, http.StatusBadRequest, "offset must not be negative")
+			return
+		}
+	}
+	calcCount := ctx.Query("count") != ""
+
+	var where string
+	if len(conds) > 0 {
+		where = `WHERE (` + strings.Join(conds, `) AND (`) + `) `
+	}
+	countSQL := `SELECT count(*) FROM archiving_log ` + where
+	fetchSQL := `SELECT id, time, policy, action, dry_run, outcome::text, ` +
+		`publisher, tracking_id, state::text, entered, error ` +
+		`FROM archiving_log ` + where +
+		`ORDER BY time DESC, id DESC` +
+		` LIMIT ` + strconv.FormatInt(limit, 10) +
+		` OFFSET ` + strconv.FormatInt(offset, 10)
+
+	var (
+		entries []archivingLogEntry
+		count   int64
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			if calcCount {
+				if err := conn.QueryRow(rctx, countSQL, values...).Scan(&count); err != nil {
+					return fmt.Errorf("cannot calculate count %w", err)
+				}
+			}
+			rows, _ := conn.Query(rctx, fetchSQL, values...)
+			var err error
+			entries, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (archivingLogEntry, error) {
+				var (
+					e     archivingLogEntry
+					state string
+				)
+				err := row.Scan(
+					&e.ID, &e.Time, &e.Policy, &e.Action, &e.DryRun, &e.Outcome,
+					&e.Publisher, &e.TrackingID, &state, &e.Entered, &e.Error)
+				e.State = models.Workflow(state)
+				e.Time, e.Entered = e.Time.UTC(), e.Entered.UTC()
+				return e, err
+			})
+			return err
+		}, c.cfg.Database.MaxQueryDuration,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	type archivingLog struct {
+		Entries []archivingLogEntry `json:"entries"`
+		Count   *int64              `json:"count,omitempty"`
+	}
+	al := archivingLog{Entries: entries}
+	if al.Entries == nil {
+		al.Entries = []archivingLogEntry{}
+	}
+	if calcCount {
+		al.Count = &count
+	}
+	ctx.JSON(http.StatusOK, &al)
+}
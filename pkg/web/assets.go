@@ -0,0 +1,185 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocsaf/csaf/v3/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+type documentAsset struct {
+	URL         string    `json:"url"`
+	Fetched     time.Time `json:"fetched"`
+	SHA256      *string   `json:"sha256,omitempty"`
+	Size        *int64    `json:"size,omitempty"`
+	ContentType *string   `json:"content_type,omitempty"`
+	Error       *string   `json:"error,omitempty"`
+}
+
+// documentTLP checks if the user may access the given document
+// and returns its TLP label.
+func documentTLP(rctx context.Context, conn *pgxpool.Conn, docID int64) (*string, error) {
+	const tlpSQL = `SELECT tlp FROM documents WHERE id = $1`
+	var tlp *string
+	err := conn.QueryRow(rctx, tlpSQL, docID).Scan(&tlp)
+	return tlp, err
+}
+
+// viewDocumentAssets is an endpoint that returns the mirrored files
+// referenced by a document.
+//
+//	@Summary		Returns the mirrored assets of a document.
+//	@Description	Returns the files referenced by the document which were mirrored
+//	@Description	or could not be mirrored.
+//	@Param			id	path	int	true	"Document ID"
+//	@Produce		json
+//	@Success		200	{array}		web.documentAsset
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/documents/{id}/assets [get]
+func (c *Controller) viewDocumentAssets(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	const selectSQL = `SELECT da.url, da.fetched, ` +
+		`encode(da.assets_sha256, 'hex'), a.size, a.content_type, da.error ` +
+		`FROM document_assets da LEFT JOIN assets a ON da.assets_sha256 = a.sha256 ` +
+		`WHERE da.documents_id = $1 ` +
+		`ORDER BY da.url`
+	var list []documentAsset
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			if _, err := documentTLP(rctx, conn, id); err != nil {
+				return err
+			}
+			rows, _ := conn.Query(rctx, selectSQL, id)
+			var err error
+			list, err = pgx.AppendRows(list, rows,
+				func(row pgx.CollectableRow) (documentAsset, error) {
+					var da documentAsset
+					err := row.Scan(
+						&da.URL, &da.Fetched,
+						&da.SHA256, &da.Size, &da.ContentType, &da.Error)
+					da.Fetched = da.Fetched.UTC()
+					return da, err
+				})
+			return err
+		}, 0,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			models.SendErrorMessage(ctx, http.StatusNotFound, "document not found")
+		} else {
+			slog.ErrorContext(ctx, "database error", "err", err)
+			models.SendError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	if list == nil {
+		list = []documentAsset{}
+	}
+	ctx.JSON(http.StatusOK, list)
+}
+
+// viewDocumentAsset is an endpoint that returns a mirrored file
+// referenced by a document.
+//
+//	@Summary		Returns a mirrored asset of a document.
+//	@Description	Returns the content of a mirrored file referenced by the document.
+//	@Param			id		path	int		true	"Document ID"
+//	@Param			sha256	path	string	true	"SHA256 of the asset"
+//	@Produce		octet-stream
+//	@Success		200	{file}		binary
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/documents/{id}/assets/{sha256} [get]
+func (c *Controller) viewDocumentAsset(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	hash, ok := parse(ctx, hex.DecodeString, ctx.Param("sha256"))
+	if !ok {
+		return
+	}
+	const selectSQL = `SELECT da.url, a.content_type, a.data ` +
+		`FROM document_assets da JOIN assets a ON da.assets_sha256 = a.sha256 ` +
+		`WHERE da.documents_id = $1 AND da.assets_sha256 = $2 ` +
+		`LIMIT 1`
+	var (
+		assetURL    string
+		contentType *string
+		data        []byte
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tlp, err := documentTLP(rctx, conn, id)
+			if err != nil {
+				return err
+			}
+			if err := conn.QueryRow(rctx, selectSQL, id, hash).
+				Scan(&assetURL, &contentType, &data); err != nil {
+				return err
+			}
+			// The assets are part of the restricted material, too.
+			if tlp == nil || !models.TLP(*tlp).Restricted() {
+				return nil
+			}
+			_, err = conn.Exec(rctx, logDocumentAccessSQL,
+				ctx.GetString("uid"), downloadAccess, id)
+			return err
+		}, 0,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			models.SendErrorMessage(ctx, http.StatusNotFound, "asset not found")
+		} else {
+			slog.ErrorContext(ctx, "database error", "err", err)
+			models.SendError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	filename := "asset"
+	if parsed, err := url.Parse(assetURL); err == nil {
+		if base := path.Base(parsed.Path); base != "/" && base != "." {
+			filename = util.CleanFileName(base)
+		}
+	}
+	ct := "application/octet-stream"
+	if contentType != nil {
+		ct = *contentType
+	}
+	ctx.DataFromReader(
+		http.StatusOK, int64(len(data)),
+		ct,
+		bytes.NewReader(data),
+		map[string]string{
+			"Content-Disposition": fmt.Sprintf("attachment; filename=\"%s\"", filename),
+		})
+}
@@ -0,0 +1,48 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ISDuBA/ISDuBA/pkg/database"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// requireDatabase rejects requests changing data while
+// the database is unavailable.
+func (c *Controller) requireDatabase(ctx *gin.Context) {
+	switch ctx.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		if !c.db.Available() {
+			retry := max(1, int(c.cfg.Database.RetryMax.Seconds()))
+			ctx.Header("Retry-After", strconv.Itoa(retry))
+			models.SendError(ctx, http.StatusServiceUnavailable, database.ErrUnavailable)
+			ctx.Abort()
+			return
+		}
+	}
+	ctx.Next()
+}
+
+// databaseStatus returns the availability of the database.
+//
+//	@Summary		Returns the availability of the database.
+//	@Description	Returns if the database is reachable, since when it is not and the number of outages since the start.
+//	@Produce		json
+//	@Success		200	{object}	database.Status
+//	@Failure		401
+//	@Router			/admin/database [get]
+func (c *Controller) databaseStatus(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.db.Status())
+}
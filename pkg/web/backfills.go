@@ -0,0 +1,185 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/forwarder"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+type forwarderBackfill struct {
+	ID         int64      `json:"id"`
+	Target     string     `json:"target"`
+	Creator    *string    `json:"creator,omitempty"`
+	Started    time.Time  `json:"started"`
+	Finished   *time.Time `json:"finished,omitempty"`
+	Status     string     `json:"status"`
+	Advisories int64      `json:"advisories"`
+	Processed  int64      `json:"processed"`
+	Queued     int64      `json:"queued"`
+	Error      *string    `json:"error,omitempty"`
+}
+
+const selectBackfillSQL = `SELECT fb.id, fw.url, fb.creator, fb.started, fb.finished, ` +
+	`fb.status, fb.advisories, fb.processed, fb.queued, fb.error ` +
+	`FROM forwarder_backfills fb JOIN forwarders fw ON fb.forwarders_id = fw.id `
+
+func scanForwarderBackfill(row pgx.Row, fb *forwarderBackfill) error {
+	if err := row.Scan(
+		&fb.ID, &fb.Target, &fb.Creator, &fb.Started, &fb.Finished,
+		&fb.Status, &fb.Advisories, &fb.Processed, &fb.Queued, &fb.Error,
+	); err != nil {
+		return err
+	}
+	fb.Started = fb.Started.UTC()
+	if fb.Finished != nil {
+		*fb.Finished = fb.Finished.UTC()
+	}
+	return nil
+}
+
+// startForwarderBackfill is an endpoint that starts a backfill for a forward target.
+//
+//	@Summary		Starts a forwarder backfill.
+//	@Description	Queues the already imported documents which match the current
+//	@Description	publisher filter and strategy of the automatic target and were not
+//	@Description	queued for it before, e.g. after its filter was broadened.
+//	@Description	The backfill runs in the background. Only one backfill per target
+//	@Description	is run at a time. The target ID is the one listed at /forwarder/targets.
+//	@Param			target	path	int	true	"Target ID"
+//	@Produce		json
+//	@Success		201	{object}	models.ID
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		409	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/forwarder/targets/{target}/backfill [post]
+func (c *Controller) startForwarderBackfill(ctx *gin.Context) {
+	targetID, ok := parse(ctx, toInt64, ctx.Param("target"))
+	if !ok {
+		return
+	}
+	id, err := c.fm.StartBackfill(ctx.Request.Context(), targetID, c.currentUser(ctx))
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusCreated, models.ID{ID: id})
+	case errors.Is(err, forwarder.ErrNoSuchTarget):
+		models.SendError(ctx, http.StatusNotFound, err)
+	case errors.Is(err, forwarder.ErrNotAutomatic):
+		models.SendError(ctx, http.StatusBadRequest, err)
+	case errors.Is(err, forwarder.ErrBackfillRunning):
+		models.SendError(ctx, http.StatusConflict, err)
+	default:
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+	}
+}
+
+// viewForwarderBackfills is an endpoint that returns the forwarder backfills.
+//
+//	@Summary		Returns forwarder backfills.
+//	@Description	Returns the forwarder backfills with their progress, newest first.
+//	@Produce		json
+//	@Success		200	{array}		forwarderBackfill
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/forwarder/backfills [get]
+func (c *Controller) viewForwarderBackfills(ctx *gin.Context) {
+	const orderSQL = `ORDER BY fb.started DESC, fb.id DESC`
+	backfills := []forwarderBackfill{}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, selectBackfillSQL+orderSQL)
+			defer rows.Close()
+			for rows.Next() {
+				var fb forwarderBackfill
+				if err := scanForwarderBackfill(rows, &fb); err != nil {
+					return err
+				}
+				backfills = append(backfills, fb)
+			}
+			return rows.Err()
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, backfills)
+}
+
+// viewForwarderBackfill is an endpoint that returns a forwarder backfill.
+//
+//	@Summary		Returns a forwarder backfill.
+//	@Description	Returns the forwarder backfill with its progress.
+//	@Param			id	path	int	true	"Backfill ID"
+//	@Produce		json
+//	@Success		200	{object}	forwarderBackfill
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/forwarder/backfills/{id} [get]
+func (c *Controller) viewForwarderBackfill(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	var fb forwarderBackfill
+	switch err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			return scanForwarderBackfill(
+				conn.QueryRow(rctx, selectBackfillSQL+`WHERE fb.id = $1`, id), &fb)
+		}, 0,
+	); {
+	case errors.Is(err, pgx.ErrNoRows):
+		models.SendErrorMessage(ctx, http.StatusNotFound, "backfill not found")
+	case err != nil:
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+	default:
+		ctx.JSON(http.StatusOK, &fb)
+	}
+}
+
+// cancelForwarderBackfill is an endpoint that cancels a running forwarder backfill.
+//
+//	@Summary		Cancels a forwarder backfill.
+//	@Description	Cancels the running backfill. Already queued documents stay queued.
+//	@Param			id	path	int	true	"Backfill ID"
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Router			/forwarder/backfills/{id} [delete]
+func (c *Controller) cancelForwarderBackfill(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	if !c.fm.CancelBackfill(id) {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "no such running backfill")
+		return
+	}
+	models.SendSuccess(ctx, http.StatusOK, "canceled")
+}
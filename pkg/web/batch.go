@@ -0,0 +1,268 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/eventbus"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// maxBatchDocuments is the maximal number of documents which can
+// be commented in one request. Every document has its own transaction.
+const maxBatchDocuments = 1000
+
+// batchComment is the input of the batch comment endpoint.
+type batchComment struct {
+	Documents []int64 `json:"documents" binding:"required,min=1"`
+	Message   string  `json:"message"`
+	Template  *int64  `json:"template"`
+	SSVC      string  `json:"ssvc"`
+}
+
+// batchCommentResult is the outcome of a batch comment for a single document.
+type batchCommentResult struct {
+	DocumentID int64  `json:"document_id"`
+	Status     int    `json:"status"`
+	CommentID  *int64 `json:"comment_id,omitempty"`
+	SSVC       string `json:"ssvc,omitempty"`
+	Unchanged  bool   `json:"unchanged,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// createCommentsBatch is an endpoint that attaches the same comment
+// and/or SSVC vector to multiple documents.
+//
+//	@Summary		Comments and assesses multiple documents.
+//	@Description	Creates the same comment and/or sets the same SSVC vector
+//	@Description	for each of the specified documents. Every document is
+//	@Description	checked and handled on its own. The outcome per document
+//	@Description	is reported in the result list, including database errors.
+//	@Description	Documents whose SSVC vector is already set and which get no
+//	@Description	comment are reported as unchanged. At most 1000 documents
+//	@Description	can be handled in one request.
+//	@Description	Instead of a message a comment template can be given
+//	@Description	which is filled with the values of each document.
+//	@Param			input	body	batchComment	true	"Documents, comment or template and SSVC vector"
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{array}		batchCommentResult
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		403	{object}	models.Error
+//	@Router			/comments [post]
+func (c *Controller) createCommentsBatch(ctx *gin.Context) {
+	var input batchComment
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if len(input.Documents) > maxBatchDocuments {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "too many documents")
+		return
+	}
+	if input.Message != "" && input.Template != nil {
+		models.SendErrorMessage(ctx, http.StatusBadRequest,
+			"'message' and 'template' are mutually exclusive")
+		return
+	}
+	if input.Message == "" && input.Template == nil && input.SSVC == "" {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "missing message, template or ssvc")
+		return
+	}
+	if input.SSVC != "" {
+		if err := models.ValidateSSVCv2Vector(input.SSVC); err != nil {
+			models.SendError(ctx, http.StatusBadRequest, err)
+			return
+		}
+		// Only editors are allowed to change the SSVC.
+		if !c.hasAnyRole(ctx, models.Editor) {
+			models.SendErrorMessage(ctx, http.StatusForbidden, "user not allowed to change ssvc")
+			return
+		}
+	}
+
+	results := make([]batchCommentResult, 0, len(input.Documents))
+	seen := make(map[int64]bool, len(input.Documents))
+	for _, docID := range input.Documents {
+		if seen[docID] {
+			continue
+		}
+		seen[docID] = true
+		result, err := c.commentDocument(ctx, docID, input.Message, input.Template, input.SSVC)
+		if err != nil {
+			// The other documents are handled nevertheless.
+			slog.ErrorContext(ctx, "database error", "document", docID, "err", err)
+			result = batchCommentResult{
+				DocumentID: docID,
+				Status:     http.StatusInternalServerError,
+				Error:      err.Error(),
+			}
+		}
+		results = append(results, result)
+	}
+	ctx.JSON(http.StatusOK, results)
+}
+
+// commentDocument attaches a comment and/or a SSVC vector to a single
+// document in its own transaction. If a template is given the comment
+// is rendered from it.
+func (c *Controller) commentDocument(
+	ctx *gin.Context,
+	docID int64,
+	message string,
+	templateID *int64,
+	vector string,
+) (batchCommentResult, error) {
+	const (
+		findSQL = `SELECT ads.state::text, ads.tracking_id, ads.publisher, sh.ssvc ` +
+			`FROM documents docs JOIN advisories ads ` +
+			`ON docs.advisories_id = ads.id ` +
+			`LEFT JOIN LATERAL ` +
+			`(SELECT ssvc FROM ssvc_history ` +
+			`WHERE documents_id = docs.id ORDER BY changedate DESC, change_number DESC LIMIT 1) ` +
+			`sh ON true WHERE docs.id = $1`
+		assessingStateSQL = `UPDATE advisories SET state = 'assessing' ` +
+			`WHERE (tracking_id, publisher) = ($1, $2)`
+		eventSQL = `INSERT INTO events_log ` +
+			`(event, state, time, actor, documents_id, comments_id) ` +
+			`VALUES($1::events, $2::workflow, $3, $4, $5, $6)`
+		insertSQL = `INSERT INTO comments ` +
+			`(documents_id, time, commentator, message) ` +
+			`VALUES ($1, $2, $3, $4) ` +
+			`RETURNING id`
+		updateSSVC = `INSERT INTO ssvc_history (actor, documents_id, ssvc) VALUES ` +
+			`($1::varchar, $2::integer, $3)`
+	)
+
+	var (
+		result = batchCommentResult{DocumentID: docID, Status: http.StatusOK}
+		actor  = c.currentUser(ctx)
+		now    = time.Now().UTC()
+		evs    []eventbus.Event
+	)
+
+	fail := func(status int, msg string) error {
+		result.Status = status
+		result.Error = msg
+		return nil
+	}
+
+	err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tx, err := conn.BeginTx(rctx, pgx.TxOptions{})
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(rctx)
+
+			var (
+				stateS     string
+				trackingID string
+				publisher  string
+				ssvc       sql.NullString
+			)
+			switch err := tx.QueryRow(rctx, findSQL, docID).Scan(
+				&stateS, &trackingID, &publisher, &ssvc,
+			); {
+			// Documents the user is not allowed to see are not found, too.
+			case errors.Is(err, pgx.ErrNoRows):
+				return fail(http.StatusNotFound, "document not found")
+			case err != nil:
+				return err
+			}
+
+			state := models.Workflow(stateS)
+			if (message != "" || templateID != nil) && !c.isCommentingAllowed(ctx, state) {
+				return fail(http.StatusBadRequest, "invalid state to comment")
+			}
+			if templateID != nil {
+				var bad string
+				if message, bad, err = c.commentFromTemplate(
+					ctx, rctx, tx, *templateID, docID,
+				); err != nil {
+					return err
+				}
+				if bad != "" {
+					return fail(http.StatusBadRequest, bad)
+				}
+			}
+			changeSSVC := vector != "" && !(ssvc.Valid && ssvc.String == vector)
+			if changeSSVC && state != models.ReadWorkflow && state != models.AssessingWorkflow {
+				return fail(http.StatusBadRequest, "unsuited state")
+			}
+			// Nothing to write so the state is left as it is.
+			if message == "" && !changeSSVC {
+				result.Unchanged = true
+				return nil
+			}
+
+			var commentID *int64
+			logEvent := func(event models.Event, state models.Workflow) error {
+				_, err := tx.Exec(
+					rctx, eventSQL, string(event), string(state), now, actor, docID, commentID)
+				evs = append(evs, c.documentEvent(ctx, event, docID, state))
+				return err
+			}
+
+			// Switch to assessing state if we are not in.
+			if state == models.ReadWorkflow {
+				roles := models.ReadWorkflow.TransitionsRoles(models.AssessingWorkflow)
+				if len(roles) == 0 || !c.hasAnyRole(ctx, roles...) {
+					return fail(http.StatusForbidden, "user not allowed to change state")
+				}
+				if _, err := tx.Exec(rctx, assessingStateSQL, trackingID, publisher); err != nil {
+					return err
+				}
+				if err := logEvent(models.StateChangeEvent, models.AssessingWorkflow); err != nil {
+					return err
+				}
+				state = models.AssessingWorkflow
+			}
+
+			if message != "" {
+				if err := tx.QueryRow(
+					rctx, insertSQL,
+					docID, now, actor, message,
+				).Scan(&commentID); err != nil {
+					return err
+				}
+				if err := logEvent(models.AddCommentEvent, state); err != nil {
+					return err
+				}
+				result.CommentID = commentID
+			}
+
+			// The SSVC events are logged by a trigger.
+			if changeSSVC {
+				if _, err := tx.Exec(rctx, updateSSVC, actor, docID, vector); err != nil {
+					return err
+				}
+				result.SSVC = vector
+			}
+
+			return tx.Commit(rctx)
+		}, 0,
+	)
+	if err == nil && result.Status == http.StatusOK {
+		c.publishEvents(evs)
+	}
+	return result, err
+}
@@ -0,0 +1,58 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+type blobStorageStatus struct {
+	Type            string `json:"type"`
+	Inline          int64  `json:"inline"`
+	Offloaded       int64  `json:"offloaded"`
+	PendingRemovals int64  `json:"pending_removals"`
+}
+
+// viewBlobStorage is an endpoint that returns where the original uploads are kept.
+//
+//	@Summary		Returns the blob storage status.
+//	@Description	Returns the configured blob storage type, the number of original
+//	@Description	uploads still kept in the database and in the blob storage and
+//	@Description	the number of blobs of deleted documents waiting for removal.
+//	@Produce		json
+//	@Success		200	{object}	blobStorageStatus
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/admin/blobs [get]
+func (c *Controller) viewBlobStorage(ctx *gin.Context) {
+	const selectSQL = `SELECT ` +
+		`(SELECT count(*) FROM documents WHERE original IS NOT NULL), ` +
+		`(SELECT count(*) FROM documents WHERE original_key IS NOT NULL), ` +
+		`(SELECT count(*) FROM deleted_blobs)`
+	status := blobStorageStatus{Type: c.cfg.BlobStorage.Type.String()}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			return conn.QueryRow(rctx, selectSQL).Scan(
+				&status.Inline, &status.Offloaded, &status.PendingRemovals)
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, &status)
+}
@@ -0,0 +1,139 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/sources"
+)
+
+// evictedCaches are the numbers of entries removed from the caches.
+type evictedCaches struct {
+	sources.CacheEviction
+	Aggregators int `json:"aggregators"`
+	Search      int `json:"search"`
+}
+
+// cacheStats are the numbers of the caches.
+type cacheStats struct {
+	sources.CacheStats
+	Aggregators int `json:"aggregators"`
+	Search      int `json:"search"`
+}
+
+// viewCaches is an endpoint that returns the numbers of the caches.
+//
+//	@Summary		Returns the numbers of the caches.
+//	@Description	Returns the numbers of entries of the caches and the usage
+//	@Description	and hit rate of the HTTP cache shared by the fetches of the
+//	@Description	provider metadata, OpenPGP keys, feeds and aggregators.
+//	@Produce		json
+//	@Success		200	{object}	web.cacheStats
+//	@Failure		401
+//	@Router			/admin/caches [get]
+func (c *Controller) viewCaches(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, cacheStats{
+		CacheStats:  c.sm.CacheStats(),
+		Aggregators: c.am.Cache.Len(),
+		Search:      c.qc.Len(),
+	})
+}
+
+// evictCaches is an endpoint that removes entries from the caches.
+//
+//	@Summary		Removes entries from the caches.
+//	@Description	Removes entries from the caches of the provider metadata (pmd),
+//	@Description	the OpenPGP keys of the sources (keys), the aggregators (aggregator),
+//	@Description	the search results (search) and the shared HTTP cache (http)
+//	@Description	to get rid of stale entries after upstream fixes. Without a cache
+//	@Description	selector all caches are used. The entries can be restricted to a URL
+//	@Description	or a source. The search cache can only be cleared as a whole.
+//	@Description	Evicted PMDs and aggregators are removed from the HTTP cache, too.
+//	@Param			cache	query	[]string	false	"Caches"	Enums(pmd, keys, aggregator, search, http)
+//	@Param			url		query	string		false	"URL of a PMD or an aggregator"
+//	@Param			source	query	int			false	"Source ID"
+//	@Produce		json
+//	@Success		200	{object}	web.evictedCaches
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Router			/admin/caches [delete]
+func (c *Controller) evictCaches(ctx *gin.Context) {
+	var sourceID int64
+	if s := ctx.Query("source"); s != "" {
+		var ok bool
+		if sourceID, ok = parse(ctx, toInt64, s); !ok {
+			return
+		}
+	}
+	url := ctx.Query("url")
+	selected := map[string]bool{}
+	for _, cache := range ctx.QueryArray("cache") {
+		switch cache {
+		case "pmd", "keys", "aggregator", "search", "http":
+			selected[cache] = true
+		default:
+			models.SendErrorMessage(ctx, http.StatusBadRequest,
+				fmt.Sprintf("unknown cache %q", cache))
+			return
+		}
+	}
+	if len(selected) == 0 {
+		selected["pmd"], selected["keys"], selected["aggregator"] = true, true, true
+		// The search results cannot be selected by URL or source.
+		selected["search"] = url == "" && sourceID == 0
+		selected["http"] = sourceID == 0
+	}
+
+	var (
+		result evictedCaches
+		err    error
+	)
+	if selected["pmd"] || selected["keys"] {
+		result.CacheEviction, err = c.sm.EvictCaches(
+			selected["pmd"], selected["keys"], url, sourceID)
+		if err != nil {
+			if errors.Is(err, sources.NoSuchEntryError("")) {
+				models.SendErrorMessage(ctx, http.StatusNotFound, "source not found")
+				return
+			}
+			models.SendError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	// Aggregators are not bound to sources.
+	if selected["aggregator"] && sourceID == 0 {
+		result.Aggregators = c.am.Cache.Evict(url)
+	}
+	if selected["search"] {
+		result.Search = c.qc.Len()
+		c.qc.Invalidate()
+	}
+	// The HTTP cache is not bound to sources.
+	if selected["http"] && sourceID == 0 {
+		result.HTTP += c.sm.EvictHTTPCache(url)
+	}
+	slog.InfoContext(ctx, "caches evicted",
+		"user", ctx.GetString("uid"),
+		"url", url,
+		"source", sourceID,
+		"pmds", result.PMDs,
+		"keys", result.Keys,
+		"aggregators", result.Aggregators,
+		"search", result.Search,
+		"http", result.HTTP)
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,216 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/helper"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/database/query"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// assessmentCertificateType identifies the statements.
+const assessmentCertificateType = "isduba-assessment-certificate"
+
+// assessmentCertificate is the signed statement of the assessment of a document.
+type assessmentCertificate struct {
+	Type    string    `json:"type"`
+	Version int       `json:"version"`
+	Issued  time.Time `json:"issued"`
+	Issuer  string    `json:"issuer,omitempty"`
+	// Document identifies the assessed document.
+	Document struct {
+		Publisher  string `json:"publisher"`
+		TrackingID string `json:"tracking_id"`
+		Version    string `json:"version"`
+		SHA256     string `json:"sha256"`
+	} `json:"document"`
+	State        string     `json:"state"`
+	StateChanged *time.Time `json:"state_changed,omitempty"`
+	SSVC         *string    `json:"ssvc,omitempty"`
+	// Assessor is the user who set the SSVC vector, Assessed the time of it.
+	Assessor *string    `json:"assessor,omitempty"`
+	Assessed *time.Time `json:"assessed,omitempty"`
+}
+
+// viewAssessmentCertificate is an endpoint that returns a signed
+// statement of the assessment of a document.
+//
+//	@Summary		Returns a signed statement of the assessment of a document.
+//	@Description	Returns the workflow state, the SSVC vector, the assessor and
+//	@Description	the times of the assessment of the document as JSON in an OpenPGP
+//	@Description	cleartext signed message. The statement contains the SHA256 checksum
+//	@Description	of the original document so partners can verify it against their copy.
+//	@Description	The message is signed with the key of the signed archives.
+//	@Param			id	path	int	true	"Document ID"
+//	@Produce		text/plain
+//	@Success		200	{string}	string
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/documents/{id}/certificate [get]
+func (c *Controller) viewAssessmentCertificate(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	keyRing, err := c.archiveSigningKey()
+	if err != nil {
+		slog.ErrorContext(ctx, "cannot sign certificate", "err", err)
+		models.SendErrorMessage(ctx, http.StatusInternalServerError, "certificate signing not configured")
+		return
+	}
+	defer keyRing.ClearPrivateParams()
+
+	expr := query.FieldEqInt("id", id)
+	builder := query.SQLBuilder{}
+	builder.CreateWhere(expr)
+	// Only documents the user is allowed to see are certified.
+	allowedSQL := builder.CreateQuery([]string{"id"}, "", -1, -1)
+
+	const (
+		documentSQL = `SELECT ads.publisher, ads.tracking_id, docs.version, ` +
+			`ads.state::text, docs.ssvc, docs.original, docs.original_key ` +
+			`FROM documents docs JOIN advisories ads ON docs.advisories_id = ads.id ` +
+			`WHERE docs.id = $1`
+		ssvcSQL = `SELECT actor, changedate FROM ssvc_history ` +
+			`WHERE documents_id = $1 ORDER BY change_number DESC LIMIT 1`
+		stateSQL = `SELECT max(time) FROM events_log ` +
+			`WHERE documents_id = $1 AND event = 'state_change'`
+	)
+
+	cert := assessmentCertificate{
+		Type:    assessmentCertificateType,
+		Version: 1,
+		Issued:  time.Now().UTC().Truncate(time.Second),
+		Issuer:  c.cfg.Web.ExternalURL,
+	}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tx, err := conn.BeginTx(rctx, pgx.TxOptions{
+				IsoLevel:   pgx.RepeatableRead,
+				AccessMode: pgx.ReadOnly,
+			})
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(rctx)
+			if err := tx.QueryRow(rctx, allowedSQL, builder.Replacements...).Scan(&id); err != nil {
+				return err
+			}
+			var (
+				original []byte
+				key      *string
+			)
+			doc := &cert.Document
+			if err := tx.QueryRow(rctx, documentSQL, id).Scan(
+				&doc.Publisher, &doc.TrackingID, &doc.Version,
+				&cert.State, &cert.SSVC, &original, &key,
+			); err != nil {
+				return err
+			}
+			if key != nil {
+				// The blobs are keyed by their SHA-256 sums.
+				doc.SHA256 = *key
+			} else {
+				sum := sha256.Sum256(original)
+				doc.SHA256 = hex.EncodeToString(sum[:])
+			}
+			if cert.SSVC != nil {
+				var assessed time.Time
+				switch err := tx.QueryRow(rctx, ssvcSQL, id).Scan(&cert.Assessor, &assessed); {
+				case errors.Is(err, pgx.ErrNoRows):
+				case err != nil:
+					return err
+				default:
+					assessed = assessed.UTC()
+					cert.Assessed = &assessed
+				}
+			}
+			if err := tx.QueryRow(rctx, stateSQL, id).Scan(&cert.StateChanged); err != nil {
+				return err
+			}
+			if cert.StateChanged != nil {
+				*cert.StateChanged = cert.StateChanged.UTC()
+			}
+			return tx.Commit(rctx)
+		}, 0,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			models.SendErrorMessage(ctx, http.StatusNotFound, "document not found")
+		} else {
+			slog.ErrorContext(ctx, "database error", "err", err)
+			models.SendError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	data, err := json.MarshalIndent(&cert, "", "  ")
+	if err != nil {
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	signed, err := helper.SignCleartextMessage(keyRing, string(data))
+	if err != nil {
+		slog.ErrorContext(ctx, "signing certificate failed", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf(
+		"attachment; filename=\"assessment-%d.json.asc\"", id))
+	ctx.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(signed))
+}
+
+// viewCertificateKey is an endpoint that returns the public key
+// to verify the assessment certificates and the signed archives.
+//
+//	@Summary		Returns the public key of the signed certificates and archives.
+//	@Description	Returns the ASCII armored public OpenPGP key to verify
+//	@Description	the assessment certificates and the signed archives.
+//	@Produce		text/plain
+//	@Success		200	{string}	string
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/documents/certificates/key [get]
+func (c *Controller) viewCertificateKey(ctx *gin.Context) {
+	keyRing, err := c.archiveSigningKey()
+	if err != nil {
+		slog.ErrorContext(ctx, "cannot load signing key", "err", err)
+		models.SendErrorMessage(ctx, http.StatusInternalServerError, "certificate signing not configured")
+		return
+	}
+	defer keyRing.ClearPrivateParams()
+	key, err := keyRing.GetKey(0)
+	if err != nil {
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	armored, err := key.GetArmoredPublicKey()
+	if err != nil {
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf(
+		"attachment; filename=\"%s.asc\"", key.GetFingerprint()))
+	ctx.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(armored))
+}
@@ -0,0 +1,135 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// maxChangesLimit limits the number of changes returned at once.
+const maxChangesLimit = 10_000
+
+// documentChangesResult is the answer of the changes endpoint.
+type documentChangesResult struct {
+	Changes []models.DocumentChange `json:"changes"`
+	Next    models.ChangeCursor     `json:"next" swaggertype:"string"`
+	More    bool                    `json:"more"`
+}
+
+// documentChanges is an endpoint that returns the changes of the documents after a cursor.
+//
+//	@Summary		Returns the changes of the documents.
+//	@Description	Returns the documents imported, updated or deleted after the given cursor
+//	@Description	in a stable order to synchronize external systems incrementally.
+//	@Description	Deleted documents are reported as tombstones. Pass the returned next cursor
+//	@Description	as since to continue. If more is true there are further changes.
+//	@Description	The ETag is the next cursor, so polling with If-None-Match results in
+//	@Description	304 Not Modified if nothing has changed.
+//	@Param			since		query	string	false	"Cursor of the last seen change"
+//	@Param			limit		query	int		false	"Maximum number of changes"
+//	@Param			publisher	query	string	false	"Only changes of this publisher"
+//	@Produce		json
+//	@Success		200	{object}	web.documentChangesResult
+//	@Success		304
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/documents/changes [get]
+func (c *Controller) documentChanges(ctx *gin.Context) {
+	since, ok := parse(ctx, models.ParseChangeCursor, ctx.Query("since"))
+	if !ok {
+		return
+	}
+	limit, ok := parse(ctx, toInt64, ctx.DefaultQuery("limit", "1000"))
+	if !ok {
+		return
+	}
+	limit = min(max(limit, 1), maxChangesLimit)
+	publisher := ctx.Query("publisher")
+
+	// Only the changes of transactions older than all running
+	// ones are returned as the others may be followed by
+	// changes with smaller ids.
+	// The changes outlive the documents so the TLP rules
+	// are checked against the recorded publishers and TLPs.
+	const selectSQL = `SELECT xid::text, id, documents_id, kind::text, changed, ` +
+		`publisher, tracking_id, version, tlp, ` +
+		`tlp IS NOT NULL AND session_tlp_allowed(publisher, tlp) ` +
+		`FROM document_changes ` +
+		`WHERE (xid, id) > ($1::text::xid8, $2) ` +
+		`AND xid < pg_snapshot_xmin(pg_current_snapshot()) ` +
+		`AND ($3 = '' OR publisher = $3) ` +
+		`ORDER BY xid, id ` +
+		`LIMIT $4`
+
+	var (
+		next    = since
+		scanned int64
+		changes = []models.DocumentChange{}
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, err := conn.Query(rctx, selectSQL,
+				strconv.FormatUint(since.XID, 10), since.ID, publisher, limit)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var (
+					xid     string
+					dc      models.DocumentChange
+					visible bool
+				)
+				if err := rows.Scan(
+					&xid, &dc.Cursor.ID, &dc.DocumentID, &dc.Kind, &dc.Changed,
+					&dc.Publisher, &dc.TrackingID, &dc.Version, &dc.TLP, &visible,
+				); err != nil {
+					return err
+				}
+				if dc.Cursor.XID, err = strconv.ParseUint(xid, 10, 64); err != nil {
+					return err
+				}
+				scanned++
+				// The cursor advances over the changes hidden from the user, too.
+				next = dc.Cursor
+				if !visible {
+					continue
+				}
+				dc.Changed = dc.Changed.UTC()
+				changes = append(changes, dc)
+			}
+			return rows.Err()
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	etag := `"` + next.String() + `"`
+	ctx.Header("ETag", etag)
+	if ctx.GetHeader("If-None-Match") == etag {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+	ctx.JSON(http.StatusOK, documentChangesResult{
+		Changes: changes,
+		Next:    next,
+		More:    scanned == limit,
+	})
+}
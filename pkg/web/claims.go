@@ -0,0 +1,319 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+type documentClaim struct {
+	DocumentID int64     `json:"document_id"`
+	Claimant   string    `json:"claimant"`
+	Claimed    time.Time `json:"claimed"`
+	Expires    time.Time `json:"expires"`
+}
+
+// documentVisible checks if the document exists and the user is allowed to see it.
+func documentVisible(rctx context.Context, conn *pgxpool.Conn, docID int64) (bool, error) {
+	const existsSQL = `SELECT EXISTS(SELECT 1 FROM documents WHERE id = $1)`
+	var exists bool
+	err := conn.QueryRow(rctx, existsSQL, docID).Scan(&exists)
+	return exists, err
+}
+
+// viewClaims is an endpoint that returns all active claims.
+//
+//	@Summary		Returns active claims.
+//	@Description	Returns all documents which are currently claimed.
+//	@Produce		json
+//	@Success		200	{array}		documentClaim
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/claims [get]
+func (c *Controller) viewClaims(ctx *gin.Context) {
+	// The join with the documents hides the claims of documents
+	// the user is not allowed to see.
+	const selectSQL = `SELECT dc.documents_id, dc.claimant, dc.claimed, dc.expires ` +
+		`FROM document_claims dc ` +
+		`JOIN documents docs ON dc.documents_id = docs.id ` +
+		`WHERE dc.expires > current_timestamp ` +
+		`ORDER BY dc.claimed`
+
+	claims := []documentClaim{}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, selectSQL)
+			defer rows.Close()
+			for rows.Next() {
+				var claim documentClaim
+				if err := rows.Scan(
+					&claim.DocumentID, &claim.Claimant, &claim.Claimed, &claim.Expires,
+				); err != nil {
+					return err
+				}
+				claim.Claimed = claim.Claimed.UTC()
+				claim.Expires = claim.Expires.UTC()
+				claims = append(claims, claim)
+			}
+			return rows.Err()
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, claims)
+}
+
+// viewClaim is an endpoint that returns the claim of a document.
+//
+//	@Summary		Returns the claim of a document.
+//	@Description	Returns who currently holds the claim of the specified document.
+//	@Param			document	path	int	true	"Document ID"
+//	@Produce		json
+//	@Success		200	{object}	documentClaim
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/claims/{document} [get]
+func (c *Controller) viewClaim(ctx *gin.Context) {
+	docID, ok := parse(ctx, toInt64, ctx.Param("document"))
+	if !ok {
+		return
+	}
+	const selectSQL = `SELECT claimant, claimed, expires FROM document_claims ` +
+		`WHERE documents_id = $1 AND expires > current_timestamp`
+
+	claim := documentClaim{DocumentID: docID}
+	var exists, claimed bool
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			var err error
+			if exists, err = documentVisible(rctx, conn, docID); err != nil || !exists {
+				return err
+			}
+			switch err := conn.QueryRow(rctx, selectSQL, docID).Scan(
+				&claim.Claimant, &claim.Claimed, &claim.Expires,
+			); {
+			case errors.Is(err, pgx.ErrNoRows):
+				return nil
+			case err != nil:
+				return err
+			}
+			claimed = true
+			return nil
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	switch {
+	case !exists:
+		models.SendErrorMessage(ctx, http.StatusNotFound, "document not found")
+	case !claimed:
+		models.SendErrorMessage(ctx, http.StatusNotFound, "not claimed")
+	default:
+		claim.Claimed = claim.Claimed.UTC()
+		claim.Expires = claim.Expires.UTC()
+		ctx.JSON(http.StatusOK, &claim)
+	}
+}
+
+// claimAttempts is the number of attempts to claim a document
+// whose claim is released while claiming it.
+const claimAttempts = 3
+
+// claimDocument is an endpoint that claims a document for the current user.
+//
+//	@Summary		Claims a document.
+//	@Description	Claims the specified document for the current user.
+//	@Description	Claiming an own document again extends the claim.
+//	@Description	Admins are able to take over claims of other users with force.
+//	@Description	Team leads and admins are able to assign the document to another
+//	@Description	user. Team leads only to the members of the teams they lead.
+//	@Param			document	path	int		true	"Document ID"
+//	@Param			force		query	bool	false	"Take over the claim of another user"
+//	@Param			assignee	query	string	false	"User to claim the document for"
+//	@Produce		json
+//	@Success		200	{object}	documentClaim
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		403	{object}	models.Error
+//	@Failure		404	{object}	models.Error
+//	@Failure		409	{object}	documentClaim	"claimed by another user"
+//	@Failure		500	{object}	models.Error
+//	@Router			/claims/{document} [post]
+func (c *Controller) claimDocument(ctx *gin.Context) {
+	docID, ok := parse(ctx, toInt64, ctx.Param("document"))
+	if !ok {
+		return
+	}
+	force, ok := parse(ctx, strconv.ParseBool, ctx.DefaultQuery("force", "false"))
+	if !ok {
+		return
+	}
+	if force && !c.hasAnyRole(ctx, models.Admin) {
+		models.SendErrorMessage(ctx, http.StatusForbidden, "only admins are allowed to force claims")
+		return
+	}
+	user := ctx.GetString("uid")
+	claimant := user
+	if assignee := ctx.Query("assignee"); assignee != "" {
+		claimant = assignee
+	}
+	admin := c.hasAnyRole(ctx, models.Admin)
+
+	const (
+		claimSQL = `INSERT INTO document_claims (documents_id, claimant, claimed, expires) ` +
+			`VALUES ($1, $2, $3, $4) ` +
+			`ON CONFLICT (documents_id) DO UPDATE SET ` +
+			`claimed = CASE WHEN document_claims.claimant = EXCLUDED.claimant ` +
+			`AND document_claims.expires > EXCLUDED.claimed ` +
+			`THEN document_claims.claimed ELSE EXCLUDED.claimed END, ` +
+			`claimant = EXCLUDED.claimant, ` +
+			`expires = EXCLUDED.expires ` +
+			`WHERE document_claims.claimant = EXCLUDED.claimant ` +
+			`OR document_claims.expires <= EXCLUDED.claimed ` +
+			`OR $5 ` +
+			`RETURNING claimant, claimed, expires`
+		holderSQL = `SELECT claimant, claimed, expires FROM document_claims ` +
+			`WHERE documents_id = $1`
+	)
+
+	var (
+		now      = time.Now().UTC()
+		expires  = now.Add(c.cfg.Workflow.ClaimDuration)
+		claim    = documentClaim{DocumentID: docID}
+		exists   bool
+		conflict bool
+		denied   bool
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			var err error
+			if exists, err = documentVisible(rctx, conn, docID); err != nil || !exists {
+				return err
+			}
+			if claimant != user && !admin {
+				lead, err := leadsMember(rctx, conn, user, claimant)
+				if denied = !lead; err != nil || denied {
+					return err
+				}
+			}
+			for range claimAttempts {
+				switch err := conn.QueryRow(
+					rctx, claimSQL, docID, claimant, now, expires, force,
+				).Scan(&claim.Claimant, &claim.Claimed, &claim.Expires); {
+				case err == nil:
+					return nil
+				case !errors.Is(err, pgx.ErrNoRows):
+					return err
+				}
+				// Held by someone else.
+				switch err := conn.QueryRow(rctx, holderSQL, docID).Scan(
+					&claim.Claimant, &claim.Claimed, &claim.Expires); {
+				case errors.Is(err, pgx.ErrNoRows):
+					// Released in the meantime, so try again.
+					continue
+				case err != nil:
+					return err
+				}
+				conflict = true
+				return nil
+			}
+			return errors.New("claim changed concurrently too often")
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	claim.Claimed = claim.Claimed.UTC()
+	claim.Expires = claim.Expires.UTC()
+	switch {
+	case !exists:
+		models.SendErrorMessage(ctx, http.StatusNotFound, "document not found")
+	case denied:
+		models.SendErrorMessage(ctx, http.StatusForbidden,
+			"only leads of a team of the assignee are allowed to assign")
+	case conflict:
+		ctx.JSON(http.StatusConflict, &claim)
+	default:
+		ctx.JSON(http.StatusOK, &claim)
+	}
+}
+
+// releaseClaim is an endpoint that releases the claim of a document.
+//
+//	@Summary		Releases a claim.
+//	@Description	Releases the claim of the specified document.
+//	@Description	Admins are able to release the claims of other users,
+//	@Description	team leads the claims of the members of their teams.
+//	@Param			document	path	int	true	"Document ID"
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/claims/{document} [delete]
+func (c *Controller) releaseClaim(ctx *gin.Context) {
+	docID, ok := parse(ctx, toInt64, ctx.Param("document"))
+	if !ok {
+		return
+	}
+	const deleteSQL = `DELETE FROM document_claims WHERE documents_id = $1 AND ` +
+		`(claimant = $2 OR $3 OR claimant IN (` +
+		`SELECT "user" FROM team_members WHERE teams_id IN (SELECT led_teams($2))))`
+	admin := c.hasAnyRole(ctx, models.Admin)
+	var exists, released bool
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			var err error
+			if exists, err = documentVisible(rctx, conn, docID); err != nil || !exists {
+				return err
+			}
+			tag, err := conn.Exec(rctx, deleteSQL, docID, ctx.GetString("uid"), admin)
+			if err != nil {
+				return err
+			}
+			released = tag.RowsAffected() > 0
+			return nil
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	switch {
+	case !exists:
+		models.SendErrorMessage(ctx, http.StatusNotFound, "document not found")
+	case !released:
+		models.SendErrorMessage(ctx, http.StatusNotFound, "no claim to release")
+	default:
+		models.SendSuccess(ctx, http.StatusOK, "released")
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/ISDuBA/ISDuBA/pkg/database/query"
+	"github.com/ISDuBA/ISDuBA/pkg/eventbus"
 	"github.com/ISDuBA/ISDuBA/pkg/models"
 )
 
@@ -44,8 +45,11 @@ func (c *Controller) isCommentingAllowed(ctx *gin.Context, state models.Workflow
 //
 //	@Summary		Creates a comment.
 //	@Description	Creates a comment for the specified document.
-//	@Param			id		path		int		true	"Document ID"
-//	@Param			message	formData	string	true	"Comment message"
+//	@Description	Instead of a message a comment template can be given
+//	@Description	which is filled with the values of the document.
+//	@Param			id			path		int		true	"Document ID"
+//	@Param			message		formData	string	false	"Comment message"
+//	@Param			template	formData	int		false	"Comment template ID"
 //	@Accept			multipart/form-data
 //	@Produce		json
 //	@Success		201	{object}	web.createComment.commentResult
@@ -67,7 +71,24 @@ func (c *Controller) createComment(ctx *gin.Context) {
 		return
 	}
 
-	expr := c.andTLPExpr(ctx, query.FieldEqInt("id", docID))
+	var (
+		templateID *int64
+		message, _ = ctx.GetPostForm("message")
+	)
+	if tmpl := ctx.PostForm("template"); tmpl != "" {
+		id, ok := parse(ctx, toInt64, tmpl)
+		if !ok {
+			return
+		}
+		if message != "" {
+			models.SendErrorMessage(ctx, http.StatusBadRequest,
+				"'message' and 'template' are mutually exclusive")
+			return
+		}
+		templateID = &id
+	}
+
+	expr := query.FieldEqInt("id", docID)
 	builder := query.SQLBuilder{}
 	builder.CreateWhere(expr)
 
@@ -75,10 +96,11 @@ func (c *Controller) createComment(ctx *gin.Context) {
 		exists            bool
 		commentingAllowed bool
 		forbidden         bool
+		badTemplate       string
 		commentator       = c.currentUser(ctx)
-		message, _        = ctx.GetPostForm("message")
 		now               = time.Now().UTC()
 		commentID         *int64
+		evs               []eventbus.Event
 	)
 
 	if err := c.db.Run(
@@ -116,12 +138,21 @@ func (c *Controller) createComment(ctx *gin.Context) {
 				return nil
 			}
 
+			if templateID != nil {
+				if message, badTemplate, err = c.commentFromTemplate(
+					ctx, rctx, tx, *templateID, docID,
+				); err != nil || badTemplate != "" {
+					return err
+				}
+			}
+
 			logEvent := func(event models.Event, state models.Workflow) error {
 				const eventSQL = `INSERT INTO events_log ` +
 					`(event, state, time, actor, documents_id, comments_id) ` +
 					`VALUES($1::events, $2::workflow, $3, $4, $5, $6)`
 				_, err := tx.Exec(
 					rctx, eventSQL, string(event), string(state), now, commentator, docID, commentID)
+				evs = append(evs, c.documentEvent(ctx, event, docID, state))
 				return err
 			}
 
@@ -168,7 +199,7 @@ func (c *Controller) createComment(ctx *gin.Context) {
 			return tx.Commit(rctx)
 		}, 0,
 	); err != nil {
-		slog.Error("database error", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -177,9 +208,12 @@ func (c *Controller) createComment(ctx *gin.Context) {
 		models.SendErrorMessage(ctx, http.StatusNotFound, "document not found")
 	case !commentingAllowed:
 		models.SendErrorMessage(ctx, http.StatusBadRequest, "invalid state to comment")
+	case badTemplate != "":
+		models.SendErrorMessage(ctx, http.StatusBadRequest, badTemplate)
 	case forbidden:
 		models.SendErrorMessage(ctx, http.StatusForbidden, "user not allowed to change state")
 	default:
+		c.publishEvents(evs)
 		ctx.JSON(http.StatusCreated, commentResult{
 			ID:          commentID,
 			Time:        now,
@@ -208,7 +242,7 @@ func (c *Controller) updateComment(ctx *gin.Context) {
 		return
 	}
 
-	expr := c.andTLPExpr(ctx, query.FieldEqInt("com.id", commentID))
+	expr := query.FieldEqInt("com.id", commentID)
 	builder := query.SQLBuilder{}
 	builder.CreateWhere(expr)
 
@@ -283,7 +317,7 @@ func (c *Controller) updateComment(ctx *gin.Context) {
 			return tx.Commit(rctx)
 		}, 0,
 	); err != nil {
-		slog.Error("database error", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -325,7 +359,7 @@ func (c *Controller) viewComment(ctx *gin.Context) {
 		return
 	}
 
-	expr := c.andTLPExpr(ctx, query.FieldEqInt("comments.id", id))
+	expr := query.FieldEqInt("comments.id", id)
 
 	builder := query.SQLBuilder{}
 
@@ -346,7 +380,7 @@ func (c *Controller) viewComment(ctx *gin.Context) {
 	case errors.Is(err, pgx.ErrNoRows):
 		models.SendErrorMessage(ctx, http.StatusNotFound, "comment post not found")
 	case err != nil:
-		slog.Error("database error while fetching comment post", "err", err)
+		slog.ErrorContext(ctx, "database error while fetching comment post", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 	default:
 		ctx.JSON(http.StatusOK, &post)
@@ -378,9 +412,8 @@ func (c *Controller) viewComments(ctx *gin.Context) {
 		return
 	}
 
-	expr := c.andTLPExpr(ctx,
-		query.FieldEqString("tracking_id", key.TrackingID).And(
-			query.FieldEqString("publisher", key.Publisher)))
+	expr := query.FieldEqString("tracking_id", key.TrackingID).And(
+		query.FieldEqString("publisher", key.Publisher))
 
 	builder := query.SQLBuilder{}
 	builder.CreateWhere(expr)
@@ -420,7 +453,7 @@ func (c *Controller) viewComments(ctx *gin.Context) {
 			return err
 		}, 0,
 	); err != nil {
-		slog.Error("database error", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
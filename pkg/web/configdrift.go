@@ -0,0 +1,254 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/sources"
+)
+
+// configSnapshotLimit is the maximal size of an uploaded configuration snapshot.
+const configSnapshotLimit = 16 * 1024 * 1024
+
+// configDrift is the result of comparing two configuration snapshots.
+type configDrift struct {
+	LocalHash  string                    `json:"local_hash"`
+	RemoteHash string                    `json:"remote_hash"`
+	Aligned    bool                      `json:"aligned"`
+	Changes    []models.ConfigDifference `json:"changes"`
+}
+
+// headerNames returns the names of the given "name: value" headers
+// as their values may carry credentials.
+func headerNames(headers []string) []string {
+	names := make([]string, 0, len(headers))
+	for _, header := range headers {
+		name, _, _ := strings.Cut(header, ":")
+		names = append(names, strings.ToLower(strings.TrimSpace(name)))
+	}
+	return names
+}
+
+// configSnapshot collects the runtime relevant configuration
+// of this instance without the secrets.
+func (c *Controller) configSnapshot(ctx context.Context) (*models.ConfigSnapshot, error) {
+	cs := models.NewConfigSnapshot()
+
+	// Global source settings and policies.
+	sc := &c.cfg.Sources
+	cs.Set("sources/download_slots", sc.DownloadSlots)
+	cs.Set("sources/max_slots_per_source", sc.MaxSlotsPerSource)
+	cs.Set("sources/max_rate_per_source", sc.MaxRatePerSource)
+	cs.Set("sources/feed_refresh", sc.FeedRefresh)
+	cs.Set("sources/timeout", sc.Timeout)
+	cs.Set("sources/feed_log_level", sc.FeedLogLevel)
+	cs.Set("sources/feed_importer", sc.FeedImporter)
+	cs.Set("sources/strict_mode", sc.StrictMode)
+	cs.Set("sources/secure", sc.Secure)
+	cs.Set("sources/signature_check", sc.SignatureCheck)
+	cs.Set("sources/default_age", sc.DefaultAge)
+	cs.Set("sources/checking", sc.Checking)
+	cs.Set("sources/publishers_tlps", sc.PublishersTLPs)
+	cs.Set("publishers_tlps", c.cfg.PublishersTLPs)
+
+	wf := &c.cfg.Workflow
+	for i, approval := range wf.Approvals {
+		cs.Set(fmt.Sprintf("workflow/approval/%d/state", i), approval.State)
+		cs.Set(fmt.Sprintf("workflow/approval/%d/min_cvss", i), approval.MinCVSS)
+	}
+	cs.Set("workflow/claim_duration", wf.ClaimDuration)
+	cs.Set("workflow/share_duration", wf.ShareDuration)
+	cs.Set("workflow/share_max_duration", wf.ShareMaxDuration)
+	cs.Set("workflow/share_tlps", wf.ShareTLPs)
+
+	// Forwarder targets are identified by their URLs.
+	fc := &c.cfg.Forwarder
+	cs.Set("forwarder/update_interval", fc.UpdateInterval)
+	cs.Set("forwarder/strategy", fc.Strategy)
+	for i := range fc.Targets {
+		target := &fc.Targets[i]
+		prefix := "forwarder/targets/" + target.URL + "/"
+		cs.Set(prefix+"name", target.Name)
+		cs.Set(prefix+"type", target.Type)
+		cs.Set(prefix+"automatic", target.Automatic)
+		cs.Set(prefix+"publisher", target.Publisher)
+		cs.Set(prefix+"strategy", target.Strategy)
+		cs.Set(prefix+"tlp", target.TLP)
+		cs.Set(prefix+"timeout", target.Timeout)
+		cs.Set(prefix+"headers", headerNames(target.Header))
+		cs.Set(prefix+"has_client_cert", target.ClientPublicCert != "")
+		cs.Set(prefix+"has_password", target.Password != nil)
+		cs.Set(prefix+"has_passphrase", target.Passphrase != nil)
+	}
+
+	// Sources are identified by their names and feeds by their URLs.
+	var infos []sources.SourceInfo
+	if err := c.sm.Sources(ctx, func(si *sources.SourceInfo) {
+		infos = append(infos, *si)
+	}, false, sources.OrderByID); err != nil {
+		return nil, err
+	}
+	for i := range infos {
+		si := &infos[i]
+		var feeds []sources.FeedInfo
+		if err := c.sm.Feeds(ctx, si.ID, func(fi *sources.FeedInfo) {
+			feeds = append(feeds, *fi)
+		}, false, sources.OrderByID); err != nil {
+			// The source was removed in the meantime.
+			continue
+		}
+		prefix := "sources/" + si.Name + "/"
+		ignorePatterns := make([]string, 0, len(si.IgnorePatterns))
+		for _, re := range si.IgnorePatterns {
+			ignorePatterns = append(ignorePatterns, re.String())
+		}
+		cs.Set(prefix+"url", si.URL)
+		cs.Set(prefix+"active", si.Active)
+		cs.Set(prefix+"shadow", si.Shadow)
+		cs.Set(prefix+"priority", si.Priority)
+		cs.Set(prefix+"rate", si.Rate)
+		cs.Set(prefix+"slots", si.Slots)
+		cs.Set(prefix+"weight", si.Weight)
+		cs.Set(prefix+"headers", headerNames(si.Headers))
+		cs.Set(prefix+"strict_mode", si.StrictMode)
+		cs.Set(prefix+"secure", si.Secure)
+		cs.Set(prefix+"signature_check", si.SignatureCheck)
+		cs.Set(prefix+"age", si.Age)
+		cs.Set(prefix+"ignore_patterns", ignorePatterns)
+		cs.Set(prefix+"has_client_cert", si.HasClientCertPublic)
+		cs.Set(prefix+"oauth2_token_url", si.OAuth2TokenURL)
+		cs.Set(prefix+"oauth2_client_id", si.OAuth2ClientID)
+		cs.Set(prefix+"oauth2_scopes", si.OAuth2Scopes)
+		cs.Set(prefix+"basic_auth_user", si.BasicAuthUser)
+		cs.Set(prefix+"has_tls_ca_bundle", si.HasTLSCABundle)
+		cs.Set(prefix+"tls_pinned_certs", si.TLSPinnedCerts)
+		cs.Set(prefix+"receipt_url", si.ReceiptURL)
+		for j := range feeds {
+			fi := &feeds[j]
+			feedPrefix := prefix + "feeds/" + fi.URL.String() + "/"
+			cs.Set(feedPrefix+"label", fi.Label)
+			cs.Set(feedPrefix+"rolie", fi.Rolie)
+			cs.Set(feedPrefix+"log_level", fi.Lvl)
+		}
+	}
+
+	// The scoring is changeable at runtime so it is taken from the database.
+	const (
+		weightsSQL = `SELECT factor::text, weight FROM scoring_weights`
+		trustSQL   = `SELECT publisher, trust FROM publishers_trust`
+	)
+	if err := c.db.Run(
+		ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			var (
+				name  string
+				value float64
+			)
+			rows, _ := conn.Query(rctx, weightsSQL)
+			if _, err := pgx.ForEachRow(rows, []any{&name, &value}, func() error {
+				cs.Set("scoring/weights/"+name, value)
+				return nil
+			}); err != nil {
+				return err
+			}
+			rows, _ = conn.Query(rctx, trustSQL)
+			_, err := pgx.ForEachRow(rows, []any{&name, &value}, func() error {
+				cs.Set("scoring/publishers_trust/"+name, value)
+				return nil
+			})
+			return err
+		}, 0,
+	); err != nil {
+		return nil, err
+	}
+	cs.Seal()
+	return cs, nil
+}
+
+// viewConfigSnapshot is an endpoint that exports a snapshot of the configuration.
+//
+//	@Summary		Returns a snapshot of the configuration.
+//	@Description	Returns a normalized snapshot of the runtime relevant configuration
+//	@Description	(sources, feeds, forwarder targets, workflow, TLP and scoring policies)
+//	@Description	with a hash over its entries. Secrets and instance specific ids are
+//	@Description	not included. Snapshots of different instances can be compared
+//	@Description	with the diff endpoint.
+//	@Produce		json
+//	@Success		200	{object}	models.ConfigSnapshot
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/admin/config/snapshot [get]
+func (c *Controller) viewConfigSnapshot(ctx *gin.Context) {
+	cs, err := c.configSnapshot(ctx.Request.Context())
+	if err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, cs)
+}
+
+// diffConfigSnapshot is an endpoint that compares a configuration
+// snapshot of another instance with the configuration of this one.
+//
+//	@Summary		Compares a configuration snapshot.
+//	@Description	Compares the uploaded snapshot of another instance with the
+//	@Description	configuration of this instance and returns the differing entries.
+//	@Param			snapshot	body	models.ConfigSnapshot	true	"Configuration snapshot"
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	configDrift
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/admin/config/diff [post]
+func (c *Controller) diffConfigSnapshot(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, configSnapshotLimit)
+	var remote models.ConfigSnapshot
+	if err := ctx.ShouldBindJSON(&remote); err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if remote.Version != models.ConfigSnapshotVersion {
+		models.SendErrorMessage(ctx, http.StatusBadRequest,
+			"unsupported snapshot version "+strconv.Itoa(remote.Version))
+		return
+	}
+	if remote.Entries == nil {
+		remote.Entries = map[string]string{}
+	}
+	if remote.Hash != "" && remote.Hash != remote.ComputeHash() {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "snapshot hash does not match its entries")
+		return
+	}
+	local, err := c.configSnapshot(ctx.Request.Context())
+	if err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	changes := local.Diff(&remote)
+	ctx.JSON(http.StatusOK, configDrift{
+		LocalHash:  local.Hash,
+		RemoteHash: remote.ComputeHash(),
+		Aligned:    len(changes) == 0,
+		Changes:    changes,
+	})
+}
@@ -0,0 +1,200 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/requestid"
+	"github.com/ISDuBA/ISDuBA/pkg/sources"
+	"github.com/gin-gonic/gin"
+)
+
+type connectivityTimings struct {
+	DNS       time.Duration `json:"dns,omitempty" swaggertype:"integer"`
+	Connect   time.Duration `json:"connect,omitempty" swaggertype:"integer"`
+	TLS       time.Duration `json:"tls,omitempty" swaggertype:"integer"`
+	FirstByte time.Duration `json:"first_byte,omitempty" swaggertype:"integer"`
+	Total     time.Duration `json:"total" swaggertype:"integer"`
+}
+
+type connectivityCert struct {
+	Subject   string    `json:"subject"`
+	Issuer    string    `json:"issuer"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+	DNSNames  []string  `json:"dns_names,omitempty"`
+}
+
+type connectivityTLS struct {
+	Version     string             `json:"version"`
+	CipherSuite string             `json:"cipher_suite"`
+	ServerName  string             `json:"server_name,omitempty"`
+	Chain       []connectivityCert `json:"chain"`
+}
+
+type connectivityResult struct {
+	URL        string              `json:"url"`
+	Method     string              `json:"method"`
+	RemoteAddr string              `json:"remote_addr,omitempty"`
+	Proxy      string              `json:"proxy,omitempty"`
+	StatusCode int                 `json:"status_code,omitempty"`
+	Status     string              `json:"status,omitempty"`
+	Proto      string              `json:"proto,omitempty"`
+	TLS        *connectivityTLS    `json:"tls,omitempty"`
+	Timings    connectivityTimings `json:"timings"`
+	Error      string              `json:"error,omitempty"`
+}
+
+// connectivityCheck is an endpoint that checks if a URL is reachable from the server.
+//
+//	@Summary		Checks the reachability of a URL.
+//	@Description	Requests the URL with the proxy and TLS settings of the server
+//	@Description	and reports timings, the TLS certificate chain and the HTTP status.
+//	@Description	Failing requests are reported in the error field of the result.
+//	@Param			url		formData	string	true	"URL to check"
+//	@Param			method	formData	string	false	"HEAD or GET, defaults to HEAD"
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Success		200	{object}	connectivityResult
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Router			/admin/connectivity-check [post]
+func (c *Controller) connectivityCheck(ctx *gin.Context) {
+	rawURL, ok := ctx.GetPostForm("url")
+	if !ok || rawURL == "" {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "missing 'url'")
+		return
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if scheme := strings.ToLower(u.Scheme); scheme != "http" && scheme != "https" {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "URL has to be http or https")
+		return
+	}
+	method := strings.ToUpper(ctx.DefaultPostForm("method", http.MethodHead))
+	if method != http.MethodHead && method != http.MethodGet {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "method has to be HEAD or GET")
+		return
+	}
+
+	result := connectivityResult{
+		URL:    u.String(),
+		Method: method,
+	}
+
+	transport := c.cfg.General.Transport()
+	defer transport.CloseIdleConnections()
+	client := http.Client{Transport: requestid.NewTransport(transport)}
+	if c.cfg.Sources.Timeout > 0 {
+		client.Timeout = c.cfg.Sources.Timeout
+	}
+
+	req, err := http.NewRequestWithContext(ctx.Request.Context(), method, u.String(), nil)
+	if err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	req.Header.Set("User-Agent", sources.UserAgent)
+
+	if proxy, err := transport.Proxy(req); err == nil && proxy != nil {
+		result.Proxy = proxy.Redacted()
+	}
+
+	// The hooks may be called concurrently when dialing multiple addresses.
+	var (
+		mu                               sync.Mutex
+		dnsStart, connectStart, tlsStart time.Time
+	)
+	locked := func(fn func()) {
+		mu.Lock()
+		defer mu.Unlock()
+		fn()
+	}
+	start := time.Now()
+	trace := httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			locked(func() { dnsStart = time.Now() })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			locked(func() { result.Timings.DNS = time.Since(dnsStart) })
+		},
+		ConnectStart: func(string, string) {
+			locked(func() { connectStart = time.Now() })
+		},
+		ConnectDone: func(_, addr string, err error) {
+			if err == nil {
+				locked(func() {
+					result.Timings.Connect = time.Since(connectStart)
+					result.RemoteAddr = addr
+				})
+			}
+		},
+		TLSHandshakeStart: func() {
+			locked(func() { tlsStart = time.Now() })
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if err == nil {
+				locked(func() { result.Timings.TLS = time.Since(tlsStart) })
+			}
+		},
+		GotFirstResponseByte: func() {
+			locked(func() { result.Timings.FirstByte = time.Since(start) })
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &trace))
+
+	resp, err := client.Do(req)
+	mu.Lock()
+	defer mu.Unlock()
+	if err != nil {
+		result.Timings.Total = time.Since(start)
+		result.Error = err.Error()
+		ctx.JSON(http.StatusOK, &result)
+		return
+	}
+	defer resp.Body.Close()
+	// Drain a limited amount of the body to measure the transfer.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, int64(c.cfg.General.AdvisoryUploadLimit)))
+	result.Timings.Total = time.Since(start)
+
+	result.StatusCode = resp.StatusCode
+	result.Status = resp.Status
+	result.Proto = resp.Proto
+	if state := resp.TLS; state != nil {
+		ct := connectivityTLS{
+			Version:     tls.VersionName(state.Version),
+			CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+			ServerName:  state.ServerName,
+			Chain:       make([]connectivityCert, 0, len(state.PeerCertificates)),
+		}
+		for _, cert := range state.PeerCertificates {
+			ct.Chain = append(ct.Chain, connectivityCert{
+				Subject:   cert.Subject.String(),
+				Issuer:    cert.Issuer.String(),
+				NotBefore: cert.NotBefore.UTC(),
+				NotAfter:  cert.NotAfter.UTC(),
+				DNSNames:  cert.DNSNames,
+			})
+		}
+		result.TLS = &ct
+	}
+	ctx.JSON(http.StatusOK, &result)
+}
@@ -13,21 +13,32 @@ import (
 	"log/slog"
 	"net/http"
 
-	"github.com/gin-contrib/static"
 	"github.com/gin-gonic/gin"
 	"github.com/gocsaf/csaf/v3/csaf"
 	sloggin "github.com/samber/slog-gin"
 
 	"github.com/ISDuBA/ISDuBA/pkg/aggregators"
+	"github.com/ISDuBA/ISDuBA/pkg/blobs"
 	"github.com/ISDuBA/ISDuBA/pkg/config"
 	"github.com/ISDuBA/ISDuBA/pkg/database"
+	"github.com/ISDuBA/ISDuBA/pkg/eventbus"
 	"github.com/ISDuBA/ISDuBA/pkg/forwarder"
 	"github.com/ISDuBA/ISDuBA/pkg/ginkeycloak"
+	"github.com/ISDuBA/ISDuBA/pkg/grouptlps"
 	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/recompute"
+	"github.com/ISDuBA/ISDuBA/pkg/scanner"
+	"github.com/ISDuBA/ISDuBA/pkg/scheduler"
+	"github.com/ISDuBA/ISDuBA/pkg/searchcache"
 	"github.com/ISDuBA/ISDuBA/pkg/sources"
+	"github.com/ISDuBA/ISDuBA/pkg/subscriptions"
+	"github.com/ISDuBA/ISDuBA/pkg/sweeper"
 	"github.com/ISDuBA/ISDuBA/pkg/tempstore"
+	"github.com/ISDuBA/ISDuBA/pkg/transform"
+	"github.com/ISDuBA/ISDuBA/pkg/usage"
+	"github.com/ISDuBA/ISDuBA/pkg/webclient"
 
-	_ "github.com/ISDuBA/ISDuBA/pkg/web/docs" // include generated swagger data.
+	"github.com/ISDuBA/ISDuBA/pkg/web/docs" // include generated swagger data.
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -40,7 +51,20 @@ type Controller struct {
 	ts  *tempstore.Store
 	sm  *sources.Manager
 	am  *aggregators.Manager
+	sw  *sweeper.Sweeper
+	rc  *recompute.Recomputer
+	bs  *blobs.Store
 	val csaf.RemoteValidator
+	st  *scheduler.Registry
+	qc  *searchcache.Cache
+	gt  *grouptlps.Rules
+	ur  *usage.Recorder
+	nf  *subscriptions.Notifier
+	sc  *scanner.Scanner
+	tf  *transform.Transformer
+	eb  *eventbus.Bus
+
+	simulated simulatedSources
 }
 
 // NewController returns a new Controller.
@@ -51,7 +75,18 @@ func NewController(
 	ts *tempstore.Store,
 	dl *sources.Manager,
 	am *aggregators.Manager,
+	sw *sweeper.Sweeper,
+	rc *recompute.Recomputer,
+	bs *blobs.Store,
 	val csaf.RemoteValidator,
+	st *scheduler.Registry,
+	qc *searchcache.Cache,
+	gt *grouptlps.Rules,
+	ur *usage.Recorder,
+	nf *subscriptions.Notifier,
+	sc *scanner.Scanner,
+	tf *transform.Transformer,
+	eb *eventbus.Bus,
 ) *Controller {
 	return &Controller{
 		cfg: cfg,
@@ -60,7 +95,18 @@ func NewController(
 		ts:  ts,
 		sm:  dl,
 		am:  am,
+		sw:  sw,
+		rc:  rc,
+		bs:  bs,
 		val: val,
+		st:  st,
+		qc:  qc,
+		gt:  gt,
+		ur:  ur,
+		nf:  nf,
+		sc:  sc,
+		tf:  tf,
+		eb:  eb,
 	}
 }
 
@@ -74,22 +120,57 @@ func (c *Controller) currentUser(ctx *gin.Context) sql.NullString {
 	return user
 }
 
+// webClient returns the server of the web client. The embedded
+// client is preferred over the static folder if configured.
+func (c *Controller) webClient() *webclient.Server {
+	if c.cfg.Web.Embedded {
+		if client := webclient.NewEmbedded(); client != nil {
+			slog.Info("serving embedded web client")
+			return client
+		}
+	}
+	if c.cfg.Web.Static != "" {
+		return webclient.NewDisk(c.cfg.Web.Static)
+	}
+	return nil
+}
+
 // Bind return a http handler to be used in a web server.
 func (c *Controller) Bind() http.Handler {
 	r := gin.New()
+	// Let the gin context fall back to the request context
+	// so that it carries the request id into the logs.
+	r.ContextWithFallback = true
+	r.Use(requestID)
 	r.Use(sloggin.New(slog.Default()))
 	r.Use(gin.Recovery())
+
+	// Everything is served below the configured base path.
+	basePath := c.cfg.Web.BasePath
+	root := r.Group(basePath)
+
 	// Serve API description.
-	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	docs.SwaggerInfo.BasePath = basePath + "/api"
+	root.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	if c.cfg.Web.Static != "" {
-		r.Use(static.Serve("/", static.LocalFile(c.cfg.Web.Static, false)))
+	if client := c.webClient(); client != nil {
+		r.Use(client.Handler(basePath))
 	}
 
 	kcCfg := c.cfg.Keycloak.Config(extractTLPs)
 
+	// Authenticated requests only see the documents
+	// permitted by the TLP rules of the user.
+	restrict := func(auth gin.HandlerFunc) gin.HandlerFunc {
+		return func(ctx *gin.Context) {
+			if auth(ctx); !ctx.IsAborted() {
+				c.restrictDocuments(ctx)
+			}
+		}
+	}
+
 	authRoles := func(roles ...models.WorkflowRole) gin.HandlerFunc {
-		return ginkeycloak.Auth(ginkeycloak.RoleCheck(rolesAsStrings(roles)...), kcCfg)
+		return restrict(ginkeycloak.Auth(ginkeycloak.RoleCheck(rolesAsStrings(roles)...), kcCfg))
 	}
 
 	var (
@@ -97,8 +178,11 @@ func (c *Controller) Bind() http.Handler {
 		authAdAuEdRe   = authRoles(models.Admin, models.Auditor, models.Editor, models.Reviewer)
 		authAdEdImReSM = authRoles(models.Admin, models.Editor, models.Importer, models.Reviewer,
 			models.SourceManager)
-		authAdEdRe = authRoles(models.Admin, models.Editor, models.Reviewer)
-		authAuEdRe = authRoles(models.Auditor, models.Editor, models.Reviewer)
+		authAdAu     = authRoles(models.Admin, models.Auditor)
+		authAdEdRe   = authRoles(models.Admin, models.Editor, models.Reviewer)
+		authAuEdRe   = authRoles(models.Auditor, models.Editor, models.Reviewer)
+		authAuEdReSM = authRoles(models.Auditor, models.Editor, models.Reviewer,
+			models.SourceManager)
 		authAuEdSM = authRoles(models.Auditor, models.Editor, models.SourceManager)
 		authEd     = authRoles(models.Editor)
 		authEdRe   = authRoles(models.Editor, models.Reviewer)
@@ -106,19 +190,50 @@ func (c *Controller) Bind() http.Handler {
 		authSM     = authRoles(models.SourceManager)
 		authAll    = authRoles(models.Admin, models.Auditor, models.Editor, models.Importer,
 			models.Reviewer, models.SourceManager)
+		authPMD = restrict(ginkeycloak.Auth(ginkeycloak.RoleCheck(
+			append([]string{string(models.SourceManager)}, c.cfg.Sources.PMDProxyRoles...)...), kcCfg))
 	)
 
-	api := r.Group("/api")
+	// Requests changing data are rejected while the database is unavailable.
+	api := root.Group("/api", c.recordUsage, c.requireDatabase)
+	// Operations which work without the database.
+	ops := root.Group("/api", c.recordUsage)
+	// Administrative endpoints which may be restricted to the admin listener.
+	admin := api.Group("", c.onAdminListener)
+	adminOps := ops.Group("", c.onAdminListener)
 
 	// Documents
 	// Importer can import (POST) documents
 	api.POST("/documents", authIm, c.importDocument)
+	api.POST("/validate", authAll, c.validateDocuments)
 	// Everyone can view (GET) overviewDocuments and viewDocuments?
 	api.GET("/documents", authAll, c.overviewDocuments)
 	api.GET("/documents/:id", authAll, c.viewDocument)
+	api.PUT("/documents/:id/read", authAll, c.markDocumentRead)
+	api.DELETE("/documents/:id/read", authAll, c.markDocumentUnread)
+	api.POST("/documents/read", authAll, c.markDocumentsRead)
 	api.GET("/documents/forward", authAdEdImReSM, c.viewForwardTargets)
+	api.GET("/documents/changes", authAll, c.documentChanges)
+	api.GET("/documents/archive", authAdAu, c.exportArchive)
+	api.GET("/documents/:id/certificate", authAll, c.viewAssessmentCertificate)
+	api.GET("/documents/:id/transformations", authAll, c.viewDocumentTransformations)
+	api.GET("/documents/:id/ssvc/history", authAll, c.viewDocumentSSVCHistory)
+	api.GET("/documents/certificates/key", authAll, c.viewCertificateKey)
+	api.GET("/documents/sample", authAll, c.sampleDocuments)
 	api.GET("/documents/texts/:id", authAdEdImReSM, c.documentTexts)
 	api.POST("/documents/forward/:id/:target", authAdEdImReSM, c.forwardDocument)
+	admin.GET("/forwarder/targets", authAd, c.viewForwarderTargets)
+	admin.POST("/forwarder/targets", authAd, c.createForwardTarget)
+	admin.GET("/forwarder/targets/:target", authAd, c.viewForwardTarget)
+	admin.PUT("/forwarder/targets/:target", authAd, c.updateForwardTarget)
+	admin.DELETE("/forwarder/targets/:target", authAd, c.deleteForwardTarget)
+	admin.POST("/forwarder/targets/:target/test", authAd, c.testForwardTarget)
+	admin.POST("/forwarder/targets/:target/backfill", authAd, c.startForwarderBackfill)
+	admin.GET("/forwarder/backfills", authAd, c.viewForwarderBackfills)
+	admin.GET("/forwarder/backfills/:id", authAd, c.viewForwarderBackfill)
+	admin.DELETE("/forwarder/backfills/:id", authAd, c.cancelForwarderBackfill)
+	admin.GET("/forwarder/log", authAd, c.viewForwardLog)
+	admin.POST("/forwarder/replay/:document", authAd, c.replayForwardedDocument)
 	// Admin can delete documents
 	api.DELETE("/documents/:id", authAd, c.deleteDocument)
 
@@ -127,10 +242,23 @@ func (c *Controller) Bind() http.Handler {
 	// Related CVEs
 	api.GET("/documents/:id/cve_related", authAdAuEdRe, c.cveRelatedDocuments)
 
+	// Mirrored assets
+	api.GET("/documents/:id/assets", authAll, c.viewDocumentAssets)
+	api.GET("/documents/:id/assets/:sha256", authAll, c.viewDocumentAsset)
+
+	// Annotations anchored to parts of the documents
+	api.GET("/documents/:id/annotations", authAdAuEdRe, c.viewAnnotations)
+	api.POST("/documents/:id/annotations", authAdEdRe, c.createAnnotation)
+	api.DELETE("/annotations/:id", authAdEdRe, c.deleteAnnotation)
+
 	// Advisories
 	api.DELETE("/advisory/:publisher/:trackingid", authAd, c.deleteAdvisory)
 
+	// Access log of restricted documents
+	api.GET("/accesses/export", authAdAu, c.exportAccessLog)
+
 	// Comments
+	api.POST("/comments", authAdEdRe, c.createCommentsBatch)
 	api.POST("/comments/:document", authAdEdRe, c.createComment)
 	api.GET("/comments/:publisher/:trackingid", authAdAuEdRe, c.viewComments)
 	api.PUT("/comments/post/:id", authAdEdRe, c.updateComment)
@@ -143,18 +271,85 @@ func (c *Controller) Bind() http.Handler {
 	api.GET("/queries/:query", authAll, c.fetchStoredQuery)
 	api.PUT("/queries/:query", authAll, c.updateStoredQuery)
 	api.DELETE("/queries/:query", authAll, c.deleteStoredQuery)
+	api.POST("/queries/:query/clone", authAll, c.cloneStoredQuery)
 	api.GET("/queries/ignore", authAll, c.getDefaultQueryExclusion)
+	api.GET("/queries/history", authAll, c.viewQueryHistory)
+	api.DELETE("/queries/history", authAll, c.clearQueryHistory)
 	api.POST("/queries/ignore/:query", authAll, c.insertDefaultQueryExclusion)
 	api.DELETE("/queries/ignore/:query", authAll, c.deleteDefaultQueryExclusion)
 
+	// Query subscriptions
+	api.POST("/queries/:query/subscription", authAll, c.subscribeStoredQuery)
+	api.DELETE("/queries/:query/subscription", authAll, c.unsubscribeStoredQuery)
+	api.POST("/queries/:query/subscription/test", authAll, c.testSubscriptionWebhook)
+	api.GET("/subscriptions", authAll, c.listSubscriptions)
+	api.GET("/subscriptions/matches", authAll, c.viewSubscriptionMatches)
+	api.POST("/subscriptions/matches/seen", authAll, c.markSubscriptionMatchesSeen)
+
+	// Team defaults
+	api.GET("/landing", authAll, c.viewLandingPage)
+	admin.GET("/teams", authAd, c.viewTeamDefaults)
+	admin.PUT("/teams/*group", authAd, c.updateTeamDefaults)
+	admin.DELETE("/teams/*group", authAd, c.deleteTeamDefaults)
+
+	// TLP rules of groups
+	admin.GET("/tlps/groups", authAd, c.viewGroupTLPs)
+	admin.PUT("/tlps/groups/*group", authAd, c.updateGroupTLPs)
+	admin.DELETE("/tlps/groups/*group", authAd, c.deleteGroupTLPs)
+
+	// Organization
+	api.GET("/organization/me", authAll, c.viewUserOrganization)
+	api.GET("/organization/teams", authAll, c.listTeams)
+	api.GET("/organization/teams/:id", authAll, c.viewTeam)
+	admin.POST("/organization/teams", authAd, c.createTeam)
+	admin.PUT("/organization/teams/:id", authAd, c.updateTeam)
+	admin.DELETE("/organization/teams/:id", authAd, c.deleteTeam)
+
+	// Claims
+	api.GET("/claims", authAdAuEdRe, c.viewClaims)
+	api.GET("/claims/:document", authAdAuEdRe, c.viewClaim)
+	api.POST("/claims/:document", authAdEdRe, c.claimDocument)
+	api.DELETE("/claims/:document", authAdEdRe, c.releaseClaim)
+
+	// Shared links
+	api.POST("/shares/documents/:document", authAll, c.createSharedLink)
+	api.GET("/shares", authAll, c.viewSharedLinks)
+	api.DELETE("/shares/:id", authAll, c.revokeSharedLink)
+	api.GET("/shared/:token", c.viewSharedDocument)
+
+	// Legal holds
+	admin.POST("/legalholds", authAd, c.createLegalHold)
+	api.GET("/legalholds", authAdAu, c.viewLegalHolds)
+	api.GET("/legalholds/:id", authAdAu, c.viewLegalHold)
+	admin.DELETE("/legalholds/:id", authAd, c.releaseLegalHold)
+
+	// Archiving policies
+	admin.GET("/archiving/preview", authAd, c.viewArchivingPreview)
+	api.GET("/archiving/log", authAdAu, c.viewArchivingLog)
+
+	// Text templates
+	api.POST("/templates", authAdEdRe, c.createTemplate)
+	api.GET("/templates", authAdAuEdRe, c.listTemplates)
+	api.GET("/templates/:id", authAdAuEdRe, c.viewTemplate)
+	api.PUT("/templates/:id", authAdEdRe, c.updateTemplate)
+	api.DELETE("/templates/:id", authAdEdRe, c.deleteTemplate)
+	api.GET("/templates/:id/documents/:document", authAdEdRe, c.renderTemplate)
+
 	// Events
 	api.GET("/events", authAdAuEdRe, c.overviewEvents)
+	api.GET("/events/history", authAdAuEdRe, c.eventHistory)
+	api.GET("/events/stream", authAdAuEdRe, c.streamEvents)
 	api.GET("/events/:publisher/:trackingid", authAdAuEdRe, c.viewEvents)
 
 	// State change
 	api.PUT("/status/:publisher/:trackingid/:state", authAdEdRe, c.changeStatus)
 	api.PUT("/status", authAdEdRe, c.changeStatusBulk)
 
+	// Approvals of state changes
+	api.GET("/approvals", authAdAuEdRe, c.viewApprovals)
+	api.PUT("/approvals/:id/approve", authAdEdRe, c.approveStateChange)
+	api.PUT("/approvals/:id/reject", authAdEdRe, c.rejectStateChange)
+
 	// SSVC view/change
 	api.PUT("/ssvc/:document", authEd, c.changeSSVC)
 	api.GET("/ssvc/documents/:document", authAll, c.viewSSVC)
@@ -164,10 +359,11 @@ func (c *Controller) Bind() http.Handler {
 	api.GET("/diff/:document1/:document2", authEdRe, c.viewDiff)
 
 	// Manage temporary documents
+	// Source managers stage the previews of feed documents, too.
 	api.POST("/tempdocuments", authAuEdRe, c.importTempDocument)
-	api.GET("/tempdocuments", authAuEdRe, c.overviewTempDocuments)
-	api.GET("/tempdocuments/:id", authAuEdRe, c.viewTempDocument)
-	api.DELETE("/tempdocuments/:id", authAuEdRe, c.deleteTempDocument)
+	api.GET("/tempdocuments", authAuEdReSM, c.overviewTempDocuments)
+	api.GET("/tempdocuments/:id", authAuEdReSM, c.viewTempDocument)
+	api.DELETE("/tempdocuments/:id", authAuEdReSM, c.deleteTempDocument)
 
 	// Backend information
 	api.GET("/about", authAll, c.about)
@@ -179,26 +375,40 @@ func (c *Controller) Bind() http.Handler {
 	api.GET("/client-config", c.clientConfig)
 
 	// PMD proxy
-	api.GET("/pmd", authSM, c.pmd)
+	admin.GET("/pmd", authPMD, c.pmd)
 
 	// Source manager
 	api.GET("/sources", authAuEdSM, c.viewSources)
-	api.POST("/sources", authSM, c.createSource)
+	admin.POST("/sources", authSM, c.createSource)
+	admin.POST("/sources/import", authSM, c.importSources)
+	admin.GET("/sources/export", authSM, c.exportSources)
 	api.GET("/sources/message", authAll, c.defaultMessage)
-	api.GET("/sources/attention", authSM, c.attentionSources)
-	api.GET("/sources/default", authSM, c.defaultSourceConfig)
-	api.DELETE("/sources/:id", authSM, c.deleteSource)
-	api.GET("/sources/:id", authSM, c.viewSource)
-	api.PUT("/sources/:id", authSM, c.updateSource)
+	admin.GET("/sources/attention", authSM, c.attentionSources)
+	admin.POST("/sources/attention/ack", authSM, c.acknowledgeAttentionSources)
+	admin.GET("/sources/default", authSM, c.defaultSourceConfig)
+	admin.GET("/sources/pipeline", authSM, c.pipelineStats)
+	admin.GET("/sources/failed", authSM, c.failedDownloads)
+	admin.POST("/sources/failed", authSM, c.handleFailedDownloads)
+	admin.DELETE("/sources/:id", authSM, c.deleteSource)
+	admin.GET("/sources/:id/delete-preview", authSM, c.previewDeleteSource)
+	admin.GET("/sources/:id", authSM, c.viewSource)
+	admin.GET("/sources/:id/actions", authSM, c.viewSourceActions)
+	admin.PUT("/sources/:id", authSM, c.updateSource)
 
 	// Source feeds
+	api.GET("/sources/:id/metrics", authAuEdSM, c.viewSourceMetrics)
 	api.GET("/sources/:id/feeds", authAuEdSM, c.viewFeeds)
-	api.POST("/sources/:id/feeds", authSM, c.createFeed)
+	admin.POST("/sources/:id/feeds", authSM, c.createFeed)
+	admin.GET("/sources/:id/feeds/rescan", authSM, c.rescanFeeds)
+	admin.POST("/sources/:id/feeds/rescan", authSM, c.applyFeedRescan)
 	api.GET("/sources/feeds/:id", authAuEdSM, c.viewFeed)
-	api.PUT("/sources/feeds/:id", authSM, c.updateFeed)
-	api.DELETE("/sources/feeds/:id", authSM, c.deleteFeed)
-	api.GET("/sources/feeds/log", authSM, c.allFeedsLog)
-	api.GET("/sources/feeds/:id/log", authSM, c.feedLog)
+	admin.PUT("/sources/feeds/:id", authSM, c.updateFeed)
+	admin.DELETE("/sources/feeds/:id", authSM, c.deleteFeed)
+	admin.GET("/sources/feeds/log", authSM, c.allFeedsLog)
+	admin.GET("/sources/feeds/log/export", authSM, c.exportFeedLog)
+	admin.GET("/sources/feeds/:id/log", authSM, c.feedLog)
+	admin.GET("/sources/feeds/:id/sync", authSM, c.feedSync)
+	admin.POST("/sources/feeds/:id/preview", authSM, c.previewFeedDocument)
 	api.GET("/sources/feeds/keep", authAll, c.keepFeedTime)
 
 	// Import stats
@@ -212,15 +422,74 @@ func (c *Controller) Bind() http.Handler {
 	api.GET("/stats/critical/feed/:id", authAll, c.criticalStatsFeed)
 	api.GET("/stats/critical", authAll, c.criticalStatsAllSources)
 	api.GET("/stats/totals", authAll, c.statsTotal)
+	api.GET("/stats/tlp", authAll, c.tlpStatsTimeline)
+	api.GET("/stats/tlp/report", authAll, c.tlpReportPeriod)
+	api.GET("/stats/sla", authAll, c.slaReportPeriod)
+	api.GET("/stats/triage", authAll, c.triageReportPeriod)
+
+	// Escalations of overdue advisories
+	api.GET("/sla/overdue", authAdAuEdRe, c.viewOverdueAdvisories)
+	api.GET("/sla/escalations", authAdAuEdRe, c.viewEscalations)
+
+	// Scoring
+	api.GET("/scoring", authAll, c.viewScoring)
+	admin.PUT("/scoring", authAd, c.updateScoring)
+	admin.PUT("/scoring/cves", authAd, c.updateCVEScores)
+	api.PUT("/scoring/assets/:document", authAdEdRe, c.updateAssetMatch)
+	api.DELETE("/scoring/assets/:document", authAdEdRe, c.deleteAssetMatch)
+
+	// Admin
+	adminOps.POST("/admin/connectivity-check", authAd, c.connectivityCheck)
+	adminOps.GET("/admin/schedulers", authAd, c.viewSchedulers)
+	adminOps.POST("/admin/schedulers/:name/trigger", authAd, c.triggerScheduler)
+	adminOps.PUT("/admin/schedulers/:name", authAd, c.updateScheduler)
+	adminOps.GET("/admin/database", authAd, c.databaseStatus)
+	adminOps.GET("/admin/caches", authAd, c.viewCaches)
+	adminOps.DELETE("/admin/caches", authAd, c.evictCaches)
+	adminOps.GET("/admin/config/snapshot", authAd, c.viewConfigSnapshot)
+	adminOps.POST("/admin/config/diff", authAd, c.diffConfigSnapshot)
+	adminOps.GET("/admin/support-bundle", authAd, c.supportBundle)
+	admin.GET("/admin/usage", authAd, c.overviewUsage)
+	admin.GET("/admin/blobs", authAd, c.viewBlobStorage)
+	adminOps.GET("/admin/tempdocuments", authAd, c.overviewAllTempDocuments)
+	adminOps.DELETE("/admin/tempdocuments/:user/:id", authAd, c.deleteUserTempDocument)
+
+	// API usage
+	api.GET("/usage", authAll, c.viewUsage)
+
+	// Validation sweeps
+	admin.POST("/validation/sweeps", authAd, c.startValidationSweep)
+	admin.GET("/validation/sweeps", authAd, c.viewValidationSweeps)
+	admin.GET("/validation/sweeps/:id", authAd, c.viewValidationSweep)
+	admin.DELETE("/validation/sweeps/:id", authAd, c.cancelValidationSweep)
+
+	// Recomputation of the derived fields
+	admin.POST("/recompute/jobs", authAd, c.startRecomputeJob)
+	admin.GET("/recompute/jobs", authAd, c.viewRecomputeJobs)
+	admin.GET("/recompute/jobs/:id", authAd, c.viewRecomputeJob)
+	admin.DELETE("/recompute/jobs/:id", authAd, c.cancelRecomputeJob)
+	admin.POST("/recompute/jobs/:id/resume", authAd, c.resumeRecomputeJob)
 
 	// Aggregators
-	api.GET("/aggregator", authAuEdSM, c.aggregatorProxy)
-	api.GET("/aggregators", authAuEdSM, c.viewAggregators)
-	api.GET("/aggregators/:id", authAuEdSM, c.viewAggregator)
-	api.PUT("/aggregators/:id", authSM, c.updateAggregator)
-	api.GET("/aggregators/attention", authSM, c.attentionAggregators)
-	api.POST("/aggregators", authSM, c.createAggregator)
-	api.DELETE("/aggregators/:id", authSM, c.deleteAggregator)
-
-	return r
+	admin.GET("/aggregator", authAuEdSM, c.aggregatorProxy)
+	admin.GET("/aggregators", authAuEdSM, c.viewAggregators)
+	admin.GET("/aggregators/:id", authAuEdSM, c.viewAggregator)
+	admin.PUT("/aggregators/:id", authSM, c.updateAggregator)
+	admin.GET("/aggregators/attention", authSM, c.attentionAggregators)
+	admin.GET("/aggregators/recommendations", authSM, c.aggregatorRecommendations)
+	admin.GET("/aggregators/stats", authSM, c.aggregatorsStats)
+	admin.POST("/aggregators/attention/ack", authSM, c.acknowledgeAttentionAggregators)
+	admin.POST("/aggregators", authSM, c.createAggregator)
+	admin.DELETE("/aggregators/:id", authSM, c.deleteAggregator)
+	admin.POST("/aggregators/:id/subscribe", authSM, c.subscribeAggregator)
+
+	// Development helpers, intentionally not part of the API documentation.
+	if c.cfg.Web.DevEndpoints {
+		slog.Warn("dev endpoints are enabled")
+		adminOps.POST("/dev/sources/simulated", authSM, c.createSimulatedSource)
+		adminOps.GET("/dev/sources/simulated", authSM, c.viewSimulatedSources)
+		adminOps.DELETE("/dev/sources/simulated/:id", authSM, c.deleteSimulatedSource)
+	}
+
+	return c.versioned(r)
 }
@@ -0,0 +1,199 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocsaf/csaf/v3/csaf"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/sources/sourcetest"
+)
+
+// maxSimulatedDocuments limits the number of documents
+// served by a simulated source.
+const maxSimulatedDocuments = 1000
+
+// simulatedSources are the fake providers started via the dev endpoints.
+type simulatedSources struct {
+	mu        sync.Mutex
+	nextID    int64
+	providers map[int64]*simulatedSource
+}
+
+type simulatedSource struct {
+	provider *sourcetest.Provider
+	created  time.Time
+}
+
+type simulatedSourceInfo struct {
+	ID          int64     `json:"id"`
+	Created     time.Time `json:"created"`
+	URL         string    `json:"url"`
+	Fingerprint string    `json:"fingerprint,omitempty"`
+	Certificate string    `json:"certificate,omitempty"`
+	Documents   []string  `json:"documents"`
+}
+
+func (ss *simulatedSource) info(id int64) simulatedSourceInfo {
+	return simulatedSourceInfo{
+		ID:          id,
+		Created:     ss.created,
+		URL:         ss.provider.PMDURL(),
+		Fingerprint: ss.provider.Fingerprint(),
+		Certificate: string(ss.provider.CertificatePEM()),
+		Documents:   ss.provider.Documents(),
+	}
+}
+
+func parseLayout(s string) (sourcetest.Layout, error) {
+	switch strings.ToLower(s) {
+	case "", "rolie":
+		return sourcetest.ROLIE, nil
+	case "directory":
+		return sourcetest.Directory, nil
+	case "both":
+		return sourcetest.ROLIE | sourcetest.Directory, nil
+	}
+	return 0, fmt.Errorf("unknown layout %q", s)
+}
+
+func parseTLPLabel(s string) (csaf.TLPLabel, error) {
+	if s == "" {
+		return csaf.TLPLabelWhite, nil
+	}
+	label := csaf.TLPLabel(strings.ToUpper(s))
+	if !slices.Contains([]csaf.TLPLabel{
+		csaf.TLPLabelWhite,
+		csaf.TLPLabelGreen,
+		csaf.TLPLabelAmber,
+		csaf.TLPLabelRed,
+	}, label) {
+		return "", fmt.Errorf("unknown TLP label %q", s)
+	}
+	return label, nil
+}
+
+// createSimulatedSource starts a fake CSAF provider serving generated documents.
+// Only available if the dev endpoints are enabled.
+func (c *Controller) createSimulatedSource(ctx *gin.Context) {
+	count, ok := parse(ctx, strconv.Atoi, ctx.DefaultPostForm("count", "5"))
+	if !ok {
+		return
+	}
+	if count < 0 || count > maxSimulatedDocuments {
+		models.SendErrorMessage(ctx, http.StatusBadRequest,
+			fmt.Sprintf("count has to be between 0 and %d", maxSimulatedDocuments))
+		return
+	}
+	layout, ok := parse(ctx, parseLayout, ctx.PostForm("layout"))
+	if !ok {
+		return
+	}
+	tlp, ok := parse(ctx, parseTLPLabel, ctx.PostForm("tlp"))
+	if !ok {
+		return
+	}
+	opts := sourcetest.Options{
+		Layout:    layout,
+		Publisher: "Simulated Provider",
+	}
+	for _, flag := range []struct {
+		key   string
+		value *bool
+	}{
+		{"plain_http", &opts.PlainHTTP},
+		{"unsigned", &opts.Unsigned},
+		{"bad_hashes", &opts.BadHashes},
+		{"bad_signatures", &opts.BadSignatures},
+	} {
+		if *flag.value, ok = parse(ctx, strconv.ParseBool, ctx.DefaultPostForm(flag.key, "false")); !ok {
+			return
+		}
+	}
+	provider, err := sourcetest.NewProvider(opts)
+	if err != nil {
+		slog.ErrorContext(ctx, "starting simulated source failed", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	now := time.Now().UTC()
+	for i := 1; i <= count; i++ {
+		doc, err := sourcetest.NewDocument(sourcetest.Document{
+			Publisher:  opts.Publisher,
+			Namespace:  provider.URL(),
+			TrackingID: fmt.Sprintf("SIM-%d-%04d", now.Year(), i),
+			TLP:        tlp,
+			Released:   now.Add(time.Duration(i-count) * time.Minute),
+		})
+		if err == nil {
+			_, err = provider.Add(doc)
+		}
+		if err != nil {
+			provider.Close()
+			slog.ErrorContext(ctx, "generating simulated documents failed", "err", err)
+			models.SendError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	ss := &simulatedSource{provider: provider, created: now}
+	c.simulated.mu.Lock()
+	c.simulated.nextID++
+	id := c.simulated.nextID
+	if c.simulated.providers == nil {
+		c.simulated.providers = map[int64]*simulatedSource{}
+	}
+	c.simulated.providers[id] = ss
+	c.simulated.mu.Unlock()
+	slog.InfoContext(ctx, "simulated source started", "id", id, "url", provider.PMDURL())
+	ctx.JSON(http.StatusCreated, ss.info(id))
+}
+
+// viewSimulatedSources lists the running fake CSAF providers.
+// Only available if the dev endpoints are enabled.
+func (c *Controller) viewSimulatedSources(ctx *gin.Context) {
+	c.simulated.mu.Lock()
+	list := make([]simulatedSourceInfo, 0, len(c.simulated.providers))
+	for id, ss := range c.simulated.providers {
+		list = append(list, ss.info(id))
+	}
+	c.simulated.mu.Unlock()
+	slices.SortFunc(list, func(a, b simulatedSourceInfo) int {
+		return int(a.ID - b.ID)
+	})
+	ctx.JSON(http.StatusOK, list)
+}
+
+// deleteSimulatedSource stops a fake CSAF provider.
+// Only available if the dev endpoints are enabled.
+func (c *Controller) deleteSimulatedSource(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	c.simulated.mu.Lock()
+	ss := c.simulated.providers[id]
+	delete(c.simulated.providers, id)
+	c.simulated.mu.Unlock()
+	if ss == nil {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "simulated source not found")
+		return
+	}
+	ss.provider.Close()
+	models.SendSuccess(ctx, http.StatusOK, "simulated source stopped")
+}
@@ -81,25 +81,33 @@ func (c *Controller) viewDiff(ctx *gin.Context) {
 
 	// Do we need to load docs from database?
 	if len(fromDB) > 0 {
-		tlps := c.tlps(ctx)
-		if len(tlps) == 0 {
-			models.SendErrorMessage(ctx, http.StatusNotFound, "document not found")
-			return
-		}
-		tlpExpr := tlps.AsExpr()
 		if err := c.db.Run(
 			ctx.Request.Context(),
 			func(rctx context.Context, conn *pgxpool.Conn) error {
 				for _, f := range fromDB {
-					expr := query.FieldEqInt("documents.id", f.id).And(tlpExpr)
+					expr := query.FieldEqInt("documents.id", f.id)
 					var b query.SQLBuilder
 					b.CreateWhere(expr)
-					fetchSQL := `SELECT original ` +
+					fetchSQL := `SELECT original, original_key, tlp ` +
 						`FROM documents JOIN advisories ON documents.advisories_id = advisories.id ` +
 						`WHERE ` + b.WhereClause
-					if err := conn.QueryRow(rctx, fetchSQL, b.Replacements...).Scan(f.doc); err != nil {
+					var key, tlp *string
+					if err := conn.QueryRow(rctx, fetchSQL, b.Replacements...).Scan(f.doc, &key, &tlp); err != nil {
 						return fmt.Errorf("fetching data from database failed: %w", err)
 					}
+					// Reading restricted documents has to be accounted.
+					if tlp != nil && models.TLP(*tlp).Restricted() {
+						if _, err := conn.Exec(
+							rctx, logDocumentAccessSQL, ctx.GetString("uid"), downloadAccess, f.id,
+						); err != nil {
+							return fmt.Errorf("logging document access failed: %w", err)
+						}
+					}
+					original, err := c.bs.Original(rctx, *f.doc, key)
+					if err != nil {
+						return fmt.Errorf("fetching data from blob storage failed: %w", err)
+					}
+					*f.doc = original
 				}
 				return nil
 			}, 0,
@@ -107,7 +115,7 @@ func (c *Controller) viewDiff(ctx *gin.Context) {
 			if errors.Is(err, pgx.ErrNoRows) {
 				models.SendErrorMessage(ctx, http.StatusNotFound, "document not found")
 			} else {
-				slog.Error("database error", "err", err)
+				slog.ErrorContext(ctx, "database error", "err", err)
 				models.SendError(ctx, http.StatusInternalServerError, err)
 			}
 			return
@@ -123,13 +131,13 @@ func (c *Controller) viewDiff(ctx *gin.Context) {
 			models.SendError(ctx, http.StatusNotFound, err)
 			return
 		case err != nil:
-			slog.Error("temp store fetch error", "err", err)
+			slog.ErrorContext(ctx, "temp store fetch error", "err", err)
 			models.SendError(ctx, http.StatusInternalServerError, err)
 			return
 		}
 		data := make([]byte, int(entry.Length))
 		if _, err := io.ReadFull(r, data); err != nil {
-			slog.Error("temp store read error", "err", err)
+			slog.ErrorContext(ctx, "temp store read error", "err", err)
 			models.SendError(ctx, http.StatusInternalServerError, err)
 			return
 		}
@@ -139,7 +147,7 @@ func (c *Controller) viewDiff(ctx *gin.Context) {
 	// Create the patch.
 	patch, err := jsonpatch.CreatePatch(doc[0], doc[1])
 	if err != nil {
-		slog.Error("creating patch failed", "err", err)
+		slog.ErrorContext(ctx, "creating patch failed", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -153,7 +161,7 @@ func (c *Controller) viewDiff(ctx *gin.Context) {
 			}
 			var d1 any
 			if err := json.Unmarshal(doc[0], &d1); err != nil {
-				slog.Error("unmarshaling failed", "err", err)
+				slog.ErrorContext(ctx, "unmarshaling failed", "err", err)
 				models.SendError(ctx, http.StatusInternalServerError, err)
 				return
 			}
@@ -180,7 +188,7 @@ func (c *Controller) viewDiff(ctx *gin.Context) {
 	// Calculate word diff for "replace" operations.
 	var d1 any
 	if err := json.Unmarshal(doc[0], &d1); err != nil {
-		slog.Error("unmarshaling failed", "err", err)
+		slog.ErrorContext(ctx, "unmarshaling failed", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
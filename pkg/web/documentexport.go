@@ -0,0 +1,205 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/database/query"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/xlsx"
+)
+
+// rowWriter writes the rows of an export.
+type rowWriter interface {
+	WriteHeader([]string) error
+	WriteRow([]any) error
+	Close() error
+}
+
+// documentExporters write the query results in the different export formats.
+var documentExporters = map[string]struct {
+	contentType string
+	newWriter   func(io.Writer, string) (rowWriter, error)
+}{
+	"csv": {"text/csv; charset=utf-8", newCSVRowWriter},
+	"xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		func(w io.Writer, sheet string) (rowWriter, error) { return xlsx.NewWriter(w, sheet) }},
+}
+
+// csvRowWriter writes the rows as CSV.
+type csvRowWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVRowWriter(w io.Writer, _ string) (rowWriter, error) {
+	return &csvRowWriter{w: csv.NewWriter(w)}, nil
+}
+
+func (cw *csvRowWriter) WriteHeader(names []string) error {
+	return cw.w.Write(names)
+}
+
+func (cw *csvRowWriter) WriteRow(cells []any) error {
+	cw.record = cw.record[:0]
+	for _, cell := range cells {
+		switch v := cell.(type) {
+		case nil:
+			cw.record = append(cw.record, "")
+		case int64:
+			cw.record = append(cw.record, strconv.FormatInt(v, 10))
+		case float64:
+			cw.record = append(cw.record, strconv.FormatFloat(v, 'g', -1, 64))
+		case string:
+			cw.record = append(cw.record, csvText(v))
+		default:
+			cw.record = append(cw.record, csvText(fmt.Sprint(v)))
+		}
+	}
+	return cw.w.Write(cw.record)
+}
+
+func (cw *csvRowWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// csvText prevents texts from being evaluated as formulas
+// when the CSV file is opened in a spreadsheet application.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// exportCell converts a value of a result column into a number,
+// a text or nil.
+func exportCell(v any) any {
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case int16:
+		return int64(x)
+	case int32:
+		return int64(x)
+	case int64:
+		return x
+	case int:
+		return int64(x)
+	case float32:
+		return float64(x)
+	case float64:
+		return x
+	case string:
+		return x
+	case bool:
+		return strconv.FormatBool(x)
+	case time.Time:
+		return x.UTC().Format(time.RFC3339)
+	case []any:
+		parts := make([]string, 0, len(x))
+		for _, e := range x {
+			if e = exportCell(e); e != nil {
+				parts = append(parts, fmt.Sprint(e))
+			}
+		}
+		return strings.Join(parts, ", ")
+	case fmt.Stringer:
+		return x.String()
+	default:
+		if data, err := json.Marshal(x); err == nil {
+			return string(data)
+		}
+		return fmt.Sprint(x)
+	}
+}
+
+// exportResults streams the results of the builder in the given format.
+func (c *Controller) exportResults(
+	ctx *gin.Context,
+	format string,
+	advisory bool,
+	limit, offset int64,
+	builder *query.AdvancedSQLBuilder,
+) {
+	exporter := documentExporters[format]
+	name := "documents"
+	if advisory {
+		name = "advisories"
+	}
+	fields := builder.Fields()
+	started := false
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			sql := builder.CreateQuery(limit, offset)
+			if slog.Default().Enabled(rctx, slog.LevelDebug) {
+				slog.DebugContext(ctx, "export", "SQL", query.InterpolateSQLqnd(sql, builder.Replacements))
+			}
+			rows, err := conn.Query(rctx, sql, builder.Replacements...)
+			if err != nil {
+				return fmt.Errorf("cannot fetch results: %w", err)
+			}
+			defer rows.Close()
+
+			started = true
+			ctx.Header("Content-Type", exporter.contentType)
+			ctx.Header("Content-Disposition",
+				fmt.Sprintf("attachment; filename=\"%s.%s\"", name, format))
+			ctx.Status(http.StatusOK)
+
+			out, err := exporter.newWriter(ctx.Writer, name)
+			if err != nil {
+				return err
+			}
+			if err := out.WriteHeader(fields); err != nil {
+				return err
+			}
+			cells := make([]any, len(fields))
+			for rows.Next() {
+				values, err := rows.Values()
+				if err != nil {
+					return fmt.Errorf("scanning row failed: %w", err)
+				}
+				for i, v := range values {
+					cells[i] = exportCell(v)
+				}
+				if err := out.WriteRow(cells); err != nil {
+					return err
+				}
+			}
+			if err := rows.Err(); err != nil {
+				return fmt.Errorf("scanning failed: %w", err)
+			}
+			return out.Close()
+		},
+		c.cfg.Database.MaxQueryDuration, // In case the user provided a very expensive query.
+	); err != nil {
+		if started {
+			slog.ErrorContext(ctx, "exporting documents failed", "err", err)
+			return
+		}
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+	}
+}
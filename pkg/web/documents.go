@@ -20,6 +20,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gocsaf/csaf/v3/csaf"
@@ -27,8 +28,13 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/ISDuBA/ISDuBA/pkg/database"
 	"github.com/ISDuBA/ISDuBA/pkg/database/query"
+	"github.com/ISDuBA/ISDuBA/pkg/eventbus"
+	"github.com/ISDuBA/ISDuBA/pkg/forwarder"
 	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/scanner"
+	"github.com/ISDuBA/ISDuBA/pkg/transform"
 )
 
 // MinSearchLength enforces a minimal length of search phrases.
@@ -44,6 +50,7 @@ const MinSearchLength = 2 // Makes at least "Go" searchable ;-)
 //	@Failure		400	{object}	models.Error
 //	@Failure		401
 //	@Failure		404	{object}	models.Error
+//	@Failure		409	{object}	models.Error	"under legal hold"
 //	@Failure		500	{object}	models.Error
 //	@Router			/documents/{id} [delete]
 func (c *Controller) deleteDocument(ctx *gin.Context) {
@@ -56,12 +63,15 @@ func (c *Controller) deleteDocument(ctx *gin.Context) {
 	// FieldEqInt is a shortcut mainly for building expressions
 	// accessing an integer column like 'id's.
 	// Expr encapsulates a parsed expression to be converted to an SQL WHERE clause.
-	expr := c.andTLPExpr(ctx, query.FieldEqInt("id", docID))
+	expr := query.FieldEqInt("id", docID)
 
 	builder := query.SQLBuilder{}
 	builder.CreateWhere(expr)
 
-	deleted := false
+	var (
+		deleted bool
+		evs     []eventbus.Event
+	)
 
 	if err := c.db.Run(
 		ctx.Request.Context(),
@@ -72,9 +82,36 @@ func (c *Controller) deleteDocument(ctx *gin.Context) {
 			}
 			defer tx.Rollback(rctx)
 
+			const selectPrefix = `SELECT id FROM documents WHERE `
+			rows, _ := tx.Query(rctx, selectPrefix+builder.WhereClause, builder.Replacements...)
+			ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+			if err != nil {
+				return fmt.Errorf("selecting documents failed: %w", err)
+			}
+			if err := models.CheckLegalHolds(rctx, tx, ids); err != nil {
+				return err
+			}
+
+			// Remember the details for the announcement of the deletion.
+			const detailsSQL = `SELECT docs.id, ads.publisher, ads.tracking_id, docs.version, docs.tlp ` +
+				`FROM documents docs JOIN advisories ads ON docs.advisories_id = ads.id ` +
+				`WHERE docs.id = ANY($1)`
+			rows, _ = tx.Query(rctx, detailsSQL, ids)
+			if evs, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (eventbus.Event, error) {
+				ev := c.documentEvent(ctx, models.DeleteDocumentEvent, 0, "")
+				var tlp *string
+				err := row.Scan(&ev.DocumentID, &ev.Publisher, &ev.TrackingID, &ev.Version, &tlp)
+				if tlp != nil {
+					ev.TLP = *tlp
+				}
+				return ev, err
+			}); err != nil {
+				return fmt.Errorf("loading document details failed: %w", err)
+			}
+
 			const deletePrefix = `DELETE FROM documents WHERE `
 			deleteSQL := deletePrefix + builder.WhereClause
-			slog.Debug("delete document", "SQL",
+			slog.DebugContext(ctx, "delete document", "SQL",
 				query.InterpolateSQLqnd(deleteSQL, builder.Replacements))
 
 			tags, err := tx.Exec(rctx, deleteSQL, builder.Replacements...)
@@ -95,11 +132,16 @@ func (c *Controller) deleteDocument(ctx *gin.Context) {
 			return tx.Commit(rctx)
 		}, 0,
 	); err != nil {
-		slog.Error("database error", "err", err)
+		if errors.Is(err, models.ErrLegalHold) {
+			models.SendErrorMessage(ctx, http.StatusConflict, "document is under legal hold")
+			return
+		}
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 	if deleted {
+		c.publishEvents(evs)
 		models.SendSuccess(ctx, http.StatusOK, "document deleted")
 	} else {
 		models.SendErrorMessage(ctx, http.StatusNotFound, "document not found")
@@ -160,11 +202,23 @@ func (c *Controller) importDocument(ctx *gin.Context) {
 		return
 	}
 
+	if err := c.sc.Scan(ctx.Request.Context(), file.Filename, bytes.NewReader(buf.Bytes())); err != nil {
+		if ie := (*scanner.InfectedError)(nil); errors.As(err, &ie) {
+			slog.WarnContext(ctx, "rejected upload with malware",
+				"user", ctx.GetString("uid"), "file", file.Filename, "threat", ie.Threat)
+			models.SendError(ctx, http.StatusBadRequest, err)
+		} else {
+			slog.ErrorContext(ctx, "scanning upload failed", "err", err)
+			models.SendError(ctx, http.StatusBadGateway, err)
+		}
+		return
+	}
+
 	// Is remote validator configured?
 	if c.val != nil {
 		rvr, err := c.val.Validate(document)
 		if err != nil {
-			slog.Error("remote validation failed", "err", err)
+			slog.ErrorContext(ctx, "remote validation failed", "err", err)
 			models.SendErrorMessage(ctx, http.StatusInternalServerError,
 				"remote validation failed: "+err.Error())
 			return
@@ -176,6 +230,13 @@ func (c *Controller) importDocument(ctx *gin.Context) {
 		}
 	}
 
+	// Normalize the known quirks of the provider.
+	changes, err := c.tf.Transform(document)
+	if err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
 	// Store stats in database.
 	storeStats := func(ctx context.Context, tx pgx.Tx, docID int64, duplicate bool) error {
 		if duplicate {
@@ -194,19 +255,25 @@ func (c *Controller) importDocument(ctx *gin.Context) {
 			id, err = models.ImportDocumentData(
 				rctx, conn, document, buf.Bytes(),
 				actor, c.tlps(ctx),
-				models.ChainInTx(storeStats, models.StoreFilename(file.Filename)),
+				models.ChainInTx(
+					storeStats,
+					models.StoreFilename(file.Filename),
+					transform.Store(changes),
+					c.bs.Offload(buf.Bytes())),
 				false)
 			return err
 		}, 0,
 	); {
 	case err == nil:
+		c.publishEvents([]eventbus.Event{
+			c.documentEvent(ctx, models.ImportDocumentEvent, id, models.NewWorkflow)})
 		ctx.JSON(http.StatusCreated, models.ID{ID: id})
 	case errors.Is(err, models.ErrAlreadyInDatabase):
 		models.SendErrorMessage(ctx, http.StatusConflict, "already in database")
 	case errors.Is(err, models.ErrNotAllowed):
 		models.SendErrorMessage(ctx, http.StatusForbidden, "wrong publisher/tlp")
 	default:
-		slog.Error("storing document failed", "err", err)
+		slog.ErrorContext(ctx, "storing document failed", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 	}
 }
@@ -215,9 +282,13 @@ func (c *Controller) importDocument(ctx *gin.Context) {
 //
 //	@Summary		Returns the document.
 //	@Description	Returns the document in its original format.
+//	@Description	The language of the document is reported in the Content-Language header,
+//	@Description	the languages of all documents of the advisory in X-Advisory-Languages.
 //	@Param			id	path	int	true	"Document ID"
 //	@Produce		json
 //	@Success		200	{object}	any
+//	@Header			200	{string}	Content-Language		"Language of the document"
+//	@Header			200	{string}	X-Advisory-Languages	"Languages of the advisory"
 //	@Failure		400	{object}	models.Error	"could not parse id"
 //	@Failure		401
 //	@Failure		404	{object}	models.Error	"document not found"
@@ -229,32 +300,54 @@ func (c *Controller) viewDocument(ctx *gin.Context) {
 		return
 	}
 
-	expr := c.andTLPExpr(ctx, query.FieldEqInt("id", id))
+	expr := query.FieldEqInt("id", id)
 
-	fields := []string{"original", "filename"}
+	fields := []string{"original", "original_key", "filename", "lang", "languages", "tlp"}
 	builder := query.SQLBuilder{}
 	builder.CreateWhere(expr)
 	sql := builder.CreateQuery(fields, "", -1, -1)
 
 	var original []byte
+	var key *string
 	var filename string
+	var lang, languages, tlp *string
 
 	if err := c.db.Run(
 		ctx.Request.Context(),
 		func(rctx context.Context, conn *pgxpool.Conn) error {
-			return conn.QueryRow(rctx, sql, builder.Replacements...).
-				Scan(&original, &filename)
+			if err := conn.QueryRow(rctx, sql, builder.Replacements...).
+				Scan(&original, &key, &filename, &lang, &languages, &tlp); err != nil {
+				return err
+			}
+			// Viewing a document marks it as read.
+			if _, err := conn.Exec(rctx, markReadSQL, id, ctx.GetString("uid")); err != nil {
+				return err
+			}
+			// Downloads of restricted documents have to be accounted.
+			if tlp == nil || !models.TLP(*tlp).Restricted() {
+				return nil
+			}
+			actor := ctx.GetString("uid")
+			_, err := conn.Exec(rctx, logDocumentAccessSQL, actor, downloadAccess, id)
+			return err
 		}, 0,
 	); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			models.SendErrorMessage(ctx, http.StatusNotFound, "document not found")
 		} else {
-			slog.Error("database error", "err", err)
+			slog.ErrorContext(ctx, "database error", "err", err)
 			models.SendError(ctx, http.StatusInternalServerError, err)
 		}
 		return
 	}
 
+	original, err := c.bs.Original(ctx.Request.Context(), original, key)
+	if err != nil {
+		slog.ErrorContext(ctx, "loading original document failed", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
 	if filename == "" {
 		filename = "document.json"
 	} else {
@@ -263,6 +356,14 @@ func (c *Controller) viewDocument(ctx *gin.Context) {
 
 	extraHeaders := map[string]string{
 		"Content-Disposition": fmt.Sprintf("attachment; filename=\"%s\"", filename),
+		// The annotations to highlight are fetched separately.
+		"Link": fmt.Sprintf("<%d/annotations>; rel=\"annotations\"", id),
+	}
+	if lang != nil && *lang != "" {
+		extraHeaders["Content-Language"] = *lang
+	}
+	if languages != nil {
+		extraHeaders["X-Advisory-Languages"] = *languages
 	}
 
 	ctx.DataFromReader(
@@ -288,6 +389,7 @@ func (c *Controller) viewForwardTargets(ctx *gin.Context) {
 //
 //	@Summary		Forwards a document.
 //	@Description	Forwards a document to the specified target.
+//	@Description	Documents not matching the rules of the target are refused.
 //	@Param			id		path	int	true	"Document ID"
 //	@Param			target	path	int	true	"Target ID"
 //	@Produce		json
@@ -296,6 +398,7 @@ func (c *Controller) viewForwardTargets(ctx *gin.Context) {
 //	@Failure		401
 //	@Failure		404	{object}	models.Error
 //	@Failure		500	{object}	models.Error
+//	@Failure		503	{object}	models.Error
 //	@Router			/documents/forward/{id}/{target} [post]
 func (c *Controller) forwardDocument(ctx *gin.Context) {
 	id, ok := parse(ctx, toInt64, ctx.Param("id"))
@@ -308,7 +411,7 @@ func (c *Controller) forwardDocument(ctx *gin.Context) {
 		return
 	}
 
-	expr := c.andTLPExpr(ctx, query.FieldEqInt("id", id))
+	expr := query.FieldEqInt("id", id)
 
 	fields := []string{"id"}
 	builder := query.SQLBuilder{}
@@ -331,13 +434,68 @@ func (c *Controller) forwardDocument(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.fm.ForwardDocument(ctx.Request.Context(), int(targetID), documentID); err != nil {
+	switch err := c.fm.ForwardDocument(
+		ctx.Request.Context(), targetID, documentID, c.currentUser(ctx),
+	); {
+	case errors.Is(err, forwarder.ErrTargetDown):
+		models.SendError(ctx, http.StatusServiceUnavailable, err)
+		return
+	case errors.Is(err, forwarder.ErrRulesNotMatched):
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	case err != nil:
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, models.ID{ID: documentID})
 }
 
+// testForwardTarget is an endpoint that tests the delivery to a forward target.
+//
+//	@Summary		Tests a forward target.
+//	@Description	Sends a synthetic CSAF document in draft status to the specified
+//	@Description	target and returns the round-trip result. The request is marked
+//	@Description	with the X-ISDuBA-Test header. Automatic targets can be tested, too.
+//	@Description	The target ID is the one listed at /forwarder/targets.
+//	@Param			target	path	int	true	"Target ID"
+//	@Produce		json
+//	@Success		200	{object}	models.DeliveryResult
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Router			/forwarder/targets/{target}/test [post]
+func (c *Controller) testForwardTarget(ctx *gin.Context) {
+	targetID, ok := parse(ctx, toInt64, ctx.Param("target"))
+	if !ok {
+		return
+	}
+	result, err := c.fm.TestTarget(ctx.Request.Context(), targetID)
+	if err != nil {
+		models.SendError(ctx, http.StatusNotFound, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// viewForwarderTargets is an endpoint that returns the health of the forward targets.
+//
+//	@Summary		Returns the health of the forward targets.
+//	@Description	Returns the state of the circuit breaker of all forward targets,
+//	@Description	the last health check and the number of pending documents.
+//	@Produce		json
+//	@Success		200	{array}		forwarder.TargetStatus
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/forwarder/targets [get]
+func (c *Controller) viewForwarderTargets(ctx *gin.Context) {
+	statuses, err := c.fm.TargetStatuses(ctx.Request.Context())
+	if err != nil {
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, statuses)
+}
+
 // overviewDocuments is an end point to return an overview document.
 //
 //	@Summary		Returns documents.
@@ -347,10 +505,18 @@ func (c *Controller) forwardDocument(ctx *gin.Context) {
 //	@Param			columns		query	string	false	"Columns"
 //	@Param			orders		query	string	false	"Ordering"
 //	@Param			count		query	bool	false	"Enable counting"
+//	@Param			estimate	query	bool	false	"Return the estimated number of matching documents"
+//	@Param			has_more	query	bool	false	"Return if there are more documents after the limit"
 //	@Param			limit		query	int		false	"Maximum documents"
 //	@Param			offset		query	int		false	"Offset"
 //	@Param			results		query	bool	false	"Return search results"
+//	@Param			as_of		query	string	false	"Evaluate workflow states and SSVC values as of this time"
+//	@Param			severities	query	bool	false	"Count the matching documents per severity"
+//	@Param			scope		query	string	false	"Texts searched by search terms: documents, comments, events (comma separated)"
+//	@Param			format		query	string	false	"json, csv or xlsx, defaults to json"
 //	@Produce		json
+//	@Produce		text/csv
+//	@Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 //	@Success		200	{object}	web.flatResults.documentResult
 //	@Failure		400	{object}	models.Error
 //	@Failure		401
@@ -386,9 +552,6 @@ func (c *Controller) overviewDocuments(ctx *gin.Context) {
 		return
 	}
 
-	// Filter the allowed
-	expr = c.andTLPExpr(ctx, expr)
-
 	// In advisory mode we only show the latest.
 	if advisory {
 		expr = expr.And(query.BoolField("latest"))
@@ -413,23 +576,81 @@ func (c *Controller) overviewDocuments(ctx *gin.Context) {
 		fields = append(fields, "id")
 	}
 
+	// Reports may need the situation at a given time.
+	var asOf *time.Time
+	if value := ctx.Query("as_of"); value != "" {
+		t, ok := parse(ctx, parseTime, value)
+		if !ok {
+			return
+		}
+		asOf = &t
+	}
+
+	// The results can be downloaded as spreadsheets.
+	format := strings.ToLower(ctx.DefaultQuery("format", "json"))
+	if _, found := documentExporters[format]; !found && format != "json" {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "format has to be json, csv or xlsx")
+		return
+	}
+	export := format != "json"
+	if export && aggregate {
+		models.SendErrorMessage(ctx, http.StatusBadRequest,
+			"'format' "+format+" cannot be combined with 'aggregate'")
+		return
+	}
+
+	// Analysts may search their comments and the event log, too.
+	scope, ok := parse(ctx, query.ParseSearchScope, ctx.DefaultQuery("scope", "documents"))
+	if !ok {
+		return
+	}
+
 	builder, err := query.NewAdvancedSQLBuilder(
 		query.AdvancedSQLBuilderExpr(expr),
 		query.AdvancedSQLBuilderOrderFields(orderFields),
 		query.AdvancedSQLBuilderFields(fields),
 		query.AdvancedSQLBuilderParser(&parser),
-		query.AdvancedSQLBuilderAggregate(aggregate))
+		query.AdvancedSQLBuilderAggregate(aggregate),
+		query.AdvancedSQLBuilderAsOf(asOf),
+		query.AdvancedSQLBuilderSearchScope(scope))
 
 	if err != nil {
 		models.SendError(ctx, http.StatusBadRequest, err)
 		return
 	}
+	c.recordQuery(ctx, advisory)
 
 	var (
 		calcCount           = ctx.Query("count") != ""
 		limit, offset int64 = -1, -1
 	)
 
+	// The severity buckets are counted over all matching documents.
+	severities, ok := parse(ctx, strconv.ParseBool, ctx.DefaultQuery("severities", "false"))
+	if !ok {
+		return
+	}
+	if severities && aggregate {
+		models.SendErrorMessage(ctx, http.StatusBadRequest,
+			"'severities' cannot be combined with 'aggregate'")
+		return
+	}
+
+	// Paging UIs may skip the exact count which is expensive on large sets.
+	estimate, ok := parse(ctx, strconv.ParseBool, ctx.DefaultQuery("estimate", "false"))
+	if !ok {
+		return
+	}
+	hasMore, ok := parse(ctx, strconv.ParseBool, ctx.DefaultQuery("has_more", "false"))
+	if !ok {
+		return
+	}
+	if (estimate || hasMore) && aggregate {
+		models.SendErrorMessage(ctx, http.StatusBadRequest,
+			"'estimate' and 'has_more' cannot be combined with 'aggregate'")
+		return
+	}
+
 	if lim := ctx.Query("limit"); lim != "" {
 		if limit, ok = parse(ctx, toInt64, lim); !ok {
 			return
@@ -442,34 +663,58 @@ func (c *Controller) overviewDocuments(ctx *gin.Context) {
 		}
 	}
 
-	deliver := (*Controller).flatResults
-	if aggregate {
-		deliver = (*Controller).aggregatedResults
+	// Exports are streamed and not cached.
+	if export {
+		c.exportResults(ctx, format, advisory, limit, offset, builder)
+		return
 	}
-	deliver(c, ctx, calcCount, limit, offset, builder)
+
+	// The visibility of the documents depends on the TLP rules of the user.
+	rules, _ := database.TLPRules(ctx.Request.Context())
+	sql := builder.CreateQuery(limit, offset)
+	key := c.qc.ReadsKey(slices.Collect(expr.Readers()),
+		aggregate, calcCount, severities, estimate, hasMore, sql, builder.Replacements, rules)
+	c.cachedSearch(ctx, key, func() {
+		if aggregate {
+			c.aggregatedResults(ctx, calcCount, limit, offset, builder)
+		} else {
+			c.flatResults(ctx, calcCount, estimate, hasMore, severities, limit, offset, builder)
+		}
+	})
 }
 
 func (c *Controller) flatResults(
 	ctx *gin.Context,
-	calcCount bool,
+	calcCount, estimate, hasMore, severities bool,
 	limit, offset int64,
 	builder *query.AdvancedSQLBuilder,
 ) {
 	type documentResult struct {
-		Count     *int64           `json:"count,omitempty"`
-		Documents []map[string]any `json:"documents"`
+		Count          *int64           `json:"count,omitempty"`
+		EstimatedTotal *int64           `json:"estimated_total,omitempty"`
+		HasMore        *bool            `json:"has_more,omitempty"`
+		Severities     map[string]int64 `json:"severities,omitempty"`
+		Documents      []map[string]any `json:"documents"`
 	}
 	var (
-		results []map[string]any
-		count   int64
+		results   []map[string]any
+		count     int64
+		estimated int64
+		more      bool
+		buckets   map[string]int64
 	)
+	// To find out if there are more documents one more is fetched.
+	fetch := limit
+	if hasMore && limit >= 0 {
+		fetch++
+	}
 	if err := c.db.Run(
 		ctx.Request.Context(),
 		func(rctx context.Context, conn *pgxpool.Conn) error {
 			if calcCount {
 				countSQL := builder.CreateCountSQL()
 				if slog.Default().Enabled(rctx, slog.LevelDebug) {
-					slog.Debug("count", "SQL", query.InterpolateSQLqnd(countSQL, builder.Replacements))
+					slog.DebugContext(ctx, "count", "SQL", query.InterpolateSQLqnd(countSQL, builder.Replacements))
 				}
 				if err := conn.QueryRow(
 					rctx,
@@ -479,15 +724,31 @@ func (c *Controller) flatResults(
 					return fmt.Errorf("cannot calculate count %w", err)
 				}
 			}
+			if estimate {
+				var err error
+				if estimated, err = database.EstimateCount(
+					rctx, conn,
+					builder.CreateQuery(-1, -1),
+					builder.Replacements...,
+				); err != nil {
+					return fmt.Errorf("cannot estimate count: %w", err)
+				}
+			}
+			if severities {
+				var err error
+				if buckets, err = severityBuckets(rctx, conn, builder); err != nil {
+					return fmt.Errorf("cannot count severities: %w", err)
+				}
+			}
 			// Skip fields if they are not requested.
 			if !builder.HasFields() {
 				return nil
 			}
 
-			sql := builder.CreateQuery(limit, offset)
+			sql := builder.CreateQuery(fetch, offset)
 
 			if slog.Default().Enabled(rctx, slog.LevelDebug) {
-				slog.Debug("documents", "SQL", query.InterpolateSQLqnd(sql, builder.Replacements))
+				slog.DebugContext(ctx, "documents", "SQL", query.InterpolateSQLqnd(sql, builder.Replacements))
 			}
 			rows, err := conn.Query(rctx, sql, builder.Replacements...)
 			if err != nil {
@@ -497,25 +758,62 @@ func (c *Controller) flatResults(
 			if results, err = scanRows(rows, builder.Fields()); err != nil {
 				return fmt.Errorf("loading data failed: %w", err)
 			}
+			if fetch > limit && int64(len(results)) > limit {
+				results, more = results[:limit], true
+			}
 			return nil
 		},
 		c.cfg.Database.MaxQueryDuration, // In case the user provided a very expensive query.
 	); err != nil {
-		slog.Error("database error", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
-	h := documentResult{}
+	h := documentResult{Severities: buckets}
 	if calcCount {
 		h.Count = &count
 	}
+	if estimate {
+		h.EstimatedTotal = &estimated
+	}
+	if hasMore {
+		h.HasMore = &more
+	}
 	if len(results) > 0 {
 		h.Documents = results
 	}
 	ctx.JSON(http.StatusOK, h)
 }
 
+// severityBuckets counts the documents matching the filter of
+// the builder per severity. Empty buckets are included.
+func severityBuckets(
+	ctx context.Context,
+	conn *pgxpool.Conn,
+	builder *query.AdvancedSQLBuilder,
+) (map[string]int64, error) {
+	buckets := map[string]int64{
+		"none": 0, "low": 0, "medium": 0, "high": 0, "critical": 0,
+	}
+	countSQL := builder.CreateGroupCountSQL("severity")
+	if slog.Default().Enabled(ctx, slog.LevelDebug) {
+		slog.DebugContext(ctx, "severities", "SQL", query.InterpolateSQLqnd(countSQL, builder.Replacements))
+	}
+	var (
+		severity string
+		count    int64
+	)
+	rows, _ := conn.Query(ctx, countSQL, builder.Replacements...)
+	if _, err := pgx.ForEachRow(rows, []any{&severity, &count}, func() error {
+		buckets[severity] = count
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return buckets, nil
+}
+
 // scanRows turns a result set into a slice of maps.
 func scanRows(
 	rows pgx.Rows,
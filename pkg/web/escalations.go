@@ -0,0 +1,402 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/sla"
+)
+
+const (
+	// defaultEscalationsLimit is the page size if no limit is given.
+	defaultEscalationsLimit = 100
+	// maxEscalationsLimit is the largest page size.
+	maxEscalationsLimit = 1000
+)
+
+// slaLimitsSQL is the table of the configured limits in seconds per state.
+const slaLimitsSQL = `WITH limits (state, seconds) AS (` +
+	`SELECT * FROM unnest($1::text[], $2::float8[])) `
+
+// overdueAdvisory is an advisory staying longer in its state than allowed.
+type overdueAdvisory struct {
+	Publisher  string          `json:"publisher"`
+	TrackingID string          `json:"tracking_id"`
+	DocumentID int64           `json:"document_id"`
+	State      models.Workflow `json:"state"`
+	Entered    time.Time       `json:"entered"`
+	Deadline   time.Time       `json:"deadline"`
+}
+
+// viewOverdueAdvisories is an endpoint that returns the overdue advisories.
+//
+//	@Summary		Returns the overdue advisories.
+//	@Description	Returns the advisories which stay longer in their workflow state
+//	@Description	than allowed by the configured limits, the most overdue first.
+//	@Param			within	query	string	false	"Also return the advisories due within this duration"
+//	@Produce		json
+//	@Success		200	{array}		overdueAdvisory
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/sla/overdue [get]
+func (c *Controller) viewOverdueAdvisories(ctx *gin.Context) {
+	var within time.Duration
+	if value := ctx.Query("within"); value != "" {
+		var ok bool
+		if within, ok = parse(ctx, time.ParseDuration, value); !ok {
+			return
+		}
+		if within < 0 {
+			models.SendErrorMessage(ctx, http.StatusBadRequest, "within must not be negative")
+			return
+		}
+	}
+	const overdueSQL = slaLimitsSQL +
+		`SELECT ads.publisher, ads.tracking_id, docs.id, sh.state::text, sh.valid_from, ` +
+		`sh.valid_from + limits.seconds * interval '1 second' AS deadline ` +
+		`FROM state_history sh ` +
+		`JOIN limits ON sh.state::text = limits.state ` +
+		`JOIN advisories ads ON sh.advisories_id = ads.id ` +
+		`JOIN documents docs ON docs.advisories_id = ads.id AND docs.latest ` +
+		`WHERE sh.valid_to IS NULL ` +
+		`AND sh.valid_from + limits.seconds * interval '1 second' < current_timestamp + $3::float8 * interval '1 second' ` +
+		`ORDER BY deadline`
+	states, seconds := sla.Limits(&c.cfg.SLA)
+	var overdue []overdueAdvisory
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, overdueSQL, states, seconds, within.Seconds())
+			var err error
+			overdue, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (overdueAdvisory, error) {
+				var (
+					oa    overdueAdvisory
+					state string
+				)
+				err := row.Scan(
+					&oa.Publisher, &oa.TrackingID, &oa.DocumentID,
+					&state, &oa.Entered, &oa.Deadline)
+				oa.State = models.Workflow(state)
+				oa.Entered, oa.Deadline = oa.Entered.UTC(), oa.Deadline.UTC()
+				return oa, err
+			})
+			return err
+		}, c.cfg.Database.MaxQueryDuration,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if overdue == nil {
+		overdue = []overdueAdvisory{}
+	}
+	ctx.JSON(http.StatusOK, overdue)
+}
+
+// slaEscalation is a recorded escalation of an overdue advisory.
+type slaEscalation struct {
+	ID         int64           `json:"id"`
+	Publisher  string          `json:"publisher"`
+	TrackingID string          `json:"tracking_id"`
+	DocumentID int64           `json:"document_id"`
+	State      models.Workflow `json:"state"`
+	Entered    time.Time       `json:"entered"`
+	Deadline   time.Time       `json:"deadline"`
+	Escalated  time.Time       `json:"escalated"`
+	Left       *time.Time      `json:"left,omitempty"`
+}
+
+// viewEscalations is an endpoint that returns the recorded escalations.
+//
+//	@Summary		Returns the recorded escalations.
+//	@Description	Returns the escalations of advisories which stayed longer in a
+//	@Description	workflow state than allowed, newest first. Escalations of advisories
+//	@Description	which already left the state carry the time they left it.
+//	@Param			state		query	string	false	"Workflow state"
+//	@Param			publisher	query	string	false	"Publisher"
+//	@Param			open		query	bool	false	"Only escalations of advisories still or no longer in the state"
+//	@Param			from		query	string	false	"Escalated after"
+//	@Param			to			query	string	false	"Escalated before"
+//	@Param			limit		query	int		false	"Maximum number of entries"
+//	@Param			offset		query	int		false	"Number of entries to skip"
+//	@Param			count		query	bool	false	"Also return the number of matching entries"
+//	@Produce		json
+//	@Success		200	{object}	web.viewEscalations.escalations
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/sla/escalations [get]
+func (c *Controller) viewEscalations(ctx *gin.Context) {
+	var (
+		values []any
+		conds  []string
+		ok     bool
+	)
+	add := func(cond string, value any) {
+		values = append(values, value)
+		conds = append(conds, strings.ReplaceAll(cond, "$?", "$"+strconv.Itoa(len(values))))
+	}
+	if state := ctx.Query("state"); state != "" {
+		switch models.Workflow(state) {
+		case models.NewWorkflow, models.ReadWorkflow,
+			models.AssessingWorkflow, models.ReviewWorkflow:
+		default:
+			models.SendErrorMessage(ctx, http.StatusBadRequest,
+				fmt.Sprintf("unknown state %q", state))
+			return
+		}
+		add(`se.state = $?::workflow`, state)
+	}
+	if publisher := ctx.Query("publisher"); publisher != "" {
+		add(`ads.publisher = $?`, publisher)
+	}
+	if value := ctx.Query("open"); value != "" {
+		open, ok := parse(ctx, strconv.ParseBool, value)
+		if !ok {
+			return
+		}
+		add(`(sh.valid_to IS NULL) = $?`, open)
+	}
+	if from := ctx.Query("from"); from != "" {
+		t, ok := parse(ctx, parseTime, from)
+		if !ok {
+			return
+		}
+		add(`se.escalated >= $?`, t)
+	}
+	if to := ctx.Query("to"); to != "" {
+		t, ok := parse(ctx, parseTime, to)
+		if !ok {
+			return
+		}
+		add(`se.escalated <= $?`, t)
+	}
+
+	var limit, offset int64 = defaultEscalationsLimit, 0
+	if lim := ctx.Query("limit"); lim != "" {
+		if limit, ok = parse(ctx, toInt64, lim); !ok {
+			return
+		}
+		if limit < 1 || limit > maxEscalationsLimit {
+			models.SendErrorMessage(ctx, http.StatusBadRequest,
+				fmt.Sprintf("limit has to be between 1 and %d", maxEscalationsLimit))
+			return
+		}
+	}
+	if ofs := ctx.Query("offset"); ofs != "" {
+		if offset, ok = parse(ctx, toInt64, ofs); !ok {
+			return
+		}
+		if offset < 0 {
+			models.SendErrorMessage(ctx, http.StatusBadRequest, "offset must not be negative")
+			return
+		}
+	}
+	calcCount := ctx.Query("count") != ""
+
+	const fromSQL = `FROM sla_escalations se ` +
+		`JOIN advisories ads ON se.advisories_id = ads.id ` +
+		`JOIN documents docs ON docs.advisories_id = ads.id AND docs.latest ` +
+		`LEFT JOIN state_history sh ON sh.advisories_id = se.advisories_id ` +
+		`AND sh.state = se.state AND sh.valid_from = se.entered `
+	var where string
+	if len(conds) > 0 {
+		where = `WHERE (` + strings.Join(conds, `) AND (`) + `) `
+	}
+	countSQL := `SELECT count(*) ` + fromSQL + where
+	fetchSQL := `SELECT se.id, ads.publisher, ads.tracking_id, docs.id, se.state::text, ` +
+		`se.entered, se.deadline, se.escalated, sh.valid_to ` +
+		fromSQL + where +
+		`ORDER BY se.escalated DESC, se.id DESC` +
+		` LIMIT ` + strconv.FormatInt(limit, 10) +
+		` OFFSET ` + strconv.FormatInt(offset, 10)
+
+	var (
+		entries []slaEscalation
+		count   int64
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			if calcCount {
+				if err := conn.QueryRow(rctx, countSQL, values...).Scan(&count); err != nil {
+					return fmt.Errorf("cannot calculate count %w", err)
+				}
+			}
+			rows, _ := conn.Query(rctx, fetchSQL, values...)
+			var err error
+			entries, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (slaEscalation, error) {
+				var (
+					e     slaEscalation
+					state string
+				)
+				err := row.Scan(
+					&e.ID, &e.Publisher, &e.TrackingID, &e.DocumentID, &state,
+					&e.Entered, &e.Deadline, &e.Escalated, &e.Left)
+				e.State = models.Workflow(state)
+				e.Entered, e.Deadline, e.Escalated = e.Entered.UTC(), e.Deadline.UTC(), e.Escalated.UTC()
+				if e.Left != nil {
+					left := e.Left.UTC()
+					e.Left = &left
+				}
+				return e, err
+			})
+			return err
+		}, c.cfg.Database.MaxQueryDuration,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	type escalations struct {
+		Entries []slaEscalation `json:"entries"`
+		Count   *int64          `json:"count,omitempty"`
+	}
+	es := escalations{Entries: entries}
+	if es.Entries == nil {
+		es.Entries = []slaEscalation{}
+	}
+	if calcCount {
+		es.Count = &count
+	}
+	ctx.JSON(http.StatusOK, &es)
+}
+
+// triageStats are the stays of the advisories in a workflow state
+// which began in the period of a report.
+type triageStats struct {
+	State      models.Workflow `json:"state"`
+	Limit      float64         `json:"limit"`
+	Stays      int64           `json:"stays"`
+	InTime     int64           `json:"in_time"`
+	Overdue    int64           `json:"overdue"`
+	Pending    int64           `json:"pending"`
+	Median     *float64        `json:"median,omitempty"`
+	Max        *float64        `json:"max,omitempty"`
+	Compliance float64         `json:"compliance"`
+}
+
+type triageReport struct {
+	Period models.ReportPeriod `json:"period"`
+	From   time.Time           `json:"from"`
+	To     time.Time           `json:"to"`
+	States []triageStats       `json:"states"`
+}
+
+// triageReportSQL aggregates the durations of the stays in the states
+// with limits. Stays which are not finished yet count as overdue if they
+// already exceed the limit and as pending otherwise. The median and
+// the maximum only cover the finished stays.
+const triageReportSQL = slaLimitsSQL + `, stays AS (` +
+	`SELECT sh.state::text AS state, limits.seconds, sh.valid_to IS NULL AS open, ` +
+	`extract(epoch FROM coalesce(sh.valid_to, current_timestamp) - sh.valid_from)::float8 AS duration ` +
+	`FROM state_history sh ` +
+	`JOIN limits ON sh.state::text = limits.state ` +
+	`JOIN advisories ads ON sh.advisories_id = ads.id ` +
+	`JOIN documents docs ON docs.advisories_id = ads.id AND docs.latest ` +
+	`WHERE sh.valid_from >= $3 AND sh.valid_from < $4` +
+	`) SELECT state, count(*), ` +
+	`count(*) FILTER (WHERE NOT open AND duration <= seconds), ` +
+	`count(*) FILTER (WHERE duration > seconds), ` +
+	`count(*) FILTER (WHERE open AND duration <= seconds), ` +
+	`percentile_cont(0.5) WITHIN GROUP (ORDER BY duration) FILTER (WHERE NOT open), ` +
+	`max(duration) FILTER (WHERE NOT open) ` +
+	`FROM stays GROUP BY state`
+
+// triageReportPeriod is an endpoint that returns a triage report.
+//
+//	@Summary		Returns a triage report.
+//	@Description	Returns how many of the advisories entering a workflow state with
+//	@Description	a configured limit in a calendar period left it in time.
+//	@Description	The durations are given in seconds.
+//	@Description	Without parameters the report covers the last completed month.
+//	@Param			period	query	string	false	"week, month, quarter or year"
+//	@Param			offset	query	int		false	"Number of periods back, 0 is the current one"
+//	@Produce		json
+//	@Success		200	{object}	triageReport
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/stats/triage [get]
+func (c *Controller) triageReportPeriod(ctx *gin.Context) {
+	period, ok := parse(ctx, models.ParseReportPeriod, ctx.DefaultQuery("period", "month"))
+	if !ok {
+		return
+	}
+	offset, ok := parse(ctx, strconv.Atoi, ctx.DefaultQuery("offset", "1"))
+	if !ok {
+		return
+	}
+	if offset < 0 {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "offset has to be non-negative")
+		return
+	}
+	from, to := period.Bounds(time.Now(), offset)
+
+	states, seconds := sla.Limits(&c.cfg.SLA)
+	report := triageReport{
+		Period: period,
+		From:   from,
+		To:     to,
+		States: make([]triageStats, len(states)),
+	}
+	index := make(map[string]int, len(states))
+	for i, state := range states {
+		report.States[i] = triageStats{State: models.Workflow(state), Limit: seconds[i]}
+		index[state] = i
+	}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, triageReportSQL, states, seconds, from, to)
+			defer rows.Close()
+			for rows.Next() {
+				var (
+					state string
+					ts    triageStats
+				)
+				if err := rows.Scan(
+					&state, &ts.Stays, &ts.InTime, &ts.Overdue, &ts.Pending,
+					&ts.Median, &ts.Max,
+				); err != nil {
+					return err
+				}
+				i, found := index[state]
+				if !found {
+					continue
+				}
+				ts.State, ts.Limit = report.States[i].State, report.States[i].Limit
+				if decided := ts.InTime + ts.Overdue; decided > 0 {
+					ts.Compliance = float64(ts.InTime) / float64(decided)
+				}
+				report.States[i] = ts
+			}
+			return rows.Err()
+		}, c.cfg.Database.MaxQueryDuration,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, &report)
+}
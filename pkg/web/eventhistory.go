@@ -0,0 +1,217 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+const (
+	// defaultEventHistoryLimit is the page size if no limit is given.
+	defaultEventHistoryLimit = 100
+	// maxEventHistoryLimit is the largest page size.
+	maxEventHistoryLimit = 1000
+)
+
+// historyEvent is an entry of the raw event log.
+type historyEvent struct {
+	ID         int64            `json:"id"`
+	Event      models.Event     `json:"event_type"`
+	State      *models.Workflow `json:"state,omitempty"`
+	Time       time.Time        `json:"time"`
+	Actor      *string          `json:"actor,omitempty"`
+	DocumentID *int64           `json:"document_id,omitempty"`
+	CommentID  *int64           `json:"comment_id,omitempty"`
+	Publisher  *string          `json:"publisher,omitempty"`
+	TrackingID *string          `json:"tracking_id,omitempty"`
+	Version    *string          `json:"version,omitempty"`
+	Message    *string          `json:"message,omitempty"`
+}
+
+// eventTypes parses a comma separated list of event types.
+func eventTypes(s string) ([]string, error) {
+	var types []string
+	for t := range strings.SplitSeq(s, ",") {
+		if t = strings.TrimSpace(t); t == "" {
+			continue
+		}
+		var ev models.Event
+		if err := ev.UnmarshalText([]byte(t)); err != nil {
+			return nil, err
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// eventHistory is an endpoint that returns the raw event log.
+//
+//	@Summary		Returns the raw event log.
+//	@Description	Returns the entries of the event log including the events
+//	@Description	of deleted documents, newest first.
+//	@Description	Events without a document are only visible to admins and auditors.
+//	@Param			type		query	string	false	"Comma separated list of event types"
+//	@Param			actor		query	string	false	"Actor"
+//	@Param			document	query	int		false	"Document ID"
+//	@Param			from		query	string	false	"Timerange start"
+//	@Param			to			query	string	false	"Timerange end"
+//	@Param			limit		query	int		false	"Maximum number of events"
+//	@Param			offset		query	int		false	"Number of events to skip"
+//	@Param			count		query	bool	false	"Also return the number of matching events"
+//	@Produce		json
+//	@Success		200	{object}	web.eventHistory.history
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/events/history [get]
+func (c *Controller) eventHistory(ctx *gin.Context) {
+	var (
+		values []any
+		conds  []string
+		ok     bool
+	)
+	add := func(cond string, value any) {
+		values = append(values, value)
+		conds = append(conds, strings.ReplaceAll(cond, "$?", "$"+strconv.Itoa(len(values))))
+	}
+
+	// Only the events of the visible documents are fetched.
+	// Events without a document are only visible to admins and auditors.
+	add(`documents.id IS NOT NULL OR (events_log.documents_id IS NULL AND $?)`,
+		c.hasAnyRole(ctx, models.Admin, models.Auditor))
+
+	if ts := ctx.Query("type"); ts != "" {
+		types, ok := parse(ctx, eventTypes, ts)
+		if !ok {
+			return
+		}
+		if len(types) > 0 {
+			add(`events_log.event::text = ANY($?)`, types)
+		}
+	}
+	if actor := ctx.Query("actor"); actor != "" {
+		add(`events_log.actor = $?`, actor)
+	}
+	if doc := ctx.Query("document"); doc != "" {
+		docID, ok := parse(ctx, toInt64, doc)
+		if !ok {
+			return
+		}
+		add(`events_log.documents_id = $?`, docID)
+	}
+	if from := ctx.Query("from"); from != "" {
+		t, ok := parse(ctx, parseTime, from)
+		if !ok {
+			return
+		}
+		add(`events_log.time >= $?`, t)
+	}
+	if to := ctx.Query("to"); to != "" {
+		t, ok := parse(ctx, parseTime, to)
+		if !ok {
+			return
+		}
+		add(`events_log.time <= $?`, t)
+	}
+
+	var limit, offset int64 = defaultEventHistoryLimit, 0
+	if lim := ctx.Query("limit"); lim != "" {
+		if limit, ok = parse(ctx, toInt64, lim); !ok {
+			return
+		}
+		if limit < 1 || limit > maxEventHistoryLimit {
+			models.SendErrorMessage(ctx, http.StatusBadRequest,
+				fmt.Sprintf("limit has to be between 1 and %d", maxEventHistoryLimit))
+			return
+		}
+	}
+	if ofs := ctx.Query("offset"); ofs != "" {
+		if offset, ok = parse(ctx, toInt64, ofs); !ok {
+			return
+		}
+		if offset < 0 {
+			models.SendErrorMessage(ctx, http.StatusBadRequest, "offset must not be negative")
+			return
+		}
+	}
+	calcCount := ctx.Query("count") != ""
+
+	const fromSQL = `FROM events_log ` +
+		`LEFT JOIN documents ON events_log.documents_id = documents.id ` +
+		`LEFT JOIN advisories ON documents.advisories_id = advisories.id `
+	where := `WHERE (` + strings.Join(conds, `) AND (`) + `)`
+	countSQL := `SELECT count(*) ` + fromSQL + where
+	fetchSQL := `SELECT events_log.id, events_log.event, events_log.state, ` +
+		`events_log.time, events_log.actor, ` +
+		`events_log.documents_id, events_log.comments_id, ` +
+		`advisories.publisher, advisories.tracking_id, documents.version, ` +
+		`events_log.message ` +
+		fromSQL + where +
+		` ORDER BY events_log.time DESC, events_log.id DESC` +
+		` LIMIT ` + strconv.FormatInt(limit, 10) +
+		` OFFSET ` + strconv.FormatInt(offset, 10)
+
+	var (
+		events []historyEvent
+		count  int64
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			if calcCount {
+				if err := conn.QueryRow(rctx, countSQL, values...).Scan(&count); err != nil {
+					return fmt.Errorf("cannot calculate count %w", err)
+				}
+			}
+			rows, _ := conn.Query(rctx, fetchSQL, values...)
+			var err error
+			events, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (historyEvent, error) {
+				var ev historyEvent
+				err := row.Scan(
+					&ev.ID, &ev.Event, &ev.State,
+					&ev.Time, &ev.Actor,
+					&ev.DocumentID, &ev.CommentID,
+					&ev.Publisher, &ev.TrackingID, &ev.Version,
+					&ev.Message)
+				ev.Time = ev.Time.UTC()
+				return ev, err
+			})
+			return err
+		}, c.cfg.Database.MaxQueryDuration,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	type history struct {
+		Events []historyEvent `json:"events"`
+		Count  *int64         `json:"count,omitempty"`
+	}
+	h := history{Events: events}
+	if h.Events == nil {
+		h.Events = []historyEvent{}
+	}
+	if calcCount {
+		h.Count = &count
+	}
+	ctx.JSON(http.StatusOK, h)
+}
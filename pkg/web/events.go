@@ -50,9 +50,6 @@ func (c *Controller) overviewEvents(ctx *gin.Context) {
 		return
 	}
 
-	// Filter the allowed
-	expr = c.andTLPExpr(ctx, expr)
-
 	builder := query.SQLBuilder{Mode: query.EventMode}
 	builder.CreateWhere(expr)
 
@@ -113,7 +110,7 @@ func (c *Controller) overviewEvents(ctx *gin.Context) {
 			sql := builder.CreateQuery(fields, order, limit, offset)
 
 			if slog.Default().Enabled(rctx, slog.LevelDebug) {
-				slog.Debug("events", "SQL", query.InterpolateSQLqnd(sql, builder.Replacements))
+				slog.DebugContext(ctx, "events", "SQL", query.InterpolateSQLqnd(sql, builder.Replacements))
 			}
 			rows, err := conn.Query(rctx, sql, builder.Replacements...)
 			if err != nil {
@@ -128,7 +125,7 @@ func (c *Controller) overviewEvents(ctx *gin.Context) {
 		},
 		c.cfg.Database.MaxQueryDuration, // In case the user provided a very expensive query.
 	); err != nil {
-		slog.Error("database error", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -172,9 +169,8 @@ func (c *Controller) viewEvents(ctx *gin.Context) {
 		return
 	}
 
-	expr := c.andTLPExpr(ctx,
-		query.FieldEqString("tracking_id", key.TrackingID).And(
-			query.FieldEqString("publisher", key.Publisher)))
+	expr := query.FieldEqString("tracking_id", key.TrackingID).And(
+		query.FieldEqString("publisher", key.Publisher))
 
 	builder := query.SQLBuilder{}
 	builder.CreateWhere(expr)
@@ -226,7 +222,7 @@ func (c *Controller) viewEvents(ctx *gin.Context) {
 			return err
 		}, 0,
 	); err != nil {
-		slog.Error("database error", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
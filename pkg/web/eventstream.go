@@ -0,0 +1,177 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ISDuBA/ISDuBA/pkg/eventbus"
+	"github.com/ISDuBA/ISDuBA/pkg/ginkeycloak"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// eventStreamKeepAlive is the interval of the comments sent
+// to keep idle connections open through proxies.
+const eventStreamKeepAlive = 30 * time.Second
+
+// documentEvent returns an event of a document caused by the current user.
+// The details of the document are completed by the event bus.
+func (c *Controller) documentEvent(
+	ctx *gin.Context,
+	event models.Event,
+	docID int64,
+	state models.Workflow,
+) eventbus.Event {
+	return eventbus.Event{
+		Type:       event,
+		DocumentID: docID,
+		State:      string(state),
+		Actor:      c.currentUser(ctx).String,
+	}
+}
+
+// publishEvents hands committed events over to the event bus.
+func (c *Controller) publishEvents(evs []eventbus.Event) {
+	for _, ev := range evs {
+		c.eb.Publish(ev)
+	}
+}
+
+// tokenExpired returns a channel which is closed when the token
+// of the request expires. nil is returned if the token does not expire.
+func tokenExpired(ctx *gin.Context) <-chan time.Time {
+	token, ok := ctx.Get("token")
+	if !ok {
+		return nil
+	}
+	kct, ok := token.(*ginkeycloak.KeycloakToken)
+	if !ok || kct == nil {
+		return nil
+	}
+	exp := kct.Expires()
+	if exp.IsZero() {
+		return nil
+	}
+	return time.After(time.Until(exp))
+}
+
+// streamEvents is an endpoint that streams the changes of documents.
+//
+//	@Summary		Streams the changes of documents.
+//	@Description	Streams the imports, comments, state changes, deletions and forwardings
+//	@Description	of the documents the user is allowed to see as server-sent events.
+//	@Description	The data of every event is a JSON object. After a reconnect the
+//	@Description	recent events missed are delivered first if the Last-Event-ID header is sent.
+//	@Description	The stream is closed when the token expires.
+//	@Param			types			query	string	false	"Comma separated list of event types"
+//	@Param			Last-Event-ID	header	int		false	"ID of the last received event"
+//	@Produce		text/event-stream
+//	@Success		200	{object}	eventbus.Event
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		503	{object}	models.Error
+//	@Router			/events/stream [get]
+func (c *Controller) streamEvents(ctx *gin.Context) {
+	if c.eb == nil {
+		models.SendErrorMessage(ctx, http.StatusServiceUnavailable, "event stream not available")
+		return
+	}
+	var lastID uint64
+	if last := ctx.GetHeader("Last-Event-ID"); last != "" {
+		var ok bool
+		if lastID, ok = parse(ctx, func(s string) (uint64, error) {
+			return strconv.ParseUint(s, 10, 64)
+		}, last); !ok {
+			return
+		}
+	}
+	var types map[models.Event]bool
+	if ts := ctx.Query("types"); ts != "" {
+		types = map[models.Event]bool{}
+		for t := range strings.SplitSeq(ts, ",") {
+			types[models.Event(strings.TrimSpace(t))] = true
+		}
+	}
+	visible := func(ev *eventbus.Event) bool {
+		if types != nil && !types[ev.Type] {
+			return false
+		}
+		// Events of unknown documents are not shown.
+		// The rules are evaluated per event as the rules
+		// of the groups may change while streaming.
+		return ev.Publisher != "" && c.tlps(ctx).Allowed(ev.Publisher, models.TLP(ev.TLP))
+	}
+
+	sub, missed := c.eb.Subscribe(lastID)
+	defer sub.Close()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	// Tell reverse proxies like nginx not to buffer the stream.
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	w := ctx.Writer
+	send := func(ev *eventbus.Event) error {
+		if !visible(ev) {
+			return nil
+		}
+		data, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data)
+		return err
+	}
+	for i := range missed {
+		if send(&missed[i]) != nil {
+			return
+		}
+	}
+	// Start with a comment so that the headers are sent.
+	if _, err := fmt.Fprint(w, ": connected\n\n"); err != nil {
+		return
+	}
+	w.Flush()
+
+	keepAlive := time.NewTicker(eventStreamKeepAlive)
+	defer keepAlive.Stop()
+	done := ctx.Request.Context().Done()
+	// The claims of the token are only checked again on reconnect.
+	expired := tokenExpired(ctx)
+	for {
+		select {
+		case <-done:
+			return
+		case <-expired:
+			return
+		case ev, ok := <-sub.Events:
+			if !ok {
+				// Too slow or shutting down. The client may reconnect.
+				return
+			}
+			if send(&ev) != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		w.Flush()
+	}
+}
@@ -0,0 +1,116 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/sources"
+)
+
+// failedDownloads is an endpoint that returns the advisories
+// which could not be downloaded after all retries.
+//
+//	@Summary		Returns the failed downloads.
+//	@Description	Returns the advisories of the feeds which could not be downloaded
+//	@Description	or imported after all retries. Dismissed downloads are only
+//	@Description	returned if dismissed is true.
+//	@Param			source		query	int		false	"Source ID"
+//	@Param			feed		query	int		false	"Feed ID"
+//	@Param			dismissed	query	bool	false	"Include dismissed downloads"
+//	@Produce		json
+//	@Success		200	{array}		sources.FailedDownload
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/sources/failed [get]
+func (c *Controller) failedDownloads(ctx *gin.Context) {
+	var sourceID, feedID *int64
+	if value := ctx.Query("source"); value != "" {
+		id, ok := parse(ctx, toInt64, value)
+		if !ok {
+			return
+		}
+		sourceID = &id
+	}
+	if value := ctx.Query("feed"); value != "" {
+		id, ok := parse(ctx, toInt64, value)
+		if !ok {
+			return
+		}
+		feedID = &id
+	}
+	dismissed, ok := parse(ctx, strconv.ParseBool, ctx.DefaultQuery("dismissed", "false"))
+	if !ok {
+		return
+	}
+	failed, err := c.sm.FailedDownloads(ctx.Request.Context(), sourceID, feedID, dismissed)
+	if err != nil {
+		slog.ErrorContext(ctx, "loading failed downloads failed", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if failed == nil {
+		failed = []sources.FailedDownload{}
+	}
+	ctx.JSON(http.StatusOK, failed)
+}
+
+// handleFailedDownloads is an endpoint that requeues or dismisses
+// failed downloads.
+//
+//	@Summary		Requeues or dismisses failed downloads.
+//	@Description	With action requeue the failed downloads are put back into the
+//	@Description	queues of their feeds and retried. With action dismiss they are
+//	@Description	hidden until a newer version of the advisory fails again.
+//	@Param			action	formData	string	true	"Action"	Enums(requeue, dismiss)
+//	@Param			ids		formData	[]int	true	"Failed download IDs"	collectionFormat(multi)
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/sources/failed [post]
+func (c *Controller) handleFailedDownloads(ctx *gin.Context) {
+	ids, ok := parseIDs(ctx, "ids")
+	if !ok {
+		return
+	}
+	if len(ids) == 0 {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "missing ids")
+		return
+	}
+	switch action := ctx.PostForm("action"); action {
+	case "requeue":
+		requeued, err := c.sm.RequeueFailedDownloads(ctx.Request.Context(), ids)
+		if err != nil {
+			slog.ErrorContext(ctx, "requeuing failed downloads failed", "err", err)
+			models.SendError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		models.SendSuccess(ctx, http.StatusOK, fmt.Sprintf("requeued %d downloads", len(requeued)))
+	case "dismiss":
+		dismissed, err := c.sm.DismissFailedDownloads(ctx.Request.Context(), ids)
+		if err != nil {
+			slog.ErrorContext(ctx, "dismissing failed downloads failed", "err", err)
+			models.SendError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		models.SendSuccess(ctx, http.StatusOK, fmt.Sprintf("dismissed %d downloads", dismissed))
+	default:
+		models.SendErrorMessage(ctx, http.StatusBadRequest, fmt.Sprintf("unknown action %q", action))
+	}
+}
@@ -0,0 +1,140 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/sources"
+)
+
+// feedLogExporters write the feed log entries in the different export formats.
+var feedLogExporters = map[string]struct {
+	contentType string
+	write       func(io.Writer, iter.Seq[sources.FeedLogInfo]) error
+}{
+	"csv":    {"text/csv; charset=utf-8", writeFeedLogCSV},
+	"ndjson": {"application/x-ndjson", writeFeedLogNDJSON},
+}
+
+func writeFeedLogCSV(w io.Writer, entries iter.Seq[sources.FeedLogInfo]) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"feed_id", "time", "level", "msg"}); err != nil {
+		return err
+	}
+	for entry := range entries {
+		if err := out.Write([]string{
+			strconv.FormatInt(entry.ID, 10),
+			entry.Time.Format(time.RFC3339Nano),
+			entry.Level.String(),
+			entry.Message,
+		}); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func writeFeedLogNDJSON(w io.Writer, entries iter.Seq[sources.FeedLogInfo]) error {
+	enc := json.NewEncoder(w)
+	for entry := range entries {
+		if err := enc.Encode(&entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportFeedLog is an endpoint that exports the feed log as a file.
+//
+//	@Summary		Exports the feed log.
+//	@Description	Exports the filtered feed log of a feed, of all feeds of a source
+//	@Description	or of all feeds as CSV or NDJSON. The newest entries come first.
+//	@Param			format	query	string	false	"csv or ndjson, defaults to csv"
+//	@Param			feed	query	int		false	"Feed ID"
+//	@Param			source	query	int		false	"Source ID"
+//	@Param			levels	query	string	false	"Space separated list of log levels"
+//	@Param			from	query	string	false	"Timerange start"
+//	@Param			to		query	string	false	"Timerange end"
+//	@Param			search	query	string	false	"Search text in messages"
+//	@Produce		text/csv
+//	@Produce		application/x-ndjson
+//	@Success		200	{string}	string
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/sources/feeds/log/export [get]
+func (c *Controller) exportFeedLog(ctx *gin.Context) {
+	format := strings.ToLower(ctx.DefaultQuery("format", "csv"))
+	exporter, found := feedLogExporters[format]
+	if !found {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "format has to be csv or ndjson")
+		return
+	}
+	optID := func(name string) (*int64, bool) {
+		value := ctx.Query(name)
+		if value == "" {
+			return nil, true
+		}
+		id, ok := parse(ctx, toInt64, value)
+		return &id, ok
+	}
+	feedID, ok := optID("feed")
+	if !ok {
+		return
+	}
+	sourceID, ok := optID("source")
+	if !ok {
+		return
+	}
+	filter, ok := parseFeedLogFilter(ctx)
+	if !ok {
+		return
+	}
+
+	entries, err := c.sm.StreamFeedLog(
+		ctx.Request.Context(),
+		feedID, sourceID,
+		filter.from, filter.to,
+		filter.search,
+		-1, -1, filter.levels, nil, nil)
+	if err != nil {
+		slog.ErrorContext(ctx, "database error", "error", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	filename := "feed-log"
+	switch {
+	case feedID != nil:
+		filename += fmt.Sprintf("-feed-%d", *feedID)
+	case sourceID != nil:
+		filename += fmt.Sprintf("-source-%d", *sourceID)
+	}
+	ctx.Header("Content-Type", exporter.contentType)
+	ctx.Header("Content-Disposition",
+		fmt.Sprintf("attachment; filename=\"%s.%s\"", filename, format))
+	ctx.Status(http.StatusOK)
+	if err := exporter.write(ctx.Writer, entries); err != nil {
+		slog.ErrorContext(ctx, "exporting feed log failed", "error", err)
+	}
+}
@@ -0,0 +1,159 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/sources"
+)
+
+// feedRescanApply are the proposed feed changes selected to be applied.
+type feedRescanApply struct {
+	Add    []sources.ProposedFeed `json:"add"`
+	Remove []int64                `json:"remove"`
+}
+
+// appliedFeedChange is the outcome of applying a proposed feed change.
+type appliedFeedChange struct {
+	ID    int64   `json:"id,omitempty"`
+	Label string  `json:"label,omitempty"`
+	URL   string  `json:"url,omitempty"`
+	State string  `json:"state"`
+	Error *string `json:"error,omitempty"`
+}
+
+// appliedFeedRescan are the outcomes of the applied feed changes.
+type appliedFeedRescan struct {
+	Added   []appliedFeedChange `json:"added"`
+	Removed []appliedFeedChange `json:"removed"`
+}
+
+// rescanFeeds is an endpoint that proposes changes of the feeds of a source.
+//
+//	@Summary		Proposes changes of the feeds of a source.
+//	@Description	Re-reads the PMD of the source and compares the advertised
+//	@Description	feeds with the configured ones. Feeds only advertised are proposed
+//	@Description	to be added, feeds not advertised any more to be removed.
+//	@Param			id	path	int	true	"Source ID"
+//	@Produce		json
+//	@Success		200	{object}	sources.FeedRescan
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		502	{object}	models.Error
+//	@Router			/sources/{id}/feeds/rescan [get]
+func (c *Controller) rescanFeeds(ctx *gin.Context) {
+	sourceID, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	switch rescan, err := c.sm.RescanFeeds(sourceID); {
+	case err == nil:
+		ctx.JSON(http.StatusOK, rescan)
+	case errors.Is(err, sources.NoSuchEntryError("")):
+		models.SendError(ctx, http.StatusNotFound, err)
+	case errors.Is(err, sources.InvalidArgumentError("")):
+		slog.WarnContext(ctx, "rescanning feeds failed", "err", err, "source", sourceID)
+		models.SendError(ctx, http.StatusBadGateway, err)
+	default:
+		slog.ErrorContext(ctx, "rescanning feeds failed", "err", err, "source", sourceID)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+	}
+}
+
+// applyFeedRescan is an endpoint that applies selected proposed feed changes.
+//
+//	@Summary		Applies selected proposed feed changes.
+//	@Description	Adds the given feeds to the source and removes the feeds
+//	@Description	with the given ids from it. Every change is applied on its own,
+//	@Description	the outcome of each is reported.
+//	@Param			id		path	int				true	"Source ID"
+//	@Param			changes	body	feedRescanApply	true	"Selected changes"
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	appliedFeedRescan
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Router			/sources/{id}/feeds/rescan [post]
+func (c *Controller) applyFeedRescan(ctx *gin.Context) {
+	sourceID, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	var input feedRescanApply
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	// Only the feeds of this source may be removed.
+	owned := map[int64]bool{}
+	if err := c.sm.Feeds(ctx.Request.Context(), sourceID, func(fi *sources.FeedInfo) {
+		owned[fi.ID] = true
+	}, false, sources.OrderByID); err != nil {
+		models.SendError(ctx, http.StatusNotFound, err)
+		return
+	}
+	result := appliedFeedRescan{
+		Added:   []appliedFeedChange{},
+		Removed: []appliedFeedChange{},
+	}
+	fail := func(change appliedFeedChange, err error) appliedFeedChange {
+		msg := err.Error()
+		change.State, change.Error = "failed", &msg
+		return change
+	}
+	for _, add := range input.Add {
+		change := appliedFeedChange{Label: add.Label, URL: add.URL}
+		parsed, err := url.Parse(add.URL)
+		switch {
+		case add.Label == "":
+			change = fail(change, errors.New("missing label"))
+		case err != nil:
+			change = fail(change, err)
+		default:
+			feedID, err := c.sm.AddFeed(ctx.Request.Context(), sourceID, add.Label, parsed, c.cfg.Sources.FeedLogLevel)
+			if err != nil {
+				if !errors.Is(err, sources.InvalidArgumentError("")) {
+					slog.ErrorContext(ctx, "adding feed failed", "err", err, "source", sourceID)
+				}
+				change = fail(change, err)
+			} else {
+				change.ID, change.State = feedID, "added"
+			}
+		}
+		result.Added = append(result.Added, change)
+	}
+	for _, feedID := range input.Remove {
+		change := appliedFeedChange{ID: feedID}
+		if !owned[feedID] {
+			change = fail(change, fmt.Errorf("feed %d does not belong to source", feedID))
+		} else {
+			switch deleted, err := c.sm.RemoveFeed(ctx.Request.Context(), feedID); {
+			case err != nil:
+				slog.ErrorContext(ctx, "removing feed failed", "err", err, "feed", feedID)
+				change = fail(change, err)
+			case deleted:
+				change.State = "removed"
+			default:
+				change.State = "draining"
+			}
+		}
+		result.Removed = append(result.Removed, change)
+	}
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,271 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/database/query"
+	"github.com/ISDuBA/ISDuBA/pkg/forwarder"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+const (
+	// defaultForwardLogLimit is the page size if no limit is given.
+	defaultForwardLogLimit = 100
+	// maxForwardLogLimit is the largest page size.
+	maxForwardLogLimit = 1000
+)
+
+// forwardLogEntry is an attempt to forward a document to a target.
+type forwardLogEntry struct {
+	ID         int64     `json:"id"`
+	Time       time.Time `json:"time"`
+	Target     string    `json:"target"`
+	DocumentID *int64    `json:"document_id,omitempty"`
+	Publisher  string    `json:"publisher"`
+	TrackingID string    `json:"tracking_id"`
+	Version    string    `json:"version"`
+	Trigger    string    `json:"trigger"`
+	Actor      *string   `json:"actor,omitempty"`
+	Delivered  bool      `json:"delivered"`
+	StatusCode *int      `json:"status_code,omitempty"`
+	Response   *string   `json:"response,omitempty"`
+	Error      *string   `json:"error,omitempty"`
+}
+
+// viewForwardLog is an endpoint that returns the forward log.
+//
+//	@Summary		Returns the forward log.
+//	@Description	Returns the recorded attempts to forward documents
+//	@Description	to the targets, newest first.
+//	@Param			document	query	int		false	"Document ID"
+//	@Param			target		query	string	false	"URL of the target"
+//	@Param			trigger		query	string	false	"automatic, manual or replay"
+//	@Param			delivered	query	bool	false	"Only successful or failed attempts"
+//	@Param			from		query	string	false	"Timerange start"
+//	@Param			to			query	string	false	"Timerange end"
+//	@Param			limit		query	int		false	"Maximum number of entries"
+//	@Param			offset		query	int		false	"Number of entries to skip"
+//	@Param			count		query	bool	false	"Also return the number of matching entries"
+//	@Produce		json
+//	@Success		200	{object}	web.viewForwardLog.forwardLog
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/forwarder/log [get]
+func (c *Controller) viewForwardLog(ctx *gin.Context) {
+	var (
+		values []any
+		conds  []string
+		ok     bool
+	)
+	add := func(cond string, value any) {
+		values = append(values, value)
+		conds = append(conds, strings.ReplaceAll(cond, "$?", "$"+strconv.Itoa(len(values))))
+	}
+
+	if doc := ctx.Query("document"); doc != "" {
+		docID, ok := parse(ctx, toInt64, doc)
+		if !ok {
+			return
+		}
+		add(`documents_id = $?`, docID)
+	}
+	if target := ctx.Query("target"); target != "" {
+		add(`target = $?`, target)
+	}
+	if trigger := ctx.Query("trigger"); trigger != "" {
+		switch trigger {
+		case "automatic", "manual", "replay":
+		default:
+			models.SendErrorMessage(ctx, http.StatusBadRequest,
+				fmt.Sprintf("unknown trigger %q", trigger))
+			return
+		}
+		add(`trigger = $?::forward_trigger`, trigger)
+	}
+	if del := ctx.Query("delivered"); del != "" {
+		delivered, ok := parse(ctx, strconv.ParseBool, del)
+		if !ok {
+			return
+		}
+		add(`delivered = $?`, delivered)
+	}
+	if from := ctx.Query("from"); from != "" {
+		t, ok := parse(ctx, parseTime, from)
+		if !ok {
+			return
+		}
+		add(`time >= $?`, t)
+	}
+	if to := ctx.Query("to"); to != "" {
+		t, ok := parse(ctx, parseTime, to)
+		if !ok {
+			return
+		}
+		add(`time <= $?`, t)
+	}
+
+	var limit, offset int64 = defaultForwardLogLimit, 0
+	if lim := ctx.Query("limit"); lim != "" {
+		if limit, ok = parse(ctx, toInt64, lim); !ok {
+			return
+		}
+		if limit < 1 || limit > maxForwardLogLimit {
+			models.SendErrorMessage(ctx, http.StatusBadRequest,
+				fmt.Sprintf("limit has to be between 1 and %d", maxForwardLogLimit))
+			return
+		}
+	}
+	if ofs := ctx.Query("offset"); ofs != "" {
+		if offset, ok = parse(ctx, toInt64, ofs); !ok {
+			return
+		}
+		if offset < 0 {
+			models.SendErrorMessage(ctx, http.StatusBadRequest, "offset must not be negative")
+			return
+		}
+	}
+	calcCount := ctx.Query("count") != ""
+
+	var where string
+	if len(conds) > 0 {
+		where = `WHERE (` + strings.Join(conds, `) AND (`) + `) `
+	}
+	countSQL := `SELECT count(*) FROM forward_log ` + where
+	fetchSQL := `SELECT id, time, target, documents_id, ` +
+		`publisher, tracking_id, version, trigger::text, actor, ` +
+		`delivered, status_code, response, error ` +
+		`FROM forward_log ` + where +
+		`ORDER BY time DESC, id DESC` +
+		` LIMIT ` + strconv.FormatInt(limit, 10) +
+		` OFFSET ` + strconv.FormatInt(offset, 10)
+
+	var (
+		entries []forwardLogEntry
+		count   int64
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			if calcCount {
+				if err := conn.QueryRow(rctx, countSQL, values...).Scan(&count); err != nil {
+					return fmt.Errorf("cannot calculate count %w", err)
+				}
+			}
+			rows, _ := conn.Query(rctx, fetchSQL, values...)
+			var err error
+			entries, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (forwardLogEntry, error) {
+				var e forwardLogEntry
+				err := row.Scan(
+					&e.ID, &e.Time, &e.Target, &e.DocumentID,
+					&e.Publisher, &e.TrackingID, &e.Version, &e.Trigger, &e.Actor,
+					&e.Delivered, &e.StatusCode, &e.Response, &e.Error)
+				e.Time = e.Time.UTC()
+				return e, err
+			})
+			return err
+		}, c.cfg.Database.MaxQueryDuration,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	type forwardLog struct {
+		Entries []forwardLogEntry `json:"entries"`
+		Count   *int64            `json:"count,omitempty"`
+	}
+	fl := forwardLog{Entries: entries}
+	if fl.Entries == nil {
+		fl.Entries = []forwardLogEntry{}
+	}
+	if calcCount {
+		fl.Count = &count
+	}
+	ctx.JSON(http.StatusOK, fl)
+}
+
+// replayForwardedDocument is an endpoint that sends a document again.
+//
+//	@Summary		Resends a forwarded document.
+//	@Description	Sends the document again to the specified target or, if no
+//	@Description	target is given, to all configured targets it was forwarded to before.
+//	@Description	The target ID is the one listed at /forwarder/targets.
+//	@Description	The attempts are recorded in the forward log.
+//	@Param			document	path	int	true	"Document ID"
+//	@Param			target		query	int	false	"Target ID"
+//	@Produce		json
+//	@Success		200	{array}		forwarder.ReplayResult
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/forwarder/replay/{document} [post]
+func (c *Controller) replayForwardedDocument(ctx *gin.Context) {
+	documentID, ok := parse(ctx, toInt64, ctx.Param("document"))
+	if !ok {
+		return
+	}
+	targetID := int64(-1)
+	if target := ctx.Query("target"); target != "" {
+		if targetID, ok = parse(ctx, toInt64, target); !ok {
+			return
+		}
+		if targetID < 0 {
+			models.SendErrorMessage(ctx, http.StatusBadRequest, "target must not be negative")
+			return
+		}
+	}
+
+	// Check that the document exists and is visible.
+	expr := query.FieldEqInt("id", documentID)
+	builder := query.SQLBuilder{}
+	builder.CreateWhere(expr)
+	sql := builder.CreateQuery([]string{"id"}, "", -1, -1)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			return conn.QueryRow(rctx, sql, builder.Replacements...).Scan(&documentID)
+		}, 0,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			models.SendErrorMessage(ctx, http.StatusNotFound, "document not found")
+		} else {
+			slog.ErrorContext(ctx, "database error", "err", err)
+			models.SendError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	results, err := c.fm.Replay(
+		ctx.Request.Context(), documentID, targetID, c.currentUser(ctx))
+	switch {
+	case errors.Is(err, forwarder.ErrNoSuchTarget), errors.Is(err, forwarder.ErrNotForwarded):
+		models.SendError(ctx, http.StatusNotFound, err)
+		return
+	case err != nil:
+		slog.ErrorContext(ctx, "replaying document failed", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, results)
+}
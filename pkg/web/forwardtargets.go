@@ -0,0 +1,286 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+	"github.com/ISDuBA/ISDuBA/pkg/forwarder"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// forwardTargetInput is the configuration of a forward target managed via the API.
+type forwardTargetInput struct {
+	URL               string               `json:"url" binding:"required,url"`
+	Name              string               `json:"name"`
+	Type              string               `json:"type"`
+	Automatic         bool                 `json:"automatic"`
+	Strategy          *string              `json:"strategy"`
+	Publisher         *string              `json:"publisher"`
+	Header            []string             `json:"header"`
+	Timeout           string               `json:"timeout"`
+	TLP               string               `json:"tlp"`
+	Password          *string              `json:"password"`
+	Passphrase        *string              `json:"passphrase"`
+	ClientCertPublic  *string              `json:"client_cert_public"`
+	ClientCertPrivate *string              `json:"client_cert_private"`
+	HealthURL         string               `json:"health_url"`
+	HealthMethod      string               `json:"health_method"`
+	Rules             []config.ForwardRule `json:"rules"`
+}
+
+// forwardTarget is the configuration of a forward target without its secrets.
+type forwardTarget struct {
+	ID           int64                `json:"id"`
+	Dynamic      bool                 `json:"dynamic"`
+	URL          string               `json:"url"`
+	Name         string               `json:"name,omitempty"`
+	Type         string               `json:"type"`
+	Automatic    bool                 `json:"automatic"`
+	Strategy     *string              `json:"strategy,omitempty"`
+	Publisher    *string              `json:"publisher,omitempty"`
+	Headers      []string             `json:"headers,omitempty"`
+	Timeout      string               `json:"timeout,omitempty"`
+	TLP          string               `json:"tlp,omitempty"`
+	Password     *string              `json:"password,omitempty"`
+	Passphrase   *string              `json:"passphrase,omitempty"`
+	ClientCert   *string              `json:"client_cert,omitempty"`
+	HealthURL    string               `json:"health_url,omitempty"`
+	HealthMethod string               `json:"health_method,omitempty"`
+	Rules        []config.ForwardRule `json:"rules,omitempty"`
+}
+
+// targetConfig converts the input into the configuration of a target.
+func (fti *forwardTargetInput) targetConfig() (*forwarder.TargetConfig, error) {
+	if u, err := url.Parse(fti.URL); err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return nil, errors.New("url has to be an http or https URL")
+	}
+	typ, err := config.ParseForwardTargetType(fti.Type)
+	if err != nil {
+		return nil, err
+	}
+	tc := &forwarder.TargetConfig{
+		ForwardTarget: config.ForwardTarget{
+			URL:          fti.URL,
+			Name:         fti.Name,
+			Type:         typ,
+			Automatic:    fti.Automatic,
+			Publisher:    fti.Publisher,
+			Header:       fti.Header,
+			TLP:          fti.TLP,
+			Password:     fti.Password,
+			Passphrase:   fti.Passphrase,
+			HealthURL:    fti.HealthURL,
+			HealthMethod: fti.HealthMethod,
+			Rules:        fti.Rules,
+		},
+	}
+	if fti.Strategy != nil {
+		strategy, err := config.ParseForwarderStrategy(*fti.Strategy)
+		if err != nil {
+			return nil, err
+		}
+		tc.Strategy = &strategy
+	}
+	if fti.Timeout != "" {
+		if tc.Timeout, err = time.ParseDuration(fti.Timeout); err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+		if tc.Timeout < 0 {
+			return nil, errors.New("timeout must not be negative")
+		}
+	}
+	if fti.ClientCertPublic != nil && *fti.ClientCertPublic != "" {
+		tc.ClientCertPublic = []byte(*fti.ClientCertPublic)
+		if fti.ClientCertPrivate != nil {
+			tc.ClientCertPrivate = []byte(*fti.ClientCertPrivate)
+		}
+	}
+	return tc, nil
+}
+
+// bindForwardTarget binds the configuration of a forward target.
+// If that fails a bad request status code is set in the gin context.
+func bindForwardTarget(ctx *gin.Context) (*forwarder.TargetConfig, bool) {
+	var input forwardTargetInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return nil, false
+	}
+	tc, err := input.targetConfig()
+	if err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return nil, false
+	}
+	return tc, true
+}
+
+// sendForwardTargetError sends the error of a change of a forward target.
+func sendForwardTargetError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, forwarder.ErrNoSuchTarget):
+		models.SendError(ctx, http.StatusNotFound, err)
+	case errors.Is(err, forwarder.ErrTargetExists):
+		models.SendError(ctx, http.StatusConflict, err)
+	case errors.Is(err, forwarder.ErrTargetConfigured):
+		models.SendError(ctx, http.StatusForbidden, err)
+	case errors.Is(err, forwarder.ErrInvalidTarget):
+		models.SendError(ctx, http.StatusBadRequest, err)
+	default:
+		slog.ErrorContext(ctx, "changing forward target failed", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+	}
+}
+
+// createForwardTarget is an endpoint that adds a forward target.
+//
+//	@Summary		Adds a forward target.
+//	@Description	Adds a forward target which is stored in the database
+//	@Description	and used at once without a restart. The client certificate
+//	@Description	is given as PEM data. The secrets are stored encrypted.
+//	@Param			target	body	web.forwardTargetInput	true	"Target configuration"
+//	@Accept			json
+//	@Produce		json
+//	@Success		201	{object}	models.ID
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		409	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/forwarder/targets [post]
+func (c *Controller) createForwardTarget(ctx *gin.Context) {
+	tc, ok := bindForwardTarget(ctx)
+	if !ok {
+		return
+	}
+	id, err := c.fm.AddTarget(ctx.Request.Context(), tc, c.currentUser(ctx))
+	if err != nil {
+		sendForwardTargetError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, models.ID{ID: id})
+}
+
+// viewForwardTarget is an endpoint that returns the configuration of a forward target.
+//
+//	@Summary		Returns a forward target.
+//	@Description	Returns the configuration of a forward target without its secrets.
+//	@Description	Only the names of the headers are returned as their values may
+//	@Description	carry credentials. Targets from the configuration file are not dynamic.
+//	@Param			target	path	int	true	"Target ID"
+//	@Produce		json
+//	@Success		200	{object}	web.forwardTarget
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Router			/forwarder/targets/{target} [get]
+func (c *Controller) viewForwardTarget(ctx *gin.Context) {
+	targetID, ok := parse(ctx, toInt64, ctx.Param("target"))
+	if !ok {
+		return
+	}
+	ti, err := c.fm.Target(targetID)
+	if err != nil {
+		models.SendError(ctx, http.StatusNotFound, err)
+		return
+	}
+	ft := forwardTarget{
+		ID:           ti.ID,
+		Dynamic:      ti.Dynamic,
+		URL:          ti.Target.URL,
+		Name:         ti.Target.Name,
+		Type:         ti.Target.Type.String(),
+		Automatic:    ti.Target.Automatic,
+		Publisher:    ti.Target.Publisher,
+		Headers:      headerNames(ti.Target.Header),
+		TLP:          ti.Target.TLP,
+		Password:     threeStars(ti.HasPassword),
+		Passphrase:   threeStars(ti.HasPassphrase),
+		ClientCert:   threeStars(ti.HasClientCert),
+		HealthURL:    ti.Target.HealthURL,
+		HealthMethod: ti.Target.HealthMethod,
+		Rules:        ti.Target.Rules,
+	}
+	if ti.Target.Strategy != nil {
+		strategy := ti.Target.Strategy.String()
+		ft.Strategy = &strategy
+	}
+	if ti.Target.Timeout != 0 {
+		ft.Timeout = ti.Target.Timeout.String()
+	}
+	ctx.JSON(http.StatusOK, ft)
+}
+
+// updateForwardTarget is an endpoint that changes a forward target.
+//
+//	@Summary		Changes a forward target.
+//	@Description	Replaces the configuration of a forward target added via the API
+//	@Description	and restarts its forwarder. Secrets which are not given are kept.
+//	@Description	An empty password or passphrase removes it. The client certificate
+//	@Description	is removed if its public part is not given.
+//	@Param			target	path	int						true	"Target ID"
+//	@Param			config	body	web.forwardTargetInput	true	"Target configuration"
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		403	{object}	models.Error	"target is defined in the configuration"
+//	@Failure		404	{object}	models.Error
+//	@Failure		409	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/forwarder/targets/{target} [put]
+func (c *Controller) updateForwardTarget(ctx *gin.Context) {
+	targetID, ok := parse(ctx, toInt64, ctx.Param("target"))
+	if !ok {
+		return
+	}
+	tc, ok := bindForwardTarget(ctx)
+	if !ok {
+		return
+	}
+	if err := c.fm.UpdateTarget(ctx.Request.Context(), targetID, tc); err != nil {
+		sendForwardTargetError(ctx, err)
+		return
+	}
+	models.SendSuccess(ctx, http.StatusOK, "updated")
+}
+
+// deleteForwardTarget is an endpoint that removes a forward target.
+//
+//	@Summary		Removes a forward target.
+//	@Description	Removes a forward target added via the API together with
+//	@Description	its queue and backfills. The forward log is kept.
+//	@Param			target	path	int	true	"Target ID"
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		403	{object}	models.Error	"target is defined in the configuration"
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/forwarder/targets/{target} [delete]
+func (c *Controller) deleteForwardTarget(ctx *gin.Context) {
+	targetID, ok := parse(ctx, toInt64, ctx.Param("target"))
+	if !ok {
+		return
+	}
+	if err := c.fm.RemoveTarget(ctx.Request.Context(), targetID); err != nil {
+		sendForwardTargetError(ctx, err)
+		return
+	}
+	models.SendSuccess(ctx, http.StatusOK, "deleted")
+}
@@ -0,0 +1,190 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+type groupTLPs struct {
+	Group string                `json:"group"`
+	TLPs  models.PublishersTLPs `json:"tlps"`
+}
+
+// viewGroupTLPs is an endpoint that returns the TLP rules of the groups.
+//
+//	@Summary		Returns the TLP rules of the groups.
+//	@Description	Returns the publishers and TLP levels the members of the Keycloak groups
+//	@Description	may see in addition to the rules of their tokens or the configuration.
+//	@Produce		json
+//	@Success		200	{array}		groupTLPs
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/tlps/groups [get]
+func (c *Controller) viewGroupTLPs(ctx *gin.Context) {
+	const selectSQL = `SELECT group_name, publisher, tlps FROM group_tlps ` +
+		`ORDER BY group_name, publisher`
+	groups := []groupTLPs{}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, selectSQL)
+			defer rows.Close()
+			for rows.Next() {
+				var (
+					group     string
+					publisher models.Publisher
+					tlps      []string
+				)
+				if err := rows.Scan(&group, &publisher, &tlps); err != nil {
+					return err
+				}
+				if n := len(groups); n == 0 || groups[n-1].Group != group {
+					groups = append(groups, groupTLPs{Group: group, TLPs: models.PublishersTLPs{}})
+				}
+				rules := make([]models.TLP, len(tlps))
+				for i, tlp := range tlps {
+					rules[i] = models.TLP(tlp)
+				}
+				groups[len(groups)-1].TLPs[publisher] = rules
+			}
+			return rows.Err()
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, groups)
+}
+
+// updateGroupTLPs is an endpoint that sets the TLP rules of a group.
+//
+//	@Summary		Sets the TLP rules of a group.
+//	@Description	Replaces the TLP rules of a Keycloak group. The rules map publishers to
+//	@Description	lists of TLP levels. The publisher "*" matches all publishers without own rules.
+//	@Description	The changes are in effect for the next request of the members.
+//	@Param			group	path	string					true	"Keycloak group, may be a full path"
+//	@Param			rules	body	models.PublishersTLPs	true	"TLP rules"
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/tlps/groups/{group} [put]
+func (c *Controller) updateGroupTLPs(ctx *gin.Context) {
+	group := normalizeGroup(ctx.Param("group"))
+	if group == "" {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "missing group")
+		return
+	}
+	var rules models.PublishersTLPs
+	if err := ctx.ShouldBindJSON(&rules); err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if len(rules) == 0 {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "missing rules")
+		return
+	}
+	for publisher := range rules {
+		if strings.TrimSpace(string(publisher)) == "" {
+			models.SendErrorMessage(ctx, http.StatusBadRequest, "missing publisher")
+			return
+		}
+	}
+	const (
+		deleteSQL = `DELETE FROM group_tlps WHERE group_name = $1`
+		insertSQL = `INSERT INTO group_tlps (group_name, publisher, tlps) VALUES ($1, $2, $3)`
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tx, err := conn.BeginTx(rctx, pgx.TxOptions{})
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(rctx)
+			if _, err := tx.Exec(rctx, deleteSQL, group); err != nil {
+				return err
+			}
+			for publisher, tlps := range rules {
+				levels := make([]string, 0, len(tlps))
+				for _, tlp := range tlps {
+					levels = append(levels, string(tlp))
+				}
+				slices.Sort(levels)
+				levels = slices.Compact(levels)
+				if _, err := tx.Exec(rctx, insertSQL, group, publisher, levels); err != nil {
+					return err
+				}
+			}
+			return tx.Commit(rctx)
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	c.reloadGroupTLPs(ctx)
+	models.SendSuccess(ctx, http.StatusOK, "updated")
+}
+
+// deleteGroupTLPs is an endpoint that removes the TLP rules of a group.
+//
+//	@Summary		Removes the TLP rules of a group.
+//	@Description	Removes the TLP rules of a Keycloak group.
+//	@Param			group	path	string	true	"Keycloak group"
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/tlps/groups/{group} [delete]
+func (c *Controller) deleteGroupTLPs(ctx *gin.Context) {
+	const deleteSQL = `DELETE FROM group_tlps WHERE group_name = $1`
+	var deleted bool
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tag, err := conn.Exec(rctx, deleteSQL, normalizeGroup(ctx.Param("group")))
+			deleted = tag.RowsAffected() > 0
+			return err
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if !deleted {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "not found")
+		return
+	}
+	c.reloadGroupTLPs(ctx)
+	models.SendSuccess(ctx, http.StatusOK, "deleted")
+}
+
+// reloadGroupTLPs applies changed rules of the groups right away.
+// The other servers are notified by the database.
+func (c *Controller) reloadGroupTLPs(ctx *gin.Context) {
+	if err := c.gt.Reload(ctx.Request.Context()); err != nil {
+		slog.WarnContext(ctx, "reloading TLP rules of groups failed", "err", err)
+	}
+}
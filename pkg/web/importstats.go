@@ -303,7 +303,7 @@ func (c *Controller) serveImportStats(
 			return err
 		}, 0,
 	); err != nil {
-		slog.Error("Cannot fetch import stats", "error", err)
+		slog.ErrorContext(ctx, "Cannot fetch import stats", "error", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -0,0 +1,324 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// legalHold exempts documents from deletion until it is released.
+type legalHold struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Reason    *string    `json:"reason,omitempty"`
+	Query     *string    `json:"query,omitempty"`
+	Creator   string     `json:"creator"`
+	Created   time.Time  `json:"created"`
+	Releaser  *string    `json:"releaser,omitempty"`
+	Released  *time.Time `json:"released,omitempty"`
+	Documents []int64    `json:"documents,omitempty"`
+}
+
+const selectLegalHoldSQL = `SELECT id, name, reason, query, creator, created, releaser, released, ` +
+	`ARRAY(SELECT documents_id FROM legal_hold_documents ` +
+	`WHERE legal_holds_id = legal_holds.id ORDER BY documents_id) ` +
+	`FROM legal_holds `
+
+func scanLegalHold(row pgx.Row, lh *legalHold) error {
+	if err := row.Scan(
+		&lh.ID, &lh.Name, &lh.Reason, &lh.Query, &lh.Creator, &lh.Created,
+		&lh.Releaser, &lh.Released, &lh.Documents,
+	); err != nil {
+		return err
+	}
+	lh.Created = lh.Created.UTC()
+	if lh.Released != nil {
+		*lh.Released = lh.Released.UTC()
+	}
+	return nil
+}
+
+// logLegalHold logs an event for each of the explicitly held documents
+// of a legal hold and one without a document if it has a query.
+func logLegalHold(
+	ctx context.Context,
+	tx pgx.Tx,
+	event models.Event,
+	now time.Time,
+	actor sql.NullString,
+	id int64,
+	hasQuery bool,
+) error {
+	const (
+		documentsSQL = `INSERT INTO events_log (event, time, actor, documents_id) ` +
+			`SELECT $1::events, $2, $3, documents_id FROM legal_hold_documents ` +
+			`WHERE legal_holds_id = $4`
+		querySQL = `INSERT INTO events_log (event, time, actor) ` +
+			`VALUES ($1::events, $2, $3)`
+	)
+	if _, err := tx.Exec(ctx, documentsSQL, string(event), now, actor, id); err != nil {
+		return fmt.Errorf("event logging failed: %w", err)
+	}
+	if hasQuery {
+		if _, err := tx.Exec(ctx, querySQL, string(event), now, actor); err != nil {
+			return fmt.Errorf("event logging failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// createLegalHold is an endpoint that puts documents under legal hold.
+//
+//	@Summary		Creates a legal hold.
+//	@Description	Exempts the given documents and the documents matching the
+//	@Description	query from deletion until the hold is released. The query
+//	@Description	is evaluated when documents are deleted so documents imported
+//	@Description	after the creation of the hold are covered, too.
+//	@Param			name		formData	string	true	"Name"
+//	@Param			reason		formData	string	false	"Reason"
+//	@Param			query		formData	string	false	"Documents query"
+//	@Param			documents	formData	[]int	false	"Document IDs"
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Success		201	{object}	models.ID
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/legalholds [post]
+func (c *Controller) createLegalHold(ctx *gin.Context) {
+	name := strings.TrimSpace(ctx.PostForm("name"))
+	if name == "" {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "missing 'name'")
+		return
+	}
+	var reason, q *string
+	if value := strings.TrimSpace(ctx.PostForm("reason")); value != "" {
+		reason = &value
+	}
+	creator := ctx.GetString("uid")
+	if value := strings.TrimSpace(ctx.PostForm("query")); value != "" {
+		if _, _, err := models.LegalHoldExpr(value, creator, nil); err != nil {
+			models.SendErrorMessage(ctx, http.StatusBadRequest, "invalid query: "+err.Error())
+			return
+		}
+		q = &value
+	}
+	var documents []int64
+	for _, value := range ctx.PostFormArray("documents") {
+		id, ok := parse(ctx, toInt64, value)
+		if !ok {
+			return
+		}
+		documents = append(documents, id)
+	}
+	slices.Sort(documents)
+	documents = slices.Compact(documents)
+	if q == nil && len(documents) == 0 {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "missing 'query' or 'documents'")
+		return
+	}
+
+	const (
+		insertSQL = `INSERT INTO legal_holds (name, reason, query, creator, created) ` +
+			`VALUES ($1, $2, $3, $4, $5) RETURNING id`
+		documentsSQL = `INSERT INTO legal_hold_documents (legal_holds_id, documents_id) ` +
+			`SELECT $1, id FROM documents WHERE id = ANY($2)`
+	)
+	var (
+		now     = time.Now().UTC()
+		id      int64
+		missing bool
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tx, err := conn.BeginTx(rctx, pgx.TxOptions{})
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(rctx)
+			if err := tx.QueryRow(rctx, insertSQL, name, reason, q, creator, now).Scan(&id); err != nil {
+				return err
+			}
+			if len(documents) > 0 {
+				tags, err := tx.Exec(rctx, documentsSQL, id, documents)
+				if err != nil {
+					return err
+				}
+				if missing = tags.RowsAffected() != int64(len(documents)); missing {
+					return nil
+				}
+			}
+			if err := logLegalHold(rctx, tx, models.LegalHoldEvent,
+				now, c.currentUser(ctx), id, q != nil); err != nil {
+				return err
+			}
+			return tx.Commit(rctx)
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if missing {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "unknown documents")
+		return
+	}
+	ctx.JSON(http.StatusCreated, models.ID{ID: id})
+}
+
+// viewLegalHolds is an endpoint that returns the legal holds.
+//
+//	@Summary		Returns the legal holds.
+//	@Description	Returns the legal holds, the latest first.
+//	@Param			active	query	bool	false	"Only return holds which are not released"
+//	@Produce		json
+//	@Success		200	{array}		legalHold
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/legalholds [get]
+func (c *Controller) viewLegalHolds(ctx *gin.Context) {
+	active, ok := parse(ctx, strconv.ParseBool, ctx.DefaultQuery("active", "false"))
+	if !ok {
+		return
+	}
+	const whereSQL = `WHERE NOT $1 OR released IS NULL ORDER BY created DESC, id DESC`
+	holds := []legalHold{}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, selectLegalHoldSQL+whereSQL, active)
+			defer rows.Close()
+			for rows.Next() {
+				var lh legalHold
+				if err := scanLegalHold(rows, &lh); err != nil {
+					return err
+				}
+				holds = append(holds, lh)
+			}
+			return rows.Err()
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, holds)
+}
+
+// viewLegalHold is an endpoint that returns a legal hold.
+//
+//	@Summary		Returns a legal hold.
+//	@Description	Returns the legal hold with the explicitly held documents.
+//	@Param			id	path	int	true	"Legal hold ID"
+//	@Produce		json
+//	@Success		200	{object}	legalHold
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/legalholds/{id} [get]
+func (c *Controller) viewLegalHold(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	var lh legalHold
+	switch err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			return scanLegalHold(conn.QueryRow(rctx, selectLegalHoldSQL+`WHERE id = $1`, id), &lh)
+		}, 0,
+	); {
+	case errors.Is(err, pgx.ErrNoRows):
+		models.SendErrorMessage(ctx, http.StatusNotFound, "legal hold not found")
+	case err != nil:
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+	default:
+		ctx.JSON(http.StatusOK, &lh)
+	}
+}
+
+// releaseLegalHold is an endpoint that releases a legal hold.
+//
+//	@Summary		Releases a legal hold.
+//	@Description	Releases the legal hold so that its documents can be deleted
+//	@Description	again if they are not held by other legal holds.
+//	@Param			id	path	int	true	"Legal hold ID"
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/legalholds/{id} [delete]
+func (c *Controller) releaseLegalHold(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	const releaseSQL = `UPDATE legal_holds SET (releaser, released) = ($1, $2) ` +
+		`WHERE id = $3 AND released IS NULL ` +
+		`RETURNING query IS NOT NULL`
+	var (
+		now      = time.Now().UTC()
+		released bool
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tx, err := conn.BeginTx(rctx, pgx.TxOptions{})
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(rctx)
+			var hasQuery bool
+			switch err := tx.QueryRow(
+				rctx, releaseSQL, ctx.GetString("uid"), now, id,
+			).Scan(&hasQuery); {
+			case errors.Is(err, pgx.ErrNoRows):
+				return nil
+			case err != nil:
+				return err
+			}
+			if err := logLegalHold(rctx, tx, models.ReleaseLegalHoldEvent,
+				now, c.currentUser(ctx), id, hasQuery); err != nil {
+				return err
+			}
+			released = true
+			return tx.Commit(rctx)
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if !released {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "legal hold not found")
+		return
+	}
+	models.SendSuccess(ctx, http.StatusOK, "legal hold released")
+}
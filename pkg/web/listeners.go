@@ -0,0 +1,39 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+type adminListenerKey struct{}
+
+// AdminListenerContext marks a context as belonging to the admin listener.
+// To be used as the base context of the server of the admin listener.
+func AdminListenerContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, adminListenerKey{}, true)
+}
+
+// onAdminListener rejects requests to administrative endpoints
+// which did not come in over the admin listener if one is configured.
+// The endpoints are hidden as if they would not exist.
+func (c *Controller) onAdminListener(ctx *gin.Context) {
+	if c.cfg.Web.AdminListener() &&
+		ctx.Request.Context().Value(adminListenerKey{}) == nil {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "not found")
+		ctx.Abort()
+		return
+	}
+	ctx.Next()
+}
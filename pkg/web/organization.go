@@ -0,0 +1,487 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// team is a node of the organization hierarchy.
+type team struct {
+	ID          int64    `json:"id"`
+	Name        string   `json:"name" binding:"required"`
+	Description *string  `json:"description,omitempty"`
+	Group       *string  `json:"group,omitempty"`
+	Parent      *int64   `json:"parent,omitempty"`
+	Leads       []string `json:"leads"`
+	Sources     []int64  `json:"sources"`
+	Members     []string `json:"members,omitempty"`
+}
+
+// userOrganization are the teams of the current user.
+type userOrganization struct {
+	// Teams are the teams the user is a member of including their parent teams.
+	Teams []int64 `json:"teams"`
+	// Leads are the teams the user leads including their sub-teams.
+	Leads []int64 `json:"leads"`
+}
+
+// rowQuerier is implemented by the connections and the transactions.
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// inTeam checks if the user is a member of the team or one of its sub-teams.
+func inTeam(rctx context.Context, q rowQuerier, user string, teamID int64) (bool, error) {
+	const sql = `SELECT $2::int IN (SELECT user_teams($1))`
+	var member bool
+	err := q.QueryRow(rctx, sql, user, teamID).Scan(&member)
+	return member, err
+}
+
+// leadsTeam checks if the user leads the team or one of its parent teams.
+func leadsTeam(rctx context.Context, q rowQuerier, user string, teamID int64) (bool, error) {
+	const sql = `SELECT $2::int IN (SELECT led_teams($1))`
+	var lead bool
+	err := q.QueryRow(rctx, sql, user, teamID).Scan(&lead)
+	return lead, err
+}
+
+// leadsMember checks if the lead leads a team the member belongs to.
+func leadsMember(rctx context.Context, q rowQuerier, lead, member string) (bool, error) {
+	const sql = `SELECT EXISTS(SELECT 1 FROM team_members ` +
+		`WHERE "user" = $2 AND teams_id IN (SELECT led_teams($1)))`
+	var leads bool
+	err := q.QueryRow(rctx, sql, lead, member).Scan(&leads)
+	return leads, err
+}
+
+// mayShareWithTeam checks if the current user is allowed to share
+// with a team. Admins are allowed to share with all teams.
+func (c *Controller) mayShareWithTeam(
+	ctx *gin.Context,
+	rctx context.Context,
+	q rowQuerier,
+	teamID int64,
+) (bool, error) {
+	if c.hasAnyRole(ctx, models.Admin) {
+		return true, nil
+	}
+	user := ctx.GetString("uid")
+	if member, err := inTeam(rctx, q, user, teamID); err != nil || member {
+		return member, err
+	}
+	return leadsTeam(rctx, q, user, teamID)
+}
+
+// syncTeamMembers updates the team memberships of the current
+// user from the Keycloak groups of the user.
+func (c *Controller) syncTeamMembers(ctx *gin.Context, rctx context.Context, conn *pgxpool.Conn) error {
+	const (
+		deleteSQL = `DELETE FROM team_members USING teams ` +
+			`WHERE team_members.teams_id = teams.id AND team_members."user" = $1 ` +
+			`AND teams.group_name IS NOT NULL AND NOT teams.group_name = ANY($2)`
+		upsertSQL = `INSERT INTO team_members (teams_id, "user") ` +
+			`SELECT id, $1 FROM teams WHERE group_name = ANY($2) ` +
+			`ON CONFLICT (teams_id, "user") DO UPDATE SET seen = CURRENT_TIMESTAMP`
+	)
+	user := ctx.GetString("uid")
+	if user == "" {
+		return nil
+	}
+	groups := c.groups(ctx)
+	batch := &pgx.Batch{}
+	batch.Queue(deleteSQL, user, groups)
+	batch.Queue(upsertSQL, user, groups)
+	return conn.SendBatch(rctx, batch).Close()
+}
+
+// normalizeTeam checks and cleans up the input of a team.
+func normalizeTeam(t *team) error {
+	if t.Name = strings.TrimSpace(t.Name); t.Name == "" {
+		return errors.New("missing name")
+	}
+	if t.Group != nil {
+		if group := normalizeGroup(*t.Group); group == "" {
+			t.Group = nil
+		} else {
+			t.Group = &group
+		}
+	}
+	leads := make([]string, 0, len(t.Leads))
+	for _, lead := range t.Leads {
+		if lead = strings.TrimSpace(lead); lead != "" && !slices.Contains(leads, lead) {
+			leads = append(leads, lead)
+		}
+	}
+	t.Leads = leads
+	if t.Sources == nil {
+		t.Sources = []int64{}
+	}
+	return nil
+}
+
+// storeTeamSources replaces the sources of a team.
+func storeTeamSources(rctx context.Context, tx pgx.Tx, id int64, sources []int64) error {
+	const (
+		deleteSQL = `DELETE FROM team_sources WHERE teams_id = $1`
+		insertSQL = `INSERT INTO team_sources (teams_id, sources_id) ` +
+			`SELECT $1, unnest($2::int[]) ON CONFLICT DO NOTHING`
+	)
+	if _, err := tx.Exec(rctx, deleteSQL, id); err != nil {
+		return err
+	}
+	_, err := tx.Exec(rctx, insertSQL, id, sources)
+	return err
+}
+
+// sendTeamError sends the constraint violations of storing a team
+// as client errors.
+func sendTeamError(ctx *gin.Context, err error) {
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.As(err, &pgErr) && pgErr.Code == "23505":
+		models.SendErrorMessage(ctx, http.StatusConflict, "name or group already in use")
+	case errors.As(err, &pgErr) && pgErr.Code == "23503":
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "unknown parent team or source")
+	case errors.As(err, &pgErr) && pgErr.Code == "23514":
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "a team cannot be its own parent")
+	default:
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+	}
+}
+
+// listTeams is an endpoint that returns all teams.
+//
+//	@Summary		Returns the teams.
+//	@Description	Returns the organization hierarchy of the teams with
+//	@Description	their Keycloak groups, leads and sources.
+//	@Produce		json
+//	@Success		200	{array}		team
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/organization/teams [get]
+func (c *Controller) listTeams(ctx *gin.Context) {
+	const selectSQL = `SELECT id, name, description, group_name, parent_id, leads, ` +
+		`coalesce((SELECT array_agg(sources_id ORDER BY sources_id) ` +
+		`FROM team_sources WHERE teams_id = teams.id), '{}') ` +
+		`FROM teams ORDER BY name`
+	var teams []team
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, selectSQL)
+			var err error
+			teams, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (team, error) {
+				var t team
+				err := row.Scan(
+					&t.ID, &t.Name, &t.Description, &t.Group, &t.Parent,
+					&t.Leads, &t.Sources)
+				return t, err
+			})
+			return err
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if teams == nil {
+		teams = []team{}
+	}
+	ctx.JSON(http.StatusOK, teams)
+}
+
+// viewTeam is an endpoint that returns a team with its members.
+//
+//	@Summary		Returns a team.
+//	@Description	Returns the team with its members. The members are the users
+//	@Description	of the Keycloak group of the team seen since their last login.
+//	@Description	With subteams the members of the sub-teams are included.
+//	@Param			id			path	int		true	"Team ID"
+//	@Param			subteams	query	bool	false	"Include the members of the sub-teams"
+//	@Produce		json
+//	@Success		200	{object}	team
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/organization/teams/{id} [get]
+func (c *Controller) viewTeam(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	subteams, ok := parse(ctx, strconv.ParseBool, ctx.DefaultQuery("subteams", "false"))
+	if !ok {
+		return
+	}
+	const (
+		teamSQL = `SELECT name, description, group_name, parent_id, leads, ` +
+			`coalesce((SELECT array_agg(sources_id ORDER BY sources_id) ` +
+			`FROM team_sources WHERE teams_id = teams.id), '{}') ` +
+			`FROM teams WHERE id = $1`
+		membersSQL = `SELECT coalesce(array_agg(DISTINCT "user" ORDER BY "user"), '{}') ` +
+			`FROM team_members ` +
+			`WHERE teams_id = $1 OR ($2 AND teams_id IN (SELECT team_subtree($1)))`
+	)
+	t := team{ID: id}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			if err := conn.QueryRow(rctx, teamSQL, id).Scan(
+				&t.Name, &t.Description, &t.Group, &t.Parent, &t.Leads, &t.Sources,
+			); err != nil {
+				return err
+			}
+			return conn.QueryRow(rctx, membersSQL, id, subteams).Scan(&t.Members)
+		}, 0,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			models.SendErrorMessage(ctx, http.StatusNotFound, "team not found")
+			return
+		}
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, &t)
+}
+
+// createTeam is an endpoint that creates a team.
+//
+//	@Summary		Creates a team.
+//	@Description	Creates a team. The members of the team are the users of its
+//	@Description	Keycloak group. The leads are allowed to assign documents to the
+//	@Description	members and to subscribe stored queries for the team.
+//	@Param			team	body	team	true	"Team"
+//	@Accept			json
+//	@Produce		json
+//	@Success		201	{object}	models.ID
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		409	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/organization/teams [post]
+func (c *Controller) createTeam(ctx *gin.Context) {
+	var t team
+	if err := ctx.ShouldBindJSON(&t); err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if err := normalizeTeam(&t); err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	const insertSQL = `INSERT INTO teams (name, description, group_name, parent_id, leads) ` +
+		`VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	var id int64
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tx, err := conn.BeginTx(rctx, pgx.TxOptions{})
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(rctx)
+			if err := tx.QueryRow(rctx, insertSQL,
+				t.Name, t.Description, t.Group, t.Parent, t.Leads,
+			).Scan(&id); err != nil {
+				return err
+			}
+			if err := storeTeamSources(rctx, tx, id, t.Sources); err != nil {
+				return err
+			}
+			return tx.Commit(rctx)
+		}, 0,
+	); err != nil {
+		sendTeamError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, models.ID{ID: id})
+}
+
+// updateTeam is an endpoint that updates a team.
+//
+//	@Summary		Updates a team.
+//	@Description	Replaces the name, description, Keycloak group, parent team,
+//	@Description	leads and sources of the team. The members of a changed group
+//	@Description	are taken over on their next login.
+//	@Param			id		path	int		true	"Team ID"
+//	@Param			team	body	team	true	"Team"
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		409	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/organization/teams/{id} [put]
+func (c *Controller) updateTeam(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	var t team
+	if err := ctx.ShouldBindJSON(&t); err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if err := normalizeTeam(&t); err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	const (
+		groupSQL  = `SELECT group_name FROM teams WHERE id = $1 FOR UPDATE`
+		cycleSQL  = `SELECT $1::int IN (SELECT team_subtree($2))`
+		updateSQL = `UPDATE teams SET name = $2, description = $3, ` +
+			`group_name = $4, parent_id = $5, leads = $6 WHERE id = $1`
+		membersSQL = `DELETE FROM team_members WHERE teams_id = $1`
+	)
+	var notFound, cycle bool
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tx, err := conn.BeginTx(rctx, pgx.TxOptions{})
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(rctx)
+			var oldGroup *string
+			switch err := tx.QueryRow(rctx, groupSQL, id).Scan(&oldGroup); {
+			case errors.Is(err, pgx.ErrNoRows):
+				notFound = true
+				return nil
+			case err != nil:
+				return err
+			}
+			if t.Parent != nil {
+				if err := tx.QueryRow(rctx, cycleSQL, *t.Parent, id).Scan(&cycle); err != nil || cycle {
+					return err
+				}
+			}
+			if _, err := tx.Exec(rctx, updateSQL,
+				id, t.Name, t.Description, t.Group, t.Parent, t.Leads,
+			); err != nil {
+				return err
+			}
+			// The memberships of a changed group are outdated.
+			if (oldGroup == nil) != (t.Group == nil) ||
+				(oldGroup != nil && *oldGroup != *t.Group) {
+				if _, err := tx.Exec(rctx, membersSQL, id); err != nil {
+					return err
+				}
+			}
+			if err := storeTeamSources(rctx, tx, id, t.Sources); err != nil {
+				return err
+			}
+			return tx.Commit(rctx)
+		}, 0,
+	); err != nil {
+		sendTeamError(ctx, err)
+		return
+	}
+	switch {
+	case notFound:
+		models.SendErrorMessage(ctx, http.StatusNotFound, "team not found")
+	case cycle:
+		models.SendErrorMessage(ctx, http.StatusBadRequest,
+			"the parent team cannot be the team or one of its sub-teams")
+	default:
+		models.SendSuccess(ctx, http.StatusOK, "updated")
+	}
+}
+
+// deleteTeam is an endpoint that deletes a team.
+//
+//	@Summary		Deletes a team.
+//	@Description	Deletes the team, its memberships and its subscriptions.
+//	@Description	The sub-teams become top level teams and the shared
+//	@Description	stored queries stay with their definers.
+//	@Param			id	path	int	true	"Team ID"
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/organization/teams/{id} [delete]
+func (c *Controller) deleteTeam(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	const deleteSQL = `DELETE FROM teams WHERE id = $1`
+	var deleted bool
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tag, err := conn.Exec(rctx, deleteSQL, id)
+			deleted = tag.RowsAffected() > 0
+			return err
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if !deleted {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "team not found")
+		return
+	}
+	models.SendSuccess(ctx, http.StatusOK, "deleted")
+}
+
+// viewUserOrganization is an endpoint that returns the teams of the current user.
+//
+//	@Summary		Returns the teams of the current user.
+//	@Description	Updates the memberships of the current user from the Keycloak
+//	@Description	groups and returns the teams the user is a member of and the
+//	@Description	teams the user leads, both with their related teams.
+//	@Produce		json
+//	@Success		200	{object}	userOrganization
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/organization/me [get]
+func (c *Controller) viewUserOrganization(ctx *gin.Context) {
+	const selectSQL = `SELECT ` +
+		`coalesce((SELECT array_agg(id ORDER BY id) FROM user_teams($1) AS id), '{}'), ` +
+		`coalesce((SELECT array_agg(id ORDER BY id) FROM led_teams($1) AS id), '{}')`
+	var org userOrganization
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			if err := c.syncTeamMembers(ctx, rctx, conn); err != nil {
+				return err
+			}
+			return conn.QueryRow(rctx, selectSQL, ctx.GetString("uid")).Scan(
+				&org.Teams, &org.Leads)
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, &org)
+}
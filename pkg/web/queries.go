@@ -99,6 +99,22 @@ func (c *Controller) createStoredQuery(ctx *gin.Context) {
 		}
 	}
 
+	// Team to share with
+	if team := ctx.PostForm("team"); team != "" {
+		teamID, ok := parse(ctx, toInt64, team)
+		if !ok {
+			return
+		}
+		sq.Team = &teamID
+	}
+
+	// Roles to share with
+	sq.SharedRoles = sharedRoles(ctx.PostForm("shared_roles"))
+	if !c.mayShareWithRoles(ctx, sq.SharedRoles) {
+		models.SendErrorMessage(ctx, http.StatusForbidden, "queries can only be shared with own roles")
+		return
+	}
+
 	parser := query.Parser{Mode: sq.Kind}
 
 	// The query to filter the documents.
@@ -155,15 +171,24 @@ func (c *Controller) createStoredQuery(ctx *gin.Context) {
 		`orders,` +
 		`dashboard,` +
 		`role,` +
-		`default_query ` +
-		`) VALUES ($1::stored_queries_kind, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)` +
+		`default_query,` +
+		`teams_id,` +
+		`shared_roles ` +
+		`) VALUES ($1::stored_queries_kind, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)` +
 		`RETURNING id, num`
 
 	var queryID, queryNum int64
+	var notInTeam bool
 
 	if err := c.db.Run(
 		ctx.Request.Context(),
 		func(rctx context.Context, conn *pgxpool.Conn) error {
+			if sq.Team != nil {
+				allowed, err := c.mayShareWithTeam(ctx, rctx, conn, *sq.Team)
+				if notInTeam = !allowed; err != nil || notInTeam {
+					return err
+				}
+			}
 			return conn.QueryRow(rctx, insertSQL,
 				sq.Kind.String(),
 				sq.Definer,
@@ -176,6 +201,8 @@ func (c *Controller) createStoredQuery(ctx *gin.Context) {
 				sq.Dashboard,
 				sq.Role,
 				sq.DefaultQuery,
+				sq.Team,
+				sq.SharedRoles,
 			).Scan(&queryID, &queryNum)
 		}, 0,
 	); err != nil {
@@ -185,10 +212,19 @@ func (c *Controller) createStoredQuery(ctx *gin.Context) {
 			models.SendErrorMessage(ctx, http.StatusConflict, "already in database")
 			return
 		}
-		slog.Error("database error", "err", err)
+		// Foreign key violation
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			models.SendErrorMessage(ctx, http.StatusBadRequest, "unknown team")
+			return
+		}
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
+	if notInTeam {
+		models.SendErrorMessage(ctx, http.StatusForbidden, "queries can only be shared with own teams")
+		return
+	}
 	ctx.JSON(http.StatusCreated, createResult{
 		ID:  queryID,
 		Num: queryNum,
@@ -249,7 +285,7 @@ func (c *Controller) updateOrder(ctx *gin.Context) {
 			return tx.Commit(rctx)
 		}, 0,
 	); err != nil {
-		slog.Error("database error", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -267,7 +303,7 @@ func (c *Controller) updateOrder(ctx *gin.Context) {
 //	@Failure		500	{object}	models.Error
 //	@Router			/queries [get]
 func (c *Controller) listStoredQueries(ctx *gin.Context) {
-	const selectSQL = `SELECT ` +
+	selectSQL := `SELECT ` +
 		`id,` +
 		`kind::text,` +
 		`definer,` +
@@ -280,9 +316,11 @@ func (c *Controller) listStoredQueries(ctx *gin.Context) {
 		`orders,` +
 		`dashboard,` +
 		`role,` +
-		`default_query ` +
-		`FROM stored_queries WHERE ` +
-		`definer = $1 OR global ` +
+		`default_query,` +
+		`teams_id,` +
+		`shared_roles,` +
+		`cloned_from ` +
+		`FROM stored_queries WHERE ` + visibleQuerySQL(1, 2) + ` ` +
 		`ORDER BY global desc, definer, num`
 
 	var queries []*models.StoredQuery
@@ -290,8 +328,12 @@ func (c *Controller) listStoredQueries(ctx *gin.Context) {
 	if err := c.db.Run(
 		ctx.Request.Context(),
 		func(rctx context.Context, conn *pgxpool.Conn) error {
+			// New users get the default queries of their teams.
+			if err := c.provisionUser(ctx, rctx, conn); err != nil {
+				return err
+			}
 			definer := ctx.GetString("uid")
-			rows, _ := conn.Query(rctx, selectSQL, definer)
+			rows, _ := conn.Query(rctx, selectSQL, definer, c.realmRoles(ctx))
 			var err error
 			queries, err = pgx.CollectRows(rows,
 				func(row pgx.CollectableRow) (*models.StoredQuery, error) {
@@ -310,6 +352,9 @@ func (c *Controller) listStoredQueries(ctx *gin.Context) {
 						&storedQuery.Dashboard,
 						&storedQuery.Role,
 						&storedQuery.DefaultQuery,
+						&storedQuery.Team,
+						&storedQuery.SharedRoles,
+						&storedQuery.ClonedFrom,
 					); err != nil {
 						return nil, err
 					}
@@ -318,7 +363,7 @@ func (c *Controller) listStoredQueries(ctx *gin.Context) {
 			return err
 		}, 0,
 	); err != nil {
-		slog.Error("database error", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -376,7 +421,7 @@ func (c *Controller) deleteStoredQuery(ctx *gin.Context) {
 			return err
 		}, 0,
 	); err != nil {
-		slog.Error("database error", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -406,7 +451,7 @@ func (c *Controller) fetchStoredQuery(ctx *gin.Context) {
 		return
 	}
 
-	const selectSQL = `SELECT ` +
+	selectSQL := `SELECT ` +
 		`kind::text,` +
 		`definer,` +
 		`global,` +
@@ -418,9 +463,11 @@ func (c *Controller) fetchStoredQuery(ctx *gin.Context) {
 		`orders,` +
 		`dashboard,` +
 		`role,` +
-		`default_query ` +
-		`FROM stored_queries WHERE id = $1 AND ` +
-		`(global OR definer = $2)`
+		`default_query,` +
+		`teams_id,` +
+		`shared_roles,` +
+		`cloned_from ` +
+		`FROM stored_queries WHERE id = $1 AND ` + visibleQuerySQL(2, 3)
 
 	storedQuery := models.StoredQuery{
 		ID: queryID,
@@ -429,7 +476,7 @@ func (c *Controller) fetchStoredQuery(ctx *gin.Context) {
 		ctx.Request.Context(),
 		func(rctx context.Context, conn *pgxpool.Conn) error {
 			definer := ctx.GetString("uid")
-			return conn.QueryRow(rctx, selectSQL, queryID, definer).Scan(
+			return conn.QueryRow(rctx, selectSQL, queryID, definer, c.realmRoles(ctx)).Scan(
 				&storedQuery.Kind,
 				&storedQuery.Definer,
 				&storedQuery.Global,
@@ -442,6 +489,9 @@ func (c *Controller) fetchStoredQuery(ctx *gin.Context) {
 				&storedQuery.Dashboard,
 				&storedQuery.Role,
 				&storedQuery.DefaultQuery,
+				&storedQuery.Team,
+				&storedQuery.SharedRoles,
+				&storedQuery.ClonedFrom,
 			)
 		}, 0,
 	); err != nil {
@@ -449,7 +499,7 @@ func (c *Controller) fetchStoredQuery(ctx *gin.Context) {
 		case errors.Is(err, pgx.ErrNoRows):
 			models.SendErrorMessage(ctx, http.StatusNotFound, "not found")
 		default:
-			slog.Error("database error", "err", err)
+			slog.ErrorContext(ctx, "database error", "err", err)
 			models.SendError(ctx, http.StatusInternalServerError, err)
 		}
 		return
@@ -490,7 +540,9 @@ func (c *Controller) updateStoredQuery(ctx *gin.Context) {
 			`dashboard,` +
 			`role,` +
 			`default_query,` +
-			`definer ` +
+			`definer,` +
+			`teams_id,` +
+			`shared_roles ` +
 			`FROM stored_queries WHERE id = $1 AND `
 		selectNoAdminSQL = selectSQLPrefix +
 			`definer = $2`
@@ -534,6 +586,8 @@ func (c *Controller) updateStoredQuery(ctx *gin.Context) {
 				&sq.Role,
 				&sq.DefaultQuery,
 				&sq.Definer,
+				&sq.Team,
+				&sq.SharedRoles,
 			); err != nil {
 				if errors.Is(err, pgx.ErrNoRows) {
 					notFound = true
@@ -651,6 +705,42 @@ func (c *Controller) updateStoredQuery(ctx *gin.Context) {
 				add(global != sq.Global, "global", global)
 			}
 
+			// Check team
+			if team, ok := ctx.GetPostForm("team"); ok {
+				if team == "" {
+					add(sq.Team != nil, "teams_id", nil)
+				} else {
+					teamID, err := toInt64(team)
+					if err != nil {
+						bad = "bad 'team' value: " + err.Error()
+						return nil
+					}
+					if sq.Team == nil || teamID != *sq.Team {
+						allowed, err := c.mayShareWithTeam(ctx, rctx, tx, teamID)
+						if err != nil {
+							return err
+						}
+						if !allowed {
+							bad = "queries can only be shared with own teams"
+							return nil
+						}
+						add(true, "teams_id", teamID)
+					}
+				}
+			}
+
+			// Check roles to share with
+			if sr, ok := ctx.GetPostForm("shared_roles"); ok {
+				roles := sharedRoles(sr)
+				if !slices.Equal(roles, sq.SharedRoles) {
+					if !c.mayShareWithRoles(ctx, roles) {
+						bad = "queries can only be shared with own roles"
+						return nil
+					}
+					add(true, "shared_roles", roles)
+				}
+			}
+
 			// Check num
 			if glb, ok := ctx.GetPostForm("num"); ok {
 				num, err := strconv.ParseInt(glb, 10, 64)
@@ -723,7 +813,7 @@ func (c *Controller) updateStoredQuery(ctx *gin.Context) {
 				placeholders.String(),
 				len(values))
 
-			slog.Debug("update statement", "stmt", updateSQL)
+			slog.DebugContext(ctx, "update statement", "stmt", updateSQL)
 
 			tag, err := tx.Exec(rctx, updateSQL, values...)
 			if err != nil {
@@ -736,10 +826,13 @@ func (c *Controller) updateStoredQuery(ctx *gin.Context) {
 		// As name and num changes can cause unique constraint violations
 		// don't report these not as internal server errors as this expected.
 		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		switch {
+		case errors.As(err, &pgErr) && pgErr.Code == "23505":
 			bad = "not a unique value: %s" + err.Error()
-		} else {
-			slog.Error("database error", "err", err)
+		case errors.As(err, &pgErr) && pgErr.Code == "23503":
+			bad = "unknown team"
+		default:
+			slog.ErrorContext(ctx, "database error", "err", err)
 			models.SendError(ctx, http.StatusInternalServerError, err)
 			return
 		}
@@ -793,7 +886,7 @@ func (c *Controller) getDefaultQueryExclusion(ctx *gin.Context) {
 		case errors.Is(err, pgx.ErrNoRows):
 			models.SendErrorMessage(ctx, http.StatusNotFound, "not found")
 		default:
-			slog.Error("database error", "err", err)
+			slog.ErrorContext(ctx, "database error", "err", err)
 			models.SendError(ctx, http.StatusInternalServerError, err)
 		}
 		return
@@ -835,7 +928,7 @@ func (c *Controller) deleteDefaultQueryExclusion(ctx *gin.Context) {
 			return err
 		}, 0,
 	); err != nil {
-		slog.Error("database error", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -889,7 +982,7 @@ func (c *Controller) insertDefaultQueryExclusion(ctx *gin.Context) {
 			models.SendErrorMessage(ctx, http.StatusConflict, "already in database")
 			return
 		}
-		slog.Error("database error", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -0,0 +1,168 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// queryHistoryEntry is a recently executed ad-hoc query.
+type queryHistoryEntry struct {
+	ID         int64     `json:"id"`
+	Executed   time.Time `json:"executed"`
+	Advisories bool      `json:"advisories"`
+	Query      string    `json:"query"`
+	Columns    string    `json:"columns,omitempty"`
+	Orders     string    `json:"orders,omitempty"`
+}
+
+// recordQuery records the ad-hoc query of the current request
+// in the query history of the user if it is enabled.
+// Requests without an explicit query are not recorded.
+// The recording is done in the background and does not
+// delay the search.
+func (c *Controller) recordQuery(ctx *gin.Context, advisories bool) {
+	qh := &c.cfg.QueryHistory
+	if !qh.Enabled {
+		return
+	}
+	q, ok := ctx.GetQuery("query")
+	if !ok || q == "" {
+		return
+	}
+	var (
+		user    = ctx.GetString("uid")
+		columns = ctx.Query("columns")
+		orders  = ctx.Query("orders")
+		rctx    = context.WithoutCancel(ctx.Request.Context())
+	)
+	const (
+		// Re-running a query moves it to the top.
+		deleteSQL = `DELETE FROM query_history ` +
+			`WHERE "user" = $1 AND advisories = $2 AND query = $3 ` +
+			`AND columns = $4 AND orders = $5`
+		insertSQL = `INSERT INTO query_history ` +
+			`("user", advisories, query, columns, orders) ` +
+			`VALUES ($1, $2, $3, $4, $5)`
+		pruneSQL = `DELETE FROM query_history ` +
+			`WHERE "user" = $1 AND (executed < $2 OR id NOT IN (` +
+			`SELECT id FROM query_history WHERE "user" = $1 ` +
+			`ORDER BY executed DESC, id DESC LIMIT $3))`
+	)
+	go func() {
+		var before time.Time
+		if qh.Retention > 0 {
+			before = time.Now().Add(-qh.Retention)
+		}
+		if err := c.db.Run(
+			rctx,
+			func(rctx context.Context, conn *pgxpool.Conn) error {
+				tx, err := conn.Begin(rctx)
+				if err != nil {
+					return err
+				}
+				defer tx.Rollback(rctx)
+				batch := &pgx.Batch{}
+				batch.Queue(deleteSQL, user, advisories, q, columns, orders)
+				batch.Queue(insertSQL, user, advisories, q, columns, orders)
+				batch.Queue(pruneSQL, user, before, qh.MaxEntries)
+				if err := tx.SendBatch(rctx, batch).Close(); err != nil {
+					return err
+				}
+				return tx.Commit(rctx)
+			}, 0,
+		); err != nil {
+			slog.WarnContext(rctx, "recording query history failed", "err", err)
+		}
+	}()
+}
+
+// viewQueryHistory is an endpoint that returns the query history of the current user.
+//
+//	@Summary		Returns the query history.
+//	@Description	Returns the recently executed ad-hoc document queries
+//	@Description	of the current user, the latest first.
+//	@Param			limit	query	int	false	"Maximal number of entries"
+//	@Produce		json
+//	@Success		200	{array}		web.queryHistoryEntry
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/queries/history [get]
+func (c *Controller) viewQueryHistory(ctx *gin.Context) {
+	limit := int64(c.cfg.QueryHistory.MaxEntries)
+	if value := ctx.Query("limit"); value != "" {
+		var ok bool
+		if limit, ok = parse(ctx, toInt64, value); !ok {
+			return
+		}
+	}
+	const selectSQL = `SELECT id, executed, advisories, query, columns, orders ` +
+		`FROM query_history WHERE "user" = $1 ` +
+		`ORDER BY executed DESC, id DESC LIMIT $2`
+	var entries []queryHistoryEntry
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, selectSQL, ctx.GetString("uid"), max(limit, 0))
+			var err error
+			entries, err = pgx.CollectRows(rows,
+				func(row pgx.CollectableRow) (queryHistoryEntry, error) {
+					var e queryHistoryEntry
+					err := row.Scan(&e.ID, &e.Executed, &e.Advisories,
+						&e.Query, &e.Columns, &e.Orders)
+					e.Executed = e.Executed.UTC()
+					return e, err
+				})
+			return err
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if entries == nil {
+		entries = []queryHistoryEntry{}
+	}
+	ctx.JSON(http.StatusOK, entries)
+}
+
+// clearQueryHistory is an endpoint that clears the query history of the current user.
+//
+//	@Summary		Clears the query history.
+//	@Description	Removes all recorded queries of the current user.
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/queries/history [delete]
+func (c *Controller) clearQueryHistory(ctx *gin.Context) {
+	const deleteSQL = `DELETE FROM query_history WHERE "user" = $1`
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			_, err := conn.Exec(rctx, deleteSQL, ctx.GetString("uid"))
+			return err
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	models.SendSuccess(ctx, http.StatusOK, "query history cleared")
+}
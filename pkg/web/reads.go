@@ -0,0 +1,201 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/database/query"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// maxReadDocuments is the maximal number of documents
+// which can be marked explicitly in one request.
+const maxReadDocuments = 1000
+
+// markReadSQL marks a document as read by a user.
+const markReadSQL = `INSERT INTO document_reads (documents_id, "user") ` +
+	`VALUES ($1, $2) ON CONFLICT (documents_id, "user") DO NOTHING`
+
+// readMarks is the input of the bulk reading status endpoint.
+type readMarks struct {
+	Documents []int64 `json:"documents"`
+	Query     string  `json:"query"`
+	Read      *bool   `json:"read"`
+}
+
+// readMarksResult tells how many documents were marked.
+type readMarksResult struct {
+	Documents int64 `json:"documents"`
+}
+
+// setReadStatus sets the reading status of the current user
+// for the visible documents matching the expression.
+// Returns the number of matching documents.
+func (c *Controller) setReadStatus(
+	ctx *gin.Context,
+	parser *query.Parser,
+	expr *query.Expr,
+	read bool,
+) (int64, error) {
+	builder, err := query.NewAdvancedSQLBuilder(
+		query.AdvancedSQLBuilderExpr(expr),
+		query.AdvancedSQLBuilderFields([]string{"id"}),
+		query.AdvancedSQLBuilderParser(parser))
+	if err != nil {
+		return 0, err
+	}
+	const (
+		markSQL = `INSERT INTO document_reads (documents_id, "user") ` +
+			`SELECT unnest($1::int[]), $2 ON CONFLICT (documents_id, "user") DO NOTHING`
+		unmarkSQL = `DELETE FROM document_reads WHERE documents_id = ANY($1) AND "user" = $2`
+	)
+	var (
+		idsSQL = builder.CreateQuery(-1, -1)
+		user   = ctx.GetString("uid")
+		ids    []int64
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, idsSQL, builder.Replacements...)
+			var err error
+			if ids, err = pgx.CollectRows(rows, pgx.RowTo[int64]); err != nil || len(ids) == 0 {
+				return err
+			}
+			statusSQL := unmarkSQL
+			if read {
+				statusSQL = markSQL
+			}
+			_, err = conn.Exec(rctx, statusSQL, ids, user)
+			return err
+		}, 0,
+	); err != nil {
+		return 0, err
+	}
+	return int64(len(ids)), nil
+}
+
+// setDocumentReadStatus sets the reading status of a single document.
+func (c *Controller) setDocumentReadStatus(ctx *gin.Context, read bool) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	parser := query.Parser{Mode: query.DocumentMode, Me: ctx.GetString("uid")}
+	n, err := c.setReadStatus(ctx, &parser, query.FieldEqInt("id", id), read)
+	switch {
+	case err != nil:
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+	case n == 0:
+		models.SendErrorMessage(ctx, http.StatusNotFound, "document not found")
+	case read:
+		models.SendSuccess(ctx, http.StatusOK, "marked as read")
+	default:
+		models.SendSuccess(ctx, http.StatusOK, "marked as unread")
+	}
+}
+
+// markDocumentRead is an endpoint that marks a document as read.
+//
+//	@Summary		Marks a document as read.
+//	@Description	Marks the document as read by the current user.
+//	@Description	Documents are marked automatically when they are viewed.
+//	@Param			id	path	int	true	"Document ID"
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/documents/{id}/read [put]
+func (c *Controller) markDocumentRead(ctx *gin.Context) {
+	c.setDocumentReadStatus(ctx, true)
+}
+
+// markDocumentUnread is an endpoint that marks a document as unread.
+//
+//	@Summary		Marks a document as unread.
+//	@Description	Marks the document as not read by the current user.
+//	@Param			id	path	int	true	"Document ID"
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/documents/{id}/read [delete]
+func (c *Controller) markDocumentUnread(ctx *gin.Context) {
+	c.setDocumentReadStatus(ctx, false)
+}
+
+// markDocumentsRead is an endpoint that sets the reading status of multiple documents.
+//
+//	@Summary		Sets the reading status of documents.
+//	@Description	Marks the given documents and the documents matching the query
+//	@Description	as read or, if read is false, as unread by the current user.
+//	@Description	If documents and a query are given both have to match.
+//	@Param			input	body	readMarks	true	"Documents, query and status"
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	readMarksResult
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/documents/read [post]
+func (c *Controller) markDocumentsRead(ctx *gin.Context) {
+	var input readMarks
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if len(input.Documents) == 0 && input.Query == "" {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "missing 'documents' or 'query'")
+		return
+	}
+	if len(input.Documents) > maxReadDocuments {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "too many documents")
+		return
+	}
+	parser := query.Parser{
+		Mode:            query.DocumentMode,
+		MinSearchLength: MinSearchLength,
+		Me:              ctx.GetString("uid"),
+	}
+	expr := query.True()
+	if input.Query != "" {
+		var err error
+		if expr, err = parser.Parse(input.Query); err != nil {
+			models.SendError(ctx, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if len(input.Documents) > 0 {
+		some := query.False()
+		for _, id := range input.Documents {
+			some = some.Or(query.FieldEqInt("id", id))
+		}
+		expr = expr.And(some)
+	}
+	read := input.Read == nil || *input.Read
+	n, err := c.setReadStatus(ctx, &parser, expr, read)
+	if err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, readMarksResult{Documents: n})
+}
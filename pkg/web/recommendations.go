@@ -0,0 +1,165 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// inventoryVendor is a vendor of products in the asset inventory.
+type inventoryVendor struct {
+	Name      string  `json:"name"`
+	Documents int64   `json:"documents"`
+	Relevance float64 `json:"relevance"`
+}
+
+// aggregatorRecommendation recommends a listed provider or publisher
+// as a source as it publishes advisories of a vendor in the inventory.
+type aggregatorRecommendation struct {
+	Vendor      inventoryVendor `json:"vendor"`
+	URL         string          `json:"url"`
+	Publisher   string          `json:"publisher"`
+	Aggregators []string        `json:"aggregators"`
+	Available   []string        `json:"available,omitempty"`
+}
+
+// inventoryVendorsSQL extracts the vendors of the products of the
+// documents matching the asset inventory.
+const inventoryVendorsSQL = `SELECT vendor, count(DISTINCT id), max(relevance) FROM (` +
+	`SELECT docs.id, am.relevance, ` +
+	`jsonb_path_query(docs.document, ` +
+	`'strict $.product_tree.** ? (@.category == "vendor").name') #>> '{}' AS vendor ` +
+	`FROM documents docs JOIN asset_matches am ON am.documents_id = docs.id ` +
+	`WHERE am.relevance > 0) AS vendors ` +
+	`GROUP BY vendor ` +
+	`ORDER BY max(relevance) DESC, vendor`
+
+// aggregatorRecommendations is an endpoint that recommends sources
+// for the vendors in the asset inventory.
+//
+//	@Summary		Recommends sources for the vendors in the asset inventory.
+//	@Description	Cross-references the vendors of the products in the documents
+//	@Description	matching the asset inventory with the providers and publishers
+//	@Description	listed by the active aggregators. Listed providers and publishers
+//	@Description	whose names start with the name of a vendor and which are not
+//	@Description	configured as sources yet are recommended. Instead of the vendors
+//	@Description	of the inventory the vendors can be given explicitly.
+//	@Param			vendor	query	[]string	false	"Vendors"
+//	@Produce		json
+//	@Success		200	{array}		aggregatorRecommendation
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/aggregators/recommendations [get]
+func (c *Controller) aggregatorRecommendations(ctx *gin.Context) {
+	var (
+		vendors     []inventoryVendor
+		aggregators []string
+	)
+	for _, name := range ctx.QueryArray("vendor") {
+		vendors = append(vendors, inventoryVendor{Name: name})
+	}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, `SELECT url FROM aggregators WHERE active ORDER BY name`)
+			var err error
+			if aggregators, err = pgx.CollectRows(rows, pgx.RowTo[string]); err != nil {
+				return err
+			}
+			if len(vendors) > 0 {
+				return nil
+			}
+			rows, _ = conn.Query(rctx, inventoryVendorsSQL)
+			vendors, err = pgx.CollectRows(rows,
+				func(row pgx.CollectableRow) (inventoryVendor, error) {
+					var v inventoryVendor
+					err := row.Scan(&v.Name, &v.Documents, &v.Relevance)
+					return v, err
+				})
+			return err
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Collect the listings matching the vendors by URL.
+	recommendations := []*aggregatorRecommendation{}
+	byURL := map[string]*aggregatorRecommendation{}
+	for _, aggregator := range aggregators {
+		ca, err := c.am.Cache.GetAggregator(ctx.Request.Context(), aggregator, c.cfg)
+		if err != nil {
+			slog.WarnContext(ctx, "fetching aggregator failed",
+				"url", aggregator, "err", err)
+			continue
+		}
+		for _, listing := range ca.Listings(aggregator) {
+			if listing.Publisher == nil || listing.Publisher.Name == nil {
+				continue
+			}
+			publisher := *listing.Publisher.Name
+			idx := slices.IndexFunc(vendors, func(v inventoryVendor) bool {
+				return models.VendorMatches(v.Name, publisher)
+			})
+			if idx < 0 {
+				continue
+			}
+			if rec := byURL[listing.URL]; rec != nil {
+				if !slices.Contains(rec.Aggregators, aggregator) {
+					rec.Aggregators = append(rec.Aggregators, aggregator)
+				}
+				continue
+			}
+			rec := &aggregatorRecommendation{
+				Vendor:      vendors[idx],
+				URL:         listing.URL,
+				Publisher:   publisher,
+				Aggregators: []string{aggregator},
+			}
+			byURL[listing.URL] = rec
+			recommendations = append(recommendations, rec)
+		}
+	}
+	if len(recommendations) == 0 {
+		ctx.JSON(http.StatusOK, recommendations)
+		return
+	}
+
+	// Drop the providers already configured as sources.
+	urls := make([]string, len(recommendations))
+	for i, rec := range recommendations {
+		urls[i] = rec.URL
+	}
+	subscribed := map[string]bool{}
+	for _, sub := range c.sm.Subscriptions(urls) {
+		if len(sub.Subscriptions) > 0 {
+			subscribed[sub.URL] = true
+		} else if rec := byURL[sub.URL]; rec != nil {
+			rec.Available = sub.Available
+		}
+	}
+	recommendations = slices.DeleteFunc(recommendations, func(rec *aggregatorRecommendation) bool {
+		return subscribed[rec.URL]
+	})
+	slices.SortStableFunc(recommendations, func(a, b *aggregatorRecommendation) int {
+		return cmp.Compare(b.Vendor.Relevance, a.Vendor.Relevance)
+	})
+	ctx.JSON(http.StatusOK, recommendations)
+}
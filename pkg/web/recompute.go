@@ -0,0 +1,223 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/recompute"
+)
+
+type recomputeJob struct {
+	ID        int64      `json:"id"`
+	Creator   *string    `json:"creator,omitempty"`
+	Started   time.Time  `json:"started"`
+	Finished  *time.Time `json:"finished,omitempty"`
+	Status    string     `json:"status"`
+	Fields    []string   `json:"fields"`
+	Publisher *string    `json:"publisher,omitempty"`
+	Total     int64      `json:"total"`
+	Processed int64      `json:"processed"`
+	Skipped   int64      `json:"skipped"`
+	LastID    int64      `json:"last_id"`
+	Error     *string    `json:"error,omitempty"`
+}
+
+const recomputeJobColumns = `id, creator, started, finished, status, ` +
+	`fields, publisher, total, processed, skipped, last_id, error`
+
+func scanRecomputeJob(row pgx.Row, rj *recomputeJob) error {
+	if err := row.Scan(
+		&rj.ID, &rj.Creator, &rj.Started, &rj.Finished, &rj.Status,
+		&rj.Fields, &rj.Publisher, &rj.Total, &rj.Processed, &rj.Skipped,
+		&rj.LastID, &rj.Error,
+	); err != nil {
+		return err
+	}
+	rj.Started = rj.Started.UTC()
+	if rj.Finished != nil {
+		*rj.Finished = rj.Finished.UTC()
+	}
+	return nil
+}
+
+// startRecomputeJob is an endpoint that starts a recomputation of the derived fields.
+//
+//	@Summary		Starts a recompute job.
+//	@Description	Recomputes the fields derived from the stored documents in the
+//	@Description	background in batches, e.g. after the extraction logic has changed.
+//	@Description	The fields are a comma separated list of texts, columns, cves,
+//	@Description	notes and score. All fields are recomputed if none is given.
+//	@Description	Only one job is run at a time.
+//	@Param			fields		formData	string	false	"Fields to recompute"
+//	@Param			publisher	formData	string	false	"Only advisories of this publisher"
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Success		201	{object}	models.ID
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		409	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/recompute/jobs [post]
+func (c *Controller) startRecomputeJob(ctx *gin.Context) {
+	fields, ok := parse(ctx, recompute.ParseFields, ctx.PostForm("fields"))
+	if !ok {
+		return
+	}
+	var publisher *string
+	if value, ok := ctx.GetPostForm("publisher"); ok && value != "" {
+		publisher = &value
+	}
+	id, err := c.rc.Start(ctx.Request.Context(), c.currentUser(ctx), fields, publisher)
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusCreated, models.ID{ID: id})
+	case errors.Is(err, recompute.ErrRunning):
+		models.SendError(ctx, http.StatusConflict, err)
+	default:
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+	}
+}
+
+// viewRecomputeJobs is an endpoint that returns the recompute jobs.
+//
+//	@Summary		Returns recompute jobs.
+//	@Description	Returns the recompute jobs, newest first.
+//	@Produce		json
+//	@Success		200	{array}		recomputeJob
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/recompute/jobs [get]
+func (c *Controller) viewRecomputeJobs(ctx *gin.Context) {
+	const selectSQL = `SELECT ` + recomputeJobColumns +
+		` FROM recompute_jobs ORDER BY started DESC`
+	jobs := []recomputeJob{}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, selectSQL)
+			defer rows.Close()
+			for rows.Next() {
+				var rj recomputeJob
+				if err := scanRecomputeJob(rows, &rj); err != nil {
+					return err
+				}
+				jobs = append(jobs, rj)
+			}
+			return rows.Err()
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, jobs)
+}
+
+// viewRecomputeJob is an endpoint that returns a recompute job.
+//
+//	@Summary		Returns a recompute job.
+//	@Description	Returns the recompute job and its progress.
+//	@Param			id	path	int	true	"Job ID"
+//	@Produce		json
+//	@Success		200	{object}	recomputeJob
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/recompute/jobs/{id} [get]
+func (c *Controller) viewRecomputeJob(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	const selectSQL = `SELECT ` + recomputeJobColumns +
+		` FROM recompute_jobs WHERE id = $1`
+	var rj recomputeJob
+	switch err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			return scanRecomputeJob(conn.QueryRow(rctx, selectSQL, id), &rj)
+		}, 0,
+	); {
+	case errors.Is(err, pgx.ErrNoRows):
+		models.SendErrorMessage(ctx, http.StatusNotFound, "recompute job not found")
+	case err != nil:
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+	default:
+		ctx.JSON(http.StatusOK, &rj)
+	}
+}
+
+// cancelRecomputeJob is an endpoint that cancels a running recompute job.
+//
+//	@Summary		Cancels a recompute job.
+//	@Description	Cancels the running recompute job after the current batch.
+//	@Description	A canceled job can be resumed later.
+//	@Param			id	path	int	true	"Job ID"
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Router			/recompute/jobs/{id} [delete]
+func (c *Controller) cancelRecomputeJob(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	if !c.rc.Cancel(id) {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "no such running recompute job")
+		return
+	}
+	models.SendSuccess(ctx, http.StatusOK, "canceled")
+}
+
+// resumeRecomputeJob is an endpoint that resumes a failed or canceled recompute job.
+//
+//	@Summary		Resumes a recompute job.
+//	@Description	Resumes the failed or canceled recompute job after
+//	@Description	the last document it has processed.
+//	@Param			id	path	int	true	"Job ID"
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		409	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/recompute/jobs/{id}/resume [post]
+func (c *Controller) resumeRecomputeJob(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	switch err := c.rc.Resume(ctx.Request.Context(), id); {
+	case err == nil:
+		models.SendSuccess(ctx, http.StatusOK, "resumed")
+	case errors.Is(err, recompute.ErrNotFound):
+		models.SendError(ctx, http.StatusNotFound, err)
+	case errors.Is(err, recompute.ErrRunning):
+		models.SendError(ctx, http.StatusConflict, err)
+	default:
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+	}
+}
@@ -10,11 +10,9 @@ package web
 
 import (
 	"context"
-	"fmt"
 	"log/slog"
 	"net/http"
 
-	"github.com/ISDuBA/ISDuBA/pkg/database/query"
 	"github.com/ISDuBA/ISDuBA/pkg/models"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
@@ -30,8 +28,7 @@ WITH related AS (
    FROM documents_cves dc
      JOIN unique_cves uc ON dc.cve_id          = uc.id
      JOIN documents docs ON dc.documents_id    = docs.id
-     JOIN advisories ads ON docs.advisories_id = ads.id
-   WHERE documents_id = $%[2]d AND %[1]s
+   WHERE documents_id = $1
 ),
 others AS (
   SELECT
@@ -39,7 +36,7 @@ others AS (
     related.cve
   FROM documents_cves dc2
     JOIN related ON dc2.cve_id = related.cve_id
-  WHERE dc2.documents_id <> $%[2]d
+  WHERE dc2.documents_id <> $1
 )
 SELECT
   others.documents_id,
@@ -61,7 +58,6 @@ FROM others
     ORDER BY changedate DESC, change_number DESC
     LIMIT 1
   ) sh ON true
-WHERE %[1]s
 `
 
 // cveRelatedDocuments is an endpoint that returns the documents
@@ -82,22 +78,12 @@ func (c *Controller) cveRelatedDocuments(ctx *gin.Context) {
 		return
 	}
 
-	var (
-		sb         = query.SQLBuilder{}
-		allowedDoc = c.tlps(ctx).AsExprPublisher("ads.publisher")
-		tlpCheck   = sb.CreateWhere(allowedDoc)
-	)
-
-	sb.Replacements = append(sb.Replacements, docID)
-	docIndex := len(sb.Replacements)
-	selectSQL := fmt.Sprintf(selectCVERelatedSQL, tlpCheck, docIndex)
-
 	var relatedDocuments []*models.RelatedDocument
 
 	if err := c.db.Run(
 		ctx.Request.Context(),
 		func(rctx context.Context, conn *pgxpool.Conn) error {
-			rows, _ := conn.Query(rctx, selectSQL, sb.Replacements...)
+			rows, _ := conn.Query(rctx, selectCVERelatedSQL, docID)
 			var err error
 			relatedDocuments, err = pgx.CollectRows(rows,
 				func(row pgx.CollectableRow) (*models.RelatedDocument, error) {
@@ -120,7 +106,7 @@ func (c *Controller) cveRelatedDocuments(ctx *gin.Context) {
 			return err
 		}, 0,
 	); err != nil {
-		slog.Error("database error", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -0,0 +1,30 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/ISDuBA/ISDuBA/pkg/requestid"
+)
+
+// requestID is a middleware which accepts or generates the
+// id of a request, returns it in the response and puts it
+// into the request context for logging and downstream calls.
+func requestID(ctx *gin.Context) {
+	id := ctx.GetHeader(requestid.Header)
+	if !requestid.Valid(id) {
+		id = requestid.New()
+		// Let the request logger pick up the accepted id.
+		ctx.Request.Header.Set(requestid.Header, id)
+	}
+	ctx.Header(requestid.Header, id)
+	ctx.Request = ctx.Request.WithContext(
+		requestid.WithID(ctx.Request.Context(), id))
+}
@@ -0,0 +1,195 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/database/query"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// maxSampleSize limits the number of documents in a sample.
+const maxSampleSize = 1000
+
+type documentSample struct {
+	Seed       string           `json:"seed"`
+	Population int              `json:"population"`
+	Documents  []map[string]any `json:"documents"`
+}
+
+// sampleDocuments is an endpoint that returns a random sample of documents.
+//
+//	@Summary		Returns a random sample of documents.
+//	@Description	Returns a pseudo random sample of the documents matching the query
+//	@Description	for quality assurance spot checks. The documents can be further
+//	@Description	restricted to those imported in a time range or downloaded from
+//	@Description	a source. The same seed results in the same sample. Without a seed
+//	@Description	a random one is chosen which is returned to reproduce the sample.
+//	@Description	The population is the number of documents the sample is taken from.
+//	@Param			query		query	string	false	"Query to filter the documents"
+//	@Param			n			query	int		false	"Sample size, defaults to 10"
+//	@Param			seed		query	string	false	"Seed"
+//	@Param			from		query	string	false	"Imported not before"
+//	@Param			to			query	string	false	"Imported not after"
+//	@Param			source		query	int		false	"Downloaded from source"
+//	@Param			columns		query	string	false	"Columns"
+//	@Produce		json
+//	@Success		200	{object}	web.documentSample
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/documents/sample [get]
+func (c *Controller) sampleDocuments(ctx *gin.Context) {
+	n, ok := parse(ctx, toInt64, ctx.DefaultQuery("n", "10"))
+	if !ok {
+		return
+	}
+	n = min(max(n, 1), maxSampleSize)
+
+	seed := ctx.Query("seed")
+	if seed == "" {
+		seed = rand.Text()[:16]
+	}
+
+	var (
+		conds  []string
+		values []any
+	)
+	from, to := ctx.Query("from"), ctx.Query("to")
+	if from != "" || to != "" {
+		var fromT, toT time.Time
+		if from != "" {
+			if fromT, ok = parse(ctx, parseTime, from); !ok {
+				return
+			}
+		}
+		if to != "" {
+			if toT, ok = parse(ctx, parseTime, to); !ok {
+				return
+			}
+		} else {
+			toT = time.Now()
+		}
+		// $1 are the ids.
+		values = append(values, fromT, toT)
+		conds = append(conds, fmt.Sprintf(`EXISTS(SELECT FROM events_log `+
+			`WHERE documents_id = ids.id AND event = 'import_document' `+
+			`AND time BETWEEN $%d AND $%d)`, len(values), len(values)+1))
+	}
+	if value := ctx.Query("source"); value != "" {
+		sourceID, ok := parse(ctx, toInt64, value)
+		if !ok {
+			return
+		}
+		values = append(values, sourceID)
+		conds = append(conds, fmt.Sprintf(`EXISTS(SELECT FROM downloads `+
+			`JOIN feeds ON downloads.feeds_id = feeds.id `+
+			`WHERE downloads.documents_id = ids.id AND feeds.sources_id = $%d)`,
+			len(values)+1))
+	}
+
+	parser := query.Parser{
+		Mode:            query.DocumentMode,
+		MinSearchLength: MinSearchLength,
+		Me:              ctx.GetString("uid"),
+	}
+	expr, ok := parse(ctx, parser.Parse, ctx.DefaultQuery("query", "true"))
+	if !ok {
+		return
+	}
+	idsBuilder, err := query.NewAdvancedSQLBuilder(
+		query.AdvancedSQLBuilderExpr(expr),
+		query.AdvancedSQLBuilderFields([]string{"id"}),
+		query.AdvancedSQLBuilderParser(&parser))
+	if err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	fields := strings.Fields(
+		ctx.DefaultQuery("columns", "id title tracking_id version publisher"))
+	if !slices.Contains(fields, "id") {
+		fields = append(fields, "id")
+	}
+	// Check the columns before taking the sample.
+	if _, err := query.NewAdvancedSQLBuilder(
+		query.AdvancedSQLBuilderExpr(query.False()),
+		query.AdvancedSQLBuilderFields(fields),
+		query.AdvancedSQLBuilderParser(&parser),
+	); err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	result := documentSample{
+		Seed:      seed,
+		Documents: []map[string]any{},
+	}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, idsBuilder.CreateQuery(-1, -1), idsBuilder.Replacements...)
+			ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+			if err != nil {
+				return err
+			}
+			if len(conds) > 0 && len(ids) > 0 {
+				filterSQL := `SELECT id FROM unnest($1::int[]) AS ids(id) WHERE ` +
+					strings.Join(conds, " AND ")
+				rows, _ := conn.Query(rctx, filterSQL, append([]any{ids}, values...)...)
+				if ids, err = pgx.CollectRows(rows, pgx.RowTo[int64]); err != nil {
+					return err
+				}
+			}
+			result.Population = len(ids)
+			sample := models.SampleIDs(ids, int(n), seed)
+			if len(sample) == 0 {
+				return nil
+			}
+			sampled := query.False()
+			for _, id := range sample {
+				sampled = sampled.Or(query.FieldEqInt("id", id))
+			}
+			builder, err := query.NewAdvancedSQLBuilder(
+				query.AdvancedSQLBuilderExpr(sampled),
+				query.AdvancedSQLBuilderOrderFields([]string{"id"}),
+				query.AdvancedSQLBuilderFields(fields),
+				query.AdvancedSQLBuilderParser(&parser))
+			if err != nil {
+				return err
+			}
+			if rows, err = conn.Query(rctx, builder.CreateQuery(-1, -1), builder.Replacements...); err != nil {
+				return err
+			}
+			defer rows.Close()
+			documents, err := scanRows(rows, builder.Fields())
+			if documents != nil {
+				result.Documents = documents
+			}
+			return err
+		}, c.cfg.Database.MaxQueryDuration,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, &result)
+}
@@ -0,0 +1,95 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/scheduler"
+)
+
+// viewSchedulers is an endpoint that returns the state of the background tasks.
+//
+//	@Summary		Returns the state of the background tasks.
+//	@Description	Returns the interval, the last and next run, the duration
+//	@Description	and the last error of the periodic background tasks.
+//	@Produce		json
+//	@Success		200	{array}	scheduler.Status
+//	@Failure		401
+//	@Router			/admin/schedulers [get]
+func (c *Controller) viewSchedulers(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.st.Status())
+}
+
+// findScheduler looks up the task given by the name parameter.
+func (c *Controller) findScheduler(ctx *gin.Context) *scheduler.Task {
+	task := c.st.Task(ctx.Param("name"))
+	if task == nil {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "not found")
+	}
+	return task
+}
+
+// triggerScheduler is an endpoint that requests an immediate run of a background task.
+//
+//	@Summary		Triggers a background task.
+//	@Description	Requests an immediate run of a background task.
+//	@Description	Paused tasks are run, too.
+//	@Param			name	path	string	true	"Task name"
+//	@Produce		json
+//	@Success		202	{object}	models.Success
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		409	{object}	models.Error	"run already requested"
+//	@Router			/admin/schedulers/{name}/trigger [post]
+func (c *Controller) triggerScheduler(ctx *gin.Context) {
+	task := c.findScheduler(ctx)
+	if task == nil {
+		return
+	}
+	if !task.Trigger() {
+		models.SendErrorMessage(ctx, http.StatusConflict, "run already requested")
+		return
+	}
+	slog.InfoContext(ctx, "background task triggered", "task", ctx.Param("name"), "user", ctx.GetString("uid"))
+	models.SendSuccess(ctx, http.StatusAccepted, "triggered")
+}
+
+// updateScheduler is an endpoint that pauses or resumes a background task.
+//
+//	@Summary		Pauses or resumes a background task.
+//	@Description	Paused tasks are skipped when they are due
+//	@Description	but can still be triggered manually.
+//	@Param			name	path		string	true	"Task name"
+//	@Param			paused	formData	bool	true	"Pause the task"
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Success		200	{object}	scheduler.Status
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Router			/admin/schedulers/{name} [put]
+func (c *Controller) updateScheduler(ctx *gin.Context) {
+	paused, ok := parse(ctx, strconv.ParseBool, ctx.PostForm("paused"))
+	if !ok {
+		return
+	}
+	task := c.findScheduler(ctx)
+	if task == nil {
+		return
+	}
+	task.SetPaused(paused)
+	slog.InfoContext(ctx, "background task updated", "task", ctx.Param("name"), "paused", paused, "user", ctx.GetString("uid"))
+	ctx.JSON(http.StatusOK, task.Status())
+}
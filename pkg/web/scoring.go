@@ -0,0 +1,344 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/recompute"
+)
+
+type scoring struct {
+	Weights         models.ScoringWeights  `json:"weights"`
+	PublishersTrust models.PublishersTrust `json:"publishers_trust"`
+}
+
+type cveScore struct {
+	CVE  string   `json:"cve" binding:"required"`
+	EPSS *float64 `json:"epss,omitempty"`
+	KEV  bool     `json:"kev"`
+}
+
+// viewScoring is an endpoint that returns the scoring configuration.
+//
+//	@Summary		Returns the scoring configuration.
+//	@Description	Returns the weights of the scoring factors and the trust into the publishers.
+//	@Produce		json
+//	@Success		200	{object}	scoring
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/scoring [get]
+func (c *Controller) viewScoring(ctx *gin.Context) {
+	const (
+		weightsSQL = `SELECT factor::text, weight FROM scoring_weights`
+		trustSQL   = `SELECT publisher, trust FROM publishers_trust`
+	)
+	sc := scoring{
+		Weights:         models.ScoringWeights{},
+		PublishersTrust: models.PublishersTrust{},
+	}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			var (
+				name  string
+				value float64
+			)
+			rows, _ := conn.Query(rctx, weightsSQL)
+			if _, err := pgx.ForEachRow(rows, []any{&name, &value}, func() error {
+				sc.Weights[models.ScoringFactor(name)] = value
+				return nil
+			}); err != nil {
+				return err
+			}
+			rows, _ = conn.Query(rctx, trustSQL)
+			_, err := pgx.ForEachRow(rows, []any{&name, &value}, func() error {
+				sc.PublishersTrust[models.Publisher(name)] = value
+				return nil
+			})
+			return err
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, &sc)
+}
+
+// updateScoring is an endpoint that changes the scoring configuration.
+//
+//	@Summary		Changes the scoring configuration.
+//	@Description	Replaces the weights and/or the publishers trust.
+//	@Description	Parts not given are kept. The scores of all documents are
+//	@Description	recomputed by a background job.
+//	@Param			scoring	body	scoring	true	"Scoring configuration"
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/scoring [put]
+func (c *Controller) updateScoring(ctx *gin.Context) {
+	var input scoring
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if input.Weights != nil {
+		if err := input.Weights.Validate(); err != nil {
+			models.SendError(ctx, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if err := input.PublishersTrust.Validate(); err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if input.Weights == nil && input.PublishersTrust == nil {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "nothing to change")
+		return
+	}
+	const (
+		deleteWeightsSQL = `DELETE FROM scoring_weights`
+		insertWeightSQL  = `INSERT INTO scoring_weights (factor, weight) ` +
+			`VALUES ($1::scoring_factor, $2)`
+		deleteTrustSQL = `DELETE FROM publishers_trust`
+		insertTrustSQL = `INSERT INTO publishers_trust (publisher, trust) VALUES ($1, $2)`
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tx, err := conn.BeginTx(rctx, pgx.TxOptions{})
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(rctx)
+			if input.Weights != nil {
+				if _, err := tx.Exec(rctx, deleteWeightsSQL); err != nil {
+					return err
+				}
+				for factor, weight := range input.Weights {
+					if _, err := tx.Exec(rctx, insertWeightSQL, string(factor), weight); err != nil {
+						return err
+					}
+				}
+			}
+			if input.PublishersTrust != nil {
+				if _, err := tx.Exec(rctx, deleteTrustSQL); err != nil {
+					return err
+				}
+				for publisher, trust := range input.PublishersTrust {
+					if _, err := tx.Exec(rctx, insertTrustSQL, string(publisher), trust); err != nil {
+						return err
+					}
+				}
+			}
+			return tx.Commit(rctx)
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if !c.recomputeScores(ctx) {
+		return
+	}
+	models.SendSuccess(ctx, http.StatusOK, "scoring updated")
+}
+
+// updateCVEScores is an endpoint that stores EPSS and KEV data of CVEs.
+//
+//	@Summary		Stores EPSS and KEV data.
+//	@Description	Stores the EPSS probabilities and the known exploited state of CVEs.
+//	@Description	With replace all formerly stored values are removed.
+//	@Description	The scores of the documents are recomputed by a background job.
+//	@Param			scores	body	[]cveScore	true	"CVE scores"
+//	@Param			replace	query	bool		false	"Replace all stored values"
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/scoring/cves [put]
+func (c *Controller) updateCVEScores(ctx *gin.Context) {
+	replace, ok := parse(ctx, strconv.ParseBool, ctx.DefaultQuery("replace", "false"))
+	if !ok {
+		return
+	}
+	var scores []cveScore
+	if err := ctx.ShouldBindJSON(&scores); err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	for i := range scores {
+		s := &scores[i]
+		s.CVE = strings.TrimSpace(s.CVE)
+		if s.CVE == "" {
+			models.SendErrorMessage(ctx, http.StatusBadRequest, "missing cve")
+			return
+		}
+		if s.EPSS != nil && !(*s.EPSS >= 0 && *s.EPSS <= 1) {
+			models.SendErrorMessage(ctx, http.StatusBadRequest,
+				fmt.Sprintf("epss of %q has to be between 0 and 1", s.CVE))
+			return
+		}
+	}
+	const (
+		deleteSQL = `DELETE FROM cve_scores`
+		upsertSQL = `INSERT INTO cve_scores (cve, epss, kev) VALUES ($1, $2, $3) ` +
+			`ON CONFLICT (cve) DO UPDATE SET epss = $2, kev = $3`
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tx, err := conn.BeginTx(rctx, pgx.TxOptions{})
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(rctx)
+			if replace {
+				if _, err := tx.Exec(rctx, deleteSQL); err != nil {
+					return err
+				}
+			}
+			for i := range scores {
+				s := &scores[i]
+				if _, err := tx.Exec(rctx, upsertSQL, s.CVE, s.EPSS, s.KEV); err != nil {
+					return err
+				}
+			}
+			return tx.Commit(rctx)
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if !c.recomputeScores(ctx) {
+		return
+	}
+	models.SendSuccess(ctx, http.StatusOK, "cve scores updated")
+}
+
+// updateAssetMatch is an endpoint that sets the asset relevance of a document.
+//
+//	@Summary		Sets the asset relevance of a document.
+//	@Description	Sets how relevant the document is for the own assets.
+//	@Description	The score of the document is recomputed.
+//	@Param			document	path		int		true	"Document ID"
+//	@Param			relevance	formData	number	true	"Relevance between 0 and 1"
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/scoring/assets/{document} [put]
+func (c *Controller) updateAssetMatch(ctx *gin.Context) {
+	docID, ok := parse(ctx, toInt64, ctx.Param("document"))
+	if !ok {
+		return
+	}
+	relevance, ok := parse(ctx, toFloat64, ctx.PostForm("relevance"))
+	if !ok {
+		return
+	}
+	if !(relevance >= 0 && relevance <= 1) {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "relevance has to be between 0 and 1")
+		return
+	}
+	const upsertSQL = `INSERT INTO asset_matches (documents_id, relevance) VALUES ($1, $2) ` +
+		`ON CONFLICT (documents_id) DO UPDATE SET relevance = $2`
+	c.changeAssetMatch(ctx, docID, upsertSQL, relevance)
+}
+
+// deleteAssetMatch is an endpoint that removes the asset relevance of a document.
+//
+//	@Summary		Removes the asset relevance of a document.
+//	@Description	Removes the asset relevance of the document.
+//	@Description	The score of the document is recomputed.
+//	@Param			document	path	int	true	"Document ID"
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/scoring/assets/{document} [delete]
+func (c *Controller) deleteAssetMatch(ctx *gin.Context) {
+	docID, ok := parse(ctx, toInt64, ctx.Param("document"))
+	if !ok {
+		return
+	}
+	const deleteSQL = `DELETE FROM asset_matches WHERE documents_id = $1`
+	c.changeAssetMatch(ctx, docID, deleteSQL)
+}
+
+// recomputeScores schedules the recomputation of the scores of all
+// documents in the background. Returns false if an error was sent.
+func (c *Controller) recomputeScores(ctx *gin.Context) bool {
+	fields := []recompute.Field{recompute.Score}
+	if _, err := c.rc.Schedule(ctx.Request.Context(), c.currentUser(ctx), fields); err != nil {
+		slog.ErrorContext(ctx, "scheduling recomputation of scores failed", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return false
+	}
+	return true
+}
+
+func (c *Controller) changeAssetMatch(ctx *gin.Context, docID int64, sql string, args ...any) {
+	const recomputeSQL = `UPDATE documents ` +
+		`SET score = document_score(document, advisories_id, id) ` +
+		`WHERE id = $1`
+	var exists bool
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			var err error
+			if exists, err = documentVisible(rctx, conn, docID); err != nil || !exists {
+				return err
+			}
+			tx, err := conn.BeginTx(rctx, pgx.TxOptions{})
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(rctx)
+			if _, err := tx.Exec(rctx, sql, append([]any{docID}, args...)...); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(rctx, recomputeSQL, docID); err != nil {
+				return err
+			}
+			return tx.Commit(rctx)
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if !exists {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "document not found")
+		return
+	}
+	models.SendSuccess(ctx, http.StatusOK, "asset relevance updated")
+}
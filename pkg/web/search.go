@@ -53,7 +53,7 @@ func (c *Controller) aggregatedResults(
 	)
 
 	if slog.Default().Enabled(rctx, slog.LevelDebug) {
-		slog.Debug("documents", "SQL", query.InterpolateSQLqnd(sql, builder.Replacements))
+		slog.DebugContext(ctx, "documents", "SQL", query.InterpolateSQLqnd(sql, builder.Replacements))
 	}
 	if err := c.db.Run(
 		rctx,
@@ -70,7 +70,7 @@ func (c *Controller) aggregatedResults(
 		},
 		c.cfg.Database.MaxQueryDuration, // In case the user provided a very expensive query.
 	); err != nil {
-		slog.Error("database error", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -86,7 +86,7 @@ func (c *Controller) aggregatedResults(
 			var err error
 			ilikes, err = query.CompileILike(searches...)
 			if err != nil {
-				slog.Error("compiling ilikes failed", "err", err)
+				slog.ErrorContext(ctx, "compiling ilikes failed", "err", err)
 				models.SendError(ctx, http.StatusInternalServerError, err)
 				return
 			}
@@ -190,8 +190,9 @@ func (c *Controller) aggregatedResults(
 		},
 		c.cfg.Database.MaxQueryDuration, // In case the user provided a very expensive query.
 	); err != nil {
-		slog.Error("database error", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
 		// Too late to send an error to the client.
+		ctx.Error(err)
 	}
 }
 
@@ -376,7 +377,7 @@ func (c *Controller) documentTexts(ctx *gin.Context) {
 	}
 	ilikes, err := query.CompileILike(searches...)
 	if err != nil {
-		slog.Error("compiling ilikes failed", "err", err)
+		slog.ErrorContext(ctx, "compiling ilikes failed", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -385,17 +386,7 @@ func (c *Controller) documentTexts(ctx *gin.Context) {
 		CreateTextSearchWhereClause("ut.txt", expr)
 	replacements = append(replacements, docID)
 
-	tlps := c.tlps(ctx)
-
 	const (
-		publisherTLPSQL = `` +
-			`SELECT` +
-			` ads.publisher,` +
-			` docs.tlp ` +
-			`FROM` +
-			` documents docs JOIN` +
-			` advisories ads ON docs.advisories_id = ads.id ` +
-			`WHERE docs.id = $1`
 		uniqueTextsSQL = `` +
 			`SELECT` +
 			` dt.num,` +
@@ -417,21 +408,14 @@ func (c *Controller) documentTexts(ctx *gin.Context) {
 		documentData []byte
 	)
 
-	var forbidden bool
+	var exists bool
 	switch err := c.db.Run(
 		ctx.Request.Context(),
 		func(rctx context.Context, conn *pgxpool.Conn) error {
-			// Check permissions.
-			var publisher, tlp string
-			if err := conn.QueryRow(rctx, publisherTLPSQL, docID).Scan(
-				&publisher,
-				&tlp,
-			); err != nil {
-				return fmt.Errorf("failed to extract publisher and tlp: %w", err)
-			}
-			if len(tlps) > 0 && !tlps.Allowed(publisher, models.TLP(tlp)) {
-				forbidden = true
-				return nil
+			// The texts are only visible with the document.
+			var err error
+			if exists, err = documentVisible(rctx, conn, docID); err != nil || !exists {
+				return err
 			}
 			// Load unique texts.
 			query := fmt.Sprintf(uniqueTextsSQL, len(replacements), searchTerm)
@@ -470,11 +454,11 @@ func (c *Controller) documentTexts(ctx *gin.Context) {
 		models.SendErrorMessage(ctx, http.StatusNotFound, "document not found")
 		return
 	case err != nil:
-		slog.Error("database error", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
-	case forbidden:
-		models.SendErrorMessage(ctx, http.StatusForbidden, "access denied")
+	case !exists:
+		models.SendErrorMessage(ctx, http.StatusNotFound, "document not found")
 		return
 	}
 
@@ -0,0 +1,78 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"github.com/ISDuBA/ISDuBA/pkg/searchcache"
+)
+
+// recordingWriter records the written body up to a limit.
+type recordingWriter struct {
+	gin.ResponseWriter
+	limit    int64
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (rw *recordingWriter) record(p []byte) {
+	if rw.overflow {
+		return
+	}
+	if int64(rw.body.Len()+len(p)) > rw.limit {
+		rw.overflow = true
+		rw.body = bytes.Buffer{}
+		return
+	}
+	rw.body.Write(p)
+}
+
+// Write implements [io.Writer].
+func (rw *recordingWriter) Write(p []byte) (int, error) {
+	rw.record(p)
+	return rw.ResponseWriter.Write(p)
+}
+
+// WriteString implements [io.StringWriter].
+func (rw *recordingWriter) WriteString(s string) (int, error) {
+	rw.record([]byte(s))
+	return rw.ResponseWriter.WriteString(s)
+}
+
+// cachedSearch serves the result of a search from the cache if possible.
+// Otherwise the result delivered by deliver is stored in the cache.
+func (c *Controller) cachedSearch(
+	ctx *gin.Context,
+	key searchcache.Key,
+	deliver func(),
+) {
+	if c.qc == nil {
+		deliver()
+		return
+	}
+	if data, ok := c.qc.Get(key); ok {
+		ctx.Header("X-Search-Cache", "hit")
+		ctx.Data(http.StatusOK, binding.MIMEJSON+"; charset=utf-8", data)
+		return
+	}
+	ctx.Header("X-Search-Cache", "miss")
+	rw := &recordingWriter{ResponseWriter: ctx.Writer, limit: c.qc.MaxEntrySize()}
+	ctx.Writer = rw
+	defer func() { ctx.Writer = rw.ResponseWriter }()
+	deliver()
+	// Errors while streaming are recorded in the context.
+	if rw.Status() == http.StatusOK && !rw.overflow && len(ctx.Errors) == 0 {
+		c.qc.Set(key, bytes.Clone(rw.body.Bytes()))
+	}
+}
@@ -0,0 +1,132 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// visibleQuerySQL returns the condition if a stored query is visible
+// to a user. A query is visible to its definer, to all users if it is
+// global, to the members of the team it is shared with and to the
+// users having one of the roles it is shared with.
+// user and roles are the placeholder indices of the user and its roles.
+func visibleQuerySQL(user, roles int) string {
+	u, r := `$`+strconv.Itoa(user), `$`+strconv.Itoa(roles)
+	return `(global OR definer = ` + u +
+		` OR teams_id IN (SELECT user_teams(` + u + `))` +
+		` OR shared_roles && ` + r + `::varchar[])`
+}
+
+// sharedRoles parses a space separated list of roles.
+// The result is sorted and free of duplicates.
+func sharedRoles(s string) []string {
+	roles := strings.Fields(s)
+	slices.Sort(roles)
+	return slices.Compact(roles)
+}
+
+// mayShareWithRoles checks if the current user is allowed to share
+// a query with the given roles. Admins may share with all roles,
+// all others only with roles they have themselves.
+func (c *Controller) mayShareWithRoles(ctx *gin.Context, roles []string) bool {
+	if c.hasAnyRole(ctx, models.Admin) {
+		return true
+	}
+	own := c.realmRoles(ctx)
+	for _, role := range roles {
+		if !slices.Contains(own, role) {
+			return false
+		}
+	}
+	return true
+}
+
+// cloneStoredQuery is an endpoint that clones a visible stored query.
+//
+//	@Summary		Clones a stored query.
+//	@Description	Creates a private copy of a visible stored query owned by
+//	@Description	the current user. Without a name the name of the cloned query is used.
+//	@Param			query	path		int		true	"Query ID"
+//	@Param			name	formData	string	false	"Name of the copy"
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Success		201	{object}	web.cloneStoredQuery.cloneResult
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		409	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/queries/{query}/clone [post]
+func (c *Controller) cloneStoredQuery(ctx *gin.Context) {
+	type cloneResult struct {
+		ID  int64 `json:"id"`
+		Num int64 `json:"num"`
+	}
+	queryID, ok := parse(ctx, toInt64, ctx.Param("query"))
+	if !ok {
+		return
+	}
+	var name *string
+	if nm, ok := ctx.GetPostForm("name"); ok {
+		if nm == "" {
+			models.SendErrorMessage(ctx, http.StatusBadRequest, "empty name is not allowed")
+			return
+		}
+		name = &nm
+	}
+
+	cloneSQL := `INSERT INTO stored_queries (` +
+		`kind, definer, global, name, description, query, ` +
+		`columns, orders, dashboard, default_query, role, cloned_from) ` +
+		`SELECT kind, $2, false, coalesce($4, name), description, query, ` +
+		`columns, orders, dashboard, false, role, id ` +
+		`FROM stored_queries WHERE id = $1 AND ` + visibleQuerySQL(2, 3) + ` ` +
+		`RETURNING id, num`
+
+	var result cloneResult
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			return conn.QueryRow(rctx, cloneSQL,
+				queryID,
+				ctx.GetString("uid"),
+				c.realmRoles(ctx),
+				name,
+			).Scan(&result.ID, &result.Num)
+		}, 0,
+	); err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			models.SendErrorMessage(ctx, http.StatusNotFound, "query not found")
+		// Unique constraint violation
+		case errors.As(err, &pgErr) && pgErr.Code == "23505":
+			models.SendErrorMessage(ctx, http.StatusConflict, "a query with this name already exists")
+		default:
+			slog.ErrorContext(ctx, "database error", "err", err)
+			models.SendError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, result)
+}
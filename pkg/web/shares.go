@@ -0,0 +1,401 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocsaf/csaf/v3/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+type sharedLink struct {
+	ID         int64      `json:"id"`
+	DocumentID int64      `json:"document_id"`
+	Creator    string     `json:"creator"`
+	Created    time.Time  `json:"created"`
+	Expires    time.Time  `json:"expires"`
+	Revoked    *time.Time `json:"revoked,omitempty"`
+	Accesses   int64      `json:"accesses"`
+	LastAccess *time.Time `json:"last_access,omitempty"`
+}
+
+type createdSharedLink struct {
+	ID         int64     `json:"id"`
+	DocumentID int64     `json:"document_id"`
+	Token      string    `json:"token"`
+	Expires    time.Time `json:"expires"`
+}
+
+// shareTokenHash returns the hash of a share token as it is stored in the database.
+func shareTokenHash(token string) []byte {
+	h := sha256.Sum256([]byte(token))
+	return h[:]
+}
+
+// shareableTLP checks if documents of the given TLP are allowed to be shared.
+func (c *Controller) shareableTLP(tlp *string) bool {
+	return tlp != nil && slices.Contains(c.cfg.Workflow.ShareTLPs, models.TLP(*tlp))
+}
+
+// createSharedLink is an endpoint that creates an expiring link to a document.
+//
+//	@Summary		Creates a shared link.
+//	@Description	Creates an expiring link to the specified document which can be
+//	@Description	accessed without logging in. Only documents with a TLP configured
+//	@Description	to be shareable can be shared. The token is only returned once.
+//	@Param			document	path		int		true	"Document ID"
+//	@Param			duration	formData	string	false	"Validity of the link, e.g. 24h"
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Success		201	{object}	createdSharedLink
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		403	{object}	models.Error
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/shares/documents/{document} [post]
+func (c *Controller) createSharedLink(ctx *gin.Context) {
+	docID, ok := parse(ctx, toInt64, ctx.Param("document"))
+	if !ok {
+		return
+	}
+	duration := c.cfg.Workflow.ShareDuration
+	if value, ok := ctx.GetPostForm("duration"); ok && value != "" {
+		if duration, ok = parse(ctx, time.ParseDuration, value); !ok {
+			return
+		}
+	}
+	if duration <= 0 || duration > c.cfg.Workflow.ShareMaxDuration {
+		models.SendErrorMessage(ctx, http.StatusBadRequest,
+			fmt.Sprintf("duration has to be positive and at most %s", c.cfg.Workflow.ShareMaxDuration))
+		return
+	}
+
+	var raw [32]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		slog.ErrorContext(ctx, "creating share token failed", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw[:])
+
+	const (
+		tlpSQL    = `SELECT tlp FROM documents WHERE id = $1`
+		insertSQL = `INSERT INTO shared_links (token_hash, documents_id, creator, created, expires) ` +
+			`VALUES ($1, $2, $3, $4, $5) RETURNING id`
+		eventSQL = `INSERT INTO events_log (event, time, actor, documents_id) ` +
+			`VALUES ('share_document'::events, $1, $2, $3)`
+	)
+
+	var (
+		now       = time.Now().UTC()
+		created   = createdSharedLink{DocumentID: docID, Token: token, Expires: now.Add(duration)}
+		exists    bool
+		forbidden bool
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			var err error
+			if exists, err = documentVisible(rctx, conn, docID); err != nil || !exists {
+				return err
+			}
+			tx, err := conn.BeginTx(rctx, pgx.TxOptions{})
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(rctx)
+			var tlp *string
+			if err := tx.QueryRow(rctx, tlpSQL, docID).Scan(&tlp); err != nil {
+				return err
+			}
+			if forbidden = !c.shareableTLP(tlp); forbidden {
+				return nil
+			}
+			if err := tx.QueryRow(rctx, insertSQL,
+				shareTokenHash(token), docID, ctx.GetString("uid"), now, created.Expires,
+			).Scan(&created.ID); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(rctx, eventSQL, now, c.currentUser(ctx), docID); err != nil {
+				return fmt.Errorf("event logging failed: %w", err)
+			}
+			return tx.Commit(rctx)
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	switch {
+	case !exists:
+		models.SendErrorMessage(ctx, http.StatusNotFound, "document not found")
+	case forbidden:
+		models.SendErrorMessage(ctx, http.StatusForbidden, "TLP of document does not allow sharing")
+	default:
+		ctx.JSON(http.StatusCreated, &created)
+	}
+}
+
+// viewSharedLinks is an endpoint that returns the shared links.
+//
+//	@Summary		Returns shared links.
+//	@Description	Returns the shared links created by the current user.
+//	@Description	Admins see the links of all users.
+//	@Param			document	query	int		false	"Only links to this document"
+//	@Param			active		query	bool	false	"Only links which are neither expired nor revoked"
+//	@Produce		json
+//	@Success		200	{array}		sharedLink
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/shares [get]
+func (c *Controller) viewSharedLinks(ctx *gin.Context) {
+	active, ok := parse(ctx, strconv.ParseBool, ctx.DefaultQuery("active", "false"))
+	if !ok {
+		return
+	}
+	var docID *int64
+	if value, ok := ctx.GetQuery("document"); ok {
+		id, ok := parse(ctx, toInt64, value)
+		if !ok {
+			return
+		}
+		docID = &id
+	}
+	const selectSQL = `SELECT id, documents_id, creator, created, expires, ` +
+		`revoked, accesses, last_access ` +
+		`FROM shared_links ` +
+		`WHERE (creator = $1 OR $2) ` +
+		`AND ($3::int IS NULL OR documents_id = $3) ` +
+		`AND (NOT $4 OR (revoked IS NULL AND expires > current_timestamp)) ` +
+		`ORDER BY created DESC`
+
+	admin := c.hasAnyRole(ctx, models.Admin)
+	links := []sharedLink{}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, selectSQL, ctx.GetString("uid"), admin, docID, active)
+			defer rows.Close()
+			for rows.Next() {
+				var link sharedLink
+				if err := rows.Scan(
+					&link.ID, &link.DocumentID, &link.Creator, &link.Created, &link.Expires,
+					&link.Revoked, &link.Accesses, &link.LastAccess,
+				); err != nil {
+					return err
+				}
+				link.Created = link.Created.UTC()
+				link.Expires = link.Expires.UTC()
+				if link.Revoked != nil {
+					*link.Revoked = link.Revoked.UTC()
+				}
+				if link.LastAccess != nil {
+					*link.LastAccess = link.LastAccess.UTC()
+				}
+				links = append(links, link)
+			}
+			return rows.Err()
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, links)
+}
+
+// revokeSharedLink is an endpoint that revokes a shared link.
+//
+//	@Summary		Revokes a shared link.
+//	@Description	Revokes the specified shared link so it cannot be used any longer.
+//	@Description	Admins are able to revoke the links of other users.
+//	@Param			id	path	int	true	"Shared link ID"
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/shares/{id} [delete]
+func (c *Controller) revokeSharedLink(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	const (
+		revokeSQL = `UPDATE shared_links SET revoked = $1 ` +
+			`WHERE id = $2 AND revoked IS NULL AND (creator = $3 OR $4) ` +
+			`RETURNING documents_id`
+		eventSQL = `INSERT INTO events_log (event, time, actor, documents_id) ` +
+			`VALUES ('revoke_share'::events, $1, $2, $3)`
+	)
+	var (
+		now     = time.Now().UTC()
+		admin   = c.hasAnyRole(ctx, models.Admin)
+		revoked bool
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tx, err := conn.BeginTx(rctx, pgx.TxOptions{})
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(rctx)
+			var docID int64
+			switch err := tx.QueryRow(
+				rctx, revokeSQL, now, id, ctx.GetString("uid"), admin,
+			).Scan(&docID); {
+			case errors.Is(err, pgx.ErrNoRows):
+				return nil
+			case err != nil:
+				return err
+			}
+			if _, err := tx.Exec(rctx, eventSQL, now, c.currentUser(ctx), docID); err != nil {
+				return fmt.Errorf("event logging failed: %w", err)
+			}
+			revoked = true
+			return tx.Commit(rctx)
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if !revoked {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "shared link not found")
+		return
+	}
+	models.SendSuccess(ctx, http.StatusOK, "revoked")
+}
+
+// viewSharedDocument is an endpoint that returns a document by a shared link.
+//
+//	@Summary		Returns a shared document.
+//	@Description	Returns the document the token of a shared link points to.
+//	@Description	No login is needed. Expired and revoked links are rejected.
+//	@Param			token		path	string	true	"Token of the shared link"
+//	@Param			download	query	bool	false	"Serve the document as an attachment"
+//	@Produce		json
+//	@Success		200	{object}	any
+//	@Failure		400	{object}	models.Error
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/shared/{token} [get]
+func (c *Controller) viewSharedDocument(ctx *gin.Context) {
+	download, ok := parse(ctx, strconv.ParseBool, ctx.DefaultQuery("download", "false"))
+	if !ok {
+		return
+	}
+	const (
+		findSQL = `SELECT sl.id, sl.documents_id, docs.original, docs.original_key, docs.filename, docs.tlp ` +
+			`FROM shared_links sl JOIN documents docs ON sl.documents_id = docs.id ` +
+			`WHERE sl.token_hash = $1 AND sl.revoked IS NULL AND sl.expires > $2 ` +
+			`FOR UPDATE OF sl`
+		accessSQL = `UPDATE shared_links SET accesses = accesses + 1, last_access = $1 ` +
+			`WHERE id = $2`
+		// There is no logged in user so the access is logged without an actor.
+		eventSQL = `INSERT INTO events_log (event, time, documents_id) ` +
+			`VALUES ('access_share'::events, $1, $2)`
+	)
+	var (
+		now      = time.Now().UTC()
+		original []byte
+		key      *string
+		filename *string
+		found    bool
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tx, err := conn.BeginTx(rctx, pgx.TxOptions{})
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(rctx)
+			var (
+				linkID, docID int64
+				tlp           *string
+			)
+			switch err := tx.QueryRow(
+				rctx, findSQL, shareTokenHash(ctx.Param("token")), now,
+			).Scan(&linkID, &docID, &original, &key, &filename, &tlp); {
+			case errors.Is(err, pgx.ErrNoRows):
+				return nil
+			case err != nil:
+				return err
+			}
+			// The sharing rules may have changed since the link was created.
+			if !c.shareableTLP(tlp) {
+				return nil
+			}
+			if _, err := tx.Exec(rctx, accessSQL, now, linkID); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(rctx, eventSQL, now, docID); err != nil {
+				return fmt.Errorf("event logging failed: %w", err)
+			}
+			if tlp != nil && models.TLP(*tlp).Restricted() {
+				if _, err := tx.Exec(rctx, logDocumentAccessSQL, nil, shareAccess, docID); err != nil {
+					return fmt.Errorf("access logging failed: %w", err)
+				}
+			}
+			found = true
+			return tx.Commit(rctx)
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if !found {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "shared document not found")
+		return
+	}
+	original, err := c.bs.Original(ctx.Request.Context(), original, key)
+	if err != nil {
+		slog.ErrorContext(ctx, "loading original document failed", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	name := "document.json"
+	if filename != nil && *filename != "" {
+		name = util.CleanFileName(*filename)
+	}
+	disposition := "inline"
+	if download {
+		disposition = "attachment"
+	}
+	extraHeaders := map[string]string{
+		"Content-Disposition": fmt.Sprintf("%s; filename=\"%s\"", disposition, name),
+	}
+	ctx.DataFromReader(
+		http.StatusOK, int64(len(original)),
+		"application/json",
+		bytes.NewReader(original),
+		extraHeaders)
+}
@@ -0,0 +1,172 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// slaStats are the distribution latencies of the documents of a source
+// in seconds from their upstream release date to their import.
+type slaStats struct {
+	SourceID     *int64   `json:"source_id,omitempty"`
+	SourceName   *string  `json:"source_name,omitempty"`
+	Imports      int64    `json:"imports"`
+	Median       *float64 `json:"median,omitempty"`
+	P90          *float64 `json:"p90,omitempty"`
+	P99          *float64 `json:"p99,omitempty"`
+	Max          *float64 `json:"max,omitempty"`
+	WithinTarget int64    `json:"within_target"`
+	Compliance   float64  `json:"compliance"`
+}
+
+type slaReport struct {
+	Period  models.ReportPeriod `json:"period"`
+	From    time.Time           `json:"from"`
+	To      time.Time           `json:"to"`
+	Target  float64             `json:"target"`
+	Sources []slaStats          `json:"sources"`
+	Total   slaStats            `json:"total"`
+}
+
+// slaReportSQL aggregates the latencies between the current release
+// date of the documents and their successful download per source.
+// The row without a source is the total over all sources.
+// Manually uploaded documents are not included.
+// With a team only the sources of the team and its sub-teams are included.
+const slaReportSQL = `WITH latencies AS (` +
+	`SELECT sources.id AS sources_id, sources.name AS sources_name, ` +
+	`greatest(extract(epoch FROM downloads.time - documents.current_release_date), 0)::float8 AS latency ` +
+	`FROM downloads ` +
+	`JOIN documents ON downloads.documents_id = documents.id ` +
+	`JOIN feeds ON downloads.feeds_id = feeds.id ` +
+	`JOIN sources ON feeds.sources_id = sources.id ` +
+	`WHERE downloads.time >= $1 AND downloads.time < $2 ` +
+	`AND sources.id <> 0 ` +
+	`AND documents.current_release_date IS NOT NULL ` +
+	`AND NOT coalesce(downloads.duplicate_failed, false) ` +
+	`AND ($4::int IS NULL OR sources.id = $4) ` +
+	`AND ($5::int IS NULL OR sources.id IN (` +
+	`SELECT sources_id FROM team_sources WHERE teams_id IN (SELECT team_subtree($5))))` +
+	`) SELECT sources_id, sources_name, count(*), ` +
+	`percentile_cont(0.5) WITHIN GROUP (ORDER BY latency), ` +
+	`percentile_cont(0.9) WITHIN GROUP (ORDER BY latency), ` +
+	`percentile_cont(0.99) WITHIN GROUP (ORDER BY latency), ` +
+	`max(latency), ` +
+	`count(*) FILTER (WHERE latency <= $3) ` +
+	`FROM latencies ` +
+	`GROUP BY GROUPING SETS ((sources_id, sources_name), ()) ` +
+	`ORDER BY sources_name NULLS LAST`
+
+// slaReportPeriod is an endpoint that returns a distribution latency report per source.
+//
+//	@Summary		Returns a distribution latency report per source.
+//	@Description	Returns the percentiles of the latencies in seconds between the
+//	@Description	upstream release of the documents and their import per source for a
+//	@Description	calendar period together with the share imported within the target.
+//	@Description	Without parameters the report covers the last completed month.
+//	@Param			period	query	string	false	"week, month, quarter or year"
+//	@Param			offset	query	int		false	"Number of periods back, 0 is the current one"
+//	@Param			target	query	string	false	"Target latency, defaults to 24h"
+//	@Param			source	query	int		false	"Restrict to source"
+//	@Param			team	query	int		false	"Restrict to the sources of a team and its sub-teams"
+//	@Produce		json
+//	@Success		200	{object}	slaReport
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/stats/sla [get]
+func (c *Controller) slaReportPeriod(ctx *gin.Context) {
+	period, ok := parse(ctx, models.ParseReportPeriod, ctx.DefaultQuery("period", "month"))
+	if !ok {
+		return
+	}
+	offset, ok := parse(ctx, strconv.Atoi, ctx.DefaultQuery("offset", "1"))
+	if !ok {
+		return
+	}
+	if offset < 0 {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "offset has to be non-negative")
+		return
+	}
+	target, ok := parse(ctx, time.ParseDuration, ctx.DefaultQuery("target", "24h"))
+	if !ok {
+		return
+	}
+	if target <= 0 {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "target has to be positive")
+		return
+	}
+	var sourceID *int64
+	if value := ctx.Query("source"); value != "" {
+		id, ok := parse(ctx, toInt64, value)
+		if !ok {
+			return
+		}
+		sourceID = &id
+	}
+	var teamID *int64
+	if value := ctx.Query("team"); value != "" {
+		id, ok := parse(ctx, toInt64, value)
+		if !ok {
+			return
+		}
+		teamID = &id
+	}
+	from, to := period.Bounds(time.Now(), offset)
+
+	report := slaReport{
+		Period:  period,
+		From:    from,
+		To:      to,
+		Target:  target.Seconds(),
+		Sources: []slaStats{},
+	}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, slaReportSQL,
+				from, to, target.Seconds(), sourceID, teamID)
+			defer rows.Close()
+			for rows.Next() {
+				var ss slaStats
+				if err := rows.Scan(
+					&ss.SourceID, &ss.SourceName, &ss.Imports,
+					&ss.Median, &ss.P90, &ss.P99, &ss.Max,
+					&ss.WithinTarget,
+				); err != nil {
+					return err
+				}
+				if ss.Imports > 0 {
+					ss.Compliance = float64(ss.WithinTarget) / float64(ss.Imports)
+				}
+				if ss.SourceID == nil {
+					report.Total = ss
+				} else {
+					report.Sources = append(report.Sources, ss)
+				}
+			}
+			return rows.Err()
+		}, c.cfg.Database.MaxQueryDuration,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, &report)
+}
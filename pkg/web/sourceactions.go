@@ -0,0 +1,118 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// sourceAction is an automated state change performed on a source.
+type sourceAction struct {
+	ID      int64     `json:"id"`
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"`
+	Message string    `json:"message"`
+}
+
+// sourceActions is the timeline of the automated actions of a source.
+type sourceActions struct {
+	Count   int64          `json:"count"`
+	Entries []sourceAction `json:"entries"`
+}
+
+// viewSourceActions is an endpoint that returns the automated actions of a source.
+//
+//	@Summary		Returns the automated actions of a source.
+//	@Description	Returns the state changes the system performed automatically
+//	@Description	on the source like deactivations, raised attention flags and
+//	@Description	presented certificates which are not pinned, the latest first.
+//	@Param			id		path	int		true	"Source ID"
+//	@Param			from	query	string	false	"Only actions since"
+//	@Param			limit	query	int		false	"Maximum number of entries"
+//	@Param			offset	query	int		false	"Offset of the entries"
+//	@Produce		json
+//	@Success		200	{object}	sourceActions
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/sources/{id}/actions [get]
+func (c *Controller) viewSourceActions(ctx *gin.Context) {
+	sourceID, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	var (
+		limit, offset int64 = -1, 0
+		from          *time.Time
+	)
+	if lim := ctx.Query("limit"); lim != "" {
+		if limit, ok = parse(ctx, toInt64, lim); !ok {
+			return
+		}
+	}
+	if ofs := ctx.Query("offset"); ofs != "" {
+		if offset, ok = parse(ctx, toInt64, ofs); !ok {
+			return
+		}
+	}
+	if f := ctx.Query("from"); f != "" {
+		fp, ok := parse(ctx, parseTime, f)
+		if !ok {
+			return
+		}
+		from = &fp
+	}
+	if c.sm.Source(ctx.Request.Context(), sourceID, false) == nil {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "source not found")
+		return
+	}
+	const (
+		whereSQL = `WHERE sources_id = $1 AND ($2::timestamptz IS NULL OR time >= $2) `
+		countSQL = `SELECT count(*) FROM source_actions ` + whereSQL
+		listSQL  = `SELECT id, time, action, message FROM source_actions ` + whereSQL +
+			`ORDER BY time DESC, id DESC ` +
+			`LIMIT CASE WHEN $3 < 0 THEN NULL ELSE $3 END OFFSET $4`
+	)
+	result := sourceActions{Entries: []sourceAction{}}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			if err := conn.QueryRow(rctx, countSQL, sourceID, from).Scan(&result.Count); err != nil {
+				return err
+			}
+			rows, _ := conn.Query(rctx, listSQL, sourceID, from, limit, offset)
+			entries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (sourceAction, error) {
+				var sa sourceAction
+				err := row.Scan(&sa.ID, &sa.Time, &sa.Action, &sa.Message)
+				sa.Time = sa.Time.UTC()
+				return sa, err
+			})
+			if err != nil {
+				return err
+			}
+			result.Entries = append(result.Entries, entries...)
+			return nil
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, &result)
+}
@@ -0,0 +1,351 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ISDuBA/ISDuBA/pkg/config"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/sources"
+)
+
+const (
+	// sourceExportVersion is the version of the format of the source exports.
+	sourceExportVersion = 1
+	// sourceExportLimit is the maximal size of a restored source export.
+	sourceExportLimit = 16 * 1024 * 1024
+)
+
+// exportedFeed is the configuration of a feed in a source export.
+type exportedFeed struct {
+	Label    string              `json:"label"`
+	URL      string              `json:"url"`
+	LogLevel config.FeedLogLevel `json:"log_level"`
+}
+
+// exportedSecrets are the secrets of a source encrypted
+// with the AES key of the exporting instance.
+type exportedSecrets struct {
+	ClientCertPrivate    []byte `json:"client_cert_private,omitempty"`
+	ClientCertPassphrase []byte `json:"client_cert_passphrase,omitempty"`
+	OAuth2ClientSecret   []byte `json:"oauth2_client_secret,omitempty"`
+	BasicAuthPassword    []byte `json:"basic_auth_password,omitempty"`
+}
+
+// exportedSource is the configuration of a source in a source export.
+type exportedSource struct {
+	Name             string           `json:"name"`
+	URL              string           `json:"url"`
+	Active           bool             `json:"active"`
+	Shadow           bool             `json:"shadow,omitempty"`
+	Priority         string           `json:"priority,omitempty"`
+	Rate             *float64         `json:"rate,omitempty"`
+	Slots            *int             `json:"slots,omitempty"`
+	Weight           *int             `json:"weight,omitempty"`
+	Headers          []string         `json:"headers,omitempty"`
+	StrictMode       *bool            `json:"strict_mode,omitempty"`
+	Secure           *bool            `json:"secure,omitempty"`
+	SignatureCheck   *bool            `json:"signature_check,omitempty"`
+	Age              *sourceAge       `json:"age,omitempty" swaggertype:"primitive,integer"`
+	IgnorePatterns   []string         `json:"ignore_patterns,omitempty"`
+	FetchWindows     []string         `json:"fetch_windows,omitempty"`
+	ClientCertPublic *string          `json:"client_cert_public,omitempty"`
+	OAuth2TokenURL   *string          `json:"oauth2_token_url,omitempty"`
+	OAuth2ClientID   *string          `json:"oauth2_client_id,omitempty"`
+	OAuth2Scopes     []string         `json:"oauth2_scopes,omitempty"`
+	BasicAuthUser    *string          `json:"basic_auth_user,omitempty"`
+	TLSCABundle      *string          `json:"tls_ca_bundle,omitempty"`
+	TLSPinnedCerts   []string         `json:"tls_pinned_certs,omitempty"`
+	ReceiptURL       *string          `json:"receipt_url,omitempty"`
+	Secrets          *exportedSecrets `json:"secrets,omitempty"`
+	Feeds            []exportedFeed   `json:"feeds"`
+}
+
+// sourceExport is the complete source and feed configuration of an instance.
+type sourceExport struct {
+	Version  int              `json:"version"`
+	Exported time.Time        `json:"exported"`
+	Secrets  string           `json:"secrets"`
+	Sources  []exportedSource `json:"sources"`
+}
+
+// optString returns a pointer to the string of the data or nil if there is no data.
+func optString(data []byte) *string {
+	if data == nil {
+		return nil
+	}
+	s := string(data)
+	return &s
+}
+
+// exportSources is an endpoint that exports the source and feed configuration.
+//
+//	@Summary		Exports the source and feed configuration.
+//	@Description	Exports the configuration of all sources and their feeds as JSON,
+//	@Description	which can be restored with the source import.
+//	@Description	Secrets are left out unless secrets is set to encrypted.
+//	@Description	Then they are encrypted with the AES key of this instance
+//	@Description	and can only be restored by instances with the same key.
+//	@Param			secrets	query	string	false	"none (default) or encrypted"
+//	@Produce		json
+//	@Success		200	{object}	sourceExport
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/sources/export [get]
+func (c *Controller) exportSources(ctx *gin.Context) {
+	var withSecrets bool
+	switch secrets := ctx.DefaultQuery("secrets", "none"); secrets {
+	case "none":
+	case "encrypted":
+		withSecrets = true
+	default:
+		models.SendErrorMessage(ctx, http.StatusBadRequest,
+			fmt.Sprintf("invalid secrets mode %q", secrets))
+		return
+	}
+	var infos []sources.SourceInfo
+	if err := c.sm.Sources(ctx.Request.Context(), func(si *sources.SourceInfo) {
+		// The source of the manual imports is not configurable.
+		if si.ID != 0 {
+			infos = append(infos, *si)
+		}
+	}, false, sources.OrderByID); err != nil {
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	export := sourceExport{
+		Version:  sourceExportVersion,
+		Exported: time.Now().UTC(),
+		Secrets:  "none",
+		Sources:  make([]exportedSource, 0, len(infos)),
+	}
+	if withSecrets {
+		export.Secrets = "encrypted"
+	}
+	for i := range infos {
+		si := &infos[i]
+		creds, err := c.sm.SourceCredentials(ctx.Request.Context(), si.ID, withSecrets)
+		if errors.Is(err, sources.NoSuchEntryError("")) {
+			// Removed in the meantime.
+			continue
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "exporting source failed", "id", si.ID, "err", err)
+			models.SendError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		var age *sourceAge
+		if si.Age != nil {
+			age = &sourceAge{*si.Age}
+		}
+		es := exportedSource{
+			Name:             si.Name,
+			URL:              si.URL,
+			Active:           si.Active,
+			Shadow:           si.Shadow,
+			Priority:         string(si.Priority),
+			Rate:             si.Rate,
+			Slots:            si.Slots,
+			Weight:           si.Weight,
+			Headers:          si.Headers,
+			StrictMode:       si.StrictMode,
+			Secure:           si.Secure,
+			SignatureCheck:   si.SignatureCheck,
+			Age:              age,
+			IgnorePatterns:   sources.AsStrings(si.IgnorePatterns),
+			FetchWindows:     si.FetchWindows,
+			ClientCertPublic: optString(creds.ClientCertPublic),
+			OAuth2TokenURL:   si.OAuth2TokenURL,
+			OAuth2ClientID:   si.OAuth2ClientID,
+			OAuth2Scopes:     si.OAuth2Scopes,
+			BasicAuthUser:    si.BasicAuthUser,
+			TLSCABundle:      optString(creds.TLSCABundle),
+			TLSPinnedCerts:   si.TLSPinnedCerts,
+			ReceiptURL:       si.ReceiptURL,
+			Feeds:            []exportedFeed{},
+		}
+		if withSecrets {
+			secrets := exportedSecrets{
+				ClientCertPrivate:    creds.ClientCertPrivate,
+				ClientCertPassphrase: creds.ClientCertPassphrase,
+				OAuth2ClientSecret:   creds.OAuth2ClientSecret,
+				BasicAuthPassword:    creds.BasicAuthPassword,
+			}
+			if secrets.ClientCertPrivate != nil || secrets.ClientCertPassphrase != nil ||
+				secrets.OAuth2ClientSecret != nil || secrets.BasicAuthPassword != nil {
+				es.Secrets = &secrets
+			}
+		}
+		if err := c.sm.Feeds(ctx.Request.Context(), si.ID, func(fi *sources.FeedInfo) {
+			es.Feeds = append(es.Feeds, exportedFeed{
+				Label:    fi.Label,
+				URL:      fi.URL.String(),
+				LogLevel: fi.Lvl,
+			})
+		}, false, sources.OrderByID); err != nil {
+			// Removed in the meantime.
+			continue
+		}
+		export.Sources = append(export.Sources, es)
+	}
+	ctx.Header("Content-Disposition", "attachment; filename=\"sources.json\"")
+	ctx.JSON(http.StatusOK, export)
+}
+
+// restoreSources creates the sources of a source export.
+// Sources with names already in use are not touched.
+func (c *Controller) restoreSources(ctx *gin.Context) {
+	dryRun, ok := parse(ctx, strconv.ParseBool, ctx.DefaultQuery("dry_run", "false"))
+	if !ok {
+		return
+	}
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, sourceExportLimit)
+	var export sourceExport
+	if err := ctx.ShouldBindJSON(&export); err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if export.Version != sourceExportVersion {
+		models.SendErrorMessage(ctx, http.StatusBadRequest,
+			fmt.Sprintf("unsupported source export version %d", export.Version))
+		return
+	}
+	results := make([]importedSource, 0, len(export.Sources))
+	for i := range export.Sources {
+		result := c.restoreSource(ctx, &export.Sources[i], dryRun)
+		results = append(results, result)
+	}
+	ctx.JSON(http.StatusOK, results)
+}
+
+// restoreSource creates a single source of a source export with its feeds.
+func (c *Controller) restoreSource(ctx *gin.Context, es *exportedSource, dryRun bool) importedSource {
+	result := importedSource{
+		Name:           es.Name,
+		URL:            es.URL,
+		Rate:           es.Rate,
+		Slots:          es.Slots,
+		Headers:        es.Headers,
+		StrictMode:     es.StrictMode,
+		Secure:         es.Secure,
+		SignatureCheck: es.SignatureCheck,
+		Age:            es.Age,
+		IgnorePatterns: es.IgnorePatterns,
+	}
+	fail := func(err error) importedSource {
+		msg := err.Error()
+		result.Error = &msg
+		return result
+	}
+	fetchWindows, err := sources.ParseFetchWindows(es.FetchWindows)
+	if err != nil {
+		return fail(err)
+	}
+	src := source{
+		Name:             es.Name,
+		URL:              es.URL,
+		Priority:         es.Priority,
+		Rate:             es.Rate,
+		Slots:            es.Slots,
+		Weight:           es.Weight,
+		Headers:          es.Headers,
+		StrictMode:       es.StrictMode,
+		Secure:           es.Secure,
+		SignatureCheck:   es.SignatureCheck,
+		Age:              es.Age,
+		IgnorePatterns:   es.IgnorePatterns,
+		ClientCertPublic: es.ClientCertPublic,
+		OAuth2TokenURL:   es.OAuth2TokenURL,
+		OAuth2ClientID:   es.OAuth2ClientID,
+		OAuth2Scopes:     es.OAuth2Scopes,
+		BasicAuthUser:    es.BasicAuthUser,
+		TLSCABundle:      es.TLSCABundle,
+		TLSPinnedCerts:   es.TLSPinnedCerts,
+		ReceiptURL:       es.ReceiptURL,
+	}
+	if es.Name == "" || es.URL == "" {
+		return fail(errors.New("name and url are required"))
+	}
+	if s := es.Secrets; s != nil {
+		for _, x := range []struct {
+			name string
+			data []byte
+			dst  **string
+		}{
+			{"client_cert_private", s.ClientCertPrivate, &src.ClientCertPrivate},
+			{"client_cert_passphrase", s.ClientCertPassphrase, &src.ClientCertPassphrase},
+			{"oauth2_client_secret", s.OAuth2ClientSecret, &src.OAuth2ClientSecret},
+			{"basic_auth_password", s.BasicAuthPassword, &src.BasicAuthPassword},
+		} {
+			plain, err := c.sm.DecryptSecret(x.data)
+			if err != nil {
+				result.Warnings = append(result.Warnings,
+					fmt.Sprintf("%s left out: %v", x.name, err))
+				continue
+			}
+			*x.dst = optString(plain)
+		}
+	}
+	if dryRun {
+		return result
+	}
+
+	id, err := c.addSource(ctx.Request.Context(), &src)
+	if err != nil {
+		if !errors.Is(err, sources.InvalidArgumentError("")) {
+			slog.ErrorContext(ctx, "restoring source failed", "name", es.Name, "err", err)
+		}
+		return fail(err)
+	}
+	result.ID = &id
+
+	for _, ef := range es.Feeds {
+		u, err := url.Parse(ef.URL)
+		if err != nil {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("feed %q has an invalid URL: %v", ef.URL, err))
+			continue
+		}
+		feedID, err := c.sm.AddFeed(ctx.Request.Context(), id, ef.Label, u, ef.LogLevel)
+		if err != nil {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("feed %q could not be added: %v", ef.URL, err))
+			continue
+		}
+		result.Feeds = append(result.Feeds, importedFeed{
+			ID:    feedID,
+			Label: ef.Label,
+			URL:   ef.URL,
+		})
+	}
+	// Activate the source last so that it starts with all its feeds.
+	if _, err := c.sm.UpdateSource(ctx.Request.Context(), id, func(su *sources.SourceUpdater) error {
+		return errors.Join(
+			su.UpdateFetchWindows(fetchWindows),
+			su.UpdateShadow(es.Shadow),
+			su.UpdateActive(es.Active),
+		)
+	}); err != nil {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("source could not be activated: %v", err))
+	}
+	slog.InfoContext(ctx, "restored source", "name", es.Name, "id", id, "feeds", len(result.Feeds))
+	return result
+}
@@ -0,0 +1,214 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/sources"
+)
+
+// csafToolConfigLimit is the maximal size of an uploaded configuration.
+const csafToolConfigLimit = 1024 * 1024
+
+type importedFeed struct {
+	ID    int64  `json:"id"`
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+type importedSource struct {
+	ID             *int64         `json:"id,omitempty"`
+	Name           string         `json:"name"`
+	URL            string         `json:"url"`
+	Rate           *float64       `json:"rate,omitempty"`
+	Slots          *int           `json:"slots,omitempty"`
+	Headers        []string       `json:"headers,omitempty"`
+	StrictMode     *bool          `json:"strict_mode,omitempty"`
+	Secure         *bool          `json:"secure,omitempty"`
+	SignatureCheck *bool          `json:"signature_check,omitempty"`
+	Age            *sourceAge     `json:"age,omitempty" swaggertype:"primitive,integer"`
+	IgnorePatterns []string       `json:"ignore_patterns,omitempty"`
+	Feeds          []importedFeed `json:"feeds,omitempty"`
+	Warnings       []string       `json:"warnings,omitempty"`
+	Error          *string        `json:"error,omitempty"`
+}
+
+// importSources is an endpoint that creates sources from the
+// configuration of a csaf_downloader or csaf_aggregator.
+//
+//	@Summary		Imports sources from csaf_distribution configurations or source exports.
+//	@Description	Reads the TOML configuration of a csaf_downloader or a csaf_aggregator
+//	@Description	and creates a source for every provider with all feeds of its PMD.
+//	@Description	Rate limits, workers, ignore patterns, headers, TLS and signature
+//	@Description	settings are mapped. Settings which cannot be mapped are reported as warnings.
+//	@Description	As csaf_downloader takes the domains as arguments they have to be given.
+//	@Description	If the body is JSON the sources and feeds of a source export are restored.
+//	@Description	Sources with names already in use are reported as errors.
+//	@Description	For source exports dry_run is given as query parameter.
+//	@Param			config	formData	file			false	"TOML configuration"
+//	@Param			domains	formData	string			false	"Space separated domains of a csaf_downloader"
+//	@Param			dry_run	formData	bool			false	"Only report the mapped sources"
+//	@Param			export	body		sourceExport	false	"Source export"
+//	@Accept			multipart/form-data,json
+//	@Produce		json
+//	@Success		200	{array}		importedSource
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Router			/sources/import [post]
+func (c *Controller) importSources(ctx *gin.Context) {
+	if ctx.ContentType() == gin.MIMEJSON {
+		c.restoreSources(ctx)
+		return
+	}
+	dryRun, ok := parse(ctx, strconv.ParseBool, ctx.DefaultPostForm("dry_run", "false"))
+	if !ok {
+		return
+	}
+	file, err := ctx.FormFile("config")
+	if err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	limited := http.MaxBytesReader(ctx.Writer, f, csafToolConfigLimit)
+	defer limited.Close()
+
+	imported, err := sources.ParseCSAFToolConfig(
+		limited,
+		strings.Fields(ctx.PostForm("domains")),
+		c.cfg.Sources.MaxSlotsPerSource)
+	if err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	results := make([]importedSource, 0, len(imported))
+	for i := range imported {
+		results = append(results, c.importSource(ctx.Request.Context(), &imported[i], dryRun))
+	}
+	ctx.JSON(http.StatusOK, results)
+}
+
+// importSource validates and optionally creates a single imported source.
+func (c *Controller) importSource(
+	ctx context.Context,
+	is *sources.ImportedSource,
+	dryRun bool,
+) importedSource {
+	result := importedSource{
+		Name:           is.Name,
+		URL:            is.URL,
+		Rate:           is.Rate,
+		Slots:          is.Slots,
+		Headers:        is.Headers,
+		StrictMode:     is.StrictMode,
+		Secure:         is.Secure,
+		SignatureCheck: is.SignatureCheck,
+		IgnorePatterns: is.IgnorePatterns,
+		Warnings:       is.Warnings,
+	}
+	fail := func(err error) importedSource {
+		msg := err.Error()
+		result.Error = &msg
+		return result
+	}
+	if maxRate := c.cfg.Sources.MaxRatePerSource; result.Rate != nil && maxRate != 0 && *result.Rate > maxRate {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("rate %g reduced to %g", *result.Rate, maxRate))
+		result.Rate = &maxRate
+	}
+	if err := validateHeaders(result.Headers); err != nil {
+		return fail(err)
+	}
+	ignorePatterns, err := sources.AsRegexps(result.IgnorePatterns)
+	if err != nil {
+		return fail(err)
+	}
+	age := is.Age
+	if age == nil && c.cfg.Sources.DefaultAge != 0 {
+		age = &c.cfg.Sources.DefaultAge
+	}
+	if age != nil {
+		result.Age = &sourceAge{*age}
+	}
+	if dryRun {
+		return result
+	}
+
+	id, err := c.sm.AddSource(
+		ctx,
+		result.Name,
+		result.URL,
+		result.Rate,
+		result.Slots,
+		nil,
+		sources.NormalPriority,
+		result.Headers,
+		result.StrictMode,
+		result.Secure,
+		result.SignatureCheck,
+		age,
+		ignorePatterns,
+		nil, nil, nil,
+		nil, nil, nil, nil,
+		nil, nil,
+		nil, nil,
+		nil,
+	)
+	if err != nil {
+		if !errors.Is(err, sources.InvalidArgumentError("")) {
+			slog.Error("importing source failed", "name", result.Name, "err", err)
+		}
+		return fail(err)
+	}
+	result.ID = &id
+
+	feeds, err := c.sm.AvailableFeeds(id)
+	if err != nil {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("feeds could not be added: %v", err))
+		return result
+	}
+	for _, af := range feeds {
+		u, err := url.Parse(af.URL)
+		if err != nil {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("feed %q has an invalid URL: %v", af.URL, err))
+			continue
+		}
+		feedID, err := c.sm.AddFeed(ctx, id, af.Label, u, c.cfg.Sources.FeedLogLevel)
+		if err != nil {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("feed %q could not be added: %v", af.URL, err))
+			continue
+		}
+		result.Feeds = append(result.Feeds, importedFeed{
+			ID:    feedID,
+			Label: af.Label,
+			URL:   af.URL,
+		})
+	}
+	slog.Info("imported source", "name", result.Name, "id", id, "feeds", len(result.Feeds))
+	return result
+}
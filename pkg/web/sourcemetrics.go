@@ -0,0 +1,164 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// maxMetricsBuckets limits the number of time steps of a metrics query.
+const maxMetricsBuckets = 10_000
+
+// feedMetricsBucket are the summed up download outcomes of a time step.
+type feedMetricsBucket struct {
+	Time          time.Time `json:"time"`
+	Successes     int64     `json:"successes"`
+	Failures      int64     `json:"failures"`
+	Bytes         int64     `json:"bytes"`
+	DurationMS    int64     `json:"duration_ms"`
+	AvgDurationMS float64   `json:"avg_duration_ms"`
+}
+
+// feedMetrics is the time series of a feed.
+type feedMetrics struct {
+	ID      int64               `json:"id"`
+	Label   string              `json:"label"`
+	Buckets []feedMetricsBucket `json:"buckets"`
+}
+
+// sourceMetrics are the time series of the feeds of a source.
+type sourceMetrics struct {
+	ID    int64         `json:"id"`
+	From  time.Time     `json:"from"`
+	To    time.Time     `json:"to"`
+	Step  string        `json:"step"`
+	Feeds []feedMetrics `json:"feeds"`
+}
+
+// viewSourceMetrics is an endpoint that returns the download metrics of a source.
+//
+//	@Summary		Returns the download metrics of a source.
+//	@Description	Returns the time series of the successful and failed downloads,
+//	@Description	the download durations and the downloaded bytes of the feeds of
+//	@Description	the source summed up per time step. Steps without downloads are left out.
+//	@Param			id		path	int		true	"Source ID"
+//	@Param			from	query	string	false	"Timerange start, defaults to 24h before to"
+//	@Param			to		query	string	false	"Timerange end, defaults to now"
+//	@Param			step	query	string	false	"Time step, at least 1m, defaults to 1h"
+//	@Param			feed	query	int		false	"Restrict to a feed"
+//	@Produce		json
+//	@Success		200	{object}	sourceMetrics
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/sources/{id}/metrics [get]
+func (c *Controller) viewSourceMetrics(ctx *gin.Context) {
+	sourceID, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	from, to, step, ok := importStatsInterval(ctx, 24*time.Hour)
+	if !ok {
+		return
+	}
+	if ctx.Query("step") == "" {
+		step = time.Hour
+	}
+	if step < time.Minute {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "step has to be at least 1m")
+		return
+	}
+	if to.Sub(from)/step > maxMetricsBuckets {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "too many time steps")
+		return
+	}
+	var feedID *int64
+	if value := ctx.Query("feed"); value != "" {
+		id, ok := parse(ctx, toInt64, value)
+		if !ok {
+			return
+		}
+		feedID = &id
+	}
+
+	const (
+		existsSQL  = `SELECT EXISTS(SELECT 1 FROM sources WHERE id = $1)`
+		metricsSQL = `SELECT f.id, f.label, date_bin($2, m.time, $3) AS bucket, ` +
+			`sum(m.successes)::bigint, sum(m.failures)::bigint, ` +
+			`sum(m.bytes)::bigint, sum(m.duration_ms)::bigint ` +
+			`FROM feed_metrics m JOIN feeds f ON m.feeds_id = f.id ` +
+			`WHERE f.sources_id = $1 AND m.time >= $3 AND m.time < $4 ` +
+			`AND ($5::int IS NULL OR f.id = $5) ` +
+			`GROUP BY f.id, f.label, bucket ` +
+			`ORDER BY f.id, bucket`
+	)
+
+	result := sourceMetrics{
+		ID:    sourceID,
+		From:  from,
+		To:    to,
+		Step:  step.String(),
+		Feeds: []feedMetrics{},
+	}
+	var exists bool
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			if err := conn.QueryRow(rctx, existsSQL, sourceID).Scan(&exists); err != nil || !exists {
+				return err
+			}
+			rows, err := conn.Query(rctx, metricsSQL, sourceID, step, from, to, feedID)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var (
+					id    int64
+					label string
+					b     feedMetricsBucket
+				)
+				if err := rows.Scan(
+					&id, &label, &b.Time,
+					&b.Successes, &b.Failures, &b.Bytes, &b.DurationMS,
+				); err != nil {
+					return err
+				}
+				b.Time = b.Time.UTC()
+				if n := b.Successes + b.Failures; n > 0 {
+					b.AvgDurationMS = float64(b.DurationMS) / float64(n)
+				}
+				if l := len(result.Feeds); l == 0 || result.Feeds[l-1].ID != id {
+					result.Feeds = append(result.Feeds, feedMetrics{ID: id, Label: label})
+				}
+				last := &result.Feeds[len(result.Feeds)-1]
+				last.Buckets = append(last.Buckets, b)
+			}
+			return rows.Err()
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if !exists {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "source not found")
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
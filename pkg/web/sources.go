@@ -44,6 +44,11 @@ func (sa *sourceAge) UnmarshalParam(param string) error {
 	return nil
 }
 
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (sa *sourceAge) UnmarshalText(text []byte) error {
+	return sa.UnmarshalParam(string(text))
+}
+
 // MarshalText implements [encoding.TextMarshaler].
 func (sa sourceAge) MarshalText() ([]byte, error) {
 	s := sa.String()
@@ -55,31 +60,47 @@ type source struct {
 	Name                 string         `json:"name" form:"name" binding:"required,min=1"`
 	URL                  string         `json:"url" form:"url" binding:"required,min=1"`
 	Active               bool           `json:"active" form:"active"`
+	Shadow               bool           `json:"shadow" form:"shadow"`
+	Priority             string         `json:"priority" form:"priority"`
 	Attention            bool           `json:"attention" form:"attention"`
 	Status               []string       `json:"status,omitempty"`
+	AggregatorIssues     []string       `json:"aggregator_issues,omitempty"`
 	Rate                 *float64       `json:"rate,omitempty" form:"rate" binding:"omitnil,gte=0"`
 	Slots                *int           `json:"slots,omitempty" form:"slots" binding:"omitnil,gte=0"`
+	Weight               *int           `json:"weight,omitempty" form:"weight" binding:"omitnil,gte=0"`
 	Headers              []string       `json:"headers,omitempty" form:"headers"`
 	StrictMode           *bool          `json:"strict_mode,omitempty" form:"strict_mode"`
 	Secure               *bool          `json:"secure,omitempty" form:"secure"`
 	SignatureCheck       *bool          `json:"signature_check,omitempty" form:"signature_check"`
 	Age                  *sourceAge     `json:"age,omitempty" form:"age" swaggertype:"primitive,integer"`
 	IgnorePatterns       []string       `json:"ignore_patterns,omitempty" form:"ignore_patterns"`
+	FetchWindows         []string       `json:"fetch_windows,omitempty" form:"fetch_windows"`
 	ClientCertPublic     *string        `json:"client_cert_public,omitempty" form:"client_cert_public"`
 	ClientCertPrivate    *string        `json:"client_cert_private,omitempty" form:"client_cert_private"`
 	ClientCertPassphrase *string        `json:"client_cert_passphrase,omitempty" form:"client_cert_passphrase"`
+	OAuth2TokenURL       *string        `json:"oauth2_token_url,omitempty" form:"oauth2_token_url"`
+	OAuth2ClientID       *string        `json:"oauth2_client_id,omitempty" form:"oauth2_client_id"`
+	OAuth2ClientSecret   *string        `json:"oauth2_client_secret,omitempty" form:"oauth2_client_secret"`
+	OAuth2Scopes         []string       `json:"oauth2_scopes,omitempty" form:"oauth2_scopes"`
+	BasicAuthUser        *string        `json:"basic_auth_user,omitempty" form:"basic_auth_user"`
+	BasicAuthPassword    *string        `json:"basic_auth_password,omitempty" form:"basic_auth_password"`
+	TLSCABundle          *string        `json:"tls_ca_bundle,omitempty" form:"tls_ca_bundle"`
+	TLSPinnedCerts       []string       `json:"tls_pinned_certs,omitempty" form:"tls_pinned_certs"`
+	ReceiptURL           *string        `json:"receipt_url,omitempty" form:"receipt_url"`
+	LastActivity         *time.Time     `json:"last_activity,omitempty"`
 	Stats                *sources.Stats `json:"stats,omitempty"`
 	Healthy              *bool          `json:"healthy,omitempty"`
 }
 
 type feed struct {
-	ID       int64               `json:"id"`
-	Label    string              `json:"label"`
-	URL      string              `json:"url"`
-	Rolie    bool                `json:"rolie"`
-	LogLevel config.FeedLogLevel `json:"log_level"`
-	Stats    *sources.Stats      `json:"stats,omitempty"`
-	Healthy  *bool               `json:"healthy,omitempty"`
+	ID           int64               `json:"id"`
+	Label        string              `json:"label"`
+	URL          string              `json:"url"`
+	Rolie        bool                `json:"rolie"`
+	LogLevel     config.FeedLogLevel `json:"log_level"`
+	LastActivity *time.Time          `json:"last_activity,omitempty"`
+	Stats        *sources.Stats      `json:"stats,omitempty"`
+	Healthy      *bool               `json:"healthy,omitempty"`
 }
 
 var stars = "***"
@@ -91,6 +112,14 @@ func threeStars(b bool) *string {
 	return nil
 }
 
+// lastActivity returns nil if there was no activity.
+func lastActivity(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
 func newSource(si *sources.SourceInfo, healthy *bool) *source {
 	var sa *sourceAge
 	if si.Age != nil {
@@ -101,19 +130,34 @@ func newSource(si *sources.SourceInfo, healthy *bool) *source {
 		Name:                 si.Name,
 		URL:                  si.URL,
 		Active:               si.Active,
+		Shadow:               si.Shadow,
+		Priority:             string(si.Priority),
 		Attention:            si.Attention,
 		Status:               si.Status,
+		AggregatorIssues:     si.AggregatorIssues,
 		Rate:                 si.Rate,
 		Slots:                si.Slots,
+		Weight:               si.Weight,
 		Headers:              si.Headers,
 		StrictMode:           si.StrictMode,
 		Secure:               si.Secure,
 		SignatureCheck:       si.SignatureCheck,
 		Age:                  sa,
 		IgnorePatterns:       sources.AsStrings(si.IgnorePatterns),
+		FetchWindows:         si.FetchWindows,
 		ClientCertPublic:     threeStars(si.HasClientCertPublic),
 		ClientCertPrivate:    threeStars(si.HasClientCertPrivate),
 		ClientCertPassphrase: threeStars(si.HasClientCertPassphrase),
+		OAuth2TokenURL:       si.OAuth2TokenURL,
+		OAuth2ClientID:       si.OAuth2ClientID,
+		OAuth2ClientSecret:   threeStars(si.HasOAuth2ClientSecret),
+		OAuth2Scopes:         si.OAuth2Scopes,
+		BasicAuthUser:        si.BasicAuthUser,
+		BasicAuthPassword:    threeStars(si.HasBasicAuthPassword),
+		TLSCABundle:          threeStars(si.HasTLSCABundle),
+		TLSPinnedCerts:       si.TLSPinnedCerts,
+		ReceiptURL:           si.ReceiptURL,
+		LastActivity:         lastActivity(si.LastActivity),
 		Stats:                si.Stats,
 		Healthy:              healthy,
 	}
@@ -121,13 +165,14 @@ func newSource(si *sources.SourceInfo, healthy *bool) *source {
 
 func newFeed(fi *sources.FeedInfo, healthy *bool) *feed {
 	return &feed{
-		ID:       fi.ID,
-		Label:    fi.Label,
-		URL:      fi.URL.String(),
-		Rolie:    fi.Rolie,
-		LogLevel: fi.Lvl,
-		Stats:    fi.Stats,
-		Healthy:  healthy,
+		ID:           fi.ID,
+		Label:        fi.Label,
+		URL:          fi.URL.String(),
+		Rolie:        fi.Rolie,
+		LogLevel:     fi.Lvl,
+		LastActivity: lastActivity(fi.LastActivity),
+		Stats:        fi.Stats,
+		Healthy:      healthy,
 	}
 }
 
@@ -147,6 +192,11 @@ func showHealth(ctx *gin.Context) (bool, bool) {
 	return parse(ctx, strconv.ParseBool, st)
 }
 
+// listOrder parses the order of the listed sources or feeds.
+func listOrder(ctx *gin.Context) (sources.Order, bool) {
+	return parse(ctx, sources.ParseOrder, ctx.Query("order"))
+}
+
 func (c *Controller) isHealthy(ctx context.Context, isSource bool, id int64) (bool, error) {
 
 	healthSQL := `SELECT NOT EXISTS (` +
@@ -173,7 +223,7 @@ func (c *Controller) isHealthy(ctx context.Context, isSource bool, id int64) (bo
 			return conn.QueryRow(rctx, healthSQL, id).Scan(&healthy)
 		}, 0); {
 	case err != nil:
-		slog.Error("database error while fetching health status", "err", err)
+		slog.ErrorContext(ctx, "database error while fetching health status", "err", err)
 		return false, err
 	default:
 		return healthy, nil
@@ -186,9 +236,10 @@ func (c *Controller) isHealthy(ctx context.Context, isSource bool, id int64) (bo
 //	@Description	Returns the source configuration and metadata of all sources.
 //	@Param			stats	query	bool	false	"Enable statistic"
 //	@Param			health	query	bool	false	"Enable health indicator"
+//	@Param			order	query	string	false	"id (default), name, attention or activity"
 //	@Produce		json
 //	@Success		200	{object}	web.viewSources.sourcesResult
-//	@Failure		400	{object}	models.Error	"could not parse stats"
+//	@Failure		400	{object}	models.Error	"could not parse stats or order"
 //	@Failure		401
 //	@Router			/sources [get]
 func (c *Controller) viewSources(ctx *gin.Context) {
@@ -200,11 +251,15 @@ func (c *Controller) viewSources(ctx *gin.Context) {
 	if !ok {
 		return
 	}
+	order, ok := listOrder(ctx)
+	if !ok {
+		return
+	}
 	type sourcesResult struct {
 		Sources []*source `json:"sources"`
 	}
 	srcs := []*source{}
-	c.sm.Sources(func(si *sources.SourceInfo) {
+	if err := c.sm.Sources(ctx.Request.Context(), func(si *sources.SourceInfo) {
 		var healthy *bool
 		if health {
 			var err error
@@ -216,7 +271,10 @@ func (c *Controller) viewSources(ctx *gin.Context) {
 			healthy = &hlty
 		}
 		srcs = append(srcs, newSource(si, healthy))
-	}, stats)
+	}, stats, order); err != nil {
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
 	ctx.JSON(http.StatusOK, sourcesResult{Sources: srcs})
 }
 
@@ -244,49 +302,107 @@ func (c *Controller) createSource(ctx *gin.Context) {
 		models.SendError(ctx, http.StatusBadRequest, err)
 		return
 	}
+	switch id, err := c.addSource(ctx.Request.Context(), &src); {
+	case err == nil:
+		ctx.JSON(http.StatusCreated, models.ID{ID: id})
+	case errors.Is(err, sources.InvalidArgumentError("")):
+		models.SendError(ctx, http.StatusBadRequest, err)
+	default:
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+	}
+}
+
+// addSource validates the configuration of a new source and registers it.
+// Invalid configurations are reported as sources.InvalidArgumentError.
+func (c *Controller) addSource(ctx context.Context, src *source) (int64, error) {
 	if src.Rate != nil &&
 		(c.cfg.Sources.MaxRatePerSource != 0 && *src.Rate > c.cfg.Sources.MaxRatePerSource) {
-
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "'rate' out of range"})
-		return
+		return 0, sources.InvalidArgumentError("'rate' out of range")
 	}
 	if src.Rate != nil && *src.Rate == 0 {
 		src.Rate = nil
 	}
 	if src.Slots != nil && *src.Slots > c.cfg.Sources.MaxSlotsPerSource {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "'slots' out of range"})
-		return
+		return 0, sources.InvalidArgumentError("'slots' out of range")
 	}
 	if src.Slots != nil && *src.Slots == 0 {
 		src.Slots = nil
 	}
+	if src.Weight != nil && *src.Weight > sources.MaxWeight {
+		return 0, sources.InvalidArgumentError("'weight' out of range")
+	}
+	if src.Weight != nil && *src.Weight == 0 {
+		src.Weight = nil
+	}
+	priority := sources.NormalPriority
+	if src.Priority != "" {
+		var err error
+		if priority, err = sources.ParsePriority(src.Priority); err != nil {
+			return 0, err
+		}
+	}
 	if err := validateHeaders(src.Headers); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+		return 0, sources.InvalidArgumentError(err.Error())
 	}
 	ignorePatterns, err := sources.AsRegexps(src.IgnorePatterns)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+		return 0, sources.InvalidArgumentError(err.Error())
 	}
 	var clientCertPublic, clientCertPrivate, clientCertPassphrase []byte
 	if src.ClientCertPublic != nil {
 		clientCertPublic = []byte(*src.ClientCertPublic)
 		if !hasBlock(clientCertPublic) {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "client_cert_public has no PEM block"})
-			return
+			return 0, sources.InvalidArgumentError("client_cert_public has no PEM block")
 		}
 	}
 	if src.ClientCertPrivate != nil {
 		clientCertPrivate = []byte(*src.ClientCertPrivate)
 		if !hasBlock(clientCertPrivate) {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "client_cert_private has no PEM block"})
-			return
+			return 0, sources.InvalidArgumentError("client_cert_private has no PEM block")
 		}
 	}
 	if src.ClientCertPassphrase != nil {
 		clientCertPassphrase = []byte(*src.ClientCertPassphrase)
 	}
+	var oauth2ClientSecret []byte
+	if src.OAuth2TokenURL != nil {
+		if err := sources.ValidateTokenURL(*src.OAuth2TokenURL); err != nil {
+			return 0, sources.InvalidArgumentError(err.Error())
+		}
+		if src.OAuth2ClientID == nil || *src.OAuth2ClientID == "" {
+			return 0, sources.InvalidArgumentError("oauth2_client_id is missing")
+		}
+	}
+	if src.OAuth2ClientSecret != nil {
+		oauth2ClientSecret = []byte(*src.OAuth2ClientSecret)
+	}
+	var basicAuthPassword []byte
+	if src.BasicAuthPassword != nil {
+		if src.BasicAuthUser == nil || *src.BasicAuthUser == "" {
+			return 0, sources.InvalidArgumentError("basic_auth_user is missing")
+		}
+		basicAuthPassword = []byte(*src.BasicAuthPassword)
+	}
+	var tlsCABundle []byte
+	if src.TLSCABundle != nil && *src.TLSCABundle != "" {
+		tlsCABundle = []byte(*src.TLSCABundle)
+		if _, err := sources.ParseCABundle(tlsCABundle); err != nil {
+			return 0, sources.InvalidArgumentError(err.Error())
+		}
+	}
+	tlsPinnedCerts, err := sources.NormalizeFingerprints(src.TLSPinnedCerts)
+	if err != nil {
+		return 0, sources.InvalidArgumentError(err.Error())
+	}
+	if src.ReceiptURL != nil && *src.ReceiptURL == "" {
+		src.ReceiptURL = nil
+	}
+	if src.ReceiptURL != nil {
+		if err := sources.ValidateReceiptURL(*src.ReceiptURL); err != nil {
+			return 0, err
+		}
+	}
 
 	var age *time.Duration
 	if src.Age != nil {
@@ -296,11 +412,14 @@ func (c *Controller) createSource(ctx *gin.Context) {
 		age = &c.cfg.Sources.DefaultAge
 	}
 
-	switch id, err := c.sm.AddSource(
+	return c.sm.AddSource(
+		ctx,
 		src.Name,
 		src.URL,
 		src.Rate,
 		src.Slots,
+		src.Weight,
+		priority,
 		src.Headers,
 		src.StrictMode,
 		src.Secure,
@@ -310,21 +429,23 @@ func (c *Controller) createSource(ctx *gin.Context) {
 		clientCertPublic,
 		clientCertPrivate,
 		clientCertPassphrase,
-	); {
-	case err == nil:
-		ctx.JSON(http.StatusCreated, models.ID{ID: id})
-	case errors.Is(err, sources.InvalidArgumentError("")):
-		models.SendError(ctx, http.StatusBadRequest, err)
-	default:
-		slog.Error("database error", "err", err)
-		models.SendError(ctx, http.StatusInternalServerError, err)
-	}
+		src.OAuth2TokenURL,
+		src.OAuth2ClientID,
+		oauth2ClientSecret,
+		src.OAuth2Scopes,
+		src.BasicAuthUser,
+		basicAuthPassword,
+		tlsCABundle,
+		tlsPinnedCerts,
+		src.ReceiptURL,
+	)
 }
 
 // deleteSource is an endpoint that deletes the source with specified ID.
 //
 //	@Summary		Deletes a source.
 //	@Description	Deletes the source configuration with the specified ID.
+//	@Description	Use the delete preview to see which data is affected.
 //	@Param			id	path	int	true	"Source ID"
 //	@Produce		json
 //	@Success		200	{object}	models.Success	"source deleted"
@@ -341,13 +462,46 @@ func (c *Controller) deleteSource(ctx *gin.Context) {
 		models.SendError(ctx, http.StatusBadRequest, err)
 		return
 	}
-	switch err := c.sm.RemoveSource(input.ID); {
+	switch err := c.sm.RemoveSource(ctx.Request.Context(), input.ID); {
 	case err == nil:
 		models.SendSuccess(ctx, http.StatusOK, "source deleted")
 	case errors.Is(err, sources.NoSuchEntryError("")):
 		models.SendError(ctx, http.StatusNotFound, err)
 	default:
-		slog.Error("database error", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+	}
+}
+
+// previewDeleteSource is an endpoint that summarizes what deleting a source affects.
+//
+//	@Summary		Previews the deletion of a source.
+//	@Description	Returns the number of feeds, feed log entries and download records
+//	@Description	which are removed or unlinked if the source is deleted, the number of
+//	@Description	imported documents which are kept without their provenance, their
+//	@Description	open forwards and the dropped queued downloads.
+//	@Param			id	path	int	true	"Source ID"
+//	@Produce		json
+//	@Success		200	{object}	sources.DeletePreview
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/sources/{id}/delete-preview [get]
+func (c *Controller) previewDeleteSource(ctx *gin.Context) {
+	sourceID, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	switch preview, err := c.sm.SourceDeletePreview(ctx.Request.Context(), sourceID); {
+	case err == nil:
+		ctx.JSON(http.StatusOK, preview)
+	case errors.Is(err, sources.NoSuchEntryError("")):
+		models.SendError(ctx, http.StatusNotFound, err)
+	case errors.Is(err, sources.InvalidArgumentError("")):
+		models.SendError(ctx, http.StatusBadRequest, err)
+	default:
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 	}
 }
@@ -381,7 +535,7 @@ func (c *Controller) viewSource(ctx *gin.Context) {
 	if !ok {
 		return
 	}
-	si := c.sm.Source(input.ID, stats)
+	si := c.sm.Source(ctx.Request.Context(), input.ID, stats)
 	if si == nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
@@ -421,7 +575,7 @@ func (c *Controller) updateSource(ctx *gin.Context) {
 		models.SendError(ctx, http.StatusBadRequest, err)
 		return
 	}
-	switch ur, err := c.sm.UpdateSource(input.SourceID, func(su *sources.SourceUpdater) error {
+	switch ur, err := c.sm.UpdateSource(ctx.Request.Context(), input.SourceID, func(su *sources.SourceUpdater) error {
 		// name
 		if name, ok := ctx.GetPostForm("name"); ok {
 			if err := su.UpdateName(name); err != nil {
@@ -466,6 +620,23 @@ func (c *Controller) updateSource(ctx *gin.Context) {
 				return err
 			}
 		}
+		// weight
+		if weight, ok := ctx.GetPostForm("weight"); ok {
+			var w *int
+			if weight != "" {
+				x, err := strconv.Atoi(weight)
+				if err != nil {
+					return sources.InvalidArgumentError(
+						fmt.Sprintf("parsing 'weight' failed: %v", err.Error()))
+				}
+				if x != 0 {
+					w = &x
+				}
+			}
+			if err := su.UpdateWeight(w); err != nil {
+				return err
+			}
+		}
 		// active
 		if active, ok := ctx.GetPostForm("active"); ok {
 			act, err := strconv.ParseBool(active)
@@ -477,6 +648,27 @@ func (c *Controller) updateSource(ctx *gin.Context) {
 				return err
 			}
 		}
+		// shadow
+		if shadow, ok := ctx.GetPostForm("shadow"); ok {
+			sh, err := strconv.ParseBool(shadow)
+			if err != nil {
+				return sources.InvalidArgumentError(
+					fmt.Sprintf("parsing 'shadow' failed: %v", err.Error()))
+			}
+			if err := su.UpdateShadow(sh); err != nil {
+				return err
+			}
+		}
+		// priority
+		if priority, ok := ctx.GetPostForm("priority"); ok {
+			p, err := sources.ParsePriority(priority)
+			if err != nil {
+				return err
+			}
+			if err := su.UpdatePriority(p); err != nil {
+				return err
+			}
+		}
 		// attention
 		if attention, ok := ctx.GetPostForm("attention"); ok {
 			att, err := strconv.ParseBool(attention)
@@ -556,6 +748,16 @@ func (c *Controller) updateSource(ctx *gin.Context) {
 				return err
 			}
 		}
+		// fetchWindows
+		if windows, ok := ctx.GetPostFormArray("fetch_windows"); ok {
+			fetchWindows, err := sources.ParseFetchWindows(windows)
+			if err != nil {
+				return err
+			}
+			if err := su.UpdateFetchWindows(fetchWindows); err != nil {
+				return err
+			}
+		}
 		// client certificate update
 		optCert := func(option string, update func([]byte) error) error {
 			cert, ok := ctx.GetPostForm(option)
@@ -587,6 +789,74 @@ func (c *Controller) updateSource(ctx *gin.Context) {
 				return err
 			}
 		}
+		// OAuth2 client credentials update
+		optString := func(option string, update func(*string) error) error {
+			value, ok := ctx.GetPostForm(option)
+			if !ok {
+				return nil
+			}
+			var v *string
+			if value != "" {
+				v = &value
+			}
+			return update(v)
+		}
+		if err := optString("oauth2_token_url", su.UpdateOAuth2TokenURL); err != nil {
+			return err
+		}
+		if err := optString("oauth2_client_id", su.UpdateOAuth2ClientID); err != nil {
+			return err
+		}
+		if secret, ok := ctx.GetPostForm("oauth2_client_secret"); ok {
+			var data []byte
+			if secret != "" {
+				data = []byte(secret)
+			}
+			if err := su.UpdateOAuth2ClientSecret(data); err != nil {
+				return err
+			}
+		}
+		if scopes, ok := ctx.GetPostFormArray("oauth2_scopes"); ok {
+			if err := su.UpdateOAuth2Scopes(scopes); err != nil {
+				return err
+			}
+		}
+		// Basic auth credentials update
+		if err := optString("basic_auth_user", su.UpdateBasicAuthUser); err != nil {
+			return err
+		}
+		if password, ok := ctx.GetPostForm("basic_auth_password"); ok {
+			var data []byte
+			if password != "" {
+				data = []byte(password)
+			}
+			if err := su.UpdateBasicAuthPassword(data); err != nil {
+				return err
+			}
+		}
+		// TLS trust settings update
+		if bundle, ok := ctx.GetPostForm("tls_ca_bundle"); ok {
+			var data []byte
+			if bundle != "" {
+				data = []byte(bundle)
+			}
+			if err := su.UpdateTLSCABundle(data); err != nil {
+				return err
+			}
+		}
+		if pins, ok := ctx.GetPostFormArray("tls_pinned_certs"); ok {
+			normalized, err := sources.NormalizeFingerprints(pins)
+			if err != nil {
+				return err
+			}
+			if err := su.UpdateTLSPinnedCerts(normalized); err != nil {
+				return err
+			}
+		}
+		// Receipts to the provider
+		if err := optString("receipt_url", su.UpdateReceiptURL); err != nil {
+			return err
+		}
 		return nil
 	}); {
 	case err == nil:
@@ -596,7 +866,7 @@ func (c *Controller) updateSource(ctx *gin.Context) {
 	case errors.Is(err, sources.InvalidArgumentError("")):
 		models.SendError(ctx, http.StatusBadRequest, err)
 	default:
-		slog.Error("database error", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 	}
 }
@@ -623,9 +893,10 @@ type feedResult struct {
 //	@Param			health	path	int		true	"Source ID"
 //	@Param			stats	query	bool	false	"Enable statistic"
 //	@Param			health	query	bool	false	"Enable health indicator"
+//	@Param			order	query	string	false	"id (default), name or activity"
 //	@Produce		json
 //	@Success		200	{object}	feedResult
-//	@Failure		400	{object}	models.Error	"could not parse stats"
+//	@Failure		400	{object}	models.Error	"could not parse stats or order"
 //	@Failure		401
 //	@Failure		404	{object}	models.Error
 //	@Failure		500	{object}	models.Error
@@ -648,9 +919,13 @@ func (c *Controller) viewFeeds(ctx *gin.Context) {
 	if !ok {
 		return
 	}
+	order, ok := listOrder(ctx)
+	if !ok {
+		return
+	}
 	feeds := []*feed{}
 
-	switch err := c.sm.Feeds(input.SourceID, func(fi *sources.FeedInfo) {
+	switch err := c.sm.Feeds(ctx.Request.Context(), input.SourceID, func(fi *sources.FeedInfo) {
 		var healthy *bool
 		if health {
 			var err error
@@ -663,13 +938,15 @@ func (c *Controller) viewFeeds(ctx *gin.Context) {
 
 		}
 		feeds = append(feeds, newFeed(fi, healthy))
-	}, stats); {
+	}, stats, order); {
 	case err == nil:
 		ctx.JSON(http.StatusOK, feedResult{Feeds: feeds})
 	case errors.Is(err, sources.NoSuchEntryError("")):
 		models.SendError(ctx, http.StatusNotFound, err)
+	case errors.Is(err, sources.InvalidArgumentError("")):
+		models.SendError(ctx, http.StatusBadRequest, err)
 	default:
-		slog.Error("database error", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 	}
 }
@@ -708,6 +985,7 @@ func (c *Controller) createFeed(ctx *gin.Context) {
 	}
 	parsed, _ := url.Parse(input.URL)
 	switch feedID, err := c.sm.AddFeed(
+		ctx.Request.Context(),
 		input.SourceID,
 		input.Label,
 		parsed,
@@ -720,7 +998,7 @@ func (c *Controller) createFeed(ctx *gin.Context) {
 	case errors.Is(err, sources.InvalidArgumentError("")):
 		models.SendError(ctx, http.StatusBadRequest, err)
 	default:
-		slog.Error("database error", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 	}
 }
@@ -747,7 +1025,7 @@ func (c *Controller) updateFeed(ctx *gin.Context) {
 		models.SendError(ctx, http.StatusBadRequest, err)
 		return
 	}
-	switch updated, err := c.sm.UpdateFeed(input.FeedID, func(fu *sources.FeedUpdater) error {
+	switch updated, err := c.sm.UpdateFeed(ctx.Request.Context(), input.FeedID, func(fu *sources.FeedUpdater) error {
 		// label
 		if label, ok := ctx.GetPostForm("label"); ok {
 			if err := fu.UpdateLabel(label); err != nil {
@@ -780,7 +1058,7 @@ func (c *Controller) updateFeed(ctx *gin.Context) {
 	case errors.Is(err, sources.InvalidArgumentError("")):
 		models.SendError(ctx, http.StatusBadRequest, err)
 	default:
-		slog.Error("database error", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 	}
 }
@@ -814,7 +1092,7 @@ func (c *Controller) viewFeed(ctx *gin.Context) {
 	if !ok {
 		return
 	}
-	fi := c.sm.Feed(input.FeedID, stats)
+	fi := c.sm.Feed(ctx.Request.Context(), input.FeedID, stats)
 	if fi == nil {
 		models.SendErrorMessage(ctx, http.StatusNotFound, "feed not found")
 		return
@@ -834,9 +1112,12 @@ func (c *Controller) viewFeed(ctx *gin.Context) {
 
 // @Summary		Deletes a feed.
 // @Description	Deletes the feed configuration with the specified ID.
+// @Description	If downloads of the feed are running no new ones are started and
+// @Description	the feed is deleted after the running ones are finished.
 // @Param			id	path	int	true	"Feed ID"
 // @Produce		json
 // @Success		200	{object}	models.Success	"deleted"
+// @Success		202	{object}	models.Success	"draining"
 // @Failure		400	{object}	models.Error
 // @Failure		401
 // @Failure		404	{object}	models.Error
@@ -850,13 +1131,54 @@ func (c *Controller) deleteFeed(ctx *gin.Context) {
 		models.SendError(ctx, http.StatusBadRequest, err)
 		return
 	}
-	switch err := c.sm.RemoveFeed(input.FeedID); {
-	case err == nil:
+	switch deleted, err := c.sm.RemoveFeed(ctx.Request.Context(), input.FeedID); {
+	case err == nil && deleted:
 		models.SendSuccess(ctx, http.StatusOK, "deleted")
+	case err == nil:
+		models.SendSuccess(ctx, http.StatusAccepted, "draining")
+	case errors.Is(err, sources.NoSuchEntryError("")):
+		models.SendError(ctx, http.StatusNotFound, err)
+	default:
+		slog.ErrorContext(ctx, "removing feed failed", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+	}
+}
+
+// feedSync is an endpoint that compares the upstream feed with the local holdings.
+//
+//	@Summary		Returns the sync status of a feed.
+//	@Description	Fetches the feed index and compares it with the imported entries.
+//	@Description	The list of missing entries is capped by limit.
+//	@Param			id		path	int	true	"Feed ID"
+//	@Param			limit	query	int	false	"Max number of missing entries, defaults to 100"
+//	@Produce		json
+//	@Success		200	{object}	sources.SyncStatus
+//	@Failure		400	{object}	models.Error	"could not parse id"
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Failure		502	{object}	models.Error
+//	@Router			/sources/feeds/{id}/sync [get]
+func (c *Controller) feedSync(ctx *gin.Context) {
+	feedID, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	limit, ok := parse(ctx, strconv.Atoi, ctx.DefaultQuery("limit", "100"))
+	if !ok {
+		return
+	}
+	status, err := c.sm.FeedSync(ctx.Request.Context(), feedID, limit)
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, status)
 	case errors.Is(err, sources.NoSuchEntryError("")):
 		models.SendError(ctx, http.StatusNotFound, err)
+	case errors.Is(err, sources.InvalidArgumentError("")):
+		slog.WarnContext(ctx, "checking feed sync failed", "err", err, "feed", feedID)
+		models.SendError(ctx, http.StatusBadGateway, err)
 	default:
-		slog.Error("removing feed failed", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 	}
 }
@@ -895,7 +1217,12 @@ func (c *Controller) allFeedsLog(ctx *gin.Context) {
 
 // logRenderer renders a stream of log entries directly from the database.
 type logRenderer struct {
-	counter int64
+	counter   int64
+	estimated int64
+	// limit is the number of entries to render if hasMore is set.
+	// One more entry is fetched to find out if there are more.
+	limit   int64
+	hasMore bool
 	entries iter.Seq[sources.FeedLogInfo]
 }
 
@@ -909,16 +1236,33 @@ func (*logRenderer) WriteContentType(w http.ResponseWriter) {
 // Write implements [render.Render].
 func (lr *logRenderer) Render(w http.ResponseWriter) error {
 	var err error
+	if _, err = fmt.Fprint(w, "{"); err != nil {
+		return nil
+	}
 	if lr.counter > -1 {
-		_, err = fmt.Fprintf(w, "{\"count\":%d,\"entries\":[", lr.counter)
-	} else {
-		_, err = fmt.Fprint(w, `{"entries":[`)
+		if _, err = fmt.Fprintf(w, "\"count\":%d,", lr.counter); err != nil {
+			return nil
+		}
 	}
-	if err != nil {
+	if lr.estimated > -1 {
+		if _, err = fmt.Fprintf(w, "\"estimated_total\":%d,", lr.estimated); err != nil {
+			return nil
+		}
+	}
+	if _, err = fmt.Fprint(w, `"entries":[`); err != nil {
 		return nil
 	}
-	already := false
+	var (
+		already = false
+		more    = false
+		n       int64
+	)
 	for entry := range lr.entries {
+		if lr.hasMore && lr.limit >= 0 && n >= lr.limit {
+			more = true
+			break
+		}
+		n++
 		if already {
 			if _, err := fmt.Fprint(w, ","); err != nil {
 				return err
@@ -935,22 +1279,65 @@ func (lr *logRenderer) Render(w http.ResponseWriter) error {
 			return err
 		}
 	}
-	_, err = fmt.Fprint(w, "]}")
+	if lr.hasMore {
+		_, err = fmt.Fprintf(w, "],\"has_more\":%t}", more)
+	} else {
+		_, err = fmt.Fprint(w, "]}")
+	}
 	return err
 }
 
+// feedLogFilter are the common filter criteria of the feed log.
+type feedLogFilter struct {
+	from, to *time.Time
+	search   string
+	levels   []config.FeedLogLevel
+}
+
+// parseFeedLogFilter extracts the feed log filter from the query parameters.
+func parseFeedLogFilter(ctx *gin.Context) (feedLogFilter, bool) {
+	filter := feedLogFilter{search: ctx.Query("search")}
+
+	if lvls := ctx.Query("levels"); lvls != "" {
+		for lvl := range strings.FieldsSeq(lvls) {
+			logLevel, ok := parse(ctx, config.ParseFeedLogLevel, lvl)
+			if !ok {
+				return filter, false
+			}
+			filter.levels = append(filter.levels, logLevel)
+		}
+	}
+
+	if f := ctx.Query("from"); f != "" {
+		fp, ok := parse(ctx, parseTime, f)
+		if !ok {
+			return filter, false
+		}
+		filter.from = &fp
+	}
+
+	if t := ctx.Query("to"); t != "" {
+		tp, ok := parse(ctx, parseTime, t)
+		if !ok {
+			return filter, false
+		}
+		filter.to = &tp
+	}
+	return filter, true
+}
+
 func (c *Controller) feedLogs(ctx *gin.Context, feedID *int64) {
 	//lint:ignore U1000 It's used by swaggo.
 	type feedLogEntries struct {
-		Entries []sources.FeedLogInfo `json:"entries"`
-		Count   *int64                `json:"count,omitempty"`
+		Entries        []sources.FeedLogInfo `json:"entries"`
+		Count          *int64                `json:"count,omitempty"`
+		EstimatedTotal *int64                `json:"estimated_total,omitempty"`
+		HasMore        *bool                 `json:"has_more,omitempty"`
 	}
 	var (
-		from, to      *time.Time
-		search              = ctx.Query("search")
-		limit, offset int64 = -1, -1
-		logLevels     []config.FeedLogLevel
-		count, ok     bool
+		limit, offset       int64 = -1, -1
+		count, estimate, ok bool
+		hasMore             bool
 	)
 
 	if ofs := ctx.Query("offset"); ofs != "" {
@@ -971,50 +1358,54 @@ func (c *Controller) feedLogs(ctx *gin.Context, feedID *int64) {
 		}
 	}
 
-	if lvls := ctx.Query("levels"); lvls != "" {
-		for lvl := range strings.FieldsSeq(lvls) {
-			logLevel, ok := parse(ctx, config.ParseFeedLogLevel, lvl)
-			if !ok {
-				return
-			}
-			logLevels = append(logLevels, logLevel)
+	if est := ctx.Query("estimate"); est != "" {
+		if estimate, ok = parse(ctx, strconv.ParseBool, est); !ok {
+			return
 		}
 	}
 
-	if f := ctx.Query("from"); f != "" {
-		fp, ok := parse(ctx, parseTime, f)
-		if !ok {
+	if more := ctx.Query("has_more"); more != "" {
+		if hasMore, ok = parse(ctx, strconv.ParseBool, more); !ok {
 			return
 		}
-		from = &fp
 	}
 
-	if t := ctx.Query("to"); t != "" {
-		tp, ok := parse(ctx, parseTime, t)
-		if !ok {
-			return
-		}
-		to = &tp
+	filter, ok := parseFeedLogFilter(ctx)
+	if !ok {
+		return
 	}
 
 	var (
-		lr            = logRenderer{counter: -1}
-		reportCounter func(int64)
-		err           error
+		lr = logRenderer{
+			counter:   -1,
+			estimated: -1,
+			limit:     limit,
+			hasMore:   hasMore,
+		}
+		reportCounter, reportEstimate func(int64)
+		err                           error
 	)
 
 	if count {
 		reportCounter = func(c int64) { lr.counter = c }
 	}
+	if estimate {
+		reportEstimate = func(e int64) { lr.estimated = e }
+	}
+	// One more entry is fetched to find out if there are more.
+	fetch := limit
+	if hasMore && limit >= 0 {
+		fetch++
+	}
 
 	lr.entries, err = c.sm.StreamFeedLog(
 		ctx.Request.Context(),
-		feedID,
-		from, to,
-		search,
-		limit, offset, logLevels, reportCounter)
+		feedID, nil,
+		filter.from, filter.to,
+		filter.search,
+		fetch, offset, filter.levels, reportCounter, reportEstimate)
 	if err != nil {
-		slog.Error("database error", "error", err)
+		slog.ErrorContext(ctx, "database error", "error", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -1070,12 +1461,63 @@ func (c *Controller) attentionSources(ctx *gin.Context) {
 		Name string `json:"name"`
 	}
 	list := []attention{}
-	c.sm.AttentionSources(all, func(id int64, name string) {
+	if err := c.sm.AttentionSources(ctx.Request.Context(), all, func(id int64, name string) {
 		list = append(list, attention{ID: id, Name: name})
-	})
+	}); err != nil {
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
 	ctx.JSON(http.StatusOK, list)
 }
 
+// acknowledgeAttentionSources acknowledges the attention flags of sources in bulk.
+//
+//	@Summary		Acknowledges the attention flags of sources.
+//	@Description	Resets the attention flags of all sources that need attention
+//	@Description	and records who acknowledged them. If ids are given only these
+//	@Description	sources are acknowledged.
+//	@Param			all	formData	bool	false	"Include inactive sources"
+//	@Param			ids	formData	[]int	false	"Source IDs"	collectionFormat(multi)
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Success		200	{array}		sources.AcknowledgedSource
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/sources/attention/ack [post]
+func (c *Controller) acknowledgeAttentionSources(ctx *gin.Context) {
+	all, ok := parse(ctx, strconv.ParseBool, ctx.DefaultPostForm("all", "false"))
+	if !ok {
+		return
+	}
+	ids, ok := parseIDs(ctx, "ids")
+	if !ok {
+		return
+	}
+	acked, err := c.sm.AcknowledgeAttention(ctx.Request.Context(), ctx.GetString("uid"), all, ids)
+	if err != nil {
+		slog.ErrorContext(ctx, "acknowledging source attention failed", "error", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if acked == nil {
+		acked = []sources.AcknowledgedSource{}
+	}
+	ctx.JSON(http.StatusOK, acked)
+}
+
+// pipelineStats returns the current numbers of the import pipeline.
+//
+//	@Summary		Returns the state of the import pipeline.
+//	@Description	Returns the queue depths, the workers busy in each stage and the memory used by the import pipeline.
+//	@Produce		json
+//	@Success		200	{object}	sources.PipelineStats
+//	@Failure		401
+//	@Router			/sources/pipeline [get]
+func (c *Controller) pipelineStats(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.sm.PipelineStats())
+}
+
 // defaultSourceConfig returns the default source configuration.
 //
 //	@Summary		Returns the default configuration.
@@ -1087,6 +1529,8 @@ func (c *Controller) attentionSources(ctx *gin.Context) {
 func (c *Controller) defaultSourceConfig(ctx *gin.Context) {
 	type sourceConfig struct {
 		Slots          int                 `json:"slots"`
+		Weight         int                 `json:"weight"`
+		Priority       sources.Priority    `json:"priority"`
 		Rate           float64             `json:"rate"`
 		LogLevel       config.FeedLogLevel `json:"log_level"`
 		StrictMode     bool                `json:"strict_mode"`
@@ -1097,6 +1541,8 @@ func (c *Controller) defaultSourceConfig(ctx *gin.Context) {
 	cfg := c.cfg.Sources
 	ctx.JSON(http.StatusOK, sourceConfig{
 		Slots:          cfg.MaxSlotsPerSource,
+		Weight:         sources.DefaultWeight,
+		Priority:       sources.NormalPriority,
 		Rate:           cfg.MaxRatePerSource,
 		LogLevel:       cfg.FeedLogLevel,
 		StrictMode:     cfg.StrictMode,
@@ -1106,20 +1552,41 @@ func (c *Controller) defaultSourceConfig(ctx *gin.Context) {
 	})
 }
 
+// pmdHost returns the host of a PMD URL. Bare domains
+// without a scheme are looked up by the PMD loader, too.
+func pmdHost(raw string) (string, error) {
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	return u.Hostname(), nil
+}
+
 // pmd is an endpoint the provider metadata for a URL.
 //
 //	@Summary		Returns the pmd.
 //	@Description	Fetches and returns the provider metadata for the specified URL.
-//	@Param			url	query	string	true	"PMD URL"
+//	@Description	If a source is given its PMD is fetched with the credentials of the source.
+//	@Description	Fetching arbitrary URLs requires one of the configured PMD proxy roles
+//	@Description	and the host has to be in the configured domains. This applies
+//	@Description	to bare domains and to the redirects, too.
+//	@Param			url		query	string	false	"PMD URL or domain"
+//	@Param			source	query	int		false	"Source ID"
 //	@Produce		json
 //	@Success		200	{object}	any
 //	@Failure		400	{object}	models.Error	"could not parse url"
 //	@Failure		401
+//	@Failure		403	{object}	models.Error
+//	@Failure		404	{object}	models.Error
 //	@Failure		502	{object}	web.pmd.messages	"could not fetch pmd"
 //	@Router			/pmd [get]
 func (c *Controller) pmd(ctx *gin.Context) {
 	type inputForm struct {
-		URL string `form:"url" binding:"required,min=1"`
+		URL    string `form:"url" binding:"required_without=Source"`
+		Source *int64 `form:"source"`
 	}
 	input := inputForm{}
 	if err := ctx.ShouldBindQuery(&input); err != nil {
@@ -1129,7 +1596,35 @@ func (c *Controller) pmd(ctx *gin.Context) {
 	type messages struct {
 		Messages []string `json:"messages"`
 	}
-	cpmd := c.sm.PMD(input.URL)
+	var cpmd *sources.CachedProviderMetadata
+	if input.Source != nil {
+		if !c.hasAnyRole(ctx, models.SourceManager) {
+			models.SendErrorMessage(ctx, http.StatusForbidden, "not allowed to fetch PMD of source")
+			return
+		}
+		if cpmd = c.sm.SourcePMD(*input.Source); cpmd == nil {
+			models.SendErrorMessage(ctx, http.StatusNotFound, "not found")
+			return
+		}
+	} else {
+		slog.InfoContext(ctx, "PMD proxy request", "user", ctx.GetString("uid"), "url", input.URL)
+		if !c.hasAnyRoleName(ctx, c.cfg.Sources.PMDProxyRoles...) {
+			models.SendErrorMessage(ctx, http.StatusForbidden, "not allowed to fetch PMD of URL")
+			return
+		}
+		host, err := pmdHost(input.URL)
+		if err != nil {
+			models.SendError(ctx, http.StatusBadRequest, err)
+			return
+		}
+		if host == "" || !c.cfg.Sources.PMDProxyAllowed(host) {
+			slog.WarnContext(ctx, "PMD proxy request to disallowed domain",
+				"user", ctx.GetString("uid"), "url", input.URL)
+			models.SendErrorMessage(ctx, http.StatusForbidden, "domain not allowed")
+			return
+		}
+		cpmd = c.sm.ProxyPMD(input.URL)
+	}
 	if !cpmd.Valid() {
 		h := messages{}
 		msgs := cpmd.Loaded.Messages
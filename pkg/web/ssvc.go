@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
@@ -23,12 +24,16 @@ import (
 	"github.com/ISDuBA/ISDuBA/pkg/models"
 )
 
+// maxSSVCReasonLength limits the length of the reason given for a SSVC change.
+const maxSSVCReasonLength = 4096
+
 // changeSSVC is an endpoint that changes the SSVC of the specified document.
 //
 //	@Summary		Changes the SSVC.
 //	@Description	This updates the SSVC of the specified document.
 //	@Param			document	path	int		true	"Document ID"
 //	@Param			vector		query	string	true	"SSVC vector"
+//	@Param			reason		query	string	false	"Reason for the change"
 //	@Produce		json
 //	@Success		200	{object}	models.Success
 //	@Failure		400	{object}	models.Error
@@ -49,13 +54,22 @@ func (c *Controller) changeSSVC(ctx *gin.Context) {
 		return
 	}
 
+	var reason *string
+	if r := strings.TrimSpace(ctx.Query("reason")); r != "" {
+		if len(r) > maxSSVCReasonLength {
+			models.SendErrorMessage(ctx, http.StatusBadRequest, "reason too long")
+			return
+		}
+		reason = &r
+	}
+
 	const (
 		//		findSSVC = `SELECT docs.ssvc, ads.tracking_id, ads.publisher, docs.tlp, ads.state::text ` +
 		//			`FROM documents docs JOIN advisories ads ` +
 		//			`ON docs.advisories_id = ads.id ` +
 		//			`WHERE docs.id = $1`
 		// First part taken from above
-		findSSVC = `SELECT sh.ssvc, ads.tracking_id, ads.publisher, docs.tlp, ads.state::text ` +
+		findSSVC = `SELECT sh.ssvc, ads.tracking_id, ads.publisher, ads.state::text ` +
 			`FROM documents docs JOIN advisories ads ` +
 			`ON docs.advisories_id = ads.id ` +
 			// LEFT JOIN so we just get an empty ssvc if there is none in the history
@@ -71,8 +85,8 @@ func (c *Controller) changeSSVC(ctx *gin.Context) {
 			`WHERE (tracking_id, publisher) = ($1, $2)`
 		insertLog = `INSERT INTO events_log (event, state, actor, documents_id) ` +
 			`VALUES ($1::events, $2::workflow, $3, $4)`
-		updateSSVC = `INSERT INTO ssvc_history (actor, documents_id, ssvc) VALUES ` +
-			`($1::varchar, $2::integer, $3)`
+		updateSSVC = `INSERT INTO ssvc_history (actor, documents_id, ssvc, reason) VALUES ` +
+			`($1::varchar, $2::integer, $3, $4)`
 	)
 
 	var forbidden, unchanged, bad bool
@@ -90,25 +104,17 @@ func (c *Controller) changeSSVC(ctx *gin.Context) {
 				ssvc       sql.NullString
 				trackingID string
 				publisher  string
-				tlp        string
 				state      string
 			)
 			if err := tx.QueryRow(rctx, findSSVC, documentID).Scan(
 				&ssvc,
 				&trackingID,
 				&publisher,
-				&tlp,
 				&state,
 			); err != nil {
 				return err
 			}
 
-			// check if we are allowed to do
-			if tlps := c.tlps(ctx); len(tlps) > 0 && !tlps.Allowed(publisher, models.TLP(tlp)) {
-				forbidden = true
-				return nil
-			}
-
 			// check if it's a real change
 			if ssvc.Valid && ssvc.String == vector {
 				unchanged = true
@@ -146,7 +152,7 @@ func (c *Controller) changeSSVC(ctx *gin.Context) {
 			}
 
 			// Now do the actual SSVC update.
-			if _, err := tx.Exec(rctx, updateSSVC, actor, documentID, vector); err != nil {
+			if _, err := tx.Exec(rctx, updateSSVC, actor, documentID, vector, reason); err != nil {
 				return err
 			}
 
@@ -156,7 +162,7 @@ func (c *Controller) changeSSVC(ctx *gin.Context) {
 		if errors.Is(err, pgx.ErrNoRows) {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "document not found"})
 		} else {
-			slog.Error("database error", "err", err)
+			slog.ErrorContext(ctx, "database error", "err", err)
 			models.SendError(ctx, http.StatusInternalServerError, err)
 		}
 		return
@@ -180,7 +186,6 @@ func (c *Controller) changeSSVC(ctx *gin.Context) {
 //	@Produce		json
 //	@Param			document	path		int	true	"Document ID"
 //	@Success		200			{object}	map[string]string
-//	@Failure		403			{object}	models.Error
 //	@Failure		404			{object}	models.Error
 //	@Failure		500			{object}	models.Error
 //	@Router			/ssvc/documents/{document} [get]
@@ -190,42 +195,24 @@ func (c *Controller) viewSSVC(ctx *gin.Context) {
 		return
 	}
 
-	const findSSVC = `SELECT sh.ssvc, ads.publisher, docs.tlp ` +
-		`FROM documents docs JOIN advisories ads ` +
-		`ON docs.advisories_id = ads.id ` +
+	const findSSVC = `SELECT sh.ssvc ` +
+		`FROM documents docs ` +
 		`LEFT JOIN LATERAL ` +
 		`(SELECT ssvc FROM ssvc_history ` +
 		`WHERE documents_id = docs.id ORDER BY changedate DESC, change_number DESC LIMIT 1) ` +
 		`sh ON true WHERE docs.id = $1`
 
-	var (
-		forbidden bool
-		ssvc      models.SSVCResponse
-	)
+	var ssvc models.SSVCResponse
 
 	if err := c.db.Run(
 		ctx.Request.Context(),
 		func(rctx context.Context, conn *pgxpool.Conn) error {
 
-			var (
-				ssvcdb    sql.NullString
-				publisher string
-				tlp       string
-			)
-			if err := conn.QueryRow(rctx, findSSVC, documentID).Scan(
-				&ssvcdb,
-				&publisher,
-				&tlp,
-			); err != nil {
+			var ssvcdb sql.NullString
+			if err := conn.QueryRow(rctx, findSSVC, documentID).Scan(&ssvcdb); err != nil {
 				return err
 			}
 
-			// check if we are allowed to do
-			if tlps := c.tlps(ctx); len(tlps) > 0 && !tlps.Allowed(publisher, models.TLP(tlp)) {
-				forbidden = true
-				return nil
-			}
-
 			if ssvcdb.Valid {
 				ssvc.SSVC = &ssvcdb.String
 			}
@@ -235,17 +222,12 @@ func (c *Controller) viewSSVC(ctx *gin.Context) {
 		if errors.Is(err, pgx.ErrNoRows) {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "document not found"})
 		} else {
-			slog.Error("database error", "err", err)
+			slog.ErrorContext(ctx, "database error", "err", err)
 			models.SendError(ctx, http.StatusInternalServerError, err)
 		}
 		return
 	}
-	switch {
-	case forbidden:
-		models.SendErrorMessage(ctx, http.StatusForbidden, "access denied")
-	default:
-		ctx.JSON(http.StatusOK, ssvc)
-	}
+	ctx.JSON(http.StatusOK, ssvc)
 }
 
 // viewSSVCHistory is an endpoint that returns the SSVC History of the specified advisory.
@@ -256,7 +238,6 @@ func (c *Controller) viewSSVC(ctx *gin.Context) {
 //	@Success		200			{object}	map[string][]models.SSVCChange
 //	@Param			publisher	path	string	true	"Advisory publisher"
 //	@Param			trackingid	path	string	true	"Advisory tracking ID"
-//	@Failure		403			{object}	models.Error
 //	@Failure		404			{object}	models.Error
 //	@Failure		500			{object}	models.Error
 //	@Router			/ssvc/history/{publisher}/{trackingid} [get]
@@ -264,19 +245,6 @@ func (c *Controller) viewSSVCHistory(ctx *gin.Context) {
 	publisherNamespace := ctx.Param("publisher")
 	trackingID := ctx.Param("trackingid")
 
-	// fetch access data
-	const findPublisherTLP = `WITH advisory_docs AS ( ` +
-		`SELECT docs.id, ads.publisher, docs.tlp ` +
-		`FROM documents docs ` +
-		`JOIN advisories ads ON docs.advisories_id = ads.id ` +
-		`WHERE ads.publisher = $1 ` +
-		`AND ads.tracking_id = $2 ` +
-		`ORDER BY docs.id ` +
-		`) ` +
-		`SELECT publisher, tlp ` +
-		`FROM advisory_docs ` +
-		`LIMIT 1;`
-
 	// fetch entire history if exists
 	const findSSVCHistory = `WITH advisory_docs AS ( ` +
 		`SELECT docs.id, docs.version ` +
@@ -285,30 +253,16 @@ func (c *Controller) viewSSVCHistory(ctx *gin.Context) {
 		`WHERE ads.publisher = $1 ` +
 		`AND ads.tracking_id = $2 ` +
 		`) ` +
-		`SELECT h.ssvc, h.changedate, h.change_number, h.actor, h.documents_id, ad.version ` +
+		`SELECT h.ssvc, h.changedate, h.change_number, h.actor, h.documents_id, ad.version, h.reason ` +
 		`FROM ssvc_history h ` +
 		`JOIN advisory_docs ad ON h.documents_id = ad.id ` +
 		`ORDER BY h.documents_id ASC, h.changedate DESC, h.change_number DESC;`
 
-	var (
-		forbidden   bool
-		ssvcHistory = []models.SSVCHistoryEntry{}
-	)
-	tlps := c.tlps(ctx)
+	ssvcHistory := []models.SSVCHistoryEntry{}
 	if err := c.db.Run(
 		ctx.Request.Context(),
 		func(rctx context.Context, conn *pgxpool.Conn) error {
 
-			var publisher, tlp string
-			err := conn.QueryRow(rctx, findPublisherTLP, publisherNamespace, trackingID).Scan(&publisher, &tlp)
-			if err != nil {
-				return fmt.Errorf("failed to find authorization information for queried document: %w", err)
-			}
-			if len(tlps) > 0 && !tlps.Allowed(publisher, models.TLP(tlp)) {
-				forbidden = true
-				return nil
-			}
-
 			rows, err := conn.Query(rctx, findSSVCHistory, publisherNamespace, trackingID)
 			if err != nil {
 				return fmt.Errorf("scanning for SSVCHistory failed: %w", err)
@@ -325,6 +279,7 @@ func (c *Controller) viewSSVCHistory(ctx *gin.Context) {
 					&entry.Actor,
 					&entry.DocumentsID,
 					&entry.DocumentsVersion,
+					&entry.Reason,
 				)
 				entry.ChangeDate = entry.ChangeDate.UTC()
 				return entry, err
@@ -336,13 +291,11 @@ func (c *Controller) viewSSVCHistory(ctx *gin.Context) {
 			return nil
 		}, 0,
 	); err != nil {
-		slog.Error("database error", "err", err)
+		slog.ErrorContext(ctx, "database error", "err", err)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 	switch {
-	case forbidden:
-		models.SendErrorMessage(ctx, http.StatusForbidden, "access denied")
 	case len(ssvcHistory) == 0:
 		models.SendErrorMessage(ctx, http.StatusNotFound, "no History found")
 	default:
@@ -352,6 +305,77 @@ func (c *Controller) viewSSVCHistory(ctx *gin.Context) {
 	}
 }
 
+// viewDocumentSSVCHistory is an endpoint that returns the SSVC history of the specified document.
+//
+//	@Summary		View the SSVC history of a document.
+//	@Description	Returns all SSVC changes of the specified document with author,
+//	@Description	time and reason, newest first.
+//	@Produce		json
+//	@Param			id	path		int	true	"Document ID"
+//	@Success		200	{object}	map[string][]models.SSVCChange
+//	@Failure		400	{object}	models.Error
+//	@Failure		401	{object}	models.Error
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/documents/{id}/ssvc/history [get]
+func (c *Controller) viewDocumentSSVCHistory(ctx *gin.Context) {
+	documentID, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+
+	const (
+		historySQL = `SELECT h.ssvc, h.changedate, h.change_number, h.actor, ` +
+			`h.documents_id, docs.version, h.reason ` +
+			`FROM ssvc_history h JOIN documents docs ON h.documents_id = docs.id ` +
+			`WHERE h.documents_id = $1 ` +
+			`ORDER BY h.changedate DESC, h.change_number DESC`
+	)
+
+	var (
+		exists  bool
+		history []models.SSVCHistoryEntry
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			var err error
+			if exists, err = documentVisible(rctx, conn, documentID); err != nil || !exists {
+				return err
+			}
+			rows, _ := conn.Query(rctx, historySQL, documentID)
+			history, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.SSVCHistoryEntry, error) {
+				var entry models.SSVCHistoryEntry
+				err := row.Scan(
+					&entry.SSVC,
+					&entry.ChangeDate,
+					&entry.ChangeNumber,
+					&entry.Actor,
+					&entry.DocumentsID,
+					&entry.DocumentsVersion,
+					&entry.Reason,
+				)
+				entry.ChangeDate = entry.ChangeDate.UTC()
+				return entry, err
+			})
+			return err
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if !exists {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "document not found")
+		return
+	}
+	changes := buildSSVCChange(history)
+	if changes == nil {
+		changes = []models.SSVCChange{}
+	}
+	ctx.JSON(http.StatusOK, gin.H{"ssvcChanges": changes})
+}
+
 // buildSSVCChange turns an array of SSVCHistoryEntry's into an Array of SSVCChange's by looking up the last ssvc if it's not the oldest entry.
 func buildSSVCChange(history []models.SSVCHistoryEntry) []models.SSVCChange {
 	var changes []models.SSVCChange
@@ -0,0 +1,515 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/subscriptions"
+)
+
+// userSubscriptionsSQL selects the subscriptions of the user in $1
+// and the subscriptions of the teams of the user.
+const userSubscriptionsSQL = `((qs."user" = $1 AND qs.teams_id IS NULL) ` +
+	`OR qs.teams_id IN (SELECT user_teams($1))) `
+
+// querySubscription is a subscription of a stored query.
+type querySubscription struct {
+	ID        int64     `json:"id"`
+	QueryID   int64     `json:"query_id"`
+	QueryName string    `json:"query_name"`
+	Webhook   *string   `json:"webhook,omitempty"`
+	Created   time.Time `json:"created"`
+	Unseen    int64     `json:"unseen"`
+	// Team is the team the subscription is shared with.
+	Team *int64 `json:"team,omitempty"`
+}
+
+// queryMatch is a document matching a subscribed query.
+type queryMatch struct {
+	ID             int64      `json:"id"`
+	SubscriptionID int64      `json:"subscription_id"`
+	Team           *int64     `json:"team,omitempty"`
+	QueryName      string     `json:"query_name"`
+	Matched        time.Time  `json:"matched"`
+	Seen           bool       `json:"seen"`
+	Delivered      *time.Time `json:"delivered,omitempty"`
+	DocumentID     int64      `json:"document_id"`
+	Publisher      string     `json:"publisher"`
+	TrackingID     string     `json:"tracking_id"`
+	Version        string     `json:"version"`
+	Title          *string    `json:"title,omitempty"`
+	TLP            *string    `json:"tlp,omitempty"`
+	Critical       *float64   `json:"critical,omitempty"`
+}
+
+// parseWebhook checks if a webhook is an absolute http(s) URL
+// to one of the configured webhook domains.
+func (c *Controller) parseWebhook(s string) (string, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("webhook %q is not an absolute http(s) URL", s)
+	}
+	if !c.cfg.Subscriptions.WebhookAllowed(u.Hostname()) {
+		return "", fmt.Errorf("webhook host %q is not allowed", u.Hostname())
+	}
+	return u.String(), nil
+}
+
+// subscriptionTeam parses the optional team of a subscription and
+// checks if the current user is allowed to manage its subscriptions.
+func (c *Controller) subscriptionTeam(ctx *gin.Context, value string) (*int64, bool) {
+	if value == "" {
+		return nil, true
+	}
+	teamID, ok := parse(ctx, toInt64, value)
+	if !ok {
+		return nil, false
+	}
+	if c.hasAnyRole(ctx, models.Admin) {
+		return &teamID, true
+	}
+	var lead bool
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			var err error
+			lead, err = leadsTeam(rctx, conn, ctx.GetString("uid"), teamID)
+			return err
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	if !lead {
+		models.SendErrorMessage(ctx, http.StatusForbidden,
+			"only leads are allowed to manage the subscriptions of a team")
+		return nil, false
+	}
+	return &teamID, true
+}
+
+// subscribeStoredQuery is an endpoint that subscribes the current user to a stored query.
+//
+//	@Summary		Subscribes to a stored query.
+//	@Description	Notifies the current user about newly imported documents matching the stored query.
+//	@Description	If a webhook is given the matches are posted to it.
+//	@Description	Webhooks are only allowed to the configured webhook domains.
+//	@Description	Team leads are able to subscribe for a team. The matches of a team
+//	@Description	subscription are shared by the members of the team and its sub-teams.
+//	@Param			query	path		int		true	"Query ID"
+//	@Param			webhook	formData	string	false	"Webhook URL"
+//	@Param			team	formData	int		false	"Team ID"
+//	@Produce		json
+//	@Success		201	{object}	models.ID
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/queries/{query}/subscription [post]
+func (c *Controller) subscribeStoredQuery(ctx *gin.Context) {
+	queryID, ok := parse(ctx, toInt64, ctx.Param("query"))
+	if !ok {
+		return
+	}
+	var webhook *string
+	if s := ctx.PostForm("webhook"); s != "" {
+		w, ok := parse(ctx, c.parseWebhook, s)
+		if !ok {
+			return
+		}
+		webhook = &w
+	}
+	teamID, ok := c.subscriptionTeam(ctx, ctx.PostForm("team"))
+	if !ok {
+		return
+	}
+	const (
+		insertSQL = `INSERT INTO query_subscriptions ` +
+			`(stored_queries_id, "user", tlps, webhook, teams_id, last_import) ` +
+			`VALUES ($1, $2, $3, $4, $5, (SELECT coalesce(max(seq), 0) FROM document_imports)) `
+		userSQL = insertSQL +
+			`ON CONFLICT (stored_queries_id, "user") WHERE teams_id IS NULL DO UPDATE SET ` +
+			`tlps = EXCLUDED.tlps, webhook = EXCLUDED.webhook ` +
+			`RETURNING id`
+		teamSQL = insertSQL +
+			`ON CONFLICT (stored_queries_id, teams_id) WHERE teams_id IS NOT NULL DO UPDATE SET ` +
+			`"user" = EXCLUDED."user", tlps = EXCLUDED.tlps, webhook = EXCLUDED.webhook ` +
+			`RETURNING id`
+		// Imports committing right now are recorded for the new subscription.
+		lockSQL = `LOCK TABLE document_imports IN SHARE MODE`
+	)
+	kindSQL := `SELECT kind::text FROM stored_queries ` +
+		`WHERE id = $1 AND ` + visibleQuerySQL(2, 3)
+	var (
+		user     = ctx.GetString("uid")
+		tlps     = c.tlps(ctx)
+		kind     string
+		id       int64
+		notFound = errors.New("not found")
+		badKind  = errors.New("bad kind")
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			switch err := conn.QueryRow(rctx, kindSQL, queryID, user, c.realmRoles(ctx)).Scan(&kind); {
+			case errors.Is(err, pgx.ErrNoRows):
+				return notFound
+			case err != nil:
+				return err
+			}
+			if kind == "events" {
+				return badKind
+			}
+			upsertSQL := userSQL
+			if teamID != nil {
+				upsertSQL = teamSQL
+			}
+			tx, err := conn.Begin(rctx)
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(rctx)
+			if _, err := tx.Exec(rctx, lockSQL); err != nil {
+				return err
+			}
+			err = tx.QueryRow(rctx, upsertSQL, queryID, user, tlps, webhook, teamID).Scan(&id)
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				return notFound
+			}
+			if err != nil {
+				return err
+			}
+			return tx.Commit(rctx)
+		}, 0,
+	); err != nil {
+		switch {
+		case errors.Is(err, notFound):
+			models.SendErrorMessage(ctx, http.StatusNotFound, "query or team not found")
+		case errors.Is(err, badKind):
+			models.SendErrorMessage(ctx, http.StatusBadRequest,
+				"queries of kind events cannot be subscribed")
+		default:
+			slog.ErrorContext(ctx, "database error", "err", err)
+			models.SendError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, models.ID{ID: id})
+}
+
+// unsubscribeStoredQuery is an endpoint that removes the subscription
+// of the current user to a stored query.
+//
+//	@Summary		Unsubscribes from a stored query.
+//	@Description	Removes the subscription of the current user to the stored query and its matches.
+//	@Description	With a team the subscription of the team is removed.
+//	@Param			query	path	int	true	"Query ID"
+//	@Param			team	query	int	false	"Team ID"
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/queries/{query}/subscription [delete]
+func (c *Controller) unsubscribeStoredQuery(ctx *gin.Context) {
+	queryID, ok := parse(ctx, toInt64, ctx.Param("query"))
+	if !ok {
+		return
+	}
+	teamID, ok := c.subscriptionTeam(ctx, ctx.Query("team"))
+	if !ok {
+		return
+	}
+	const (
+		userSQL = `DELETE FROM query_subscriptions ` +
+			`WHERE stored_queries_id = $1 AND "user" = $2 AND teams_id IS NULL`
+		teamSQL = `DELETE FROM query_subscriptions ` +
+			`WHERE stored_queries_id = $1 AND teams_id = $2`
+	)
+	var deleted bool
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			var (
+				tag pgconn.CommandTag
+				err error
+			)
+			if teamID != nil {
+				tag, err = conn.Exec(rctx, teamSQL, queryID, *teamID)
+			} else {
+				tag, err = conn.Exec(rctx, userSQL, queryID, ctx.GetString("uid"))
+			}
+			deleted = tag.RowsAffected() > 0
+			return err
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if !deleted {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "subscription not found")
+		return
+	}
+	models.SendSuccess(ctx, http.StatusOK, "unsubscribed")
+}
+
+// testSubscriptionWebhook is an endpoint that tests the webhook
+// of the subscription of the current user to a stored query.
+//
+//	@Summary		Tests the webhook of a subscription.
+//	@Description	Posts a notification with a synthetic match to the webhook of the
+//	@Description	subscription of the current user to the stored query and returns
+//	@Description	the round-trip result. The notification is marked as a test.
+//	@Description	Only admins get the response, the error and the duration.
+//	@Param			query	path	int	true	"Query ID"
+//	@Produce		json
+//	@Success		200	{object}	models.DeliveryResult
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/queries/{query}/subscription/test [post]
+func (c *Controller) testSubscriptionWebhook(ctx *gin.Context) {
+	queryID, ok := parse(ctx, toInt64, ctx.Param("query"))
+	if !ok {
+		return
+	}
+	const selectSQL = `SELECT qs.id, qs.webhook, sq.id, sq.name ` +
+		`FROM query_subscriptions qs ` +
+		`JOIN stored_queries sq ON qs.stored_queries_id = sq.id ` +
+		`WHERE sq.id = $1 AND qs."user" = $2 AND qs.teams_id IS NULL`
+	var (
+		notification subscriptions.Notification
+		webhook      *string
+		found        bool
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			switch err := conn.QueryRow(rctx, selectSQL, queryID, ctx.GetString("uid")).Scan(
+				&notification.Subscription, &webhook,
+				&notification.QueryID, &notification.QueryName,
+			); {
+			case errors.Is(err, pgx.ErrNoRows):
+				return nil
+			case err != nil:
+				return err
+			}
+			found = true
+			return nil
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	switch {
+	case !found:
+		models.SendErrorMessage(ctx, http.StatusNotFound, "subscription not found")
+		return
+	case webhook == nil:
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "subscription has no webhook")
+		return
+	}
+	result, err := c.nf.TestWebhook(ctx.Request.Context(), *webhook, notification)
+	if err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	// Only the admins see the details of the response.
+	if !c.hasAnyRole(ctx, models.Admin) {
+		result = result.Redacted()
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// listSubscriptions is an endpoint that returns the subscriptions of the current user.
+//
+//	@Summary		Returns the subscriptions.
+//	@Description	Returns the stored queries the current user is subscribed to
+//	@Description	including the subscriptions of the teams of the user.
+//	@Produce		json
+//	@Success		200	{array}		web.querySubscription
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/subscriptions [get]
+func (c *Controller) listSubscriptions(ctx *gin.Context) {
+	const selectSQL = `SELECT qs.id, sq.id, sq.name, qs.webhook, qs.created, ` +
+		`(SELECT count(*) FROM query_matches qm ` +
+		`JOIN documents d ON qm.documents_id = d.id ` +
+		`WHERE qm.query_subscriptions_id = qs.id AND NOT qm.seen), ` +
+		`qs.teams_id ` +
+		`FROM query_subscriptions qs ` +
+		`JOIN stored_queries sq ON qs.stored_queries_id = sq.id ` +
+		`WHERE ` + userSubscriptionsSQL +
+		`ORDER BY sq.name, qs.id`
+	var list []querySubscription
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, selectSQL, ctx.GetString("uid"))
+			var err error
+			list, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (querySubscription, error) {
+				var s querySubscription
+				err := row.Scan(
+					&s.ID, &s.QueryID, &s.QueryName, &s.Webhook, &s.Created, &s.Unseen,
+					&s.Team)
+				s.Created = s.Created.UTC()
+				return s, err
+			})
+			return err
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if list == nil {
+		list = []querySubscription{}
+	}
+	ctx.JSON(http.StatusOK, list)
+}
+
+// viewSubscriptionMatches is an endpoint that returns the documents
+// matching the subscribed queries of the current user.
+//
+//	@Summary		Returns the matches of the subscriptions.
+//	@Description	Returns the documents matching the subscribed queries, newest first.
+//	@Description	Matches of team subscriptions the current user is not allowed
+//	@Description	to see by TLP are left out.
+//	@Param			unseen	query	bool	false	"Only unseen matches"
+//	@Param			limit	query	int		false	"Maximum number of matches"
+//	@Param			offset	query	int		false	"Offset"
+//	@Produce		json
+//	@Success		200	{array}		web.queryMatch
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/subscriptions/matches [get]
+func (c *Controller) viewSubscriptionMatches(ctx *gin.Context) {
+	unseen, ok := parse(ctx, strconv.ParseBool, ctx.DefaultQuery("unseen", "false"))
+	if !ok {
+		return
+	}
+	limit, ok := parse(ctx, toInt64, ctx.DefaultQuery("limit", "100"))
+	if !ok {
+		return
+	}
+	offset, ok := parse(ctx, toInt64, ctx.DefaultQuery("offset", "0"))
+	if !ok {
+		return
+	}
+	// The matches of team subscriptions are found with the TLPs of the lead.
+	// The join with the documents hides the ones the user is not allowed to see.
+	const selectSQL = `SELECT qm.id, qs.id, qs.teams_id, sq.name, ` +
+		`qm.matched, qm.seen, qm.delivered, ` +
+		`d.id, a.publisher, a.tracking_id, d.version, d.title, d.tlp, d.critical ` +
+		`FROM query_matches qm ` +
+		`JOIN query_subscriptions qs ON qm.query_subscriptions_id = qs.id ` +
+		`JOIN stored_queries sq ON qs.stored_queries_id = sq.id ` +
+		`JOIN documents d ON qm.documents_id = d.id ` +
+		`JOIN advisories a ON d.advisories_id = a.id ` +
+		`WHERE ` + userSubscriptionsSQL + `AND (NOT $2 OR NOT qm.seen) ` +
+		`ORDER BY qm.matched DESC, qm.id DESC ` +
+		`LIMIT $3 OFFSET $4`
+	var matches []queryMatch
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, selectSQL,
+				ctx.GetString("uid"), unseen, max(limit, 0), max(offset, 0))
+			var err error
+			matches, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (queryMatch, error) {
+				var m queryMatch
+				err := row.Scan(
+					&m.ID, &m.SubscriptionID, &m.Team,
+					&m.QueryName, &m.Matched, &m.Seen, &m.Delivered,
+					&m.DocumentID, &m.Publisher, &m.TrackingID, &m.Version,
+					&m.Title, &m.TLP, &m.Critical)
+				m.Matched = m.Matched.UTC()
+				return m, err
+			})
+			return err
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if matches == nil {
+		matches = []queryMatch{}
+	}
+	ctx.JSON(http.StatusOK, matches)
+}
+
+// markSubscriptionMatchesSeen is an endpoint that marks matches of the
+// subscriptions of the current user as seen.
+//
+//	@Summary		Marks matches as seen.
+//	@Description	Marks the given matches or all matches of the current user as seen.
+//	@Description	Matches of team subscriptions are marked as seen for the whole team.
+//	@Param			ids	formData	[]int	false	"Match IDs"
+//	@Param			all	formData	bool	false	"Mark all matches"
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/subscriptions/matches/seen [post]
+func (c *Controller) markSubscriptionMatchesSeen(ctx *gin.Context) {
+	all, ok := parse(ctx, strconv.ParseBool, ctx.DefaultPostForm("all", "false"))
+	if !ok {
+		return
+	}
+	ids, ok := parseIDs(ctx, "ids")
+	if !ok {
+		return
+	}
+	if !all && len(ids) == 0 {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "neither ids nor all given")
+		return
+	}
+	const updateSQL = `UPDATE query_matches qm SET seen = true ` +
+		`FROM query_subscriptions qs ` +
+		`WHERE qm.query_subscriptions_id = qs.id AND ` + userSubscriptionsSQL +
+		`AND NOT qm.seen AND ($2 OR qm.id = ANY($3))`
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			_, err := conn.Exec(rctx, updateSQL, ctx.GetString("uid"), all, ids)
+			return err
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	models.SendSuccess(ctx, http.StatusOK, "marked as seen")
+}
@@ -0,0 +1,226 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/database"
+	"github.com/ISDuBA/ISDuBA/pkg/logbuffer"
+	"github.com/ISDuBA/ISDuBA/pkg/sources"
+	"github.com/ISDuBA/ISDuBA/pkg/version"
+)
+
+// bundleError is stored in place of a part of the
+// support bundle which could not be collected.
+type bundleError struct {
+	Error string `json:"error"`
+}
+
+// appliedMigration is a migration applied to the database.
+type appliedMigration struct {
+	Version     int64     `json:"version"`
+	Description string    `json:"description"`
+	Time        time.Time `json:"time"`
+}
+
+// migrationState compares the migrations of the database
+// with the migrations known to the application.
+type migrationState struct {
+	Application int64              `json:"application"`
+	Database    int64              `json:"database"`
+	Applied     []appliedMigration `json:"applied"`
+}
+
+// migrationState loads the recently applied migrations.
+func (c *Controller) migrationState(ctx context.Context) (*migrationState, error) {
+	latest, err := database.LatestMigration()
+	if err != nil {
+		return nil, err
+	}
+	state := migrationState{Application: latest, Database: -1}
+	const selectSQL = `SELECT version, description, time FROM versions ` +
+		`ORDER BY version DESC LIMIT 10`
+	if err := c.db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+		rows, _ := conn.Query(rctx, selectSQL)
+		var err error
+		state.Applied, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (appliedMigration, error) {
+			var am appliedMigration
+			err := row.Scan(&am.Version, &am.Description, &am.Time)
+			am.Time = am.Time.UTC()
+			return am, err
+		})
+		return err
+	}, 0); err != nil {
+		return nil, err
+	}
+	if len(state.Applied) > 0 {
+		state.Database = state.Applied[0].Version
+	}
+	return &state, nil
+}
+
+// tableSizes estimates the number of rows of the central tables.
+// The estimates of the statistics are used as counting is expensive.
+func (c *Controller) tableSizes(ctx context.Context) (map[string]int64, error) {
+	const selectSQL = `SELECT relname, greatest(reltuples, 0)::bigint FROM pg_class ` +
+		`WHERE relkind = 'r' AND relname = ANY($1)`
+	tables := []string{
+		"advisories", "documents", "comments", "events_log",
+		"sources", "feeds", "downloads",
+	}
+	sizes := make(map[string]int64, len(tables))
+	if err := c.db.Run(ctx, func(rctx context.Context, conn *pgxpool.Conn) error {
+		var (
+			name string
+			size int64
+		)
+		rows, _ := conn.Query(rctx, selectSQL, tables)
+		_, err := pgx.ForEachRow(rows, []any{&name, &size}, func() error {
+			sizes[name] = size
+			return nil
+		})
+		return err
+	}, 0); err != nil {
+		return nil, err
+	}
+	return sizes, nil
+}
+
+// supportBundle is an endpoint that collects the information needed
+// for troubleshooting into a single archive.
+//
+//	@Summary		Returns a support bundle.
+//	@Description	Returns a ZIP archive with the version, the migration state,
+//	@Description	the configuration with the secrets redacted, the state of the
+//	@Description	background tasks, the recent warnings and errors of the log
+//	@Description	and key metrics to be attached to bug reports.
+//	@Produce		application/zip
+//	@Success		200	{string}	string
+//	@Failure		401
+//	@Router			/admin/support-bundle [get]
+func (c *Controller) supportBundle(ctx *gin.Context) {
+	type versionInfo struct {
+		Version   string    `json:"version"`
+		GoVersion string    `json:"go_version"`
+		OS        string    `json:"os"`
+		Arch      string    `json:"arch"`
+		Generated time.Time `json:"generated"`
+	}
+	type runtimeStats struct {
+		Goroutines int    `json:"goroutines"`
+		HeapAlloc  uint64 `json:"heap_alloc"`
+		HeapSys    uint64 `json:"heap_sys"`
+		NumGC      uint32 `json:"num_gc"`
+	}
+	type metrics struct {
+		Pipeline *sources.PipelineStats `json:"pipeline"`
+		Caches   cacheStats             `json:"caches"`
+		Runtime  runtimeStats           `json:"runtime"`
+		Tables   map[string]int64       `json:"tables,omitempty"`
+		Error    string                 `json:"error,omitempty"`
+	}
+
+	rctx := ctx.Request.Context()
+	now := time.Now().UTC()
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	m := metrics{
+		Pipeline: c.sm.PipelineStats(),
+		Caches: cacheStats{
+			CacheStats:  c.sm.CacheStats(),
+			Aggregators: c.am.Cache.Len(),
+			Search:      c.qc.Len(),
+		},
+		Runtime: runtimeStats{
+			Goroutines: runtime.NumGoroutine(),
+			HeapAlloc:  ms.HeapAlloc,
+			HeapSys:    ms.HeapSys,
+			NumGC:      ms.NumGC,
+		},
+	}
+	if sizes, err := c.tableSizes(rctx); err != nil {
+		m.Error = err.Error()
+	} else {
+		m.Tables = sizes
+	}
+
+	var migrations any
+	if state, err := c.migrationState(rctx); err != nil {
+		migrations = bundleError{Error: err.Error()}
+	} else {
+		migrations = state
+	}
+
+	asJSON := func(v any) func(io.Writer) error {
+		return func(w io.Writer) error {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(v)
+		}
+	}
+	parts := []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{"version.json", asJSON(versionInfo{
+			Version:   version.SemVersion,
+			GoVersion: runtime.Version(),
+			OS:        runtime.GOOS,
+			Arch:      runtime.GOARCH,
+			Generated: now,
+		})},
+		{"migrations.json", asJSON(migrations)},
+		{"config.toml", func(w io.Writer) error {
+			return toml.NewEncoder(w).Encode(c.cfg.Redacted())
+		}},
+		{"database.json", asJSON(c.db.Status())},
+		{"schedulers.json", asJSON(c.st.Status())},
+		{"logs.json", asJSON(logbuffer.Recent.Entries())},
+		{"metrics.json", asJSON(m)},
+	}
+
+	ctx.Header("Content-Type", "application/zip")
+	ctx.Header("Content-Disposition", fmt.Sprintf(
+		"attachment; filename=\"isduba-support-%s.zip\"", now.Format("20060102-150405")))
+	ctx.Status(http.StatusOK)
+
+	zw := zip.NewWriter(ctx.Writer)
+	for _, part := range parts {
+		w, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     part.name,
+			Method:   zip.Deflate,
+			Modified: now,
+		})
+		if err == nil {
+			err = part.write(w)
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "writing support bundle failed", "part", part.name, "err", err)
+			return
+		}
+	}
+	if err := zw.Close(); err != nil {
+		slog.ErrorContext(ctx, "writing support bundle failed", "err", err)
+	}
+}
@@ -0,0 +1,277 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/sweeper"
+)
+
+type validationSweep struct {
+	ID          int64      `json:"id"`
+	Creator     *string    `json:"creator,omitempty"`
+	Started     time.Time  `json:"started"`
+	Finished    *time.Time `json:"finished,omitempty"`
+	Status      string     `json:"status"`
+	Publisher   *string    `json:"publisher,omitempty"`
+	Since       *time.Time `json:"since,omitempty"`
+	Until       *time.Time `json:"until,omitempty"`
+	Remote      bool       `json:"remote"`
+	Checked     int64      `json:"checked"`
+	Regressions int64      `json:"regressions"`
+	Fixed       int64      `json:"fixed"`
+	Error       *string    `json:"error,omitempty"`
+}
+
+type validationResult struct {
+	DocumentID           int64    `json:"document_id"`
+	SchemaFailed         bool     `json:"schema_failed"`
+	RemoteFailed         *bool    `json:"remote_failed,omitempty"`
+	PreviousSchemaFailed bool     `json:"previous_schema_failed"`
+	PreviousRemoteFailed *bool    `json:"previous_remote_failed,omitempty"`
+	Regression           bool     `json:"regression"`
+	Messages             []string `json:"messages,omitempty"`
+}
+
+type validationSweepResults struct {
+	validationSweep
+	Results []validationResult `json:"results"`
+}
+
+const validationSweepColumns = `id, creator, started, finished, status, ` +
+	`publisher, since, until, remote, checked, regressions, fixed, error`
+
+func scanValidationSweep(row pgx.Row, vs *validationSweep) error {
+	if err := row.Scan(
+		&vs.ID, &vs.Creator, &vs.Started, &vs.Finished, &vs.Status,
+		&vs.Publisher, &vs.Since, &vs.Until, &vs.Remote,
+		&vs.Checked, &vs.Regressions, &vs.Fixed, &vs.Error,
+	); err != nil {
+		return err
+	}
+	vs.Started = vs.Started.UTC()
+	if vs.Finished != nil {
+		*vs.Finished = vs.Finished.UTC()
+	}
+	return nil
+}
+
+// startValidationSweep is an endpoint that starts a re-validation of the stored documents.
+//
+//	@Summary		Starts a validation sweep.
+//	@Description	Re-validates the stored documents in the background against the
+//	@Description	schema and optionally the remote validator. Documents whose verdict
+//	@Description	differs from the one recorded at download time are recorded.
+//	@Description	Only one sweep is run at a time.
+//	@Param			publisher	formData	string	false	"Only advisories of this publisher"
+//	@Param			since		formData	string	false	"Only documents released at or after"
+//	@Param			until		formData	string	false	"Only documents released before"
+//	@Param			remote		formData	bool	false	"Use the remote validator, too"
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Success		201	{object}	models.ID
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		409	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/validation/sweeps [post]
+func (c *Controller) startValidationSweep(ctx *gin.Context) {
+	var filter sweeper.Filter
+	if value, ok := ctx.GetPostForm("publisher"); ok && value != "" {
+		filter.Publisher = &value
+	}
+	optTime := func(name string) (*time.Time, bool) {
+		value, ok := ctx.GetPostForm(name)
+		if !ok || value == "" {
+			return nil, true
+		}
+		t, ok := parse(ctx, parseTime, value)
+		return &t, ok
+	}
+	var ok bool
+	if filter.Since, ok = optTime("since"); !ok {
+		return
+	}
+	if filter.Until, ok = optTime("until"); !ok {
+		return
+	}
+	if value, ok := ctx.GetPostForm("remote"); ok && value != "" {
+		if filter.Remote, ok = parse(ctx, strconv.ParseBool, value); !ok {
+			return
+		}
+	}
+	if filter.Remote && !c.sw.HasRemoteValidator() {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "no remote validator configured")
+		return
+	}
+	id, err := c.sw.Start(ctx.Request.Context(), c.currentUser(ctx), filter)
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusCreated, models.ID{ID: id})
+	case errors.Is(err, sweeper.ErrRunning):
+		models.SendError(ctx, http.StatusConflict, err)
+	default:
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+	}
+}
+
+// viewValidationSweeps is an endpoint that returns the validation sweeps.
+//
+//	@Summary		Returns validation sweeps.
+//	@Description	Returns the validation sweeps, newest first.
+//	@Produce		json
+//	@Success		200	{array}		validationSweep
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/validation/sweeps [get]
+func (c *Controller) viewValidationSweeps(ctx *gin.Context) {
+	const selectSQL = `SELECT ` + validationSweepColumns +
+		` FROM validation_sweeps ORDER BY started DESC`
+	sweeps := []validationSweep{}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, selectSQL)
+			defer rows.Close()
+			for rows.Next() {
+				var vs validationSweep
+				if err := scanValidationSweep(rows, &vs); err != nil {
+					return err
+				}
+				sweeps = append(sweeps, vs)
+			}
+			return rows.Err()
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, sweeps)
+}
+
+// viewValidationSweep is an endpoint that returns a validation sweep and its results.
+//
+//	@Summary		Returns a validation sweep.
+//	@Description	Returns the validation sweep and the documents with changed verdicts.
+//	@Param			id			path	int		true	"Sweep ID"
+//	@Param			regressions	query	bool	false	"Only regressions"
+//	@Param			limit		query	int		false	"Maximum number of results"
+//	@Param			offset		query	int		false	"Offset of the results"
+//	@Produce		json
+//	@Success		200	{object}	validationSweepResults
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/validation/sweeps/{id} [get]
+func (c *Controller) viewValidationSweep(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	regressions, ok := parse(ctx, strconv.ParseBool, ctx.DefaultQuery("regressions", "false"))
+	if !ok {
+		return
+	}
+	limit, ok := parse(ctx, toInt64, ctx.DefaultQuery("limit", "-1"))
+	if !ok {
+		return
+	}
+	offset, ok := parse(ctx, toInt64, ctx.DefaultQuery("offset", "0"))
+	if !ok {
+		return
+	}
+	var limitArg *int64
+	if limit >= 0 {
+		limitArg = &limit
+	}
+	const (
+		sweepSQL = `SELECT ` + validationSweepColumns +
+			` FROM validation_sweeps WHERE id = $1`
+		resultsSQL = `SELECT documents_id, schema_failed, remote_failed, ` +
+			`previous_schema_failed, previous_remote_failed, regression, messages ` +
+			`FROM validation_results ` +
+			`WHERE validation_sweeps_id = $1 AND (NOT $2 OR regression) ` +
+			`ORDER BY documents_id ` +
+			`LIMIT $3 OFFSET $4`
+	)
+	result := validationSweepResults{Results: []validationResult{}}
+	var found bool
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			switch err := scanValidationSweep(conn.QueryRow(rctx, sweepSQL, id), &result.validationSweep); {
+			case errors.Is(err, pgx.ErrNoRows):
+				return nil
+			case err != nil:
+				return err
+			}
+			found = true
+			rows, _ := conn.Query(rctx, resultsSQL, id, regressions, limitArg, offset)
+			defer rows.Close()
+			for rows.Next() {
+				var vr validationResult
+				if err := rows.Scan(
+					&vr.DocumentID, &vr.SchemaFailed, &vr.RemoteFailed,
+					&vr.PreviousSchemaFailed, &vr.PreviousRemoteFailed,
+					&vr.Regression, &vr.Messages,
+				); err != nil {
+					return err
+				}
+				result.Results = append(result.Results, vr)
+			}
+			return rows.Err()
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if !found {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "validation sweep not found")
+		return
+	}
+	ctx.JSON(http.StatusOK, &result)
+}
+
+// cancelValidationSweep is an endpoint that cancels a running validation sweep.
+//
+//	@Summary		Cancels a validation sweep.
+//	@Description	Cancels the running validation sweep. Already recorded results are kept.
+//	@Param			id	path	int	true	"Sweep ID"
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Router			/validation/sweeps/{id} [delete]
+func (c *Controller) cancelValidationSweep(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	if !c.sw.Cancel(id) {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "no such running validation sweep")
+		return
+	}
+	models.SendSuccess(ctx, http.StatusOK, "canceled")
+}
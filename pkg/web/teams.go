@@ -0,0 +1,300 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/ginkeycloak"
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+type teamDefaults struct {
+	Group   string  `json:"group"`
+	Landing *string `json:"landing,omitempty"`
+	Queries []int64 `json:"queries"`
+}
+
+type landingPage struct {
+	Landing *string  `json:"landing,omitempty"`
+	Groups  []string `json:"groups"`
+}
+
+// normalizeGroup removes the leading slash Keycloak adds to full group paths.
+func normalizeGroup(group string) string {
+	return strings.TrimPrefix(strings.TrimSpace(group), "/")
+}
+
+// groups returns the Keycloak groups of the current user.
+func (c *Controller) groups(ctx *gin.Context) []string {
+	groups := []string{}
+	token, ok := ctx.Get("token")
+	if !ok {
+		return groups
+	}
+	kct, ok := token.(*ginkeycloak.KeycloakToken)
+	if !ok || kct == nil {
+		return groups
+	}
+	for _, group := range kct.Groups {
+		if group = normalizeGroup(group); group != "" {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// provisionUser copies the stored queries of the teams of the
+// current user to the user if the user is seen the first time.
+func (c *Controller) provisionUser(ctx *gin.Context, rctx context.Context, conn *pgxpool.Conn) error {
+	const (
+		markSQL = `INSERT INTO provisioned_users ("user") VALUES ($1) ` +
+			`ON CONFLICT DO NOTHING`
+		copySQL = `INSERT INTO stored_queries (` +
+			`kind, definer, global, name, description, query, ` +
+			`columns, orders, dashboard, default_query, role) ` +
+			`SELECT kind, $1, false, name, description, query, ` +
+			`columns, orders, dashboard, false, role ` +
+			`FROM stored_queries JOIN team_queries ` +
+			`ON team_queries.stored_queries_id = stored_queries.id ` +
+			`WHERE team_queries.group_name = ANY($2) ` +
+			`ORDER BY team_queries.group_name, team_queries.position ` +
+			`ON CONFLICT (definer, name) DO NOTHING`
+	)
+	user := ctx.GetString("uid")
+	if user == "" {
+		return nil
+	}
+	tx, err := conn.BeginTx(rctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(rctx)
+	tag, err := tx.Exec(rctx, markSQL, user)
+	if err != nil {
+		return err
+	}
+	// Already provisioned.
+	if tag.RowsAffected() == 0 {
+		return nil
+	}
+	if groups := c.groups(ctx); len(groups) > 0 {
+		if tag, err = tx.Exec(rctx, copySQL, user, groups); err != nil {
+			return fmt.Errorf("copying team queries failed: %w", err)
+		}
+		slog.InfoContext(ctx, "provisioned team defaults",
+			"user", user, "groups", groups, "queries", tag.RowsAffected())
+	}
+	return tx.Commit(rctx)
+}
+
+// viewLandingPage is an endpoint that returns the landing page of the current user.
+//
+//	@Summary		Returns the landing page.
+//	@Description	Returns the landing page configured for the teams of the current user.
+//	@Description	The team memberships of the user are updated from the Keycloak groups.
+//	@Description	On the first call of a user the default stored queries of the
+//	@Description	teams are copied to the user.
+//	@Produce		json
+//	@Success		200	{object}	landingPage
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/landing [get]
+func (c *Controller) viewLandingPage(ctx *gin.Context) {
+	const landingSQL = `SELECT landing FROM team_defaults ` +
+		`WHERE group_name = ANY($1) AND landing IS NOT NULL ` +
+		`ORDER BY array_position($1, group_name) ` +
+		`LIMIT 1`
+	page := landingPage{Groups: c.groups(ctx)}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			if err := c.syncTeamMembers(ctx, rctx, conn); err != nil {
+				return err
+			}
+			if err := c.provisionUser(ctx, rctx, conn); err != nil {
+				return err
+			}
+			switch err := conn.QueryRow(rctx, landingSQL, page.Groups).Scan(&page.Landing); {
+			case errors.Is(err, pgx.ErrNoRows):
+				return nil
+			default:
+				return err
+			}
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, &page)
+}
+
+// viewTeamDefaults is an endpoint that returns the defaults of all teams.
+//
+//	@Summary		Returns the team defaults.
+//	@Description	Returns the landing pages and default stored queries per Keycloak group.
+//	@Produce		json
+//	@Success		200	{array}		teamDefaults
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/teams [get]
+func (c *Controller) viewTeamDefaults(ctx *gin.Context) {
+	const selectSQL = `SELECT team_defaults.group_name, landing, ` +
+		`coalesce(array_agg(stored_queries_id ORDER BY position) ` +
+		`FILTER (WHERE stored_queries_id IS NOT NULL), '{}') ` +
+		`FROM team_defaults LEFT JOIN team_queries ` +
+		`ON team_defaults.group_name = team_queries.group_name ` +
+		`GROUP BY team_defaults.group_name, landing ` +
+		`ORDER BY team_defaults.group_name`
+	teams := []teamDefaults{}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, selectSQL)
+			defer rows.Close()
+			for rows.Next() {
+				var td teamDefaults
+				if err := rows.Scan(&td.Group, &td.Landing, &td.Queries); err != nil {
+					return err
+				}
+				teams = append(teams, td)
+			}
+			return rows.Err()
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, teams)
+}
+
+// updateTeamDefaults is an endpoint that sets the defaults of a team.
+//
+//	@Summary		Sets the team defaults.
+//	@Description	Sets the landing page and the default stored queries of a Keycloak group.
+//	@Description	The stored queries are copied to new members on their first login.
+//	@Param			group	path		string	true	"Keycloak group, may be a full path"
+//	@Param			landing	formData	string	false	"Landing page"
+//	@Param			queries	formData	string	false	"Space separated list of stored query IDs"
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/teams/{group} [put]
+func (c *Controller) updateTeamDefaults(ctx *gin.Context) {
+	group := normalizeGroup(ctx.Param("group"))
+	if group == "" {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "missing group")
+		return
+	}
+	var landing *string
+	if value := strings.TrimSpace(ctx.PostForm("landing")); value != "" {
+		landing = &value
+	}
+	var queries []int64
+	for _, field := range strings.Fields(ctx.PostForm("queries")) {
+		id, ok := parse(ctx, toInt64, field)
+		if !ok {
+			return
+		}
+		queries = append(queries, id)
+	}
+	const (
+		upsertSQL = `INSERT INTO team_defaults (group_name, landing) VALUES ($1, $2) ` +
+			`ON CONFLICT (group_name) DO UPDATE SET landing = $2`
+		deleteSQL = `DELETE FROM team_queries WHERE group_name = $1`
+		insertSQL = `INSERT INTO team_queries (group_name, stored_queries_id, position) ` +
+			`VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`
+	)
+	var unknown bool
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tx, err := conn.BeginTx(rctx, pgx.TxOptions{})
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(rctx)
+			if _, err := tx.Exec(rctx, upsertSQL, group, landing); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(rctx, deleteSQL, group); err != nil {
+				return err
+			}
+			for i, id := range queries {
+				if _, err := tx.Exec(rctx, insertSQL, group, id, i); err != nil {
+					var pgErr *pgconn.PgError
+					if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+						unknown = true
+						return nil
+					}
+					return err
+				}
+			}
+			return tx.Commit(rctx)
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if unknown {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "unknown stored query")
+		return
+	}
+	models.SendSuccess(ctx, http.StatusOK, "updated")
+}
+
+// deleteTeamDefaults is an endpoint that removes the defaults of a team.
+//
+//	@Summary		Removes the team defaults.
+//	@Description	Removes the landing page and the default stored queries of a Keycloak group.
+//	@Description	Already copied stored queries are kept.
+//	@Param			group	path	string	true	"Keycloak group"
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/teams/{group} [delete]
+func (c *Controller) deleteTeamDefaults(ctx *gin.Context) {
+	const deleteSQL = `DELETE FROM team_defaults WHERE group_name = $1`
+	var deleted bool
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tag, err := conn.Exec(rctx, deleteSQL, normalizeGroup(ctx.Param("group")))
+			deleted = tag.RowsAffected() > 0
+			return err
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if !deleted {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "not found")
+		return
+	}
+	models.SendSuccess(ctx, http.StatusOK, "deleted")
+}
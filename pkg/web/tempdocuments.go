@@ -15,9 +15,12 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"path"
 	"strings"
+	"time"
 
 	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/sources"
 	"github.com/ISDuBA/ISDuBA/pkg/tempstore"
 	"github.com/gin-gonic/gin"
 	"github.com/gocsaf/csaf/v3/csaf"
@@ -27,7 +30,9 @@ import (
 //
 //	@Summary		Uploads a temporary document.
 //	@Description	Uploads a temporary document, that can be used to create diff views.
+//	@Description	The document is kept for ttl after its last access.
 //	@Param			file	formData	file	true	"Temporary document"
+//	@Param			ttl		formData	string	false	"Storage duration, e.g. 2h"
 //	@Accept			multipart/form-data
 //	@Produce		json
 //	@Success		200	{object}	models.ID
@@ -35,6 +40,17 @@ import (
 //	@Failure		401
 //	@Router			/tempdocuments [post]
 func (c *Controller) importTempDocument(ctx *gin.Context) {
+	ttl := c.cfg.TempStore.StorageDuration
+	if value, ok := ctx.GetPostForm("ttl"); ok && value != "" {
+		if ttl, ok = parse(ctx, time.ParseDuration, value); !ok {
+			return
+		}
+	}
+	if ttl <= 0 || ttl > c.cfg.TempStore.MaxStorageDuration {
+		models.SendErrorMessage(ctx, http.StatusBadRequest,
+			fmt.Sprintf("ttl has to be positive and at most %s", c.cfg.TempStore.MaxStorageDuration))
+		return
+	}
 	file, err := ctx.FormFile("file")
 	if err != nil {
 		models.SendError(ctx, http.StatusBadRequest, err)
@@ -50,21 +66,8 @@ func (c *Controller) importTempDocument(ctx *gin.Context) {
 	defer limited.Close()
 
 	user := ctx.GetString("uid")
-	id, err := c.ts.Store(user, file.Filename, func(w io.Writer) error {
-		// Check if the uploaded document is a valid CSAF document.
-		var document any
-		r := io.TeeReader(limited, w)
-		if err := json.NewDecoder(r).Decode(&document); err != nil {
-			return fmt.Errorf("decoding JSON failed: %w", err)
-		}
-		msgs, err := csaf.ValidateCSAF(document)
-		if err != nil {
-			return fmt.Errorf("schema validation failed: %w", err)
-		}
-		if len(msgs) > 0 {
-			return errors.New("schema validation failed: " + strings.Join(msgs, ", "))
-		}
-		return nil
+	id, err := c.ts.Store(ctx.Request.Context(), user, file.Filename, ttl, func(w io.Writer) error {
+		return storeCSAF(limited, w)
 	})
 	if err != nil {
 		models.SendError(ctx, http.StatusBadRequest, err)
@@ -73,27 +76,99 @@ func (c *Controller) importTempDocument(ctx *gin.Context) {
 	ctx.JSON(http.StatusCreated, models.ID{ID: id})
 }
 
+// storeCSAF copies the document from r to w and checks
+// if it is a valid CSAF document.
+func storeCSAF(r io.Reader, w io.Writer) error {
+	var document any
+	if err := json.NewDecoder(io.TeeReader(r, w)).Decode(&document); err != nil {
+		return fmt.Errorf("decoding JSON failed: %w", err)
+	}
+	msgs, err := csaf.ValidateCSAF(document)
+	if err != nil {
+		return fmt.Errorf("schema validation failed: %w", err)
+	}
+	if len(msgs) > 0 {
+		return errors.New("schema validation failed: " + strings.Join(msgs, ", "))
+	}
+	return nil
+}
+
+// previewFeedDocument is an endpoint that stages a not yet imported
+// document of a feed into the temporary store.
+//
+//	@Summary		Stages a feed document as temporary document.
+//	@Description	Downloads a document of a feed without importing it and
+//	@Description	stores it as a temporary document so it can be previewed.
+//	@Description	The staging source manager can view and delete it under /tempdocuments.
+//	@Param			id	path		int		true	"Feed ID"
+//	@Param			url	formData	string	true	"URL of the document"
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Success		201	{object}	models.ID
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		502	{object}	models.Error
+//	@Router			/sources/feeds/{id}/preview [post]
+func (c *Controller) previewFeedDocument(ctx *gin.Context) {
+	feedID, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	docURL, ok := ctx.GetPostForm("url")
+	if !ok || docURL == "" {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "missing 'url'")
+		return
+	}
+	var (
+		user     = ctx.GetString("uid")
+		id       int64
+		storeErr error
+	)
+	err := c.sm.FetchDocument(feedID, docURL, func(r io.Reader) error {
+		filename := path.Base(docURL)
+		id, storeErr = c.ts.Store(ctx.Request.Context(), user, filename, 0, func(w io.Writer) error {
+			return storeCSAF(r, w)
+		})
+		return nil
+	})
+	switch {
+	case errors.Is(err, sources.NoSuchEntryError("")):
+		models.SendError(ctx, http.StatusNotFound, err)
+	case errors.Is(err, sources.InvalidArgumentError("")):
+		models.SendError(ctx, http.StatusBadRequest, err)
+	case err != nil:
+		slog.WarnContext(ctx, "fetching feed document failed", "err", err, "feed", feedID)
+		models.SendError(ctx, http.StatusBadGateway, err)
+	case storeErr != nil:
+		models.SendError(ctx, http.StatusBadRequest, storeErr)
+	default:
+		ctx.JSON(http.StatusCreated, models.ID{ID: id})
+	}
+}
+
 // overviewTempDocuments is an endpoint that returns an overview over all temporary documents.
 //
 //	@Summary		Returns an overview of all temporary documents.
 //	@Description	An overview of all temporary documents that are uploaded by the user are returned.
+//	@Description	Free is the number of files and free_size the number of bytes the user is still able to store.
 //	@Produce		json
 //	@Success		200	{object}	web.overviewTempDocuments.tempDocuments
 //	@Failure		401
 //	@Router			/tempdocuments [get]
 func (c *Controller) overviewTempDocuments(ctx *gin.Context) {
 	type tempDocuments struct {
-		Files []tempstore.Entry `json:"files"`
-		Free  int               `json:"free"`
+		Files    []tempstore.Entry `json:"files"`
+		Free     int               `json:"free"`
+		Size     int64             `json:"size"`
+		FreeSize int64             `json:"free_size"`
 	}
-	user := ctx.GetString("uid")
-	files := c.ts.List(user)
-	free := max(0, min(
-		c.cfg.TempStore.FilesTotal-c.ts.Total(),
-		c.cfg.TempStore.FilesUser-len(files)))
+	usage := c.ts.Usage(ctx.GetString("uid"))
 	ctx.JSON(http.StatusOK, tempDocuments{
-		Files: files,
-		Free:  free,
+		Files:    usage.Files,
+		Free:     usage.FreeFiles,
+		Size:     usage.Size,
+		FreeSize: usage.FreeSize,
 	})
 }
 
@@ -121,7 +196,7 @@ func (c *Controller) viewTempDocument(ctx *gin.Context) {
 		models.SendError(ctx, http.StatusNotFound, err)
 		return
 	case err != nil:
-		slog.Error("fetch temp file failed", "err", err, "id", id)
+		slog.ErrorContext(ctx, "fetch temp file failed", "err", err, "id", id)
 		models.SendError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -159,3 +234,42 @@ func (c *Controller) deleteTempDocument(ctx *gin.Context) {
 		models.SendErrorMessage(ctx, http.StatusNotFound, "not found")
 	}
 }
+
+// overviewAllTempDocuments is an endpoint that returns the temporary
+// documents of all users.
+//
+//	@Summary		Returns the temporary documents of all users.
+//	@Description	Returns the temporary documents and the storage usage of all users
+//	@Description	holding files in the temporary store.
+//	@Produce		json
+//	@Success		200	{array}	tempstore.Usage
+//	@Failure		401
+//	@Router			/admin/tempdocuments [get]
+func (c *Controller) overviewAllTempDocuments(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.ts.Usages())
+}
+
+// deleteUserTempDocument is an endpoint that deletes a temporary document
+// of a given user.
+//
+//	@Summary		Deletes a temporary document of a user.
+//	@Description	Deletes the temporary document with the specified ID of the specified user.
+//	@Param			user	path	string	true	"User"
+//	@Param			id		path	int		true	"Document ID"
+//	@Produce		json
+//	@Success		200	{object}	models.Success	"deleted"
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Router			/admin/tempdocuments/{user}/{id} [delete]
+func (c *Controller) deleteUserTempDocument(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	if c.ts.Delete(ctx.Param("user"), id) {
+		models.SendSuccess(ctx, http.StatusOK, "deleted")
+	} else {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "not found")
+	}
+}
@@ -0,0 +1,591 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+const selectTemplateSQL = `SELECT ` +
+	`id,` +
+	`kind::text,` +
+	`definer,` +
+	`global,` +
+	`teams_id,` +
+	`name,` +
+	`description,` +
+	`body ` +
+	`FROM text_templates `
+
+func scanTemplate(row pgx.Row) (*models.TextTemplate, error) {
+	var tt models.TextTemplate
+	if err := row.Scan(
+		&tt.ID,
+		&tt.Kind,
+		&tt.Definer,
+		&tt.Global,
+		&tt.Team,
+		&tt.Name,
+		&tt.Description,
+		&tt.Body,
+	); err != nil {
+		return nil, err
+	}
+	return &tt, nil
+}
+
+// visibleTemplateSQL returns the condition if a text template is visible
+// to a user. A template is visible to its definer, to all users if it is
+// global and to the members of the team it is shared with.
+// user is the placeholder index of the user.
+func visibleTemplateSQL(user int) string {
+	u := `$` + strconv.Itoa(user)
+	return `(global OR definer = ` + u +
+		` OR teams_id IN (SELECT user_teams(` + u + `)))`
+}
+
+// templateEditable checks if the current user is allowed to modify the template.
+func (c *Controller) templateEditable(ctx *gin.Context, tt *models.TextTemplate) bool {
+	return tt.Definer == ctx.GetString("uid") ||
+		(tt.Global && c.hasAnyRole(ctx, models.Admin))
+}
+
+// checkTemplateSharing checks if the current user is allowed to share
+// the template in the given way. Only admins may share with everyone.
+// A changed team has to be one the user is allowed to share with.
+func (c *Controller) checkTemplateSharing(
+	ctx *gin.Context,
+	rctx context.Context,
+	q rowQuerier,
+	tt *models.TextTemplate,
+	formerTeam *int64,
+) (string, error) {
+	if tt.Global && !c.hasAnyRole(ctx, models.Admin) {
+		return "only admins are allowed to share templates with everyone", nil
+	}
+	if tt.Team == nil || (formerTeam != nil && *formerTeam == *tt.Team) {
+		return "", nil
+	}
+	allowed, err := c.mayShareWithTeam(ctx, rctx, q, *tt.Team)
+	if err != nil || allowed {
+		return "", err
+	}
+	return "templates can only be shared with own teams", nil
+}
+
+// templateFromForm fills the template with the values from the form.
+func templateFromForm(ctx *gin.Context, tt *models.TextTemplate) string {
+	if kind, ok := ctx.GetPostForm("kind"); ok {
+		k, err := models.ParseTemplateKind(kind)
+		if err != nil {
+			return "bad 'kind' value: " + err.Error()
+		}
+		tt.Kind = k
+	}
+	if name, ok := ctx.GetPostForm("name"); ok {
+		if name == "" {
+			return "empty name is not allowed"
+		}
+		tt.Name = name
+	}
+	if desc, ok := ctx.GetPostForm("description"); ok {
+		if desc == "" {
+			tt.Description = nil
+		} else {
+			tt.Description = &desc
+		}
+	}
+	if body, ok := ctx.GetPostForm("body"); ok {
+		if body == "" {
+			return "empty body is not allowed"
+		}
+		tt.Body = body
+	}
+	if global, ok := ctx.GetPostForm("global"); ok && global != "" {
+		g, err := strconv.ParseBool(global)
+		if err != nil {
+			return "bad 'global' value: " + err.Error()
+		}
+		tt.Global = g
+	}
+	if team, ok := ctx.GetPostForm("team"); ok {
+		if team == "" {
+			tt.Team = nil
+		} else {
+			teamID, err := toInt64(team)
+			if err != nil {
+				return "bad 'team' value: " + err.Error()
+			}
+			tt.Team = &teamID
+		}
+	}
+	return ""
+}
+
+// createTemplate is an endpoint that creates a text template.
+//
+//	@Summary		Creates a text template.
+//	@Description	Creates a reusable text template for comments or assessments.
+//	@Param			inputForm	formData	models.TextTemplate	true	"Template"
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Success		201	{object}	models.ID
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		403	{object}	models.Error
+//	@Failure		409	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/templates [post]
+func (c *Controller) createTemplate(ctx *gin.Context) {
+	tt := models.TextTemplate{
+		Kind:    models.CommentTemplate,
+		Definer: ctx.GetString("uid"),
+	}
+	if bad := templateFromForm(ctx, &tt); bad != "" {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, bad)
+		return
+	}
+	if tt.Name == "" || tt.Body == "" {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "missing 'name' or 'body'")
+		return
+	}
+
+	const insertSQL = `INSERT INTO text_templates ` +
+		`(kind, definer, global, teams_id, name, description, body) ` +
+		`VALUES ($1::text_templates_kind, $2, $3, $4, $5, $6, $7) ` +
+		`RETURNING id`
+
+	var (
+		id        int64
+		forbidden string
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			var err error
+			if forbidden, err = c.checkTemplateSharing(ctx, rctx, conn, &tt, nil); err != nil || forbidden != "" {
+				return err
+			}
+			return conn.QueryRow(rctx, insertSQL,
+				string(tt.Kind),
+				tt.Definer,
+				tt.Global,
+				tt.Team,
+				tt.Name,
+				tt.Description,
+				tt.Body,
+			).Scan(&id)
+		}, 0,
+	); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				models.SendErrorMessage(ctx, http.StatusConflict, "already in database")
+				return
+			case "23503":
+				models.SendErrorMessage(ctx, http.StatusBadRequest, "unknown team")
+				return
+			}
+		}
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if forbidden != "" {
+		models.SendErrorMessage(ctx, http.StatusForbidden, forbidden)
+		return
+	}
+	ctx.JSON(http.StatusCreated, models.ID{ID: id})
+}
+
+// listTemplates is an endpoint that returns all visible text templates.
+//
+//	@Summary		Returns text templates.
+//	@Description	Returns the own templates and the templates shared with the user.
+//	@Param			kind	query	string	false	"Template kind"
+//	@Produce		json
+//	@Success		200	{array}		models.TextTemplate
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/templates [get]
+func (c *Controller) listTemplates(ctx *gin.Context) {
+	var kind *models.TemplateKind
+	if k := ctx.Query("kind"); k != "" {
+		tk, ok := parse(ctx, models.ParseTemplateKind, k)
+		if !ok {
+			return
+		}
+		kind = &tk
+	}
+
+	listSQL := selectTemplateSQL +
+		`WHERE ` + visibleTemplateSQL(1) + ` ` +
+		`AND ($2::text_templates_kind IS NULL OR kind = $2::text_templates_kind) ` +
+		`ORDER BY global DESC, name`
+
+	templates := []*models.TextTemplate{}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, listSQL, ctx.GetString("uid"), kind)
+			var err error
+			templates, err = pgx.CollectRows(rows,
+				func(row pgx.CollectableRow) (*models.TextTemplate, error) {
+					return scanTemplate(row)
+				})
+			return err
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, templates)
+}
+
+// fetchTemplate loads a template if it is visible to the user.
+func fetchTemplate(ctx *gin.Context, rctx context.Context, q rowQuerier, id int64) (*models.TextTemplate, error) {
+	fetchSQL := selectTemplateSQL + `WHERE id = $1 AND ` + visibleTemplateSQL(2)
+	return scanTemplate(q.QueryRow(rctx, fetchSQL, id, ctx.GetString("uid")))
+}
+
+// viewTemplate is an endpoint that returns a text template.
+//
+//	@Summary		Returns a text template.
+//	@Description	Returns the text template with the specified ID.
+//	@Param			id	path	int	true	"Template ID"
+//	@Produce		json
+//	@Success		200	{object}	models.TextTemplate
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/templates/{id} [get]
+func (c *Controller) viewTemplate(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	var tt *models.TextTemplate
+	switch err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			var err error
+			tt, err = fetchTemplate(ctx, rctx, conn, id)
+			return err
+		}, 0); {
+	case errors.Is(err, pgx.ErrNoRows):
+		models.SendErrorMessage(ctx, http.StatusNotFound, "not found")
+	case err != nil:
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+	default:
+		ctx.JSON(http.StatusOK, tt)
+	}
+}
+
+// updateTemplate is an endpoint that updates a text template.
+//
+//	@Summary		Updates a text template.
+//	@Description	Updates the text template with the specified ID.
+//	@Param			id			path		int					true	"Template ID"
+//	@Param			inputForm	formData	models.TextTemplate	true	"Template"
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		403	{object}	models.Error
+//	@Failure		404	{object}	models.Error
+//	@Failure		409	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/templates/{id} [put]
+func (c *Controller) updateTemplate(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+
+	const updateSQL = `UPDATE text_templates SET ` +
+		`(kind, global, teams_id, name, description, body) = ` +
+		`($1::text_templates_kind, $2, $3, $4, $5, $6) ` +
+		`WHERE id = $7`
+
+	var bad, forbidden string
+	switch err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tt, err := fetchTemplate(ctx, rctx, conn, id)
+			if err != nil {
+				return err
+			}
+			if !c.templateEditable(ctx, tt) {
+				forbidden = "not allowed to change template"
+				return nil
+			}
+			formerTeam := tt.Team
+			if bad = templateFromForm(ctx, tt); bad != "" {
+				return nil
+			}
+			if forbidden, err = c.checkTemplateSharing(
+				ctx, rctx, conn, tt, formerTeam,
+			); err != nil || forbidden != "" {
+				return err
+			}
+			_, err = conn.Exec(rctx, updateSQL,
+				string(tt.Kind),
+				tt.Global,
+				tt.Team,
+				tt.Name,
+				tt.Description,
+				tt.Body,
+				id)
+			return err
+		}, 0); {
+	case errors.Is(err, pgx.ErrNoRows):
+		models.SendErrorMessage(ctx, http.StatusNotFound, "not found")
+	case err != nil:
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				models.SendErrorMessage(ctx, http.StatusConflict, "already in database")
+				return
+			case "23503":
+				models.SendErrorMessage(ctx, http.StatusBadRequest, "unknown team")
+				return
+			}
+		}
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+	case bad != "":
+		models.SendErrorMessage(ctx, http.StatusBadRequest, bad)
+	case forbidden != "":
+		models.SendErrorMessage(ctx, http.StatusForbidden, forbidden)
+	default:
+		models.SendSuccess(ctx, http.StatusOK, "changed")
+	}
+}
+
+// deleteTemplate is an endpoint that deletes a text template.
+//
+//	@Summary		Deletes a text template.
+//	@Description	Deletes the text template with the specified ID.
+//	@Param			id	path	int	true	"Template ID"
+//	@Produce		json
+//	@Success		200	{object}	models.Success
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		403	{object}	models.Error
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/templates/{id} [delete]
+func (c *Controller) deleteTemplate(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	var forbidden bool
+	switch err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			tt, err := fetchTemplate(ctx, rctx, conn, id)
+			if err != nil {
+				return err
+			}
+			if forbidden = !c.templateEditable(ctx, tt); forbidden {
+				return nil
+			}
+			_, err = conn.Exec(rctx, `DELETE FROM text_templates WHERE id = $1`, id)
+			return err
+		}, 0); {
+	case errors.Is(err, pgx.ErrNoRows):
+		models.SendErrorMessage(ctx, http.StatusNotFound, "not found")
+	case err != nil:
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+	case forbidden:
+		models.SendErrorMessage(ctx, http.StatusForbidden, "not allowed to delete template")
+	default:
+		models.SendSuccess(ctx, http.StatusOK, "deleted")
+	}
+}
+
+// templateVarsSQL loads the values of a document used by the
+// placeholders of the text templates.
+const templateVarsSQL = `SELECT advisories.tracking_id, advisories.publisher, ` +
+	`documents.title, documents.version, documents.tlp, advisories.state::text, ` +
+	`documents.critical, documents.cvss_v3_score, documents.cvss_v2_score, ` +
+	`(SELECT string_agg(cve, ', ' ORDER BY cve) FROM documents_cves ` +
+	`JOIN unique_cves ON documents_cves.cve_id = unique_cves.id ` +
+	`WHERE documents_cves.documents_id = documents.id) ` +
+	`FROM documents JOIN advisories ` +
+	`ON documents.advisories_id = advisories.id ` +
+	`WHERE documents.id = $1`
+
+// templateVars returns the placeholder values of a document.
+// pgx.ErrNoRows is returned if the document is not visible to the user.
+func templateVars(
+	ctx *gin.Context,
+	rctx context.Context,
+	q rowQuerier,
+	docID int64,
+) (map[string]string, error) {
+	var (
+		trackingID, publisher, version, state string
+		title, tlp, cves                      sql.NullString
+		critical, cvss3, cvss2                sql.NullFloat64
+	)
+	if err := q.QueryRow(rctx, templateVarsSQL, docID).Scan(
+		&trackingID, &publisher,
+		&title, &version, &tlp, &state,
+		&critical, &cvss3, &cvss2,
+		&cves,
+	); err != nil {
+		return nil, err
+	}
+	formatFn := func(f sql.NullFloat64) string {
+		if !f.Valid {
+			return ""
+		}
+		return strconv.FormatFloat(f.Float64, 'f', 1, 64)
+	}
+	return map[string]string{
+		"tracking_id":   trackingID,
+		"publisher":     publisher,
+		"title":         title.String,
+		"version":       version,
+		"tlp":           tlp.String,
+		"state":         state,
+		"max_cvss":      formatFn(critical),
+		"cvss_v3_score": formatFn(cvss3),
+		"cvss_v2_score": formatFn(cvss2),
+		"cves":          cves.String,
+		"user":          ctx.GetString("uid"),
+		"date":          time.Now().UTC().Format(time.DateOnly),
+	}, nil
+}
+
+// applyTemplate fills the template with the values of a document and
+// adds the configured banners.
+func (c *Controller) applyTemplate(tt *models.TextTemplate, vars map[string]string) string {
+	text := models.ExpandTemplate(tt.Body, vars)
+	if c.cfg.Banners.Templates {
+		banner, provenance := c.cfg.Banners.Texts(vars["tlp"], vars)
+		if banner != "" || provenance != "" {
+			text = models.WithBanner(text, banner, provenance)
+		}
+	}
+	return text
+}
+
+// commentFromTemplate renders a comment template with the values of a document.
+// The second result is set if the template cannot be used for comments.
+func (c *Controller) commentFromTemplate(
+	ctx *gin.Context,
+	rctx context.Context,
+	q rowQuerier,
+	templateID, docID int64,
+) (string, string, error) {
+	tt, err := fetchTemplate(ctx, rctx, q, templateID)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return "", "template not found", nil
+	case err != nil:
+		return "", "", err
+	case tt.Kind != models.CommentTemplate:
+		return "", "template is not a comment template", nil
+	}
+	vars, err := templateVars(ctx, rctx, q, docID)
+	if err != nil {
+		return "", "", err
+	}
+	return c.applyTemplate(tt, vars), "", nil
+}
+
+// renderTemplate is an endpoint that fills a text template with the
+// values of a document.
+//
+//	@Summary		Renders a text template.
+//	@Description	Replaces the placeholders of the template with values of the
+//	@Description	specified document. Supported placeholders are {tracking_id},
+//	@Description	{publisher}, {title}, {version}, {tlp}, {state}, {max_cvss},
+//	@Description	{cvss_v3_score}, {cvss_v2_score}, {cves}, {user} and {date}.
+//	@Description	If configured the distribution banner of the TLP label and
+//	@Description	the provenance note are added.
+//	@Param			id			path	int	true	"Template ID"
+//	@Param			document	path	int	true	"Document ID"
+//	@Produce		json
+//	@Success		200	{object}	web.renderTemplate.renderResult
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/templates/{id}/documents/{document} [get]
+func (c *Controller) renderTemplate(ctx *gin.Context) {
+	type renderResult struct {
+		Kind models.TemplateKind `json:"kind"`
+		Text string              `json:"text"`
+	}
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	docID, ok := parse(ctx, toInt64, ctx.Param("document"))
+	if !ok {
+		return
+	}
+
+	var (
+		tt    *models.TextTemplate
+		noDoc bool
+		vars  map[string]string
+	)
+	switch err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			var err error
+			if tt, err = fetchTemplate(ctx, rctx, conn, id); err != nil {
+				return err
+			}
+			vars, err = templateVars(ctx, rctx, conn, docID)
+			if noDoc = errors.Is(err, pgx.ErrNoRows); noDoc {
+				return nil
+			}
+			return err
+		}, 0); {
+	case errors.Is(err, pgx.ErrNoRows):
+		models.SendErrorMessage(ctx, http.StatusNotFound, "template not found")
+	case err != nil:
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+	case noDoc:
+		models.SendErrorMessage(ctx, http.StatusNotFound, "document not found")
+	default:
+		ctx.JSON(http.StatusOK, renderResult{
+			Kind: tt.Kind,
+			Text: c.applyTemplate(tt, vars),
+		})
+	}
+}
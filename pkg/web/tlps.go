@@ -11,7 +11,7 @@ package web
 import (
 	"github.com/gin-gonic/gin"
 
-	"github.com/ISDuBA/ISDuBA/pkg/database/query"
+	"github.com/ISDuBA/ISDuBA/pkg/database"
 	"github.com/ISDuBA/ISDuBA/pkg/ginkeycloak"
 	"github.com/ISDuBA/ISDuBA/pkg/models"
 )
@@ -41,7 +41,17 @@ func extractTLPs(claims func(any) error, kc *ginkeycloak.KeycloakToken) error {
 }
 
 // tlps fetches the TLPs from the given Gin context.
+// The rules of the groups of the user extend them.
 func (c *Controller) tlps(ctx *gin.Context) models.PublishersTLPs {
+	tlps := c.tokenTLPs(ctx)
+	if groups := c.gt.ForGroups(c.groups(ctx)); groups != nil {
+		return tlps.Merge(groups)
+	}
+	return tlps
+}
+
+// tokenTLPs fetches the TLPs from the token or the configuration.
+func (c *Controller) tokenTLPs(ctx *gin.Context) models.PublishersTLPs {
 	token, ok := ctx.Get("token")
 	if !ok {
 		return c.cfg.PublishersTLPs
@@ -57,10 +67,12 @@ func (c *Controller) tlps(ctx *gin.Context) models.PublishersTLPs {
 	return tlps
 }
 
-// andTLPExpr adds a filter expressing to only fetch the permitted documents.
-func (c *Controller) andTLPExpr(ctx *gin.Context, expr *query.Expr) *query.Expr {
-	tlps := c.tlps(ctx)
-	return expr.And(tlps.AsExpr())
+// restrictDocuments restricts the documents seen by the database
+// accesses of the request to the ones permitted by the TLP rules of the user.
+func (c *Controller) restrictDocuments(ctx *gin.Context) {
+	rules := c.tlps(ctx).Rules()
+	ctx.Request = ctx.Request.WithContext(
+		database.WithTLPRules(ctx.Request.Context(), rules))
 }
 
 // rolesAsStrings converts a slice of roles to a slice of strings.
@@ -74,6 +86,11 @@ func rolesAsStrings(roles []models.WorkflowRole) []string {
 
 // hasAnyRole checks if at least one of the roles is fulfilled.
 func (c *Controller) hasAnyRole(ctx *gin.Context, roles ...models.WorkflowRole) bool {
+	return c.hasAnyRoleName(ctx, rolesAsStrings(roles)...)
+}
+
+// hasAnyRoleName checks if at least one of the named roles is fulfilled.
+func (c *Controller) hasAnyRoleName(ctx *gin.Context, roles ...string) bool {
 	token, ok := ctx.Get("token")
 	if !ok {
 		return false
@@ -82,5 +99,18 @@ func (c *Controller) hasAnyRole(ctx *gin.Context, roles ...models.WorkflowRole)
 	if !ok || kct == nil {
 		return false
 	}
-	return kct.RealmAccess.ContainsAny(rolesAsStrings(roles))
+	return kct.RealmAccess.ContainsAny(roles)
+}
+
+// realmRoles returns the Keycloak realm roles of the current user.
+func (c *Controller) realmRoles(ctx *gin.Context) []string {
+	token, ok := ctx.Get("token")
+	if !ok {
+		return nil
+	}
+	kct, ok := token.(*ginkeycloak.KeycloakToken)
+	if !ok || kct == nil {
+		return nil
+	}
+	return kct.RealmAccess.Roles
 }
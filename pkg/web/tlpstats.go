@@ -0,0 +1,192 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// tlpStatsColumns are the columns aggregated per TLP label.
+// An advisory counts as assessed if it has reached the review or archived state.
+const tlpStatsColumns = `coalesce(documents.tlp, '') AS tlp, ` +
+	`count(*) AS imports, ` +
+	`coalesce(sum(coalesce(octet_length(documents.original), documents.original_size)), 0) AS size, ` +
+	`count(*) FILTER (WHERE advisories.state IN ('review', 'archived')) AS assessed ` +
+	`FROM events_log ` +
+	`JOIN documents ON events_log.documents_id = documents.id ` +
+	`JOIN advisories ON documents.advisories_id = advisories.id `
+
+type tlpStats struct {
+	TLP        string  `json:"tlp"`
+	Imports    int64   `json:"imports"`
+	Size       int64   `json:"size"`
+	Assessed   int64   `json:"assessed"`
+	Completion float64 `json:"completion"`
+}
+
+type tlpReport struct {
+	Period models.ReportPeriod `json:"period"`
+	From   time.Time           `json:"from"`
+	To     time.Time           `json:"to"`
+	TLPs   []tlpStats          `json:"tlps"`
+	Total  tlpStats            `json:"total"`
+}
+
+// complete calculates the ratio of assessed imports.
+func (ts *tlpStats) complete() {
+	if ts.Imports > 0 {
+		ts.Completion = float64(ts.Assessed) / float64(ts.Imports)
+	}
+}
+
+// tlpStatsTimeline is an endpoint that returns import statistics per TLP.
+//
+//	@Summary		Returns TLP statistics.
+//	@Description	Returns the number, the size and the number of assessed imports
+//	@Description	per TLP label binned in time steps.
+//	@Param			from	query	string	false	"Timerange start"
+//	@Param			to		query	string	false	"Timerange end"
+//	@Param			step	query	string	false	"Time step"
+//	@Produce		json
+//	@Success		200	{object}	any
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/stats/tlp [get]
+func (c *Controller) tlpStatsTimeline(ctx *gin.Context) {
+	from, to, step, ok := importStatsInterval(ctx, importStatsDefaultInterval)
+	if !ok {
+		return
+	}
+	if step <= 0 {
+		step = time.Hour
+	}
+	const selectSQL = `SELECT date_bin($1, events_log.time, $2) AS bucket, ` +
+		tlpStatsColumns +
+		`WHERE events_log.event = 'import_document' ` +
+		`AND events_log.time BETWEEN $2 AND $3 ` +
+		`GROUP BY bucket, 2 ` +
+		`ORDER BY bucket, 2`
+	c.serveImportStats(ctx,
+		func(rctx context.Context, conn *pgxpool.Conn) (pgx.Rows, error) {
+			return conn.Query(rctx, selectSQL, step, from, to)
+		}, collectTLPBuckets)
+}
+
+func collectTLPBuckets(rows pgx.Rows) ([][]any, error) {
+	defer rows.Close()
+	var (
+		list = [][]any{} // [[bucket, [[tlp, imports, size, assessed], ...]], ...]
+		bins [][]any
+		last time.Time
+	)
+	for rows.Next() {
+		var (
+			bucket                  time.Time
+			tlp                     string
+			imports, size, assessed int64
+		)
+		if err := rows.Scan(&bucket, &tlp, &imports, &size, &assessed); err != nil {
+			return nil, fmt.Errorf("cannot scan TLP stats: %w", err)
+		}
+		bucket = bucket.UTC()
+		if len(bins) > 0 && !bucket.Equal(last) {
+			list = append(list, []any{last, bins})
+			bins = nil
+		}
+		bins = append(bins, []any{tlp, imports, size, assessed})
+		last = bucket
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(bins) > 0 {
+		list = append(list, []any{last, bins})
+	}
+	return list, nil
+}
+
+// tlpReportPeriod is an endpoint that returns the TLP distribution of a period.
+//
+//	@Summary		Returns a TLP distribution report.
+//	@Description	Returns the number, the size and the assessment completion of
+//	@Description	the imports per TLP label for a calendar period.
+//	@Description	Without parameters the report covers the last completed month.
+//	@Param			period	query	string	false	"week, month, quarter or year"
+//	@Param			offset	query	int		false	"Number of periods back, 0 is the current one"
+//	@Produce		json
+//	@Success		200	{object}	tlpReport
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/stats/tlp/report [get]
+func (c *Controller) tlpReportPeriod(ctx *gin.Context) {
+	period, ok := parse(ctx, models.ParseReportPeriod, ctx.DefaultQuery("period", "month"))
+	if !ok {
+		return
+	}
+	offset, ok := parse(ctx, strconv.Atoi, ctx.DefaultQuery("offset", "1"))
+	if !ok {
+		return
+	}
+	if offset < 0 {
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "offset has to be non-negative")
+		return
+	}
+	from, to := period.Bounds(time.Now(), offset)
+
+	const selectSQL = `SELECT ` + tlpStatsColumns +
+		`WHERE events_log.event = 'import_document' ` +
+		`AND events_log.time >= $1 AND events_log.time < $2 ` +
+		`GROUP BY 1 ` +
+		`ORDER BY 1`
+
+	report := tlpReport{
+		Period: period,
+		From:   from,
+		To:     to,
+		TLPs:   []tlpStats{},
+	}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, selectSQL, from, to)
+			defer rows.Close()
+			for rows.Next() {
+				var ts tlpStats
+				if err := rows.Scan(&ts.TLP, &ts.Imports, &ts.Size, &ts.Assessed); err != nil {
+					return err
+				}
+				ts.complete()
+				report.Total.Imports += ts.Imports
+				report.Total.Size += ts.Size
+				report.Total.Assessed += ts.Assessed
+				report.TLPs = append(report.TLPs, ts)
+			}
+			return rows.Err()
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	report.Total.complete()
+	ctx.JSON(http.StatusOK, &report)
+}
@@ -98,7 +98,7 @@ func (c *Controller) statsTotal(ctx *gin.Context) {
 			return tx.SendBatch(rctx, batch).Close()
 		}, 0,
 	); err != nil {
-		slog.Error("counting documents/advisories failed", "error", err)
+		slog.ErrorContext(ctx, "counting documents/advisories failed", "error", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
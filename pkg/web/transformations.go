@@ -0,0 +1,78 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+	"github.com/ISDuBA/ISDuBA/pkg/transform"
+)
+
+// viewDocumentTransformations is an endpoint that returns the fields
+// of a document changed by the transformation rules at import.
+//
+//	@Summary		Returns the transformations of a document.
+//	@Description	Returns the fields of the document normalized by the configured
+//	@Description	transformation rules at import with their upstream values.
+//	@Description	The original document is kept unchanged.
+//	@Param			id	path	int	true	"Document ID"
+//	@Produce		json
+//	@Success		200	{array}		transform.Change
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		404	{object}	models.Error
+//	@Failure		500	{object}	models.Error
+//	@Router			/documents/{id}/transformations [get]
+func (c *Controller) viewDocumentTransformations(ctx *gin.Context) {
+	id, ok := parse(ctx, toInt64, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	const selectSQL = `SELECT rule, path, original, replaced ` +
+		`FROM document_transformations WHERE documents_id = $1 ORDER BY num`
+	var (
+		exists  bool
+		changes []transform.Change
+	)
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			var err error
+			if exists, err = documentVisible(rctx, conn, id); err != nil || !exists {
+				return err
+			}
+			rows, _ := conn.Query(rctx, selectSQL, id)
+			changes, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (transform.Change, error) {
+				var ch transform.Change
+				err := row.Scan(&ch.Rule, &ch.Path, &ch.Original, &ch.Replaced)
+				return ch, err
+			})
+			return err
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "database error", "err", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if !exists {
+		models.SendErrorMessage(ctx, http.StatusNotFound, "document not found")
+		return
+	}
+	if changes == nil {
+		changes = []transform.Change{}
+	}
+	ctx.JSON(http.StatusOK, changes)
+}
@@ -0,0 +1,225 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// usageDefaultInterval is the default time range of the usage reports.
+const usageDefaultInterval = 30 * 24 * time.Hour
+
+type usageCounts struct {
+	Requests int64 `json:"requests"`
+	BytesIn  int64 `json:"bytes_in"`
+	BytesOut int64 `json:"bytes_out"`
+}
+
+type usageEndpoint struct {
+	Method   string `json:"method"`
+	Endpoint string `json:"endpoint"`
+	usageCounts
+}
+
+type usageDay struct {
+	Day time.Time `json:"day"`
+	usageCounts
+}
+
+type usageUser struct {
+	User string `json:"user"`
+	usageCounts
+}
+
+type usageReport struct {
+	User      string          `json:"user"`
+	From      time.Time       `json:"from"`
+	To        time.Time       `json:"to"`
+	Total     usageCounts     `json:"total"`
+	Days      []usageDay      `json:"days"`
+	Endpoints []usageEndpoint `json:"endpoints"`
+}
+
+type usageOverview struct {
+	From  time.Time   `json:"from"`
+	To    time.Time   `json:"to"`
+	Total usageCounts `json:"total"`
+	Users []usageUser `json:"users"`
+}
+
+// recordUsage is a middleware which records the API calls
+// of authenticated users.
+func (c *Controller) recordUsage(ctx *gin.Context) {
+	if c.ur == nil {
+		return
+	}
+	ctx.Next()
+	user, route := ctx.GetString("uid"), ctx.FullPath()
+	// Unauthenticated calls and unknown routes are not recorded.
+	if user == "" || route == "" {
+		return
+	}
+	// Keep the records independent of the deployment.
+	route = strings.TrimPrefix(route, c.cfg.Web.BasePath)
+	c.ur.Record(
+		user,
+		ctx.Request.Method,
+		route,
+		ctx.Request.ContentLength,
+		int64(ctx.Writer.Size()))
+}
+
+// usageInterval extracts the days of the requested time range.
+func usageInterval(ctx *gin.Context) (time.Time, time.Time, bool) {
+	from, to, _, ok := importStatsInterval(ctx, usageDefaultInterval)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	const day = 24 * time.Hour
+	return from.Truncate(day), to.Truncate(day), true
+}
+
+// viewUsage is an endpoint that returns the API usage of the current user.
+//
+//	@Summary		Returns the API usage of the current user.
+//	@Description	Returns the number of API calls and the transferred data
+//	@Description	of the current user per day and per endpoint.
+//	@Param			from	query	string	false	"Timerange start"
+//	@Param			to		query	string	false	"Timerange end"
+//	@Produce		json
+//	@Success		200	{object}	web.usageReport
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		500	{object}	models.Error
+//	@Router			/usage [get]
+func (c *Controller) viewUsage(ctx *gin.Context) {
+	c.serveUsageReport(ctx, ctx.GetString("uid"))
+}
+
+// overviewUsage is an endpoint that returns the API usage of all users.
+//
+//	@Summary		Returns the API usage of all users.
+//	@Description	Returns the number of API calls and the transferred data per user.
+//	@Description	If a user is given the detailed usage of this user is returned.
+//	@Param			from	query	string	false	"Timerange start"
+//	@Param			to		query	string	false	"Timerange end"
+//	@Param			user	query	string	false	"User"
+//	@Produce		json
+//	@Success		200	{object}	web.usageOverview
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Failure		403
+//	@Failure		500	{object}	models.Error
+//	@Router			/admin/usage [get]
+func (c *Controller) overviewUsage(ctx *gin.Context) {
+	if user := ctx.Query("user"); user != "" {
+		c.serveUsageReport(ctx, user)
+		return
+	}
+	from, to, ok := usageInterval(ctx)
+	if !ok {
+		return
+	}
+	const selectSQL = `SELECT "user", ` +
+		`sum(requests)::bigint, sum(bytes_in)::bigint, sum(bytes_out)::bigint ` +
+		`FROM api_usage WHERE day BETWEEN $1 AND $2 ` +
+		`GROUP BY "user" ` +
+		`ORDER BY 2 DESC, "user"`
+	overview := usageOverview{From: from, To: to}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, selectSQL, from, to)
+			var err error
+			overview.Users, err = pgx.CollectRows(rows,
+				func(row pgx.CollectableRow) (usageUser, error) {
+					var u usageUser
+					err := row.Scan(&u.User, &u.Requests, &u.BytesIn, &u.BytesOut)
+					return u, err
+				})
+			return err
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "Cannot fetch API usage", "error", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	for i := range overview.Users {
+		overview.Total.add(&overview.Users[i].usageCounts)
+	}
+	ctx.JSON(http.StatusOK, overview)
+}
+
+func (uc *usageCounts) add(o *usageCounts) {
+	uc.Requests += o.Requests
+	uc.BytesIn += o.BytesIn
+	uc.BytesOut += o.BytesOut
+}
+
+func (c *Controller) serveUsageReport(ctx *gin.Context, user string) {
+	from, to, ok := usageInterval(ctx)
+	if !ok {
+		return
+	}
+	const (
+		daysSQL = `SELECT day, ` +
+			`sum(requests)::bigint, sum(bytes_in)::bigint, sum(bytes_out)::bigint ` +
+			`FROM api_usage WHERE "user" = $1 AND day BETWEEN $2 AND $3 ` +
+			`GROUP BY day ` +
+			`ORDER BY day`
+		endpointsSQL = `SELECT method, endpoint, ` +
+			`sum(requests)::bigint, sum(bytes_in)::bigint, sum(bytes_out)::bigint ` +
+			`FROM api_usage WHERE "user" = $1 AND day BETWEEN $2 AND $3 ` +
+			`GROUP BY method, endpoint ` +
+			`ORDER BY 3 DESC, endpoint, method`
+	)
+	report := usageReport{User: user, From: from, To: to}
+	if err := c.db.Run(
+		ctx.Request.Context(),
+		func(rctx context.Context, conn *pgxpool.Conn) error {
+			rows, _ := conn.Query(rctx, daysSQL, user, from, to)
+			var err error
+			if report.Days, err = pgx.CollectRows(rows,
+				func(row pgx.CollectableRow) (usageDay, error) {
+					var d usageDay
+					err := row.Scan(&d.Day, &d.Requests, &d.BytesIn, &d.BytesOut)
+					d.Day = d.Day.UTC()
+					return d, err
+				}); err != nil {
+				return err
+			}
+			rows, _ = conn.Query(rctx, endpointsSQL, user, from, to)
+			report.Endpoints, err = pgx.CollectRows(rows,
+				func(row pgx.CollectableRow) (usageEndpoint, error) {
+					var e usageEndpoint
+					err := row.Scan(&e.Method, &e.Endpoint, &e.Requests, &e.BytesIn, &e.BytesOut)
+					return e, err
+				})
+			return err
+		}, 0,
+	); err != nil {
+		slog.ErrorContext(ctx, "Cannot fetch API usage", "error", err)
+		models.SendError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	for i := range report.Days {
+		report.Total.add(&report.Days[i].usageCounts)
+	}
+	ctx.JSON(http.StatusOK, report)
+}
@@ -65,6 +65,9 @@ func notEmpty(s string) (string, error) {
 // toInt64 parses a given string to a 64bit integer.
 func toInt64(s string) (int64, error) { return strconv.ParseInt(s, 10, 64) }
 
+// toFloat64 parses a given string to a 64bit float.
+func toFloat64(s string) (float64, error) { return strconv.ParseFloat(s, 64) }
+
 // parse parses a string with a given function to a value.
 // If that fails a bad request status code is set in the gin context.
 func parse[T any](ctx *gin.Context, conv func(string) (T, error), s string) (T, bool) {
@@ -75,3 +78,17 @@ func parse[T any](ctx *gin.Context, conv func(string) (T, error), s string) (T,
 	}
 	return v, true
 }
+
+// parseIDs parses the ids given as a repeated form field.
+// If that fails a bad request status code is set in the gin context.
+func parseIDs(ctx *gin.Context, key string) ([]int64, bool) {
+	var ids []int64
+	for _, s := range ctx.PostFormArray(key) {
+		id, ok := parse(ctx, toInt64, s)
+		if !ok {
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	return ids, true
+}
@@ -0,0 +1,259 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocsaf/csaf/v3/csaf"
+	"github.com/gocsaf/csaf/v3/util"
+
+	"github.com/ISDuBA/ISDuBA/pkg/models"
+)
+
+// maxValidateDocuments limits the number of documents
+// to be validated in one request.
+const maxValidateDocuments = 100
+
+// documentValidation is the result of validating an uploaded document
+// with the same checks as applied during the ingestion.
+type documentValidation struct {
+	Filename        string                       `json:"filename"`
+	Valid           bool                         `json:"valid"`
+	TrackingID      string                       `json:"tracking_id,omitempty"`
+	SchemaValid     bool                         `json:"schema_valid"`
+	SchemaErrors    []string                     `json:"schema_errors,omitempty"`
+	FilenameValid   bool                         `json:"filename_valid"`
+	FilenameError   string                       `json:"filename_error,omitempty"`
+	Remote          *csaf.RemoteValidationResult `json:"remote,omitempty"`
+	RemoteError     string                       `json:"remote_error,omitempty"`
+	RemoteAvailable bool                         `json:"remote_available"`
+	Findings        []validationFinding          `json:"findings"`
+}
+
+// validationFinding is a single finding of one of the checks.
+type validationFinding struct {
+	// Check is one of "json", "schema", "filename" or "remote".
+	Check string `json:"check"`
+	// Test is the name of the remote test.
+	Test string `json:"test,omitempty"`
+	// Severity is one of "error", "warning" or "info".
+	Severity     string `json:"severity"`
+	InstancePath string `json:"instance_path,omitempty"`
+	Message      string `json:"message"`
+}
+
+// addFinding records a finding.
+func (vr *documentValidation) addFinding(check, severity, message string) {
+	vr.Findings = append(vr.Findings, validationFinding{
+		Check:    check,
+		Severity: severity,
+		Message:  message,
+	})
+}
+
+// addSchemaErrors records the errors of the schema validation.
+// They are formatted as "instance path: message".
+func (vr *documentValidation) addSchemaErrors(msgs []string) {
+	vr.SchemaErrors = append(vr.SchemaErrors, msgs...)
+	for _, msg := range msgs {
+		path, message, found := strings.Cut(msg, ": ")
+		if !found {
+			path, message = "", msg
+		}
+		vr.Findings = append(vr.Findings, validationFinding{
+			Check:        "schema",
+			Severity:     "error",
+			InstancePath: path,
+			Message:      message,
+		})
+	}
+}
+
+// addRemoteResult records the result of the remote validation.
+func (vr *documentValidation) addRemoteResult(rvr *csaf.RemoteValidationResult) {
+	vr.Remote = rvr
+	for i := range rvr.Tests {
+		test := &rvr.Tests[i]
+		for _, results := range []struct {
+			severity string
+			results  []csaf.RemoteTestResult
+		}{
+			{"error", test.Error},
+			{"warning", test.Warning},
+			{"info", test.Info},
+		} {
+			for _, r := range results.results {
+				vr.Findings = append(vr.Findings, validationFinding{
+					Check:        "remote",
+					Test:         test.Name,
+					Severity:     results.severity,
+					InstancePath: r.InstancePath,
+					Message:      r.Message,
+				})
+			}
+		}
+	}
+}
+
+// validateDocuments is an endpoint that validates CSAF documents without importing them.
+//
+//	@Summary		Validates CSAF documents.
+//	@Description	Checks the uploaded documents like the ingestion does:
+//	@Description	against the schema, if the tracking ID matches the filename
+//	@Description	and against the remote validator if configured.
+//	@Description	The documents are not imported.
+//	@Description	A single document may be posted as JSON body, too. Its filename
+//	@Description	is only checked if given. In this case a single result is returned.
+//	@Param			file		formData	file	false	"Document files"
+//	@Param			filename	query		string	false	"Filename of a document posted as JSON"
+//	@Accept			multipart/form-data
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{array}		documentValidation
+//	@Failure		400	{object}	models.Error
+//	@Failure		401
+//	@Router			/validate [post]
+func (c *Controller) validateDocuments(ctx *gin.Context) {
+	limit := int64(c.cfg.General.AdvisoryUploadLimit)
+	if ctx.ContentType() == "application/json" {
+		c.validateJSONDocument(ctx, limit)
+		return
+	}
+	ctx.Request.Body = http.MaxBytesReader(
+		ctx.Writer, ctx.Request.Body, limit*maxValidateDocuments)
+
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		models.SendError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	files := form.File["file"]
+	switch {
+	case len(files) == 0:
+		models.SendErrorMessage(ctx, http.StatusBadRequest, "missing 'file'")
+		return
+	case len(files) > maxValidateDocuments:
+		models.SendErrorMessage(ctx, http.StatusBadRequest,
+			fmt.Sprintf("too many documents (max %d)", maxValidateDocuments))
+		return
+	}
+	results := make([]*documentValidation, 0, len(files))
+	for _, file := range files {
+		results = append(results, c.validateDocument(file, limit))
+	}
+	ctx.JSON(http.StatusOK, results)
+}
+
+// validateJSONDocument validates a single document posted as JSON body.
+func (c *Controller) validateJSONDocument(ctx *gin.Context, limit int64) {
+	filename := ctx.Query("filename")
+	vr := c.newDocumentValidation(filename)
+	body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, limit)
+	var document any
+	if err := json.NewDecoder(body).Decode(&document); err != nil {
+		vr.notJSON(err)
+	} else {
+		c.checkDocument(vr, filename, document)
+	}
+	ctx.JSON(http.StatusOK, vr)
+}
+
+// newDocumentValidation creates a validation result without findings.
+func (c *Controller) newDocumentValidation(filename string) *documentValidation {
+	return &documentValidation{
+		Filename:        filename,
+		RemoteAvailable: c.val != nil,
+		Findings:        []validationFinding{},
+	}
+}
+
+// notJSON records that the document could not be decoded.
+func (vr *documentValidation) notJSON(err error) {
+	msg := "document is not JSON: " + err.Error()
+	vr.SchemaErrors = []string{msg}
+	vr.addFinding("json", "error", msg)
+}
+
+// validateDocument runs the checks on an uploaded document.
+func (c *Controller) validateDocument(file *multipart.FileHeader, limit int64) *documentValidation {
+	vr := c.newDocumentValidation(file.Filename)
+	if file.Size > limit {
+		msg := fmt.Sprintf("document exceeds upload limit of %d bytes", limit)
+		vr.SchemaErrors = []string{msg}
+		vr.addFinding("json", "error", msg)
+		return vr
+	}
+	f, err := file.Open()
+	if err != nil {
+		msg := "reading document failed: " + err.Error()
+		vr.SchemaErrors = []string{msg}
+		vr.addFinding("json", "error", msg)
+		return vr
+	}
+	defer f.Close()
+
+	var document any
+	if err := json.NewDecoder(io.LimitReader(f, limit)).Decode(&document); err != nil {
+		vr.notJSON(err)
+		return vr
+	}
+	c.checkDocument(vr, file.Filename, document)
+	return vr
+}
+
+// checkDocument runs the checks on a decoded document.
+// The filename is only checked if it is not empty.
+func (c *Controller) checkDocument(vr *documentValidation, filename string, document any) {
+	expr := util.NewPathEval()
+	// The tracking ID is only informational, errors are reported below.
+	expr.Extract(`$.document.tracking.id`, util.StringMatcher(&vr.TrackingID), true, document)
+
+	switch msgs, err := csaf.ValidateCSAF(document); {
+	case err != nil:
+		msg := "schema validation failed: " + err.Error()
+		vr.SchemaErrors = []string{msg}
+		vr.addFinding("schema", "error", msg)
+	case len(msgs) > 0:
+		vr.addSchemaErrors(msgs)
+	default:
+		vr.SchemaValid = true
+	}
+
+	if filename == "" {
+		vr.FilenameValid = true
+	} else if err := util.IDMatchesFilename(expr, document, filename); err != nil {
+		vr.FilenameError = err.Error()
+		vr.addFinding("filename", "error", vr.FilenameError)
+	} else {
+		vr.FilenameValid = true
+	}
+
+	vr.Valid = vr.SchemaValid && vr.FilenameValid
+
+	if c.val != nil {
+		rvr, err := c.val.Validate(document)
+		if err != nil {
+			slog.Error("remote validation failed", "err", err)
+			vr.RemoteError = err.Error()
+			vr.addFinding("remote", "error", vr.RemoteError)
+			vr.Valid = false
+		} else {
+			vr.addRemoteResult(rvr)
+			vr.Valid = vr.Valid && rvr.Valid
+		}
+	}
+}
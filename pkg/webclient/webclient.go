@@ -0,0 +1,176 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package webclient serves the built web client either from
+// the files embedded into the binary or from a directory on disk.
+//
+// To embed the client copy the build output to the dist folder
+// of this package and build with the tag embedclient.
+package webclient
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// embedded are the files of the web client embedded into the binary.
+// nil if the binary was built without them.
+var embedded fs.FS
+
+// immutablePrefix is the folder where SvelteKit places the assets
+// which have a content hash in their names.
+const immutablePrefix = "_app/immutable/"
+
+const (
+	cacheImmutable   = "public, max-age=31536000, immutable"
+	cacheRevalidated = "no-cache"
+)
+
+// Embedded returns true if the web client is embedded into the binary.
+func Embedded() bool {
+	return embedded != nil
+}
+
+// Server serves the files of the web client.
+type Server struct {
+	fsys fs.FS
+	// etags are the cached entity tags of immutable file systems.
+	etags sync.Map
+	// static is true if the file system does not change.
+	static bool
+}
+
+// NewEmbedded returns a server for the embedded web client.
+// Returns nil if the binary was built without it.
+func NewEmbedded() *Server {
+	if embedded == nil {
+		return nil
+	}
+	return NewServer(embedded, true)
+}
+
+// NewDisk returns a server for the web client in the given directory.
+func NewDisk(dir string) *Server {
+	return NewServer(os.DirFS(dir), false)
+}
+
+// NewServer returns a server for the given file system.
+// If static is true the files are assumed to never change
+// and their entity tags are cached.
+func NewServer(fsys fs.FS, static bool) *Server {
+	return &Server{fsys: fsys, static: static}
+}
+
+// Handler returns a middleware which serves the existing files
+// below the given base path and passes all other requests on.
+// The base path is either empty or starts with a slash and
+// has no trailing slash.
+func (s *Server) Handler(basePath string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.Request.Method != http.MethodGet && ctx.Request.Method != http.MethodHead {
+			return
+		}
+		p := ctx.Request.URL.Path
+		if basePath != "" {
+			// Relative links of the client need the trailing slash.
+			if p == basePath {
+				target := basePath + "/"
+				if q := ctx.Request.URL.RawQuery; q != "" {
+					target += "?" + q
+				}
+				ctx.Redirect(http.StatusMovedPermanently, target)
+				ctx.Abort()
+				return
+			}
+			rest, ok := strings.CutPrefix(p, basePath)
+			if !ok || !strings.HasPrefix(rest, "/") {
+				return
+			}
+			p = rest
+		}
+		if s.serve(ctx.Writer, ctx.Request, p) {
+			ctx.Abort()
+		}
+	}
+}
+
+// serve serves the file with the given path. Returns false if there is no such file.
+func (s *Server) serve(w http.ResponseWriter, r *http.Request, p string) bool {
+	name := strings.TrimPrefix(path.Clean("/"+p), "/")
+	if name == "" {
+		name = "index.html"
+	}
+	f, err := s.fsys.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	if info.IsDir() {
+		f.Close()
+		name = path.Join(name, "index.html")
+		if f, err = s.fsys.Open(name); err != nil {
+			return false
+		}
+		defer f.Close()
+		if info, err = f.Stat(); err != nil || info.IsDir() {
+			return false
+		}
+	}
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		return false
+	}
+	h := w.Header()
+	if strings.HasPrefix(name, immutablePrefix) {
+		h.Set("Cache-Control", cacheImmutable)
+	} else {
+		h.Set("Cache-Control", cacheRevalidated)
+	}
+	if s.static {
+		// Embedded files have no modification time so
+		// validate the caches with entity tags.
+		etag, err := s.etag(name, content)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return true
+		}
+		h.Set("ETag", etag)
+	}
+	http.ServeContent(w, r, name, info.ModTime(), content)
+	return true
+}
+
+// etag returns the entity tag of a file.
+func (s *Server) etag(name string, content io.ReadSeeker) (string, error) {
+	if etag, ok := s.etags.Load(name); ok {
+		return etag.(string), nil
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := `"` + base64.RawURLEncoding.EncodeToString(hash.Sum(nil)[:16]) + `"`
+	s.etags.Store(name, etag)
+	return etag, nil
+}
@@ -0,0 +1,118 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package webclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestServe(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":                  {Data: []byte("<html></html>")},
+		"sources/index.html":          {Data: []byte("<html>sources</html>")},
+		"_app/immutable/app.1234.js":  {Data: []byte("console.log(1)")},
+		"_app/version.json":           {Data: []byte(`{"version":"1"}`)},
+		"_app/immutable/chunks/x.css": {Data: []byte("body{}")},
+	}
+	s := NewServer(fsys, true)
+
+	get := func(path, etag string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rec := httptest.NewRecorder()
+		if !s.serve(rec, req, path) {
+			rec.Code = http.StatusNotFound
+		}
+		return rec
+	}
+
+	for _, x := range []struct {
+		path  string
+		code  int
+		cache string
+		body  string
+	}{
+		{"/", http.StatusOK, cacheRevalidated, "<html></html>"},
+		{"/sources", http.StatusOK, cacheRevalidated, "<html>sources</html>"},
+		{"/sources/", http.StatusOK, cacheRevalidated, "<html>sources</html>"},
+		{"/_app/version.json", http.StatusOK, cacheRevalidated, `{"version":"1"}`},
+		{"/_app/immutable/app.1234.js", http.StatusOK, cacheImmutable, "console.log(1)"},
+		{"/_app/immutable/../../index.html", http.StatusOK, cacheRevalidated, "<html></html>"},
+		{"/missing.js", http.StatusNotFound, "", ""},
+		{"/_app", http.StatusNotFound, "", ""},
+	} {
+		rec := get(x.path, "")
+		if rec.Code != x.code {
+			t.Errorf("%s: got status %d, want %d", x.path, rec.Code, x.code)
+			continue
+		}
+		if got := rec.Header().Get("Cache-Control"); got != x.cache {
+			t.Errorf("%s: got Cache-Control %q, want %q", x.path, got, x.cache)
+		}
+		if got := rec.Body.String(); got != x.body {
+			t.Errorf("%s: got body %q, want %q", x.path, got, x.body)
+		}
+	}
+
+	etag := get("/_app/version.json", "").Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("missing ETag")
+	}
+	if rec := get("/_app/version.json", etag); rec.Code != http.StatusNotModified {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusNotModified)
+	}
+	if other := get("/", "").Header().Get("ETag"); other == etag {
+		t.Error("different files have the same ETag")
+	}
+}
+
+func TestHandlerBasePath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(NewServer(fstest.MapFS{
+		"index.html": {Data: []byte("<html></html>")},
+	}, true).Handler("/isduba"))
+	r.GET("/isduba/api/about", func(ctx *gin.Context) { ctx.String(http.StatusOK, "about") })
+
+	for _, x := range []struct {
+		path     string
+		code     int
+		location string
+		body     string
+	}{
+		{"/isduba", http.StatusMovedPermanently, "/isduba/", ""},
+		{"/isduba?x=1", http.StatusMovedPermanently, "/isduba/?x=1", ""},
+		{"/isduba/", http.StatusOK, "", "<html></html>"},
+		{"/isduba/index.html", http.StatusOK, "", "<html></html>"},
+		{"/isduba/api/about", http.StatusOK, "", "about"},
+		{"/", http.StatusNotFound, "", ""},
+		{"/isdubax/", http.StatusNotFound, "", ""},
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, x.path, nil))
+		if rec.Code != x.code {
+			t.Errorf("%s: got status %d, want %d", x.path, rec.Code, x.code)
+			continue
+		}
+		if got := rec.Header().Get("Location"); got != x.location {
+			t.Errorf("%s: got location %q, want %q", x.path, got, x.location)
+		}
+		if x.body != "" && rec.Body.String() != x.body {
+			t.Errorf("%s: got body %q, want %q", x.path, rec.Body.String(), x.body)
+		}
+	}
+}
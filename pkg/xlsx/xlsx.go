@@ -0,0 +1,209 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package xlsx writes spreadsheets with a single sheet in the
+// Office Open XML format. The rows are streamed to the underlying
+// writer so the size of the sheet is not limited by the memory.
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxCellLength is the maximum number of characters of a cell.
+// Longer texts are truncated.
+const MaxCellLength = 32767
+
+// staticParts are the parts of the package besides the sheet.
+var staticParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", xml.Header +
+		`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`},
+	{"xl/styles.xml", xml.Header +
+		`<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font>` +
+		`<font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill>` +
+		`<fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+		`</styleSheet>`},
+}
+
+// Writer writes a spreadsheet with a single sheet.
+type Writer struct {
+	zw *zip.Writer
+	bw *bufio.Writer
+}
+
+// NewWriter starts a spreadsheet with a sheet of the given name.
+func NewWriter(w io.Writer, sheet string) (*Writer, error) {
+	zw := zip.NewWriter(w)
+	for _, part := range staticParts {
+		pw, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(pw, part.content); err != nil {
+			return nil, err
+		}
+	}
+	pw, err := zw.Create("xl/workbook.xml")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(pw, xml.Header+
+		`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" `+
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`+
+		`<sheets><sheet name="`); err != nil {
+		return nil, err
+	}
+	if err := xml.EscapeText(pw, []byte(sheetName(sheet))); err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(pw, `" sheetId="1" r:id="rId1"/></sheets></workbook>`); err != nil {
+		return nil, err
+	}
+	sw, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	bw := bufio.NewWriter(sw)
+	if _, err := bw.WriteString(xml.Header +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<sheetData>`); err != nil {
+		return nil, err
+	}
+	return &Writer{zw: zw, bw: bw}, nil
+}
+
+// sheetName returns a valid name of a sheet.
+func sheetName(name string) string {
+	const invalid = `[]:*?/\`
+	runes := make([]rune, 0, len(name))
+	for _, r := range name {
+		if !strings.ContainsRune(invalid, r) {
+			runes = append(runes, r)
+		}
+	}
+	if len(runes) > 31 {
+		runes = runes[:31]
+	}
+	if len(runes) == 0 {
+		return "Sheet1"
+	}
+	return string(runes)
+}
+
+// WriteHeader writes a row of bold texts.
+func (w *Writer) WriteHeader(names []string) error {
+	w.bw.WriteString(`<row>`)
+	for _, name := range names {
+		w.bw.WriteString(`<c t="inlineStr" s="1">`)
+		if err := w.text(name); err != nil {
+			return err
+		}
+		w.bw.WriteString(`</c>`)
+	}
+	_, err := w.bw.WriteString(`</row>`)
+	return err
+}
+
+// WriteRow writes a row of cells. Integers and floats are stored
+// as numbers, nil as empty cells and all other values as texts.
+func (w *Writer) WriteRow(cells []any) error {
+	w.bw.WriteString(`<row>`)
+	for _, cell := range cells {
+		var num string
+		switch v := cell.(type) {
+		case nil:
+			w.bw.WriteString(`<c/>`)
+			continue
+		case int:
+			num = strconv.Itoa(v)
+		case int32:
+			num = strconv.FormatInt(int64(v), 10)
+		case int64:
+			num = strconv.FormatInt(v, 10)
+		case float32:
+			num = strconv.FormatFloat(float64(v), 'g', -1, 32)
+		case float64:
+			num = strconv.FormatFloat(v, 'g', -1, 64)
+		case string:
+			w.bw.WriteString(`<c t="inlineStr">`)
+			if err := w.text(v); err != nil {
+				return err
+			}
+			w.bw.WriteString(`</c>`)
+			continue
+		default:
+			w.bw.WriteString(`<c t="inlineStr">`)
+			if err := w.text(fmt.Sprint(v)); err != nil {
+				return err
+			}
+			w.bw.WriteString(`</c>`)
+			continue
+		}
+		w.bw.WriteString(`<c><v>`)
+		w.bw.WriteString(num)
+		w.bw.WriteString(`</v></c>`)
+	}
+	_, err := w.bw.WriteString(`</row>`)
+	return err
+}
+
+// text writes an inline text truncated to the maximum cell length.
+func (w *Writer) text(s string) error {
+	if utf8.RuneCountInString(s) > MaxCellLength {
+		s = string([]rune(s)[:MaxCellLength])
+	}
+	w.bw.WriteString(`<is><t xml:space="preserve">`)
+	if err := xml.EscapeText(w.bw, []byte(s)); err != nil {
+		return err
+	}
+	_, err := w.bw.WriteString(`</t></is>`)
+	return err
+}
+
+// Close finishes the sheet and the spreadsheet.
+// It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if _, err := w.bw.WriteString(`</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	if err := w.bw.Flush(); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
@@ -0,0 +1,123 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "advisories: <2026>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteHeader([]string{"id", "title", "critical"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow([]any{int64(1), "A & B <c>", 9.8}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow([]any{int64(2), nil, " x "}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string][]byte{}
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// All parts have to be well-formed.
+		dec := xml.NewDecoder(bytes.NewReader(data))
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s: %v", f.Name, err)
+			}
+		}
+		parts[f.Name] = data
+	}
+	for _, name := range []string{
+		"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml",
+		"xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml",
+	} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("missing part %s", name)
+		}
+	}
+	if !bytes.Contains(parts["xl/workbook.xml"], []byte(`name="advisories &lt;2026&gt;"`)) {
+		t.Errorf("unexpected sheet name: %s", parts["xl/workbook.xml"])
+	}
+
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(parts["xl/worksheets/sheet1.xml"], &sheet); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, row := range sheet.Rows {
+		var cells []string
+		for _, c := range row.Cells {
+			if c.Type == "inlineStr" {
+				cells = append(cells, "s:"+c.Inline)
+			} else {
+				cells = append(cells, "n:"+c.Value)
+			}
+		}
+		got = append(got, strings.Join(cells, "|"))
+	}
+	want := []string{
+		"s:id|s:title|s:critical",
+		"n:1|s:A & B <c>|n:9.8",
+		"n:2|n:|s: x ",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSheetName(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                      "Sheet1",
+		"a/b":                   "ab",
+		"[]:*?/\\":              "Sheet1",
+		strings.Repeat("x", 40): strings.Repeat("x", 31),
+	} {
+		if got := sheetName(in); got != want {
+			t.Errorf("%q: got %q, want %q", in, got, want)
+		}
+	}
+}